@@ -48,6 +48,8 @@ func (x *SetRequest) UnmarshalJSON(data []byte) error {
 			v = &x.Ex
 		case "nx":
 			v = &x.Nx
+		case "xx":
+			v = &x.Xx
 		case "px":
 			v = &x.Px
 		default:
@@ -154,6 +156,127 @@ func (x *KeysRequest) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// UnmarshalJSON for MSetRequest.
+func (x *MSetRequest) UnmarshalJSON(data []byte) error {
+	var mp map[string]jsoniter.RawMessage
+	if err := jsoniter.Unmarshal(data, &mp); err != nil {
+		return err
+	}
+
+	for key, value := range mp {
+		var v any
+
+		switch strings.ToLower(key) {
+		case "project":
+			v = &x.Project
+		case "name":
+			v = &x.Name
+		case "entries":
+			var entries []struct {
+				Key   string              `json:"key"`
+				Value jsoniter.RawMessage `json:"value"`
+			}
+			if err := jsoniter.Unmarshal(value, &entries); err != nil {
+				return err
+			}
+			x.Entries = make([]*CacheEntry, len(entries))
+			for i, e := range entries {
+				x.Entries[i] = &CacheEntry{Key: e.Key, Value: e.Value}
+			}
+			continue
+		case "ex":
+			v = &x.Ex
+		case "nx":
+			v = &x.Nx
+		case "px":
+			v = &x.Px
+		default:
+			continue
+		}
+		if err := jsoniter.Unmarshal(value, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalJSON for HSetRequest.
+func (x *HSetRequest) UnmarshalJSON(data []byte) error {
+	var mp map[string]jsoniter.RawMessage
+	if err := jsoniter.Unmarshal(data, &mp); err != nil {
+		return err
+	}
+
+	for key, value := range mp {
+		var v any
+
+		switch strings.ToLower(key) {
+		case "project":
+			v = &x.Project
+		case "name":
+			v = &x.Name
+		case "key":
+			v = &x.Key
+		case "fields":
+			var fields map[string]jsoniter.RawMessage
+			if err := jsoniter.Unmarshal(value, &fields); err != nil {
+				return err
+			}
+			x.Fields = make(map[string][]byte, len(fields))
+			for f, fv := range fields {
+				x.Fields[f] = fv
+			}
+			continue
+		default:
+			continue
+		}
+		if err := jsoniter.Unmarshal(value, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalJSON for LPushRequest.
+func (x *LPushRequest) UnmarshalJSON(data []byte) error {
+	var mp map[string]jsoniter.RawMessage
+	if err := jsoniter.Unmarshal(data, &mp); err != nil {
+		return err
+	}
+
+	for key, value := range mp {
+		var v any
+
+		switch strings.ToLower(key) {
+		case "project":
+			v = &x.Project
+		case "name":
+			v = &x.Name
+		case "key":
+			v = &x.Key
+		case "values":
+			var values []jsoniter.RawMessage
+			if err := jsoniter.Unmarshal(value, &values); err != nil {
+				return err
+			}
+			x.Values = make([][]byte, len(values))
+			for i, lv := range values {
+				x.Values[i] = lv
+			}
+			continue
+		default:
+			continue
+		}
+		if err := jsoniter.Unmarshal(value, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (x *GetSetResponse) MarshalJSON() ([]byte, error) {
 	resp := struct {
 		Status   string              `json:"status,omitempty"`
@@ -176,6 +299,62 @@ func (x *GetResponse) MarshalJSON() ([]byte, error) {
 	return jsoniter.Marshal(resp)
 }
 
+func (x *MGetResponse) MarshalJSON() ([]byte, error) {
+	type entry struct {
+		Key   string              `json:"key"`
+		Value jsoniter.RawMessage `json:"value,omitempty"`
+		Found bool                `json:"found"`
+	}
+
+	entries := make([]entry, len(x.GetEntries()))
+	for i, e := range x.GetEntries() {
+		entries[i] = entry{
+			Key:   e.GetKey(),
+			Value: e.GetValue(),
+			Found: e.GetFound(),
+		}
+	}
+
+	resp := struct {
+		Entries []entry `json:"entries"`
+	}{
+		Entries: entries,
+	}
+	return jsoniter.Marshal(resp)
+}
+
+func (x *HGetResponse) MarshalJSON() ([]byte, error) {
+	resp := struct {
+		Value jsoniter.RawMessage `json:"value,omitempty"`
+	}{
+		Value: x.GetValue(),
+	}
+	return jsoniter.Marshal(resp)
+}
+
+func (x *HGetAllResponse) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]jsoniter.RawMessage, len(x.GetFields()))
+	for f, v := range x.GetFields() {
+		fields[f] = v
+	}
+
+	resp := struct {
+		Fields map[string]jsoniter.RawMessage `json:"fields"`
+	}{
+		Fields: fields,
+	}
+	return jsoniter.Marshal(resp)
+}
+
+func (x *RPopResponse) MarshalJSON() ([]byte, error) {
+	resp := struct {
+		Value jsoniter.RawMessage `json:"value,omitempty"`
+	}{
+		Value: x.GetValue(),
+	}
+	return jsoniter.Marshal(resp)
+}
+
 func (x *KeysResponse) MarshalJSON() ([]byte, error) {
 	resp := struct {
 		Keys   []string `json:"keys"`
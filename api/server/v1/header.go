@@ -47,6 +47,7 @@ const (
 	HeaderSchemaVersion             = "Tigris-Schema-Version"
 	HeaderBypassAuthCache           = "Tigris-Bypass-Auth-Cache" // #nosec G101
 	HeaderReadSearchDataFromStorage = "Tigris-Search-Read-From-Storage"
+	HeaderReadVersion               = "Tigris-Read-Version"
 	HeaderServerTiming              = "Server-Timing"
 )
 
@@ -207,6 +207,14 @@ func (x *SearchRequest) UnmarshalJSON(data []byte) error {
 			// delaying the vector deserialization
 			x.Vector = value
 			continue
+		case "hybrid":
+			// delaying the hybrid search deserialization
+			x.Hybrid = value
+			continue
+		case "relevance":
+			// delaying the relevance deserialization
+			x.Relevance = value
+			continue
 		case "include_fields":
 			v = &x.IncludeFields
 		case "exclude_fields":
@@ -16,8 +16,10 @@ package api
 
 import (
 	"strings"
+	"time"
 
 	jsoniter "github.com/json-iterator/go"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (x *GetDocumentResponse) MarshalJSON() ([]byte, error) {
@@ -74,6 +76,140 @@ func (x *SearchIndexResponse) MarshalJSON() ([]byte, error) {
 	return jsoniter.Marshal(resp)
 }
 
+func (x *MultiSearchResponse) MarshalJSON() ([]byte, error) {
+	resp := struct {
+		Results []*MultiSearchResult `json:"results"`
+		Hits    []*MultiSearchHit    `json:"hits,omitempty"`
+		Meta    *SearchMetadata      `json:"meta,omitempty"`
+	}{
+		Results: x.Results,
+		Hits:    x.Hits,
+		Meta:    x.Meta,
+	}
+
+	if resp.Results == nil {
+		resp.Results = make([]*MultiSearchResult, 0)
+	}
+	return jsoniter.Marshal(resp)
+}
+
+func (x *MultiSearchResult) MarshalJSON() ([]byte, error) {
+	resp := struct {
+		Index  string                  `json:"index"`
+		Hits   []*SearchHit            `json:"hits,omitempty"`
+		Facets map[string]*SearchFacet `json:"facets"`
+		Meta   *SearchMetadata         `json:"meta"`
+		Group  []*GroupedSearchHits    `json:"group,omitempty"`
+	}{
+		Index:  x.Index,
+		Hits:   x.Hits,
+		Facets: x.Facets,
+		Meta:   x.Meta,
+		Group:  x.Group,
+	}
+
+	if resp.Facets == nil {
+		resp.Facets = make(map[string]*SearchFacet)
+	}
+	return jsoniter.Marshal(resp)
+}
+
+func (x *MultiSearchHit) MarshalJSON() ([]byte, error) {
+	resp := struct {
+		Index    string              `json:"index"`
+		Data     jsoniter.RawMessage `json:"data,omitempty"`
+		Metadata *SearchHitMetadata  `json:"metadata,omitempty"`
+	}{
+		Index:    x.Index,
+		Data:     x.Data,
+		Metadata: CreateMDFromSearchMD(x.Metadata),
+	}
+
+	return jsoniter.Marshal(resp)
+}
+
+func (x *CurationRule) MarshalJSON() ([]byte, error) {
+	resp := struct {
+		Id         string              `json:"id"`
+		Pattern    string              `json:"pattern"`
+		Match      string              `json:"match,omitempty"`
+		Pins       []*CurationPin      `json:"pins,omitempty"`
+		Hides      []string            `json:"hides,omitempty"`
+		Filter     jsoniter.RawMessage `json:"filter,omitempty"`
+		Sort       jsoniter.RawMessage `json:"sort,omitempty"`
+		ValidFrom  *time.Time          `json:"valid_from,omitempty"`
+		ValidUntil *time.Time          `json:"valid_until,omitempty"`
+	}{
+		Id:      x.Id,
+		Pattern: x.Pattern,
+		Match:   x.Match,
+		Pins:    x.Pins,
+		Hides:   x.Hides,
+		Filter:  x.Filter,
+		Sort:    x.Sort,
+	}
+	if x.ValidFrom != nil {
+		t := x.ValidFrom.AsTime()
+		resp.ValidFrom = &t
+	}
+	if x.ValidUntil != nil {
+		t := x.ValidUntil.AsTime()
+		resp.ValidUntil = &t
+	}
+
+	return jsoniter.Marshal(resp)
+}
+
+func (x *CurationRule) UnmarshalJSON(data []byte) error {
+	var mp map[string]jsoniter.RawMessage
+
+	if err := jsoniter.Unmarshal(data, &mp); err != nil {
+		return err
+	}
+
+	for key, value := range mp {
+		var v any
+
+		switch key {
+		case "id":
+			v = &x.Id
+		case "pattern":
+			v = &x.Pattern
+		case "match":
+			v = &x.Match
+		case "pins":
+			v = &x.Pins
+		case "hides":
+			v = &x.Hides
+		case "filter":
+			x.Filter = value
+			continue
+		case "sort":
+			x.Sort = value
+			continue
+		case "valid_from", "valid_until":
+			var t time.Time
+			if err := jsoniter.Unmarshal(value, &t); err != nil {
+				return err
+			}
+			if key == "valid_from" {
+				x.ValidFrom = timestamppb.New(t)
+			} else {
+				x.ValidUntil = timestamppb.New(t)
+			}
+			continue
+		default:
+			continue
+		}
+
+		if err := jsoniter.Unmarshal(value, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (x *CreateOrUpdateIndexRequest) UnmarshalJSON(data []byte) error {
 	var mp map[string]jsoniter.RawMessage
 	if err := jsoniter.Unmarshal(data, &mp); err != nil {
@@ -315,6 +451,39 @@ func (x *DeleteByQueryRequest) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+func (x *MultiSearchRequest) UnmarshalJSON(data []byte) error {
+	var mp map[string]jsoniter.RawMessage
+
+	if err := jsoniter.Unmarshal(data, &mp); err != nil {
+		return err
+	}
+
+	for key, value := range mp {
+		var v any
+
+		switch key {
+		case "project":
+			v = &x.Project
+		case "searches":
+			v = &x.Searches
+		case "merge":
+			v = &x.Merge
+		case "page_size":
+			v = &x.PageSize
+		case "page":
+			v = &x.Page
+		default:
+			continue
+		}
+
+		if err := jsoniter.Unmarshal(value, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (x *SearchIndexRequest) UnmarshalJSON(data []byte) error {
 	var mp map[string]jsoniter.RawMessage
 
@@ -364,6 +533,14 @@ func (x *SearchIndexRequest) UnmarshalJSON(data []byte) error {
 			// delaying the vector deserialization
 			x.Vector = value
 			continue
+		case "hybrid":
+			// delaying the hybrid search deserialization
+			x.Hybrid = value
+			continue
+		case "relevance":
+			// delaying the relevance deserialization
+			x.Relevance = value
+			continue
 		default:
 			continue
 		}
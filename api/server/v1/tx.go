@@ -40,16 +40,21 @@ const (
 	UpdateMethodName  = apiMethodPrefix + "Update"
 	ReadMethodName    = apiMethodPrefix + "Read"
 	CountMethodName   = apiMethodPrefix + "Count"
+	ExportMethodName  = apiMethodPrefix + "Export"
 
 	BuildCollectionIndexMethodName = apiMethodPrefix + "BuildCollectionIndex"
 	ExplainMethodName              = apiMethodPrefix + "Explain"
 
-	SearchMethodName = apiMethodPrefix + "Search"
-	ImportMethodName = apiMethodPrefix + "Import"
+	SearchMethodName     = apiMethodPrefix + "Search"
+	ImportMethodName     = apiMethodPrefix + "Import"
+	BulkImportMethodName = apiMethodPrefix + "BulkImport"
 
 	IndexCollection                 = apiMethodPrefix + "IndexCollection"
 	SearchIndexCollectionMethodName = apiMethodPrefix + "BuildSearchIndex"
 
+	VerifyCollectionSearchIndexMethodName    = apiMethodPrefix + "VerifyCollectionSearchIndex"
+	GetCollectionSearchIndexReportMethodName = apiMethodPrefix + "GetCollectionSearchIndexReport"
+
 	CreateOrUpdateCollectionMethodName  = apiMethodPrefix + "CreateOrUpdateCollection"
 	CreateOrUpdateCollectionsMethodName = apiMethodPrefix + "CreateOrUpdateCollections"
 
@@ -67,6 +72,9 @@ const (
 	DeleteBranchMethodName = apiMethodPrefix + "DeleteBranch"
 	ListBranchesMethodName = apiMethodPrefix + "ListBranches"
 
+	BackupProjectMethodName  = apiMethodPrefix + "BackupProject"
+	RestoreProjectMethodName = apiMethodPrefix + "RestoreProject"
+
 	CreateAppKeyMethodName       = apiMethodPrefix + "CreateAppKey"
 	UpdateAppKeyMethodName       = apiMethodPrefix + "UpdateAppKey"
 	DeleteAppKeyMethodName       = apiMethodPrefix + "DeleteAppKey"
@@ -100,6 +108,23 @@ const (
 	GetMethodName         = cacheMethodPrefix + "Get"
 	DelMethodName         = cacheMethodPrefix + "Del"
 	KeysMethodName        = cacheMethodPrefix + "Keys"
+	ExpireMethodName      = cacheMethodPrefix + "Expire"
+	PersistMethodName     = cacheMethodPrefix + "Persist"
+	TTLMethodName         = cacheMethodPrefix + "TTL"
+	MGetMethodName        = cacheMethodPrefix + "MGet"
+	MSetMethodName        = cacheMethodPrefix + "MSet"
+	ExistsMethodName      = cacheMethodPrefix + "Exists"
+	IncrMethodName        = cacheMethodPrefix + "Incr"
+	IncrByMethodName      = cacheMethodPrefix + "IncrBy"
+	DecrMethodName        = cacheMethodPrefix + "Decr"
+	HSetMethodName        = cacheMethodPrefix + "HSet"
+	HGetMethodName        = cacheMethodPrefix + "HGet"
+	HGetAllMethodName     = cacheMethodPrefix + "HGetAll"
+	ZAddMethodName        = cacheMethodPrefix + "ZAdd"
+	ZRangeMethodName      = cacheMethodPrefix + "ZRange"
+	ZRankMethodName       = cacheMethodPrefix + "ZRank"
+	LPushMethodName       = cacheMethodPrefix + "LPush"
+	RPopMethodName        = cacheMethodPrefix + "RPop"
 
 	// Health.
 	HealthMethodName = "/HealthAPI/Health"
@@ -123,12 +148,22 @@ const (
 	WhoAmIMethodName                 = ObservabilityMethodPrefix + "WhoAmI"
 
 	// Realtime.
-	PresenceMethodName          = realtimeMethodPrefix + "Presence"
-	GetRTChannelMethodName      = realtimeMethodPrefix + "GetRTChannel"
-	GetRTChannelsMethodName     = realtimeMethodPrefix + "GetRTChannels"
-	ReadMessagesMethodName      = realtimeMethodPrefix + "ReadMessages"
-	MessagesMethodName          = realtimeMethodPrefix + "Messages"
-	ListSubscriptionsMethodName = realtimeMethodPrefix + "ListSubscriptions"
+	PresenceMethodName            = realtimeMethodPrefix + "Presence"
+	GetRTChannelMethodName        = realtimeMethodPrefix + "GetRTChannel"
+	GetRTChannelsMethodName       = realtimeMethodPrefix + "GetRTChannels"
+	ReadMessagesMethodName        = realtimeMethodPrefix + "ReadMessages"
+	MessagesMethodName            = realtimeMethodPrefix + "Messages"
+	ListSubscriptionsMethodName   = realtimeMethodPrefix + "ListSubscriptions"
+	SetChannelRuleMethodName      = realtimeMethodPrefix + "SetChannelRule"
+	DeleteChannelRuleMethodName   = realtimeMethodPrefix + "DeleteChannelRule"
+	ListChannelRulesMethodName    = realtimeMethodPrefix + "ListChannelRules"
+	ChannelHistoryMethodName      = realtimeMethodPrefix + "ChannelHistory"
+	SetChannelRetentionMethodName = realtimeMethodPrefix + "SetChannelRetention"
+	GetChannelRetentionMethodName = realtimeMethodPrefix + "GetChannelRetention"
+	GetPresenceMethodName         = realtimeMethodPrefix + "GetPresence"
+	CreateWebhookMethodName       = realtimeMethodPrefix + "CreateWebhook"
+	DeleteWebhookMethodName       = realtimeMethodPrefix + "DeleteWebhook"
+	ListWebhooksMethodName        = realtimeMethodPrefix + "ListWebhooks"
 
 	// Search.
 	CreateOrUpdateIndexMethodName = searchMethodPrefix + "CreateOrUpdateIndex"
@@ -143,6 +178,19 @@ const (
 	SearchDelete                  = searchMethodPrefix + "Delete"
 	SearchDeleteByQuery           = searchMethodPrefix + "DeleteByQuery"
 	SearchSearch                  = searchMethodPrefix + "Search"
+	MultiSearchMethodName         = searchMethodPrefix + "MultiSearch"
+	UpsertSynonymMethodName       = searchMethodPrefix + "UpsertSynonym"
+	DeleteSynonymMethodName       = searchMethodPrefix + "DeleteSynonym"
+	ListSynonymsMethodName        = searchMethodPrefix + "ListSynonyms"
+	UpsertStopwordsMethodName     = searchMethodPrefix + "UpsertStopwords"
+	DeleteStopwordsMethodName     = searchMethodPrefix + "DeleteStopwords"
+	ListStopwordsMethodName       = searchMethodPrefix + "ListStopwords"
+	UpsertCurationRuleMethodName  = searchMethodPrefix + "UpsertCurationRule"
+	DeleteCurationRuleMethodName  = searchMethodPrefix + "DeleteCurationRule"
+	ListCurationRulesMethodName   = searchMethodPrefix + "ListCurationRules"
+	GetTopQueriesMethodName       = searchMethodPrefix + "GetTopQueries"
+	GetNoResultQueriesMethodName  = searchMethodPrefix + "GetNoResultQueries"
+	SuggestQueriesMethodName      = searchMethodPrefix + "SuggestQueries"
 )
 
 func IsTxSupported(ctx context.Context) bool {
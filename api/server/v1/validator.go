@@ -16,6 +16,7 @@ package api
 
 import (
 	"regexp"
+	"strings"
 )
 
 var validNamePattern = regexp.MustCompile("^[a-zA-Z_]+[a-zA-Z0-9_-]+$")
@@ -158,6 +159,14 @@ func (*DescribeCollectionRequest) Validate() error {
 	return nil
 }
 
+func (x *VerifyCollectionSearchIndexRequest) Validate() error {
+	return isValidCollectionAndDatabase(x.Collection, x.Project)
+}
+
+func (x *GetCollectionSearchIndexReportRequest) Validate() error {
+	return isValidCollectionAndDatabase(x.Collection, x.Project)
+}
+
 func (*DescribeDatabaseRequest) Validate() error {
 	return nil
 }
@@ -253,6 +262,169 @@ func (x *GetDocumentRequest) Validate() error {
 	return nil
 }
 
+func (x *UpsertSynonymRequest) Validate() error {
+	if err := isValidProjectAndSearchIndex(x.Project, x.Index); err != nil {
+		return err
+	}
+
+	if len(x.GetSynonym().GetId()) == 0 {
+		return Errorf(Code_INVALID_ARGUMENT, "synonym 'id' is a required field")
+	}
+	if len(x.GetSynonym().GetSynonyms()) == 0 {
+		return Errorf(Code_INVALID_ARGUMENT, "synonym must have at least one term in 'synonyms'")
+	}
+	return nil
+}
+
+func (x *DeleteSynonymRequest) Validate() error {
+	if err := isValidProjectAndSearchIndex(x.Project, x.Index); err != nil {
+		return err
+	}
+
+	if len(x.Id) == 0 {
+		return Errorf(Code_INVALID_ARGUMENT, "'id' is a required field")
+	}
+	return nil
+}
+
+func (x *ListSynonymsRequest) Validate() error {
+	return isValidProjectAndSearchIndex(x.Project, x.Index)
+}
+
+func (x *UpsertStopwordsRequest) Validate() error {
+	if err := isValidProjectAndSearchIndex(x.Project, x.Index); err != nil {
+		return err
+	}
+
+	if len(x.GetStopwordSet().GetId()) == 0 {
+		return Errorf(Code_INVALID_ARGUMENT, "stopword set 'id' is a required field")
+	}
+	if len(x.GetStopwordSet().GetStopwords()) == 0 {
+		return Errorf(Code_INVALID_ARGUMENT, "stopword set must have at least one word in 'stopwords'")
+	}
+	return nil
+}
+
+func (x *DeleteStopwordsRequest) Validate() error {
+	if err := isValidProjectAndSearchIndex(x.Project, x.Index); err != nil {
+		return err
+	}
+
+	if len(x.Id) == 0 {
+		return Errorf(Code_INVALID_ARGUMENT, "'id' is a required field")
+	}
+	return nil
+}
+
+func (x *ListStopwordsRequest) Validate() error {
+	return isValidProjectAndSearchIndex(x.Project, x.Index)
+}
+
+func (x *UpsertCurationRuleRequest) Validate() error {
+	if err := isValidProjectAndSearchIndex(x.Project, x.Index); err != nil {
+		return err
+	}
+
+	if x.Rule == nil {
+		return Errorf(Code_INVALID_ARGUMENT, "'rule' is a required field")
+	}
+	return nil
+}
+
+func (x *DeleteCurationRuleRequest) Validate() error {
+	if err := isValidProjectAndSearchIndex(x.Project, x.Index); err != nil {
+		return err
+	}
+
+	if len(x.Id) == 0 {
+		return Errorf(Code_INVALID_ARGUMENT, "'id' is a required field")
+	}
+	return nil
+}
+
+func (x *ListCurationRulesRequest) Validate() error {
+	return isValidProjectAndSearchIndex(x.Project, x.Index)
+}
+
+// MaxMultiSearches is the maximum number of searches in a single multi search request.
+const MaxMultiSearches = 10
+
+func (x *MultiSearchRequest) Validate() error {
+	if err := isValidDatabase(x.Project); err != nil {
+		return err
+	}
+
+	if len(x.Searches) == 0 {
+		return Errorf(Code_INVALID_ARGUMENT, "'searches' is a required field")
+	}
+	if len(x.Searches) > MaxMultiSearches {
+		return Errorf(Code_INVALID_ARGUMENT, "at most %d searches are allowed in a request", MaxMultiSearches)
+	}
+
+	for _, search := range x.Searches {
+		if search == nil {
+			return Errorf(Code_INVALID_ARGUMENT, "empty search in 'searches'")
+		}
+		if len(search.Project) > 0 && search.Project != x.Project {
+			return Errorf(Code_INVALID_ARGUMENT, "all the searches should belong to the project '%s'", x.Project)
+		}
+		if err := isValidSearchIndexName(search.Index); err != nil {
+			return err
+		}
+		if len(search.IncludeFields) > 0 && len(search.ExcludeFields) > 0 {
+			return Errorf(Code_INVALID_ARGUMENT, "Cannot use both `include_fields` and `exclude_fields` together")
+		}
+	}
+
+	if err := isValidPaginationParam("page", int(x.Page)); err != nil {
+		return err
+	}
+	return isValidPaginationParam("page_size", int(x.PageSize))
+}
+
+func (x *GetTopQueriesRequest) Validate() error {
+	return isValidAnalyticsTarget(x.Project, x.Index, x.Collection, x.Days, x.Limit)
+}
+
+func (x *GetNoResultQueriesRequest) Validate() error {
+	return isValidAnalyticsTarget(x.Project, x.Index, x.Collection, x.Days, x.Limit)
+}
+
+func (x *SuggestQueriesRequest) Validate() error {
+	if err := isValidAnalyticsTarget(x.Project, x.Index, x.Collection, x.Days, x.Limit); err != nil {
+		return err
+	}
+
+	if len(strings.TrimSpace(x.Prefix)) == 0 {
+		return Errorf(Code_INVALID_ARGUMENT, "'prefix' is a required field")
+	}
+	return nil
+}
+
+// isValidAnalyticsTarget validates the search analytics requests which either target a search index or the search
+// of a collection.
+func isValidAnalyticsTarget(project string, index string, collection string, days int32, limit int32) error {
+	if len(index) > 0 && len(collection) > 0 {
+		return Errorf(Code_INVALID_ARGUMENT, "only one of 'index' or 'collection' can be set")
+	}
+
+	if len(collection) > 0 {
+		if err := isValidDatabase(project); err != nil {
+			return err
+		}
+		if err := isValidCollection(collection); err != nil {
+			return err
+		}
+	} else if err := isValidProjectAndSearchIndex(project, index); err != nil {
+		return err
+	}
+
+	if err := isValidPaginationParam("days", int(days)); err != nil {
+		return err
+	}
+	return isValidPaginationParam("limit", int(limit))
+}
+
 func isValidCollection(name string) error {
 	if len(name) == 0 {
 		return Errorf(Code_INVALID_ARGUMENT, "invalid collection name")
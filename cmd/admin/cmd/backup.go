@@ -0,0 +1,139 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/tigrisdata/tigris/server/cdc"
+	"github.com/tigrisdata/tigris/server/config"
+	"github.com/tigrisdata/tigris/server/defaults"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/request"
+	"github.com/tigrisdata/tigris/server/services/v1/database"
+	"github.com/tigrisdata/tigris/store/cache"
+	"github.com/tigrisdata/tigris/util"
+)
+
+var (
+	backupNamespace string
+	backupProject   string
+	backupFile      string
+	invalidateCache bool
+)
+
+func backupContext() context.Context {
+	ctx := context.TODO()
+
+	util.Fatal(Mgr.Tenant.Reload(ctx), "reloading tenants")
+	request.Init(Mgr.Tenant)
+
+	md := request.Metadata{}
+	md.SetNamespace(ctx, backupNamespace)
+
+	return md.SaveToContext(ctx)
+}
+
+func backupSessions() (*database.SessionManager, *database.QueryRunnerFactory) {
+	cdcMgr := cdc.NewManager()
+
+	var txListeners []database.TxListener
+	if config.DefaultConfig.Search.WriteEnabled {
+		txListeners = append(txListeners, database.NewSearchIndexer(Mgr.Search, Mgr.Tenant))
+	}
+	if invalidateCache {
+		// the restored documents replace the cached ones
+		txListeners = append(txListeners, database.NewDocumentCache(cache.NewCache(&config.DefaultConfig.Cache), Mgr.Tenant))
+	}
+
+	sessions := database.NewSessionManager(Mgr.Tx, Mgr.Tenant, txListeners, metadata.NewCacheTracker(Mgr.Tenant, Mgr.Tx))
+
+	return sessions, database.NewQueryRunnerFactory(Mgr.Tx, cdcMgr, Mgr.Search, nil)
+}
+
+func backup() {
+	ctx := backupContext()
+	sessions, factory := backupSessions()
+	defer sessions.Stop()
+
+	out := io.Writer(os.Stdout)
+	if len(backupFile) > 0 {
+		f, err := os.Create(backupFile)
+		util.Fatal(err, "creating backup file")
+		defer func() { util.Fatal(f.Close(), "closing backup file") }()
+		out = f
+	}
+
+	runner := factory.GetBackupRunner(backupProject, out, cache.NewCache(&config.DefaultConfig.Cache), nil)
+	_, err := sessions.ReadOnlyExecute(ctx, runner, database.ReqOptions{})
+	util.Fatal(err, "backup")
+
+	footer := runner.Footer()
+	log.Info().Int64("collections", footer.Collections).Int64("documents", footer.Documents).Msg("backup completed")
+}
+
+func restore() {
+	ctx := backupContext()
+	sessions, factory := backupSessions()
+	defer sessions.Stop()
+
+	in := io.Reader(os.Stdin)
+	if len(backupFile) > 0 {
+		f, err := os.Open(backupFile)
+		util.Fatal(err, "opening backup file")
+		defer func() { _ = f.Close() }()
+		in = bufio.NewReader(f)
+	}
+
+	footer, err := database.NewRestorer(sessions, factory, cache.NewCache(&config.DefaultConfig.Cache)).Restore(ctx, backupProject, in, nil)
+	util.Fatal(err, "restore")
+
+	log.Info().Int64("collections", footer.Collections).Int64("documents", footer.Documents).Msg("restore completed")
+}
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Backup a project to a file",
+	Run: func(cmd *cobra.Command, args []string) {
+		backup()
+	},
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore a project from a backup file",
+	Run: func(cmd *cobra.Command, args []string) {
+		restore()
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{backupCmd, restoreCmd} {
+		c.Flags().StringVar(&backupNamespace, "namespace", defaults.DefaultNamespaceName, "namespace of the project")
+		c.Flags().StringVar(&backupProject, "project", "", "project name, restore defaults to the project in the archive")
+		c.Flags().StringVar(&backupFile, "file", "", "archive file, defaults to stdout for backup and stdin for restore")
+	}
+	_ = backupCmd.MarkFlagRequired("project")
+	restoreCmd.Flags().BoolVar(&invalidateCache, "invalidate-cache", false,
+		"invalidate the cached documents of the restored collections, requires the cache store")
+
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(restoreCmd)
+}
@@ -39,7 +39,11 @@ func main() {
 	ulog.Configure(config.DefaultConfig.Log)
 
 	defaultConfig := &config.DefaultConfig
-	searchStore := search.NewStore(&defaultConfig.Search, defaultConfig.Metrics.Search.Enabled)
+	searchStore, err := search.NewStore(&defaultConfig.Search, defaultConfig.Metrics.Search.Enabled)
+	if err != nil {
+		log.Error().Err(err).Msg("error initializing search store")
+		os.Exit(1)
+	}
 
 	// creating kv store for search and database independently allows us to enable functionality slowly. This is
 	// temporary as once we have functionality tested then
@@ -70,6 +74,7 @@ func main() {
 		KVDB:      kvStoreForDatabase,
 		KVSearch:  kvStoreForSearch,
 		MetaStore: metadata.NewMetadataDictionary(metadata.DefaultNameRegistry),
+		Search:    searchStore,
 	}
 
 	cmd.Execute()
@@ -44,6 +44,10 @@ func InternalWS(format string, args ...any) *api.ErrorEvent {
 	return Errorf(CloseInternalServerErr, format, args...)
 }
 
+func PermissionDeniedWS(format string, args ...any) *api.ErrorEvent {
+	return Errorf(ClosePolicyViolation, format, args...)
+}
+
 func Errorf(c WSErrorCode, format string, a ...any) *api.ErrorEvent {
 	if c == CodeOK {
 		return nil
@@ -32,7 +32,9 @@ var (
 	SecondaryTableKeyPrefix = []byte("idx")
 	SearchTableKeyPrefix    = []byte("sea")
 	PartitionKeyPrefix      = []byte("part")
+	OutboxTableKeyPrefix    = []byte("outbox")
 	CacheKeyPrefix          = "cache"
+	InternalCacheKeyPrefix  = "internal"
 )
 
 var bh codec.BincHandle
@@ -0,0 +1,83 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/tigrisdata/tigris/schema"
+)
+
+// Analyzer returns the analyzer of a searched field, nil if the field is analyzed by the standard analyzer.
+func (q *Query) Analyzer(field string) *schema.FieldAnalyzer {
+	return q.Analyzers[field]
+}
+
+// ToSearchPrefix returns whether each searched field is matched as a prefix, in the same order as the fields. It is
+// empty if none of the searched fields has an analyzer changing the prefix matching. Keyword and n-gram fields are not
+// matched as a prefix, edge n-gram fields always are.
+func (q *Query) ToSearchPrefix() string {
+	if !q.hasAnalyzer(schema.AnalyzerKeyword, schema.AnalyzerNGram, schema.AnalyzerEdgeNGram) {
+		return ""
+	}
+
+	prefix := make([]string, len(q.SearchFields))
+	for i, f := range q.SearchFields {
+		a := q.Analyzer(f)
+		switch {
+		case a.Is(schema.AnalyzerKeyword), a.Is(schema.AnalyzerNGram):
+			prefix[i] = strconv.FormatBool(false)
+		case a.Is(schema.AnalyzerEdgeNGram):
+			prefix[i] = strconv.FormatBool(true)
+		default:
+			prefix[i] = strconv.FormatBool(q.IsPrefixSearch())
+		}
+	}
+
+	return strings.Join(prefix, ",")
+}
+
+// ToSearchInfix returns the infix mode of each searched field, in the same order as the fields. It is empty if none
+// of the searched fields is an n-gram field.
+func (q *Query) ToSearchInfix() string {
+	if !q.hasAnalyzer(schema.AnalyzerNGram) {
+		return ""
+	}
+
+	infix := make([]string, len(q.SearchFields))
+	for i, f := range q.SearchFields {
+		if q.Analyzer(f).Is(schema.AnalyzerNGram) {
+			infix[i] = "always"
+		} else {
+			infix[i] = "off"
+		}
+	}
+
+	return strings.Join(infix, ",")
+}
+
+func (q *Query) hasAnalyzer(types ...string) bool {
+	for _, f := range q.SearchFields {
+		a := q.Analyzer(f)
+		for _, t := range types {
+			if a.Is(t) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
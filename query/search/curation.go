@@ -0,0 +1,46 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PinnedHit places a document at a fixed position of the search results. The position starts from 1.
+type PinnedHit struct {
+	Id       string
+	Position int
+}
+
+// ToSearchPinnedHits returns the pinned hits in the "id:position" list format of the search store.
+func (q *Query) ToSearchPinnedHits() string {
+	var pinned strings.Builder
+	for i, p := range q.PinnedHits {
+		if i != 0 {
+			pinned.WriteString(",")
+		}
+		pinned.WriteString(p.Id)
+		pinned.WriteString(":")
+		pinned.WriteString(strconv.Itoa(p.Position))
+	}
+
+	return pinned.String()
+}
+
+// ToSearchHiddenHits returns the ids of the documents that are removed from the search results.
+func (q *Query) ToSearchHiddenHits() string {
+	return strings.Join(q.HiddenHits, ",")
+}
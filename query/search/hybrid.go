@@ -0,0 +1,107 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	jsoniter "github.com/json-iterator/go"
+	"github.com/tigrisdata/tigris/errors"
+)
+
+type FusionType string
+
+const (
+	// FusionRRF is the reciprocal rank fusion, a document scores 1/(rank_constant + rank) in each of the rankings.
+	FusionRRF FusionType = "rrf"
+	// FusionAlpha weights the vector similarity by alpha and the normalized text score by 1 - alpha.
+	FusionAlpha FusionType = "alpha"
+)
+
+const (
+	defaultRankConstant = 60
+	defaultAlpha        = 0.5
+)
+
+// HybridSearch runs the keyword and the vector search of the query together and fuses both rankings.
+type HybridSearch struct {
+	Fusion       FusionType
+	Alpha        float64
+	RankConstant int
+}
+
+func UnmarshalHybridSearch(input jsoniter.RawMessage) (*HybridSearch, error) {
+	if len(input) == 0 {
+		return nil, nil
+	}
+
+	var raw struct {
+		Fusion       FusionType `json:"fusion"`
+		Alpha        *float64   `json:"alpha"`
+		RankConstant *int       `json:"rank_constant"`
+	}
+	if err := jsoniter.Unmarshal(input, &raw); err != nil {
+		return nil, errors.InvalidArgument("invalid hybrid search '%s'", err.Error())
+	}
+
+	h := &HybridSearch{
+		Fusion:       raw.Fusion,
+		Alpha:        defaultAlpha,
+		RankConstant: defaultRankConstant,
+	}
+	switch h.Fusion {
+	case "":
+		h.Fusion = FusionRRF
+	case FusionRRF, FusionAlpha:
+	default:
+		return nil, errors.InvalidArgument("unsupported hybrid search fusion '%s'", raw.Fusion)
+	}
+
+	if raw.Alpha != nil {
+		if *raw.Alpha < 0 || *raw.Alpha > 1 {
+			return nil, errors.InvalidArgument("hybrid search 'alpha' must be between 0 and 1")
+		}
+		h.Alpha = *raw.Alpha
+	}
+	if raw.RankConstant != nil {
+		if *raw.RankConstant < 0 {
+			return nil, errors.InvalidArgument("hybrid search 'rank_constant' can't be negative")
+		}
+		h.RankConstant = *raw.RankConstant
+	}
+
+	return h, nil
+}
+
+// ValidateSearchMode returns an error if the query mixes the full text and the vector search without a hybrid search, or
+// if a hybrid search is missing either of these or is combined with a sort or a group by.
+func (q *Query) ValidateSearchMode() error {
+	if !q.IsHybridSearch() {
+		if q.IsQAndVectorBoth() {
+			return errors.InvalidArgument("Currently either full text or vector search is supported")
+		}
+		return nil
+	}
+
+	if !q.IsQAndVectorBoth() {
+		return errors.InvalidArgument("hybrid search requires both a full text query 'q' and a 'vector'")
+	}
+	if q.SortOrder != nil && len(*q.SortOrder) > 0 {
+		return errors.InvalidArgument("hybrid search can't be sorted")
+	}
+	if len(q.GroupBy.Fields) > 0 {
+		return errors.InvalidArgument("hybrid search can't be grouped")
+	}
+
+	return nil
+}
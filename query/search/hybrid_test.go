@@ -0,0 +1,55 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalHybridSearch(t *testing.T) {
+	h, err := UnmarshalHybridSearch(nil)
+	require.NoError(t, err)
+	require.Nil(t, h)
+
+	h, err = UnmarshalHybridSearch([]byte(`{}`))
+	require.NoError(t, err)
+	require.Equal(t, &HybridSearch{Fusion: FusionRRF, Alpha: 0.5, RankConstant: 60}, h)
+
+	h, err = UnmarshalHybridSearch([]byte(`{"fusion": "alpha", "alpha": 0, "rank_constant": 10}`))
+	require.NoError(t, err)
+	require.Equal(t, &HybridSearch{Fusion: FusionAlpha, Alpha: 0, RankConstant: 10}, h)
+
+	for _, invalid := range []string{`{"fusion": "max"}`, `{"alpha": 1.5}`, `{"rank_constant": -1}`, `[]`} {
+		_, err = UnmarshalHybridSearch([]byte(invalid))
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestQuery_ValidateSearchMode(t *testing.T) {
+	vector := VectorSearch{VectorF: "vec", VectorV: []float64{1, 0}}
+	hybrid := &HybridSearch{Fusion: FusionRRF, RankConstant: 60}
+
+	require.NoError(t, NewBuilder().Query("shoes").Build().ValidateSearchMode())
+	require.NoError(t, NewBuilder().Query("*").VectorSearch(vector).Build().ValidateSearchMode())
+	require.Error(t, NewBuilder().Query("shoes").VectorSearch(vector).Build().ValidateSearchMode())
+
+	require.NoError(t, NewBuilder().Query("shoes").VectorSearch(vector).Hybrid(hybrid).Build().ValidateSearchMode())
+	require.Error(t, NewBuilder().Query("*").VectorSearch(vector).Hybrid(hybrid).Build().ValidateSearchMode())
+	require.Error(t, NewBuilder().Query("shoes").Hybrid(hybrid).Build().ValidateSearchMode())
+	require.Error(t, NewBuilder().Query("shoes").VectorSearch(vector).Hybrid(hybrid).
+		GroupBy(GroupBy{Fields: []string{"brand"}}).Build().ValidateSearchMode())
+}
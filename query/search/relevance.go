@@ -0,0 +1,74 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// weightedFields returns the weighted fields ordered by their weight and then by their name. These are searched when
+// the query doesn't set the fields to search, so that the weights are not dropped.
+func (q *Query) weightedFields() []string {
+	if q.Relevance == nil || len(q.Relevance.FieldWeights) == 0 {
+		return nil
+	}
+
+	fields := make([]string, 0, len(q.Relevance.FieldWeights))
+	for f := range q.Relevance.FieldWeights {
+		fields = append(fields, f)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		wi, wj := q.Relevance.FieldWeights[fields[i]], q.Relevance.FieldWeights[fields[j]]
+		if wi != wj {
+			return wi > wj
+		}
+		return fields[i] < fields[j]
+	})
+
+	return fields
+}
+
+// ToSearchQueryByWeights returns the weights of the searched fields in the same order as the fields, it is empty if
+// no field is weighted.
+func (q *Query) ToSearchQueryByWeights() string {
+	if q.Relevance == nil || len(q.Relevance.FieldWeights) == 0 || len(q.SearchFields) == 0 {
+		return ""
+	}
+
+	weights := make([]string, len(q.SearchFields))
+	for i, f := range q.SearchFields {
+		weights[i] = strconv.Itoa(q.FieldWeight(f))
+	}
+
+	return strings.Join(weights, ",")
+}
+
+// FieldWeight returns the weight of a searched field, the fields that are not weighted have a weight of 1.
+func (q *Query) FieldWeight(field string) int {
+	if q.Relevance != nil {
+		if weight, ok := q.Relevance.FieldWeights[field]; ok {
+			return weight
+		}
+	}
+
+	return 1
+}
+
+// IsPrefixSearch returns true if the last word of the query is matched as a prefix, which is the default.
+func (q *Query) IsPrefixSearch() bool {
+	return q.Relevance == nil || q.Relevance.Prefix == nil || *q.Relevance.Prefix
+}
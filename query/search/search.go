@@ -20,6 +20,7 @@ import (
 	"github.com/tigrisdata/tigris/query/filter"
 	"github.com/tigrisdata/tigris/query/read"
 	"github.com/tigrisdata/tigris/query/sort"
+	"github.com/tigrisdata/tigris/schema"
 )
 
 const (
@@ -37,6 +38,12 @@ type Query struct {
 	SortOrder      *sort.Ordering
 	GroupBy        GroupBy
 	VectorS        VectorSearch
+	Hybrid         *HybridSearch
+	PinnedHits     []PinnedHit
+	HiddenHits     []string
+	Relevance      *schema.SearchRelevance
+	Analyzers      map[string]*schema.FieldAnalyzer
+	Stopwords      []string
 }
 
 func (q *Query) ToSearchFacetSize() int {
@@ -140,6 +147,10 @@ func (q *Query) IsVectorSearch() bool {
 	return len(q.VectorS.VectorF) > 0
 }
 
+func (q *Query) IsHybridSearch() bool {
+	return q.Hybrid != nil
+}
+
 func (q *Query) IsQAndVectorBoth() bool {
 	return len(q.VectorS.VectorF) > 0 && len(q.Q) > 0 && q.Q != all
 }
@@ -212,11 +223,45 @@ func (b *Builder) VectorSearch(vecSearch VectorSearch) *Builder {
 	return b
 }
 
+func (b *Builder) Hybrid(h *HybridSearch) *Builder {
+	b.query.Hybrid = h
+	return b
+}
+
+func (b *Builder) PinnedHits(pinned []PinnedHit) *Builder {
+	b.query.PinnedHits = pinned
+	return b
+}
+
+func (b *Builder) HiddenHits(ids []string) *Builder {
+	b.query.HiddenHits = ids
+	return b
+}
+
+func (b *Builder) Relevance(r *schema.SearchRelevance) *Builder {
+	b.query.Relevance = r
+	return b
+}
+
+func (b *Builder) Analyzers(a map[string]*schema.FieldAnalyzer) *Builder {
+	b.query.Analyzers = a
+	return b
+}
+
+func (b *Builder) Stopwords(stopwords []string) *Builder {
+	b.query.Stopwords = stopwords
+	return b
+}
+
 func (b *Builder) PageSize(s int) *Builder {
 	b.query.PageSize = s
 	return b
 }
 
 func (b *Builder) Build() *Query {
+	if len(b.query.SearchFields) == 0 {
+		b.query.SearchFields = b.query.weightedFields()
+	}
+
 	return b.query
 }
@@ -78,3 +78,58 @@ func TestQuery_ToSortFields(t *testing.T) {
 		assert.Equal(t, expected, sortBy)
 	})
 }
+
+func TestQuery_Curation(t *testing.T) {
+	q := NewBuilder().Build()
+	require.Empty(t, q.ToSearchPinnedHits())
+	require.Empty(t, q.ToSearchHiddenHits())
+
+	q = NewBuilder().
+		PinnedHits([]PinnedHit{{Id: "123", Position: 1}, {Id: "456", Position: 5}}).
+		HiddenHits([]string{"7", "8"}).
+		Build()
+	require.Equal(t, "123:1,456:5", q.ToSearchPinnedHits())
+	require.Equal(t, "7,8", q.ToSearchHiddenHits())
+}
+
+func TestQuery_Relevance(t *testing.T) {
+	q := NewBuilder().SearchFields([]string{"title", "body"}).Build()
+	require.Empty(t, q.ToSearchQueryByWeights())
+	require.True(t, q.IsPrefixSearch())
+
+	prefix := false
+	q = NewBuilder().
+		SearchFields([]string{"title", "body"}).
+		Relevance(&schema.SearchRelevance{Prefix: &prefix, FieldWeights: map[string]int{"title": 5}}).
+		Build()
+	require.Equal(t, "5,1", q.ToSearchQueryByWeights())
+	require.Equal(t, 1, q.FieldWeight("body"))
+	require.False(t, q.IsPrefixSearch())
+
+	// the weighted fields are searched if the fields to search are not set
+	q = NewBuilder().
+		Relevance(&schema.SearchRelevance{FieldWeights: map[string]int{"body": 2, "title": 5, "author": 2}}).
+		Build()
+	require.Equal(t, "title,author,body", q.ToSearchFields())
+	require.Equal(t, "5,2,2", q.ToSearchQueryByWeights())
+}
+
+func TestQuery_Analyzers(t *testing.T) {
+	q := NewBuilder().SearchFields([]string{"title", "body"}).
+		Analyzers(map[string]*schema.FieldAnalyzer{"body": {Type: schema.AnalyzerLanguage, Language: "fr"}}).
+		Build()
+	require.Empty(t, q.ToSearchPrefix())
+	require.Empty(t, q.ToSearchInfix())
+
+	prefix := false
+	q = NewBuilder().SearchFields([]string{"sku", "title", "name", "body"}).
+		Relevance(&schema.SearchRelevance{Prefix: &prefix}).
+		Analyzers(map[string]*schema.FieldAnalyzer{
+			"sku":   {Type: schema.AnalyzerKeyword},
+			"title": {Type: schema.AnalyzerEdgeNGram},
+			"name":  {Type: schema.AnalyzerNGram},
+		}).
+		Build()
+	require.Equal(t, "false,true,false,false", q.ToSearchPrefix())
+	require.Equal(t, "off,off,always,off", q.ToSearchInfix())
+}
@@ -0,0 +1,50 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/exp/slices"
+)
+
+// ToSearchQ returns the query text sent to the search store, the stopwords of the index are dropped from it. The query
+// is kept as-is if it only has stopwords so that searching for a stopword alone still returns results.
+func (q *Query) ToSearchQ() string {
+	return removeStopwords(q.Q, q.Stopwords)
+}
+
+func removeStopwords(q string, stopwords []string) string {
+	if len(stopwords) == 0 || len(q) == 0 {
+		return q
+	}
+
+	words := strings.Fields(q)
+	kept := make([]string, 0, len(words))
+	for _, word := range words {
+		normalized := strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		}))
+		if !slices.Contains(stopwords, normalized) {
+			kept = append(kept, word)
+		}
+	}
+	if len(kept) == 0 || len(kept) == len(words) {
+		return q
+	}
+
+	return strings.Join(kept, " ")
+}
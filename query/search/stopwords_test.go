@@ -0,0 +1,44 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToSearchQ(t *testing.T) {
+	stopwords := []string{"the", "a", "of"}
+
+	cases := []struct {
+		q   string
+		exp string
+	}{
+		{"the lord of the rings", "lord rings"},
+		{"The Lord", "Lord"},
+		{"a, red shoe", "red shoe"},
+		{"red shoe", "red shoe"},
+		{"the", "the"},
+		{"the a", "the a"},
+		{"*", "*"},
+	}
+	for _, c := range cases {
+		q := NewBuilder().Query(c.q).Stopwords(stopwords).Build()
+		require.Equal(t, c.exp, q.ToSearchQ(), c.q)
+	}
+
+	require.Equal(t, "the lord", NewBuilder().Query("the lord").Build().ToSearchQ())
+}
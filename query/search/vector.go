@@ -30,6 +30,8 @@ type VectorSearch struct {
 	VectorF    string
 	VectorV    []float64
 	RawVectorV []byte
+	// Text is set instead of the vector when the field has an embedding, the vector is then computed by the server.
+	Text string
 }
 
 func UnmarshalVectorSearch(input jsoniter.RawMessage) (VectorSearch, error) {
@@ -50,6 +52,11 @@ func UnmarshalVectorSearch(input jsoniter.RawMessage) (VectorSearch, error) {
 				return err
 			}
 			g.TopK = int(val)
+		} else if jsonDataType == jsonparser.String {
+			if g.Text, err = jsonparser.ParseString(v); err != nil {
+				return err
+			}
+			g.VectorF = string(k)
 		} else {
 			if err = jsoniter.Unmarshal(v, &g.VectorV); err != nil {
 				return err
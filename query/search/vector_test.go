@@ -30,6 +30,14 @@ func TestUnmarshalVectorSearch(t *testing.T) {
 		TopK:       2,
 	}, vs)
 
+	vs, err = UnmarshalVectorSearch([]byte(`{"vec": "red \"running\" shoes", "top_k": 2}`))
+	require.NoError(t, err)
+	require.Equal(t, VectorSearch{
+		VectorF: "vec",
+		Text:    `red "running" shoes`,
+		TopK:    2,
+	}, vs)
+
 	vs, err = UnmarshalVectorSearch([]byte(`{"vec": ["a", "b"]}`))
 	require.Error(t, err)
 	require.Empty(t, vs.VectorF)
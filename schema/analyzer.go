@@ -0,0 +1,165 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import "github.com/tigrisdata/tigris/errors"
+
+const (
+	// AnalyzerStandard splits the text into words, it is the default of the search store.
+	AnalyzerStandard = "standard"
+	// AnalyzerKeyword indexes the whole value as a single token, it is meant for codes, SKUs or emails.
+	AnalyzerKeyword = "keyword"
+	// AnalyzerNGram matches any part of the words, it is meant for infix search.
+	AnalyzerNGram = "ngram"
+	// AnalyzerEdgeNGram matches the beginning of the words, it is meant for autocomplete.
+	AnalyzerEdgeNGram = "edge_ngram"
+	// AnalyzerLanguage applies the segmentation and stemming rules of the language of the field.
+	AnalyzerLanguage = "language"
+)
+
+// AnalyzerLanguages are the languages supported by the language analyzer.
+var AnalyzerLanguages = map[string]struct{}{
+	"en": {},
+	"fr": {},
+	"de": {},
+	"es": {},
+	"it": {},
+	"pt": {},
+	"nl": {},
+	"zh": {},
+	"ja": {},
+	"ko": {},
+	"th": {},
+}
+
+// FieldAnalyzer declares how the text of a string field is analyzed when it is indexed and searched.
+type FieldAnalyzer struct {
+	Type     string `json:"type"`
+	Language string `json:"language,omitempty"`
+}
+
+// Is returns true if the analyzer is of the type passed, a nil analyzer is a standard analyzer.
+func (a *FieldAnalyzer) Is(analyzerType string) bool {
+	if a == nil {
+		return analyzerType == AnalyzerStandard
+	}
+
+	return a.Type == analyzerType
+}
+
+// Locale returns the locale the search store uses to segment the field, empty if it is the default one.
+func (a *FieldAnalyzer) Locale() string {
+	if a == nil || a.Type != AnalyzerLanguage {
+		return ""
+	}
+
+	return a.Language
+}
+
+// Infix returns true if the search store needs to index the field for infix search.
+func (a *FieldAnalyzer) Infix() bool {
+	return a != nil && a.Type == AnalyzerNGram
+}
+
+// Equal returns true if both analyzers analyze the text the same way.
+func (a *FieldAnalyzer) Equal(b *FieldAnalyzer) bool {
+	if a == nil || b == nil {
+		return a.Is(AnalyzerStandard) && b.Is(AnalyzerStandard)
+	}
+
+	return a.Type == b.Type && a.Language == b.Language
+}
+
+// applyTo sets the options of the analyzer that the search store applies while indexing the field.
+func (a *FieldAnalyzer) applyTo(field SearchStoreField) SearchStoreField {
+	if locale := a.Locale(); len(locale) > 0 {
+		field.Locale = &locale
+	}
+	if a.Infix() {
+		infix := true
+		field.Infix = &infix
+	}
+
+	return field
+}
+
+// analyzerChanged returns true if the field in the search store is not indexed with the options of the analyzer.
+func analyzerChanged(a *FieldAnalyzer, inSearch SearchStoreField) bool {
+	locale := ""
+	if inSearch.Locale != nil {
+		locale = *inSearch.Locale
+	}
+	infix := inSearch.Infix != nil && *inSearch.Infix
+
+	return locale != a.Locale() || infix != a.Infix()
+}
+
+// SearchAnalyzers returns the analyzers of the fields keyed by the name of the field in the search store.
+func SearchAnalyzers(fields []*QueryableField) map[string]*FieldAnalyzer {
+	var analyzers map[string]*FieldAnalyzer
+	for _, f := range fields {
+		if f.Analyzer == nil {
+			continue
+		}
+		if analyzers == nil {
+			analyzers = make(map[string]*FieldAnalyzer)
+		}
+		analyzers[f.InMemoryName()] = f.Analyzer
+	}
+
+	return analyzers
+}
+
+// ValidateAnalyzers checks that analyzers are only set on string fields and that their options are supported.
+func ValidateAnalyzers(fields []*Field) error {
+	for _, f := range fields {
+		if err := ValidateAnalyzers(f.Fields); err != nil {
+			return err
+		}
+		if f.Analyzer == nil {
+			continue
+		}
+
+		if !isAnalyzableField(f) {
+			return errors.InvalidArgument("analyzer is only supported on string fields, field '%s' is not a string", f.FieldName)
+		}
+
+		switch f.Analyzer.Type {
+		case AnalyzerStandard, AnalyzerKeyword, AnalyzerNGram, AnalyzerEdgeNGram:
+			if len(f.Analyzer.Language) > 0 {
+				return errors.InvalidArgument("language is only supported by the '%s' analyzer, field '%s'", AnalyzerLanguage, f.FieldName)
+			}
+		case AnalyzerLanguage:
+			if len(f.Analyzer.Language) == 0 {
+				return errors.InvalidArgument("analyzer of field '%s' is missing the language", f.FieldName)
+			}
+			if _, ok := AnalyzerLanguages[f.Analyzer.Language]; !ok {
+				return errors.InvalidArgument("unsupported analyzer language '%s' of field '%s'", f.Analyzer.Language, f.FieldName)
+			}
+		default:
+			return errors.InvalidArgument("unsupported analyzer '%s' of field '%s'", f.Analyzer.Type, f.FieldName)
+		}
+	}
+
+	return nil
+}
+
+func isAnalyzableField(f *Field) bool {
+	if f.DataType == StringType {
+		return true
+	}
+
+	return f.DataType == ArrayType && len(f.Fields) > 0 && f.Fields[0].DataType == StringType
+}
@@ -0,0 +1,64 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"time"
+
+	"github.com/tigrisdata/tigris/errors"
+)
+
+const (
+	// DefaultDocumentCacheTTL is the expiry of the cached documents when the cache policy doesn't set one.
+	DefaultDocumentCacheTTL = 5 * time.Minute
+	maxDocumentCacheTTL     = 24 * time.Hour
+)
+
+// CachePolicy opts a collection in to caching its documents in the cache store. The reads by primary key are served
+// from the cache and the cached documents are invalidated once a transaction changing them is committed. The writes
+// always go to the database, the cache is only populated by the reads. The policy has no effect unless the document
+// cache is enabled in the server config.
+type CachePolicy struct {
+	// Enabled turns on the caching of the documents of the collection.
+	Enabled bool `json:"enabled"`
+	// TTLMs is the expiry of the cached documents in milliseconds, DefaultDocumentCacheTTL if not set.
+	TTLMs int64 `json:"ttl_ms,omitempty"`
+}
+
+// IsEnabled returns true if the documents of the collection are cached.
+func (p *CachePolicy) IsEnabled() bool {
+	return p != nil && p.Enabled
+}
+
+// TTL returns the expiry of the cached documents.
+func (p *CachePolicy) TTL() time.Duration {
+	if p == nil || p.TTLMs == 0 {
+		return DefaultDocumentCacheTTL
+	}
+
+	return time.Duration(p.TTLMs) * time.Millisecond
+}
+
+func (p *CachePolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+
+	if p.TTLMs < 0 || time.Duration(p.TTLMs)*time.Millisecond > maxDocumentCacheTTL {
+		return errors.InvalidArgument("cache 'ttl_ms' must be between 0 and %d", maxDocumentCacheTTL.Milliseconds())
+	}
+
+	return nil
+}
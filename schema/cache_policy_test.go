@@ -0,0 +1,50 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachePolicy(t *testing.T) {
+	var p *CachePolicy
+	require.False(t, p.IsEnabled())
+	require.Equal(t, DefaultDocumentCacheTTL, p.TTL())
+	require.NoError(t, p.Validate())
+
+	p = &CachePolicy{Enabled: true, TTLMs: 1500}
+	require.True(t, p.IsEnabled())
+	require.Equal(t, 1500*time.Millisecond, p.TTL())
+	require.NoError(t, p.Validate())
+
+	for _, invalid := range []*CachePolicy{
+		{Enabled: true, TTLMs: -1},
+		{Enabled: true, TTLMs: maxDocumentCacheTTL.Milliseconds() + 1},
+	} {
+		require.Error(t, invalid.Validate())
+	}
+}
+
+func TestCachePolicyFromSchema(t *testing.T) {
+	factory, err := NewFactoryBuilder(true).Build("t1", []byte(`{"title": "t1", "properties": {"a": {"type": "string"}}, "primary_key": ["a"], "cache": {"enabled": true, "ttl_ms": 1000}}`))
+	require.NoError(t, err)
+	require.Equal(t, &CachePolicy{Enabled: true, TTLMs: 1000}, factory.Cache)
+
+	_, err = NewFactoryBuilder(true).Build("t1", []byte(`{"title": "t1", "properties": {"a": {"type": "string"}}, "primary_key": ["a"], "cache": {"ttl_ms": -5}}`))
+	require.Error(t, err)
+}
@@ -25,7 +25,6 @@ import (
 	jsoniter "github.com/json-iterator/go"
 	"github.com/santhosh-tekuri/jsonschema/v5"
 	"github.com/tigrisdata/tigris/errors"
-	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
 )
 
 const (
@@ -73,11 +72,17 @@ type DefaultCollection struct {
 	QueryableFields []*QueryableField
 	// CollectionType is the type of the collection. Only two types of collections are supported "messages" and "documents"
 	CollectionType CollectionType
+	// Relevance is the default relevance of the search queries on the collection.
+	Relevance *SearchRelevance
+	// Cache is the policy of caching the documents of the collection.
+	Cache *CachePolicy
+	// Publish is the trigger publishing the changes of the documents to a realtime channel.
+	Publish *PublishTrigger
 	// Track all the int64 paths in the collection. For example, if top level object has an int64 field then key would be
 	// obj.fieldName so that caller can easily navigate to this field.
 	int64FieldsPath *int64PathBuilder
 	// This is the existing fields in search
-	FieldsInSearch []tsApi.Field
+	FieldsInSearch []SearchStoreField
 
 	fieldsWithInsertDefaults map[string]struct{}
 	fieldsWithUpdateDefaults map[string]struct{}
@@ -174,7 +179,7 @@ func NewDefaultCollection(id uint32, schVer uint32, factory *Factory, schemas Ve
 	}
 	disableAdditionalPropertiesAndAllowNullable(validator.Required, validator.Properties)
 
-	var prevVersionInSearch []tsApi.Field
+	var prevVersionInSearch []SearchStoreField
 	if implicitSearchIndex != nil {
 		prevVersionInSearch = implicitSearchIndex.prevVersionInSearch
 	}
@@ -198,6 +203,9 @@ func NewDefaultCollection(id uint32, schVer uint32, factory *Factory, schemas Ve
 		Schema:                   factory.Schema,
 		QueryableFields:          queryableFields,
 		CollectionType:           factory.CollectionType,
+		Relevance:                factory.Relevance,
+		Cache:                    factory.Cache,
+		Publish:                  factory.Publish,
 		ImplicitSearchIndex:      implicitSearchIndex,
 		fieldsWithInsertDefaults: make(map[string]struct{}),
 		fieldsWithUpdateDefaults: make(map[string]struct{}),
@@ -0,0 +1,152 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/tigrisdata/tigris/errors"
+)
+
+type CurationMatch string
+
+const (
+	// CurationMatchExact matches when the query is equal to the pattern, this is the default.
+	CurationMatchExact CurationMatch = "exact"
+	// CurationMatchContains matches when the query contains the pattern.
+	CurationMatchContains CurationMatch = "contains"
+	// CurationMatchPrefix matches when the query starts with the pattern.
+	CurationMatchPrefix CurationMatch = "prefix"
+	// CurationMatchRegex matches when the query matches the pattern as a regular expression.
+	CurationMatchRegex CurationMatch = "regex"
+)
+
+// CurationPin places the document at a fixed position of the results, the position starts from 1.
+type CurationPin struct {
+	Id       string `json:"id"`
+	Position int    `json:"position"`
+}
+
+// CurationRule changes the results of the search queries that match its pattern. The matching documents can be pinned
+// at fixed positions or hidden, and a filter or a sort order can be injected in the query. A rule is only active in
+// its time window if one is set.
+type CurationRule struct {
+	Id      string        `json:"id"`
+	Pattern string        `json:"pattern"`
+	Match   CurationMatch `json:"match,omitempty"`
+	Pins    []CurationPin `json:"pins,omitempty"`
+	Hides   []string      `json:"hides,omitempty"`
+	// Filter is ANDed with the filter of the query.
+	Filter jsoniter.RawMessage `json:"filter,omitempty"`
+	// Sort is only used if the query doesn't have a sort order.
+	Sort       jsoniter.RawMessage `json:"sort,omitempty"`
+	ValidFrom  *time.Time          `json:"valid_from,omitempty"`
+	ValidUntil *time.Time          `json:"valid_until,omitempty"`
+
+	// regex is the compiled pattern of a regex rule.
+	regex *regexp.Regexp
+}
+
+func (r *CurationRule) Validate() error {
+	if len(r.Id) == 0 {
+		return errors.InvalidArgument("curation rule 'id' is a required field")
+	}
+	if len(r.Pattern) == 0 {
+		return errors.InvalidArgument("curation rule 'pattern' is a required field")
+	}
+
+	switch r.Match {
+	case "", CurationMatchExact, CurationMatchContains, CurationMatchPrefix:
+	case CurationMatchRegex:
+		if err := r.Compile(); err != nil {
+			return err
+		}
+	default:
+		return errors.InvalidArgument("unsupported curation rule match '%s'", r.Match)
+	}
+
+	if len(r.Pins) == 0 && len(r.Hides) == 0 && len(r.Filter) == 0 && len(r.Sort) == 0 {
+		return errors.InvalidArgument("curation rule needs at least one of 'pins', 'hides', 'filter' or 'sort'")
+	}
+	for _, p := range r.Pins {
+		if len(p.Id) == 0 || p.Position < 1 {
+			return errors.InvalidArgument("pinned document needs an 'id' and a 'position' starting from 1")
+		}
+	}
+	if r.ValidFrom != nil && r.ValidUntil != nil && !r.ValidFrom.Before(*r.ValidUntil) {
+		return errors.InvalidArgument("curation rule 'valid_from' must be before 'valid_until'")
+	}
+
+	return nil
+}
+
+// Compile compiles the pattern of a regex rule so that it isn't compiled again for every search query. The rules are
+// compiled when these are validated and when these are loaded from the metadata.
+func (r *CurationRule) Compile() error {
+	if r.Match != CurationMatchRegex {
+		return nil
+	}
+
+	regex, err := regexp.Compile("(?i)" + r.Pattern)
+	if err != nil {
+		return errors.InvalidArgument("invalid curation rule pattern '%s'", err.Error())
+	}
+
+	r.regex = regex
+	return nil
+}
+
+// CompileCurationRules returns a copy of the rules with their patterns compiled. A rule with an invalid pattern never
+// matches.
+func CompileCurationRules(rules []CurationRule) []CurationRule {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	compiled := make([]CurationRule, len(rules))
+	for i := range rules {
+		compiled[i] = rules[i]
+		_ = compiled[i].Compile()
+	}
+
+	return compiled
+}
+
+// Matches returns true if the rule is active at "now" and the search query matches its pattern. The comparison
+// ignores the case and the extra whitespaces of the query.
+func (r *CurationRule) Matches(q string, now time.Time) bool {
+	if r.ValidFrom != nil && now.Before(*r.ValidFrom) {
+		return false
+	}
+	if r.ValidUntil != nil && !now.Before(*r.ValidUntil) {
+		return false
+	}
+
+	q = strings.Join(strings.Fields(strings.ToLower(q)), " ")
+	pattern := strings.ToLower(r.Pattern)
+	switch r.Match {
+	case CurationMatchContains:
+		return strings.Contains(q, pattern)
+	case CurationMatchPrefix:
+		return strings.HasPrefix(q, pattern)
+	case CurationMatchRegex:
+		return r.regex != nil && r.regex.MatchString(q)
+	default:
+		return q == strings.Join(strings.Fields(pattern), " ")
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurationRuleMatches(t *testing.T) {
+	now := time.Now()
+	before, after := now.Add(-time.Hour), now.Add(time.Hour)
+
+	cases := []struct {
+		rule    CurationRule
+		q       string
+		matches bool
+	}{
+		{CurationRule{Pattern: "smart tv"}, "Smart  TV", true},
+		{CurationRule{Pattern: "smart tv"}, "smart tv 55", false},
+		{CurationRule{Pattern: "tv", Match: CurationMatchContains}, "smart tv 55", true},
+		{CurationRule{Pattern: "smart", Match: CurationMatchPrefix}, "smart tv", true},
+		{CurationRule{Pattern: "smart", Match: CurationMatchPrefix}, "a smart tv", false},
+		{CurationRule{Pattern: "^(tv|television)s?$", Match: CurationMatchRegex}, "Televisions", true},
+		{CurationRule{Pattern: "tv", ValidFrom: &before, ValidUntil: &after}, "tv", true},
+		{CurationRule{Pattern: "tv", ValidFrom: &after}, "tv", false},
+		{CurationRule{Pattern: "tv", ValidUntil: &before}, "tv", false},
+	}
+	for _, c := range cases {
+		require.NoError(t, c.rule.Compile())
+		require.Equal(t, c.matches, c.rule.Matches(c.q, now), "%s %s", c.rule.Pattern, c.q)
+	}
+
+	compiled := CompileCurationRules([]CurationRule{
+		{Pattern: "^tvs?$", Match: CurationMatchRegex},
+		{Pattern: "(tv", Match: CurationMatchRegex},
+	})
+	require.True(t, compiled[0].Matches("TVs", now))
+	require.False(t, compiled[1].Matches("(tv", now))
+}
+
+func TestCurationRuleValidate(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Hour)
+
+	require.NoError(t, (&CurationRule{Id: "r1", Pattern: "tv", Pins: []CurationPin{{Id: "1", Position: 1}}}).Validate())
+	require.NoError(t, (&CurationRule{Id: "r1", Pattern: "tv", Filter: []byte(`{"brand":"acme"}`)}).Validate())
+
+	for _, invalid := range []CurationRule{
+		{Pattern: "tv", Hides: []string{"1"}},
+		{Id: "r1", Hides: []string{"1"}},
+		{Id: "r1", Pattern: "tv"},
+		{Id: "r1", Pattern: "tv", Match: "fuzzy", Hides: []string{"1"}},
+		{Id: "r1", Pattern: "(tv", Match: CurationMatchRegex, Hides: []string{"1"}},
+		{Id: "r1", Pattern: "tv", Pins: []CurationPin{{Id: "1", Position: 0}}},
+		{Id: "r1", Pattern: "tv", Hides: []string{"1"}, ValidFrom: &later, ValidUntil: &now},
+	} {
+		invalid := invalid
+		require.Error(t, invalid.Validate(), invalid)
+	}
+}
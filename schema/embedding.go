@@ -0,0 +1,114 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"strings"
+
+	"github.com/tigrisdata/tigris/errors"
+)
+
+const (
+	// EmbeddingProviderHTTP calls an OpenAI compatible embeddings endpoint configured in the server.
+	EmbeddingProviderHTTP = "http"
+	// EmbeddingProviderHash is a deterministic local model hashing the tokens of the text, it is meant for tests.
+	EmbeddingProviderHash = "hash"
+)
+
+// FieldEmbedding declares that the vector field is computed by the server from the text of the source fields. The
+// provider is the one configured in the server if it is not set.
+type FieldEmbedding struct {
+	Source   []string `json:"source"`
+	Provider string   `json:"provider,omitempty"`
+	Model    string   `json:"model,omitempty"`
+}
+
+// EmbeddingFields returns the top level vector fields that are derived from other fields.
+func EmbeddingFields(fields []*Field) []*Field {
+	var embedded []*Field
+	for _, f := range fields {
+		if f.Embedding != nil {
+			embedded = append(embedded, f)
+		}
+	}
+
+	return embedded
+}
+
+// ValidateEmbeddings checks that only top level vector fields with dimensions have an embedding and that the sources of
+// the embeddings are string fields of the schema.
+func ValidateEmbeddings(fields []*Field) error {
+	for _, f := range fields {
+		if f.Embedding == nil {
+			if err := validateNestedEmbeddings(f.Fields); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if f.DataType != VectorType {
+			return errors.InvalidArgument("embedding is only supported on vector fields, field '%s' is not a vector", f.FieldName)
+		}
+		if f.Dimensions == nil || *f.Dimensions <= 0 {
+			return errors.InvalidArgument("embedding of field '%s' needs the dimensions of the vector", f.FieldName)
+		}
+		if len(f.Embedding.Source) == 0 {
+			return errors.InvalidArgument("embedding of field '%s' is missing the source fields", f.FieldName)
+		}
+
+		switch f.Embedding.Provider {
+		case "", EmbeddingProviderHTTP, EmbeddingProviderHash:
+		default:
+			return errors.InvalidArgument("unsupported embedding provider '%s' of field '%s'", f.Embedding.Provider, f.FieldName)
+		}
+
+		for _, source := range f.Embedding.Source {
+			sf := getFieldByPath(fields, source)
+			if sf == nil {
+				return errors.InvalidArgument("embedding source '%s' of field '%s' is not in the schema", source, f.FieldName)
+			}
+			if sf.DataType != StringType {
+				return errors.InvalidArgument("embedding source '%s' of field '%s' is not a string", source, f.FieldName)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateNestedEmbeddings(fields []*Field) error {
+	for _, f := range fields {
+		if f.Embedding != nil {
+			return errors.InvalidArgument("embedding is only supported on top level fields '%s'", f.FieldName)
+		}
+		if err := validateNestedEmbeddings(f.Fields); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func getFieldByPath(fields []*Field, path string) *Field {
+	var field *Field
+	for _, key := range strings.Split(path, ".") {
+		if field = GetField(fields, key); field == nil {
+			return nil
+		}
+		fields = field.Fields
+	}
+
+	return field
+}
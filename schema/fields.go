@@ -287,6 +287,8 @@ var SupportedFieldProperties = container.NewHashSet(
 	"maxItems",
 	"additionalProperties",
 	"dimensions",
+	"embedding",
+	"analyzer",
 	"id",
 )
 
@@ -439,6 +441,8 @@ type FieldBuilder struct {
 	ID                   *bool                 `json:"id,omitempty"`
 	SearchIndex          *bool                 `json:"searchIndex,omitempty"`
 	Dimensions           *int                  `json:"dimensions,omitempty"`
+	Embedding            *FieldEmbedding       `json:"embedding,omitempty"`
+	Analyzer             *FieldAnalyzer        `json:"analyzer,omitempty"`
 	Items                *FieldBuilder         `json:"items,omitempty"`
 	Properties           jsoniter.RawMessage   `json:"properties,omitempty"`
 	Primary              *bool
@@ -483,6 +487,8 @@ func (f *FieldBuilder) Build(setSearchDefaults bool) (*Field, error) {
 		PrimaryKeyField:      f.Primary,
 		AutoGenerated:        f.Auto,
 		Dimensions:           f.Dimensions,
+		Embedding:            f.Embedding,
+		Analyzer:             f.Analyzer,
 		AdditionalProperties: f.AdditionalProperties,
 		SearchIdField:        f.ID,
 	}
@@ -518,6 +524,8 @@ type Field struct {
 	SearchIndexed   *bool
 	SearchIdField   *bool
 	Dimensions      *int
+	Embedding       *FieldEmbedding
+	Analyzer        *FieldAnalyzer
 	// Nested fields are the fields where we know the schema of nested attributes like if properties are
 	Fields               []*Field
 	AdditionalProperties *bool
@@ -128,6 +128,14 @@ type Product struct {
 	Subtype Subtype ` + "`" + `json:"subtype"` + "`" + `
 }
 `},
+		{
+			"analyzer", analyzerTest, `
+type Product struct {
+	NameAn string ` + "`" + `json:"name_an" tigris:"analyzer:keyword"` + "`" + `
+	NameSi string ` + "`" + `json:"name_si" tigris:"searchIndex,analyzer:language,language:fr"` + "`" + `
+}
+`,
+		},
 		{
 			"no_tag", noGoTagSchema, `
 type Product struct {
@@ -75,14 +75,21 @@ type Field struct {
 
 	Required []string `json:"required,omitempty"`
 
-	SearchIndex bool `json:"searchIndex,omitempty"`
-	Facet       bool `json:"facet,omitempty"`
-	Sort        bool `json:"sort,omitempty"`
+	SearchIndex bool           `json:"searchIndex,omitempty"`
+	Facet       bool           `json:"facet,omitempty"`
+	Sort        bool           `json:"sort,omitempty"`
+	Analyzer    *FieldAnalyzer `json:"analyzer,omitempty"`
 
 	// RequiredTag is used during schema building only
 	RequiredTag bool `json:"-"`
 }
 
+// FieldAnalyzer represents the text analyzer of a search field.
+type FieldAnalyzer struct {
+	Type     string `json:"type"`
+	Language string `json:"language,omitempty"`
+}
+
 // Schema is top level JSON schema object.
 type Schema struct {
 	Name   string            `json:"title,omitempty"`
@@ -133,9 +140,11 @@ type FieldGen struct {
 	CreatedAt bool
 	Required  bool
 
-	SearchIndex bool
-	Facet       bool
-	Sort        bool
+	SearchIndex      bool
+	Facet            bool
+	Sort             bool
+	Analyzer         string
+	AnalyzerLanguage string
 
 	Nullable      bool
 	ItemsNullable bool
@@ -185,6 +194,10 @@ func (s *schemaGenerator) genField(w io.Writer, n string, v *Field, pk []string,
 	f.SearchIndex = v.SearchIndex
 	f.Facet = v.Facet
 	f.Sort = v.Sort
+	if v.Analyzer != nil {
+		f.Analyzer = v.Analyzer.Type
+		f.AnalyzerLanguage = v.Analyzer.Language
+	}
 	f.Nullable = v.Type.isNullable
 
 	f.Default = v.Default
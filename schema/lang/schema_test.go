@@ -136,6 +136,13 @@ var (
           }
         }`
 
+	analyzerTest = `{
+        "title": "products",
+        "properties": {
+          "name_an": { "type": "string", "analyzer": { "type": "keyword" } },
+          "name_si": { "type": "string", "searchIndex": true, "analyzer": { "type": "language", "language": "fr" } }
+		}}`
+
 	noGoTagSchema = `{
         "title": "products",
         "properties": {
@@ -231,6 +231,18 @@ export class Product {
   @Field()
   subtype: Subtype;
 }
+`,
+		},
+		{
+			"analyzer", analyzerTest, `
+export class Product {
+  @Field()
+  name_an: string;
+
+  @Field()
+  @SearchField({analyzer: "language", language: "fr"})
+  name_si: string;
+}
 `,
 		},
 	}
@@ -0,0 +1,76 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"github.com/tigrisdata/tigris/errors"
+)
+
+var publishTriggerOps = []string{"insert", "replace", "update", "delete"}
+
+// PublishTrigger publishes a realtime message to a channel of the project for every change of the documents of the
+// collection. The message is enqueued in the transaction changing the documents so that it is published if and only
+// if the transaction commits.
+type PublishTrigger struct {
+	// Channel is the realtime channel the messages are published to.
+	Channel string `json:"channel"`
+	// Name is the name of the messages, the operation of the change if not set.
+	Name string `json:"name,omitempty"`
+	// Ops are the operations publishing a message, all of them if not set.
+	Ops []string `json:"ops,omitempty"`
+}
+
+// Triggers returns true if the change operation publishes a message.
+func (p *PublishTrigger) Triggers(op string) bool {
+	if p == nil {
+		return false
+	}
+	if len(p.Ops) == 0 {
+		return true
+	}
+
+	for _, o := range p.Ops {
+		if o == op {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *PublishTrigger) Validate() error {
+	if p == nil {
+		return nil
+	}
+
+	if len(p.Channel) == 0 {
+		return errors.InvalidArgument("publish 'channel' is required")
+	}
+
+	for _, op := range p.Ops {
+		found := false
+		for _, supported := range publishTriggerOps {
+			if op == supported {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.InvalidArgument("unsupported publish op '%s', expecting one of %v", op, publishTriggerOps)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishTrigger(t *testing.T) {
+	var p *PublishTrigger
+	require.False(t, p.Triggers("insert"))
+	require.NoError(t, p.Validate())
+
+	p = &PublishTrigger{Channel: "orders"}
+	require.True(t, p.Triggers("insert"))
+	require.True(t, p.Triggers("delete"))
+	require.NoError(t, p.Validate())
+
+	p = &PublishTrigger{Channel: "orders", Ops: []string{"insert", "update"}}
+	require.True(t, p.Triggers("update"))
+	require.False(t, p.Triggers("delete"))
+	require.NoError(t, p.Validate())
+
+	for _, invalid := range []*PublishTrigger{
+		{},
+		{Channel: "orders", Ops: []string{"upsert"}},
+	} {
+		require.Error(t, invalid.Validate())
+	}
+
+	factory, err := NewFactoryBuilder(true).Build("t1", []byte(`{"title": "t1", "properties": {"a": {"type": "string"}}, "primary_key": ["a"], "publish": {"channel": "orders", "ops": ["insert"]}}`))
+	require.NoError(t, err)
+	require.Equal(t, &PublishTrigger{Channel: "orders", Ops: []string{"insert"}}, factory.Publish)
+
+	_, err = NewFactoryBuilder(true).Build("t1", []byte(`{"title": "t1", "properties": {"a": {"type": "string"}}, "primary_key": ["a"], "publish": {"ops": ["insert"]}}`))
+	require.Error(t, err)
+}
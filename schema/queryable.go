@@ -14,11 +14,7 @@
 
 package schema
 
-import (
-	"strings"
-
-	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
-)
+import "strings"
 
 // QueryableField is internal structure used after flattening the fields i.e. the representation of the queryable field
 // is of the following form "field" OR "parent.field". This allows us to perform look faster by just checking in this
@@ -38,6 +34,8 @@ type QueryableField struct {
 	packThis       bool
 	DoNotFlatten   bool
 	Dimensions     *int
+	Embedding      *FieldEmbedding
+	Analyzer       *FieldAnalyzer
 	SearchIdField  bool
 	// This is not stored in flattened form in search
 	// but will allow filtering on array of objects.
@@ -99,7 +97,7 @@ func NewDynamicQueryableField(name string, unFlattenName string, ft FieldType) *
 	}
 }
 
-func (*QueryableFieldsBuilder) NewQueryableField(name string, f *Field, fieldsInSearch []tsApi.Field) *QueryableField {
+func (*QueryableFieldsBuilder) NewQueryableField(name string, f *Field, fieldsInSearch []SearchStoreField) *QueryableField {
 	var (
 		searchType    string
 		faceted       = f.Faceted
@@ -146,6 +144,8 @@ func (*QueryableFieldsBuilder) NewQueryableField(name string, f *Field, fieldsIn
 		PrimaryIndexed: f.IsPrimaryKey(),
 		SearchIdField:  f.IsSearchId(),
 		Dimensions:     f.Dimensions,
+		Embedding:      f.Embedding,
+		Analyzer:       f.Analyzer,
 		UnFlattenName:  f.Name(),
 	}
 	if !packThis && f.DataType == ArrayType && len(f.Fields) > 0 && f.Fields[0].DataType == ObjectType {
@@ -192,7 +192,7 @@ func (*QueryableFieldsBuilder) NewQueryableField(name string, f *Field, fieldsIn
 	return q
 }
 
-func (builder *QueryableFieldsBuilder) BuildQueryableFields(fields []*Field, fieldsInSearch []tsApi.Field, indexMetadata bool) []*QueryableField {
+func (builder *QueryableFieldsBuilder) BuildQueryableFields(fields []*Field, fieldsInSearch []SearchStoreField, indexMetadata bool) []*QueryableField {
 	var queryableFields []*QueryableField
 
 	for _, f := range fields {
@@ -230,7 +230,7 @@ func (builder *QueryableFieldsBuilder) BuildQueryableFields(fields []*Field, fie
 	return queryableFields
 }
 
-func (builder *QueryableFieldsBuilder) buildQueryableForObject(parent string, fields []*Field, fieldsInSearch []tsApi.Field) []*QueryableField {
+func (builder *QueryableFieldsBuilder) buildQueryableForObject(parent string, fields []*Field, fieldsInSearch []SearchStoreField) []*QueryableField {
 	var queryable []*QueryableField
 	for _, nested := range fields {
 		if nested.DataType == ObjectType {
@@ -247,7 +247,7 @@ func (builder *QueryableFieldsBuilder) buildQueryableForObject(parent string, fi
 	return queryable
 }
 
-func (builder *QueryableFieldsBuilder) buildQueryableField(parent string, f *Field, fieldsInSearch []tsApi.Field) *QueryableField {
+func (builder *QueryableFieldsBuilder) buildQueryableField(parent string, f *Field, fieldsInSearch []SearchStoreField) *QueryableField {
 	name := f.FieldName
 	if len(parent) > 0 {
 		name = parent + ObjFlattenDelimiter + f.FieldName
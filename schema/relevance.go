@@ -0,0 +1,151 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	jsoniter "github.com/json-iterator/go"
+	"github.com/tigrisdata/tigris/errors"
+)
+
+const (
+	maxSearchTypos       = 2
+	maxSearchFieldWeight = 127
+)
+
+// SearchRelevance tunes how the full text search matches and ranks the documents. The options can be set in the
+// schema as the defaults of the index and in the search requests. The options that are not set keep the index
+// defaults and then the search store defaults.
+type SearchRelevance struct {
+	// NumTypos is the number of typos tolerated in a word, between 0 and 2.
+	NumTypos *int `json:"num_typos,omitempty"`
+	// MinLen1Typo is the minimum length of a word to tolerate one typo.
+	MinLen1Typo *int `json:"min_len_1typo,omitempty"`
+	// MinLen2Typo is the minimum length of a word to tolerate two typos.
+	MinLen2Typo *int `json:"min_len_2typo,omitempty"`
+	// Prefix matches the last word of the query as a prefix.
+	Prefix *bool `json:"prefix,omitempty"`
+	// FieldWeights boosts the matches in some of the searched fields, the other fields have a weight of 1.
+	FieldWeights map[string]int `json:"field_weights,omitempty"`
+	// DropTokensThreshold is the number of results under which the words of the query are dropped, starting from the
+	// right, until enough documents match. Zero disables it.
+	DropTokensThreshold *int `json:"drop_tokens_threshold,omitempty"`
+	// PrioritizeExactMatch ranks the documents matching the query exactly first.
+	PrioritizeExactMatch *bool `json:"prioritize_exact_match,omitempty"`
+}
+
+func UnmarshalSearchRelevance(input jsoniter.RawMessage) (*SearchRelevance, error) {
+	if len(input) == 0 {
+		return nil, nil
+	}
+
+	var r SearchRelevance
+	if err := jsoniter.Unmarshal(input, &r); err != nil {
+		return nil, errors.InvalidArgument("invalid search relevance '%s'", err.Error())
+	}
+
+	return &r, nil
+}
+
+// Merge returns the options of the request completed by the defaults of the index, the field weights are merged.
+func (r *SearchRelevance) Merge(defaults *SearchRelevance) *SearchRelevance {
+	if r == nil && defaults == nil {
+		return nil
+	}
+
+	merged := &SearchRelevance{}
+	for _, src := range []*SearchRelevance{defaults, r} {
+		if src == nil {
+			continue
+		}
+		if src.NumTypos != nil {
+			merged.NumTypos = src.NumTypos
+		}
+		if src.MinLen1Typo != nil {
+			merged.MinLen1Typo = src.MinLen1Typo
+		}
+		if src.MinLen2Typo != nil {
+			merged.MinLen2Typo = src.MinLen2Typo
+		}
+		if src.Prefix != nil {
+			merged.Prefix = src.Prefix
+		}
+		if src.DropTokensThreshold != nil {
+			merged.DropTokensThreshold = src.DropTokensThreshold
+		}
+		if src.PrioritizeExactMatch != nil {
+			merged.PrioritizeExactMatch = src.PrioritizeExactMatch
+		}
+		for field, weight := range src.FieldWeights {
+			if merged.FieldWeights == nil {
+				merged.FieldWeights = make(map[string]int)
+			}
+			merged.FieldWeights[field] = weight
+		}
+	}
+
+	return merged
+}
+
+// Validate checks the options against the queryable fields of the index. The field weights are keyed by the name of
+// the fields in the search store once validated.
+func (r *SearchRelevance) Validate(fields []*QueryableField) error {
+	if r == nil {
+		return nil
+	}
+
+	if r.NumTypos != nil && (*r.NumTypos < 0 || *r.NumTypos > maxSearchTypos) {
+		return errors.InvalidArgument("'num_typos' must be between 0 and %d", maxSearchTypos)
+	}
+	if r.MinLen1Typo != nil && *r.MinLen1Typo < 1 {
+		return errors.InvalidArgument("'min_len_1typo' must be positive")
+	}
+	if r.MinLen2Typo != nil && *r.MinLen2Typo < 1 {
+		return errors.InvalidArgument("'min_len_2typo' must be positive")
+	}
+	if r.MinLen1Typo != nil && r.MinLen2Typo != nil && *r.MinLen1Typo > *r.MinLen2Typo {
+		return errors.InvalidArgument("'min_len_1typo' can't be greater than 'min_len_2typo'")
+	}
+	if r.DropTokensThreshold != nil && *r.DropTokensThreshold < 0 {
+		return errors.InvalidArgument("'drop_tokens_threshold' can't be negative")
+	}
+
+	weights := make(map[string]int, len(r.FieldWeights))
+	for name, weight := range r.FieldWeights {
+		if weight < 0 || weight > maxSearchFieldWeight {
+			return errors.InvalidArgument("weight of field '%s' must be between 0 and %d", name, maxSearchFieldWeight)
+		}
+
+		var field *QueryableField
+		for _, f := range fields {
+			if f.Name() == name {
+				field = f
+				break
+			}
+		}
+		if field == nil {
+			return errors.InvalidArgument("weighted field '%s' is not present in the schema", name)
+		}
+		if !field.SearchIndexed || (field.DataType != StringType && field.SubType != StringType) {
+			return errors.InvalidArgument("weighted field '%s' is not a searchable text field", name)
+		}
+
+		weights[field.InMemoryName()] = weight
+	}
+	if len(weights) > 0 {
+		r.FieldWeights = weights
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchRelevance_Merge(t *testing.T) {
+	var r *SearchRelevance
+	require.Nil(t, r.Merge(nil))
+
+	one, two, prefix := 1, 2, false
+	defaults := &SearchRelevance{NumTypos: &two, Prefix: &prefix, FieldWeights: map[string]int{"title": 5, "body": 2}}
+	r = &SearchRelevance{NumTypos: &one, FieldWeights: map[string]int{"title": 3}}
+
+	merged := r.Merge(defaults)
+	require.Equal(t, &SearchRelevance{
+		NumTypos:     &one,
+		Prefix:       &prefix,
+		FieldWeights: map[string]int{"title": 3, "body": 2},
+	}, merged)
+	// the inputs are not modified
+	require.Equal(t, map[string]int{"title": 5, "body": 2}, defaults.FieldWeights)
+
+	require.Equal(t, defaults, (*SearchRelevance)(nil).Merge(defaults))
+}
+
+func TestSearchRelevance_Validate(t *testing.T) {
+	fields := []*QueryableField{
+		{FieldName: "id", InMemoryAlias: "_tigris_id", DataType: StringType, SearchIndexed: true},
+		{FieldName: "title", InMemoryAlias: "title", DataType: StringType, SearchIndexed: true},
+		{FieldName: "tags", InMemoryAlias: "tags", DataType: ArrayType, SubType: StringType, SearchIndexed: true},
+		{FieldName: "price", InMemoryAlias: "price", DataType: Int64Type, SearchIndexed: true},
+		{FieldName: "notes", InMemoryAlias: "notes", DataType: StringType},
+	}
+	intP := func(i int) *int { return &i }
+
+	r := &SearchRelevance{NumTypos: intP(1), MinLen1Typo: intP(3), MinLen2Typo: intP(6), FieldWeights: map[string]int{"id": 2, "tags": 1}}
+	require.NoError(t, r.Validate(fields))
+	require.Equal(t, map[string]int{"_tigris_id": 2, "tags": 1}, r.FieldWeights)
+
+	require.NoError(t, (*SearchRelevance)(nil).Validate(fields))
+
+	for _, invalid := range []*SearchRelevance{
+		{NumTypos: intP(3)},
+		{MinLen1Typo: intP(0)},
+		{MinLen1Typo: intP(5), MinLen2Typo: intP(4)},
+		{DropTokensThreshold: intP(-1)},
+		{FieldWeights: map[string]int{"title": 128}},
+		{FieldWeights: map[string]int{"unknown": 1}},
+		{FieldWeights: map[string]int{"price": 1}},
+		{FieldWeights: map[string]int{"notes": 1}},
+	} {
+		require.Error(t, invalid.Validate(fields))
+	}
+}
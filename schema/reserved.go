@@ -29,6 +29,7 @@ const (
 	DateSearchKeyPrefix
 	SearchArrNullItem
 	SearchNullKeys
+	SearchVersion
 )
 
 var ReservedFields = [...]string{
@@ -39,6 +40,7 @@ var ReservedFields = [...]string{
 	DateSearchKeyPrefix: TigrisFieldsPrefix + "date_",
 	SearchArrNullItem:   TigrisFieldsPrefix + "null",
 	SearchNullKeys:      TigrisFieldsPrefix + "null_keys",
+	SearchVersion:       TigrisFieldsPrefix + "version",
 }
 
 func IsReservedField(name string) bool {
@@ -116,6 +116,9 @@ type JSONSchema struct {
 	PrimaryKeys    []string            `json:"primary_key,omitempty"`
 	CollectionType string              `json:"collection_type,omitempty"`
 	Version        uint32              `json:"version,omitempty"`
+	Relevance      *SearchRelevance    `json:"relevance,omitempty"`
+	Cache          *CachePolicy        `json:"cache,omitempty"`
+	Publish        *PublishTrigger     `json:"publish,omitempty"`
 }
 
 // Factory is used as an intermediate step so that collection can be initialized with properly encoded values.
@@ -135,6 +138,12 @@ type Factory struct {
 	// CollectionType is the type of the collection. Only two types of collections are supported "messages" and "documents"
 	CollectionType CollectionType
 	Version        uint32
+	// Relevance is the default relevance of the search queries on the collection.
+	Relevance *SearchRelevance
+	// Cache is the policy of caching the documents of the collection.
+	Cache *CachePolicy
+	// Publish is the trigger publishing the changes of the documents to a realtime channel.
+	Publish *PublishTrigger
 }
 
 func (f *Factory) SecondaryIndexes() []*Index {
@@ -243,6 +252,9 @@ func (fb *FactoryBuilder) Build(collection string, reqSchema jsoniter.RawMessage
 		Schema:         reqSchema,
 		CollectionType: cType,
 		Version:        schema.Version,
+		Relevance:      schema.Relevance,
+		Cache:          schema.Cache,
+		Publish:        schema.Publish,
 	}
 
 	if fb.onUserRequest {
@@ -261,7 +273,23 @@ func (*FactoryBuilder) validateSchema(factory *Factory) error {
 		}
 	}
 
-	return nil
+	if err := ValidateEmbeddings(factory.Fields); err != nil {
+		return err
+	}
+
+	if err := ValidateAnalyzers(factory.Fields); err != nil {
+		return err
+	}
+
+	if err := factory.Cache.Validate(); err != nil {
+		return err
+	}
+
+	if err := factory.Publish.Validate(); err != nil {
+		return err
+	}
+
+	return factory.Relevance.Merge(nil).Validate(NewQueryableFieldsBuilder().BuildQueryableFields(factory.Fields, nil, false))
 }
 
 func setPrimaryKey(reqSchema jsoniter.RawMessage, format string, ifMissing bool) (jsoniter.RawMessage, error) {
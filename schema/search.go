@@ -20,7 +20,6 @@ import (
 	jsoniter "github.com/json-iterator/go"
 	api "github.com/tigrisdata/tigris/api/server/v1"
 	"github.com/tigrisdata/tigris/errors"
-	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
 )
 
 // SearchIndexState represents the search state of collection search.
@@ -65,6 +64,7 @@ type SearchJSONSchema struct {
 	Description string              `json:"description,omitempty"`
 	Properties  jsoniter.RawMessage `json:"properties,omitempty"`
 	Source      *SearchSource       `json:"source,omitempty"`
+	Relevance   *SearchRelevance    `json:"relevance,omitempty"`
 }
 
 // SearchFactory is used as an intermediate step so that collection can be initialized with properly encoded values.
@@ -77,6 +77,8 @@ type SearchFactory struct {
 	Schema jsoniter.RawMessage
 	Sub    string
 	Source SearchSource
+	// Relevance is the default relevance of the search queries on the index.
+	Relevance *SearchRelevance
 }
 
 func (fb *FactoryBuilder) BuildSearch(index string, reqSchema jsoniter.RawMessage) (*SearchFactory, error) {
@@ -122,10 +124,11 @@ func (fb *FactoryBuilder) BuildSearch(index string, reqSchema jsoniter.RawMessag
 	}
 
 	factory := &SearchFactory{
-		Name:   index,
-		Fields: fields,
-		Schema: searchSchema,
-		Source: source,
+		Name:      index,
+		Fields:    fields,
+		Schema:    searchSchema,
+		Source:    source,
+		Relevance: schema.Relevance,
 	}
 
 	idFound := false
@@ -162,7 +165,38 @@ func (*FactoryBuilder) validateSearchSchema(factory *SearchFactory) error {
 		}
 	}
 
-	return nil
+	if err := ValidateEmbeddings(factory.Fields); err != nil {
+		return err
+	}
+
+	if err := ValidateAnalyzers(factory.Fields); err != nil {
+		return err
+	}
+
+	return factory.Relevance.Merge(nil).Validate(NewQueryableFieldsBuilder().BuildQueryableFields(factory.Fields, nil, true))
+}
+
+// SearchStoreField is a field of an index in the search store. Nested fields are flattened, so Name is the flattened
+// name of the field and Type is its search type. The options are pointers so that an unset option keeps the default
+// of the search store.
+type SearchStoreField struct {
+	Name     string  `json:"name"`
+	Type     string  `json:"type"`
+	Facet    *bool   `json:"facet,omitempty"`
+	Index    *bool   `json:"index,omitempty"`
+	Sort     *bool   `json:"sort,omitempty"`
+	Optional *bool   `json:"optional,omitempty"`
+	Infix    *bool   `json:"infix,omitempty"`
+	Locale   *string `json:"locale,omitempty"`
+	NumDim   *int    `json:"num_dim,omitempty"`
+	// Drop is only set in a schema update, it removes the field from the index.
+	Drop *bool `json:"drop,omitempty"`
+}
+
+// SearchStoreSchema is the schema of an index in the search store.
+type SearchStoreSchema struct {
+	Name   string             `json:"name"`
+	Fields []SearchStoreField `json:"fields"`
 }
 
 // SearchIndex is to manage search index created by the user.
@@ -176,7 +210,7 @@ type SearchIndex struct {
 	// JSON schema.
 	Schema jsoniter.RawMessage
 	// StoreSchema is the search schema of the underlying search engine.
-	StoreSchema *tsApi.CollectionSchema
+	StoreSchema *SearchStoreSchema
 	// QueryableFields are similar to Fields but these are flattened forms of fields. For instance, a simple field
 	// will be one to one mapped to queryable field but complex fields like object type field there may be more than
 	// one queryableFields. As queryableFields represent a flattened state these can be used as-is to index in memory.
@@ -184,12 +218,18 @@ type SearchIndex struct {
 	// Source of this index
 	Source        SearchSource
 	SearchIDField *QueryableField
+	// Stopwords are removed from the search queries, these are the words of all the stopword sets of the index.
+	Stopwords []string
+	// CurationRules are applied to the search queries matching their pattern.
+	CurationRules []CurationRule
+	// Relevance is the default relevance of the search queries on the index.
+	Relevance *SearchRelevance
 	// Track all the int64 paths in the collection. For example, if top level object has an int64 field then key would be
 	// obj.fieldName so that caller can easily navigate to this field.
 	int64FieldsPath *int64PathBuilder
 }
 
-func NewSearchIndex(ver uint32, searchStoreName string, factory *SearchFactory, fieldsInSearch []tsApi.Field) *SearchIndex {
+func NewSearchIndex(ver uint32, searchStoreName string, factory *SearchFactory, fieldsInSearch []SearchStoreField) *SearchIndex {
 	queryableFields := NewQueryableFieldsBuilder().BuildQueryableFields(factory.Fields, fieldsInSearch, true)
 
 	var searchIdField *QueryableField
@@ -204,6 +244,7 @@ func NewSearchIndex(ver uint32, searchStoreName string, factory *SearchFactory,
 		Fields:          factory.Fields,
 		Schema:          factory.Schema,
 		Source:          factory.Source,
+		Relevance:       factory.Relevance,
 		SearchIDField:   searchIdField,
 		QueryableFields: queryableFields,
 		int64FieldsPath: buildInt64Path(factory.Fields),
@@ -289,9 +330,9 @@ func (s *SearchIndex) validateLow(value any, keys []string, field *Field) error
 
 func (s *SearchIndex) buildSearchSchema(name string) {
 	ptrTrue, ptrFalse := true, false
-	tsFields := make([]tsApi.Field, 0, len(s.QueryableFields))
+	storeFields := make([]SearchStoreField, 0, len(s.QueryableFields))
 	for _, s := range s.QueryableFields {
-		tsFields = append(tsFields, tsApi.Field{
+		storeFields = append(storeFields, s.Analyzer.applyTo(SearchStoreField{
 			Name:     s.Name(),
 			Type:     s.SearchType,
 			Facet:    &s.Faceted,
@@ -299,23 +340,23 @@ func (s *SearchIndex) buildSearchSchema(name string) {
 			Sort:     &s.Sortable,
 			Optional: &ptrTrue,
 			NumDim:   s.Dimensions,
-		})
+		}))
 
 		if s.InMemoryName() != s.Name() {
 			// we are storing this field differently in in-memory store
-			tsFields = append(tsFields, tsApi.Field{
+			storeFields = append(storeFields, s.Analyzer.applyTo(SearchStoreField{
 				Name:     s.InMemoryName(),
 				Type:     s.SearchType,
 				Facet:    &s.Faceted,
 				Index:    &s.SearchIndexed,
 				Sort:     &s.Sortable,
 				Optional: &ptrTrue,
-			})
+			}))
 		}
 
 		// Save original date as string to disk
 		if !s.IsReserved() && s.DataType == DateTimeType {
-			tsFields = append(tsFields, tsApi.Field{
+			storeFields = append(storeFields, SearchStoreField{
 				Name:     ToSearchDateKey(s.Name()),
 				Type:     toSearchFieldType(StringType, UnknownType),
 				Facet:    &ptrFalse,
@@ -326,13 +367,13 @@ func (s *SearchIndex) buildSearchSchema(name string) {
 		}
 	}
 
-	s.StoreSchema = &tsApi.CollectionSchema{
+	s.StoreSchema = &SearchStoreSchema{
 		Name:   name,
-		Fields: tsFields,
+		Fields: storeFields,
 	}
 }
 
-func (s *SearchIndex) GetSearchDeltaFields(existingFields []*QueryableField, fieldsInSearch []tsApi.Field) []tsApi.Field {
+func (s *SearchIndex) GetSearchDeltaFields(existingFields []*QueryableField, fieldsInSearch []SearchStoreField) []SearchStoreField {
 	ptrTrue := true
 
 	incomingQueryable := NewQueryableFieldsBuilder().BuildQueryableFields(s.Fields, fieldsInSearch, true)
@@ -342,30 +383,31 @@ func (s *SearchIndex) GetSearchDeltaFields(existingFields []*QueryableField, fie
 		existingFieldMap[f.FieldName] = f
 	}
 
-	fieldsInSearchMap := make(map[string]tsApi.Field)
+	fieldsInSearchMap := make(map[string]SearchStoreField)
 	for _, f := range fieldsInSearch {
 		fieldsInSearchMap[f.Name] = f
 	}
 
-	tsFields := make([]tsApi.Field, 0, len(incomingQueryable))
+	storeFields := make([]SearchStoreField, 0, len(incomingQueryable))
 	for _, f := range incomingQueryable {
 		e := existingFieldMap[f.FieldName]
 		delete(existingFieldMap, f.FieldName)
 
-		if e != nil && f.SearchType == e.SearchType && f.SearchIndexed == e.SearchIndexed && f.Faceted == e.Faceted && f.Sortable == e.Sortable {
+		if e != nil && f.SearchType == e.SearchType && f.SearchIndexed == e.SearchIndexed && f.Faceted == e.Faceted && f.Sortable == e.Sortable &&
+			f.Analyzer.Equal(e.Analyzer) {
 			continue
 		}
 
 		// attribute changed, drop the field first
 		if e != nil {
-			tsFields = append(tsFields, tsApi.Field{
+			storeFields = append(storeFields, SearchStoreField{
 				Name: f.FieldName,
 				Drop: &ptrTrue,
 			})
 		} else {
 			// this can happen if update request is timed out on Tigris side but succeed on search
 			if _, found := fieldsInSearchMap[f.FieldName]; found {
-				tsFields = append(tsFields, tsApi.Field{
+				storeFields = append(storeFields, SearchStoreField{
 					Name: f.FieldName,
 					Drop: &ptrTrue,
 				})
@@ -373,7 +415,7 @@ func (s *SearchIndex) GetSearchDeltaFields(existingFields []*QueryableField, fie
 		}
 
 		// add new field
-		tsFields = append(tsFields, tsApi.Field{
+		storeFields = append(storeFields, f.Analyzer.applyTo(SearchStoreField{
 			Name:     f.FieldName,
 			Type:     f.SearchType,
 			Facet:    &f.Faceted,
@@ -381,20 +423,20 @@ func (s *SearchIndex) GetSearchDeltaFields(existingFields []*QueryableField, fie
 			Sort:     &f.Sortable,
 			Optional: &ptrTrue,
 			NumDim:   f.Dimensions,
-		})
+		}))
 	}
 
 	// drop fields non existing in new schema
 	for _, f := range existingFieldMap {
-		tsField := tsApi.Field{
+		storeField := SearchStoreField{
 			Name: f.FieldName,
 			Drop: &ptrTrue,
 		}
 
-		tsFields = append(tsFields, tsField)
+		storeFields = append(storeFields, storeField)
 	}
 
-	return tsFields
+	return storeFields
 }
 
 func (s *SearchIndex) GetInt64FieldsPath() map[string]struct{} {
@@ -407,17 +449,17 @@ type ImplicitSearchIndex struct {
 	// Name is the name of the index.
 	Name string
 	// StoreSchema is the search schema of the underlying search engine.
-	StoreSchema *tsApi.CollectionSchema
+	StoreSchema *SearchStoreSchema
 	// QueryableFields are similar to Fields but these are flattened forms of fields. For instance, a simple field
 	// will be one to one mapped to queryable field but complex fields like object type field there may be more than
 	// one queryableFields. As queryableFields represent a flattened state these can be used as-is to index in memory.
 	QueryableFields     []*QueryableField
-	prevVersionInSearch []tsApi.Field
+	prevVersionInSearch []SearchStoreField
 	// State will start tracking whether collection search index is active or not
 	state SearchIndexState
 }
 
-func NewImplicitSearchIndex(name string, searchStoreName string, fields []*Field, prevVersionInSearch []tsApi.Field) *ImplicitSearchIndex {
+func NewImplicitSearchIndex(name string, searchStoreName string, fields []*Field, prevVersionInSearch []SearchStoreField) *ImplicitSearchIndex {
 	// this is created by collection so the forSearchIndex is false.
 	queryableFields := NewQueryableFieldsBuilder().BuildQueryableFields(fields, prevVersionInSearch, false)
 	index := &ImplicitSearchIndex{
@@ -455,7 +497,7 @@ func (s *ImplicitSearchIndex) StoreIndexName() string {
 
 func (s *ImplicitSearchIndex) buildSearchSchema(searchStoreName string) {
 	ptrTrue, ptrFalse := true, false
-	tsFields := make([]tsApi.Field, 0, len(s.QueryableFields))
+	storeFields := make([]SearchStoreField, 0, len(s.QueryableFields))
 
 	for _, f := range s.QueryableFields {
 		// the implicit search index by default index all the fields that are indexable and same applies to facet/sort.
@@ -471,7 +513,7 @@ func (s *ImplicitSearchIndex) buildSearchSchema(searchStoreName string) {
 			shouldFacet = true
 		}
 
-		tsFields = append(tsFields, tsApi.Field{
+		storeFields = append(storeFields, f.Analyzer.applyTo(SearchStoreField{
 			Name:     f.Name(),
 			Type:     f.SearchType,
 			Facet:    &shouldFacet,
@@ -479,22 +521,22 @@ func (s *ImplicitSearchIndex) buildSearchSchema(searchStoreName string) {
 			Sort:     &shouldSort,
 			Optional: &ptrTrue,
 			NumDim:   f.Dimensions,
-		})
+		}))
 
 		if f.InMemoryName() != f.Name() {
 			// we are storing this field differently in in-memory store
-			tsFields = append(tsFields, tsApi.Field{
+			storeFields = append(storeFields, f.Analyzer.applyTo(SearchStoreField{
 				Name:     f.InMemoryName(),
 				Type:     f.SearchType,
 				Facet:    &shouldFacet,
 				Index:    &shouldIndex,
 				Sort:     &shouldSort,
 				Optional: &ptrTrue,
-			})
+			}))
 		}
 		// Save original date as string to disk
 		if !f.IsReserved() && f.DataType == DateTimeType {
-			tsFields = append(tsFields, tsApi.Field{
+			storeFields = append(storeFields, SearchStoreField{
 				Name:     ToSearchDateKey(f.Name()),
 				Type:     toSearchFieldType(StringType, UnknownType),
 				Facet:    &ptrFalse,
@@ -505,13 +547,13 @@ func (s *ImplicitSearchIndex) buildSearchSchema(searchStoreName string) {
 		}
 	}
 
-	s.StoreSchema = &tsApi.CollectionSchema{
+	s.StoreSchema = &SearchStoreSchema{
 		Name:   searchStoreName,
-		Fields: tsFields,
+		Fields: storeFields,
 	}
 }
 
-func (s *ImplicitSearchIndex) GetSearchDeltaFields(existingFields []*QueryableField, incomingFields []*Field) []tsApi.Field {
+func (s *ImplicitSearchIndex) GetSearchDeltaFields(existingFields []*QueryableField, incomingFields []*Field) []SearchStoreField {
 	ptrTrue := true
 
 	incomingQueryable := NewQueryableFieldsBuilder().BuildQueryableFields(incomingFields, s.prevVersionInSearch, false)
@@ -521,12 +563,12 @@ func (s *ImplicitSearchIndex) GetSearchDeltaFields(existingFields []*QueryableFi
 		existingFieldMap[f.FieldName] = f
 	}
 
-	fieldsInSearchMap := make(map[string]tsApi.Field)
+	fieldsInSearchMap := make(map[string]SearchStoreField)
 	for _, f := range s.prevVersionInSearch {
 		fieldsInSearchMap[f.Name] = f
 	}
 
-	tsFields := make([]tsApi.Field, 0, len(incomingQueryable))
+	storeFields := make([]SearchStoreField, 0, len(incomingQueryable))
 	for _, f := range incomingQueryable {
 		e := existingFieldMap[f.FieldName]
 		delete(existingFieldMap, f.FieldName)
@@ -553,6 +595,9 @@ func (s *ImplicitSearchIndex) GetSearchDeltaFields(existingFields []*QueryableFi
 			if found && inSearchState.Sort != nil && *inSearchState.Sort != shouldSort {
 				stateChanged = true
 			}
+			if found && analyzerChanged(f.Analyzer, inSearchState) {
+				stateChanged = true
+			}
 
 			if !stateChanged {
 				continue
@@ -561,14 +606,14 @@ func (s *ImplicitSearchIndex) GetSearchDeltaFields(existingFields []*QueryableFi
 
 		// attribute changed, drop the field first
 		if e != nil && stateChanged {
-			tsFields = append(tsFields, tsApi.Field{
+			storeFields = append(storeFields, SearchStoreField{
 				Name: f.FieldName,
 				Drop: &ptrTrue,
 			})
 		} else {
 			// this can happen if update request is timed out on Tigris side but succeed on search
 			if _, found := fieldsInSearchMap[f.FieldName]; found {
-				tsFields = append(tsFields, tsApi.Field{
+				storeFields = append(storeFields, SearchStoreField{
 					Name: f.FieldName,
 					Drop: &ptrTrue,
 				})
@@ -576,7 +621,7 @@ func (s *ImplicitSearchIndex) GetSearchDeltaFields(existingFields []*QueryableFi
 		}
 
 		// add new field
-		tsFields = append(tsFields, tsApi.Field{
+		storeFields = append(storeFields, f.Analyzer.applyTo(SearchStoreField{
 			Name:     f.FieldName,
 			Type:     f.SearchType,
 			Facet:    &shouldFacet,
@@ -584,18 +629,18 @@ func (s *ImplicitSearchIndex) GetSearchDeltaFields(existingFields []*QueryableFi
 			Sort:     &shouldSort,
 			Optional: &ptrTrue,
 			NumDim:   f.Dimensions,
-		})
+		}))
 	}
 
 	// drop fields non existing in new schema
 	for _, f := range existingFieldMap {
-		tsField := tsApi.Field{
+		storeField := SearchStoreField{
 			Name: f.FieldName,
 			Drop: &ptrTrue,
 		}
 
-		tsFields = append(tsFields, tsField)
+		storeFields = append(storeFields, storeField)
 	}
 
-	return tsFields
+	return storeFields
 }
@@ -153,6 +153,62 @@ func TestSearchIndex_Schema(t *testing.T) {
 			[]byte(`{"title": "t1", "properties": { "a": {"type": "string"}, "b": {"type": "array", "format": "vector", "dimensions": 4}}}`),
 			"",
 		},
+		{
+			[]byte(`{"title": "t1", "properties": { "a": {"type": "string"}, "o": {"type": "object", "properties": {"c": {"type": "string"}}}, "b": {"type": "array", "format": "vector", "dimensions": 4, "embedding": {"source": ["a", "o.c"], "provider": "hash"}}}}`),
+			"",
+		},
+		{
+			[]byte(`{"title": "t1", "properties": { "a": {"type": "string"}, "b": {"type": "array", "format": "vector", "dimensions": 0, "embedding": {"source": ["a"]}}}}`),
+			"embedding of field 'b' needs the dimensions of the vector",
+		},
+		{
+			[]byte(`{"title": "t1", "properties": { "a": {"type": "string", "embedding": {"source": ["a"]}}}}`),
+			"embedding is only supported on vector fields, field 'a' is not a vector",
+		},
+		{
+			[]byte(`{"title": "t1", "properties": { "a": {"type": "integer"}, "b": {"type": "array", "format": "vector", "dimensions": 4, "embedding": {"source": ["a"]}}}}`),
+			"embedding source 'a' of field 'b' is not a string",
+		},
+		{
+			[]byte(`{"title": "t1", "properties": { "a": {"type": "string"}, "b": {"type": "array", "format": "vector", "dimensions": 4, "embedding": {"source": ["c"]}}}}`),
+			"embedding source 'c' of field 'b' is not in the schema",
+		},
+		{
+			[]byte(`{"title": "t1", "properties": { "a": {"type": "string"}, "b": {"type": "array", "format": "vector", "dimensions": 4, "embedding": {"source": ["a"], "provider": "unknown"}}}}`),
+			"unsupported embedding provider 'unknown' of field 'b'",
+		},
+		{
+			[]byte(`{"title": "t1", "properties": { "a": {"type": "string", "analyzer": {"type": "keyword"}}, "b": {"type": "array", "items": {"type": "string"}, "analyzer": {"type": "edge_ngram"}}, "c": {"type": "string", "analyzer": {"type": "language", "language": "fr"}}}}`),
+			"",
+		},
+		{
+			[]byte(`{"title": "t1", "properties": { "a": {"type": "integer", "analyzer": {"type": "keyword"}}}}`),
+			"analyzer is only supported on string fields, field 'a' is not a string",
+		},
+		{
+			[]byte(`{"title": "t1", "properties": { "a": {"type": "string", "analyzer": {"type": "snowball"}}}}`),
+			"unsupported analyzer 'snowball' of field 'a'",
+		},
+		{
+			[]byte(`{"title": "t1", "properties": { "a": {"type": "string", "analyzer": {"type": "language"}}}}`),
+			"analyzer of field 'a' is missing the language",
+		},
+		{
+			[]byte(`{"title": "t1", "properties": { "a": {"type": "string", "analyzer": {"type": "language", "language": "xx"}}}}`),
+			"unsupported analyzer language 'xx' of field 'a'",
+		},
+		{
+			[]byte(`{"title": "t1", "properties": { "a": {"type": "string", "analyzer": {"type": "ngram", "language": "en"}}}}`),
+			"language is only supported by the 'language' analyzer, field 'a'",
+		},
+		{
+			[]byte(`{"title": "t1", "properties": { "a": {"type": "string"}, "b": {"type": "integer"}}, "relevance": {"num_typos": 1, "prefix": false, "field_weights": {"a": 3}}}`),
+			"",
+		},
+		{
+			[]byte(`{"title": "t1", "properties": { "a": {"type": "string"}, "b": {"type": "integer"}}, "relevance": {"field_weights": {"b": 3}}}`),
+			"weighted field 'b' is not a searchable text field",
+		},
 		{
 			[]byte(`{"title": "t1", "properties": { "a": {"type": "string", "id": true}, "b": {"type": "array", "items": {"type": "integer"}}}}`),
 			"",
@@ -303,4 +359,43 @@ func TestSearchIndex_Validate(t *testing.T) {
 			require.NoError(t, idx.Validate(mp))
 		}
 	}
-}
\ No newline at end of file
+}
+func TestSearchIndex_Analyzers(t *testing.T) {
+	factory, err := NewFactoryBuilder(true).BuildSearch("t1", []byte(`{"title": "t1", "properties": {
+		"sku": {"type": "string", "analyzer": {"type": "keyword"}},
+		"title": {"type": "string", "analyzer": {"type": "ngram"}},
+		"body": {"type": "string", "analyzer": {"type": "language", "language": "ja"}},
+		"name": {"type": "string"}
+	}}`))
+	require.NoError(t, err)
+
+	index := NewSearchIndex(1, "t1", factory, nil)
+	fields := make(map[string]SearchStoreField)
+	for _, f := range index.StoreSchema.Fields {
+		fields[f.Name] = f
+	}
+	require.Nil(t, fields["sku"].Locale)
+	require.Nil(t, fields["sku"].Infix)
+	require.True(t, *fields["title"].Infix)
+	require.Equal(t, "ja", *fields["body"].Locale)
+	require.Nil(t, fields["name"].Locale)
+
+	analyzers := SearchAnalyzers(index.QueryableFields)
+	require.Len(t, analyzers, 3)
+	require.True(t, analyzers["sku"].Is(AnalyzerKeyword))
+
+	updated, err := NewFactoryBuilder(true).BuildSearch("t1", []byte(`{"title": "t1", "properties": {
+		"sku": {"type": "string", "analyzer": {"type": "keyword"}},
+		"title": {"type": "string", "analyzer": {"type": "ngram"}},
+		"body": {"type": "string", "analyzer": {"type": "language", "language": "zh"}},
+		"name": {"type": "string"}
+	}}`))
+	require.NoError(t, err)
+
+	// only the field with a different analyzer is dropped and added back
+	delta := NewSearchIndex(2, "t1", updated, index.StoreSchema.Fields).GetSearchDeltaFields(index.QueryableFields, index.StoreSchema.Fields)
+	require.Len(t, delta, 2)
+	require.Equal(t, "body", delta[0].Name)
+	require.True(t, *delta[0].Drop)
+	require.Equal(t, "zh", *delta[1].Locale)
+}
@@ -46,6 +46,8 @@ type Config struct {
 	MetadataCluster ClusterConfig        `json:"metadata_cluster" mapstructure:"metadata_cluster" yaml:"metadata_cluster"`
 	Billing         Billing              `json:"billing"          yaml:"billing"`
 	Cdc             CdcConfig            `json:"cdc"              yaml:"cdc"`
+	Outbox          OutboxConfig         `json:"outbox"           yaml:"outbox"`
+	DocumentCache   DocumentCacheConfig  `json:"document_cache"   mapstructure:"document_cache"   yaml:"document_cache"`
 	Search          SearchConfig         `json:"search"           yaml:"search"`
 	KV              KVConfig             `json:"kv"               yaml:"kv"`
 	SecondaryIndex  SecondaryIndexConfig `json:"secondary_index"  mapstructure:"secondary_index"  yaml:"secondary_index"`
@@ -140,6 +142,20 @@ type CdcConfig struct {
 	StreamBuffer   int
 }
 
+// OutboxConfig controls the relay publishing the realtime messages enqueued by the database transactions. It is disabled
+// by default as the relay polls the outbox of every namespace.
+type OutboxConfig struct {
+	Enabled       bool          `json:"enabled"        mapstructure:"enabled"        yaml:"enabled"`
+	RelayInterval time.Duration `json:"relay_interval" mapstructure:"relay_interval" yaml:"relay_interval"`
+	RelayBatch    int           `json:"relay_batch"    mapstructure:"relay_batch"    yaml:"relay_batch"`
+}
+
+// DocumentCacheConfig controls the read-through cache of the documents of the collections having a cache policy. It is
+// disabled by default as the cached documents of every committed transaction have to be invalidated.
+type DocumentCacheConfig struct {
+	Enabled bool `json:"enabled" mapstructure:"enabled" yaml:"enabled"`
+}
+
 type TracingConfig struct {
 	Enabled bool                 `json:"enabled" mapstructure:"enabled" yaml:"enabled"`
 	Datadog DatadogTracingConfig `json:"datadog" mapstructure:"datadog" yaml:"datadog"`
@@ -378,6 +394,14 @@ var DefaultConfig = Config{
 		StreamBatch:    100,
 		StreamBuffer:   200,
 	},
+	Outbox: OutboxConfig{
+		Enabled:       false,
+		RelayInterval: 200 * time.Millisecond,
+		RelayBatch:    100,
+	},
+	DocumentCache: DocumentCacheConfig{
+		Enabled: false,
+	},
 	Search: SearchConfig{
 		Host:              "localhost",
 		Port:              8108,
@@ -388,11 +412,29 @@ var DefaultConfig = Config{
 		Compression:       false,
 		IgnoreExtraFields: false,
 		LogFilter:         false,
+		Backend:           SearchBackendTypesense,
+		Embedding: EmbeddingConfig{
+			HTTP: HTTPEmbeddingConfig{
+				Model:   "text-embedding-3-small",
+				Timeout: 30 * time.Second,
+			},
+		},
+		Analytics: AnalyticsConfig{
+			Enabled:        false,
+			RetentionDays:  30,
+			MaxQueryLength: 128,
+		},
 	},
 	KV: KVConfig{
 		Chunking:             false,
 		Compression:          false,
 		MinCompressThreshold: 0,
+		LongRunningTx: LongRunningTxConfig{
+			Enabled:     true,
+			Timeout:     5 * time.Minute,
+			MaxSize:     5 * 1024 * 1024,
+			MaxReadSize: 10 * 1024 * 1024,
+		},
 	},
 	SecondaryIndex: SecondaryIndexConfig{
 		ReadEnabled:   true,
@@ -400,9 +442,11 @@ var DefaultConfig = Config{
 		MutateEnabled: false,
 	},
 	Cache: CacheConfig{
-		Host:    "0.0.0.0",
-		Port:    6379,
-		MaxScan: 500,
+		Host:     "0.0.0.0",
+		Port:     6379,
+		MaxScan:  500,
+		MaxBatch: 1000,
+		Backend:  CacheBackendRedis,
 	},
 	Tracing: TracingConfig{
 		Enabled: false,
@@ -606,6 +650,23 @@ type KVConfig struct {
 	// Compression allows us to compress payload before storing in storage.
 	Compression          bool  `json:"compression"               mapstructure:"compression"               yaml:"compression"`
 	MinCompressThreshold int32 `json:"min_compression_threshold" mapstructure:"min_compression_threshold" yaml:"min_compression_threshold"`
+	// LongRunningTx configures the explicit transactions that are not bounded by the five seconds limit of FDB.
+	LongRunningTx LongRunningTxConfig `json:"long_running_tx" mapstructure:"long_running_tx" yaml:"long_running_tx"`
+}
+
+// LongRunningTxConfig keeps the limits of long-running transactions. A long-running transaction buffers its writes in
+// the server and validates its reads at commit, so the buffered writes, the reads and the lifetime are bounded.
+type LongRunningTxConfig struct {
+	Enabled bool `json:"enabled" mapstructure:"enabled" yaml:"enabled"`
+	// Timeout is the maximum lifetime of the transaction, it is rolled back once it is reached.
+	Timeout time.Duration `json:"timeout" mapstructure:"timeout" yaml:"timeout"`
+	// MaxSize is the maximum size in bytes of the buffered writes. It must leave room under the ten megabytes
+	// transaction limit of FDB for the writes done by the commit, like the search and secondary indexes.
+	MaxSize int64 `json:"max_size" mapstructure:"max_size" yaml:"max_size"`
+	// MaxReadSize is the maximum size in bytes of the rows read by the transaction. The commit reads all of them again
+	// to validate them, in the same FDB transaction as the writes, so it must be small enough to be read within the
+	// five seconds limit of FDB. Snapshot reads are not validated and don't count.
+	MaxReadSize int64 `json:"max_read_size" mapstructure:"max_read_size" yaml:"max_read_size"`
 }
 
 // FoundationDBConfig keeps FoundationDB configuration parameters.
@@ -631,6 +692,53 @@ type SearchConfig struct {
 	LogFilter         bool `json:"log_filter"          mapstructure:"log_filter"          yaml:"log_filter"`
 
 	DoNotReloadMetadata bool `json:"do_not_reload_metadata" mapstructure:"do_not_reload_metadata" yaml:"do_not_reload_metadata"`
+
+	// Backend selects the search store, either SearchBackendTypesense or SearchBackendEmbedded.
+	Backend  string               `json:"backend"  mapstructure:"backend"  yaml:"backend"`
+	Embedded EmbeddedSearchConfig `json:"embedded" mapstructure:"embedded" yaml:"embedded"`
+
+	Embedding EmbeddingConfig `json:"embedding" mapstructure:"embedding" yaml:"embedding"`
+	Analytics AnalyticsConfig `json:"analytics" mapstructure:"analytics" yaml:"analytics"`
+}
+
+const (
+	// SearchBackendTypesense uses an external Typesense server configured by the host, port and auth key.
+	SearchBackendTypesense = "typesense"
+	// SearchBackendEmbedded indexes the documents in the server process, it is meant for local development and CI.
+	SearchBackendEmbedded = "embedded"
+)
+
+// EmbeddedSearchConfig keeps the configuration of the in-process search store.
+type EmbeddedSearchConfig struct {
+	// Dir is the directory where the indexes are persisted. The indexes are kept only in memory if it is empty.
+	Dir string `json:"dir" mapstructure:"dir" yaml:"dir"`
+}
+
+// EmbeddingConfig keeps the configuration of the providers computing the vector fields derived from text fields.
+type EmbeddingConfig struct {
+	// Provider is used by the fields that don't set one in their schema, either "http" or "hash". The fields need to
+	// set their provider if it is empty, which is the default.
+	Provider string              `json:"provider" mapstructure:"provider" yaml:"provider"`
+	HTTP     HTTPEmbeddingConfig `json:"http"     mapstructure:"http"     yaml:"http"`
+}
+
+// HTTPEmbeddingConfig is the OpenAI compatible embeddings endpoint.
+type HTTPEmbeddingConfig struct {
+	URL    string `json:"url"     mapstructure:"url"     yaml:"url"`
+	APIKey string `json:"api_key" mapstructure:"api_key" yaml:"api_key"`
+	// Model is used by the fields that don't set one in their schema.
+	Model   string        `json:"model"   mapstructure:"model"   yaml:"model"`
+	Timeout time.Duration `json:"timeout" mapstructure:"timeout" yaml:"timeout"`
+}
+
+// AnalyticsConfig controls recording of the search queries used by the top queries, no result queries and
+// suggestion APIs.
+type AnalyticsConfig struct {
+	Enabled bool `json:"enabled" mapstructure:"enabled" yaml:"enabled"`
+	// RetentionDays is the number of daily rollups kept per index, older rollups are dropped while recording.
+	RetentionDays int `json:"retention_days" mapstructure:"retention_days" yaml:"retention_days"`
+	// MaxQueryLength truncates the recorded queries, in characters.
+	MaxQueryLength int `json:"max_query_length" mapstructure:"max_query_length" yaml:"max_query_length"`
 }
 
 type SecondaryIndexConfig struct {
@@ -640,11 +748,22 @@ type SecondaryIndexConfig struct {
 }
 
 type CacheConfig struct {
-	Host    string `json:"host"     mapstructure:"host"     yaml:"host"`
-	Port    int16  `json:"port"     mapstructure:"port"     yaml:"port"`
-	MaxScan int64  `json:"max_scan" mapstructure:"max_scan" yaml:"max_scan"`
+	Host     string `json:"host"      mapstructure:"host"      yaml:"host"`
+	Port     int16  `json:"port"      mapstructure:"port"      yaml:"port"`
+	MaxScan  int64  `json:"max_scan"  mapstructure:"max_scan"  yaml:"max_scan"`
+	MaxBatch int    `json:"max_batch" mapstructure:"max_batch" yaml:"max_batch"`
+	// Backend selects the cache store, either CacheBackendRedis or CacheBackendMemory.
+	Backend string `json:"backend" mapstructure:"backend" yaml:"backend"`
 }
 
+const (
+	// CacheBackendRedis uses an external Redis server configured by the host and port.
+	CacheBackendRedis = "redis"
+	// CacheBackendMemory keeps the keys and the streams in the server process, it is meant for local development and
+	// tests.
+	CacheBackendMemory = "memory"
+)
+
 type LimitsConfig struct {
 	Enabled bool
 
@@ -0,0 +1,204 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/tigrisdata/tigris/errors"
+	qsearch "github.com/tigrisdata/tigris/query/search"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/server/config"
+)
+
+// Provider computes the embeddings of texts.
+type Provider interface {
+	// Embed returns a vector of the given dimensions for each of the texts. The provider default model is used if
+	// the model is empty.
+	Embed(ctx context.Context, model string, dimensions int, texts []string) ([][]float64, error)
+}
+
+// NewProvider returns the provider with the given name, or the configured one if the name is empty.
+func NewProvider(name string, cfg *config.EmbeddingConfig) (Provider, error) {
+	if len(name) == 0 {
+		name = cfg.Provider
+	}
+
+	switch name {
+	case "":
+		return nil, errors.InvalidArgument("embedding provider is not configured in the server")
+	case schema.EmbeddingProviderHTTP:
+		if len(cfg.HTTP.URL) == 0 {
+			return nil, errors.InvalidArgument("embedding provider '%s' is not configured", name)
+		}
+		return NewHTTPProvider(&cfg.HTTP), nil
+	case schema.EmbeddingProviderHash:
+		return NewHashProvider(), nil
+	default:
+		return nil, errors.InvalidArgument("unsupported embedding provider '%s'", name)
+	}
+}
+
+// EmbedDocuments sets the vector fields derived from the text fields of the documents. The texts of all the documents
+// are embedded together for each of the fields. A document is skipped if none of the sources of the field are set in
+// it, which is the case for the partial updates not modifying them.
+func EmbedDocuments(ctx context.Context, fields []*schema.Field, docs []map[string]any) error {
+	for _, f := range schema.EmbeddingFields(fields) {
+		var (
+			texts   []string
+			targets []map[string]any
+		)
+		for _, doc := range docs {
+			if doc == nil {
+				continue
+			}
+			if text, ok := sourceText(doc, f.Embedding.Source); ok {
+				texts = append(texts, text)
+				targets = append(targets, doc)
+			}
+		}
+		if len(texts) == 0 {
+			continue
+		}
+
+		vectors, err := embed(ctx, f.Embedding, f.GetDimensions(), texts)
+		if err != nil {
+			return err
+		}
+
+		for i, doc := range targets {
+			// the documents are decoded from JSON so the vector is kept in the same form
+			vector := make([]any, len(vectors[i]))
+			for j := range vectors[i] {
+				vector[j] = vectors[i][j]
+			}
+			doc[f.FieldName] = vector
+		}
+	}
+
+	return nil
+}
+
+// EmbedVectorSearch computes the vector of the text of the vector search with the embedding of the field.
+func EmbedVectorSearch(ctx context.Context, field *schema.QueryableField, vectorSearch *qsearch.VectorSearch) error {
+	if len(vectorSearch.Text) == 0 {
+		return nil
+	}
+	if field.Embedding == nil {
+		return errors.InvalidArgument("field '%s' doesn't have an embedding, the vector search needs a vector", field.FieldName)
+	}
+
+	dimensions := 0
+	if field.Dimensions != nil {
+		dimensions = *field.Dimensions
+	}
+
+	vectors, err := embed(ctx, field.Embedding, dimensions, []string{vectorSearch.Text})
+	if err != nil {
+		return err
+	}
+
+	vectorSearch.VectorV = vectors[0]
+	vectorSearch.RawVectorV, err = jsoniter.Marshal(vectors[0])
+
+	return err
+}
+
+var (
+	providersMu sync.Mutex
+	providers   = make(map[string]Provider)
+)
+
+// getProvider returns the provider with the given name, or the configured one if the name is empty. The providers are
+// created once and shared by the requests, so that the HTTP provider reuses its connections.
+func getProvider(name string) (Provider, error) {
+	cfg := &config.DefaultConfig.Search.Embedding
+	if len(name) == 0 {
+		name = cfg.Provider
+	}
+
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	if provider, ok := providers[name]; ok {
+		return provider, nil
+	}
+
+	provider, err := NewProvider(name, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	providers[name] = provider
+	return provider, nil
+}
+
+func embed(ctx context.Context, embedding *schema.FieldEmbedding, dimensions int, texts []string) ([][]float64, error) {
+	provider, err := getProvider(embedding.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	vectors, err := provider.Embed(ctx, embedding.Model, dimensions, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(vectors) != len(texts) {
+		return nil, errors.Internal("embedding provider returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+	for _, v := range vectors {
+		if len(v) != dimensions {
+			return nil, errors.Internal("embedding provider returned a vector of %d dimensions, expected %d", len(v), dimensions)
+		}
+	}
+
+	return vectors, nil
+}
+
+// sourceText joins the values of the source fields set in the document. The sources are either the keys of the
+// flattened document or paths in the nested objects.
+func sourceText(doc map[string]any, sources []string) (string, bool) {
+	var values []string
+	for _, source := range sources {
+		value, found := doc[source]
+		if !found {
+			value, found = getByPath(doc, strings.Split(source, "."))
+		}
+
+		if str, ok := value.(string); found && ok {
+			values = append(values, str)
+		}
+	}
+
+	return strings.Join(values, "\n"), len(values) > 0
+}
+
+func getByPath(doc map[string]any, keys []string) (any, bool) {
+	value, found := doc[keys[0]]
+	if !found || len(keys) == 1 {
+		return value, found
+	}
+
+	nested, ok := value.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	return getByPath(nested, keys[1:])
+}
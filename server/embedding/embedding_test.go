@@ -0,0 +1,129 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+	qsearch "github.com/tigrisdata/tigris/query/search"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/server/config"
+)
+
+func cosine(a []float64, b []float64) float64 {
+	var dot float64
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return dot
+}
+
+func TestHashProvider(t *testing.T) {
+	p := NewHashProvider()
+
+	vectors, err := p.Embed(context.TODO(), "", 64, []string{"Red running shoes", "red RUNNING shoes!", "green hat", ""})
+	require.NoError(t, err)
+	require.Len(t, vectors, 4)
+	require.Len(t, vectors[0], 64)
+	require.Equal(t, vectors[0], vectors[1])
+	require.InDelta(t, 1, cosine(vectors[0], vectors[0]), 0.0001)
+	require.Greater(t, cosine(vectors[0], vectors[1]), cosine(vectors[0], vectors[2]))
+	require.Equal(t, make([]float64, 64), vectors[3])
+
+	other, err := p.Embed(context.TODO(), "other", 64, []string{"Red running shoes"})
+	require.NoError(t, err)
+	require.NotEqual(t, vectors[0], other[0])
+
+	_, err = p.Embed(context.TODO(), "", 0, []string{"a"})
+	require.Error(t, err)
+}
+
+func TestHTTPProvider(t *testing.T) {
+	var received httpEmbeddingRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		require.NoError(t, jsoniter.NewDecoder(r.Body).Decode(&received))
+		if received.Model == "broken" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// the data is returned in the reverse order to check that the index is used
+		_, _ = w.Write([]byte(`{"data": [{"index": 1, "embedding": [0, 1]}, {"index": 0, "embedding": [1, 0]}]}`))
+	}))
+	defer server.Close()
+
+	p := NewHTTPProvider(&config.HTTPEmbeddingConfig{URL: server.URL, APIKey: "secret", Model: "default"})
+
+	vectors, err := p.Embed(context.TODO(), "", 2, []string{"a", "b"})
+	require.NoError(t, err)
+	require.Equal(t, [][]float64{{1, 0}, {0, 1}}, vectors)
+	require.Equal(t, httpEmbeddingRequest{Input: []string{"a", "b"}, Model: "default", Dimensions: 2}, received)
+
+	_, err = p.Embed(context.TODO(), "broken", 2, []string{"a"})
+	require.Error(t, err)
+}
+
+func TestEmbedDocuments(t *testing.T) {
+	config.DefaultConfig.Search.Embedding.Provider = schema.EmbeddingProviderHash
+
+	dim := 8
+	vec := &schema.Field{
+		FieldName:  "vec",
+		DataType:   schema.VectorType,
+		Dimensions: &dim,
+		Embedding:  &schema.FieldEmbedding{Source: []string{"name", "info.description"}},
+	}
+	fields := []*schema.Field{{FieldName: "name", DataType: schema.StringType}, vec}
+
+	docs := []map[string]any{
+		{"name": "red shoes", "info": map[string]any{"description": "for running"}},
+		{"name": "red shoes", "info.description": "for running"},
+		{"price": 10},
+		nil,
+	}
+	require.NoError(t, EmbedDocuments(context.TODO(), fields, docs))
+	require.Len(t, docs[0]["vec"], 8)
+	require.Equal(t, docs[0]["vec"], docs[1]["vec"])
+	require.NotContains(t, docs[2], "vec")
+
+	expected, err := NewHashProvider().Embed(context.TODO(), "", 8, []string{"red shoes\nfor running"})
+	require.NoError(t, err)
+
+	vectorSearch := qsearch.VectorSearch{VectorF: "vec", Text: "red shoes\nfor running"}
+	require.NoError(t, EmbedVectorSearch(context.TODO(), &schema.QueryableField{
+		FieldName:  "vec",
+		Dimensions: &dim,
+		Embedding:  vec.Embedding,
+	}, &vectorSearch))
+	require.Equal(t, expected[0], vectorSearch.VectorV)
+	require.NotEmpty(t, vectorSearch.RawVectorV)
+
+	err = EmbedVectorSearch(context.TODO(), &schema.QueryableField{FieldName: "other"}, &qsearch.VectorSearch{VectorF: "other", Text: "a"})
+	require.Error(t, err)
+
+	vec.Embedding.Provider = schema.EmbeddingProviderHTTP
+	require.Error(t, EmbedDocuments(context.TODO(), fields, []map[string]any{{"name": "a"}}))
+
+	// the embeddings are disabled if neither the field nor the server sets a provider
+	vec.Embedding.Provider = ""
+	config.DefaultConfig.Search.Embedding.Provider = ""
+	require.Error(t, EmbedDocuments(context.TODO(), fields, []map[string]any{{"name": "a"}}))
+}
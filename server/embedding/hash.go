@@ -0,0 +1,85 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/tigrisdata/tigris/errors"
+)
+
+// HashProvider is a deterministic local model, each token of the text is hashed to one of the dimensions of the vector
+// so that texts sharing tokens are close. It doesn't capture the meaning of the text and is meant for tests.
+type HashProvider struct{}
+
+func NewHashProvider() *HashProvider {
+	return &HashProvider{}
+}
+
+func (*HashProvider) Embed(_ context.Context, model string, dimensions int, texts []string) ([][]float64, error) {
+	if dimensions <= 0 {
+		return nil, errors.InvalidArgument("embedding dimensions must be positive")
+	}
+
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vector := make([]float64, dimensions)
+		for _, token := range tokenize(text) {
+			h := fnv.New64a()
+			_, _ = h.Write([]byte(model))
+			_, _ = h.Write([]byte{0})
+			_, _ = h.Write([]byte(token))
+			sum := h.Sum64()
+
+			// the highest bit gives the sign so that unrelated tokens cancel each other out on average
+			if sum>>63 == 1 {
+				vector[sum%uint64(dimensions)]--
+			} else {
+				vector[sum%uint64(dimensions)]++
+			}
+		}
+
+		vectors[i] = normalize(vector)
+	}
+
+	return vectors, nil
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func normalize(vector []float64) []float64 {
+	var norm float64
+	for _, v := range vector {
+		norm += v * v
+	}
+	if norm == 0 {
+		return vector
+	}
+
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] /= norm
+	}
+
+	return vector
+}
@@ -0,0 +1,109 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog/log"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/server/config"
+)
+
+// maxErrorBody is the maximum size of the body of an error response that is logged.
+const maxErrorBody = 1024
+
+type httpEmbeddingRequest struct {
+	Input      []string `json:"input"`
+	Model      string   `json:"model"`
+	Dimensions int      `json:"dimensions,omitempty"`
+}
+
+type httpEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+// HTTPProvider calls an OpenAI compatible embeddings endpoint.
+type HTTPProvider struct {
+	cfg    *config.HTTPEmbeddingConfig
+	client *http.Client
+}
+
+func NewHTTPProvider(cfg *config.HTTPEmbeddingConfig) *HTTPProvider {
+	return &HTTPProvider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+func (p *HTTPProvider) Embed(ctx context.Context, model string, dimensions int, texts []string) ([][]float64, error) {
+	if len(model) == 0 {
+		model = p.cfg.Model
+	}
+
+	payload, err := jsoniter.Marshal(&httpEmbeddingRequest{
+		Input:      texts,
+		Model:      model,
+		Dimensions: dimensions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.URL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	if len(p.cfg.APIKey) > 0 {
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", p.cfg.APIKey))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		log.Err(err).Str("model", model).Msg("embedding request failed")
+		return nil, errors.Unavailable("embedding provider is not reachable")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		log.Error().Int("status", resp.StatusCode).Str("model", model).Bytes("body", body).Msg("embedding request failed")
+		return nil, errors.Unavailable("embedding provider failed with status %d", resp.StatusCode)
+	}
+
+	var decoded httpEmbeddingResponse
+	if err = jsoniter.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, errors.Internal("unable to decode the embedding response %s", err.Error())
+	}
+
+	vectors := make([][]float64, len(texts))
+	for _, d := range decoded.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, errors.Internal("embedding response has an unexpected index %d", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+
+	return vectors, nil
+}
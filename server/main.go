@@ -39,6 +39,10 @@ import (
 	ulog "github.com/tigrisdata/tigris/util/log"
 )
 
+// registered receives the muxer once the services are registered, so that their background work is stopped on
+// shutdown.
+var registered = make(chan *muxer.Muxer, 1)
+
 func main() {
 	sigs := make(chan os.Signal, 1)
 
@@ -47,6 +51,12 @@ func main() {
 	go func() {
 		<-sigs
 
+		select {
+		case mx := <-registered:
+			mx.Stop()
+		default:
+		}
+
 		os.Exit(0)
 	}()
 
@@ -75,7 +85,11 @@ func mainWithCode() int {
 
 	log.Info().Str("version", util.Version).Msgf("Starting server")
 
-	searchStore := search.NewStore(&defaultConfig.Search, defaultConfig.Metrics.Search.Enabled)
+	searchStore, err := search.NewStore(&defaultConfig.Search, defaultConfig.Metrics.Search.Enabled)
+	if err != nil {
+		log.Error().Err(err).Msg("error initializing search store")
+		return 1
+	}
 
 	// creating kv store for search and database independently allows us to enable functionality slowly. This is
 	// temporary as once we have functionality tested then
@@ -122,6 +136,7 @@ func mainWithCode() int {
 
 	mx := muxer.NewMuxer(cfg)
 	mx.RegisterServices(&cfg.Server, kvStoreForDatabase, searchStore, tenantMgr, txMgr, forSearchTxMgr, bProvider)
+	registered <- mx
 
 	// metrics is already initialized, we can start reporting usage data
 	ur, err := billing.NewUsageReporter(metrics.GlobalSt, tenantMgr, tenantMgr, bProvider, txMgr)
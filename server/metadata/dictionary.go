@@ -309,7 +309,10 @@ type Dictionary struct {
 	schemaStore       *SchemaSubspace
 	searchSchemaStore *SearchSchemaSubspace
 
-	queueStore *QueueSubspace
+	queueStore     *QueueSubspace
+	analyticsStore *SearchAnalyticsSubspace
+	verifyStore    *SearchVerifySubspace
+	realtimeStore  *RealtimeSubspace
 }
 
 func NewMetadataDictionary(mdNameRegistry *NameRegistry) *Dictionary {
@@ -326,6 +329,9 @@ func NewMetadataDictionary(mdNameRegistry *NameRegistry) *Dictionary {
 		schemaStore:       NewSchemaStore(mdNameRegistry),
 		searchSchemaStore: NewSearchSchemaStore(mdNameRegistry),
 		queueStore:        queueStore,
+		analyticsStore:    NewSearchAnalyticsStore(mdNameRegistry),
+		verifyStore:       NewSearchVerifyStore(mdNameRegistry),
+		realtimeStore:     NewRealtimeStore(mdNameRegistry),
 	}
 }
 
@@ -361,6 +367,18 @@ func (k *Dictionary) Queue() *QueueSubspace {
 	return k.queueStore
 }
 
+func (k *Dictionary) SearchAnalytics() *SearchAnalyticsSubspace {
+	return k.analyticsStore
+}
+
+func (k *Dictionary) SearchVerify() *SearchVerifySubspace {
+	return k.verifyStore
+}
+
+func (k *Dictionary) Realtime() *RealtimeSubspace {
+	return k.realtimeStore
+}
+
 // ReserveNamespace is the first step in the encoding and the mapping is passed the caller. As this is the first encoded
 // integer the caller needs to make sure a unique value is assigned to this namespace.
 func (k *Dictionary) ReserveNamespace(ctx context.Context, tx transaction.Tx, namespaceId string,
@@ -41,6 +41,9 @@ type SearchEncoder interface {
 
 type CacheEncoder interface {
 	EncodeCacheTableName(tenantId uint32, projId uint32, name string) (string, error)
+	// EncodeInternalCacheTableName encodes one of the reserved tables the server keeps in the cache store for a
+	// project, it errors for any other name.
+	EncodeInternalCacheTableName(tenantId uint32, projId uint32, name string) (string, error)
 	DecodeCacheTableName(stream string) (uint32, uint32, string, bool)
 	DecodeInternalCacheKeyNameToExternal(internalKey string) string
 }
@@ -54,6 +57,9 @@ type Encoder interface {
 	// EncodeSecondaryIndexTableName returns encoded bytes for the table name of a collections secondary index.
 	EncodeSecondaryIndexTableName(ns Namespace, db *Database, coll *schema.DefaultCollection) ([]byte, error)
 	EncodePartitionTableName(ns Namespace, db *Database, coll *schema.DefaultCollection) ([]byte, error)
+	// EncodeOutboxTableName returns encoded bytes for the table of the realtime messages enqueued by the transactions
+	// of the namespace.
+	EncodeOutboxTableName(ns Namespace) []byte
 	// EncodeIndexName returns encoded bytes for the index name
 	EncodeIndexName(idx *schema.Index) []byte
 	// EncodeKey returns encoded bytes of the key which will be used to store the values in fdb. The Key return by this
@@ -69,6 +75,28 @@ type Encoder interface {
 	DecodeIndexName(indexName []byte) uint32
 }
 
+// The reserved tables of the cache store, see EncodeInternalCacheTableName.
+const (
+	// PresenceCacheTable holds the presence members of the channels of a project.
+	PresenceCacheTable = "presence"
+	// PresenceChannelsCacheTable tracks the channels having presence members, it is shared by all the projects and
+	// encoded with a zero tenant and project.
+	PresenceChannelsCacheTable = "presence_channels"
+	// OutboxCacheTable holds the lease of the outbox relay and the number of messages published of the outbox entries
+	// of a namespace.
+	OutboxCacheTable = "outbox"
+	// WebhookLeasesCacheTable holds the leases of the servers delivering the webhooks, it is shared by all the
+	// projects and encoded with a zero tenant and project.
+	WebhookLeasesCacheTable = "webhook_leases"
+)
+
+var reservedCacheTables = map[string]struct{}{
+	PresenceCacheTable:         {},
+	PresenceChannelsCacheTable: {},
+	OutboxCacheTable:           {},
+	WebhookLeasesCacheTable:    {},
+}
+
 // NewCacheEncoder creates CacheEncoder to encode cache tenant, project and keys.
 func NewCacheEncoder() CacheEncoder {
 	return &DictKeyEncoder{}
@@ -97,6 +125,10 @@ func (d *DictKeyEncoder) EncodePartitionTableName(ns Namespace, db *Database, co
 	return d.encodedTableName(ns, db, coll, internal.PartitionKeyPrefix), nil
 }
 
+func (d *DictKeyEncoder) EncodeOutboxTableName(ns Namespace) []byte {
+	return d.encodedTableName(ns, nil, nil, internal.OutboxTableKeyPrefix)
+}
+
 func (d *DictKeyEncoder) EncodeIndexName(idx *schema.Index) []byte {
 	return d.encodedIdxName(idx)
 }
@@ -156,6 +188,17 @@ func (*DictKeyEncoder) EncodeCacheTableName(tenantId uint32, projId uint32, name
 	return fmt.Sprintf("%s:%d:%d:%s", internal.CacheKeyPrefix, tenantId, projId, name), nil
 }
 
+// EncodeInternalCacheTableName encodes the reserved tables with InternalCacheKeyPrefix rather than CacheKeyPrefix, the
+// names of the caches and the channels of the users are not restricted so only a different prefix keeps them from
+// addressing these tables.
+func (*DictKeyEncoder) EncodeInternalCacheTableName(tenantId uint32, projId uint32, name string) (string, error) {
+	if _, ok := reservedCacheTables[name]; !ok {
+		return "", errors.Internal("'%s' is not a reserved cache table", name)
+	}
+
+	return fmt.Sprintf("%s:%d:%d:%s", internal.InternalCacheKeyPrefix, tenantId, projId, name), nil
+}
+
 func (*DictKeyEncoder) DecodeInternalCacheKeyNameToExternal(internalKey string) string {
 	i := 0
 	// first four parts are internal (cache prefix, tenant, project, cache name)
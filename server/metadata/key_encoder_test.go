@@ -76,6 +76,11 @@ func TestEncodeDecodeKey(t *testing.T) {
 	require.Equal(t, db.Name(), dbObj.Name())
 	require.Equal(t, coll.Name, collName)
 	require.True(t, ok)
+
+	outboxTable := k.EncodeOutboxTableName(ns)
+	require.Equal(t, append(append([]byte{}, internal.OutboxTableKeyPrefix...), UInt32ToByte(1)...), outboxTable)
+	_, _, _, ok = k.DecodeTableName(outboxTable)
+	require.False(t, ok)
 }
 
 func TestCacheEncoderKeyConversion(t *testing.T) {
@@ -87,3 +92,21 @@ func TestCacheEncoderKeyConversion(t *testing.T) {
 	externalKey2 := cacheEncoder.DecodeInternalCacheKeyNameToExternal("cache:1:1:c1:k1:x1")
 	require.Equal(t, "k1:x1", externalKey2)
 }
+
+func TestCacheEncoderInternalTables(t *testing.T) {
+	cacheEncoder := NewCacheEncoder()
+
+	name, err := cacheEncoder.EncodeInternalCacheTableName(1, 2, PresenceCacheTable)
+	require.NoError(t, err)
+	require.Equal(t, "internal:1:2:presence", name)
+
+	// the users' caches and channels can't address the internal tables
+	userName, err := cacheEncoder.EncodeCacheTableName(1, 2, PresenceCacheTable)
+	require.NoError(t, err)
+	require.NotEqual(t, name, userName)
+	_, _, _, ok := cacheEncoder.DecodeCacheTableName(name)
+	require.False(t, ok)
+
+	_, err = cacheEncoder.EncodeInternalCacheTableName(1, 2, "c1")
+	require.Error(t, err)
+}
@@ -16,11 +16,14 @@ package metadata
 
 import (
 	"context"
+	"strings"
 
 	"github.com/tigrisdata/tigris/errors"
 	"github.com/tigrisdata/tigris/keys"
+	"github.com/tigrisdata/tigris/schema"
 	"github.com/tigrisdata/tigris/server/defaults"
 	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/store/search"
 )
 
 // A Namespace is a logical grouping of databases.
@@ -72,12 +75,36 @@ type CacheMetadata struct {
 	Name      string
 	Creator   string
 	CreatedAt int64
+	// DefaultTTLMs is the expiry of the keys set without an explicit expiry, zero if they don't expire.
+	DefaultTTLMs uint64
 }
 
 type SearchMetadata struct {
 	Name      string
 	Creator   string
 	CreatedAt int64
+	// Synonyms and StopwordSets tune the relevance of the index, these are pushed to the search store whenever the
+	// index is created or rebuilt.
+	Synonyms      []search.Synonym      `json:",omitempty"`
+	StopwordSets  []search.StopwordSet  `json:",omitempty"`
+	CurationRules []schema.CurationRule `json:",omitempty"`
+}
+
+// Stopwords returns the lower-cased words of all the stopword sets of the index.
+func (m *SearchMetadata) Stopwords() []string {
+	var stopwords []string
+	seen := make(map[string]struct{})
+	for _, set := range m.StopwordSets {
+		for _, word := range set.Stopwords {
+			word = strings.ToLower(word)
+			if _, ok := seen[word]; !ok {
+				seen[word] = struct{}{}
+				stopwords = append(stopwords, word)
+			}
+		}
+	}
+
+	return stopwords
 }
 
 // StrId returns id assigned to the namespace.
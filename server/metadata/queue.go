@@ -75,6 +75,7 @@ const (
 	BUILD_INDEX_QUEUE_TASK TaskType = iota
 	TEST_QUEUE_TASK
 	BUILD_SEARCH_INDEX_TASK
+	VERIFY_SEARCH_INDEX_TASK
 )
 
 type IndexBuildTask struct {
@@ -84,6 +85,13 @@ type IndexBuildTask struct {
 	CollName    string `json:"collection"`
 }
 
+// SearchVerifyTask verifies the implicit search index of a collection and optionally repairs the documents that are
+// out of sync.
+type SearchVerifyTask struct {
+	IndexBuildTask
+	Repair bool `json:"repair"`
+}
+
 type QueueItem struct {
 	Id         string    `json:"id"`
 	Priority   int64     `json:"priority"`
@@ -0,0 +1,157 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/keys"
+	"github.com/tigrisdata/tigris/server/metrics"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/store/kv"
+)
+
+// The settings of the realtime channels of a project, like the channel rules. These are kept in the metadata and not
+// in the cache store holding the channels, so the cache API can't reach them and a flush of the cache store doesn't
+// lose them.
+//
+// The FDB structure is:
+// ["realtime", "version", namespace, project, kind, name] = JSON value
+
+const (
+	realtimeValueVersion int32 = 1
+	realtimeKeyVersion   byte  = 1
+)
+
+const (
+	// RealtimeChannelRules is the kind of the access rules of the channels.
+	RealtimeChannelRules = "channel_rules"
+	// RealtimeChannelRetention is the kind of the retention policies of the channels.
+	RealtimeChannelRetention = "channel_retention"
+	// RealtimeWebhooks is the kind of the webhooks of the channels.
+	RealtimeWebhooks = "webhooks"
+)
+
+type RealtimeSubspace struct {
+	metadataSubspace
+}
+
+func NewRealtimeStore(nameRegistry *NameRegistry) *RealtimeSubspace {
+	return &RealtimeSubspace{
+		metadataSubspace{
+			SubspaceName: nameRegistry.RealtimeSubspaceName(),
+			KeyVersion:   []byte{realtimeKeyVersion},
+		},
+	}
+}
+
+func (s *RealtimeSubspace) getKey(nsId uint32, projId uint32, parts ...any) keys.Key {
+	return keys.NewKey(s.SubspaceName, append([]any{s.KeyVersion, int64(nsId), int64(projId)}, parts...)...)
+}
+
+// Put adds the setting or replaces the setting of the kind having the same name.
+func (s *RealtimeSubspace) Put(ctx context.Context, tx transaction.Tx, nsId uint32, projId uint32, kind string,
+	name string, value []byte,
+) error {
+	return s.updatePayload(ctx, tx, nil,
+		s.getKey(nsId, projId, kind, name),
+		realtimeValueVersion,
+		value)
+}
+
+// Get returns the setting, errors.ErrNotFound if there is no such setting.
+func (s *RealtimeSubspace) Get(ctx context.Context, tx transaction.Tx, nsId uint32, projId uint32, kind string,
+	name string,
+) ([]byte, error) {
+	payload, err := s.getPayload(ctx, tx, nil, s.getKey(nsId, projId, kind, name))
+	if err != nil {
+		return nil, err
+	}
+
+	return payload.RawData, nil
+}
+
+// Delete removes the setting, it returns false if there is no such setting.
+func (s *RealtimeSubspace) Delete(ctx context.Context, tx transaction.Tx, nsId uint32, projId uint32, kind string,
+	name string,
+) (bool, error) {
+	key := s.getKey(nsId, projId, kind, name)
+
+	_, err := s.getPayload(ctx, tx, nil, key)
+	if err == errors.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, s.deleteMetadata(ctx, tx, nil, key)
+}
+
+// List returns the settings of the kind keyed by name.
+func (s *RealtimeSubspace) List(ctx context.Context, tx transaction.Tx, nsId uint32, projId uint32, kind string,
+) (map[string][]byte, error) {
+	it, err := tx.Read(ctx, s.getKey(nsId, projId, kind), false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do not count for metadata operations
+	metrics.SetMetadataOperationInContext(ctx)
+
+	settings := make(map[string][]byte)
+
+	var row kv.KeyValue
+	for it.Next(&row) {
+		name, ok := row.Key[len(row.Key)-1].(string)
+		if !ok {
+			return nil, errors.Internal("name not found %v", row.Key)
+		}
+		settings[name] = row.Data.RawData
+	}
+
+	return settings, it.Err()
+}
+
+// ListKind returns the settings of the kind of all the projects. It reads the settings of every kind, so it is only
+// meant for the servers polling the settings of all the projects, like the webhooks.
+func (s *RealtimeSubspace) ListKind(ctx context.Context, tx transaction.Tx, kind string) ([][]byte, error) {
+	it, err := tx.Read(ctx, keys.NewKey(s.SubspaceName, s.KeyVersion), false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do not count for metadata operations
+	metrics.SetMetadataOperationInContext(ctx)
+
+	var (
+		settings [][]byte
+		row      kv.KeyValue
+	)
+	for it.Next(&row) {
+		// the key is the version, namespace, project, kind and name
+		if len(row.Key) == 5 && row.Key[3] == kind {
+			settings = append(settings, row.Data.RawData)
+		}
+	}
+
+	return settings, it.Err()
+}
+
+// DeleteProject removes all the settings of the project, it is called when the project is deleted.
+func (s *RealtimeSubspace) DeleteProject(ctx context.Context, tx transaction.Tx, nsId uint32, projId uint32) error {
+	return s.deleteMetadata(ctx, tx, nil, s.getKey(nsId, projId))
+}
@@ -0,0 +1,444 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/keys"
+	"github.com/tigrisdata/tigris/server/config"
+	"github.com/tigrisdata/tigris/server/metrics"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/store/kv"
+)
+
+// The search analytics keep the queries sent to an index rolled up per day. A query is recorded once per search
+// request. The searches are buffered in memory and flushed periodically in a batch, the counters are updated using
+// atomic operations so that the flushes of the servers don't conflict with each other.
+//
+// The FDB structure is:
+// ["search_analytics", "version", nsId, project, branch, collection, index, countersSpace, day, query, counter] = int64
+//
+// The queries of a day are ordered, so the queries starting with a prefix are read by a range read of each day.
+
+const (
+	analyticsValueVersion int32 = 1
+
+	analyticsCountersSpace int64 = 2
+
+	analyticsSearches  = "searches"
+	analyticsNoResults = "no_results"
+	analyticsFound     = "found"
+
+	analyticsDay = 24 * time.Hour
+
+	// analyticsFlushInterval is how often the buffered searches are written.
+	analyticsFlushInterval = 5 * time.Second
+	// analyticsFlushBatch is the number of buffered queries written by a transaction.
+	analyticsFlushBatch = 500
+	// analyticsMaxPending bounds the buffered queries, the searches are dropped once it is reached until the next flush.
+	analyticsMaxPending = 10000
+)
+
+// SearchAnalyticsTarget identifies the index the queries are recorded for. Index is set for the standalone search
+// indexes, Branch and Collection for the collections.
+type SearchAnalyticsTarget struct {
+	NamespaceId uint32
+	Project     string
+	Branch      string
+	Collection  string
+	Index       string
+}
+
+// QueryStats is the rollup of a query over the requested days.
+type QueryStats struct {
+	Query string
+	// Searches is the number of searches with this query.
+	Searches int64
+	// NoResults is the number of searches which didn't match any document.
+	NoResults int64
+	// Found is the total number of documents matched by the searches.
+	Found int64
+}
+
+// analyticsEntry is a query of a day buffered before it is flushed.
+type analyticsEntry struct {
+	target SearchAnalyticsTarget
+	bucket int64
+	query  string
+}
+
+type SearchAnalyticsSubspace struct {
+	metadataSubspace
+
+	mu        sync.Mutex
+	pending   map[analyticsEntry]*QueryStats
+	flushOnce sync.Once
+}
+
+func NewSearchAnalyticsStore(nameRegistry *NameRegistry) *SearchAnalyticsSubspace {
+	return &SearchAnalyticsSubspace{
+		metadataSubspace: metadataSubspace{
+			SubspaceName: nameRegistry.SearchAnalyticsSubspaceName(),
+			KeyVersion:   []byte{byte(analyticsValueVersion)},
+		},
+		pending: make(map[analyticsEntry]*QueryStats),
+	}
+}
+
+// NormalizeQuery lowercases the query and collapses the whitespaces so that the variations of the same query are
+// rolled up together. The query is truncated to maxLen characters if maxLen is positive.
+func NormalizeQuery(query string, maxLen int) string {
+	query = strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	if maxLen > 0 && utf8.RuneCountInString(query) > maxLen {
+		query = strings.TrimSpace(string([]rune(query)[:maxLen]))
+	}
+
+	return query
+}
+
+func analyticsBucket(t time.Time) int64 {
+	return t.UTC().Unix() / int64(analyticsDay/time.Second)
+}
+
+func (a *SearchAnalyticsSubspace) targetKey(target *SearchAnalyticsTarget, parts ...any) keys.Key {
+	return keys.NewKey(a.SubspaceName, append([]any{a.KeyVersion, int64(target.NamespaceId), target.Project, target.Branch,
+		target.Collection, target.Index}, parts...)...)
+}
+
+func (a *SearchAnalyticsSubspace) counterKey(target *SearchAnalyticsTarget, bucket int64, query string, counter string) keys.Key {
+	return a.targetKey(target, analyticsCountersSpace, bucket, query, counter)
+}
+
+// Record adds a search of the normalized query which matched found documents to the rollup of the day of "at".
+func (a *SearchAnalyticsSubspace) Record(ctx context.Context, tx transaction.Tx, target *SearchAnalyticsTarget,
+	query string, found int64, at time.Time,
+) error {
+	return a.add(ctx, tx, target, analyticsBucket(at), query, newQueryStats(query, found))
+}
+
+func newQueryStats(query string, found int64) *QueryStats {
+	st := &QueryStats{Query: query, Searches: 1, Found: found}
+	if found == 0 {
+		st.NoResults = 1
+	}
+
+	return st
+}
+
+func (a *SearchAnalyticsSubspace) add(ctx context.Context, tx transaction.Tx, target *SearchAnalyticsTarget,
+	bucket int64, query string, st *QueryStats,
+) error {
+	if len(query) == 0 {
+		return nil
+	}
+
+	// every counter is added, even with zero, so that all the counters are read for any query
+	for counter, value := range map[string]int64{
+		analyticsSearches:  st.Searches,
+		analyticsNoResults: st.NoResults,
+		analyticsFound:     st.Found,
+	} {
+		if err := tx.AtomicAdd(ctx, a.counterKey(target, bucket, query, counter), value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Expire drops the rollups of the days falling out of the retention at "now". All the days before the first retained
+// day are dropped, starting from the oldest one still stored.
+func (a *SearchAnalyticsSubspace) Expire(ctx context.Context, tx transaction.Tx, target *SearchAnalyticsTarget,
+	now time.Time, retentionDays int,
+) error {
+	if retentionDays <= 0 {
+		return nil
+	}
+
+	cutoff := analyticsBucket(now) - int64(retentionDays) + 1
+	it, err := tx.AtomicReadRange(ctx, a.targetKey(target, analyticsCountersSpace),
+		a.targetKey(target, analyticsCountersSpace, cutoff), true)
+	if err != nil {
+		return err
+	}
+
+	var row kv.FdbBaseKeyValue[int64]
+	if !it.Next(&row) {
+		return it.Err()
+	}
+
+	oldest, ok := row.Key[len(row.Key)-3].(int64)
+	if !ok {
+		return errors.Internal("failed to decode search analytics key")
+	}
+
+	for bucket := oldest; bucket < cutoff; bucket++ {
+		if err = tx.Delete(ctx, a.targetKey(target, analyticsCountersSpace, bucket)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RecordSearch buffers the query if the search analytics are enabled, the buffered queries are written in batches
+// in the background. Failing to record the query is only logged, the search itself is already served.
+func (a *SearchAnalyticsSubspace) RecordSearch(_ context.Context, txMgr *transaction.Manager,
+	target *SearchAnalyticsTarget, query string, found int64,
+) {
+	cfg := config.DefaultConfig.Search.Analytics
+	if !cfg.Enabled {
+		return
+	}
+
+	query = NormalizeQuery(query, cfg.MaxQueryLength)
+	if len(query) == 0 {
+		return
+	}
+
+	a.flushOnce.Do(func() {
+		go a.flushLoop(txMgr)
+	})
+
+	entry := analyticsEntry{target: *target, bucket: analyticsBucket(time.Now()), query: query}
+	st := newQueryStats(query, found)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if pending, ok := a.pending[entry]; ok {
+		pending.Searches += st.Searches
+		pending.NoResults += st.NoResults
+		pending.Found += st.Found
+		return
+	}
+	if len(a.pending) >= analyticsMaxPending {
+		log.Debug().Str("project", target.Project).Msg("search analytics buffer is full, dropping search query")
+		return
+	}
+
+	a.pending[entry] = st
+}
+
+func (a *SearchAnalyticsSubspace) flushLoop(txMgr *transaction.Manager) {
+	ticker := time.NewTicker(analyticsFlushInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		a.flush(context.Background(), txMgr, time.Now())
+	}
+}
+
+// flush writes the buffered queries, the rollups falling out of the retention are dropped once per flush for every
+// target written.
+func (a *SearchAnalyticsSubspace) flush(ctx context.Context, txMgr *transaction.Manager, now time.Time) {
+	a.mu.Lock()
+	pending := a.pending
+	a.pending = make(map[analyticsEntry]*QueryStats)
+	a.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	targets := make(map[SearchAnalyticsTarget]struct{})
+	entries := make([]analyticsEntry, 0, len(pending))
+	for entry := range pending {
+		entries = append(entries, entry)
+		targets[entry.target] = struct{}{}
+	}
+
+	for start := 0; start < len(entries); start += analyticsFlushBatch {
+		end := start + analyticsFlushBatch
+		if end > len(entries) {
+			end = len(entries)
+		}
+
+		err := a.inTx(ctx, txMgr, func(tx transaction.Tx) error {
+			for _, entry := range entries[start:end] {
+				entry := entry
+				if err := a.add(ctx, tx, &entry.target, entry.bucket, entry.query, pending[entry]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			log.Err(err).Int("queries", end-start).Msg("failed to record search queries")
+		}
+	}
+
+	retentionDays := config.DefaultConfig.Search.Analytics.RetentionDays
+	for target := range targets {
+		target := target
+		if err := a.inTx(ctx, txMgr, func(tx transaction.Tx) error {
+			return a.Expire(ctx, tx, &target, now, retentionDays)
+		}); err != nil {
+			log.Err(err).Str("project", target.Project).Msg("failed to expire search analytics")
+		}
+	}
+}
+
+func (*SearchAnalyticsSubspace) inTx(ctx context.Context, txMgr *transaction.Manager, fn func(tx transaction.Tx) error) error {
+	tx, err := txMgr.StartTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(tx); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Rollup returns the stats of the queries recorded in the last days up to "now", including today. The counters of all
+// the days are read by a single range read.
+func (a *SearchAnalyticsSubspace) Rollup(ctx context.Context, tx transaction.Tx, target *SearchAnalyticsTarget,
+	days int, now time.Time,
+) ([]*QueryStats, error) {
+	last := analyticsBucket(now)
+	first := last - int64(days) + 1
+
+	return a.readCounters(ctx, tx, []keys.Key{a.targetKey(target, analyticsCountersSpace, first)},
+		[]keys.Key{a.targetKey(target, analyticsCountersSpace, last+1)})
+}
+
+// RollupPrefix returns the stats of the queries starting with the normalized prefix recorded in the last days up to
+// "now". Only the queries having the prefix are read, with a range read per day.
+func (a *SearchAnalyticsSubspace) RollupPrefix(ctx context.Context, tx transaction.Tx, target *SearchAnalyticsTarget,
+	prefix string, days int, now time.Time,
+) ([]*QueryStats, error) {
+	prefix = NormalizeQuery(prefix, 0)
+
+	var lKeys, rKeys []keys.Key
+	last := analyticsBucket(now)
+	for bucket := last - int64(days) + 1; bucket <= last; bucket++ {
+		lKeys = append(lKeys, a.targetKey(target, analyticsCountersSpace, bucket, prefix))
+		// the queries are encoded as UTF-8 which never has the 0xFF byte, so it is the end of the queries with the prefix
+		rKeys = append(rKeys, a.targetKey(target, analyticsCountersSpace, bucket, prefix+"\xff"))
+	}
+
+	return a.readCounters(ctx, tx, lKeys, rKeys)
+}
+
+func (*SearchAnalyticsSubspace) readCounters(ctx context.Context, tx transaction.Tx, lKeys []keys.Key, rKeys []keys.Key,
+) ([]*QueryStats, error) {
+	// Do not count for metadata operations
+	metrics.SetMetadataOperationInContext(ctx)
+
+	var result []*QueryStats
+	stats := make(map[string]*QueryStats)
+	for i := range lKeys {
+		it, err := tx.AtomicReadRange(ctx, lKeys[i], rKeys[i], true)
+		if err != nil {
+			return nil, err
+		}
+
+		var row kv.FdbBaseKeyValue[int64]
+		for it.Next(&row) {
+			if len(row.Key) < 2 {
+				return nil, errors.Internal("failed to decode search analytics key")
+			}
+			query, ok1 := row.Key[len(row.Key)-2].(string)
+			counter, ok2 := row.Key[len(row.Key)-1].(string)
+			if !ok1 || !ok2 {
+				return nil, errors.Internal("failed to decode search analytics key")
+			}
+
+			st, ok := stats[query]
+			if !ok {
+				st = &QueryStats{Query: query}
+				stats[query] = st
+				result = append(result, st)
+			}
+
+			switch counter {
+			case analyticsSearches:
+				st.Searches += row.Data
+			case analyticsNoResults:
+				st.NoResults += row.Data
+			case analyticsFound:
+				st.Found += row.Data
+			}
+		}
+		if err = it.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// Delete removes all the analytics of the target, it is called when the index or the collection is dropped.
+func (a *SearchAnalyticsSubspace) Delete(ctx context.Context, tx transaction.Tx, target *SearchAnalyticsTarget) error {
+	return tx.Delete(ctx, a.targetKey(target))
+}
+
+// TopQueries returns at most limit queries ordered by the number of searches.
+func TopQueries(stats []*QueryStats, limit int) []*QueryStats {
+	return topQueries(stats, limit, func(st *QueryStats) int64 { return st.Searches })
+}
+
+// NoResultQueries returns at most limit queries that didn't match any document ordered by the number of such
+// searches.
+func NoResultQueries(stats []*QueryStats, limit int) []*QueryStats {
+	return topQueries(stats, limit, func(st *QueryStats) int64 { return st.NoResults })
+}
+
+// SuggestQueries returns at most limit popular queries starting with the normalized prefix. The queries that never
+// matched any document are not suggested.
+func SuggestQueries(stats []*QueryStats, prefix string, limit int) []*QueryStats {
+	prefix = NormalizeQuery(prefix, 0)
+
+	return topQueries(stats, limit, func(st *QueryStats) int64 {
+		if !strings.HasPrefix(st.Query, prefix) || st.Query == prefix {
+			return 0
+		}
+		return st.Searches - st.NoResults
+	})
+}
+
+func topQueries(stats []*QueryStats, limit int, score func(st *QueryStats) int64) []*QueryStats {
+	var result []*QueryStats
+	for _, st := range stats {
+		if score(st) > 0 {
+			result = append(result, st)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		si, sj := score(result[i]), score(result[j])
+		if si != sj {
+			return si > sj
+		}
+		return result[i].Query < result[j].Query
+	})
+
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result
+}
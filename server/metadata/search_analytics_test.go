@@ -0,0 +1,169 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/server/transaction"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	require.Equal(t, "running shoes", NormalizeQuery("  Running   SHOES ", 0))
+	require.Equal(t, "runn", NormalizeQuery("running shoes", 4))
+	require.Equal(t, "café", NormalizeQuery("Café au lait", 4))
+	require.Equal(t, "", NormalizeQuery("   ", 10))
+}
+
+func TestSearchAnalyticsRanking(t *testing.T) {
+	stats := []*QueryStats{
+		{Query: "shoes", Searches: 10, NoResults: 0, Found: 200},
+		{Query: "shirt", Searches: 4, NoResults: 1, Found: 30},
+		{Query: "shoe laces", Searches: 6, NoResults: 6},
+		{Query: "shorts", Searches: 4, NoResults: 0, Found: 12},
+		{Query: "hat", Searches: 1, NoResults: 0, Found: 1},
+	}
+
+	queries := func(stats []*QueryStats) []string {
+		var q []string
+		for _, st := range stats {
+			q = append(q, st.Query)
+		}
+		return q
+	}
+
+	require.Equal(t, []string{"shoes", "shoe laces", "shirt", "shorts"}, queries(TopQueries(stats, 4)))
+	require.Equal(t, []string{"shoe laces", "shirt"}, queries(NoResultQueries(stats, 0)))
+	// queries without any result are not suggested, ties are ordered by the query
+	require.Equal(t, []string{"shoes", "shorts", "shirt"}, queries(SuggestQueries(stats, " SH", 10)))
+	require.Equal(t, []string{"shoes"}, queries(SuggestQueries(stats, "sho", 1)))
+	require.Empty(t, SuggestQueries(stats, "shoes", 10))
+}
+
+func TestSearchAnalyticsStore(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	a := NewSearchAnalyticsStore(newTestNameRegistry(t))
+	_ = kvStore.DropTable(ctx, a.SubspaceName)
+
+	tm := transaction.NewManager(kvStore)
+	tx, err := tm.StartTx(ctx)
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	index := &SearchAnalyticsTarget{NamespaceId: 1, Project: "p1", Index: "products"}
+	coll := &SearchAnalyticsTarget{NamespaceId: 1, Project: "p1", Collection: "products"}
+
+	now := time.Now()
+	yesterday := now.Add(-analyticsDay)
+	require.NoError(t, a.Record(ctx, tx, index, "shoes", 10, yesterday))
+	require.NoError(t, a.Record(ctx, tx, index, "shoes", 5, now))
+	require.NoError(t, a.Record(ctx, tx, index, "sandals", 0, now))
+	require.NoError(t, a.Record(ctx, tx, index, "shirt", 3, now))
+	require.NoError(t, a.Record(ctx, tx, coll, "boots", 2, now))
+
+	stats, err := a.Rollup(ctx, tx, index, 2, now)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []*QueryStats{
+		{Query: "shoes", Searches: 2, Found: 15},
+		{Query: "sandals", Searches: 1, NoResults: 1},
+		{Query: "shirt", Searches: 1, Found: 3},
+	}, stats)
+
+	stats, err = a.Rollup(ctx, tx, index, 1, now)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []*QueryStats{
+		{Query: "shoes", Searches: 1, Found: 5},
+		{Query: "sandals", Searches: 1, NoResults: 1},
+		{Query: "shirt", Searches: 1, Found: 3},
+	}, stats)
+
+	// only the queries with the prefix are read
+	stats, err = a.RollupPrefix(ctx, tx, index, " SH", 2, now)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []*QueryStats{
+		{Query: "shoes", Searches: 2, Found: 15},
+		{Query: "shirt", Searches: 1, Found: 3},
+	}, stats)
+
+	stats, err = a.Rollup(ctx, tx, coll, 2, now)
+	require.NoError(t, err)
+	require.Equal(t, []*QueryStats{{Query: "boots", Searches: 1, Found: 2}}, stats)
+
+	require.NoError(t, a.Delete(ctx, tx, index))
+	stats, err = a.Rollup(ctx, tx, index, 2, now)
+	require.NoError(t, err)
+	require.Empty(t, stats)
+}
+
+func TestSearchAnalyticsExpire(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	a := NewSearchAnalyticsStore(newTestNameRegistry(t))
+	_ = kvStore.DropTable(ctx, a.SubspaceName)
+
+	tm := transaction.NewManager(kvStore)
+	tx, err := tm.StartTx(ctx)
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	index := &SearchAnalyticsTarget{NamespaceId: 1, Project: "p1", Index: "products"}
+
+	now := time.Now()
+	require.NoError(t, a.Record(ctx, tx, index, "shoes", 1, now.Add(-10*analyticsDay)))
+	require.NoError(t, a.Record(ctx, tx, index, "shoes", 1, now.Add(-3*analyticsDay)))
+	require.NoError(t, a.Record(ctx, tx, index, "shoes", 1, now.Add(-analyticsDay)))
+	require.NoError(t, a.Record(ctx, tx, index, "shoes", 1, now))
+
+	// all the days before the retention are dropped, not only the day falling out of it
+	require.NoError(t, a.Expire(ctx, tx, index, now, 2))
+	stats, err := a.Rollup(ctx, tx, index, 30, now)
+	require.NoError(t, err)
+	require.Equal(t, []*QueryStats{{Query: "shoes", Searches: 2, Found: 2}}, stats)
+}
+
+func TestSearchAnalyticsFlush(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	a := NewSearchAnalyticsStore(newTestNameRegistry(t))
+	_ = kvStore.DropTable(ctx, a.SubspaceName)
+
+	index := &SearchAnalyticsTarget{NamespaceId: 1, Project: "p1", Index: "products"}
+
+	bucket := analyticsBucket(time.Now())
+	a.pending[analyticsEntry{target: *index, bucket: bucket, query: "shoes"}] = &QueryStats{Query: "shoes", Searches: 3, Found: 7}
+	a.pending[analyticsEntry{target: *index, bucket: bucket, query: "sandals"}] = &QueryStats{Query: "sandals", Searches: 1, NoResults: 1}
+
+	tm := transaction.NewManager(kvStore)
+	a.flush(ctx, tm, time.Now())
+	require.Empty(t, a.pending)
+
+	tx, err := tm.StartTx(ctx)
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	stats, err := a.Rollup(ctx, tx, index, 1, time.Now())
+	require.NoError(t, err)
+	require.ElementsMatch(t, []*QueryStats{
+		{Query: "shoes", Searches: 3, Found: 7},
+		{Query: "sandals", Searches: 1, NoResults: 1},
+	}, stats)
+}
@@ -0,0 +1,145 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+	"time"
+
+	"github.com/tigrisdata/tigris/keys"
+	"github.com/tigrisdata/tigris/server/transaction"
+)
+
+// The report of the latest verification of the search index of a collection. A new verification request overwrites
+// the previous report.
+//
+// The FDB structure is:
+// ["search_verify", "version", namespace, project, branch, collection] = SearchVerifyReport
+
+const (
+	searchVerifyValueVersion int32 = 1
+	searchVerifyKeyVersion   byte  = 1
+
+	// MaxVerifySamples is the maximum number of document ids kept in the report for each kind of inconsistency.
+	MaxVerifySamples = 100
+)
+
+const (
+	VerifyStatusQueued    = "queued"
+	VerifyStatusRunning   = "running"
+	VerifyStatusCompleted = "completed"
+	VerifyStatusFailed    = "failed"
+)
+
+// SearchVerifyReport is the outcome of comparing the documents of a collection with its search index.
+type SearchVerifyReport struct {
+	Id          string    `json:"id"`
+	Status      string    `json:"status"`
+	Repair      bool      `json:"repair,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	StartedAt   time.Time `json:"started_at,omitempty"`
+	CompletedAt time.Time `json:"completed_at,omitempty"`
+	Error       string    `json:"error,omitempty"`
+
+	// Checked is the number of documents read from the collection.
+	Checked int64 `json:"checked"`
+	// Indexed is the number of documents read from the search index.
+	Indexed int64 `json:"indexed"`
+
+	// NumMissing is the number of documents of the collection which are not in the search index.
+	NumMissing int64 `json:"num_missing"`
+	// NumExtra is the number of search documents which don't exist in the collection anymore.
+	NumExtra int64 `json:"num_extra"`
+	// NumStale is the number of search documents which don't match the version of the collection document.
+	NumStale int64 `json:"num_stale"`
+	// Repaired is the number of documents indexed or removed to fix the inconsistencies.
+	Repaired int64 `json:"repaired"`
+
+	// Missing, Extra and Stale are samples of the inconsistent document ids, at most MaxVerifySamples of each.
+	Missing []string `json:"missing,omitempty"`
+	Extra   []string `json:"extra,omitempty"`
+	Stale   []string `json:"stale,omitempty"`
+}
+
+func (r *SearchVerifyReport) AddMissing(id string) {
+	r.NumMissing++
+	r.Missing = addVerifySample(r.Missing, id)
+}
+
+func (r *SearchVerifyReport) AddExtra(id string) {
+	r.NumExtra++
+	r.Extra = addVerifySample(r.Extra, id)
+}
+
+func (r *SearchVerifyReport) AddStale(id string) {
+	r.NumStale++
+	r.Stale = addVerifySample(r.Stale, id)
+}
+
+func addVerifySample(samples []string, id string) []string {
+	if len(samples) >= MaxVerifySamples {
+		return samples
+	}
+
+	return append(samples, id)
+}
+
+type SearchVerifySubspace struct {
+	metadataSubspace
+}
+
+func NewSearchVerifyStore(nameRegistry *NameRegistry) *SearchVerifySubspace {
+	return &SearchVerifySubspace{
+		metadataSubspace{
+			SubspaceName: nameRegistry.SearchVerifySubspaceName(),
+			KeyVersion:   []byte{searchVerifyKeyVersion},
+		},
+	}
+}
+
+func (s *SearchVerifySubspace) getKey(task *IndexBuildTask) keys.Key {
+	return keys.NewKey(s.SubspaceName, s.KeyVersion, task.NamespaceId, task.ProjName, task.Branch, task.CollName)
+}
+
+// Put stores the report as the latest report of the collection.
+func (s *SearchVerifySubspace) Put(ctx context.Context, tx transaction.Tx, task *IndexBuildTask,
+	report *SearchVerifyReport,
+) error {
+	return s.updateMetadata(ctx, tx, nil,
+		s.getKey(task),
+		searchVerifyValueVersion,
+		report)
+}
+
+// Get returns the latest report of the collection or errors.ErrNotFound if the collection was never verified.
+func (s *SearchVerifySubspace) Get(ctx context.Context, tx transaction.Tx, task *IndexBuildTask,
+) (*SearchVerifyReport, error) {
+	var report SearchVerifyReport
+
+	if err := s.getMetadata(ctx, tx, nil,
+		s.getKey(task),
+		&report,
+	); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// Delete removes the report, it is called when the collection is dropped.
+func (s *SearchVerifySubspace) Delete(ctx context.Context, tx transaction.Tx, task *IndexBuildTask) error {
+	return s.deleteMetadata(ctx, tx, nil,
+		s.getKey(task))
+}
@@ -0,0 +1,84 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/server/transaction"
+)
+
+func TestSearchVerifyReportSamples(t *testing.T) {
+	var report SearchVerifyReport
+	for i := 0; i < MaxVerifySamples+10; i++ {
+		report.AddMissing(fmt.Sprintf("m%d", i))
+	}
+	report.AddExtra("e1")
+	report.AddStale("s1")
+	report.AddStale("s2")
+
+	require.Equal(t, int64(MaxVerifySamples+10), report.NumMissing)
+	require.Len(t, report.Missing, MaxVerifySamples)
+	require.Equal(t, "m0", report.Missing[0])
+	require.Equal(t, int64(1), report.NumExtra)
+	require.Equal(t, []string{"e1"}, report.Extra)
+	require.Equal(t, int64(2), report.NumStale)
+	require.Equal(t, []string{"s1", "s2"}, report.Stale)
+}
+
+func TestSearchVerifyStore(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	s := NewSearchVerifyStore(newTestNameRegistry(t))
+	_ = kvStore.DropTable(ctx, s.SubspaceName)
+
+	tm := transaction.NewManager(kvStore)
+	tx, err := tm.StartTx(ctx)
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	task := &IndexBuildTask{NamespaceId: "ns1", ProjName: "p1", Branch: MainBranch, CollName: "products"}
+
+	_, err = s.Get(ctx, tx, task)
+	require.Equal(t, errors.ErrNotFound, err)
+
+	report := &SearchVerifyReport{Id: "1", Status: VerifyStatusQueued, CreatedAt: time.Now().UTC()}
+	require.NoError(t, s.Put(ctx, tx, task, report))
+
+	report.Status = VerifyStatusCompleted
+	report.AddMissing("10")
+	require.NoError(t, s.Put(ctx, tx, task, report))
+
+	actual, err := s.Get(ctx, tx, task)
+	require.NoError(t, err)
+	require.Equal(t, VerifyStatusCompleted, actual.Status)
+	require.Equal(t, int64(1), actual.NumMissing)
+	require.Equal(t, []string{"10"}, actual.Missing)
+	require.True(t, report.CreatedAt.Equal(actual.CreatedAt))
+
+	other := &IndexBuildTask{NamespaceId: "ns1", ProjName: "p1", Branch: MainBranch, CollName: "orders"}
+	_, err = s.Get(ctx, tx, other)
+	require.Equal(t, errors.ErrNotFound, err)
+
+	require.NoError(t, s.Delete(ctx, tx, task))
+	_, err = s.Get(ctx, tx, task)
+	require.Equal(t, errors.ErrNotFound, err)
+}
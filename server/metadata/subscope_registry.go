@@ -48,6 +48,9 @@ type NameRegistry struct {
 	ClusterSB   string
 	VersionKey  string
 	QueueSB     string
+	AnalyticsSB string
+	VerifySB    string
+	RealtimeSB  string
 
 	BaseCounterValue uint32
 }
@@ -61,6 +64,9 @@ var DefaultNameRegistry = &NameRegistry{
 	NamespaceSB: "namespace",
 	ClusterSB:   "cluster",
 	QueueSB:     "queue",
+	AnalyticsSB: "search_analytics",
+	VerifySB:    "search_verify",
+	RealtimeSB:  "realtime",
 
 	BaseCounterValue: reservedBaseValue,
 }
@@ -97,6 +103,18 @@ func (d *NameRegistry) QueueSubspaceName() []byte {
 	return []byte(d.QueueSB)
 }
 
+func (d *NameRegistry) SearchAnalyticsSubspaceName() []byte {
+	return []byte(d.AnalyticsSB)
+}
+
+func (d *NameRegistry) SearchVerifySubspaceName() []byte {
+	return []byte(d.VerifySB)
+}
+
+func (d *NameRegistry) RealtimeSubspaceName() []byte {
+	return []byte(d.RealtimeSB)
+}
+
 func (d *NameRegistry) GetVersionKey() []byte {
 	return []byte(d.VersionKey)
 }
@@ -114,6 +132,9 @@ func newTestNameRegistry(t *testing.T) *NameRegistry {
 		NamespaceSB: "test_namespace_" + s,
 		ClusterSB:   "test_cluster_" + s,
 		QueueSB:     "test_queue_" + s,
+		AnalyticsSB: "test_search_analytics_" + s,
+		VerifySB:    "test_search_verify_" + s,
+		RealtimeSB:  "test_realtime_" + s,
 		VersionKey:  "test_version_key" + s,
 
 		BaseCounterValue: r.Uint32(),
@@ -27,13 +27,13 @@ import (
 	jsoniter "github.com/json-iterator/go"
 	"github.com/rs/zerolog/log"
 	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/keys"
 	"github.com/tigrisdata/tigris/schema"
 	"github.com/tigrisdata/tigris/server/config"
 	"github.com/tigrisdata/tigris/server/transaction"
 	"github.com/tigrisdata/tigris/store/kv"
 	"github.com/tigrisdata/tigris/store/search"
 	ulog "github.com/tigrisdata/tigris/util/log"
-	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
 )
 
 type NamespaceType string
@@ -115,7 +115,7 @@ type TenantManager struct {
 	// type mismatch then "pack" those values during indexing. For all new collections reloaded there
 	// shouldn't be any mismatch. Also, this logic only triggers during reloading of tenants or
 	// restart where we don't know when the collection was created.
-	searchSchemasSnapshot map[string]*tsApi.CollectionResponse
+	searchSchemasSnapshot map[string]*search.CollectionInfo
 }
 
 func (m *TenantManager) GetNamespaceStore() *NamespaceSubspace {
@@ -211,6 +211,10 @@ func (m *TenantManager) GetQueue() *QueueSubspace {
 	return m.metaStore.queueStore
 }
 
+func (m *TenantManager) GetRealtimeStore() *RealtimeSubspace {
+	return m.metaStore.Realtime()
+}
+
 // CreateTenant is a thread safe implementation of creating a new tenant. It returns an error if it already exists.
 func (m *TenantManager) CreateTenant(ctx context.Context, tx transaction.Tx, namespace Namespace) (Namespace, error) {
 	m.Lock()
@@ -651,7 +655,7 @@ func NewTenant(namespace Namespace, kvStore kv.TxStore, searchStore search.Store
 // thread will actually perform reload. This is a blocking API which means if most of the requests detected that the
 // tenant state is stale then they all will block till one of them will reload the tenant state from the database. All
 // the blocking transactions will be restarted to ensure they see the latest view of the tenant.
-func (tenant *Tenant) Reload(ctx context.Context, tx transaction.Tx, version Version, searchSchemasSnapshot map[string]*tsApi.CollectionResponse) error {
+func (tenant *Tenant) Reload(ctx context.Context, tx transaction.Tx, version Version, searchSchemasSnapshot map[string]*search.CollectionInfo) error {
 	if !tenant.shouldReload(version) {
 		return nil
 	}
@@ -679,7 +683,7 @@ func (tenant *Tenant) shouldReload(currentVersion Version) bool {
 // loads all the databases, it loads the resources for each one. Once databases are reloaded then it performs the same
 // logic for search indexes. Once search indexes are loaded it links back the search indexes to the Tigris Collection
 // if the source for these search indexes is Tigris.
-func (tenant *Tenant) reload(ctx context.Context, tx transaction.Tx, currentVersion Version, searchSchemasSnapshot map[string]*tsApi.CollectionResponse) error {
+func (tenant *Tenant) reload(ctx context.Context, tx transaction.Tx, currentVersion Version, searchSchemasSnapshot map[string]*search.CollectionInfo) error {
 	// reset
 	tenant.projects = make(map[string]*Project)
 	tenant.idToDatabaseMap = make(map[uint32]*Database)
@@ -715,11 +719,20 @@ func (tenant *Tenant) reload(ctx context.Context, tx transaction.Tx, currentVers
 		tenant.idToDatabaseMap[meta.ID] = database
 	}
 
-	// load search indexes, this is essentially loading all the search indexes created by the user and attaching it to
-	// the project object.
+	// load caches and search indexes, this is essentially loading all the caches and the search indexes created by the
+	// user and attaching them to the project object.
 	for _, p := range tenant.projects {
-		var err error
-		if p.search, err = tenant.reloadSearch(ctx, tx, p, searchSchemasSnapshot); err != nil {
+		projMetadata, err := tenant.namespaceStore.GetProjectMetadata(ctx, tx, tenant.namespace.Id(), p.Name())
+		if err != nil {
+			return errors.Internal("failed to get project metadata for project %s", p.Name())
+		}
+
+		p.caches = make(map[string]*CacheMetadata)
+		for i := range projMetadata.CachesMetadata {
+			p.caches[projMetadata.CachesMetadata[i].Name] = &projMetadata.CachesMetadata[i]
+		}
+
+		if p.search, err = tenant.reloadSearch(ctx, tx, p, projMetadata, searchSchemasSnapshot); err != nil {
 			return err
 		}
 		for _, index := range p.search.indexes {
@@ -747,7 +760,7 @@ func (tenant *Tenant) reload(ctx context.Context, tx transaction.Tx, currentVers
 // reloadDatabase is called by tenant to reload the database state. This also loads all the collections that are part of
 // this database and implicit search index for these collections.
 func (tenant *Tenant) reloadDatabase(ctx context.Context, tx transaction.Tx, dbName string, dbId uint32,
-	searchSchemasSnapshot map[string]*tsApi.CollectionResponse,
+	searchSchemasSnapshot map[string]*search.CollectionInfo,
 ) (*Database, error) {
 	database := NewDatabase(dbId, dbName)
 
@@ -771,7 +784,7 @@ func (tenant *Tenant) reloadDatabase(ctx context.Context, tx transaction.Tx, dbN
 			continue
 		}
 
-		var fieldsInSearch []tsApi.Field
+		var fieldsInSearch []schema.SearchStoreField
 		searchCollectionName := tenant.getSearchCollName(dbName, coll)
 		if searchSchema, ok := searchSchemasSnapshot[searchCollectionName]; ok {
 			fieldsInSearch = searchSchema.Fields
@@ -806,12 +819,9 @@ func (tenant *Tenant) reloadDatabase(ctx context.Context, tx transaction.Tx, dbN
 }
 
 // reloadSearch is responsible for reloading all the search indexes inside a single project.
-func (tenant *Tenant) reloadSearch(ctx context.Context, tx transaction.Tx, project *Project, searchSchemasSnapshot map[string]*tsApi.CollectionResponse) (*Search, error) {
-	projMetadata, err := tenant.namespaceStore.GetProjectMetadata(ctx, tx, tenant.namespace.Id(), project.Name())
-	if err != nil {
-		return nil, errors.Internal("failed to get project metadata for project %s", project.Name())
-	}
-
+func (tenant *Tenant) reloadSearch(ctx context.Context, tx transaction.Tx, project *Project, projMetadata *ProjectMetadata,
+	searchSchemasSnapshot map[string]*search.CollectionInfo,
+) (*Search, error) {
 	searchObj := NewSearch()
 
 	for _, searchMD := range projMetadata.SearchMetadata {
@@ -830,12 +840,15 @@ func (tenant *Tenant) reloadSearch(ctx context.Context, tx transaction.Tx, proje
 			continue
 		}
 
-		var fieldsInSearchStore []tsApi.Field
+		var fieldsInSearchStore []schema.SearchStoreField
 		searchStoreIndexName := tenant.Encoder.EncodeSearchTableName(tenant.namespace.Id(), project.Id(), searchMD.Name)
 		if searchIndexInStore, ok := searchSchemasSnapshot[searchStoreIndexName]; ok {
 			fieldsInSearchStore = searchIndexInStore.Fields
 		}
-		searchObj.indexes[searchMD.Name] = schema.NewSearchIndex(schV.Version, searchStoreIndexName, searchFactory, fieldsInSearchStore)
+		index := schema.NewSearchIndex(schV.Version, searchStoreIndexName, searchFactory, fieldsInSearchStore)
+		index.Stopwords = searchMD.Stopwords()
+		index.CurationRules = schema.CompileCurationRules(searchMD.CurationRules)
+		searchObj.indexes[searchMD.Name] = index
 	}
 
 	return searchObj, nil
@@ -924,12 +937,12 @@ func (tenant *Tenant) updateSearchIndex(ctx context.Context, tx transaction.Tx,
 	}
 
 	updatedIndex := schema.NewSearchIndex(version, index.StoreIndexName(), factory, previousIndexInStore.Fields)
+	updatedIndex.Stopwords = index.Stopwords
+	updatedIndex.CurationRules = index.CurationRules
 
 	// update indexing store schema if there is a change
 	if deltaFields := updatedIndex.GetSearchDeltaFields(index.QueryableFields, previousIndexInStore.Fields); len(deltaFields) > 0 {
-		if err := tenant.searchStore.UpdateCollection(ctx, updatedIndex.StoreIndexName(), &tsApi.CollectionUpdateSchema{
-			Fields: deltaFields,
-		}); err != nil {
+		if err := tenant.searchStore.UpdateCollection(ctx, updatedIndex.StoreIndexName(), deltaFields); err != nil {
 			return err
 		}
 	}
@@ -1001,6 +1014,11 @@ func (tenant *Tenant) deleteSearchIndex(ctx context.Context, tx transaction.Tx,
 		return errors.Internal(err.Error())
 	}
 
+	analytics := &SearchAnalyticsTarget{NamespaceId: tenant.namespace.Id(), Project: project.name, Index: index.Name}
+	if err = tenant.MetaStore.SearchAnalytics().Delete(ctx, tx, analytics); err != nil {
+		return errors.Internal(err.Error())
+	}
+
 	if err = tenant.kvStore.DropTable(ctx, tenant.Encoder.EncodeFDBSearchTableName(index.StoreIndexName())); err != nil {
 		return errors.Internal(err.Error())
 	}
@@ -1026,7 +1044,253 @@ func (tenant *Tenant) ListSearchIndexes(_ context.Context, _ transaction.Tx, pro
 	return indexes, nil
 }
 
-func (tenant *Tenant) CreateCache(ctx context.Context, tx transaction.Tx, project string, cache string, currentSub string) (bool, error) {
+// UpsertSearchSynonym creates or replaces the synonym of the search index and pushes it to the search store.
+func (tenant *Tenant) UpsertSearchSynonym(ctx context.Context, tx transaction.Tx, project *Project, indexName string, synonym search.Synonym) error {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	index, ok := project.search.GetIndex(indexName)
+	if !ok {
+		return NewSearchIndexNotFoundErr(indexName)
+	}
+
+	return tenant.updateSearchMetadata(ctx, tx, project, indexName, func(searchMD *SearchMetadata) error {
+		found := false
+		for i := range searchMD.Synonyms {
+			if searchMD.Synonyms[i].Id == synonym.Id {
+				searchMD.Synonyms[i] = synonym
+				found = true
+				break
+			}
+		}
+		if !found {
+			searchMD.Synonyms = append(searchMD.Synonyms, synonym)
+		}
+
+		return tenant.searchStore.UpsertSynonym(ctx, index.StoreIndexName(), synonym)
+	})
+}
+
+// DeleteSearchSynonym removes the synonym from the search index and from the search store.
+func (tenant *Tenant) DeleteSearchSynonym(ctx context.Context, tx transaction.Tx, project *Project, indexName string, id string) error {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	index, ok := project.search.GetIndex(indexName)
+	if !ok {
+		return NewSearchIndexNotFoundErr(indexName)
+	}
+
+	return tenant.updateSearchMetadata(ctx, tx, project, indexName, func(searchMD *SearchMetadata) error {
+		foundIdx := -1
+		for i := range searchMD.Synonyms {
+			if searchMD.Synonyms[i].Id == id {
+				foundIdx = i
+				break
+			}
+		}
+		if foundIdx == -1 {
+			return errors.NotFound("synonym not found '%s'", id)
+		}
+
+		searchMD.Synonyms = append(searchMD.Synonyms[:foundIdx], searchMD.Synonyms[foundIdx+1:]...)
+		if err := tenant.searchStore.DeleteSynonym(ctx, index.StoreIndexName(), id); err != nil && !search.IsErrNotFound(err) {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// ListSearchSynonyms returns the synonyms of the search index.
+func (tenant *Tenant) ListSearchSynonyms(ctx context.Context, tx transaction.Tx, project *Project, indexName string) ([]search.Synonym, error) {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	searchMD, err := tenant.getSearchMetadata(ctx, tx, project, indexName)
+	if err != nil {
+		return nil, err
+	}
+
+	return searchMD.Synonyms, nil
+}
+
+// UpsertSearchStopwords creates or replaces the stopword set of the search index. The stopwords of all the sets are
+// removed from the search queries of the index.
+func (tenant *Tenant) UpsertSearchStopwords(ctx context.Context, tx transaction.Tx, project *Project, indexName string, set search.StopwordSet) error {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	return tenant.updateSearchIndexSettings(ctx, tx, project, indexName, func(searchMD *SearchMetadata) error {
+		found := false
+		for i := range searchMD.StopwordSets {
+			if searchMD.StopwordSets[i].Id == set.Id {
+				searchMD.StopwordSets[i] = set
+				found = true
+				break
+			}
+		}
+		if !found {
+			searchMD.StopwordSets = append(searchMD.StopwordSets, set)
+		}
+
+		return nil
+	})
+}
+
+// DeleteSearchStopwords removes the stopword set from the search index.
+func (tenant *Tenant) DeleteSearchStopwords(ctx context.Context, tx transaction.Tx, project *Project, indexName string, id string) error {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	return tenant.updateSearchIndexSettings(ctx, tx, project, indexName, func(searchMD *SearchMetadata) error {
+		for i := range searchMD.StopwordSets {
+			if searchMD.StopwordSets[i].Id == id {
+				searchMD.StopwordSets = append(searchMD.StopwordSets[:i], searchMD.StopwordSets[i+1:]...)
+				return nil
+			}
+		}
+
+		return errors.NotFound("stopword set not found '%s'", id)
+	})
+}
+
+// ListSearchStopwords returns the stopword sets of the search index.
+func (tenant *Tenant) ListSearchStopwords(ctx context.Context, tx transaction.Tx, project *Project, indexName string) ([]search.StopwordSet, error) {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	searchMD, err := tenant.getSearchMetadata(ctx, tx, project, indexName)
+	if err != nil {
+		return nil, err
+	}
+
+	return searchMD.StopwordSets, nil
+}
+
+// UpsertSearchCurationRule creates or replaces the curation rule of the search index.
+func (tenant *Tenant) UpsertSearchCurationRule(ctx context.Context, tx transaction.Tx, project *Project, indexName string, rule schema.CurationRule) error {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	return tenant.updateSearchIndexSettings(ctx, tx, project, indexName, func(searchMD *SearchMetadata) error {
+		for i := range searchMD.CurationRules {
+			if searchMD.CurationRules[i].Id == rule.Id {
+				searchMD.CurationRules[i] = rule
+				return nil
+			}
+		}
+
+		searchMD.CurationRules = append(searchMD.CurationRules, rule)
+		return nil
+	})
+}
+
+// DeleteSearchCurationRule removes the curation rule from the search index.
+func (tenant *Tenant) DeleteSearchCurationRule(ctx context.Context, tx transaction.Tx, project *Project, indexName string, id string) error {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	return tenant.updateSearchIndexSettings(ctx, tx, project, indexName, func(searchMD *SearchMetadata) error {
+		for i := range searchMD.CurationRules {
+			if searchMD.CurationRules[i].Id == id {
+				searchMD.CurationRules = append(searchMD.CurationRules[:i], searchMD.CurationRules[i+1:]...)
+				return nil
+			}
+		}
+
+		return errors.NotFound("curation rule not found '%s'", id)
+	})
+}
+
+// ListSearchCurationRules returns the curation rules of the search index.
+func (tenant *Tenant) ListSearchCurationRules(ctx context.Context, tx transaction.Tx, project *Project, indexName string) ([]schema.CurationRule, error) {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	searchMD, err := tenant.getSearchMetadata(ctx, tx, project, indexName)
+	if err != nil {
+		return nil, err
+	}
+
+	return searchMD.CurationRules, nil
+}
+
+// SyncSearchIndexSettings pushes the synonyms stored in the metadata to the search store. This is needed whenever the
+// index is recreated in the search store, for example, when it is rebuilt.
+func (tenant *Tenant) SyncSearchIndexSettings(ctx context.Context, tx transaction.Tx, project *Project, index *schema.SearchIndex) error {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	searchMD, err := tenant.getSearchMetadata(ctx, tx, project, index.Name)
+	if err != nil {
+		return err
+	}
+
+	for _, synonym := range searchMD.Synonyms {
+		if err = tenant.searchStore.UpsertSynonym(ctx, index.StoreIndexName(), synonym); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateSearchIndexSettings applies the change to the metadata of the search index. The cached index is not modified
+// here, the settings are applied to the search queries once the metadata is reloaded after the transaction commits.
+func (tenant *Tenant) updateSearchIndexSettings(ctx context.Context, tx transaction.Tx, project *Project, indexName string, fn func(*SearchMetadata) error) error {
+	if _, ok := project.search.GetIndex(indexName); !ok {
+		return NewSearchIndexNotFoundErr(indexName)
+	}
+
+	return tenant.updateSearchMetadata(ctx, tx, project, indexName, fn)
+}
+
+func (tenant *Tenant) getSearchMetadata(ctx context.Context, tx transaction.Tx, project *Project, indexName string) (*SearchMetadata, error) {
+	metadata, err := tenant.namespaceStore.GetProjectMetadata(ctx, tx, tenant.namespace.Id(), project.name)
+	if err != nil {
+		return nil, errors.Internal("failed to get project metadata for project %s", project.name)
+	}
+
+	for i := range metadata.SearchMetadata {
+		if metadata.SearchMetadata[i].Name == indexName {
+			return &metadata.SearchMetadata[i], nil
+		}
+	}
+
+	return nil, NewSearchIndexNotFoundErr(indexName)
+}
+
+// updateSearchMetadata applies the change to the metadata of the search index and persists the project metadata.
+func (tenant *Tenant) updateSearchMetadata(ctx context.Context, tx transaction.Tx, project *Project, indexName string, fn func(*SearchMetadata) error) error {
+	metadata, err := tenant.namespaceStore.GetProjectMetadata(ctx, tx, tenant.namespace.Id(), project.name)
+	if err != nil {
+		return errors.Internal("failed to get project metadata for project %s", project.name)
+	}
+
+	foundIdx := -1
+	for i := range metadata.SearchMetadata {
+		if metadata.SearchMetadata[i].Name == indexName {
+			foundIdx = i
+			break
+		}
+	}
+	if foundIdx == -1 {
+		return NewSearchIndexNotFoundErr(indexName)
+	}
+
+	if err = fn(&metadata.SearchMetadata[foundIdx]); err != nil {
+		return err
+	}
+
+	if err = tenant.namespaceStore.UpdateProjectMetadata(ctx, tx, tenant.namespace.Id(), project.name, metadata); err != nil {
+		return errors.Internal("failed to update project metadata for search index settings")
+	}
+
+	return nil
+}
+
+func (tenant *Tenant) CreateCache(ctx context.Context, tx transaction.Tx, project string, cache string, currentSub string, defaultTTLMs uint64) (bool, error) {
 	tenant.Lock()
 	defer tenant.Unlock()
 
@@ -1043,9 +1307,10 @@ func (tenant *Tenant) CreateCache(ctx context.Context, tx transaction.Tx, projec
 		}
 	}
 	projMetadata.CachesMetadata = append(projMetadata.CachesMetadata, CacheMetadata{
-		Name:      cache,
-		Creator:   currentSub,
-		CreatedAt: time.Now().Unix(),
+		Name:         cache,
+		Creator:      currentSub,
+		CreatedAt:    time.Now().Unix(),
+		DefaultTTLMs: defaultTTLMs,
 	})
 	err = tenant.namespaceStore.UpdateProjectMetadata(ctx, tx, tenant.namespace.Id(), project, projMetadata)
 	if err != nil {
@@ -1055,6 +1320,19 @@ func (tenant *Tenant) CreateCache(ctx context.Context, tx transaction.Tx, projec
 }
 
 func (tenant *Tenant) ListCaches(ctx context.Context, tx transaction.Tx, project string) ([]string, error) {
+	cachesMetadata, err := tenant.ListCachesMetadata(ctx, tx, project)
+	if err != nil {
+		return nil, err
+	}
+	caches := make([]string, len(cachesMetadata))
+	for i := range cachesMetadata {
+		caches[i] = cachesMetadata[i].Name
+	}
+	return caches, nil
+}
+
+// ListCachesMetadata returns the metadata of all the caches of the project.
+func (tenant *Tenant) ListCachesMetadata(ctx context.Context, tx transaction.Tx, project string) ([]CacheMetadata, error) {
 	tenant.Lock()
 	defer tenant.Unlock()
 	projMetadata, err := tenant.namespaceStore.GetProjectMetadata(ctx, tx, tenant.namespace.Id(), project)
@@ -1062,13 +1340,9 @@ func (tenant *Tenant) ListCaches(ctx context.Context, tx transaction.Tx, project
 		return nil, errors.Internal("Failed to get project metadata for project %s", project)
 	}
 	if projMetadata.CachesMetadata == nil {
-		return []string{}, nil
+		return []CacheMetadata{}, nil
 	}
-	caches := make([]string, len(projMetadata.CachesMetadata))
-	for i := range projMetadata.CachesMetadata {
-		caches[i] = projMetadata.CachesMetadata[i].Name
-	}
-	return caches, nil
+	return projMetadata.CachesMetadata, nil
 }
 
 func (tenant *Tenant) DeleteCache(ctx context.Context, tx transaction.Tx, project string, cache string) (bool, error) {
@@ -1183,6 +1457,10 @@ func (tenant *Tenant) DeleteProject(ctx context.Context, tx transaction.Tx, proj
 		}
 	}
 
+	if err := tenant.MetaStore.Realtime().DeleteProject(ctx, tx, tenant.namespace.Id(), proj.Id()); err != nil {
+		return true, err
+	}
+
 	// drop metadata entry
 	if err := tenant.namespaceStore.DeleteProjectMetadata(ctx, tx, tenant.namespace.Id(), projName); err != nil {
 		log.Err(err).Msg("failed to delete project metadata")
@@ -1258,6 +1536,12 @@ func (m *TenantManager) DeleteTenant(ctx context.Context, tx transaction.Tx, ten
 			return errors.Internal("Failed to delete projects for namespace")
 		}
 	}
+
+	// the realtime messages still enqueued can't be published anymore
+	if err := tx.Delete(ctx, keys.NewKey(m.encoder.EncodeOutboxTableName(tenantToDelete.namespace))); err != nil {
+		return err
+	}
+
 	return m.metaStore.UnReserveNamespace(ctx, tx, tenantToDelete.namespace.StrId())
 }
 
@@ -1534,7 +1818,7 @@ func (tenant *Tenant) updateCollection(ctx context.Context, tx transaction.Tx, d
 		return err
 	}
 
-	existingSearch := &tsApi.CollectionResponse{}
+	existingSearch := &search.CollectionInfo{}
 	if config.DefaultConfig.Search.WriteEnabled {
 		existingSearch, err = tenant.searchStore.DescribeCollection(ctx, existingCollection.ImplicitSearchIndex.StoreIndexName())
 		if err != nil {
@@ -1614,9 +1898,7 @@ func (tenant *Tenant) updateCollection(ctx context.Context, tx transaction.Tx, d
 	if config.DefaultConfig.Search.WriteEnabled {
 		// update indexing store schema if there is a change
 		if deltaFields := collection.ImplicitSearchIndex.GetSearchDeltaFields(existingCollection.ImplicitSearchIndex.QueryableFields, schFactory.Fields); len(deltaFields) > 0 {
-			if err := tenant.searchStore.UpdateCollection(ctx, collection.ImplicitSearchIndex.StoreIndexName(), &tsApi.CollectionUpdateSchema{
-				Fields: deltaFields,
-			}); err != nil {
+			if err := tenant.searchStore.UpdateCollection(ctx, collection.ImplicitSearchIndex.StoreIndexName(), deltaFields); err != nil {
 				return err
 			}
 		}
@@ -1737,6 +2019,26 @@ func (tenant *Tenant) dropCollection(ctx context.Context, tx transaction.Tx, db
 		return err
 	}
 
+	verifyTask := &IndexBuildTask{
+		NamespaceId: tenant.namespace.StrId(),
+		ProjName:    db.DbName(),
+		Branch:      db.BranchName(),
+		CollName:    collectionName,
+	}
+	if err := tenant.MetaStore.SearchVerify().Delete(ctx, tx, verifyTask); err != nil {
+		return err
+	}
+
+	analytics := &SearchAnalyticsTarget{
+		NamespaceId: tenant.namespace.Id(),
+		Project:     db.DbName(),
+		Branch:      db.BranchName(),
+		Collection:  collectionName,
+	}
+	if err := tenant.MetaStore.SearchAnalytics().Delete(ctx, tx, analytics); err != nil {
+		return err
+	}
+
 	tableName, err := tenant.Encoder.EncodeTableName(tenant.namespace, db, cHolder.collection)
 	if err != nil {
 		return err
@@ -1952,6 +2254,7 @@ type Project struct {
 	id               uint32
 	name             string
 	search           *Search
+	caches           map[string]*CacheMetadata
 	database         *Database
 	databaseBranches map[string]*Database
 }
@@ -2002,6 +2305,20 @@ func (p *Project) GetSearch() *Search {
 	return p.search
 }
 
+// GetCache returns the metadata of the cache, it is loaded with the project so the settings of the cache are
+// available without reading the project metadata.
+func (p *Project) GetCache(name string) (*CacheMetadata, error) {
+	p.RLock()
+	defer p.RUnlock()
+
+	cache, ok := p.caches[name]
+	if !ok {
+		return nil, NewCacheNotFoundErr(name)
+	}
+
+	return cache, nil
+}
+
 // GetDatabase returns either the main database or a database branch. This depends on the DatabaseName object.
 func (p *Project) GetDatabase(databaseName *DatabaseName) (*Database, error) {
 	if databaseName.IsMainBranch() {
@@ -2205,7 +2522,7 @@ func createCollection(
 	schemas schema.Versions,
 	idxMeta map[string]*PrimaryIndexMetadata,
 	searchCollectionName string,
-	fieldsInSearch []tsApi.Field,
+	fieldsInSearch []schema.SearchStoreField,
 	secondaryIndexes []*schema.Index,
 	searchState schema.SearchIndexState,
 ) (*schema.DefaultCollection, error) {
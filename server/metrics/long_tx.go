@@ -0,0 +1,57 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/uber-go/tally"
+)
+
+const (
+	LongTxCommitted  = "committed"
+	LongTxConflict   = "conflict"
+	LongTxExpired    = "expired"
+	LongTxRolledBack = "rolled_back"
+	LongTxFailed     = "failed"
+)
+
+var LongTxMetrics tally.Scope
+
+func initializeLongTxScopes() {
+	LongTxMetrics = SessionMetrics.SubScope("long_tx")
+}
+
+// LongTxStarted counts the long-running transactions started.
+func LongTxStarted() {
+	if LongTxMetrics == nil {
+		return
+	}
+	LongTxMetrics.Counter("started").Inc(1)
+}
+
+// LongTxFinished reports the outcome of a long-running transaction along with its lifetime, the size of the buffered
+// writes and the number of reads validated at commit.
+func LongTxFinished(outcome string, duration time.Duration, writeBytes int64, reads int) {
+	if LongTxMetrics == nil {
+		return
+	}
+
+	scope := LongTxMetrics.Tagged(map[string]string{"outcome": outcome})
+	scope.Counter("finished").Inc(1)
+	scope.Timer("duration").Record(duration)
+	scope.Gauge("write_bytes").Update(float64(writeBytes))
+	scope.Gauge("reads").Update(float64(reads))
+}
@@ -40,6 +40,7 @@ var (
 	NetworkMetrics        tally.Scope
 	AuthMetrics           tally.Scope
 	SchemaMetrics         tally.Scope
+	DocumentCacheMetrics  tally.Scope
 	MetronomeMetrics      tally.Scope
 	GlobalSt              *GlobalStatus
 	once                  sync.Once
@@ -94,6 +95,18 @@ func SchemaUpdateRepaired(project string, branch string, collection string) {
 	}
 }
 
+func DocumentCacheHit(project string, branch string, collection string) {
+	if DocumentCacheMetrics != nil {
+		DocumentCacheMetrics.Tagged(GetProjectBranchCollTags(project, branch, collection)).Counter("hit").Inc(1)
+	}
+}
+
+func DocumentCacheMiss(project string, branch string, collection string) {
+	if DocumentCacheMetrics != nil {
+		DocumentCacheMetrics.Tagged(GetProjectBranchCollTags(project, branch, collection)).Counter("miss").Inc(1)
+	}
+}
+
 func InitializeMetrics() func() {
 	var closer io.Closer
 	once.Do(func() {
@@ -128,6 +141,7 @@ func InitializeMetrics() func() {
 				// Session level metrics
 				SessionMetrics = root.SubScope("session")
 				initializeSessionScopes()
+				initializeLongTxScopes()
 			}
 			if cfg.Size.Enabled {
 				// Size metrics
@@ -163,6 +177,7 @@ func InitializeMetrics() func() {
 			initializeQuotaScopes()
 
 			SchemaMetrics = root.SubScope("schema")
+			DocumentCacheMetrics = root.SubScope("document_cache")
 			GlobalSt = NewGlobalStatus()
 		}
 	})
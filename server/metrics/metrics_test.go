@@ -40,6 +40,8 @@ func TestInitializeMetrics(t *testing.T) {
 
 		SchemaReadOutdated("proj1", "branch1", "coll1")
 		SchemaUpdateRepaired("proj1", "branch1", "coll1")
+		DocumentCacheHit("proj1", "branch1", "coll1")
+		DocumentCacheMiss("proj1", "branch1", "coll1")
 	})
 }
 
@@ -56,9 +56,10 @@ type User struct {
 }
 
 type CustomClaim struct {
-	Namespace    Namespace    `json:"https://tigris/n"`
-	User         User         `json:"https://tigris/u"`
-	TigrisClaims TigrisClaims `json:"https://tigris"`
+	Namespace    Namespace      `json:"https://tigris/n"`
+	User         User           `json:"https://tigris/u"`
+	TigrisClaims TigrisClaims   `json:"https://tigris"`
+	Claims       map[string]any `json:"https://tigris/c"`
 }
 
 func (c CustomClaim) Validate(_ context.Context) error {
@@ -277,6 +278,7 @@ func authenticateUsingAuthToken(ctx context.Context, jwtValidators []*validator.
 				Sub:       validatedClaims.RegisteredClaims.Subject,
 				Role:      customClaims.TigrisClaims.Role,
 				Project:   customClaims.TigrisClaims.Project,
+				Claims:    scalarClaims(customClaims.Claims),
 			}
 			reqMetadata.SetAccessToken(token)
 			// update cache
@@ -291,6 +293,23 @@ func authenticateUsingAuthToken(ctx context.Context, jwtValidators []*validator.
 	return ctx, errors.Unauthenticated("You are not authorized to perform this action")
 }
 
+// scalarClaims returns the custom claims having a string, a number or a boolean value as strings.
+func scalarClaims(claims map[string]any) map[string]string {
+	if len(claims) == 0 {
+		return nil
+	}
+
+	scalars := make(map[string]string, len(claims))
+	for k, v := range claims {
+		switch v.(type) {
+		case string, float64, bool:
+			scalars[k] = fmt.Sprint(v)
+		}
+	}
+
+	return scalars
+}
+
 func getCachedToken(ctx context.Context, tkn string, cache gcache.Cache) any {
 	if !BypassAuthCaches(ctx) {
 		validatedToken, err := cache.Get(tkn)
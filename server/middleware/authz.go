@@ -39,11 +39,13 @@ var (
 		// db
 		api.ReadMethodName,
 		api.CountMethodName,
+		api.ExportMethodName,
 		api.ExplainMethodName,
 		api.SearchMethodName,
 		api.ListProjectsMethodName,
 		api.DescribeDatabaseMethodName,
 		api.DescribeCollectionMethodName,
+		api.GetCollectionSearchIndexReportMethodName,
 		api.ListBranchesMethodName,
 
 		// auth
@@ -53,6 +55,13 @@ var (
 		// cache
 		api.ListCachesMethodName,
 		api.GetMethodName,
+		api.TTLMethodName,
+		api.MGetMethodName,
+		api.ExistsMethodName,
+		api.HGetMethodName,
+		api.HGetAllMethodName,
+		api.ZRangeMethodName,
+		api.ZRankMethodName,
 		api.KeysMethodName,
 
 		// health
@@ -72,12 +81,24 @@ var (
 
 		// realtime
 		api.ReadMessagesMethodName,
+		api.ListChannelRulesMethodName,
+		api.ChannelHistoryMethodName,
+		api.GetChannelRetentionMethodName,
+		api.GetPresenceMethodName,
+		api.ListWebhooksMethodName,
 
 		// search
 		api.GetIndexMethodName,
 		api.ListIndexesMethodName,
 		api.SearchGetMethodName,
 		api.SearchSearch,
+		api.MultiSearchMethodName,
+		api.ListSynonymsMethodName,
+		api.ListStopwordsMethodName,
+		api.ListCurationRulesMethodName,
+		api.GetTopQueriesMethodName,
+		api.GetNoResultQueriesMethodName,
+		api.SuggestQueriesMethodName,
 	)
 
 	// editor.
@@ -92,10 +113,12 @@ var (
 		api.UpdateMethodName,
 		api.ReadMethodName,
 		api.CountMethodName,
+		api.ExportMethodName,
 		api.BuildCollectionIndexMethodName,
 		api.ExplainMethodName,
 		api.SearchMethodName,
 		api.ImportMethodName,
+		api.BulkImportMethodName,
 		api.CreateOrUpdateCollectionMethodName,
 		api.CreateOrUpdateCollectionsMethodName,
 		api.DropCollectionMethodName,
@@ -105,6 +128,7 @@ var (
 		api.DeleteProjectMethodName,
 		api.DescribeDatabaseMethodName,
 		api.DescribeCollectionMethodName,
+		api.GetCollectionSearchIndexReportMethodName,
 		api.CreateBranchMethodName,
 		api.DeleteBranchMethodName,
 		api.ListBranchesMethodName,
@@ -115,6 +139,9 @@ var (
 		api.RotateAppKeySecretMethodName,
 		api.IndexCollection,
 		api.SearchIndexCollectionMethodName,
+		api.VerifyCollectionSearchIndexMethodName,
+		api.BackupProjectMethodName,
+		api.RestoreProjectMethodName,
 
 		// auth
 		api.GetAccessTokenMethodName,
@@ -129,7 +156,24 @@ var (
 		api.SetMethodName,
 		api.GetSetMethodName,
 		api.GetMethodName,
+		api.TTLMethodName,
+		api.MGetMethodName,
+		api.ExistsMethodName,
+		api.HGetMethodName,
+		api.HGetAllMethodName,
+		api.ZRangeMethodName,
+		api.ZRankMethodName,
 		api.DelMethodName,
+		api.ExpireMethodName,
+		api.PersistMethodName,
+		api.MSetMethodName,
+		api.IncrMethodName,
+		api.IncrByMethodName,
+		api.DecrMethodName,
+		api.HSetMethodName,
+		api.ZAddMethodName,
+		api.LPushMethodName,
+		api.RPopMethodName,
 		api.KeysMethodName,
 
 		// health
@@ -157,6 +201,11 @@ var (
 		api.ReadMessagesMethodName,
 		api.MessagesMethodName,
 		api.ListSubscriptionsMethodName,
+		api.ListChannelRulesMethodName,
+		api.ChannelHistoryMethodName,
+		api.GetChannelRetentionMethodName,
+		api.GetPresenceMethodName,
+		api.ListWebhooksMethodName,
 
 		// search
 		api.CreateOrUpdateIndexMethodName,
@@ -170,6 +219,19 @@ var (
 		api.SearchUpdate,
 		api.SearchDeleteByQuery,
 		api.SearchSearch,
+		api.MultiSearchMethodName,
+		api.UpsertSynonymMethodName,
+		api.DeleteSynonymMethodName,
+		api.ListSynonymsMethodName,
+		api.UpsertStopwordsMethodName,
+		api.DeleteStopwordsMethodName,
+		api.ListStopwordsMethodName,
+		api.UpsertCurationRuleMethodName,
+		api.DeleteCurationRuleMethodName,
+		api.ListCurationRulesMethodName,
+		api.GetTopQueriesMethodName,
+		api.GetNoResultQueriesMethodName,
+		api.SuggestQueriesMethodName,
 	)
 
 	ownerMethods = container.NewHashSet(
@@ -183,10 +245,12 @@ var (
 		api.UpdateMethodName,
 		api.ReadMethodName,
 		api.CountMethodName,
+		api.ExportMethodName,
 		api.BuildCollectionIndexMethodName,
 		api.ExplainMethodName,
 		api.SearchMethodName,
 		api.ImportMethodName,
+		api.BulkImportMethodName,
 		api.CreateOrUpdateCollectionMethodName,
 		api.CreateOrUpdateCollectionsMethodName,
 
@@ -197,6 +261,7 @@ var (
 		api.DeleteProjectMethodName,
 		api.DescribeDatabaseMethodName,
 		api.DescribeCollectionMethodName,
+		api.GetCollectionSearchIndexReportMethodName,
 		api.CreateBranchMethodName,
 		api.DeleteBranchMethodName,
 		api.ListBranchesMethodName,
@@ -212,6 +277,9 @@ var (
 		api.RotateGlobalAppKeySecretMethodName,
 		api.IndexCollection,
 		api.SearchIndexCollectionMethodName,
+		api.VerifyCollectionSearchIndexMethodName,
+		api.BackupProjectMethodName,
+		api.RestoreProjectMethodName,
 
 		// auth
 		api.GetAccessTokenMethodName,
@@ -231,7 +299,24 @@ var (
 		api.SetMethodName,
 		api.GetSetMethodName,
 		api.GetMethodName,
+		api.TTLMethodName,
+		api.MGetMethodName,
+		api.ExistsMethodName,
+		api.HGetMethodName,
+		api.HGetAllMethodName,
+		api.ZRangeMethodName,
+		api.ZRankMethodName,
 		api.DelMethodName,
+		api.ExpireMethodName,
+		api.PersistMethodName,
+		api.MSetMethodName,
+		api.IncrMethodName,
+		api.IncrByMethodName,
+		api.DecrMethodName,
+		api.HSetMethodName,
+		api.ZAddMethodName,
+		api.LPushMethodName,
+		api.RPopMethodName,
 		api.KeysMethodName,
 
 		// health
@@ -259,6 +344,16 @@ var (
 		api.ReadMessagesMethodName,
 		api.MessagesMethodName,
 		api.ListSubscriptionsMethodName,
+		api.ListChannelRulesMethodName,
+		api.ChannelHistoryMethodName,
+		api.GetChannelRetentionMethodName,
+		api.GetPresenceMethodName,
+		api.ListWebhooksMethodName,
+		api.SetChannelRetentionMethodName,
+		api.SetChannelRuleMethodName,
+		api.DeleteChannelRuleMethodName,
+		api.CreateWebhookMethodName,
+		api.DeleteWebhookMethodName,
 
 		// search
 		api.CreateOrUpdateIndexMethodName,
@@ -272,6 +367,19 @@ var (
 		api.SearchUpdate,
 		api.SearchDeleteByQuery,
 		api.SearchSearch,
+		api.MultiSearchMethodName,
+		api.UpsertSynonymMethodName,
+		api.DeleteSynonymMethodName,
+		api.ListSynonymsMethodName,
+		api.UpsertStopwordsMethodName,
+		api.DeleteStopwordsMethodName,
+		api.ListStopwordsMethodName,
+		api.UpsertCurationRuleMethodName,
+		api.DeleteCurationRuleMethodName,
+		api.ListCurationRulesMethodName,
+		api.GetTopQueriesMethodName,
+		api.GetNoResultQueriesMethodName,
+		api.SuggestQueriesMethodName,
 	)
 	clusterAdminMethods = container.NewHashSet(
 		// db
@@ -284,10 +392,12 @@ var (
 		api.UpdateMethodName,
 		api.ReadMethodName,
 		api.CountMethodName,
+		api.ExportMethodName,
 		api.BuildCollectionIndexMethodName,
 		api.ExplainMethodName,
 		api.SearchMethodName,
 		api.ImportMethodName,
+		api.BulkImportMethodName,
 		api.CreateOrUpdateCollectionMethodName,
 		api.DropCollectionMethodName,
 		api.ListProjectsMethodName,
@@ -296,9 +406,12 @@ var (
 		api.DeleteProjectMethodName,
 		api.DescribeDatabaseMethodName,
 		api.DescribeCollectionMethodName,
+		api.GetCollectionSearchIndexReportMethodName,
 		api.CreateBranchMethodName,
 		api.DeleteBranchMethodName,
 		api.ListBranchesMethodName,
+		api.BackupProjectMethodName,
+		api.RestoreProjectMethodName,
 		api.CreateAppKeyMethodName,
 		api.UpdateAppKeyMethodName,
 		api.DeleteAppKeyMethodName,
@@ -324,7 +437,24 @@ var (
 		api.SetMethodName,
 		api.GetSetMethodName,
 		api.GetMethodName,
+		api.TTLMethodName,
+		api.MGetMethodName,
+		api.ExistsMethodName,
+		api.HGetMethodName,
+		api.HGetAllMethodName,
+		api.ZRangeMethodName,
+		api.ZRankMethodName,
 		api.DelMethodName,
+		api.ExpireMethodName,
+		api.PersistMethodName,
+		api.MSetMethodName,
+		api.IncrMethodName,
+		api.IncrByMethodName,
+		api.DecrMethodName,
+		api.HSetMethodName,
+		api.ZAddMethodName,
+		api.LPushMethodName,
+		api.RPopMethodName,
 		api.KeysMethodName,
 
 		// health
@@ -355,6 +485,16 @@ var (
 		api.ReadMessagesMethodName,
 		api.MessagesMethodName,
 		api.ListSubscriptionsMethodName,
+		api.ListChannelRulesMethodName,
+		api.ChannelHistoryMethodName,
+		api.GetChannelRetentionMethodName,
+		api.GetPresenceMethodName,
+		api.ListWebhooksMethodName,
+		api.SetChannelRetentionMethodName,
+		api.SetChannelRuleMethodName,
+		api.DeleteChannelRuleMethodName,
+		api.CreateWebhookMethodName,
+		api.DeleteWebhookMethodName,
 
 		// search
 		api.CreateOrUpdateIndexMethodName,
@@ -368,6 +508,19 @@ var (
 		api.SearchUpdate,
 		api.SearchDeleteByQuery,
 		api.SearchSearch,
+		api.MultiSearchMethodName,
+		api.UpsertSynonymMethodName,
+		api.DeleteSynonymMethodName,
+		api.ListSynonymsMethodName,
+		api.UpsertStopwordsMethodName,
+		api.DeleteStopwordsMethodName,
+		api.ListStopwordsMethodName,
+		api.UpsertCurationRuleMethodName,
+		api.DeleteCurationRuleMethodName,
+		api.ListCurationRulesMethodName,
+		api.GetTopQueriesMethodName,
+		api.GetNoResultQueriesMethodName,
+		api.SuggestQueriesMethodName,
 	)
 )
 
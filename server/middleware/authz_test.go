@@ -32,10 +32,12 @@ func TestAuthzOwnerRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.UpdateMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.ReadMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.CountMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.ExportMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.BuildCollectionIndexMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.ExplainMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.ImportMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.BulkImportMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.CreateOrUpdateCollectionMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.CreateOrUpdateCollectionsMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.DropCollectionMethodName, auth.OwnerRoleName))
@@ -45,6 +47,7 @@ func TestAuthzOwnerRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.DeleteProjectMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.DescribeDatabaseMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.DescribeCollectionMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.GetCollectionSearchIndexReportMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.CreateBranchMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.DeleteBranchMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.ListBranchesMethodName, auth.OwnerRoleName))
@@ -60,6 +63,9 @@ func TestAuthzOwnerRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.RotateGlobalAppKeySecretMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.IndexCollection, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchIndexCollectionMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.VerifyCollectionSearchIndexMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.BackupProjectMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.RestoreProjectMethodName, auth.OwnerRoleName))
 
 	// auth
 	require.True(t, isAuthorizedOperation(api.GetAccessTokenMethodName, auth.OwnerRoleName))
@@ -80,6 +86,23 @@ func TestAuthzOwnerRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.GetSetMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.GetMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.DelMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.ExpireMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.PersistMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.TTLMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.MGetMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.MSetMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.ExistsMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.IncrMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.IncrByMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.DecrMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.HSetMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.ZAddMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.LPushMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.RPopMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.HGetMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.HGetAllMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.ZRangeMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.ZRankMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.KeysMethodName, auth.OwnerRoleName))
 
 	// health
@@ -107,6 +130,16 @@ func TestAuthzOwnerRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.ReadMessagesMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.MessagesMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.ListSubscriptionsMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.SetChannelRuleMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.DeleteChannelRuleMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.ListChannelRulesMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.ChannelHistoryMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.GetChannelRetentionMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.GetPresenceMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.CreateWebhookMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.DeleteWebhookMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.ListWebhooksMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.SetChannelRetentionMethodName, auth.OwnerRoleName))
 
 	// search
 	require.True(t, isAuthorizedOperation(api.CreateOrUpdateIndexMethodName, auth.OwnerRoleName))
@@ -120,6 +153,19 @@ func TestAuthzOwnerRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.SearchUpdate, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchDeleteByQuery, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchSearch, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.MultiSearchMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.UpsertSynonymMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.DeleteSynonymMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.ListSynonymsMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.UpsertStopwordsMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.DeleteStopwordsMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.ListStopwordsMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.UpsertCurationRuleMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.DeleteCurationRuleMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.ListCurationRulesMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.GetTopQueriesMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.GetNoResultQueriesMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.SuggestQueriesMethodName, auth.OwnerRoleName))
 
 	// negative
 	require.False(t, isAuthorizedOperation(api.VerifyInvitationMethodName, auth.OwnerRoleName))
@@ -139,10 +185,12 @@ func TestAuthzEditorRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.UpdateMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.ReadMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.CountMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.ExportMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.BuildCollectionIndexMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.ExplainMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.ImportMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.BulkImportMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.CreateOrUpdateCollectionMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.CreateOrUpdateCollectionsMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.DropCollectionMethodName, auth.EditorRoleName))
@@ -152,6 +200,7 @@ func TestAuthzEditorRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.DeleteProjectMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.DescribeDatabaseMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.DescribeCollectionMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.GetCollectionSearchIndexReportMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.CreateBranchMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.DeleteBranchMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.ListBranchesMethodName, auth.EditorRoleName))
@@ -162,6 +211,9 @@ func TestAuthzEditorRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.RotateAppKeySecretMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.IndexCollection, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchIndexCollectionMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.VerifyCollectionSearchIndexMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.BackupProjectMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.RestoreProjectMethodName, auth.EditorRoleName))
 
 	// auth
 	require.True(t, isAuthorizedOperation(api.GetAccessTokenMethodName, auth.EditorRoleName))
@@ -177,6 +229,23 @@ func TestAuthzEditorRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.GetSetMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.GetMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.DelMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.ExpireMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.PersistMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.TTLMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.MGetMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.MSetMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.ExistsMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.IncrMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.IncrByMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.DecrMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.HSetMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.ZAddMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.LPushMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.RPopMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.HGetMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.HGetAllMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.ZRangeMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.ZRankMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.KeysMethodName, auth.EditorRoleName))
 
 	// health
@@ -204,6 +273,16 @@ func TestAuthzEditorRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.ReadMessagesMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.MessagesMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.ListSubscriptionsMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.ListChannelRulesMethodName, auth.EditorRoleName))
+	require.False(t, isAuthorizedOperation(api.SetChannelRuleMethodName, auth.EditorRoleName))
+	require.False(t, isAuthorizedOperation(api.DeleteChannelRuleMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.ChannelHistoryMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.GetChannelRetentionMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.GetPresenceMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.ListWebhooksMethodName, auth.EditorRoleName))
+	require.False(t, isAuthorizedOperation(api.CreateWebhookMethodName, auth.EditorRoleName))
+	require.False(t, isAuthorizedOperation(api.DeleteWebhookMethodName, auth.EditorRoleName))
+	require.False(t, isAuthorizedOperation(api.SetChannelRetentionMethodName, auth.EditorRoleName))
 
 	// search
 	require.True(t, isAuthorizedOperation(api.CreateOrUpdateIndexMethodName, auth.EditorRoleName))
@@ -217,6 +296,19 @@ func TestAuthzEditorRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.SearchUpdate, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchDeleteByQuery, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchSearch, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.MultiSearchMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.UpsertSynonymMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.DeleteSynonymMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.ListSynonymsMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.UpsertStopwordsMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.DeleteStopwordsMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.ListStopwordsMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.UpsertCurationRuleMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.DeleteCurationRuleMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.ListCurationRulesMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.GetTopQueriesMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.GetNoResultQueriesMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.SuggestQueriesMethodName, auth.EditorRoleName))
 
 	// negative
 	require.False(t, isAuthorizedOperation(api.ListUsersMethodName, auth.EditorRoleName))
@@ -237,11 +329,13 @@ func TestAuthzReadOnlyRole(t *testing.T) {
 	// db
 	require.True(t, isAuthorizedOperation(api.ReadMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.CountMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.ExportMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.ExplainMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.ListProjectsMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.DescribeDatabaseMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.DescribeCollectionMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.GetCollectionSearchIndexReportMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.ListBranchesMethodName, auth.ReadOnlyRoleName))
 
 	// auth
@@ -251,6 +345,13 @@ func TestAuthzReadOnlyRole(t *testing.T) {
 	// cache
 	require.True(t, isAuthorizedOperation(api.ListCachesMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.GetMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.TTLMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.MGetMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.ExistsMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.HGetMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.HGetAllMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.ZRangeMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.ZRankMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.KeysMethodName, auth.ReadOnlyRoleName))
 
 	// health
@@ -270,12 +371,27 @@ func TestAuthzReadOnlyRole(t *testing.T) {
 
 	// realtime
 	require.True(t, isAuthorizedOperation(api.ReadMessagesMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.ListChannelRulesMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.SetChannelRuleMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.ChannelHistoryMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.GetChannelRetentionMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.GetPresenceMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.ListWebhooksMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.CreateWebhookMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.SetChannelRetentionMethodName, auth.ReadOnlyRoleName))
 
 	// search
 	require.True(t, isAuthorizedOperation(api.GetIndexMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.ListIndexesMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchGetMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchSearch, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.MultiSearchMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.ListSynonymsMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.ListStopwordsMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.ListCurationRulesMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.GetTopQueriesMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.GetNoResultQueriesMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.SuggestQueriesMethodName, auth.ReadOnlyRoleName))
 
 	// negative
 	require.False(t, isAuthorizedOperation(api.BeginTransactionMethodName, auth.ReadOnlyRoleName))
@@ -289,10 +405,23 @@ func TestAuthzReadOnlyRole(t *testing.T) {
 	require.False(t, isAuthorizedOperation(api.CreateOrUpdateCollectionsMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.DeleteProjectMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.DropCollectionMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.UpsertSynonymMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.UpsertStopwordsMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.UpsertCurationRuleMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.CreateCacheMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.SetMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.GetSetMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.DelMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.ExpireMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.PersistMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.MSetMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.IncrMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.IncrByMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.DecrMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.HSetMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.ZAddMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.LPushMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.RPopMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.CreateBranchMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.DeleteBranchMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.CreateAppKeyMethodName, auth.ReadOnlyRoleName))
@@ -303,6 +432,10 @@ func TestAuthzReadOnlyRole(t *testing.T) {
 	require.False(t, isAuthorizedOperation(api.DeleteInvitationsMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.IndexCollection, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.SearchIndexCollectionMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.VerifyCollectionSearchIndexMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.BackupProjectMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.RestoreProjectMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.BulkImportMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.ListUsersMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.VerifyInvitationMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.CreateNamespaceMethodName, auth.ReadOnlyRoleName))
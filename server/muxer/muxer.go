@@ -38,7 +38,8 @@ type Server interface {
 }
 
 type Muxer struct {
-	servers []Server
+	servers  []Server
+	services []v1.Service
 }
 
 func NewMuxer(cfg *config.Config) *Muxer {
@@ -52,6 +53,7 @@ func (m *Muxer) RegisterServices(cfg *config.ServerConfig, kvStore kv.TxStore, s
 	} else {
 		services = v1.GetRegisteredServices(kvStore, searchStore, tenantMgr, txMgr, forSearchTxMgr, biller)
 	}
+	m.services = services
 	for _, r := range services {
 		for _, v := range m.servers {
 			if s, ok := v.(*GRPCServer); ok {
@@ -67,6 +69,15 @@ func (m *Muxer) RegisterServices(cfg *config.ServerConfig, kvStore kv.TxStore, s
 	}
 }
 
+// Stop stops the background work of the registered services.
+func (m *Muxer) Stop() {
+	for _, s := range m.services {
+		if stopper, ok := s.(v1.Stopper); ok {
+			stopper.Stop()
+		}
+	}
+}
+
 func (m *Muxer) serve(l net.Listener) {
 	cm := cmux.New(l)
 
@@ -0,0 +1,208 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outbox
+
+import (
+	"context"
+	"sync"
+
+	"github.com/apple/foundationdb/bindings/go/src/fdb/subspace"
+	"github.com/apple/foundationdb/bindings/go/src/fdb/tuple"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/store/kv"
+)
+
+const outboxValueVersion = 1
+
+// Message is a realtime message enqueued by a transaction.
+type Message struct {
+	TenantId  uint32
+	ProjectId uint32
+	Channel   string
+	Name      string
+	Data      []byte `json:",omitempty"`
+}
+
+// Entry are the messages enqueued by a commit, they are published in order.
+type Entry struct {
+	Messages []*Message
+}
+
+type bufferCtxKey struct{}
+
+type buffer struct {
+	sync.Mutex
+
+	messages []*Message
+}
+
+// Enqueue adds the messages to the outbox of the transaction of the context, they are written to the outbox when the
+// transaction commits. The returned context must be used for the rest of the transaction.
+func Enqueue(ctx context.Context, messages ...*Message) context.Context {
+	b, ok := ctx.Value(bufferCtxKey{}).(*buffer)
+	if !ok {
+		b = &buffer{}
+		ctx = context.WithValue(ctx, bufferCtxKey{}, b)
+	}
+
+	b.Lock()
+	b.messages = append(b.messages, messages...)
+	b.Unlock()
+
+	return ctx
+}
+
+func enqueued(ctx context.Context) []*Message {
+	b, ok := ctx.Value(bufferCtxKey{}).(*buffer)
+	if !ok {
+		return nil
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	return append([]*Message(nil), b.messages...)
+}
+
+// Manager is the transaction listener writing the outbox. The messages enqueued explicitly by the write requests and
+// the messages of the publish triggers of the collections are written in the committing transaction under a
+// versionstamped key of the outbox table of the namespace, so a commit adds exactly one outbox entry and a rolled back
+// transaction adds none.
+type Manager struct {
+	tenantMgr *metadata.TenantManager
+}
+
+func NewManager(tenantMgr *metadata.TenantManager) *Manager {
+	return &Manager{
+		tenantMgr: tenantMgr,
+	}
+}
+
+func (m *Manager) OnPreCommit(ctx context.Context, tenant *metadata.Tenant, tx transaction.Tx, listener kv.EventListener) error {
+	messages := enqueued(ctx)
+
+	triggered, err := m.triggered(tenant, listener.GetEvents())
+	if err != nil {
+		return err
+	}
+	messages = append(messages, triggered...)
+	if len(messages) == 0 {
+		return nil
+	}
+	if tenant == nil {
+		return errors.Internal("realtime messages enqueued outside of a namespace")
+	}
+
+	json, err := jsoniter.Marshal(&Entry{
+		Messages: messages,
+	})
+	if err != nil {
+		return err
+	}
+
+	key, err := nextKey(m.tenantMgr.GetEncoder().EncodeOutboxTableName(tenant.GetNamespace()))
+	if err != nil {
+		return err
+	}
+
+	enc, err := internal.Encode(internal.NewTableDataWithVersion(json, outboxValueVersion))
+	if err != nil {
+		return err
+	}
+
+	return tx.SetVersionstampedKey(ctx, key, enc)
+}
+
+func (*Manager) OnPostCommit(context.Context, *metadata.Tenant, kv.EventListener) error {
+	return nil
+}
+
+func (*Manager) OnRollback(context.Context, *metadata.Tenant, kv.EventListener) {}
+
+// triggered returns the messages of the publish triggers of the collections changed by the events.
+func (m *Manager) triggered(tenant *metadata.Tenant, events []*kv.Event) ([]*Message, error) {
+	if tenant == nil {
+		return nil, nil
+	}
+
+	var messages []*Message
+	for _, event := range events {
+		if event.Key == nil {
+			// event.Key == nil if event comes from drop table
+			continue
+		}
+
+		db, collName, ok := m.tenantMgr.DecodeTableName(event.Table)
+		if !ok {
+			continue
+		}
+
+		coll := db.GetCollection(collName)
+		if coll == nil || !coll.Publish.Triggers(event.Op) {
+			continue
+		}
+
+		project, err := tenant.GetProject(db.DbName())
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := changeData(collName, event)
+		if err != nil {
+			return nil, err
+		}
+
+		name := coll.Publish.Name
+		if len(name) == 0 {
+			name = event.Op
+		}
+
+		messages = append(messages, &Message{
+			TenantId:  tenant.GetNamespace().Id(),
+			ProjectId: project.Id(),
+			Channel:   coll.Publish.Channel,
+			Name:      name,
+			Data:      data,
+		})
+	}
+
+	return messages, nil
+}
+
+// changeData is the payload of a triggered message, the document for an insert, replace or update and the primary key
+// for a delete.
+func changeData(collName string, event *kv.Event) ([]byte, error) {
+	change := map[string]any{
+		"op":         event.Op,
+		"collection": collName,
+	}
+
+	if event.Op == kv.DeleteEvent || event.Data == nil {
+		// the zeroth element is the index name i.e. pkey
+		change["key"] = event.Key[1:]
+	} else {
+		change["document"] = jsoniter.RawMessage(event.Data.RawData)
+	}
+
+	return jsoniter.Marshal(change)
+}
+
+func nextKey(table []byte) ([]byte, error) {
+	return subspace.FromBytes(table).PackWithVersionstamp(tuple.Tuple{tuple.IncompleteVersionstamp(0)})
+}
@@ -0,0 +1,166 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/server/config"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/store/cache"
+	"github.com/tigrisdata/tigris/store/kv"
+)
+
+type testPublisher struct {
+	cache  cache.Cache
+	calls  int
+	failAt int
+}
+
+func (p *testPublisher) PublishSequenced(ctx context.Context, _ uint32, _ uint32, channel string, name string, _ []byte,
+	guardTable string, guardKey string, seq int64,
+) (bool, error) {
+	p.calls++
+	if p.calls == p.failAt {
+		return false, fmt.Errorf("publish failed")
+	}
+
+	stream, err := p.cache.CreateOrGetStream(ctx, channel)
+	if err != nil {
+		return false, err
+	}
+
+	_, added, err := stream.AddSequenced(ctx, internal.NewStreamData(internal.JsonEncoding, nil, []byte(name)), guardTable,
+		guardKey, seq)
+	return added, err
+}
+
+func (p *testPublisher) published(t *testing.T, channel string) []string {
+	stream, err := p.cache.GetStream(context.Background(), channel)
+	require.NoError(t, err)
+	messages, err := stream.Range(context.Background(), "-", "+", 0, false)
+	require.NoError(t, err)
+
+	var names []string
+	for _, m := range messages.Messages {
+		data, err := messages.Decode(m)
+		require.NoError(t, err)
+		names = append(names, string(data.RawData))
+	}
+	return names
+}
+
+func TestEnqueue(t *testing.T) {
+	ctx := context.Background()
+	require.Empty(t, enqueued(ctx))
+
+	ctx = Enqueue(ctx, &Message{Channel: "c1", Name: "a"})
+	// the buffer is shared, later enqueues are seen through the first context
+	ctx1 := Enqueue(ctx, &Message{Channel: "c1", Name: "b"})
+	require.Equal(t, ctx, ctx1)
+
+	messages := enqueued(ctx)
+	require.Len(t, messages, 2)
+	require.Equal(t, "a", messages[0].Name)
+	require.Equal(t, "b", messages[1].Name)
+}
+
+func TestChangeData(t *testing.T) {
+	data, err := changeData("users", &kv.Event{
+		Op:   kv.InsertEvent,
+		Key:  kv.BuildKey("pkey", int64(1)),
+		Data: internal.NewTableData([]byte(`{"id":1}`)),
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"op":"insert","collection":"users","document":{"id":1}}`, string(data))
+
+	data, err = changeData("users", &kv.Event{
+		Op:  kv.DeleteEvent,
+		Key: kv.BuildKey("pkey", int64(1)),
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"op":"delete","collection":"users","key":[1]}`, string(data))
+}
+
+func TestRelayDeliver(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewCache(&config.CacheConfig{Backend: config.CacheBackendMemory})
+	p := &testPublisher{cache: c, failAt: 2}
+	r := &Relay{cache: c, encoder: metadata.NewCacheEncoder(), publisher: p, owner: "relay1"}
+
+	entry := &pending{
+		nsId: 1,
+		id:   "0001",
+		entry: Entry{
+			Messages: []*Message{
+				{Channel: "c1", Name: "m1"},
+				{Channel: "c1", Name: "m2"},
+				{Channel: "c2", Name: "m3"},
+			},
+		},
+	}
+
+	require.Error(t, r.deliver(ctx, entry))
+	require.Equal(t, []string{"m1"}, p.published(t, "c1"))
+
+	// the retry publishes only the messages not published yet
+	require.NoError(t, r.deliver(ctx, entry))
+	require.Equal(t, []string{"m1", "m2"}, p.published(t, "c1"))
+	require.Equal(t, []string{"m3"}, p.published(t, "c2"))
+
+	// so does a relay taking over the entry before it was deleted
+	require.NoError(t, r.deliver(ctx, entry))
+	require.Equal(t, []string{"m1", "m2"}, p.published(t, "c1"))
+	require.Equal(t, []string{"m3"}, p.published(t, "c2"))
+
+	t.Run("lease", func(t *testing.T) {
+		lease, err := r.acquire(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, lease)
+
+		other, err := r.acquire(ctx)
+		require.NoError(t, err)
+		require.Nil(t, other)
+
+		r.release(ctx, lease)
+		other, err = r.acquire(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, other)
+
+		// a round which outlasted its lease doesn't release the lease acquired since
+		r.release(ctx, lease)
+		lease, err = r.acquire(ctx)
+		require.NoError(t, err)
+		require.Nil(t, lease)
+
+		r.release(ctx, other)
+	})
+}
+
+func TestRelayStop(t *testing.T) {
+	c := cache.NewCache(&config.CacheConfig{Backend: config.CacheBackendMemory})
+	r := NewRelay(nil, nil, c, &testPublisher{cache: c})
+	r.interval = time.Hour
+
+	r.Start()
+	// returns once the relay exited
+	r.Stop()
+	require.Error(t, r.ctx.Err())
+}
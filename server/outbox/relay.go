@@ -0,0 +1,343 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outbox
+
+import (
+	"context"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/apple/foundationdb/bindings/go/src/fdb/tuple"
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog/log"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/keys"
+	"github.com/tigrisdata/tigris/server/config"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/store/cache"
+	"github.com/tigrisdata/tigris/store/kv"
+)
+
+const (
+	leaseKey = "lease"
+	// leaseTTL bounds for how long a relay that died in the middle of a round keeps the other relays waiting.
+	leaseTTL = 60
+)
+
+// Publisher publishes the messages to the realtime channels.
+type Publisher interface {
+	// PublishSequenced publishes the message only if seq is the number of messages published so far under the guard
+	// key of the guard table, see cache.Stream.AddSequenced. It returns false if the message was published before.
+	PublishSequenced(ctx context.Context, tenantId uint32, projId uint32, channel string, name string, data []byte,
+		guardTable string, guardKey string, seq int64) (bool, error)
+}
+
+// Relay moves the committed outbox entries to the realtime channels. A single relay of the cluster runs a round at a
+// time, it reads a batch of entries of every namespace, publishes their messages in the commit order and then deletes
+// the published entries in a new transaction. Every message is published atomically with the number of messages of
+// its entry published so far, so a relay retrying an entry, or taking it over after the lease of another relay
+// expired, skips the messages already published and every message is published exactly once.
+type Relay struct {
+	tenantMgr *metadata.TenantManager
+	txMgr     *transaction.Manager
+	cache     cache.Cache
+	encoder   metadata.CacheEncoder
+	publisher Publisher
+	owner     string
+	interval  time.Duration
+	batch     int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// pending is an outbox entry read by a round of the relay.
+type pending struct {
+	table []byte
+	key   kv.KeyPart
+	nsId  uint32
+	id    string
+	entry Entry
+}
+
+func NewRelay(tenantMgr *metadata.TenantManager, txMgr *transaction.Manager, cache cache.Cache, publisher Publisher) *Relay {
+	cfg := config.DefaultConfig.Outbox
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Relay{
+		tenantMgr: tenantMgr,
+		txMgr:     txMgr,
+		cache:     cache,
+		encoder:   metadata.NewCacheEncoder(),
+		publisher: publisher,
+		owner:     uuid.New().String(),
+		interval:  cfg.RelayInterval,
+		batch:     cfg.RelayBatch,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+func (r *Relay) Start() {
+	r.wg.Add(1)
+	go r.run()
+}
+
+// Stop stops the relay and waits for the round in progress to exit. The entries of an interrupted round stay in the
+// outbox and are published by the next round of any relay, the messages already published are skipped.
+func (r *Relay) Stop() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+func (r *Relay) run() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-r.ctx.Done():
+			return
+		}
+
+		for r.ctx.Err() == nil {
+			more, err := r.relay(r.ctx)
+			if err != nil {
+				if r.ctx.Err() == nil {
+					log.Err(err).Msg("relaying outbox failed")
+				}
+				break
+			}
+			if !more {
+				break
+			}
+		}
+	}
+}
+
+// relay runs a round publishing up to batch outbox entries of every namespace, it returns true if a namespace may have
+// more entries.
+func (r *Relay) relay(ctx context.Context) (bool, error) {
+	lease, err := r.acquire(ctx)
+	if err != nil || lease == nil {
+		return false, err
+	}
+	// the lease is released even if the relay is stopped in the middle of the round
+	defer r.release(context.Background(), lease)
+
+	batch, more, err := r.read(ctx)
+	if err != nil || len(batch) == 0 {
+		return false, err
+	}
+
+	var (
+		published []*pending
+		firstErr  error
+	)
+	// an entry failing to publish holds back the following entries of its namespace only
+	failed := make(map[uint32]struct{})
+	for _, p := range batch {
+		if _, ok := failed[p.nsId]; ok {
+			continue
+		}
+
+		if err = r.deliver(ctx, p); err != nil {
+			failed[p.nsId] = struct{}{}
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		published = append(published, p)
+	}
+
+	if err = r.remove(ctx, published); err != nil {
+		return false, err
+	}
+
+	return more && firstErr == nil, firstErr
+}
+
+// read returns up to batch entries of every namespace in the commit order, the transaction is closed before the
+// messages are published. It returns true if a namespace may have more entries.
+func (r *Relay) read(ctx context.Context) ([]*pending, bool, error) {
+	tx, err := r.txMgr.StartTx(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	namespaces, err := r.tenantMgr.ListNamespaces(ctx, tx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var (
+		batch []*pending
+		more  bool
+	)
+	for _, ns := range namespaces {
+		table := r.tenantMgr.GetEncoder().EncodeOutboxTableName(ns)
+
+		it, err := tx.ReadRange(ctx, keys.NewKey(table), nil, false, false)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var (
+			row kv.KeyValue
+			n   int
+		)
+		for n < r.batch && it.Next(&row) {
+			id, err := entryId(row.Key)
+			if err != nil {
+				return nil, false, err
+			}
+
+			p := &pending{
+				table: table,
+				key:   row.Key[0],
+				nsId:  ns.Id(),
+				id:    id,
+			}
+			if err = jsoniter.Unmarshal(row.Data.RawData, &p.entry); err != nil {
+				return nil, false, err
+			}
+
+			batch = append(batch, p)
+			n++
+		}
+		if err = it.Err(); err != nil {
+			return nil, false, err
+		}
+
+		more = more || n == r.batch
+	}
+
+	return batch, more, nil
+}
+
+// deliver publishes the messages of the entry which are not published yet.
+func (r *Relay) deliver(ctx context.Context, p *pending) error {
+	guardTable, err := r.encoder.EncodeInternalCacheTableName(p.nsId, 0, metadata.OutboxCacheTable)
+	if err != nil {
+		return err
+	}
+
+	for i, m := range p.entry.Messages {
+		if _, err = r.publisher.PublishSequenced(ctx, m.TenantId, m.ProjectId, m.Channel, m.Name, m.Data, guardTable, p.id,
+			int64(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// remove deletes the published entries and then the number of their messages published. A relay failing in between
+// leaves these counters behind, the versionstamps are never reused, so they are only wasted space.
+func (r *Relay) remove(ctx context.Context, published []*pending) error {
+	if len(published) == 0 {
+		return nil
+	}
+
+	tx, err := r.txMgr.StartTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range published {
+		if err = tx.Delete(ctx, keys.NewKey(p.table, p.key)); err != nil {
+			_ = tx.Rollback(ctx)
+			return err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	guards := make(map[uint32][]string)
+	for _, p := range published {
+		guards[p.nsId] = append(guards[p.nsId], p.id)
+	}
+	for nsId, ids := range guards {
+		guardTable, err := r.encoder.EncodeInternalCacheTableName(nsId, 0, metadata.OutboxCacheTable)
+		if err == nil {
+			_, err = r.cache.Delete(ctx, guardTable, ids...)
+		}
+		if err != nil {
+			log.Err(err).Uint32("ns", nsId).Msg("deleting outbox progress failed")
+		}
+	}
+
+	return nil
+}
+
+// acquire returns the lease of this relay, nil if another relay holds the lease.
+func (r *Relay) acquire(ctx context.Context) (*internal.CacheData, error) {
+	table, err := r.encoder.EncodeInternalCacheTableName(0, 0, metadata.OutboxCacheTable)
+	if err != nil {
+		return nil, err
+	}
+
+	lease := internal.NewCacheData([]byte(r.owner))
+	err = r.cache.Set(ctx, table, leaseKey, lease, &cache.SetOptions{
+		NX: true,
+		EX: leaseTTL,
+	})
+	if err == cache.ErrKeyAlreadyExists {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return lease, nil
+}
+
+// release deletes the lease only if it is still the one acquired, a round outlasting the lease must not release the
+// lease another relay acquired since.
+func (r *Relay) release(ctx context.Context, lease *internal.CacheData) {
+	table, err := r.encoder.EncodeInternalCacheTableName(0, 0, metadata.OutboxCacheTable)
+	if err == nil {
+		_, err = r.cache.CompareAndDelete(ctx, table, leaseKey, lease)
+	}
+	if err != nil {
+		log.Err(err).Msg("releasing outbox relay lease failed")
+	}
+}
+
+// entryId is the hex encoded versionstamp of the commit which wrote the entry.
+func entryId(key kv.Key) (string, error) {
+	if len(key) != 1 {
+		return "", errors.Internal("unexpected outbox key %v", key)
+	}
+
+	vs, ok := key[0].(tuple.Versionstamp)
+	if !ok {
+		return "", errors.Internal("unexpected outbox key %v", key)
+	}
+
+	return hex.EncodeToString(vs.Bytes()), nil
+}
@@ -424,7 +424,7 @@ func isRead(name string) bool {
 	}
 
 	switch name {
-	case api.ReadMethodName, api.SearchMethodName:
+	case api.ReadMethodName, api.SearchMethodName, api.ExportMethodName:
 		return true
 	case api.ListCollectionsMethodName, api.ListProjectsMethodName:
 		return true
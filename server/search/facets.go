@@ -17,7 +17,7 @@ package search
 import (
 	api "github.com/tigrisdata/tigris/api/server/v1"
 	"github.com/tigrisdata/tigris/query/search"
-	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
+	searchStore "github.com/tigrisdata/tigris/store/search"
 )
 
 // FacetResponse is a builder utility to convert facets from search backend to tigris response.
@@ -35,42 +35,22 @@ func NewFacetResponse(query search.Facets) *FacetResponse {
 }
 
 // Build converts search backend response to api.SearchFacet.
-func (fb *FacetResponse) Build(r *[]tsApi.FacetCounts) map[string]*api.SearchFacet {
+func (fb *FacetResponse) Build(facets []searchStore.FacetCounts) map[string]*api.SearchFacet {
 	result := map[string]*api.SearchFacet{}
 
-	// return empty map
-	if r == nil {
-		return result
-	}
-
-	for _, fc := range *r {
-		// skip if no field name
-		if fc.FieldName == nil {
-			continue
-		}
-		fieldName := *fc.FieldName
+	for _, fc := range facets {
 		// skip if this facets for this field name were not requested, likely not to happen
-		if _, ok := fb.facetSizes[fieldName]; !ok {
+		if _, ok := fb.facetSizes[fc.FieldName]; !ok {
 			continue
 		}
 
 		stats := &api.FacetStats{}
 		if fc.Stats != nil {
-			if fc.Stats.Avg != nil {
-				stats.Avg = fc.Stats.Avg
-			}
-			if fc.Stats.Max != nil {
-				stats.Max = fc.Stats.Max
-			}
-			if fc.Stats.Min != nil {
-				stats.Min = fc.Stats.Min
-			}
-			if fc.Stats.Sum != nil {
-				stats.Sum = fc.Stats.Sum
-			}
-			if fc.Stats.TotalValues != nil {
-				stats.Count = int64(*fc.Stats.TotalValues)
-			}
+			stats.Avg = fc.Stats.Avg
+			stats.Max = fc.Stats.Max
+			stats.Min = fc.Stats.Min
+			stats.Sum = fc.Stats.Sum
+			stats.Count = int64(fc.Stats.TotalValues)
 		}
 
 		facet := &api.SearchFacet{
@@ -78,20 +58,17 @@ func (fb *FacetResponse) Build(r *[]tsApi.FacetCounts) map[string]*api.SearchFac
 			Stats:  stats,
 		}
 
-		if fc.Counts != nil {
-			for _, tsCount := range *fc.Counts {
-				// skip null 'value' for a faceted field
-				// skip if the user requested size for a facet field has been met
-				if tsCount.Value == nil || len(facet.Counts) >= fb.facetSizes[fieldName] {
-					continue
-				}
-				facet.Counts = append(facet.Counts, &api.FacetCount{
-					Count: int64(*tsCount.Count),
-					Value: *tsCount.Value,
-				})
+		for _, count := range fc.Counts {
+			// skip if the user requested size for a facet field has been met
+			if len(facet.Counts) >= fb.facetSizes[fc.FieldName] {
+				break
 			}
+			facet.Counts = append(facet.Counts, &api.FacetCount{
+				Count: int64(count.Count),
+				Value: count.Value,
+			})
 		}
-		result[fieldName] = facet
+		result[fc.FieldName] = facet
 	}
 
 	return result
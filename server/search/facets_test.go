@@ -17,12 +17,10 @@ package search
 import (
 	"testing"
 
-	jsoniter "github.com/json-iterator/go"
-	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	api "github.com/tigrisdata/tigris/api/server/v1"
 	"github.com/tigrisdata/tigris/query/search"
-	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
+	searchStore "github.com/tigrisdata/tigris/store/search"
 )
 
 func TestNewFacetResponse(t *testing.T) {
@@ -50,55 +48,30 @@ func TestNewFacetResponse(t *testing.T) {
 }
 
 func TestFacetResponse_Build(t *testing.T) {
-	inputData := [][]byte{
-		[]byte(`{"field_name":"a",
-				"counts":[
-      				{"count":20,"value":"value_1"},
-      				{"count":30,"value":"value_2"},
-      				{"count":10,"value":null}
-				]}`),
-		[]byte(`{"field_name":"b","counts":[
-					{"count":14,"value":"value_1"},
-					{"count":12,"value":"value_2"}
-				]}`),
-		[]byte(`{"field_name":null,
-				"counts":[
-					{"count":20,"value":"value_1"},
-					{"count":30,"value":"value_2"},
-					{"count":10,"value":null}
-				]}`),
-		[]byte(`{"field_name":"c",
-				"counts":[
-					{"count":20,"value":"value_1"},
-					{"count":30,"value":"value_2"},
-					{"count":10,"value":null},
-					{"count":10,"value":"value_3"}
-				],
-				"stats":{
-					"total_values":3,
-					"min":0,
-					"max":453.12,
-					"avg":23.254,
-					"sum":12345
-				}}`),
-		[]byte(`{"field_name":"d",
-				"counts":[
-					{"count":12,"value":"value_1"},
-					{"count":16,"value":"value_2"},
-					{"count":0,"value":"value_3"}
-				],
-				"stats":{
-					"total_values":3,
-					"sum": 0
-				}}`),
-	}
-
-	tsCounts := make([]tsApi.FacetCounts, len(inputData))
-	for i, data := range inputData {
-		var tsCount tsApi.FacetCounts
-		err := jsoniter.Unmarshal(data, &tsCount)
-		assert.NoError(t, err)
-		tsCounts[i] = tsCount
+	minC, maxC, avgC, sumC, sumD := float64(0), 453.12, 23.254, float64(12345), float64(0)
+	storeFacets := []searchStore.FacetCounts{
+		{
+			FieldName: "a",
+			Counts:    []searchStore.FacetCount{{Value: "value_1", Count: 20}, {Value: "value_2", Count: 30}},
+		},
+		{
+			FieldName: "b",
+			Counts:    []searchStore.FacetCount{{Value: "value_1", Count: 14}, {Value: "value_2", Count: 12}},
+		},
+		{
+			FieldName: "c",
+			Counts: []searchStore.FacetCount{
+				{Value: "value_1", Count: 20}, {Value: "value_2", Count: 30}, {Value: "value_3", Count: 10},
+			},
+			Stats: &searchStore.FacetStats{Min: &minC, Max: &maxC, Avg: &avgC, Sum: &sumC, TotalValues: 3},
+		},
+		{
+			FieldName: "d",
+			Counts: []searchStore.FacetCount{
+				{Value: "value_1", Count: 12}, {Value: "value_2", Count: 16}, {Value: "value_3", Count: 0},
+			},
+			Stats: &searchStore.FacetStats{Sum: &sumD, TotalValues: 3},
+		},
 	}
 
 	t.Run("build with nil input", func(t *testing.T) {
@@ -112,7 +85,7 @@ func TestFacetResponse_Build(t *testing.T) {
 
 	t.Run("build with empty facetSizes", func(t *testing.T) {
 		fr := FacetResponse{}
-		result := fr.Build(&tsCounts)
+		result := fr.Build(storeFacets)
 		require.NotNil(t, result)
 		require.Empty(t, result)
 	})
@@ -121,7 +94,7 @@ func TestFacetResponse_Build(t *testing.T) {
 		fr := FacetResponse{facetSizes: map[string]int{
 			"f": 10,
 		}}
-		result := fr.Build(&tsCounts)
+		result := fr.Build(storeFacets)
 		require.NotNil(t, result)
 		require.Empty(t, result)
 	})
@@ -132,7 +105,7 @@ func TestFacetResponse_Build(t *testing.T) {
 			"b": 1,
 			"c": 1,
 		}}
-		result := fr.Build(&tsCounts)
+		result := fr.Build(storeFacets)
 		require.NotNil(t, result)
 		require.Len(t, result, len(fr.facetSizes))
 
@@ -149,7 +122,7 @@ func TestFacetResponse_Build(t *testing.T) {
 			"c": 10,
 			"d": 10,
 		}}
-		result := fr.Build(&tsCounts)
+		result := fr.Build(storeFacets)
 		require.NotNil(t, result)
 		require.Len(t, result, len(fr.facetSizes))
 
@@ -19,11 +19,7 @@ import (
 
 	api "github.com/tigrisdata/tigris/api/server/v1"
 	"github.com/tigrisdata/tigris/errors"
-	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
-)
-
-const (
-	matchedTokensKey = "matched_tokens"
+	searchStore "github.com/tigrisdata/tigris/store/search"
 )
 
 type Hits struct {
@@ -77,111 +73,29 @@ func (sh *Hit) isFieldMissingOrNil(f string) bool {
 	return true
 }
 
-func NewSearchHit(tsHit *tsApi.SearchResultHit) *Hit {
-	if tsHit == nil || tsHit.Document == nil {
+func NewSearchHit(storeHit *searchStore.Hit) *Hit {
+	if storeHit == nil || storeHit.Document == nil {
 		return nil
 	}
 
 	var score string
-	if tsHit.TextMatch != nil {
-		score = fmt.Sprintf("%d", *tsHit.TextMatch)
+	if storeHit.TextMatch != nil {
+		score = fmt.Sprintf("%d", *storeHit.TextMatch)
 	}
 
-	var fields []*api.MatchField
-	if tsHit.Highlight != nil {
-		// check first in highlight
-		fromHighlight(*tsHit.Highlight, &fields)
-	} else if tsHit.Highlights != nil {
-		fromHighlights(*tsHit.Highlights, &fields)
+	fields := make([]*api.MatchField, 0, len(storeHit.MatchedFields))
+	for _, name := range storeHit.MatchedFields {
+		fields = append(fields, &api.MatchField{
+			Name: name,
+		})
 	}
 
 	return &Hit{
-		Document: *tsHit.Document,
+		Document: storeHit.Document,
 		Match: &api.Match{
 			Fields:         fields,
 			Score:          score,
-			VectorDistance: tsHit.VectorDistance,
+			VectorDistance: storeHit.VectorDistance,
 		},
 	}
 }
-
-func fromHighlights(highlights []tsApi.SearchHighlight, fields *[]*api.MatchField) {
-	for _, f := range highlights {
-		name := ""
-		if f.Field != nil {
-			name = *f.Field
-		}
-		*fields = append(*fields, &api.MatchField{
-			Name: name,
-		})
-	}
-}
-
-func fromHighlight(highlight map[string]any, fields *[]*api.MatchField) {
-	// check first in highlight
-	for name, value := range highlight {
-		if mp, ok := value.(map[string]any); ok {
-			// any non array match should just fall in this "if"
-			if isMatchedToken(mp) {
-				*fields = append(*fields, &api.MatchField{
-					Name: name,
-				})
-			}
-			continue
-		}
-
-		if mArr, ok := value.([]any); ok {
-			for _, each := range mArr {
-				if mp, ok := each.(map[string]any); ok {
-					matchedToken := isMatchedToken(mp)
-					if matchedToken {
-						// simple array
-						*fields = append(*fields, &api.MatchField{
-							Name: name,
-						})
-						break
-					}
-
-					// now this means we can have an array of objects, we iterate one level
-					// to build matched fields
-					buildMatchedForArrObjects(name, mp, fields)
-				}
-			}
-		}
-	}
-}
-
-func buildMatchedForArrObjects(parent string, obj map[string]any, fields *[]*api.MatchField) {
-	for k, v := range obj {
-		if mpNested, ok := v.(map[string]any); ok {
-			// nested element in an array of object is not an array
-			if isMatchedToken(mpNested) {
-				*fields = append(*fields, &api.MatchField{
-					Name: parent + "." + k,
-				})
-			}
-		} else if mpNestedArr, ok := v.([]any); ok {
-			// nested element in an array of object is an array
-			for _, eachNestedArr := range mpNestedArr {
-				if eachNested, ok := eachNestedArr.(map[string]any); ok {
-					if isMatchedToken(eachNested) {
-						*fields = append(*fields, &api.MatchField{
-							Name: parent + "." + k,
-						})
-						break
-					}
-				}
-			}
-		}
-	}
-}
-
-func isMatchedToken(mp map[string]any) bool {
-	if matched, found := mp[matchedTokensKey]; found {
-		if matchedSlice, ok := matched.([]any); ok {
-			return len(matchedSlice) > 0
-		}
-	}
-
-	return false
-}
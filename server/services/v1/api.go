@@ -17,6 +17,7 @@ package v1
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
@@ -32,10 +33,12 @@ import (
 	"github.com/tigrisdata/tigris/server/config"
 	"github.com/tigrisdata/tigris/server/metadata"
 	"github.com/tigrisdata/tigris/server/metrics"
+	"github.com/tigrisdata/tigris/server/outbox"
 	"github.com/tigrisdata/tigris/server/request"
 	"github.com/tigrisdata/tigris/server/services/v1/auth"
 	"github.com/tigrisdata/tigris/server/services/v1/database"
 	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/store/cache"
 	"github.com/tigrisdata/tigris/store/kv"
 	"github.com/tigrisdata/tigris/store/search"
 	ulog "github.com/tigrisdata/tigris/util/log"
@@ -65,6 +68,7 @@ type apiService struct {
 	versionH      *metadata.VersionHandler
 	searchStore   search.Store
 	authProvider  auth.Provider
+	streams       cache.Cache
 }
 
 func newApiService(kv kv.TxStore, searchStore search.Store, tenantMgr *metadata.TenantManager, txMgr *transaction.Manager, authProvider auth.Provider) *apiService {
@@ -76,6 +80,7 @@ func newApiService(kv kv.TxStore, searchStore search.Store, tenantMgr *metadata.
 		cdcMgr:       cdc.NewManager(),
 		tenantMgr:    tenantMgr,
 		authProvider: authProvider,
+		streams:      cache.NewCache(&config.DefaultConfig.Cache),
 	}
 
 	var err error
@@ -99,13 +104,21 @@ func newApiService(kv kv.TxStore, searchStore search.Store, tenantMgr *metadata.
 		// just for testing so that we can disable it if needed
 		txListeners = append(txListeners, database.NewSearchIndexer(searchStore, tenantMgr))
 	}
+	var docCache *database.DocumentCache
+	if config.DefaultConfig.DocumentCache.Enabled {
+		docCache = database.NewDocumentCache(u.streams, tenantMgr)
+		txListeners = append(txListeners, docCache)
+	}
+	if config.DefaultConfig.Outbox.Enabled {
+		txListeners = append(txListeners, outbox.NewManager(tenantMgr))
+	}
 
 	if config.DefaultConfig.Tracing.Enabled {
 		u.sessions = database.NewSessionManagerWithMetrics(u.txMgr, u.tenantMgr, txListeners, metadata.NewCacheTracker(tenantMgr, txMgr))
 	} else {
 		u.sessions = database.NewSessionManager(u.txMgr, u.tenantMgr, txListeners, metadata.NewCacheTracker(tenantMgr, txMgr))
 	}
-	u.runnerFactory = database.NewQueryRunnerFactory(u.txMgr, u.cdcMgr, u.searchStore)
+	u.runnerFactory = database.NewQueryRunnerFactory(u.txMgr, u.cdcMgr, u.searchStore, docCache)
 
 	return u
 }
@@ -159,9 +172,18 @@ func (s *apiService) RegisterGRPC(grpc *grpc.Server) error {
 	return nil
 }
 
-func (s *apiService) BeginTransaction(ctx context.Context, _ *api.BeginTransactionRequest) (*api.BeginTransactionResponse, error) {
+func (s *apiService) BeginTransaction(ctx context.Context, r *api.BeginTransactionRequest) (*api.BeginTransactionResponse, error) {
+	var (
+		err     error
+		session *database.QuerySession
+	)
+
 	// explicit transactions needed to be tracked
-	session, err := s.sessions.Create(ctx, true, true, true)
+	if r.GetOptions().GetLongRunning() {
+		session, err = s.sessions.CreateLongRunning(ctx)
+	} else {
+		session, err = s.sessions.Create(ctx, true, true, true)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -186,7 +208,11 @@ func (s *apiService) CommitTransaction(ctx context.Context, _ *api.CommitTransac
 		return nil, database.CreateApiError(err)
 	}
 
-	return &api.CommitTransactionResponse{}, nil
+	return &api.CommitTransactionResponse{
+		Metadata: &api.ResponseMetadata{
+			CommitVersion: session.GetTx().CommittedVersion(),
+		},
+	}, nil
 }
 
 func (s *apiService) RollbackTransaction(ctx context.Context, _ *api.RollbackTransactionRequest) (*api.RollbackTransactionResponse, error) {
@@ -220,7 +246,8 @@ func (s *apiService) Insert(ctx context.Context, r *api.InsertRequest) (*api.Ins
 	return &api.InsertResponse{
 		Status: resp.Status,
 		Metadata: &api.ResponseMetadata{
-			CreatedAt: resp.CreatedAt.GetProtoTS(),
+			CreatedAt:     resp.CreatedAt.GetProtoTS(),
+			CommitVersion: resp.CommitVersion,
 		},
 		Keys: resp.AllKeys,
 	}, nil
@@ -240,7 +267,8 @@ func (s *apiService) Import(ctx context.Context, r *api.ImportRequest) (*api.Imp
 	return &api.ImportResponse{
 		Status: resp.Status,
 		Metadata: &api.ResponseMetadata{
-			CreatedAt: resp.CreatedAt.GetProtoTS(),
+			CreatedAt:     resp.CreatedAt.GetProtoTS(),
+			CommitVersion: resp.CommitVersion,
 		},
 		Keys: resp.AllKeys,
 	}, nil
@@ -276,6 +304,32 @@ func (s *apiService) BuildSearchIndex(ctx context.Context, r *api.BuildCollectio
 	return resp.Response.(*api.BuildCollectionSearchIndexResponse), nil
 }
 
+func (s *apiService) VerifyCollectionSearchIndex(ctx context.Context, r *api.VerifyCollectionSearchIndexRequest) (*api.VerifyCollectionSearchIndexResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	runner := s.runnerFactory.GetSearchVerifyRunner(accessToken)
+	runner.SetVerifyReq(r)
+
+	resp, err := s.sessions.Execute(ctx, runner, database.ReqOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Response.(*api.VerifyCollectionSearchIndexResponse), nil
+}
+
+func (s *apiService) GetCollectionSearchIndexReport(ctx context.Context, r *api.GetCollectionSearchIndexReportRequest) (*api.GetCollectionSearchIndexReportResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	runner := s.runnerFactory.GetSearchVerifyRunner(accessToken)
+	runner.SetReportReq(r)
+
+	resp, err := s.sessions.Execute(ctx, runner, database.ReqOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Response.(*api.GetCollectionSearchIndexReportResponse), nil
+}
+
 func (s *apiService) Replace(ctx context.Context, r *api.ReplaceRequest) (*api.ReplaceResponse, error) {
 	qm := metrics.WriteQueryMetrics{}
 	accessToken, _ := request.GetAccessToken(ctx)
@@ -289,7 +343,8 @@ func (s *apiService) Replace(ctx context.Context, r *api.ReplaceRequest) (*api.R
 	return &api.ReplaceResponse{
 		Status: resp.Status,
 		Metadata: &api.ResponseMetadata{
-			CreatedAt: resp.CreatedAt.GetProtoTS(),
+			CreatedAt:     resp.CreatedAt.GetProtoTS(),
+			CommitVersion: resp.CommitVersion,
 		},
 		Keys: resp.AllKeys,
 	}, nil
@@ -309,7 +364,8 @@ func (s *apiService) Update(ctx context.Context, r *api.UpdateRequest) (*api.Upd
 		Status:        resp.Status,
 		ModifiedCount: resp.ModifiedCount,
 		Metadata: &api.ResponseMetadata{
-			UpdatedAt: resp.UpdatedAt.GetProtoTS(),
+			UpdatedAt:     resp.UpdatedAt.GetProtoTS(),
+			CommitVersion: resp.CommitVersion,
 		},
 	}, nil
 }
@@ -328,7 +384,8 @@ func (s *apiService) Delete(ctx context.Context, r *api.DeleteRequest) (*api.Del
 		DeletedCount: resp.ModifiedCount,
 		Status:       resp.Status,
 		Metadata: &api.ResponseMetadata{
-			DeletedAt: resp.DeletedAt.GetProtoTS(),
+			DeletedAt:     resp.DeletedAt.GetProtoTS(),
+			CommitVersion: resp.CommitVersion,
 		},
 	}, nil
 }
@@ -349,6 +406,13 @@ func (s *apiService) Read(r *api.ReadRequest, stream api.Tigris_ReadServer) erro
 	return err
 }
 
+func (s *apiService) Export(r *api.ExportRequest, stream api.Tigris_ExportServer) error {
+	queryMetrics := metrics.StreamingQueryMetrics{}
+	accessToken, _ := request.GetAccessToken(stream.Context())
+	_, err := s.sessions.ReadOnlyExecute(stream.Context(), s.runnerFactory.GetExportQueryRunner(r, stream, &queryMetrics, accessToken), database.ReqOptions{})
+	return err
+}
+
 func (s *apiService) Count(ctx context.Context, r *api.CountRequest) (*api.CountResponse, error) {
 	queryMetrics := metrics.StreamingQueryMetrics{}
 	accessToken, _ := request.GetAccessToken(ctx)
@@ -563,6 +627,98 @@ func (s *apiService) DeleteProject(ctx context.Context, r *api.DeleteProjectRequ
 	}, nil
 }
 
+func (s *apiService) BackupProject(r *api.BackupProjectRequest, stream api.Tigris_BackupProjectServer) error {
+	accessToken, _ := request.GetAccessToken(stream.Context())
+	runner := s.runnerFactory.GetBackupRunner(r.GetProject(), &backupStreamWriter{stream: stream}, s.streams, accessToken)
+	if _, err := s.sessions.ReadOnlyExecute(stream.Context(), runner, database.ReqOptions{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *apiService) RestoreProject(stream api.Tigris_RestoreProjectServer) error {
+	ctx := stream.Context()
+
+	// the archive is streamed in chunks, the first message carries the target project. The project is not known to
+	// the authz interceptor for a client stream, so check here that the token is allowed to access it.
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	accessToken, _ := request.GetAccessToken(ctx)
+	if accessToken != nil && accessToken.Project != "" && accessToken.Project != first.GetProject() {
+		return errors.PermissionDenied("access to project '%s' is not allowed", first.GetProject())
+	}
+
+	reader := &streamDataReader{
+		data: first.GetArchive(),
+		recv: func() ([]byte, error) {
+			req, err := stream.Recv()
+			return req.GetArchive(), err
+		},
+	}
+	footer, err := database.NewRestorer(s.sessions, s.runnerFactory, s.streams).
+		Restore(ctx, first.GetProject(), reader, accessToken)
+	if err != nil {
+		return err
+	}
+
+	return stream.SendAndClose(&api.RestoreProjectResponse{
+		Status:    database.OkStatus,
+		Documents: footer.Documents,
+	})
+}
+
+func (s *apiService) BulkImport(stream api.Tigris_BulkImportServer) error {
+	ctx := stream.Context()
+
+	// the first message carries the options, the project is not known to the authz interceptor for a client
+	// stream, so check here that the token is allowed to access it.
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	accessToken, _ := request.GetAccessToken(ctx)
+	if accessToken != nil && accessToken.Project != "" && accessToken.Project != first.GetProject() {
+		return errors.PermissionDenied("access to project '%s' is not allowed", first.GetProject())
+	}
+
+	opts := &database.BulkImportOptions{
+		Project:          first.GetProject(),
+		Branch:           first.GetBranch(),
+		Collection:       first.GetCollection(),
+		Format:           first.GetFormat(),
+		Delimiter:        first.GetDelimiter(),
+		HeaderMapping:    first.GetHeaderMapping(),
+		CreateCollection: first.GetCreateCollection(),
+		EvolveSchema:     first.GetEvolveSchema(),
+		PrimaryKey:       first.GetPrimaryKey(),
+		Autogenerated:    first.GetAutogenerated(),
+		Replace:          first.GetReplace(),
+		SkipRows:         first.GetResumeToken(),
+	}
+
+	reader := &streamDataReader{
+		data: first.GetData(),
+		recv: func() ([]byte, error) {
+			req, err := stream.Recv()
+			return req.GetData(), err
+		},
+	}
+	total, err := database.NewBulkImporter(s.sessions, s.runnerFactory).
+		Import(ctx, opts, reader, accessToken, func(p *database.BulkImportProgress) error {
+			return stream.Send(bulkImportResponse(p, ""))
+		})
+	if err != nil {
+		return err
+	}
+
+	return stream.Send(bulkImportResponse(total, database.InsertedStatus))
+}
+
 func (s *apiService) DescribeCollection(ctx context.Context, r *api.DescribeCollectionRequest) (*api.DescribeCollectionResponse, error) {
 	accessToken, _ := request.GetAccessToken(ctx)
 	runner := s.runnerFactory.GetCollectionQueryRunner(accessToken)
@@ -673,3 +829,65 @@ func (s *apiService) ListGlobalAppKeys(ctx context.Context, req *api.ListGlobalA
 func (s *apiService) RotateGlobalAppKeySecret(ctx context.Context, req *api.RotateGlobalAppKeySecretRequest) (*api.RotateGlobalAppKeySecretResponse, error) {
 	return s.authProvider.RotateGlobalAppKeySecret(ctx, req)
 }
+
+// backupStreamWriter sends the archive to the client in chunks as it is produced by the backup runner.
+type backupStreamWriter struct {
+	stream api.Tigris_BackupProjectServer
+}
+
+func (w *backupStreamWriter) Write(p []byte) (int, error) {
+	data := make([]byte, len(p))
+	copy(data, p)
+
+	if err := w.stream.Send(&api.BackupProjectResponse{Data: data}); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// streamDataReader exposes the data of the messages of a client stream, like the bulk import or the restore requests,
+// as a single stream.
+type streamDataReader struct {
+	recv func() ([]byte, error)
+	data []byte
+	eof  bool
+}
+
+func (r *streamDataReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+
+		data, err := r.recv()
+		if err == io.EOF {
+			r.eof = true
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		r.data = data
+	}
+
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+
+	return n, nil
+}
+
+func bulkImportResponse(p *database.BulkImportProgress, status string) *api.BulkImportResponse {
+	resp := &api.BulkImportResponse{
+		Status:       status,
+		RowsRead:     p.RowsRead,
+		RowsImported: p.RowsImported,
+		RowsFailed:   p.RowsFailed,
+		ResumeToken:  p.ResumeToken,
+	}
+	for _, e := range p.Errors {
+		resp.Errors = append(resp.Errors, &api.BulkImportRowError{Row: e.Row, Error: e.Error})
+	}
+
+	return resp
+}
@@ -55,7 +55,7 @@ func newCacheService(tenantMgr *metadata.TenantManager, txMgr *transaction.Manag
 func (c *cacheService) CreateCache(ctx context.Context, req *api.CreateCacheRequest) (*api.CreateCacheResponse, error) {
 	accessToken, _ := request.GetAccessToken(ctx)
 
-	resp, err := c.sessions.TxExecute(ctx, c.runnerFactory.GetCreateCacheRunner(req, accessToken))
+	resp, err := c.sessions.TxExecute(ctx, c.runnerFactory.GetCreateCacheRunner(req, accessToken), cache.SessionOptions{IncVersion: true})
 	if err != nil {
 		return nil, err
 	}
@@ -68,7 +68,7 @@ func (c *cacheService) CreateCache(ctx context.Context, req *api.CreateCacheRequ
 func (c *cacheService) DeleteCache(ctx context.Context, req *api.DeleteCacheRequest) (*api.DeleteCacheResponse, error) {
 	accessToken, _ := request.GetAccessToken(ctx)
 
-	resp, err := c.sessions.TxExecute(ctx, c.runnerFactory.GetDeleteCacheRunner(req, accessToken))
+	resp, err := c.sessions.TxExecute(ctx, c.runnerFactory.GetDeleteCacheRunner(req, accessToken), cache.SessionOptions{IncVersion: true})
 	if err != nil {
 		return nil, err
 	}
@@ -81,7 +81,7 @@ func (c *cacheService) DeleteCache(ctx context.Context, req *api.DeleteCacheRequ
 func (c *cacheService) ListCaches(ctx context.Context, req *api.ListCachesRequest) (*api.ListCachesResponse, error) {
 	accessToken, _ := request.GetAccessToken(ctx)
 
-	resp, err := c.sessions.TxExecute(ctx, c.runnerFactory.GetListCachesRunner(req, accessToken))
+	resp, err := c.sessions.TxExecute(ctx, c.runnerFactory.GetListCachesRunner(req, accessToken), cache.SessionOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -138,6 +138,197 @@ func (c *cacheService) Del(ctx context.Context, req *api.DelRequest) (*api.DelRe
 	}, nil
 }
 
+func (c *cacheService) Expire(ctx context.Context, req *api.ExpireRequest) (*api.ExpireResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetExpireRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.ExpireResponse{
+		Status:  resp.Status,
+		Message: "Expiry is set successfully",
+	}, nil
+}
+
+func (c *cacheService) Persist(ctx context.Context, req *api.PersistRequest) (*api.PersistResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetPersistRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.PersistResponse{
+		Status:  resp.Status,
+		Message: "Expiry is removed successfully",
+	}, nil
+}
+
+func (c *cacheService) TTL(ctx context.Context, req *api.TTLRequest) (*api.TTLResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetTTLRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.TTLResponse{
+		TtlMs: resp.TTLMs,
+	}, nil
+}
+
+func (c *cacheService) MGet(ctx context.Context, req *api.MGetRequest) (*api.MGetResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetMGetRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.MGetResponse{
+		Entries: resp.Entries,
+	}, nil
+}
+
+func (c *cacheService) MSet(ctx context.Context, req *api.MSetRequest) (*api.MSetResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetMSetRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.MSetResponse{
+		Status:  resp.Status,
+		Message: fmt.Sprintf("Entries set count# %d", resp.Count),
+	}, nil
+}
+
+func (c *cacheService) Exists(ctx context.Context, req *api.ExistsRequest) (*api.ExistsResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetExistsRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.ExistsResponse{
+		Count: resp.Count,
+	}, nil
+}
+
+func (c *cacheService) Incr(ctx context.Context, req *api.IncrRequest) (*api.IncrResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetIncrRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.IncrResponse{
+		Value: resp.Counter,
+	}, nil
+}
+
+func (c *cacheService) IncrBy(ctx context.Context, req *api.IncrByRequest) (*api.IncrByResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetIncrByRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.IncrByResponse{
+		Value: resp.Counter,
+	}, nil
+}
+
+func (c *cacheService) Decr(ctx context.Context, req *api.DecrRequest) (*api.DecrResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetDecrRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.DecrResponse{
+		Value: resp.Counter,
+	}, nil
+}
+
+func (c *cacheService) HSet(ctx context.Context, req *api.HSetRequest) (*api.HSetResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetHSetRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.HSetResponse{
+		Status:  resp.Status,
+		Message: fmt.Sprintf("Fields added count# %d", resp.Count),
+	}, nil
+}
+
+func (c *cacheService) HGet(ctx context.Context, req *api.HGetRequest) (*api.HGetResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetHGetRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.HGetResponse{
+		Value: resp.Data,
+	}, nil
+}
+
+func (c *cacheService) HGetAll(ctx context.Context, req *api.HGetAllRequest) (*api.HGetAllResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetHGetAllRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.HGetAllResponse{
+		Fields: resp.Fields,
+	}, nil
+}
+
+func (c *cacheService) ZAdd(ctx context.Context, req *api.ZAddRequest) (*api.ZAddResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetZAddRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.ZAddResponse{
+		Added: resp.Count,
+	}, nil
+}
+
+func (c *cacheService) ZRange(ctx context.Context, req *api.ZRangeRequest) (*api.ZRangeResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetZRangeRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.ZRangeResponse{
+		Members: resp.Members,
+	}, nil
+}
+
+func (c *cacheService) ZRank(ctx context.Context, req *api.ZRankRequest) (*api.ZRankResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetZRankRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.ZRankResponse{
+		Rank: resp.Rank,
+	}, nil
+}
+
+func (c *cacheService) LPush(ctx context.Context, req *api.LPushRequest) (*api.LPushResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetLPushRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.LPushResponse{
+		Length: resp.Count,
+	}, nil
+}
+
+func (c *cacheService) RPop(ctx context.Context, req *api.RPopRequest) (*api.RPopResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetRPopRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.RPopResponse{
+		Value: resp.Data,
+	}, nil
+}
+
 func (c *cacheService) Keys(req *api.KeysRequest, streaming api.Cache_KeysServer) error {
 	accessToken, _ := request.GetAccessToken(streaming.Context())
 	_, err := c.sessions.Execute(streaming.Context(), c.runnerFactory.GetKeysRunner(req, accessToken, streaming))
@@ -17,9 +17,11 @@ package cache
 import api "github.com/tigrisdata/tigris/api/server/v1"
 
 const (
-	SetStatus     string = "set"
-	DeletedStatus string = "deleted"
-	CreatedStatus string = "created"
+	SetStatus       string = "set"
+	DeletedStatus   string = "deleted"
+	CreatedStatus   string = "created"
+	ExpiredStatus   string = "expiry_set"
+	PersistedStatus string = "persisted"
 )
 
 // Response is a wrapper on api.Response.
@@ -32,6 +34,13 @@ type Response struct {
 	DeletedCount int64
 	Caches       []*api.CacheMetadata
 	Cursor       uint64
+	TTLMs        int64
+	Entries      []*api.CacheEntry
+	Count        int64
+	Counter      int64
+	Fields       map[string][]byte
+	Members      []*api.ZMember
+	Rank         int64
 }
 
 // StreamingKeys is a wrapper interface for passing around for streaming cache keys.
@@ -16,6 +16,7 @@ package cache
 
 import (
 	"context"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	api "github.com/tigrisdata/tigris/api/server/v1"
@@ -103,6 +104,42 @@ type KeysRunner struct {
 	streaming StreamingKeys
 }
 
+type ExpireRunner struct {
+	*BaseRunner
+
+	req *api.ExpireRequest
+}
+
+type PersistRunner struct {
+	*BaseRunner
+
+	req *api.PersistRequest
+}
+
+type TTLRunner struct {
+	*BaseRunner
+
+	req *api.TTLRequest
+}
+
+type MGetRunner struct {
+	*BaseRunner
+
+	req *api.MGetRequest
+}
+
+type MSetRunner struct {
+	*BaseRunner
+
+	req *api.MSetRequest
+}
+
+type ExistsRunner struct {
+	*BaseRunner
+
+	req *api.ExistsRequest
+}
+
 type RunnerFactory struct {
 	encoder    metadata.CacheEncoder
 	cacheStore cache.Cache
@@ -173,16 +210,60 @@ func (f *RunnerFactory) GetKeysRunner(r *api.KeysRequest, accessToken *types.Acc
 	}
 }
 
+func (f *RunnerFactory) GetExpireRunner(r *api.ExpireRequest, accessToken *types.AccessToken) *ExpireRunner {
+	return &ExpireRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetPersistRunner(r *api.PersistRequest, accessToken *types.AccessToken) *PersistRunner {
+	return &PersistRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetTTLRunner(r *api.TTLRequest, accessToken *types.AccessToken) *TTLRunner {
+	return &TTLRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetMGetRunner(r *api.MGetRequest, accessToken *types.AccessToken) *MGetRunner {
+	return &MGetRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetMSetRunner(r *api.MSetRequest, accessToken *types.AccessToken) *MSetRunner {
+	return &MSetRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetExistsRunner(r *api.ExistsRequest, accessToken *types.AccessToken) *ExistsRunner {
+	return &ExistsRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
 func (runner *CreateCacheRunner) Run(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant) (Response, context.Context, error) {
 	currentSub, err := request.GetCurrentSub(ctx)
 	if err != nil && config.DefaultConfig.Auth.Enabled {
 		return Response{}, ctx, errors.Internal("Failed to get current sub for the request")
 	}
 
-	_, err = tenant.CreateCache(ctx, tx, runner.req.GetProject(), runner.req.GetName(), currentSub)
+	// the default expiry is only kept in the metadata of the cache, it is loaded along with the project
+	_, err = tenant.CreateCache(ctx, tx, runner.req.GetProject(), runner.req.GetName(), currentSub, runner.req.GetOptions().GetTtlMs())
 	if err != nil {
 		return Response{}, ctx, createApiError(err)
 	}
+
 	return Response{
 		Status: database.CreatedStatus,
 	}, ctx, nil
@@ -222,14 +303,22 @@ func (runner *DeleteCacheRunner) Run(ctx context.Context, tx transaction.Tx, ten
 }
 
 func (runner *ListCachesRunner) Run(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant) (Response, context.Context, error) {
-	caches, err := tenant.ListCaches(ctx, tx, runner.req.GetProject())
+	caches, err := tenant.ListCachesMetadata(ctx, tx, runner.req.GetProject())
 	if err != nil {
 		return Response{}, ctx, err
 	}
 	cachesMetadata := make([]*api.CacheMetadata, len(caches))
-	for i, cache := range caches {
+	for i, c := range caches {
 		cachesMetadata[i] = &api.CacheMetadata{
-			Name: cache,
+			Name: c.Name,
+		}
+		if runner.req.GetIncludeStats() {
+			cachesMetadata[i].Stats = runner.cacheStats(ctx, tenant, c.Name)
+		}
+		if c.DefaultTTLMs > 0 {
+			cachesMetadata[i].Options = &api.CacheOptions{
+				TtlMs: c.DefaultTTLMs,
+			}
 		}
 	}
 	return Response{
@@ -237,6 +326,26 @@ func (runner *ListCachesRunner) Run(ctx context.Context, tx transaction.Tx, tena
 	}, ctx, nil
 }
 
+// cacheStats returns the number of keys and the memory used by the cache, nil if they can't be computed as the stats
+// are informational. The stats scan all the keys of the cache, so these are only returned if requested.
+func (runner *ListCachesRunner) cacheStats(ctx context.Context, tenant *metadata.Tenant, name string) *api.CacheStats {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), name, runner.encoder)
+	if err != nil {
+		return nil
+	}
+
+	stats, err := runner.cacheStore.Stats(ctx, tableName)
+	if err != nil {
+		log.Warn().Err(err).Str("cacheTableName", tableName).Msg("Failed to get cache stats")
+		return nil
+	}
+
+	return &api.CacheStats{
+		Keys:        stats.Keys,
+		MemoryBytes: stats.MemoryBytes,
+	}
+}
+
 func (runner *SetRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
 	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
 	if err != nil {
@@ -249,6 +358,9 @@ func (runner *SetRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Resp
 		EX: runner.req.GetEx(),
 		PX: runner.req.GetPx(),
 	}
+	if options.EX == 0 && options.PX == 0 {
+		options.PX = defaultTTLMs(tenant, runner.req.GetProject(), runner.req.GetName())
+	}
 
 	if err = runner.cacheStore.Set(ctx, tableName, runner.req.GetKey(), internal.NewCacheData(runner.req.GetValue()), options); err != nil {
 		return Response{}, errors.Internal("Failed to invoke set, reason %s", err.Error())
@@ -265,7 +377,9 @@ func (runner *GetSetRunner) Run(ctx context.Context, tenant *metadata.Tenant) (R
 		return Response{}, err
 	}
 
-	oldVal, err := runner.cacheStore.GetSet(ctx, tableName, runner.req.GetKey(), internal.NewCacheData(runner.req.GetValue()))
+	// the new value doesn't keep the expiry of the old one, so the default expiry of the cache is set with the value
+	ttl := time.Duration(defaultTTLMs(tenant, runner.req.GetProject(), runner.req.GetName())) * time.Millisecond
+	oldVal, err := runner.cacheStore.GetSet(ctx, tableName, runner.req.GetKey(), internal.NewCacheData(runner.req.GetValue()), ttl)
 	if err != nil {
 		return Response{}, errors.Internal("Failed to invoke set, reason %s", err.Error())
 	}
@@ -354,12 +468,210 @@ func (runner *KeysRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Res
 	return Response{}, nil
 }
 
+func (runner *ExpireRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	var ttl time.Duration
+	switch {
+	case runner.req.GetEx() > 0:
+		ttl = time.Duration(runner.req.GetEx()) * time.Second
+	case runner.req.GetPx() > 0:
+		ttl = time.Duration(runner.req.GetPx()) * time.Millisecond
+	default:
+		return Response{}, errors.InvalidArgument("expiry is required, set either 'ex' or 'px'")
+	}
+
+	if err = runner.cacheStore.Expire(ctx, tableName, runner.req.GetKey(), ttl); err != nil {
+		if err == cache.ErrKeyNotFound {
+			return Response{}, errors.NotFound(err.Error())
+		}
+		return Response{}, errors.Internal("Failed to invoke expire, reason %s", err.Error())
+	}
+
+	return Response{
+		Status: ExpiredStatus,
+	}, nil
+}
+
+func (runner *PersistRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	if err = runner.cacheStore.Persist(ctx, tableName, runner.req.GetKey()); err != nil {
+		if err == cache.ErrKeyNotFound {
+			return Response{}, errors.NotFound(err.Error())
+		}
+		return Response{}, errors.Internal("Failed to invoke persist, reason %s", err.Error())
+	}
+
+	return Response{
+		Status: PersistedStatus,
+	}, nil
+}
+
+func (runner *TTLRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	ttl, err := runner.cacheStore.TTL(ctx, tableName, runner.req.GetKey())
+	if err != nil {
+		if err == cache.ErrKeyNotFound {
+			return Response{}, errors.NotFound(err.Error())
+		}
+		return Response{}, errors.Internal("Failed to invoke ttl, reason %s", err.Error())
+	}
+
+	// -1 is returned for a key without an expiry
+	ttlMs := int64(-1)
+	if ttl != cache.NoExpiry {
+		ttlMs = ttl.Milliseconds()
+	}
+	return Response{
+		TTLMs: ttlMs,
+	}, nil
+}
+
+func (runner *MGetRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	if err := validateBatch(len(runner.req.GetKeys()), "keys"); err != nil {
+		return Response{}, err
+	}
+
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	values, err := runner.cacheStore.MGet(ctx, tableName, runner.req.GetKeys()...)
+	if err != nil {
+		return Response{}, errors.Internal("Failed to invoke mget, reason %s", err.Error())
+	}
+
+	entries := make([]*api.CacheEntry, len(values))
+	for i, v := range values {
+		entries[i] = &api.CacheEntry{
+			Key:   runner.req.GetKeys()[i],
+			Found: v != nil,
+		}
+		if v != nil {
+			entries[i].Value = v.GetRawData()
+		}
+	}
+	return Response{
+		Entries: entries,
+	}, nil
+}
+
+func (runner *MSetRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	if err := validateBatch(len(runner.req.GetEntries()), "entries"); err != nil {
+		return Response{}, err
+	}
+
+	values := make(map[string]*internal.CacheData, len(runner.req.GetEntries()))
+	for _, e := range runner.req.GetEntries() {
+		if len(e.GetKey()) == 0 {
+			return Response{}, errors.InvalidArgument("key is required for all the entries")
+		}
+		if _, ok := values[e.GetKey()]; ok {
+			return Response{}, errors.InvalidArgument("duplicate key '%s'", e.GetKey())
+		}
+		values[e.GetKey()] = internal.NewCacheData(e.GetValue())
+	}
+
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	options := &cache.SetOptions{
+		NX: runner.req.GetNx(),
+		EX: runner.req.GetEx(),
+		PX: runner.req.GetPx(),
+	}
+	if options.EX == 0 && options.PX == 0 {
+		options.PX = defaultTTLMs(tenant, runner.req.GetProject(), runner.req.GetName())
+	}
+
+	if err = runner.cacheStore.MSet(ctx, tableName, values, options); err != nil {
+		if err == cache.ErrKeyAlreadyExists {
+			return Response{}, errors.AlreadyExists(err.Error())
+		}
+		return Response{}, errors.Internal("Failed to invoke mset, reason %s", err.Error())
+	}
+
+	return Response{
+		Status: SetStatus,
+		Count:  int64(len(values)),
+	}, nil
+}
+
+func (runner *ExistsRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	if err := validateBatch(len(runner.req.GetKeys()), "keys"); err != nil {
+		return Response{}, err
+	}
+
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	count, err := runner.cacheStore.Exists(ctx, tableName, runner.req.GetKeys()...)
+	if err != nil {
+		return Response{}, errors.Internal("Failed to invoke exists, reason %s", err.Error())
+	}
+	return Response{
+		Count: count,
+	}, nil
+}
+
+// defaultTTLMs returns the expiry of the keys set without an explicit expiry, zero if the keys of the cache don't
+// expire by default. The expiry is read from the metadata of the cache loaded with the project.
+func defaultTTLMs(tenant *metadata.Tenant, projectName string, cacheName string) uint64 {
+	project, err := tenant.GetProject(projectName)
+	if err != nil {
+		return 0
+	}
+
+	c, err := project.GetCache(cacheName)
+	if err != nil {
+		return 0
+	}
+
+	return c.DefaultTTLMs
+}
+
+// validateBatch checks the number of items of the batch requests.
+func validateBatch(count int, items string) error {
+	if count == 0 {
+		return errors.InvalidArgument("'%s' can't be empty", items)
+	}
+	if maxBatch := config.DefaultConfig.Cache.MaxBatch; maxBatch > 0 && count > maxBatch {
+		return errors.InvalidArgument("too many '%s', the maximum is %d", items, maxBatch)
+	}
+
+	return nil
+}
+
 func getEncodedCacheTableName(_ context.Context, tenant *metadata.Tenant, projectName string, cacheName string, encoder metadata.CacheEncoder) (string, error) {
 	project, err := tenant.GetProject(projectName)
 	if err != nil {
 		return "", createApiError(err)
 	}
 
+	// Only the caches created through CreateCache are reachable. The cache store also keeps the internal tables of
+	// the project, like the streams of the realtime channels, under the same encoding, so a name which isn't a cache
+	// could otherwise read or overwrite them. The keys written in a cache that was never created aren't reachable
+	// anymore, the cache has to be created first.
+	if _, err = project.GetCache(cacheName); err != nil {
+		return "", createApiError(err)
+	}
+
 	// Encode cache table is encoding tenant id, project id(main database id) and cache name.
 	encodedCacheTableName, err := encoder.EncodeCacheTableName(tenant.GetNamespace().Id(), project.Id(), cacheName)
 	if err != nil {
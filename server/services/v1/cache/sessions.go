@@ -25,13 +25,19 @@ import (
 	"github.com/tigrisdata/tigris/server/transaction"
 )
 
+type SessionOptions struct {
+	// IncVersion is set by the requests changing the metadata of the caches, the tenant is reloaded by the servers
+	// once the version is incremented.
+	IncVersion bool
+}
+
 type Session interface {
 	// Execute executes the request using the query runner
 	Execute(ctx context.Context, runner Runner) (Response, error)
 
 	// TxExecute executes in a fdb transaction.
 	// Metadata of caches are stored in fdb as part of project metadata and that modification is a transactional operation.
-	TxExecute(ctx context.Context, runner TxRunner) (Response, error)
+	TxExecute(ctx context.Context, runner TxRunner, option SessionOptions) (Response, error)
 }
 
 type SessionManager struct {
@@ -70,7 +76,7 @@ func (sessMgr *SessionManager) Execute(ctx context.Context, runner Runner) (Resp
 	return runner.Run(ctx, tenant)
 }
 
-func (sessMgr *SessionManager) TxExecute(ctx context.Context, runner TxRunner) (Response, error) {
+func (sessMgr *SessionManager) TxExecute(ctx context.Context, runner TxRunner, option SessionOptions) (Response, error) {
 	namespaceForThisSession, err := request.GetNamespace(ctx)
 	if err != nil {
 		return Response{}, err
@@ -99,8 +105,22 @@ func (sessMgr *SessionManager) TxExecute(ctx context.Context, runner TxRunner) (
 		_ = tx.Rollback(ctx)
 		return Response{}, err
 	}
+	if option.IncVersion {
+		if err = sessMgr.versionH.Increment(ctx, tx); err != nil {
+			log.Warn().Err(err).Msgf("Failed to increment metadata version")
+			_ = tx.Rollback(ctx)
+			return Response{}, errors.Internal("Failed to perform transaction")
+		}
+	}
 	if err = tx.Commit(ctx); err != nil {
 		return Response{}, errors.Internal("Failed to run runner in transaction, failed to commit")
 	}
+
+	if option.IncVersion {
+		// the caches are reloaded with the project, so the cache is visible to the next requests
+		if _, err = sessMgr.tenantTracker.InstantTracking(ctx, nil, tenant); err != nil {
+			log.Warn().Err(err).Msgf("Failed to reload the tenant")
+		}
+	}
 	return resp, nil
 }
@@ -0,0 +1,389 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"time"
+
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/types"
+	"github.com/tigrisdata/tigris/store/cache"
+)
+
+type IncrByRunner struct {
+	*BaseRunner
+
+	req *api.IncrByRequest
+}
+
+type HSetRunner struct {
+	*BaseRunner
+
+	req *api.HSetRequest
+}
+
+type HGetRunner struct {
+	*BaseRunner
+
+	req *api.HGetRequest
+}
+
+type HGetAllRunner struct {
+	*BaseRunner
+
+	req *api.HGetAllRequest
+}
+
+type ZAddRunner struct {
+	*BaseRunner
+
+	req *api.ZAddRequest
+}
+
+type ZRangeRunner struct {
+	*BaseRunner
+
+	req *api.ZRangeRequest
+}
+
+type ZRankRunner struct {
+	*BaseRunner
+
+	req *api.ZRankRequest
+}
+
+type LPushRunner struct {
+	*BaseRunner
+
+	req *api.LPushRequest
+}
+
+type RPopRunner struct {
+	*BaseRunner
+
+	req *api.RPopRequest
+}
+
+// GetIncrRunner returns a runner incrementing the counter by one.
+func (f *RunnerFactory) GetIncrRunner(r *api.IncrRequest, accessToken *types.AccessToken) *IncrByRunner {
+	return f.GetIncrByRunner(&api.IncrByRequest{
+		Project:   r.GetProject(),
+		Name:      r.GetName(),
+		Key:       r.GetKey(),
+		Increment: 1,
+	}, accessToken)
+}
+
+// GetDecrRunner returns a runner decrementing the counter by one.
+func (f *RunnerFactory) GetDecrRunner(r *api.DecrRequest, accessToken *types.AccessToken) *IncrByRunner {
+	return f.GetIncrByRunner(&api.IncrByRequest{
+		Project:   r.GetProject(),
+		Name:      r.GetName(),
+		Key:       r.GetKey(),
+		Increment: -1,
+	}, accessToken)
+}
+
+func (f *RunnerFactory) GetIncrByRunner(r *api.IncrByRequest, accessToken *types.AccessToken) *IncrByRunner {
+	return &IncrByRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetHSetRunner(r *api.HSetRequest, accessToken *types.AccessToken) *HSetRunner {
+	return &HSetRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetHGetRunner(r *api.HGetRequest, accessToken *types.AccessToken) *HGetRunner {
+	return &HGetRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetHGetAllRunner(r *api.HGetAllRequest, accessToken *types.AccessToken) *HGetAllRunner {
+	return &HGetAllRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetZAddRunner(r *api.ZAddRequest, accessToken *types.AccessToken) *ZAddRunner {
+	return &ZAddRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetZRangeRunner(r *api.ZRangeRequest, accessToken *types.AccessToken) *ZRangeRunner {
+	return &ZRangeRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetZRankRunner(r *api.ZRankRequest, accessToken *types.AccessToken) *ZRankRunner {
+	return &ZRankRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetLPushRunner(r *api.LPushRequest, accessToken *types.AccessToken) *LPushRunner {
+	return &LPushRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetRPopRunner(r *api.RPopRequest, accessToken *types.AccessToken) *RPopRunner {
+	return &RPopRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (runner *IncrByRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	value, err := runner.cacheStore.IncrBy(ctx, tableName, runner.req.GetKey(), runner.req.GetIncrement())
+	if err != nil {
+		return Response{}, convertStructureErr(err, "incrby")
+	}
+	if err = runner.applyDefaultTTL(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), tableName, runner.req.GetKey()); err != nil {
+		return Response{}, err
+	}
+
+	return Response{
+		Counter: value,
+	}, nil
+}
+
+func (runner *HSetRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	if err := validateBatch(len(runner.req.GetFields()), "fields"); err != nil {
+		return Response{}, err
+	}
+
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	fields := make(map[string]*internal.CacheData, len(runner.req.GetFields()))
+	for f, v := range runner.req.GetFields() {
+		fields[f] = internal.NewCacheData(v)
+	}
+
+	added, err := runner.cacheStore.HSet(ctx, tableName, runner.req.GetKey(), fields)
+	if err != nil {
+		return Response{}, convertStructureErr(err, "hset")
+	}
+	if err = runner.applyDefaultTTL(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), tableName, runner.req.GetKey()); err != nil {
+		return Response{}, err
+	}
+
+	return Response{
+		Status: SetStatus,
+		Count:  added,
+	}, nil
+}
+
+func (runner *HGetRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	value, err := runner.cacheStore.HGet(ctx, tableName, runner.req.GetKey(), runner.req.GetField())
+	if err != nil {
+		return Response{}, convertStructureErr(err, "hget")
+	}
+
+	return Response{
+		Data: value.GetRawData(),
+	}, nil
+}
+
+func (runner *HGetAllRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	values, err := runner.cacheStore.HGetAll(ctx, tableName, runner.req.GetKey())
+	if err != nil {
+		return Response{}, convertStructureErr(err, "hgetall")
+	}
+
+	fields := make(map[string][]byte, len(values))
+	for f, v := range values {
+		fields[f] = v.GetRawData()
+	}
+
+	return Response{
+		Fields: fields,
+	}, nil
+}
+
+func (runner *ZAddRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	if err := validateBatch(len(runner.req.GetMembers()), "members"); err != nil {
+		return Response{}, err
+	}
+
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	members := make([]cache.ZMember, len(runner.req.GetMembers()))
+	for i, m := range runner.req.GetMembers() {
+		members[i] = cache.ZMember{Member: m.GetMember(), Score: m.GetScore()}
+	}
+
+	added, err := runner.cacheStore.ZAdd(ctx, tableName, runner.req.GetKey(), members...)
+	if err != nil {
+		return Response{}, convertStructureErr(err, "zadd")
+	}
+	if err = runner.applyDefaultTTL(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), tableName, runner.req.GetKey()); err != nil {
+		return Response{}, err
+	}
+
+	return Response{
+		Count: added,
+	}, nil
+}
+
+func (runner *ZRangeRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	members, err := runner.cacheStore.ZRange(ctx, tableName, runner.req.GetKey(), runner.req.GetStart(), runner.req.GetStop(), runner.req.GetRev())
+	if err != nil {
+		return Response{}, convertStructureErr(err, "zrange")
+	}
+
+	result := make([]*api.ZMember, len(members))
+	for i, m := range members {
+		result[i] = &api.ZMember{
+			Member: m.Member,
+			Score:  m.Score,
+		}
+	}
+
+	return Response{
+		Members: result,
+	}, nil
+}
+
+func (runner *ZRankRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	rank, err := runner.cacheStore.ZRank(ctx, tableName, runner.req.GetKey(), runner.req.GetMember(), runner.req.GetRev())
+	if err != nil {
+		return Response{}, convertStructureErr(err, "zrank")
+	}
+
+	return Response{
+		Rank: rank,
+	}, nil
+}
+
+func (runner *LPushRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	if err := validateBatch(len(runner.req.GetValues()), "values"); err != nil {
+		return Response{}, err
+	}
+
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	values := make([]*internal.CacheData, len(runner.req.GetValues()))
+	for i, v := range runner.req.GetValues() {
+		values[i] = internal.NewCacheData(v)
+	}
+
+	length, err := runner.cacheStore.LPush(ctx, tableName, runner.req.GetKey(), values...)
+	if err != nil {
+		return Response{}, convertStructureErr(err, "lpush")
+	}
+	if err = runner.applyDefaultTTL(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), tableName, runner.req.GetKey()); err != nil {
+		return Response{}, err
+	}
+
+	return Response{
+		Count: length,
+	}, nil
+}
+
+func (runner *RPopRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	value, err := runner.cacheStore.RPop(ctx, tableName, runner.req.GetKey())
+	if err != nil {
+		return Response{}, convertStructureErr(err, "rpop")
+	}
+
+	return Response{
+		Data: value.GetRawData(),
+	}, nil
+}
+
+// applyDefaultTTL sets the default expiry of the cache on a key created by one of the data structure operations, the
+// expiry of an existing key is left as is.
+func (runner *BaseRunner) applyDefaultTTL(ctx context.Context, tenant *metadata.Tenant, projectName string, cacheName string,
+	tableName string, key string,
+) error {
+	ttlMs := defaultTTLMs(tenant, projectName, cacheName)
+	if ttlMs == 0 {
+		return nil
+	}
+
+	if err := runner.cacheStore.ExpireIfPersistent(ctx, tableName, key, time.Duration(ttlMs)*time.Millisecond); err != nil {
+		return errors.Internal("Failed to invoke expire, reason %s", err.Error())
+	}
+
+	return nil
+}
+
+func convertStructureErr(err error, operation string) error {
+	switch err {
+	case cache.ErrKeyNotFound:
+		return errors.NotFound(err.Error())
+	case cache.ErrWrongType:
+		return errors.InvalidArgument(err.Error())
+	default:
+		return errors.Internal("Failed to invoke %s, reason %s", operation, err.Error())
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/tigrisdata/tigris/errors"
+)
+
+// BackupFormatVersion is the version of the logical backup archive. It is written in the header of every archive and
+// restore refuses to read archives with a version it doesn't know about.
+const BackupFormatVersion = 1
+
+// maxBackupRecordSize is the largest single record that the reader accepts. Documents are bounded by the chunking
+// limit, so this leaves enough headroom for the JSON envelope around them.
+const maxBackupRecordSize = 64 * 1024 * 1024
+
+// backupChunkSize is the size of the buffer in front of the destination, every flush of it is one chunk on the wire.
+const backupChunkSize = 1024 * 1024
+
+type BackupRecordType string
+
+const (
+	BackupHeaderRecord      BackupRecordType = "header"
+	BackupBranchRecord      BackupRecordType = "branch"
+	BackupCollectionRecord  BackupRecordType = "collection"
+	BackupDocumentRecord    BackupRecordType = "document"
+	BackupSearchIndexRecord BackupRecordType = "search_index"
+	BackupCacheRecord       BackupRecordType = "cache"
+	BackupChannelRecord     BackupRecordType = "channel"
+	BackupFooterRecord      BackupRecordType = "footer"
+)
+
+// BackupChunkConsistency is the consistency of an archive whose collections are read in chunks, each chunk being read
+// in its own transaction. A chunk is a point-in-time snapshot, but the writes committed during the backup may or may
+// not be part of the archive.
+const BackupChunkConsistency = "chunk"
+
+// BackupHeader is the first record of an archive. ReadVersion is the FDB version at which the backup started, the
+// documents are read at this version or later ones as described by Consistency.
+type BackupHeader struct {
+	FormatVersion int    `json:"format_version"`
+	Project       string `json:"project"`
+	ReadVersion   int64  `json:"read_version"`
+	Consistency   string `json:"consistency"`
+	CreatedAt     int64  `json:"created_at"`
+}
+
+// BackupFooter is the last record of an archive. It is used by restore to detect truncated archives.
+type BackupFooter struct {
+	Branches      int64 `json:"branches"`
+	Collections   int64 `json:"collections"`
+	Documents     int64 `json:"documents"`
+	SearchIndexes int64 `json:"search_indexes"`
+	Caches        int64 `json:"caches"`
+	Channels      int64 `json:"channels"`
+}
+
+func (f *BackupFooter) String() string {
+	return fmt.Sprintf("%d branches, %d collections, %d documents, %d search indexes, %d caches and %d channels",
+		f.Branches, f.Collections, f.Documents, f.SearchIndexes, f.Caches, f.Channels)
+}
+
+// BackupRecord is a single line of the archive. The archive is a JSON Lines stream where the first record is always
+// the header, followed by all the branches, then per branch the collection schemas and their documents, then the
+// search indexes, caches and channels of the project, and finally the footer.
+type BackupRecord struct {
+	Type       BackupRecordType    `json:"type"`
+	Header     *BackupHeader       `json:"header,omitempty"`
+	Footer     *BackupFooter       `json:"footer,omitempty"`
+	Branch     string              `json:"branch,omitempty"`
+	Collection string              `json:"collection,omitempty"`
+	Name       string              `json:"name,omitempty"`
+	Schema     jsoniter.RawMessage `json:"schema,omitempty"`
+	Document   jsoniter.RawMessage `json:"document,omitempty"`
+	CreatedAt  int64               `json:"created_at,omitempty"`
+	UpdatedAt  int64               `json:"updated_at,omitempty"`
+}
+
+// BackupWriter writes the archive records to the underlying writer and keeps the counters for the footer.
+type BackupWriter struct {
+	w      *bufio.Writer
+	footer BackupFooter
+}
+
+func NewBackupWriter(w io.Writer) *BackupWriter {
+	return &BackupWriter{
+		w: bufio.NewWriterSize(w, backupChunkSize),
+	}
+}
+
+func (bw *BackupWriter) Write(record *BackupRecord) error {
+	switch record.Type {
+	case BackupBranchRecord:
+		bw.footer.Branches++
+	case BackupCollectionRecord:
+		bw.footer.Collections++
+	case BackupDocumentRecord:
+		bw.footer.Documents++
+	case BackupSearchIndexRecord:
+		bw.footer.SearchIndexes++
+	case BackupCacheRecord:
+		bw.footer.Caches++
+	case BackupChannelRecord:
+		bw.footer.Channels++
+	}
+
+	enc, err := jsoniter.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err = bw.w.Write(enc); err != nil {
+		return err
+	}
+
+	return bw.w.WriteByte('\n')
+}
+
+// Close writes the footer and flushes the buffered records. It doesn't close the underlying writer.
+func (bw *BackupWriter) Close() (*BackupFooter, error) {
+	footer := bw.footer
+	if err := bw.Write(&BackupRecord{Type: BackupFooterRecord, Footer: &footer}); err != nil {
+		return nil, err
+	}
+
+	return &footer, bw.w.Flush()
+}
+
+// BackupReader reads the archive records. It validates the header on the first call and returns io.EOF once the
+// footer is consumed.
+type BackupReader struct {
+	scanner *bufio.Scanner
+	header  *BackupHeader
+	footer  *BackupFooter
+}
+
+func NewBackupReader(r io.Reader) *BackupReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxBackupRecordSize)
+
+	return &BackupReader{
+		scanner: scanner,
+	}
+}
+
+// Header returns the header of the archive, reading it if it is not yet consumed.
+func (br *BackupReader) Header() (*BackupHeader, error) {
+	if br.header != nil {
+		return br.header, nil
+	}
+
+	record, err := br.read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.InvalidArgument("backup archive is empty")
+		}
+		return nil, err
+	}
+	if record.Type != BackupHeaderRecord || record.Header == nil {
+		return nil, errors.InvalidArgument("backup archive doesn't start with a header")
+	}
+	if record.Header.FormatVersion != BackupFormatVersion {
+		return nil, errors.InvalidArgument("unsupported backup format version '%d'", record.Header.FormatVersion)
+	}
+
+	br.header = record.Header
+	return br.header, nil
+}
+
+// Next returns the next record after the header. The footer is not returned, instead io.EOF is returned once it is
+// read. An archive without footer is treated as truncated.
+func (br *BackupReader) Next() (*BackupRecord, error) {
+	if _, err := br.Header(); err != nil {
+		return nil, err
+	}
+	if br.footer != nil {
+		return nil, io.EOF
+	}
+
+	record, err := br.read()
+	if err == io.EOF {
+		return nil, errors.InvalidArgument("backup archive is truncated, footer is missing")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if record.Type == BackupFooterRecord {
+		if record.Footer == nil {
+			return nil, errors.InvalidArgument("backup archive footer is empty")
+		}
+		br.footer = record.Footer
+		return nil, io.EOF
+	}
+
+	return record, nil
+}
+
+// Footer returns the footer of the archive once Next returned io.EOF, nil before.
+func (br *BackupReader) Footer() *BackupFooter {
+	return br.footer
+}
+
+func (br *BackupReader) read() (*BackupRecord, error) {
+	if !br.scanner.Scan() {
+		if err := br.scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	var record BackupRecord
+	if err := jsoniter.Unmarshal(br.scanner.Bytes(), &record); err != nil {
+		return nil, errors.InvalidArgument("malformed backup record: %s", err.Error())
+	}
+
+	return &record, nil
+}
@@ -0,0 +1,561 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/keys"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/server/config"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/services/v1/auth"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/server/types"
+	"github.com/tigrisdata/tigris/store/cache"
+	"github.com/tigrisdata/tigris/store/kv"
+	ulog "github.com/tigrisdata/tigris/util/log"
+)
+
+// defaultRestoreBatchSize is the number of documents restored in a single transaction.
+const defaultRestoreBatchSize = 100
+
+// BackupRunner exports a logical backup of a project. FDB keeps only a few seconds of MVCC history, so a collection
+// is read in a sequence of transactions, each one resuming after the last key read by the previous one at the current
+// version. The archive is therefore not a single point-in-time snapshot: every chunk of a collection is consistent,
+// but the writes committed while the backup runs may or may not be part of it, which the header records. The read
+// version in the header is the version the backup started at. Search indexes, caches and channels are not versioned
+// and are listed once the documents are read.
+type BackupRunner struct {
+	*BaseQueryRunner
+
+	project  string
+	writer   *BackupWriter
+	streams  cache.Cache
+	cacheEnc metadata.CacheEncoder
+	footer   *BackupFooter
+}
+
+// Footer returns the counters of the archive once the backup is finished.
+func (runner *BackupRunner) Footer() *BackupFooter {
+	return runner.footer
+}
+
+func (runner *BackupRunner) ReadOnly(ctx context.Context, tenant *metadata.Tenant) (Response, context.Context, error) {
+	project, err := tenant.GetProject(runner.project)
+	if err != nil {
+		return Response{}, ctx, CreateApiError(err)
+	}
+
+	tx, err := runner.txMgr.StartTx(ctx)
+	if err != nil {
+		return Response{}, ctx, err
+	}
+	readVersion, err := tx.GetReadVersion(ctx)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return Response{}, ctx, err
+	}
+	caches, err := tenant.ListCaches(ctx, tx, runner.project)
+	_ = tx.Rollback(ctx)
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	if err = runner.writer.Write(&BackupRecord{
+		Type: BackupHeaderRecord,
+		Header: &BackupHeader{
+			FormatVersion: BackupFormatVersion,
+			Project:       runner.project,
+			ReadVersion:   readVersion,
+			Consistency:   BackupChunkConsistency,
+			CreatedAt:     time.Now().UTC().UnixNano(),
+		},
+	}); err != nil {
+		return Response{}, ctx, err
+	}
+
+	databases := project.GetDatabaseWithBranches()
+	for _, db := range databases {
+		if !db.IsBranch() {
+			continue
+		}
+		if err = runner.writer.Write(&BackupRecord{Type: BackupBranchRecord, Branch: db.BranchName()}); err != nil {
+			return Response{}, ctx, err
+		}
+	}
+
+	for _, db := range databases {
+		for _, coll := range db.ListCollection() {
+			if err = runner.backupCollection(ctx, db, coll); err != nil {
+				return Response{}, ctx, err
+			}
+		}
+	}
+
+	for _, index := range project.GetSearch().GetIndexes() {
+		if err = runner.writer.Write(&BackupRecord{
+			Type:   BackupSearchIndexRecord,
+			Name:   index.Name,
+			Schema: index.Schema,
+		}); err != nil {
+			return Response{}, ctx, err
+		}
+	}
+
+	for _, c := range caches {
+		if err = runner.writer.Write(&BackupRecord{Type: BackupCacheRecord, Name: c}); err != nil {
+			return Response{}, ctx, err
+		}
+	}
+
+	for _, ch := range runner.listChannels(ctx, tenant, project) {
+		if err = runner.writer.Write(&BackupRecord{Type: BackupChannelRecord, Name: ch}); err != nil {
+			return Response{}, ctx, err
+		}
+	}
+
+	if runner.footer, err = runner.writer.Close(); err != nil {
+		return Response{}, ctx, err
+	}
+
+	log.Info().Str("project", runner.project).Int64("read_version", readVersion).
+		Int64("documents", runner.footer.Documents).Msg("backup completed")
+
+	return Response{Status: OkStatus}, ctx, nil
+}
+
+// backupCollection writes the schema followed by all the documents of the collection. The scan is resumed after the
+// last key in a new transaction whenever a transaction reaches the FDB duration limit.
+func (runner *BackupRunner) backupCollection(ctx context.Context, db *metadata.Database, coll *schema.DefaultCollection) error {
+	if err := runner.writer.Write(&BackupRecord{
+		Type:       BackupCollectionRecord,
+		Branch:     db.BranchName(),
+		Collection: coll.Name,
+		Schema:     coll.Schema,
+	}); err != nil {
+		return err
+	}
+
+	return resumeScan("backup", coll.Name, func(last []byte) ([]byte, error) {
+		return runner.scan(ctx, db, coll, last)
+	})
+}
+
+func (runner *BackupRunner) scan(ctx context.Context, db *metadata.Database, coll *schema.DefaultCollection, last []byte) ([]byte, error) {
+	tx, err := runner.txMgr.StartTx(ctx)
+	if err != nil {
+		return last, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var iter Iterator
+	reader := NewDatabaseReader(ctx, tx)
+	if last != nil {
+		var from keys.Key
+		if from, err = keys.FromBinary(coll.EncodedName, last); err != nil {
+			return last, err
+		}
+		iter, err = reader.ScanIterator(from, nil, false)
+	} else {
+		iter, err = reader.ScanTable(coll.EncodedName, false)
+	}
+	if err != nil {
+		return last, err
+	}
+
+	return readResumed(iter, last, func(row *Row) error {
+		return runner.writeDocument(db, coll, row)
+	})
+}
+
+func (runner *BackupRunner) writeDocument(db *metadata.Database, coll *schema.DefaultCollection, row *Row) error {
+	rawData := row.Data.RawData
+
+	var err error
+	if !coll.CompatibleSchemaSince(uint32(row.Data.Ver)) {
+		// archive always carries the documents in the latest schema as only the latest schema is part of the archive
+		if rawData, err = coll.UpdateRowSchemaRaw(rawData, uint32(row.Data.Ver)); err != nil {
+			return err
+		}
+	}
+
+	record := &BackupRecord{
+		Type:       BackupDocumentRecord,
+		Branch:     db.BranchName(),
+		Collection: coll.Name,
+		Document:   rawData,
+	}
+	if row.Data.CreatedAt != nil {
+		record.CreatedAt = row.Data.CreatedAt.UnixNano()
+	}
+	if row.Data.UpdatedAt != nil {
+		record.UpdatedAt = row.Data.UpdatedAt.UnixNano()
+	}
+
+	return runner.writer.Write(record)
+}
+
+// listChannels returns the realtime channels of the project. Channels live in the cache store, a failure to reach it
+// is logged and the channels are skipped so that the database part of the backup is still produced.
+func (runner *BackupRunner) listChannels(ctx context.Context, tenant *metadata.Tenant, project *metadata.Project) []string {
+	if runner.streams == nil {
+		return nil
+	}
+
+	prefix, err := runner.cacheEnc.EncodeCacheTableName(tenant.GetNamespace().Id(), project.Id(), "")
+	if ulog.E(err) {
+		return nil
+	}
+
+	streams, err := runner.streams.ListStreams(ctx, prefix)
+	if err != nil {
+		log.Err(err).Str("project", project.Name()).Msg("skipping channels in backup")
+		return nil
+	}
+
+	var channels []string
+	for _, s := range streams {
+		if _, _, ch, ok := runner.cacheEnc.DecodeCacheTableName(s); ok {
+			channels = append(channels, ch)
+		}
+	}
+
+	return channels
+}
+
+// RestoreRunner applies a single unit of a backup archive. The restore is split into many units so that the metadata
+// changes go through the session manager, which bumps the metadata version, and the documents are written in batches
+// that fit in a transaction. Secondary indexes are maintained on write and the search indexes are updated by the
+// transaction listeners on commit, which rebuilds all the indexes as part of the restore.
+type RestoreRunner struct {
+	*BaseQueryRunner
+
+	project  string
+	records  []*BackupRecord
+	streams  cache.Cache
+	cacheEnc metadata.CacheEncoder
+}
+
+func (runner *RestoreRunner) Run(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant) (Response, context.Context, error) {
+	for _, record := range runner.records {
+		var err error
+		switch record.Type {
+		case BackupHeaderRecord:
+			err = runner.restoreProject(ctx, tx, tenant)
+		case BackupBranchRecord:
+			err = runner.restoreBranch(ctx, tx, tenant, record)
+		case BackupCollectionRecord:
+			err = runner.restoreCollection(ctx, tx, tenant, record)
+		case BackupSearchIndexRecord:
+			err = runner.restoreSearchIndex(ctx, tx, tenant, record)
+		case BackupCacheRecord:
+			err = runner.restoreCache(ctx, tx, tenant, record)
+		case BackupChannelRecord:
+			err = runner.restoreChannel(ctx, tenant, record)
+		case BackupDocumentRecord:
+			err = runner.restoreDocument(ctx, tx, tenant, record)
+		default:
+			err = errors.InvalidArgument("unexpected backup record '%s'", record.Type)
+		}
+		if err != nil {
+			return Response{}, ctx, err
+		}
+	}
+
+	return Response{Status: OkStatus}, ctx, nil
+}
+
+// restoreProject creates the target project if it doesn't exist yet, so that an archive can be restored on an empty
+// cluster.
+func (runner *RestoreRunner) restoreProject(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant) error {
+	if _, err := tenant.GetProject(runner.project); err == nil {
+		tx.Context().MarkNoMetadataStateChanged()
+		return nil
+	}
+
+	projMetadata, err := createProjectMetadata(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	return tenant.CreateProject(ctx, tx, runner.project, projMetadata)
+}
+
+func (runner *RestoreRunner) restoreCache(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant, record *BackupRecord) error {
+	currentSub, _ := auth.GetCurrentSub(ctx)
+
+	_, err := tenant.CreateCache(ctx, tx, runner.project, record.Name, currentSub, 0)
+	if isMetadataErrCode(err, metadata.ErrCodeCacheExists) {
+		return nil
+	}
+
+	return err
+}
+
+func (runner *RestoreRunner) restoreChannel(ctx context.Context, tenant *metadata.Tenant, record *BackupRecord) error {
+	if runner.streams == nil {
+		return nil
+	}
+
+	project, err := tenant.GetProject(runner.project)
+	if err != nil {
+		return CreateApiError(err)
+	}
+
+	encStream, err := runner.cacheEnc.EncodeCacheTableName(tenant.GetNamespace().Id(), project.Id(), record.Name)
+	if err != nil {
+		return err
+	}
+
+	_, err = runner.streams.CreateOrGetStream(ctx, encStream)
+	return err
+}
+
+func (runner *RestoreRunner) restoreBranch(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant, record *BackupRecord) error {
+	err := tenant.CreateBranch(ctx, tx, runner.project, metadata.NewDatabaseNameWithBranch(runner.project, record.Branch))
+	if isMetadataErrCode(err, metadata.ErrCodeDatabaseBranchExists) {
+		return nil
+	}
+
+	return err
+}
+
+func (runner *RestoreRunner) restoreCollection(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant, record *BackupRecord) error {
+	db, err := runner.getDatabase(ctx, tx, tenant, runner.project, record.Branch)
+	if err != nil {
+		return err
+	}
+
+	factory, err := schema.NewFactoryBuilder(true).Build(record.Collection, record.Schema)
+	if err != nil {
+		return err
+	}
+
+	return tenant.CreateCollection(ctx, tx, db, factory)
+}
+
+func (runner *RestoreRunner) restoreSearchIndex(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant, record *BackupRecord) error {
+	project, err := tenant.GetProject(runner.project)
+	if err != nil {
+		return CreateApiError(err)
+	}
+
+	factory, err := schema.NewFactoryBuilder(true).BuildSearch(record.Name, record.Schema)
+	if err != nil {
+		return err
+	}
+	factory.Sub, _ = auth.GetCurrentSub(ctx)
+
+	return tenant.CreateSearchIndex(ctx, tx, project, factory)
+}
+
+// restoreDocument writes the document with the timestamps it had at the time of the backup. The primary key is
+// regenerated from the document as the collection may have a different encoding in the target.
+func (runner *RestoreRunner) restoreDocument(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant, record *BackupRecord) error {
+	db, coll, err := runner.getDBAndCollection(ctx, tx, tenant, runner.project, record.Collection, record.Branch)
+	if err != nil {
+		return err
+	}
+
+	ctx = runner.cdcMgr.WrapContext(ctx, db.Name())
+
+	keyGen := newKeyGenerator(record.Document, tenant.TableKeyGenerator, coll.GetPrimaryKey())
+	key, err := keyGen.generate(ctx, runner.txMgr, runner.encoder, coll.EncodedName)
+	if err != nil {
+		return err
+	}
+
+	createdAt := internal.NewTimestamp()
+	if record.CreatedAt > 0 {
+		createdAt = internal.CreateNewTimestamp(record.CreatedAt)
+	}
+	var updatedAt *internal.Timestamp
+	if record.UpdatedAt > 0 {
+		updatedAt = internal.CreateNewTimestamp(record.UpdatedAt)
+	}
+
+	tableData := internal.NewTableDataWithTS(createdAt, updatedAt, keyGen.document)
+	tableData.SetVersion(int32(coll.GetVersion()))
+
+	indexer := NewSecondaryIndexer(coll, false)
+	szCtx := ctx
+	if config.DefaultConfig.SecondaryIndex.WriteEnabled {
+		sz, err := indexer.ReadDocAndDelete(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+		szCtx = kv.CtxWithSize(ctx, sz)
+	}
+	if err = tx.Replace(szCtx, key, tableData, false); err != nil {
+		return err
+	}
+
+	if config.DefaultConfig.SecondaryIndex.WriteEnabled {
+		return indexer.Index(ctx, tx, tableData, key.IndexParts())
+	}
+
+	return nil
+}
+
+func isMetadataErrCode(err error, code metadata.ErrorCode) bool {
+	//nolint:errorlint
+	e, ok := err.(metadata.Error)
+	return ok && e.Code() == code
+}
+
+// Restorer replays a backup archive into a project. Metadata records are applied one per session so that every
+// change is versioned and visible to the next records, documents are grouped per collection in batches.
+type Restorer struct {
+	sessions  Session
+	factory   *QueryRunnerFactory
+	streams   cache.Cache
+	batchSize int
+}
+
+func NewRestorer(sessions Session, factory *QueryRunnerFactory, streams cache.Cache) *Restorer {
+	return &Restorer{
+		sessions:  sessions,
+		factory:   factory,
+		streams:   streams,
+		batchSize: defaultRestoreBatchSize,
+	}
+}
+
+// Restore reads the archive from the reader and restores it in the project. An empty project name restores the
+// archive in the project it was taken from.
+//
+// The archive is streamed, so the records are applied as they are read and a truncated archive, or one whose records
+// don't add up to the counters of its footer, is only detected at its end. The restore then fails and the records
+// applied so far are left in place: the project is partially restored. The records are idempotent, the documents are
+// replaced by primary key and the existing branches, collections, indexes and caches are kept, so restoring the
+// complete archive again in the same project finishes the restore.
+func (r *Restorer) Restore(ctx context.Context, project string, reader io.Reader, accessToken *types.AccessToken) (*BackupFooter, error) {
+	archive := NewBackupReader(reader)
+	header, err := archive.Header()
+	if err != nil {
+		return nil, err
+	}
+	if len(project) == 0 {
+		project = header.Project
+	}
+
+	footer, err := r.restore(ctx, project, archive, accessToken)
+	if err != nil {
+		log.Err(err).Str("project", project).Int64("read_version", header.ReadVersion).
+			Msg("restore failed, project is partially restored")
+		return nil, err
+	}
+
+	log.Info().Str("project", project).Int64("read_version", header.ReadVersion).
+		Int64("documents", footer.Documents).Msg("restore completed")
+
+	return footer, nil
+}
+
+// restore applies the records of the archive and checks the number of records applied against the footer.
+func (r *Restorer) restore(ctx context.Context, project string, archive *BackupReader, accessToken *types.AccessToken) (*BackupFooter, error) {
+	header, err := archive.Header()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = r.apply(ctx, project, []*BackupRecord{{Type: BackupHeaderRecord, Header: header}}, true, accessToken); err != nil {
+		return nil, err
+	}
+
+	var (
+		footer BackupFooter
+		batch  []*BackupRecord
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := r.apply(ctx, project, batch, false, accessToken)
+		batch = nil
+		return err
+	}
+
+	for {
+		record, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if record.Type != BackupDocumentRecord {
+			if err = flush(); err != nil {
+				return nil, err
+			}
+			if err = r.apply(ctx, project, []*BackupRecord{record}, true, accessToken); err != nil {
+				return nil, err
+			}
+			countRestored(&footer, record.Type)
+			continue
+		}
+
+		if len(batch) > 0 && (len(batch) >= r.batchSize || batch[0].Branch != record.Branch || batch[0].Collection != record.Collection) {
+			if err = flush(); err != nil {
+				return nil, err
+			}
+		}
+		batch = append(batch, record)
+		footer.Documents++
+	}
+
+	if err = flush(); err != nil {
+		return nil, err
+	}
+
+	if expected := archive.Footer(); *expected != footer {
+		return nil, errors.InvalidArgument("backup archive doesn't match its footer, restored %s instead of %s",
+			footer.String(), expected.String())
+	}
+
+	return &footer, nil
+}
+
+func (r *Restorer) apply(ctx context.Context, project string, records []*BackupRecord, metadataChange bool, accessToken *types.AccessToken) error {
+	runner := r.factory.GetRestoreRunner(project, records, r.streams, accessToken)
+
+	_, err := r.sessions.Execute(ctx, runner, ReqOptions{
+		MetadataChange:     metadataChange,
+		InstantVerTracking: metadataChange,
+	})
+	return err
+}
+
+func countRestored(footer *BackupFooter, recordType BackupRecordType) {
+	switch recordType {
+	case BackupBranchRecord:
+		footer.Branches++
+	case BackupCollectionRecord:
+		footer.Collections++
+	case BackupSearchIndexRecord:
+		footer.SearchIndexes++
+	case BackupCacheRecord:
+		footer.Caches++
+	case BackupChannelRecord:
+		footer.Channels++
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/errors"
+)
+
+func TestBackupArchive(t *testing.T) {
+	records := []*BackupRecord{
+		{Type: BackupBranchRecord, Branch: "staging"},
+		{Type: BackupCollectionRecord, Collection: "users", Schema: []byte(`{"title":"users"}`)},
+		{Type: BackupDocumentRecord, Collection: "users", Document: []byte(`{"id":1,"name":"a"}`), CreatedAt: 10, UpdatedAt: 20},
+		{Type: BackupDocumentRecord, Collection: "users", Document: []byte(`{"id":2,"name":"b"}`), CreatedAt: 30},
+		{Type: BackupSearchIndexRecord, Name: "idx", Schema: []byte(`{"title":"idx"}`)},
+		{Type: BackupCacheRecord, Name: "c1"},
+		{Type: BackupChannelRecord, Name: "ch1"},
+	}
+
+	t.Run("round_trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewBackupWriter(&buf)
+		require.NoError(t, w.Write(&BackupRecord{
+			Type:   BackupHeaderRecord,
+			Header: &BackupHeader{FormatVersion: BackupFormatVersion, Project: "p1", ReadVersion: 1234},
+		}))
+		for _, r := range records {
+			require.NoError(t, w.Write(r))
+		}
+		footer, err := w.Close()
+		require.NoError(t, err)
+		require.Equal(t, &BackupFooter{
+			Branches:      1,
+			Collections:   1,
+			Documents:     2,
+			SearchIndexes: 1,
+			Caches:        1,
+			Channels:      1,
+		}, footer)
+
+		r := NewBackupReader(&buf)
+		header, err := r.Header()
+		require.NoError(t, err)
+		require.Equal(t, "p1", header.Project)
+		require.Equal(t, int64(1234), header.ReadVersion)
+
+		for _, exp := range records {
+			actual, err := r.Next()
+			require.NoError(t, err)
+			require.Equal(t, exp.Type, actual.Type)
+			require.Equal(t, exp.Name, actual.Name)
+			require.Equal(t, exp.Branch, actual.Branch)
+			require.Equal(t, exp.Collection, actual.Collection)
+			require.JSONEq(t, string(orEmpty(exp.Schema)), string(orEmpty(actual.Schema)))
+			require.JSONEq(t, string(orEmpty(exp.Document)), string(orEmpty(actual.Document)))
+			require.Equal(t, exp.CreatedAt, actual.CreatedAt)
+			require.Equal(t, exp.UpdatedAt, actual.UpdatedAt)
+		}
+
+		_, err = r.Next()
+		require.Equal(t, io.EOF, err)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewBackupWriter(&buf)
+		require.NoError(t, w.Write(&BackupRecord{
+			Type:   BackupHeaderRecord,
+			Header: &BackupHeader{FormatVersion: BackupFormatVersion, Project: "p1"},
+		}))
+		require.NoError(t, w.Write(records[0]))
+		require.NoError(t, w.w.Flush())
+
+		r := NewBackupReader(&buf)
+		_, err := r.Next()
+		require.NoError(t, err)
+		_, err = r.Next()
+		require.ErrorContains(t, err, "truncated")
+	})
+
+	t.Run("unsupported_version", func(t *testing.T) {
+		r := NewBackupReader(bytes.NewReader([]byte(`{"type":"header","header":{"format_version":100}}` + "\n")))
+		_, err := r.Header()
+		require.ErrorContains(t, err, "unsupported backup format version")
+	})
+
+	t.Run("no_header", func(t *testing.T) {
+		r := NewBackupReader(bytes.NewReader([]byte(`{"type":"cache","name":"c1"}` + "\n")))
+		_, err := r.Next()
+		require.ErrorContains(t, err, "doesn't start with a header")
+	})
+}
+
+// restoreTestSession records the records applied by the restore instead of executing them.
+type restoreTestSession struct {
+	Session
+
+	applied []*BackupRecord
+}
+
+func (s *restoreTestSession) Execute(_ context.Context, runner QueryRunner, _ ReqOptions) (Response, error) {
+	s.applied = append(s.applied, runner.(*RestoreRunner).records...)
+	return Response{Status: OkStatus}, nil
+}
+
+func TestRestore(t *testing.T) {
+	archive := func(footer *BackupFooter, records ...*BackupRecord) io.Reader {
+		var buf bytes.Buffer
+		w := NewBackupWriter(&buf)
+		require.NoError(t, w.Write(&BackupRecord{
+			Type:   BackupHeaderRecord,
+			Header: &BackupHeader{FormatVersion: BackupFormatVersion, Project: "p1", Consistency: BackupChunkConsistency},
+		}))
+		for _, r := range records {
+			require.NoError(t, w.Write(r))
+		}
+		if footer != nil {
+			require.NoError(t, w.Write(&BackupRecord{Type: BackupFooterRecord, Footer: footer}))
+		}
+		require.NoError(t, w.w.Flush())
+
+		return &buf
+	}
+
+	records := []*BackupRecord{
+		{Type: BackupCollectionRecord, Collection: "users", Schema: []byte(`{"title":"users"}`)},
+		{Type: BackupDocumentRecord, Collection: "users", Document: []byte(`{"id":1}`)},
+		{Type: BackupDocumentRecord, Collection: "users", Document: []byte(`{"id":2}`)},
+		{Type: BackupCacheRecord, Name: "c1"},
+	}
+	expected := &BackupFooter{Collections: 1, Documents: 2, Caches: 1}
+
+	t.Run("complete", func(t *testing.T) {
+		sessions := &restoreTestSession{}
+		footer, err := NewRestorer(sessions, &QueryRunnerFactory{}, nil).Restore(context.TODO(), "", archive(expected, records...), nil)
+		require.NoError(t, err)
+		require.Equal(t, expected, footer)
+		require.Len(t, sessions.applied, len(records)+1)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		sessions := &restoreTestSession{}
+		_, err := NewRestorer(sessions, &QueryRunnerFactory{}, nil).Restore(context.TODO(), "", archive(nil, records...), nil)
+		require.Equal(t, errors.InvalidArgument("backup archive is truncated, footer is missing"), err)
+		// the records read before the end of the archive are applied
+		require.NotEmpty(t, sessions.applied)
+	})
+
+	t.Run("mismatched_counts", func(t *testing.T) {
+		sessions := &restoreTestSession{}
+		_, err := NewRestorer(sessions, &QueryRunnerFactory{}, nil).Restore(context.TODO(), "",
+			archive(&BackupFooter{Collections: 1, Documents: 3, Caches: 1}, records...), nil)
+		require.Equal(t, errors.InvalidArgument("backup archive doesn't match its footer, restored "+
+			"0 branches, 1 collections, 2 documents, 0 search indexes, 1 caches and 0 channels instead of "+
+			"0 branches, 1 collections, 3 documents, 0 search indexes, 1 caches and 0 channels"), err)
+	})
+
+	t.Run("empty_footer", func(t *testing.T) {
+		r := NewBackupReader(archive(nil, &BackupRecord{Type: BackupFooterRecord}))
+		_, err := r.Next()
+		require.ErrorContains(t, err, "footer is empty")
+	})
+}
+
+func orEmpty(b []byte) []byte {
+	if len(b) == 0 {
+		return []byte(`null`)
+	}
+	return b
+}
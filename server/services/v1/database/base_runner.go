@@ -29,6 +29,7 @@ import (
 	"github.com/tigrisdata/tigris/server/config"
 	"github.com/tigrisdata/tigris/server/metadata"
 	"github.com/tigrisdata/tigris/server/metrics"
+	"github.com/tigrisdata/tigris/server/outbox"
 	"github.com/tigrisdata/tigris/server/request"
 	"github.com/tigrisdata/tigris/server/transaction"
 	"github.com/tigrisdata/tigris/server/types"
@@ -238,6 +239,33 @@ func (*BaseQueryRunner) mustBeDocumentsCollection(collection *schema.DefaultColl
 	return nil
 }
 
+// enqueuePublish adds the realtime message of the write request to the outbox of the transaction, so that it is only
+// published if the transaction commits.
+func (*BaseQueryRunner) enqueuePublish(ctx context.Context, tenant *metadata.Tenant, projName string, publish *api.PublishOptions) (context.Context, error) {
+	if publish == nil {
+		return ctx, nil
+	}
+	if !config.DefaultConfig.Outbox.Enabled {
+		return ctx, errors.InvalidArgument("publishing from transactions is not enabled")
+	}
+	if len(publish.Channel) == 0 {
+		return ctx, errors.InvalidArgument("channel is required to publish a message")
+	}
+
+	project, err := tenant.GetProject(projName)
+	if err != nil {
+		return ctx, err
+	}
+
+	return outbox.Enqueue(ctx, &outbox.Message{
+		TenantId:  tenant.GetNamespace().Id(),
+		ProjectId: project.Id(),
+		Channel:   publish.Channel,
+		Name:      publish.Name,
+		Data:      publish.Data,
+	}), nil
+}
+
 func (*BaseQueryRunner) getSearchOrdering(coll *schema.DefaultCollection, sortReq jsoniter.RawMessage) (*sort.Ordering, error) {
 	ordering, err := sort.UnmarshalSort(sortReq)
 	if err != nil || ordering == nil {
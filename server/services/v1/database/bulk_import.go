@@ -0,0 +1,278 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/schema"
+)
+
+const (
+	ImportFormatJSONL = "jsonl"
+	ImportFormatCSV   = "csv"
+)
+
+// maxImportRowSize is the longest line accepted in the JSON Lines format.
+const maxImportRowSize = 16 * 1024 * 1024
+
+// importRow is a single row of the input. Row numbers start at one and don't count the CSV header, they are used
+// as the resume token and to report the rows that failed to import.
+type importRow struct {
+	num    int64
+	record []string
+	doc    []byte
+}
+
+// importDecoder splits the input in rows and converts them to JSON documents. The rows are decoded first and
+// converted later because the CSV conversion depends on the schema which may only be known after the schema is
+// inferred from the first rows.
+type importDecoder interface {
+	Next() (*importRow, error)
+	// SetFields sets the schema fields used to convert the rows. Until it is called, the types of the CSV values are
+	// guessed, which is how the rows of the sample used to infer the schema are converted.
+	SetFields(fields []*schema.Field)
+	// Document converts the row to a JSON document.
+	Document(row *importRow) ([]byte, error)
+}
+
+func newImportDecoder(format string, r io.Reader, delimiter string, mapping map[string]string) (importDecoder, error) {
+	switch strings.ToLower(format) {
+	case "", ImportFormatJSONL:
+		return newJSONLDecoder(r), nil
+	case ImportFormatCSV:
+		return newCSVDecoder(r, delimiter, mapping)
+	default:
+		return nil, errors.InvalidArgument("unsupported import format '%s'", format)
+	}
+}
+
+type jsonlDecoder struct {
+	scanner *bufio.Scanner
+	num     int64
+}
+
+func newJSONLDecoder(r io.Reader) *jsonlDecoder {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportRowSize)
+
+	return &jsonlDecoder{scanner: scanner}
+}
+
+func (d *jsonlDecoder) Next() (*importRow, error) {
+	for d.scanner.Scan() {
+		d.num++
+
+		line := bytes.TrimSpace(d.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		doc := make([]byte, len(line))
+		copy(doc, line)
+
+		return &importRow{num: d.num, doc: doc}, nil
+	}
+
+	if err := d.scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+func (*jsonlDecoder) SetFields([]*schema.Field) {}
+
+func (*jsonlDecoder) Document(row *importRow) ([]byte, error) {
+	if !jsoniter.Valid(row.doc) {
+		return nil, errors.InvalidArgument("row is not a valid JSON document")
+	}
+
+	return row.doc, nil
+}
+
+// csvDecoder reads CSV with a header row. Every column is mapped to a field, the header name is used as the field
+// unless the mapping has an entry for it, a mapping to an empty name drops the column. Dotted field names are
+// written as nested objects.
+type csvDecoder struct {
+	reader  *csv.Reader
+	columns [][]string
+	types   map[string]schema.FieldType
+	num     int64
+}
+
+func newCSVDecoder(r io.Reader, delimiter string, mapping map[string]string) (*csvDecoder, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	if len(delimiter) > 0 {
+		d, size := utf8.DecodeRuneInString(delimiter)
+		if size != len(delimiter) {
+			return nil, errors.InvalidArgument("delimiter must be a single character")
+		}
+		reader.Comma = d
+	}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.InvalidArgument("csv header is missing")
+	}
+	if err != nil {
+		return nil, errors.InvalidArgument("invalid csv header: %s", err.Error())
+	}
+
+	columns := make([][]string, len(header))
+	for i, h := range header {
+		name := strings.TrimSpace(h)
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		if mapped, ok := mapping[name]; ok {
+			name = mapped
+		}
+		if len(name) > 0 {
+			columns[i] = strings.Split(name, ".")
+		}
+	}
+
+	return &csvDecoder{
+		reader:  reader,
+		columns: columns,
+	}, nil
+}
+
+func (d *csvDecoder) Next() (*importRow, error) {
+	record, err := d.reader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+
+	d.num++
+	if err != nil {
+		// a malformed row is reported as a row error, the reader continues with the next line.
+		return &importRow{num: d.num}, nil
+	}
+
+	return &importRow{num: d.num, record: record}, nil
+}
+
+func (d *csvDecoder) SetFields(fields []*schema.Field) {
+	d.types = make(map[string]schema.FieldType)
+	for _, c := range exportColumns(fields, nil) {
+		d.types[c.name] = c.dataType
+	}
+}
+
+func (d *csvDecoder) Document(row *importRow) ([]byte, error) {
+	if row.record == nil {
+		return nil, errors.InvalidArgument("malformed csv row")
+	}
+	if len(row.record) > len(d.columns) {
+		return nil, errors.InvalidArgument("row has '%d' columns, header has '%d'", len(row.record), len(d.columns))
+	}
+
+	doc := make(map[string]any)
+	for i, cell := range row.record {
+		path := d.columns[i]
+		if path == nil || len(cell) == 0 {
+			continue
+		}
+
+		name := strings.Join(path, ".")
+		dataType, known := d.types[name]
+		v, err := coerceCSVValue(cell, dataType, known)
+		if err != nil {
+			return nil, errors.InvalidArgument("column '%s': %s", name, err.Error())
+		}
+
+		setPath(doc, path, v)
+	}
+
+	return jsoniter.Marshal(doc)
+}
+
+// coerceCSVValue converts the cell to the type of the field. Cells of unknown fields are converted to the narrowest
+// type that can represent them so that the inferred schema is the same as for the equivalent JSON document.
+func coerceCSVValue(cell string, dataType schema.FieldType, known bool) (any, error) {
+	if !known {
+		return guessCSVValue(cell), nil
+	}
+
+	switch dataType {
+	case schema.Int32Type, schema.Int64Type:
+		i, err := strconv.ParseInt(strings.TrimSpace(cell), 10, 64)
+		if err != nil {
+			return nil, errors.InvalidArgument("'%s' is not an integer", cell)
+		}
+		return i, nil
+	case schema.DoubleType:
+		f, err := strconv.ParseFloat(strings.TrimSpace(cell), 64)
+		if err != nil {
+			return nil, errors.InvalidArgument("'%s' is not a number", cell)
+		}
+		return f, nil
+	case schema.BoolType:
+		b, err := strconv.ParseBool(strings.TrimSpace(cell))
+		if err != nil {
+			return nil, errors.InvalidArgument("'%s' is not a boolean", cell)
+		}
+		return b, nil
+	case schema.ArrayType, schema.ObjectType, schema.VectorType:
+		if !jsoniter.Valid([]byte(cell)) {
+			return nil, errors.InvalidArgument("'%s' is not valid JSON", cell)
+		}
+		return jsoniter.RawMessage(cell), nil
+	default:
+		return cell, nil
+	}
+}
+
+func guessCSVValue(cell string) any {
+	trimmed := strings.TrimSpace(cell)
+	if i, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(trimmed, 64); err == nil {
+		return f
+	}
+	if trimmed == "true" || trimmed == "false" {
+		return trimmed == "true"
+	}
+	if (strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{")) && jsoniter.Valid([]byte(trimmed)) {
+		return jsoniter.RawMessage(trimmed)
+	}
+
+	return cell
+}
+
+func setPath(doc map[string]any, path []string, v any) {
+	for _, p := range path[:len(path)-1] {
+		nested, ok := doc[p].(map[string]any)
+		if !ok {
+			nested = make(map[string]any)
+			doc[p] = nested
+		}
+		doc = nested
+	}
+
+	doc[path[len(path)-1]] = v
+}
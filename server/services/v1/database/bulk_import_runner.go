@@ -0,0 +1,395 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"io"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog/log"
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/schema"
+	cschema "github.com/tigrisdata/tigris/schema/lang"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/metrics"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/server/types"
+	"github.com/tigrisdata/tigris/store/kv"
+)
+
+const (
+	// defaultImportSampleSize is the number of rows used to infer or evolve the schema.
+	defaultImportSampleSize = 100
+	// defaultImportBatchSize is the maximum number of rows written in a single transaction.
+	defaultImportBatchSize = 256
+	// maxImportBatchBytes keeps a batch well under the transaction size limit of FDB.
+	maxImportBatchBytes = 4 * 1024 * 1024
+)
+
+type BulkImportOptions struct {
+	Project    string
+	Branch     string
+	Collection string
+	Format     string
+	// Delimiter is the CSV field delimiter, comma by default.
+	Delimiter string
+	// HeaderMapping maps CSV header names to field names.
+	HeaderMapping    map[string]string
+	CreateCollection bool
+	EvolveSchema     bool
+	PrimaryKey       []string
+	Autogenerated    []string
+	// Replace overwrites existing documents instead of reporting them as failed rows.
+	Replace bool
+	// SkipRows is the resume token of a previous import, the rows up to and including it are skipped.
+	SkipRows int64
+}
+
+type ImportRowError struct {
+	Row   int64
+	Error string
+}
+
+type BulkImportProgress struct {
+	RowsRead     int64
+	RowsImported int64
+	RowsFailed   int64
+	// ResumeToken is the last row that is either committed or reported as failed.
+	ResumeToken int64
+	// Errors are the rows that failed in the batch this progress is reported for.
+	Errors []*ImportRowError
+}
+
+// BulkImportSchemaRunner makes sure the collection exists before the rows are written. It creates the collection
+// from the schema inferred from the sample if it doesn't exist, or adds the new fields of the sample to the schema
+// if the schema is allowed to evolve.
+type BulkImportSchemaRunner struct {
+	*BaseQueryRunner
+
+	opts   *BulkImportOptions
+	sample [][]byte
+	fields []*schema.Field
+}
+
+// Fields returns the fields of the collection once the runner is executed.
+func (runner *BulkImportSchemaRunner) Fields() []*schema.Field {
+	return runner.fields
+}
+
+func (runner *BulkImportSchemaRunner) Run(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant) (Response, context.Context, error) {
+	opts := runner.opts
+
+	db, err := runner.getDatabase(ctx, tx, tenant, opts.Project, opts.Branch)
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	var sch cschema.Schema
+	primaryKey := opts.PrimaryKey
+	coll, err := runner.getCollection(db, opts.Collection)
+	switch {
+	case err == nil && (!opts.EvolveSchema || len(runner.sample) == 0):
+		runner.fields = coll.Fields
+		tx.Context().MarkNoMetadataStateChanged()
+		return Response{Status: OkStatus}, ctx, nil
+	case err == nil:
+		if err = jsoniter.Unmarshal(coll.Schema, &sch); err != nil {
+			return Response{}, ctx, err
+		}
+		// primary key of an existing collection can't change
+		primaryKey = nil
+	case !opts.CreateCollection:
+		return Response{}, ctx, err
+	case len(runner.sample) == 0:
+		return Response{}, ctx, errors.InvalidArgument("no valid rows to infer the schema of collection '%s'", opts.Collection)
+	}
+
+	if err = schema.Infer(&sch, opts.Collection, runner.sample, primaryKey, opts.Autogenerated, 0); err != nil {
+		return Response{}, ctx, err
+	}
+
+	raw, err := jsoniter.Marshal(&sch)
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	log.Debug().Str("collection", opts.Collection).Str("schema", string(raw)).Msg("bulk import schema")
+
+	schFactory, err := schema.NewFactoryBuilder(true).Build(opts.Collection, raw)
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	if tx.Context().GetStagedDatabase() == nil {
+		// do not modify the actual database object yet, just work on the clone
+		db = db.Clone()
+		tx.Context().StageDatabase(db)
+	}
+
+	if err = metadata.UpdateSchemaVersion(ctx, tenant.MetaStore, tx, tenant.GetNamespace().Id(), db, schFactory); err != nil {
+		return Response{}, ctx, err
+	}
+
+	if err = tenant.CreateCollection(ctx, tx, db, schFactory); err != nil {
+		if err == kv.ErrDuplicateKey {
+			// this simply means, concurrently CreateCollection is called,
+			return Response{}, ctx, errors.Aborted("concurrent create collection request, aborting")
+		}
+		return Response{}, ctx, err
+	}
+
+	runner.fields = schFactory.Fields
+
+	return Response{Status: OkStatus}, ctx, nil
+}
+
+// BulkImportRunner writes a batch of rows. A row that is rejected, because it doesn't match the schema or its key
+// already exists, is reported as a row error and the rest of the batch is still written.
+type BulkImportRunner struct {
+	*BaseQueryRunner
+
+	opts         *BulkImportOptions
+	rows         []int64
+	docs         [][]byte
+	queryMetrics *metrics.WriteQueryMetrics
+	rowErrors    []*ImportRowError
+	imported     int64
+}
+
+func (runner *BulkImportRunner) Run(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant) (Response, context.Context, error) {
+	// the session may run it again on a retryable error, the result is only of the last run.
+	runner.rowErrors = nil
+	runner.imported = 0
+
+	db, coll, err := runner.getDBAndCollection(ctx, tx, tenant, runner.opts.Project, runner.opts.Collection, runner.opts.Branch)
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	ctx = runner.cdcMgr.WrapContext(ctx, db.Name())
+
+	if err = runner.mustBeDocumentsCollection(coll, "insert"); err != nil {
+		return Response{}, ctx, err
+	}
+
+	for i, doc := range runner.docs {
+		_, _, err = runner.insertOrReplace(ctx, tx, tenant, coll, [][]byte{doc}, !runner.opts.Replace)
+		if err == nil {
+			runner.imported++
+			continue
+		}
+
+		if !isImportRowError(err) {
+			return Response{}, ctx, err
+		}
+		runner.rowErrors = append(runner.rowErrors, &ImportRowError{Row: runner.rows[i], Error: importRowErrorMessage(err)})
+	}
+
+	runner.queryMetrics.SetWriteType("bulk_import")
+	metrics.UpdateSpanTags(ctx, runner.queryMetrics)
+
+	return Response{Status: InsertedStatus}, ctx, nil
+}
+
+func isImportRowError(err error) bool {
+	if err == kv.ErrDuplicateKey {
+		return true
+	}
+
+	//nolint:errorlint
+	ep, ok := err.(*api.TigrisError)
+	return ok && (ep.Code == api.Code_INVALID_ARGUMENT || ep.Code == api.Code_ALREADY_EXISTS)
+}
+
+func importRowErrorMessage(err error) string {
+	if err == kv.ErrDuplicateKey {
+		return "duplicate key"
+	}
+
+	//nolint:errorlint
+	if ep, ok := err.(*api.TigrisError); ok {
+		return ep.Message
+	}
+
+	return err.Error()
+}
+
+// BulkImporter loads a CSV or JSON Lines stream in a collection. The schema is prepared from a sample of the first
+// rows and then the rows are written in batches, every batch in its own transaction. After every batch the progress
+// is reported with the resume token, an interrupted import continues from the last reported token.
+type BulkImporter struct {
+	sessions   Session
+	factory    *QueryRunnerFactory
+	sampleSize int
+	batchSize  int
+}
+
+func NewBulkImporter(sessions Session, factory *QueryRunnerFactory) *BulkImporter {
+	return &BulkImporter{
+		sessions:   sessions,
+		factory:    factory,
+		sampleSize: defaultImportSampleSize,
+		batchSize:  defaultImportBatchSize,
+	}
+}
+
+type importBatch struct {
+	rows    []int64
+	docs    [][]byte
+	size    int
+	lastRow int64
+	errors  []*ImportRowError
+}
+
+func (b *importBatch) empty() bool {
+	return b.lastRow == 0
+}
+
+// Import returns the total progress. On error, the returned progress is what is committed before the failure.
+func (im *BulkImporter) Import(ctx context.Context, opts *BulkImportOptions, r io.Reader, accessToken *types.AccessToken,
+	progress func(*BulkImportProgress) error,
+) (*BulkImportProgress, error) {
+	total := &BulkImportProgress{ResumeToken: opts.SkipRows}
+
+	decoder, err := newImportDecoder(opts.Format, r, opts.Delimiter, opts.HeaderMapping)
+	if err != nil {
+		return total, err
+	}
+
+	// the sample rows are converted with the guessed types, they are converted again once the schema is known.
+	var pending []*importRow
+	for len(pending) < im.sampleSize {
+		row, err := im.next(decoder, opts)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return total, err
+		}
+		pending = append(pending, row)
+	}
+
+	var sample [][]byte
+	for _, row := range pending {
+		if doc, err := decoder.Document(row); err == nil {
+			sample = append(sample, doc)
+		}
+	}
+
+	schemaRunner := im.factory.GetBulkImportSchemaRunner(opts, sample, accessToken)
+	if _, err = im.sessions.Execute(ctx, schemaRunner, ReqOptions{
+		MetadataChange:     true,
+		InstantVerTracking: true,
+	}); err != nil {
+		return total, err
+	}
+	decoder.SetFields(schemaRunner.Fields())
+
+	batch := &importBatch{}
+	add := func(row *importRow) error {
+		total.RowsRead++
+		batch.lastRow = row.num
+
+		doc, err := decoder.Document(row)
+		if err != nil {
+			batch.errors = append(batch.errors, &ImportRowError{Row: row.num, Error: importRowErrorMessage(err)})
+		} else {
+			batch.rows = append(batch.rows, row.num)
+			batch.docs = append(batch.docs, doc)
+			batch.size += len(doc)
+		}
+
+		if len(batch.docs) >= im.batchSize || batch.size >= maxImportBatchBytes {
+			return im.flush(ctx, opts, batch, total, accessToken, progress)
+		}
+		return nil
+	}
+
+	for _, row := range pending {
+		if err = add(row); err != nil {
+			return total, err
+		}
+	}
+
+	for {
+		row, err := im.next(decoder, opts)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return total, err
+		}
+		if err = add(row); err != nil {
+			return total, err
+		}
+	}
+
+	if err = im.flush(ctx, opts, batch, total, accessToken, progress); err != nil {
+		return total, err
+	}
+
+	log.Info().Str("project", opts.Project).Str("collection", opts.Collection).Int64("imported", total.RowsImported).
+		Int64("failed", total.RowsFailed).Msg("bulk import completed")
+
+	return total, nil
+}
+
+func (*BulkImporter) next(decoder importDecoder, opts *BulkImportOptions) (*importRow, error) {
+	for {
+		row, err := decoder.Next()
+		if err != nil {
+			return nil, err
+		}
+		if row.num > opts.SkipRows {
+			return row, nil
+		}
+	}
+}
+
+func (im *BulkImporter) flush(ctx context.Context, opts *BulkImportOptions, batch *importBatch, total *BulkImportProgress,
+	accessToken *types.AccessToken, progress func(*BulkImportProgress) error,
+) error {
+	if batch.empty() {
+		return nil
+	}
+
+	rowErrors := batch.errors
+	if len(batch.docs) > 0 {
+		runner := im.factory.GetBulkImportRunner(opts, batch.rows, batch.docs, &metrics.WriteQueryMetrics{}, accessToken)
+		if _, err := im.sessions.Execute(ctx, runner, ReqOptions{}); err != nil {
+			return err
+		}
+		total.RowsImported += runner.imported
+		rowErrors = append(rowErrors, runner.rowErrors...)
+	}
+
+	total.RowsFailed += int64(len(rowErrors))
+	total.ResumeToken = batch.lastRow
+	*batch = importBatch{}
+
+	if progress == nil {
+		return nil
+	}
+
+	return progress(&BulkImportProgress{
+		RowsRead:     total.RowsRead,
+		RowsImported: total.RowsImported,
+		RowsFailed:   total.RowsFailed,
+		ResumeToken:  total.ResumeToken,
+		Errors:       rowErrors,
+	})
+}
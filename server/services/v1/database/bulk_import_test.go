@@ -0,0 +1,127 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/schema"
+)
+
+func readImportRows(t *testing.T, decoder importDecoder) []*importRow {
+	var rows []*importRow
+	for {
+		row, err := decoder.Next()
+		if err == io.EOF {
+			return rows
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+}
+
+func TestImportDecoder(t *testing.T) {
+	t.Run("jsonl", func(t *testing.T) {
+		decoder, err := newImportDecoder("", strings.NewReader("{\"a\":1}\n\n  {\"b\":2}  \n{bad\n"), "", nil)
+		require.NoError(t, err)
+
+		rows := readImportRows(t, decoder)
+		require.Len(t, rows, 3)
+		require.Equal(t, []int64{1, 3, 4}, []int64{rows[0].num, rows[1].num, rows[2].num})
+
+		doc, err := decoder.Document(rows[1])
+		require.NoError(t, err)
+		require.JSONEq(t, `{"b":2}`, string(doc))
+
+		_, err = decoder.Document(rows[2])
+		require.Error(t, err)
+	})
+
+	t.Run("csv_guess", func(t *testing.T) {
+		input := "\ufeffid,name,addr.city,addr.zip,active,price,tags,skip\n" +
+			"1,a,sf,94107,true,1.5,\"[1,2]\",x\n" +
+			"2,b,,,false,,,\n"
+		decoder, err := newImportDecoder("CSV", strings.NewReader(input), "", map[string]string{"skip": ""})
+		require.NoError(t, err)
+
+		rows := readImportRows(t, decoder)
+		require.Len(t, rows, 2)
+
+		doc, err := decoder.Document(rows[0])
+		require.NoError(t, err)
+		require.JSONEq(t, `{"id":1,"name":"a","addr":{"city":"sf","zip":94107},"active":true,"price":1.5,"tags":[1,2]}`, string(doc))
+
+		doc, err = decoder.Document(rows[1])
+		require.NoError(t, err)
+		require.JSONEq(t, `{"id":2,"name":"b","active":false}`, string(doc))
+	})
+
+	t.Run("csv_fields", func(t *testing.T) {
+		input := "key;zip;flag\n007;94107;1\n008;x;true\n"
+		decoder, err := newImportDecoder(ImportFormatCSV, strings.NewReader(input), ";", map[string]string{
+			"key": "id",
+			"zip": "address.zip",
+		})
+		require.NoError(t, err)
+		decoder.SetFields([]*schema.Field{
+			{FieldName: "id", DataType: schema.StringType},
+			{FieldName: "address", DataType: schema.ObjectType, Fields: []*schema.Field{
+				{FieldName: "zip", DataType: schema.Int32Type},
+			}},
+			{FieldName: "flag", DataType: schema.BoolType},
+		})
+
+		rows := readImportRows(t, decoder)
+		require.Len(t, rows, 2)
+
+		doc, err := decoder.Document(rows[0])
+		require.NoError(t, err)
+		require.JSONEq(t, `{"id":"007","address":{"zip":94107},"flag":true}`, string(doc))
+
+		_, err = decoder.Document(rows[1])
+		require.ErrorContains(t, err, "address.zip")
+	})
+
+	t.Run("csv_malformed", func(t *testing.T) {
+		decoder, err := newImportDecoder(ImportFormatCSV, strings.NewReader("a,b\n1,\"x\n"), "", nil)
+		require.NoError(t, err)
+
+		rows := readImportRows(t, decoder)
+		require.Len(t, rows, 1)
+		_, err = decoder.Document(rows[0])
+		require.ErrorContains(t, err, "malformed")
+
+		decoder, err = newImportDecoder(ImportFormatCSV, strings.NewReader("a\n1,2\n"), "", nil)
+		require.NoError(t, err)
+		rows = readImportRows(t, decoder)
+		require.Len(t, rows, 1)
+		_, err = decoder.Document(rows[0])
+		require.Error(t, err)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := newImportDecoder("xml", strings.NewReader(""), "", nil)
+		require.Error(t, err)
+
+		_, err = newImportDecoder(ImportFormatCSV, strings.NewReader(""), "", nil)
+		require.Error(t, err)
+
+		_, err = newImportDecoder(ImportFormatCSV, strings.NewReader("a\n"), ";;", nil)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,205 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"encoding/hex"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/keys"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/metrics"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/store/cache"
+	"github.com/tigrisdata/tigris/store/kv"
+	ulog "github.com/tigrisdata/tigris/util/log"
+)
+
+// documentCacheTable is the table of the cache store holding the cached documents. The keys are derived from the
+// encoded table and primary key of the documents so the collections don't need a table of their own.
+const documentCacheTable = "document_cache"
+
+// documentCacheTombstoneTTL is how long an invalidated document can't be cached by a read. It outlives the
+// transactions, which FDB limits to five seconds, so a read which started before the commit can't cache the
+// document it has read once the document is invalidated.
+const documentCacheTombstoneTTL = 10 * time.Second
+
+// DocumentCache caches the documents of the collections having a cache policy. The point reads by primary key read
+// through it and, as a TxListener, it invalidates the cached documents once the transactions changing them are
+// committed. An invalidated document is replaced by a short-lived tombstone and the reads only cache the documents
+// missing from the cache, so a read racing with a commit can't cache the document the commit has changed.
+type DocumentCache struct {
+	store     cache.Cache
+	tenantMgr *metadata.TenantManager
+}
+
+func NewDocumentCache(store cache.Cache, tenantMgr *metadata.TenantManager) *DocumentCache {
+	return &DocumentCache{
+		store:     store,
+		tenantMgr: tenantMgr,
+	}
+}
+
+func documentCacheKey(table []byte, indexParts ...any) string {
+	return hex.EncodeToString(keys.NewKey(table, indexParts...).SerializeToBytes())
+}
+
+// eventKey returns the key of the document changed by the transaction event.
+func eventKey(event *kv.Event) keys.Key {
+	parts := make([]any, 0, len(event.Key))
+	for _, p := range event.Key {
+		parts = append(parts, p)
+	}
+
+	return keys.NewKey(event.Table, parts...)
+}
+
+// Get returns the cached document, nil if the document is not cached or is invalidated.
+func (c *DocumentCache) Get(ctx context.Context, key keys.Key) (*internal.TableData, error) {
+	cached, err := c.store.Get(ctx, documentCacheTable, documentCacheKey(key.Table(), key.IndexParts()...), nil)
+	if err == cache.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(cached.RawData) == 0 {
+		// tombstone
+		return nil, nil
+	}
+
+	return internal.Decode(cached.RawData)
+}
+
+// Put caches the document read from the database with the expiry of the cache policy of the collection. The document
+// is only cached if the key is missing from the cache, so it doesn't replace a tombstone.
+func (c *DocumentCache) Put(ctx context.Context, policy *schema.CachePolicy, key keys.Key, data *internal.TableData) error {
+	enc, err := internal.Encode(data)
+	if err != nil {
+		return err
+	}
+
+	err = c.store.Set(ctx, documentCacheTable, documentCacheKey(key.Table(), key.IndexParts()...), internal.NewCacheData(enc),
+		&cache.SetOptions{NX: true, PX: uint64(policy.TTL().Milliseconds())})
+	if err == cache.ErrKeyAlreadyExists {
+		return nil
+	}
+
+	return err
+}
+
+// invalidate replaces the cached document with a tombstone.
+func (c *DocumentCache) invalidate(ctx context.Context, key keys.Key) error {
+	return c.store.Set(ctx, documentCacheTable, documentCacheKey(key.Table(), key.IndexParts()...), internal.NewCacheData(nil),
+		&cache.SetOptions{PX: uint64(documentCacheTombstoneTTL.Milliseconds())})
+}
+
+// OnPostCommit invalidates the cached documents changed by the transaction, the next read caches the committed
+// document once the tombstone expires. The committed document isn't written to the cache, the post commit hooks of
+// concurrent transactions run in any order and an older document could replace a newer one. The transaction is
+// already committed, so the failures are only logged, the documents then stay cached until they expire.
+func (c *DocumentCache) OnPostCommit(ctx context.Context, _ *metadata.Tenant, eventListener kv.EventListener) error {
+	for _, event := range eventListener.GetEvents() {
+		db, collName, ok := c.tenantMgr.DecodeTableName(event.Table)
+		if !ok {
+			continue
+		}
+
+		collection := db.GetCollection(collName)
+		if collection == nil || event.Key == nil || !collection.Cache.IsEnabled() {
+			// event.Key == nil if event comes from drop table
+			continue
+		}
+
+		if err := c.invalidate(ctx, eventKey(event)); err != nil {
+			log.Err(err).Str("collection", collName).Msg("invalidating cached document failed")
+		}
+	}
+
+	return nil
+}
+
+func (*DocumentCache) OnPreCommit(context.Context, *metadata.Tenant, transaction.Tx, kv.EventListener) error {
+	return nil
+}
+
+func (*DocumentCache) OnRollback(context.Context, *metadata.Tenant, kv.EventListener) {}
+
+// CachedKeyIterator serves the point reads by primary key from the document cache. The documents missing from the
+// cache are read from the database and cached. A failing cache store doesn't fail the read, the documents are then
+// read from the database.
+type CachedKeyIterator struct {
+	ctx      context.Context
+	tx       transaction.Tx
+	docCache *DocumentCache
+	coll     *schema.DefaultCollection
+	keys     []keys.Key
+	keyId    int
+	err      error
+	project  string
+	branch   string
+}
+
+func NewCachedKeyIterator(ctx context.Context, tx transaction.Tx, docCache *DocumentCache, coll *schema.DefaultCollection,
+	keys []keys.Key, project string, branch string,
+) *CachedKeyIterator {
+	return &CachedKeyIterator{
+		ctx:      ctx,
+		tx:       tx,
+		docCache: docCache,
+		coll:     coll,
+		keys:     keys,
+		project:  project,
+		branch:   branch,
+	}
+}
+
+func (it *CachedKeyIterator) Next(row *Row) bool {
+	for it.err == nil && it.keyId < len(it.keys) {
+		key := it.keys[it.keyId]
+		it.keyId++
+
+		data, err := it.docCache.Get(it.ctx, key)
+		if ulog.E(err) {
+			data = nil
+		}
+		if data != nil {
+			metrics.DocumentCacheHit(it.project, it.branch, it.coll.Name)
+			row.Key = key.SerializeToBytes()
+			row.Data = data
+			return true
+		}
+
+		metrics.DocumentCacheMiss(it.project, it.branch, it.coll.Name)
+
+		var iter *KeyIterator
+		if iter, it.err = NewKeyIterator(it.ctx, it.tx, []keys.Key{key}, false); it.err != nil {
+			return false
+		}
+		if iter.Next(row) {
+			ulog.E(it.docCache.Put(it.ctx, it.coll.Cache, key, row.Data))
+			return true
+		}
+		it.err = iter.Interrupted()
+	}
+
+	return false
+}
+
+func (it *CachedKeyIterator) Interrupted() error { return it.err }
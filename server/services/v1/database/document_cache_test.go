@@ -0,0 +1,87 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/keys"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/server/config"
+	"github.com/tigrisdata/tigris/store/cache"
+	"github.com/tigrisdata/tigris/store/kv"
+)
+
+func TestDocumentCache(t *testing.T) {
+	ctx := context.TODO()
+	store := cache.NewCache(&config.CacheConfig{Backend: config.CacheBackendMemory})
+	docCache := NewDocumentCache(store, nil)
+
+	table := []byte("t1")
+	key := keys.NewKey(table, schema.PrimaryKeyIndexName, int64(10))
+
+	data, err := docCache.Get(ctx, key)
+	require.NoError(t, err)
+	require.Nil(t, data)
+
+	doc := internal.NewTableDataWithVersion([]byte(`{"id":10,"name":"foo"}`), 2)
+	require.NoError(t, docCache.Put(ctx, &schema.CachePolicy{Enabled: true, TTLMs: 60000}, key, doc))
+
+	// the keys of the transaction events address the same cached document
+	event := &kv.Event{Table: table, Key: kv.BuildKey(schema.PrimaryKeyIndexName, int64(10))}
+	data, err = docCache.Get(ctx, eventKey(event))
+	require.NoError(t, err)
+	require.Equal(t, doc.RawData, data.RawData)
+	require.Equal(t, doc.Ver, data.Ver)
+
+	ttl, err := store.TTL(ctx, documentCacheTable, documentCacheKey(table, key.IndexParts()...))
+	require.NoError(t, err)
+	require.True(t, ttl > 0 && ttl <= time.Minute)
+
+	data, err = docCache.Get(ctx, keys.NewKey(table, schema.PrimaryKeyIndexName, int64(11)))
+	require.NoError(t, err)
+	require.Nil(t, data)
+}
+
+func TestDocumentCacheInvalidate(t *testing.T) {
+	ctx := context.TODO()
+	store := cache.NewCache(&config.CacheConfig{Backend: config.CacheBackendMemory})
+	docCache := NewDocumentCache(store, nil)
+	policy := &schema.CachePolicy{Enabled: true, TTLMs: 60000}
+
+	key := keys.NewKey([]byte("t2"), schema.PrimaryKeyIndexName, int64(10))
+	stale := internal.NewTableDataWithVersion([]byte(`{"id":10,"name":"foo"}`), 1)
+
+	require.NoError(t, docCache.Put(ctx, policy, key, stale))
+	require.NoError(t, docCache.invalidate(ctx, key))
+
+	data, err := docCache.Get(ctx, key)
+	require.NoError(t, err)
+	require.Nil(t, data)
+
+	// a read which started before the commit can't cache the document it has read
+	require.NoError(t, docCache.Put(ctx, policy, key, stale))
+	data, err = docCache.Get(ctx, key)
+	require.NoError(t, err)
+	require.Nil(t, data)
+
+	ttl, err := store.TTL(ctx, documentCacheTable, documentCacheKey(key.Table(), key.IndexParts()...))
+	require.NoError(t, err)
+	require.True(t, ttl > 0 && ttl <= documentCacheTombstoneTTL)
+}
@@ -0,0 +1,324 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/parquet-go/parquet-go"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/schema"
+)
+
+const (
+	ExportFormatJSONL   = "jsonl"
+	ExportFormatCSV     = "csv"
+	ExportFormatParquet = "parquet"
+)
+
+const (
+	// exportRowGroupSize bounds the number of rows the parquet writer keeps in memory before it writes a row group.
+	exportRowGroupSize = 64 * 1024
+	// exportRowBatchSize is the number of rows handed over to the parquet writer at once.
+	exportRowBatchSize = 1024
+)
+
+// ExportEncoder converts the documents of a collection to the export format. Documents are passed in the JSON form
+// they are stored in, already upgraded to the latest schema of the collection.
+type ExportEncoder interface {
+	ContentType() string
+	Encode(doc []byte) error
+	// Close writes the trailing part of the format, if any. It doesn't close the underlying writer.
+	Close() error
+}
+
+// NewExportEncoder returns the encoder for the format. An empty format defaults to JSON Lines. The columns of the CSV
+// and Parquet formats are derived from the schema fields, nested objects with known properties are flattened to one
+// column per leaf field using a dotted name.
+func NewExportEncoder(format string, w io.Writer, fields []*schema.Field) (ExportEncoder, error) {
+	switch strings.ToLower(format) {
+	case "", ExportFormatJSONL:
+		return &jsonlEncoder{w: w}, nil
+	case ExportFormatCSV:
+		return newCSVEncoder(w, exportColumns(fields, nil))
+	case ExportFormatParquet:
+		return newParquetEncoder(w, exportColumns(fields, nil)), nil
+	default:
+		return nil, errors.InvalidArgument("unsupported export format '%s'", format)
+	}
+}
+
+type exportColumn struct {
+	name     string
+	path     []string
+	dataType schema.FieldType
+}
+
+func exportColumns(fields []*schema.Field, parent []string) []*exportColumn {
+	var columns []*exportColumn
+	for _, f := range fields {
+		path := make([]string, len(parent), len(parent)+1)
+		copy(path, parent)
+		path = append(path, f.FieldName)
+
+		if f.DataType == schema.ObjectType && len(f.Fields) > 0 {
+			columns = append(columns, exportColumns(f.Fields, path)...)
+			continue
+		}
+
+		columns = append(columns, &exportColumn{
+			name:     strings.Join(path, "."),
+			path:     path,
+			dataType: f.DataType,
+		})
+	}
+
+	return columns
+}
+
+// value returns the raw value of the column in the document. The boolean is false if the field is missing or null.
+func (c *exportColumn) value(doc []byte) ([]byte, jsonparser.ValueType, bool) {
+	v, dt, _, err := jsonparser.Get(doc, c.path...)
+	if err != nil || dt == jsonparser.NotExist || dt == jsonparser.Null {
+		return nil, dt, false
+	}
+
+	return v, dt, true
+}
+
+type jsonlEncoder struct {
+	w io.Writer
+}
+
+func (*jsonlEncoder) ContentType() string {
+	return "application/x-ndjson"
+}
+
+func (e *jsonlEncoder) Encode(doc []byte) error {
+	if _, err := e.w.Write(doc); err != nil {
+		return err
+	}
+
+	_, err := e.w.Write([]byte{'\n'})
+	return err
+}
+
+func (*jsonlEncoder) Close() error {
+	return nil
+}
+
+// csvEncoder writes a header row with the column names followed by a row per document. Strings are written unquoted,
+// arrays and objects without known properties are written as JSON and missing values as empty cells.
+type csvEncoder struct {
+	w       *csv.Writer
+	columns []*exportColumn
+	record  []string
+}
+
+func newCSVEncoder(w io.Writer, columns []*exportColumn) (*csvEncoder, error) {
+	e := &csvEncoder{
+		w:       csv.NewWriter(w),
+		columns: columns,
+		record:  make([]string, len(columns)),
+	}
+
+	for i, c := range columns {
+		e.record[i] = c.name
+	}
+	if err := e.w.Write(e.record); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (*csvEncoder) ContentType() string {
+	return "text/csv"
+}
+
+func (e *csvEncoder) Encode(doc []byte) error {
+	for i, c := range e.columns {
+		v, dt, ok := c.value(doc)
+		switch {
+		case !ok:
+			e.record[i] = ""
+		case dt == jsonparser.String:
+			s, err := jsonparser.ParseString(v)
+			if err != nil {
+				return err
+			}
+			e.record[i] = s
+		default:
+			e.record[i] = string(v)
+		}
+	}
+
+	return e.w.Write(e.record)
+}
+
+func (e *csvEncoder) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// parquetEncoder writes every column as an optional top level leaf. Scalar types are mapped to the matching parquet
+// types, date-time to a nanosecond timestamp, and arrays, vectors and objects without known properties to JSON.
+type parquetEncoder struct {
+	w       *parquet.Writer
+	columns []*exportColumn
+	rows    []parquet.Row
+}
+
+func newParquetEncoder(w io.Writer, columns []*exportColumn) *parquetEncoder {
+	group := parquet.Group{}
+	byName := make(map[string]*exportColumn, len(columns))
+	for _, c := range columns {
+		group[c.name] = parquet.Optional(parquetNode(c.dataType))
+		byName[c.name] = c
+	}
+
+	sch := parquet.NewSchema("tigris", group)
+
+	// the leaf columns of a group are ordered by name, keep the columns in the same order as the schema.
+	ordered := make([]*exportColumn, 0, len(columns))
+	for _, path := range sch.Columns() {
+		ordered = append(ordered, byName[path[0]])
+	}
+
+	return &parquetEncoder{
+		w:       parquet.NewWriter(w, sch, parquet.MaxRowsPerRowGroup(exportRowGroupSize)),
+		columns: ordered,
+	}
+}
+
+func parquetNode(dataType schema.FieldType) parquet.Node {
+	switch dataType {
+	case schema.BoolType:
+		return parquet.Leaf(parquet.BooleanType)
+	case schema.Int32Type:
+		return parquet.Int(32)
+	case schema.Int64Type:
+		return parquet.Int(64)
+	case schema.DoubleType:
+		return parquet.Leaf(parquet.DoubleType)
+	case schema.StringType, schema.UUIDType:
+		return parquet.String()
+	case schema.ByteType:
+		return parquet.Leaf(parquet.ByteArrayType)
+	case schema.DateTimeType:
+		return parquet.Timestamp(parquet.Nanosecond)
+	default:
+		return parquet.JSON()
+	}
+}
+
+func (*parquetEncoder) ContentType() string {
+	return "application/vnd.apache.parquet"
+}
+
+func (e *parquetEncoder) Encode(doc []byte) error {
+	row := make(parquet.Row, len(e.columns))
+	for i, c := range e.columns {
+		v, dt, ok := c.value(doc)
+		if !ok {
+			row[i] = parquet.NullValue().Level(0, 0, i)
+			continue
+		}
+
+		pv, err := parquetValue(c, v, dt)
+		if err != nil {
+			return err
+		}
+		row[i] = pv.Level(0, 1, i)
+	}
+
+	e.rows = append(e.rows, row)
+	if len(e.rows) >= exportRowBatchSize {
+		return e.flushRows()
+	}
+
+	return nil
+}
+
+func (e *parquetEncoder) flushRows() error {
+	if len(e.rows) == 0 {
+		return nil
+	}
+
+	_, err := e.w.WriteRows(e.rows)
+	e.rows = e.rows[:0]
+	return err
+}
+
+func (e *parquetEncoder) Close() error {
+	if err := e.flushRows(); err != nil {
+		return err
+	}
+
+	return e.w.Close()
+}
+
+func parquetValue(c *exportColumn, v []byte, dt jsonparser.ValueType) (parquet.Value, error) {
+	switch c.dataType {
+	case schema.BoolType:
+		b, err := jsonparser.ParseBoolean(v)
+		return parquet.BooleanValue(b), err
+	case schema.Int32Type:
+		i, err := jsonparser.ParseInt(v)
+		return parquet.Int32Value(int32(i)), err
+	case schema.Int64Type:
+		i, err := jsonparser.ParseInt(v)
+		return parquet.Int64Value(i), err
+	case schema.DoubleType:
+		f, err := jsonparser.ParseFloat(v)
+		return parquet.DoubleValue(f), err
+	case schema.StringType, schema.UUIDType:
+		s, err := jsonparser.ParseString(v)
+		return parquet.ByteArrayValue([]byte(s)), err
+	case schema.ByteType:
+		s, err := jsonparser.ParseString(v)
+		if err != nil {
+			return parquet.Value{}, err
+		}
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return parquet.Value{}, errors.InvalidArgument("field '%s' is not base64 encoded", c.name)
+		}
+		return parquet.ByteArrayValue(b), nil
+	case schema.DateTimeType:
+		s, err := jsonparser.ParseString(v)
+		if err != nil {
+			return parquet.Value{}, err
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return parquet.Value{}, errors.InvalidArgument("field '%s' is not a valid date-time '%s'", c.name, s)
+		}
+		return parquet.Int64Value(t.UnixNano()), nil
+	default:
+		if dt == jsonparser.String {
+			// jsonparser strips the quotes of the strings, add them back to keep it a valid JSON value.
+			quoted := make([]byte, 0, len(v)+2)
+			quoted = append(append(append(quoted, '"'), v...), '"')
+			return parquet.ByteArrayValue(quoted), nil
+		}
+		return parquet.ByteArrayValue(v), nil
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"sort"
+	"strconv"
+
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/keys"
+	"github.com/tigrisdata/tigris/query/filter"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/metrics"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/store/kv"
+	ulog "github.com/tigrisdata/tigris/util/log"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	grpcMetadata "google.golang.org/grpc/metadata"
+)
+
+// exportChunkSize is the size of the buffer in front of the stream, every flush of it is one message on the wire.
+const exportChunkSize = 1024 * 1024
+
+// ExportQueryRunner streams a collection, or the documents matching the filter, in one of the export formats. Unlike
+// Read, the export is not bounded by the five-second transaction limit: when a transaction reaches it, the export is
+// resumed after the last key in a new transaction at the current version. The consistency is therefore per chunk, the
+// documents read by a transaction are a point-in-time snapshot but the writes committed while the export runs may or
+// may not be part of it. The read version of the first transaction is returned in the Tigris-Read-Version response
+// header, every document committed before it is part of the export unless it was deleted or updated since.
+type ExportQueryRunner struct {
+	*BaseQueryRunner
+
+	req          *api.ExportRequest
+	streaming    ExportStreaming
+	queryMetrics *metrics.StreamingQueryMetrics
+}
+
+type exportPlan struct {
+	filter *filter.WrappedFilter
+	// keys is set when the filter is on the primary key, sorted so that the documents are exported in key order.
+	keys []keys.Key
+}
+
+func (runner *ExportQueryRunner) ReadOnly(ctx context.Context, tenant *metadata.Tenant) (Response, context.Context, error) {
+	db, err := runner.getDatabase(ctx, nil, tenant, runner.req.GetProject(), runner.req.GetBranch())
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	ctx = runner.cdcMgr.WrapContext(ctx, db.Name())
+
+	coll, err := runner.getCollection(db, runner.req.GetCollection())
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	plan, err := runner.buildPlan(coll)
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	sw := &exportStreamWriter{streaming: runner.streaming}
+	out := bufio.NewWriterSize(sw, exportChunkSize)
+	encoder, err := NewExportEncoder(runner.req.GetFormat(), out, coll.Fields)
+	if err != nil {
+		return Response{}, ctx, err
+	}
+	sw.contentType = encoder.ContentType()
+
+	var readVersion int64
+	err = resumeScan("export", coll.Name, func(last []byte) ([]byte, error) {
+		// A new transaction is started after the last key whenever the previous one exhausted its duration.
+		tx, err := runner.txMgr.StartTx(ctx)
+		if err != nil {
+			return last, err
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		if readVersion == 0 {
+			if readVersion, err = tx.GetReadVersion(ctx); err != nil {
+				return last, err
+			}
+			if err = runner.streaming.SetHeader(grpcMetadata.Pairs(api.HeaderReadVersion, strconv.FormatInt(readVersion, 10))); err != nil {
+				return last, err
+			}
+		}
+
+		iter, err := runner.iterator(ctx, tx, coll, plan, last)
+		if err != nil || iter == nil {
+			return last, err
+		}
+
+		return readResumed(iter, last, func(row *Row) error {
+			return runner.encodeRow(coll, plan, encoder, row)
+		})
+	})
+	if err != nil {
+		return Response{}, ctx, CreateApiError(err)
+	}
+
+	if err = encoder.Close(); err != nil {
+		return Response{}, ctx, err
+	}
+	if err = out.Flush(); err != nil {
+		return Response{}, ctx, err
+	}
+
+	runner.queryMetrics.SetReadType("export")
+	runner.queryMetrics.SetSort(false)
+	ctx = metrics.UpdateSpanTags(ctx, runner.queryMetrics)
+
+	return Response{}, ctx, nil
+}
+
+func (runner *ExportQueryRunner) buildPlan(coll *schema.DefaultCollection) (*exportPlan, error) {
+	wrapped, err := filter.NewFactory(coll.QueryableFields, nil).WrappedFilter(runner.req.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &exportPlan{filter: wrapped}
+
+	planner, err := NewPrimaryIndexQueryPlanner(coll, runner.encoder, runner.req.GetFilter(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if planner.noFilter {
+		return plan, nil
+	}
+
+	if keyPlan, err := planner.GeneratePlan(nil, nil); err == nil && len(keyPlan.Keys) > 0 {
+		plan.keys = keyPlan.Keys
+		sort.Slice(plan.keys, func(i, j int) bool {
+			return bytes.Compare(plan.keys[i].SerializeToBytes(), plan.keys[j].SerializeToBytes()) < 0
+		})
+	}
+
+	return plan, nil
+}
+
+// resumeScan calls iterate until it isn't interrupted by the transaction limit, every call resumes after the key of
+// the last row read by the previous one. The scan is aborted if a transaction doesn't read any new row.
+func resumeScan(operation string, name string, iterate func(last []byte) ([]byte, error)) error {
+	var last []byte
+	for {
+		next, err := iterate(last)
+		if err != kv.ErrTransactionMaxDurationReached && err != kv.ErrTransactionTimedOut {
+			return err
+		}
+		if bytes.Equal(next, last) {
+			return errors.Aborted("%s of collection '%s' is not making progress", operation, name)
+		}
+		last = next
+	}
+}
+
+// iterator returns the iterator of the rows after the last key, nil if the filter is on the primary key and all its
+// keys are already read.
+func (runner *ExportQueryRunner) iterator(ctx context.Context, tx transaction.Tx, coll *schema.DefaultCollection, plan *exportPlan, last []byte) (Iterator, error) {
+	reader := NewDatabaseReader(ctx, tx)
+	switch {
+	case len(plan.keys) > 0:
+		var remaining []keys.Key
+		for _, k := range plan.keys {
+			if last == nil || k.CompareBytes(last) > 0 {
+				remaining = append(remaining, k)
+			}
+		}
+		if len(remaining) == 0 {
+			return nil, nil
+		}
+		return reader.KeyIterator(remaining)
+	case last != nil:
+		from, err := keys.FromBinary(coll.EncodedName, last)
+		if err != nil {
+			return nil, err
+		}
+		return reader.ScanIterator(from, nil, false)
+	default:
+		return reader.ScanTable(coll.EncodedName, false)
+	}
+}
+
+// readResumed passes the rows of the iterator to read and returns the key of the last row it has read, whether the
+// row was exported or not, so that a resumed iteration always makes progress. The scan resumes at the last key, so the
+// row having this key is skipped.
+func readResumed(iter Iterator, last []byte, read func(row *Row) error) ([]byte, error) {
+	var row Row
+	for iter.Next(&row) {
+		if last != nil && bytes.Equal(row.Key, last) {
+			// the resume key itself is already exported by the previous transaction
+			continue
+		}
+		last = row.Key
+
+		if err := read(&row); err != nil {
+			return last, err
+		}
+	}
+
+	return last, iter.Interrupted()
+}
+
+// encodeRow encodes the row if it matches the filter.
+func (*ExportQueryRunner) encodeRow(coll *schema.DefaultCollection, plan *exportPlan, encoder ExportEncoder, row *Row) error {
+	// the row is upgraded to the latest schema first, so that the filter matches the fields added since
+	rawData := row.Data.RawData
+	if !coll.CompatibleSchemaSince(uint32(row.Data.Ver)) {
+		var err error
+		if rawData, err = coll.UpdateRowSchemaRaw(rawData, uint32(row.Data.Ver)); err != nil {
+			return err
+		}
+	}
+
+	tsJSON, err := row.Data.TimeStampsToJSON()
+	if err != nil {
+		return err
+	}
+	if !plan.filter.Matches(rawData, tsJSON) {
+		return nil
+	}
+
+	if err = encoder.Encode(rawData); ulog.E(err) {
+		return err
+	}
+
+	return nil
+}
+
+// exportStreamWriter sends the encoded collection to the client, the HTTP gateway writes the body as is with the
+// content type of the format.
+type exportStreamWriter struct {
+	streaming   ExportStreaming
+	contentType string
+}
+
+func (w *exportStreamWriter) Write(p []byte) (int, error) {
+	data := make([]byte, len(p))
+	copy(data, p)
+
+	if err := w.streaming.Send(&httpbody.HttpBody{
+		ContentType: w.contentType,
+		Data:        data,
+	}); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
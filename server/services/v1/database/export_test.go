@@ -0,0 +1,194 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/store/kv"
+)
+
+var exportTestFields = []*schema.Field{
+	{FieldName: "id", DataType: schema.Int64Type},
+	{FieldName: "name", DataType: schema.StringType},
+	{FieldName: "active", DataType: schema.BoolType},
+	{FieldName: "price", DataType: schema.DoubleType},
+	{FieldName: "created", DataType: schema.DateTimeType},
+	{FieldName: "address", DataType: schema.ObjectType, Fields: []*schema.Field{
+		{FieldName: "city", DataType: schema.StringType},
+		{FieldName: "zip", DataType: schema.Int32Type},
+	}},
+	{FieldName: "tags", DataType: schema.ArrayType},
+}
+
+var exportTestDocs = [][]byte{
+	[]byte(`{"id":1,"name":"a, \"b\"","active":true,"price":1.5,"created":"2023-01-02T03:04:05Z","address":{"city":"sf","zip":94107},"tags":["x","y"]}`),
+	[]byte(`{"id":2,"name":"c"}`),
+}
+
+func TestExportEncoder(t *testing.T) {
+	t.Run("jsonl", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc, err := NewExportEncoder("", &buf, exportTestFields)
+		require.NoError(t, err)
+		require.Equal(t, "application/x-ndjson", enc.ContentType())
+		for _, doc := range exportTestDocs {
+			require.NoError(t, enc.Encode(doc))
+		}
+		require.NoError(t, enc.Close())
+		require.Equal(t, string(exportTestDocs[0])+"\n"+string(exportTestDocs[1])+"\n", buf.String())
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc, err := NewExportEncoder(ExportFormatCSV, &buf, exportTestFields)
+		require.NoError(t, err)
+		require.Equal(t, "text/csv", enc.ContentType())
+		for _, doc := range exportTestDocs {
+			require.NoError(t, enc.Encode(doc))
+		}
+		require.NoError(t, enc.Close())
+		require.Equal(t, "id,name,active,price,created,address.city,address.zip,tags\n"+
+			`1,"a, ""b""",true,1.5,2023-01-02T03:04:05Z,sf,94107,"[""x"",""y""]"`+"\n"+
+			"2,c,,,,,,\n", buf.String())
+	})
+
+	t.Run("parquet", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc, err := NewExportEncoder(ExportFormatParquet, &buf, exportTestFields)
+		require.NoError(t, err)
+		require.Equal(t, "application/vnd.apache.parquet", enc.ContentType())
+		for _, doc := range exportTestDocs {
+			require.NoError(t, enc.Encode(doc))
+		}
+		require.NoError(t, enc.Close())
+
+		reader := parquet.NewReader(bytes.NewReader(buf.Bytes()))
+		require.Equal(t, int64(2), reader.NumRows())
+
+		columns := map[string]int{}
+		for i, path := range reader.Schema().Columns() {
+			columns[path[0]] = i
+		}
+		require.Len(t, columns, 8)
+
+		rows := make([]parquet.Row, 2)
+		n, err := reader.ReadRows(rows)
+		if err != io.EOF {
+			require.NoError(t, err)
+		}
+		require.Equal(t, 2, n)
+
+		created, _ := time.Parse(time.RFC3339, "2023-01-02T03:04:05Z")
+		first := rows[0]
+		require.Equal(t, int64(1), first[columns["id"]].Int64())
+		require.Equal(t, `a, "b"`, first[columns["name"]].String())
+		require.True(t, first[columns["active"]].Boolean())
+		require.Equal(t, 1.5, first[columns["price"]].Double())
+		require.Equal(t, created.UnixNano(), first[columns["created"]].Int64())
+		require.Equal(t, "sf", first[columns["address.city"]].String())
+		require.Equal(t, int32(94107), first[columns["address.zip"]].Int32())
+		require.JSONEq(t, `["x","y"]`, string(first[columns["tags"]].ByteArray()))
+
+		second := rows[1]
+		require.Equal(t, int64(2), second[columns["id"]].Int64())
+		require.True(t, second[columns["price"]].IsNull())
+		require.True(t, second[columns["address.city"]].IsNull())
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := NewExportEncoder("xml", io.Discard, exportTestFields)
+		require.ErrorContains(t, err, "unsupported export format")
+	})
+}
+
+// exportTestIterator reads the rows from the resume key, inclusive like a scan starting at it, and fails with the
+// transaction limit once it has read interruptAfter rows.
+type exportTestIterator struct {
+	rows           []Row
+	pos            int
+	interruptAfter int
+	err            error
+}
+
+func newExportTestIterator(rows []Row, last []byte, interruptAfter int) *exportTestIterator {
+	it := &exportTestIterator{interruptAfter: interruptAfter}
+	for _, r := range rows {
+		if last == nil || bytes.Compare(r.Key, last) >= 0 {
+			it.rows = append(it.rows, r)
+		}
+	}
+
+	return it
+}
+
+func (it *exportTestIterator) Next(row *Row) bool {
+	if it.pos == it.interruptAfter {
+		it.err = kv.ErrTransactionMaxDurationReached
+		return false
+	}
+	if it.pos == len(it.rows) {
+		return false
+	}
+
+	*row = it.rows[it.pos]
+	it.pos++
+	return true
+}
+
+func (it *exportTestIterator) Interrupted() error { return it.err }
+
+func TestResumeScan(t *testing.T) {
+	var rows []Row
+	for i := 0; i < 10; i++ {
+		rows = append(rows, Row{Key: []byte{byte(i)}})
+	}
+
+	for _, interruptAfter := range []int{2, 3, 11} {
+		var (
+			exported     [][]byte
+			transactions int
+		)
+		require.NoError(t, resumeScan("export", "coll", func(last []byte) ([]byte, error) {
+			transactions++
+			return readResumed(newExportTestIterator(rows, last, interruptAfter), last, func(row *Row) error {
+				exported = append(exported, row.Key)
+				return nil
+			})
+		}))
+
+		// every row is exported once, in key order
+		require.Len(t, exported, len(rows), "interrupt after %d", interruptAfter)
+		for i, r := range rows {
+			require.Equal(t, r.Key, exported[i])
+		}
+		if interruptAfter < len(rows) {
+			require.Greater(t, transactions, 1)
+		}
+	}
+
+	// a transaction reading only the resume key makes no progress
+	err := resumeScan("export", "coll", func(last []byte) ([]byte, error) {
+		return readResumed(newExportTestIterator(rows, last, 1), last, func(*Row) error { return nil })
+	})
+	require.Equal(t, errors.Aborted("export of collection 'coll' is not making progress"), err)
+}
@@ -122,6 +122,46 @@ type SearchIndexerRunner struct {
 	ProgressUpdate func(context.Context) error
 }
 
+// syncSearchIndexSettings pushes the synonyms of the search indexes sourced from this collection again so that these
+// survive the rebuild even if the indexes were recreated in the search store. The settings can only be pushed to an
+// existing index, so the missing ones are created first.
+func (runner *SearchIndexerRunner) syncSearchIndexSettings(ctx context.Context, tenant *metadata.Tenant) error {
+	if len(runner.collection.SearchIndexes) == 0 {
+		return nil
+	}
+
+	project, err := tenant.GetProject(runner.req.GetProject())
+	if err != nil {
+		return err
+	}
+
+	for _, index := range runner.collection.SearchIndexes {
+		if _, err = runner.searchStore.DescribeCollection(ctx, index.StoreIndexName()); err != nil {
+			if search.IsErrNotFound(err) {
+				err = runner.searchStore.CreateCollection(ctx, index.StoreSchema)
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	tx, err := runner.txMgr.StartTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	for _, index := range runner.collection.SearchIndexes {
+		if err = tenant.SyncSearchIndexSettings(ctx, tx, project, index); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (runner *SearchIndexerRunner) ReadOnly(ctx context.Context, tenant *metadata.Tenant) (Response, context.Context, error) {
 	start := time.Now()
 	tx, err := runner.txMgr.StartTx(ctx)
@@ -138,6 +178,7 @@ func (runner *SearchIndexerRunner) ReadOnly(ctx context.Context, tenant *metadat
 	); err != nil {
 		return Response{}, ctx, err
 	}
+
 	if err = tx.Commit(ctx); err != nil {
 		return Response{}, ctx, err
 	}
@@ -153,6 +194,10 @@ func (runner *SearchIndexerRunner) ReadOnly(ctx context.Context, tenant *metadat
 		}
 	}
 
+	if err = runner.syncSearchIndexSettings(ctx, tenant); err != nil {
+		return Response{}, ctx, err
+	}
+
 	runner.sigDone = make(chan struct{}, 1)
 	runner.ctx = ctx
 	runner.since = time.Now()
@@ -189,25 +234,9 @@ func (runner *SearchIndexerRunner) consume(r *api.ReadResponse) error {
 	if runner.close {
 		return errors.Aborted("consumer exited")
 	}
-	index := runner.collection.PrimaryKey
-	indexParts := make([]any, len(index.Fields)+1)
-	indexParts[0] = index.Name
-	for i, f := range index.Fields {
-		jsonVal, dtp, _, err := jsonparser.Get(r.Data, f.FieldName)
-		if err != nil || dtp == jsonparser.NotExist {
-			return errors.Internal("unable to build index '%s' '%v'", err, dtp)
-		}
-
-		v, err := value.NewValue(f.Type(), jsonVal)
-		if err != nil {
-			return errors.Internal("unable to build index '%s' '%v'", err, dtp)
-		}
-		indexParts[i+1] = v.AsInterface()
-	}
-
-	id, err := CreateSearchKey(kv.BuildKey(indexParts...))
+	id, err := searchKeyFromDocument(runner.collection, r.Data)
 	if err != nil {
-		return errors.Internal("unable to build search key '%v'", err)
+		return err
 	}
 
 	searchData, err := PackSearchFields(runner.ctx, internal.NewTableData(r.Data), runner.collection, id)
@@ -246,6 +275,41 @@ func (runner *SearchIndexerRunner) consume(r *api.ReadResponse) error {
 	return nil
 }
 
+// primaryKeyParts extracts the values of the primary key fields from the document.
+func primaryKeyParts(coll *schema.DefaultCollection, data []byte) ([]any, error) {
+	index := coll.PrimaryKey
+	parts := make([]any, len(index.Fields))
+	for i, f := range index.Fields {
+		jsonVal, dtp, _, err := jsonparser.Get(data, f.FieldName)
+		if err != nil || dtp == jsonparser.NotExist {
+			return nil, errors.Internal("unable to build index '%s' '%v'", err, dtp)
+		}
+
+		v, err := value.NewValue(f.Type(), jsonVal)
+		if err != nil {
+			return nil, errors.Internal("unable to build index '%s' '%v'", err, dtp)
+		}
+		parts[i] = v.AsInterface()
+	}
+
+	return parts, nil
+}
+
+// searchKeyFromDocument returns the id of the document in the implicit search index of the collection.
+func searchKeyFromDocument(coll *schema.DefaultCollection, data []byte) (string, error) {
+	parts, err := primaryKeyParts(coll, data)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := CreateSearchKey(kv.BuildKey(append([]any{coll.PrimaryKey.Name}, parts...)...))
+	if err != nil {
+		return "", errors.Internal("unable to build search key '%v'", err)
+	}
+
+	return id, nil
+}
+
 func createReadReq(req *api.BuildCollectionSearchIndexRequest) *api.ReadRequest {
 	return &api.ReadRequest{
 		Project:    req.Project,
@@ -81,6 +81,10 @@ func (runner *InsertQueryRunner) Run(ctx context.Context, tx transaction.Tx, ten
 		return Response{}, ctx, err
 	}
 
+	if ctx, err = runner.enqueuePublish(ctx, tenant, runner.req.GetProject(), runner.req.GetOptions().GetPublish()); err != nil {
+		return Response{}, ctx, err
+	}
+
 	ts, allKeys, err := runner.insertOrReplace(ctx, tx, tenant, coll, runner.req.GetDocuments(), true)
 	if err != nil {
 		if err == kv.ErrDuplicateKey {
@@ -125,6 +129,10 @@ func (runner *ReplaceQueryRunner) Run(ctx context.Context, tx transaction.Tx, te
 		return Response{}, ctx, err
 	}
 
+	if ctx, err = runner.enqueuePublish(ctx, tenant, runner.req.GetProject(), runner.req.GetOptions().GetPublish()); err != nil {
+		return Response{}, ctx, err
+	}
+
 	ts, allKeys, err := runner.insertOrReplace(ctx, tx, tenant, coll, runner.req.GetDocuments(), false)
 	if err != nil {
 		return Response{}, ctx, err
@@ -246,6 +254,10 @@ func (runner *UpdateQueryRunner) Run(ctx context.Context, tx transaction.Tx, ten
 		return Response{}, ctx, err
 	}
 
+	if ctx, err = runner.enqueuePublish(ctx, tenant, runner.req.GetProject(), runner.req.GetOptions().GetPublish()); err != nil {
+		return Response{}, ctx, err
+	}
+
 	factory, err := update.BuildFieldOperators(runner.req.Fields)
 	if err != nil {
 		return Response{}, ctx, err
@@ -387,6 +399,10 @@ func (runner *DeleteQueryRunner) Run(ctx context.Context, tx transaction.Tx, ten
 		return Response{}, ctx, err
 	}
 
+	if ctx, err = runner.enqueuePublish(ctx, tenant, runner.req.GetProject(), runner.req.GetOptions().GetPublish()); err != nil {
+		return Response{}, ctx, err
+	}
+
 	ts := internal.NewTimestamp()
 
 	reqStatus, reqStatusFound := metrics.RequestStatusFromContext(ctx)
@@ -516,12 +532,16 @@ type StreamingQueryRunner struct {
 	req          *api.ReadRequest
 	streaming    Streaming
 	queryMetrics *metrics.StreamingQueryMetrics
+	readVersion  int64
+	docCache     *DocumentCache
 }
 
 type readerOptions struct {
 	plan          *filter.QueryPlan
 	tablePlan     *filter.TableScanPlan
 	inMemoryStore bool
+	// cached is set if the point reads by primary key are served from the document cache
+	cached bool
 	// secondaryIndex bool
 	sorting        *sort.Ordering
 	noSearchFilter *filter.WrappedFilter
@@ -658,7 +678,18 @@ func (runner *StreamingQueryRunner) ReadOnly(ctx context.Context, tenant *metada
 		return Response{}, ctx, err
 	}
 
+	snapshotVersion, err := resolveReadVersion(ctx, runner.txMgr, runner.req.GetOptions().GetReadVersion(),
+		runner.req.GetOptions().GetReadTimestamp())
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	options.cached = runner.useDocumentCache(collection, options, snapshotVersion)
+
 	if options.inMemoryStore {
+		if snapshotVersion != 0 {
+			return Response{}, ctx, errors.InvalidArgument("read version is not supported for reads served by the search index")
+		}
 		if err = runner.iterateOnSearchStore(ctx, collection, options); err != nil {
 			return Response{}, ctx, CreateApiError(err)
 		}
@@ -673,6 +704,11 @@ func (runner *StreamingQueryRunner) ReadOnly(ctx context.Context, tenant *metada
 			return Response{}, ctx, err
 		}
 
+		if err = runner.pinReadVersion(ctx, tx, snapshotVersion); err != nil {
+			_ = tx.Rollback(ctx)
+			return Response{}, ctx, CreateApiError(err)
+		}
+
 		var last []byte
 		if options.plan != nil && filter.IndexTypeSecondary(options.plan.IndexType) {
 			last, err = runner.iterateOnSecondaryIndexStore(ctx, tx, collection, options)
@@ -682,6 +718,12 @@ func (runner *StreamingQueryRunner) ReadOnly(ctx context.Context, tenant *metada
 
 		_ = tx.Rollback(ctx)
 
+		if err == kv.ErrTransactionMaxDurationReached && snapshotVersion != 0 {
+			// the snapshot can't be read beyond the MVCC window, so unlike a read at the latest version this can't be
+			// resumed in a new transaction.
+			return Response{}, ctx, errors.DeadlineExceeded("read at version '%d' exceeded the snapshot window", snapshotVersion)
+		}
+
 		if err == kv.ErrTransactionMaxDurationReached {
 			// We have received ErrTransactionMaxDurationReached i.e. 5 second transaction limit, so we need to retry the
 			// transaction.
@@ -724,11 +766,19 @@ func (runner *StreamingQueryRunner) Run(ctx context.Context, tx transaction.Tx,
 
 	ctx = runner.cdcMgr.WrapContext(ctx, db.Name())
 
+	if runner.req.GetOptions().GetReadVersion() != 0 || runner.req.GetOptions().GetReadTimestamp() != nil {
+		return Response{}, ctx, errors.InvalidArgument("read version can't be set for a read inside a transaction")
+	}
+
 	options, err := runner.buildReaderOptions(runner.req, coll)
 	if err != nil {
 		return Response{}, ctx, err
 	}
 
+	if runner.readVersion, err = tx.GetReadVersion(ctx); err != nil {
+		return Response{}, ctx, CreateApiError(err)
+	}
+
 	ctx = runner.instrumentRunner(ctx, options)
 	if options.inMemoryStore {
 		if err = runner.iterateOnSearchStore(ctx, coll, options); err != nil {
@@ -750,6 +800,35 @@ func (runner *StreamingQueryRunner) Run(ctx context.Context, tx transaction.Tx,
 	return Response{}, ctx, nil
 }
 
+// useDocumentCache returns true if the read is served from the document cache. Only the point reads by primary key of
+// the collections having a cache policy are, outside an explicit transaction and at the latest version.
+func (runner *StreamingQueryRunner) useDocumentCache(coll *schema.DefaultCollection, options readerOptions, snapshotVersion int64) bool {
+	return runner.docCache != nil && coll.Cache.IsEnabled() && !runner.req.GetOptions().GetBypassCache() &&
+		snapshotVersion == 0 && options.tablePlan == nil && !options.inMemoryStore &&
+		options.plan != nil && filter.IndexTypePrimary(options.plan.IndexType)
+}
+
+// pinReadVersion sets the read version of the transaction to the requested snapshot version, if any, and records the
+// version the read is performed at so that it is returned to the caller.
+func (runner *StreamingQueryRunner) pinReadVersion(ctx context.Context, tx transaction.Tx, snapshotVersion int64) error {
+	if snapshotVersion != 0 {
+		if err := tx.SetReadVersion(ctx, snapshotVersion); err != nil {
+			return err
+		}
+	}
+
+	version, err := tx.GetReadVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if runner.readVersion == 0 {
+		// a read resumed in a new transaction reports the version at which it started.
+		runner.readVersion = version
+	}
+
+	return nil
+}
+
 func (runner *StreamingQueryRunner) iterateOnKvStore(ctx context.Context, tx transaction.Tx, coll *schema.DefaultCollection, options readerOptions) ([]byte, error) {
 	var err error
 	var iter Iterator
@@ -768,6 +847,8 @@ func (runner *StreamingQueryRunner) iterateOnKvStore(ctx context.Context, tx tra
 				iter, err = reader.FilteredRead(iter, options.filter)
 			}
 		}
+	} else if options.plan != nil && options.cached {
+		iter = NewCachedKeyIterator(ctx, tx, runner.docCache, coll, options.plan.Keys, runner.req.GetProject(), runner.req.GetBranch())
 	} else if options.plan != nil {
 		iter, err = reader.KeyIterator(options.plan.Keys)
 	} else {
@@ -868,8 +949,9 @@ func (runner *StreamingQueryRunner) iterate(ctx context.Context, coll *schema.De
 			if err := runner.streaming.Send(&api.ReadResponse{
 				Data: newValue,
 				Metadata: &api.ResponseMetadata{
-					CreatedAt: row.Data.CreateToProtoTS(),
-					UpdatedAt: row.Data.UpdatedToProtoTS(),
+					CreatedAt:   row.Data.CreateToProtoTS(),
+					UpdatedAt:   row.Data.UpdatedToProtoTS(),
+					ReadVersion: runner.readVersion,
 				},
 				ResumeToken: row.Key,
 			}); ulog.E(err) {
@@ -887,6 +969,9 @@ func (runner *StreamingQueryRunner) iterate(ctx context.Context, coll *schema.De
 		if err := runner.streaming.Send(&api.ReadResponse{
 			// no need to set resume token in this case.
 			Data: marshaled,
+			Metadata: &api.ResponseMetadata{
+				ReadVersion: runner.readVersion,
+			},
 		}); ulog.E(err) {
 			return row.Key, err
 		}
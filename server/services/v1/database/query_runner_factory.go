@@ -15,12 +15,15 @@
 package database
 
 import (
+	"io"
+
 	api "github.com/tigrisdata/tigris/api/server/v1"
 	"github.com/tigrisdata/tigris/server/cdc"
 	"github.com/tigrisdata/tigris/server/metadata"
 	"github.com/tigrisdata/tigris/server/metrics"
 	"github.com/tigrisdata/tigris/server/transaction"
 	"github.com/tigrisdata/tigris/server/types"
+	"github.com/tigrisdata/tigris/store/cache"
 	"github.com/tigrisdata/tigris/store/search"
 )
 
@@ -30,15 +33,17 @@ type QueryRunnerFactory struct {
 	encoder     metadata.Encoder
 	cdcMgr      *cdc.Manager
 	searchStore search.Store
+	docCache    *DocumentCache
 }
 
 // NewQueryRunnerFactory returns QueryRunnerFactory object.
-func NewQueryRunnerFactory(txMgr *transaction.Manager, cdcMgr *cdc.Manager, searchStore search.Store) *QueryRunnerFactory {
+func NewQueryRunnerFactory(txMgr *transaction.Manager, cdcMgr *cdc.Manager, searchStore search.Store, docCache *DocumentCache) *QueryRunnerFactory {
 	return &QueryRunnerFactory{
 		txMgr:       txMgr,
 		encoder:     metadata.NewEncoder(),
 		cdcMgr:      cdcMgr,
 		searchStore: searchStore,
+		docCache:    docCache,
 	}
 }
 
@@ -97,6 +102,16 @@ func (f *QueryRunnerFactory) GetStreamingQueryRunner(r *api.ReadRequest, streami
 		req:             r,
 		streaming:       streaming,
 		queryMetrics:    qm,
+		docCache:        f.docCache,
+	}
+}
+
+func (f *QueryRunnerFactory) GetExportQueryRunner(r *api.ExportRequest, streaming ExportStreaming, qm *metrics.StreamingQueryMetrics, accessToken *types.AccessToken) *ExportQueryRunner {
+	return &ExportQueryRunner{
+		BaseQueryRunner: NewBaseQueryRunner(f.encoder, f.cdcMgr, f.txMgr, f.searchStore, accessToken),
+		req:             r,
+		streaming:       streaming,
+		queryMetrics:    qm,
 	}
 }
 
@@ -150,3 +165,47 @@ func (f *QueryRunnerFactory) GetSearchIndexRunner(r *api.BuildCollectionSearchIn
 		queryMetrics:    queryMetrics,
 	}
 }
+
+func (f *QueryRunnerFactory) GetSearchVerifyRunner(accessToken *types.AccessToken) *SearchVerifyRunner {
+	return &SearchVerifyRunner{
+		BaseQueryRunner: NewBaseQueryRunner(f.encoder, f.cdcMgr, f.txMgr, f.searchStore, accessToken),
+	}
+}
+
+func (f *QueryRunnerFactory) GetBackupRunner(project string, w io.Writer, streams cache.Cache, accessToken *types.AccessToken) *BackupRunner {
+	return &BackupRunner{
+		BaseQueryRunner: NewBaseQueryRunner(f.encoder, f.cdcMgr, f.txMgr, f.searchStore, accessToken),
+		project:         project,
+		writer:          NewBackupWriter(w),
+		streams:         streams,
+		cacheEnc:        metadata.NewCacheEncoder(),
+	}
+}
+
+func (f *QueryRunnerFactory) GetBulkImportSchemaRunner(opts *BulkImportOptions, sample [][]byte, accessToken *types.AccessToken) *BulkImportSchemaRunner {
+	return &BulkImportSchemaRunner{
+		BaseQueryRunner: NewBaseQueryRunner(f.encoder, f.cdcMgr, f.txMgr, f.searchStore, accessToken),
+		opts:            opts,
+		sample:          sample,
+	}
+}
+
+func (f *QueryRunnerFactory) GetBulkImportRunner(opts *BulkImportOptions, rows []int64, docs [][]byte, qm *metrics.WriteQueryMetrics, accessToken *types.AccessToken) *BulkImportRunner {
+	return &BulkImportRunner{
+		BaseQueryRunner: NewBaseQueryRunner(f.encoder, f.cdcMgr, f.txMgr, f.searchStore, accessToken),
+		opts:            opts,
+		rows:            rows,
+		docs:            docs,
+		queryMetrics:    qm,
+	}
+}
+
+func (f *QueryRunnerFactory) GetRestoreRunner(project string, records []*BackupRecord, streams cache.Cache, accessToken *types.AccessToken) *RestoreRunner {
+	return &RestoreRunner{
+		BaseQueryRunner: NewBaseQueryRunner(f.encoder, f.cdcMgr, f.txMgr, f.searchStore, accessToken),
+		project:         project,
+		records:         records,
+		streams:         streams,
+		cacheEnc:        metadata.NewCacheEncoder(),
+	}
+}
@@ -38,6 +38,10 @@ type SearchStreaming interface {
 	api.Tigris_SearchServer
 }
 
+type ExportStreaming interface {
+	api.Tigris_ExportServer
+}
+
 // ReqOptions are options used by queryLifecycle to execute a query.
 type ReqOptions struct {
 	TxCtx              *api.TransactionCtx
@@ -54,4 +58,6 @@ type Response struct {
 	DeletedAt     *internal.Timestamp
 	ModifiedCount int32
 	AllKeys       [][]byte
+	// CommitVersion is the version at which the implicit transaction of the request is committed.
+	CommitVersion int64
 }
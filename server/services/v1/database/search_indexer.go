@@ -31,6 +31,7 @@ import (
 	"github.com/tigrisdata/tigris/lib/date"
 	"github.com/tigrisdata/tigris/schema"
 	"github.com/tigrisdata/tigris/server/config"
+	"github.com/tigrisdata/tigris/server/embedding"
 	"github.com/tigrisdata/tigris/server/metadata"
 	"github.com/tigrisdata/tigris/server/request"
 	"github.com/tigrisdata/tigris/server/transaction"
@@ -59,9 +60,13 @@ func (i *SearchIndexer) OnPostCommit(ctx context.Context, _ *metadata.Tenant, ev
 		return nil
 	}
 
-	for _, event := range eventListener.GetEvents() {
-		var err error
+	events := eventListener.GetEvents()
+	packed, err := i.packEvents(ctx, events)
+	if err != nil {
+		return err
+	}
 
+	for idx, event := range events {
 		db, collName, ok := i.tenantMgr.DecodeTableName(event.Table)
 		if !ok {
 			continue
@@ -102,12 +107,7 @@ func (i *SearchIndexer) OnPostCommit(ctx context.Context, _ *metadata.Tenant, ev
 				action = search.Update
 			}
 
-			searchData, err := PackSearchFields(ctx, event.Data, collection, searchKey)
-			if err != nil {
-				return err
-			}
-
-			reader := bytes.NewReader(searchData)
+			reader := bytes.NewReader(packed[idx])
 
 			var resp []search.IndexResp
 
@@ -127,6 +127,62 @@ func (i *SearchIndexer) OnPostCommit(ctx context.Context, _ *metadata.Tenant, ev
 	return nil
 }
 
+// packEvents packs the documents written by the transaction for the search index, indexed like the events. The
+// documents are packed together per collection so that the embeddings of a commit are computed in one batch.
+func (i *SearchIndexer) packEvents(ctx context.Context, events []*kv.Event) ([][]byte, error) {
+	type batch struct {
+		collection *schema.DefaultCollection
+		events     []int
+		data       []*internal.TableData
+		ids        []string
+	}
+
+	var batches []*batch
+	byCollection := make(map[*schema.DefaultCollection]*batch)
+	for idx, event := range events {
+		if event.Op == kv.DeleteEvent || event.Key == nil {
+			continue
+		}
+
+		db, collName, ok := i.tenantMgr.DecodeTableName(event.Table)
+		if !ok {
+			continue
+		}
+		collection := db.GetCollection(collName)
+		if collection == nil {
+			continue
+		}
+
+		searchKey, err := CreateSearchKey(event.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		b, ok := byCollection[collection]
+		if !ok {
+			b = &batch{collection: collection}
+			byCollection[collection] = b
+			batches = append(batches, b)
+		}
+		b.events = append(b.events, idx)
+		b.data = append(b.data, event.Data)
+		b.ids = append(b.ids, searchKey)
+	}
+
+	packed := make([][]byte, len(events))
+	for _, b := range batches {
+		docs, err := PackSearchDocuments(ctx, b.data, b.collection, b.ids)
+		if err != nil {
+			return nil, err
+		}
+		for j, idx := range b.events {
+			packed[idx] = docs[j]
+		}
+	}
+
+	return packed, nil
+}
+
 func (*SearchIndexer) OnPreCommit(context.Context, *metadata.Tenant, transaction.Tx, kv.EventListener) error {
 	return nil
 }
@@ -168,12 +224,44 @@ func CreateSearchKey(key kv.Key) (string, error) {
 }
 
 func PackSearchFields(ctx context.Context, data *internal.TableData, collection *schema.DefaultCollection, id string) ([]byte, error) {
-	// better to decode it and then update the JSON
-	decData, err := util.JSONToMap(data.RawData)
+	packed, err := PackSearchDocuments(ctx, []*internal.TableData{data}, collection, []string{id})
 	if err != nil {
 		return nil, err
 	}
 
+	return packed[0], nil
+}
+
+// PackSearchDocuments packs the documents of the collection for the search index. The vectors derived from the text
+// fields are computed for all the documents together, with a single call to the embedding provider per field.
+func PackSearchDocuments(ctx context.Context, data []*internal.TableData, collection *schema.DefaultCollection, ids []string) ([][]byte, error) {
+	var err error
+
+	// better to decode it and then update the JSON
+	decoded := make([]map[string]any, len(data))
+	for i := range data {
+		if decoded[i], err = util.JSONToMap(data[i].RawData); err != nil {
+			return nil, err
+		}
+	}
+
+	// the vectors derived from the text fields are only stored in the search index
+	if err = embedding.EmbedDocuments(ctx, collection.Fields, decoded); err != nil {
+		return nil, err
+	}
+
+	packed := make([][]byte, len(data))
+	for i := range data {
+		if packed[i], err = packSearchDocument(ctx, decoded[i], data[i], collection, ids[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return packed, nil
+}
+
+func packSearchDocument(ctx context.Context, decData map[string]any, data *internal.TableData, collection *schema.DefaultCollection, id string) ([]byte, error) {
+	var err error
 	if value, ok := decData[schema.SearchId]; ok {
 		// if user schema collection has id field already set then change it
 		decData[schema.ReservedFields[schema.IdToSearchKey]] = value
@@ -252,6 +340,7 @@ func PackSearchFields(ctx context.Context, data *internal.TableData, collection
 	}
 
 	decData[schema.SearchId] = id
+	decData[schema.ReservedFields[schema.SearchVersion]] = documentVersion(data)
 	decData[schema.ReservedFields[schema.CreatedAt]] = data.CreatedAt.UnixNano()
 	if data.UpdatedAt != nil {
 		decData[schema.ReservedFields[schema.UpdatedAt]] = data.UpdatedAt.UnixNano()
@@ -325,6 +414,9 @@ func UnpackSearchFields(doc map[string]any, collection *schema.DefaultCollection
 		delete(doc, schema.ReservedFields[schema.UpdatedAt])
 	}
 
+	// the version is only used to verify the search index
+	delete(doc, schema.ReservedFields[schema.SearchVersion])
+
 	// process user fields now
 	var arrayOfObjects []string
 	for _, f := range collection.QueryableFields {
@@ -62,12 +62,12 @@ func (p *page) hasCapacity() bool {
 
 // readRow should be used to read search data because this is the single point where we unpack search fields, apply
 // filter and then pack the document into bytes.
-func (p *page) readRow() map[string]any {
+func (p *page) readRow() *tsearch.Hit {
 	for p.idx < len(p.hits) {
-		document := p.hits[p.idx].Document
+		hit := p.hits[p.idx]
 		p.idx++
-		if document != nil {
-			return document
+		if hit.Document != nil {
+			return hit
 		}
 	}
 
@@ -129,7 +129,7 @@ func (p *pageReader) read() error {
 	if len(p.cachedFacets) == 0 {
 		if len(result) > 0 {
 			builder := tsearch.NewFacetResponse(p.query.Facets)
-			for field, built := range builder.Build(result[0].FacetCounts) {
+			for field, built := range builder.Build(result[0].Facets) {
 				p.cachedFacets[field] = built
 			}
 		}
@@ -138,9 +138,7 @@ func (p *pageReader) read() error {
 	if p.found == -1 {
 		p.found = 0
 		for _, r := range result {
-			if r.Found != nil {
-				p.found += int64(*r.Found)
-			}
+			p.found += int64(r.Found)
 		}
 	}
 	return nil
@@ -171,6 +169,7 @@ type FilterableSearchIterator struct {
 	pageReader *pageReader
 	collection *schema.DefaultCollection
 	ctx        context.Context
+	match      *api.Match
 }
 
 func NewFilterableSearchIterator(ctx context.Context, collection *schema.DefaultCollection, reader *pageReader, filter *filter.WrappedFilter, singlePage bool) *FilterableSearchIterator {
@@ -195,8 +194,9 @@ func (it *FilterableSearchIterator) Next(row *Row) bool {
 			}
 		}
 
-		if doc := it.page.readRow(); doc != nil {
+		if hit := it.page.readRow(); hit != nil {
 			var searchKey string
+			doc := hit.Document
 			if searchKey, row.Data, doc, it.err = UnpackSearchFields(doc, it.collection); it.err != nil {
 				return false
 			}
@@ -223,6 +223,7 @@ func (it *FilterableSearchIterator) Next(row *Row) bool {
 			}
 
 			row.Data.RawData = rawData
+			it.match = hit.Match
 
 			return true
 		}
@@ -239,6 +240,11 @@ func (it *FilterableSearchIterator) getFacets() map[string]*api.SearchFacet {
 	return it.pageReader.cachedFacets
 }
 
+// getMatch returns the text match and the vector distance of the last row returned by Next.
+func (it *FilterableSearchIterator) getMatch() *api.Match {
+	return it.match
+}
+
 func (it *FilterableSearchIterator) Interrupted() error {
 	return it.err
 }
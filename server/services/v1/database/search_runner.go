@@ -26,6 +26,7 @@ import (
 	qsearch "github.com/tigrisdata/tigris/query/search"
 	"github.com/tigrisdata/tigris/schema"
 	"github.com/tigrisdata/tigris/server/config"
+	"github.com/tigrisdata/tigris/server/embedding"
 	"github.com/tigrisdata/tigris/server/metadata"
 	"github.com/tigrisdata/tigris/server/metrics"
 	ulog "github.com/tigrisdata/tigris/util/log"
@@ -107,11 +108,25 @@ func (runner *SearchQueryRunner) ReadOnly(ctx context.Context, tenant *metadata.
 		runner.queryMetrics.SetSort(false)
 	}
 
-	vecSearch, err := runner.getVectorSearch(collection)
+	vecSearch, err := runner.getVectorSearch(ctx, collection)
 	if err != nil {
 		return Response{}, ctx, err
 	}
 
+	hybrid, err := qsearch.UnmarshalHybridSearch(runner.req.Hybrid)
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	relevance, err := runner.getRelevance(collection)
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	if err = runner.checkReadVersion(); err != nil {
+		return Response{}, ctx, err
+	}
+
 	ctx = metrics.UpdateSpanTags(ctx, runner.queryMetrics)
 
 	pageSize := int(runner.req.PageSize)
@@ -129,9 +144,12 @@ func (runner *SearchQueryRunner) ReadOnly(ctx context.Context, tenant *metadata.
 		ReadFields(fieldSelection).
 		SortOrder(sortOrder).
 		VectorSearch(vecSearch).
+		Hybrid(hybrid).
+		Relevance(relevance).
+		Analyzers(schema.SearchAnalyzers(collection.QueryableFields)).
 		Build()
-	if searchQ.IsQAndVectorBoth() {
-		return Response{}, ctx, errors.InvalidArgument("Currently either full text or vector search is supported")
+	if err = searchQ.ValidateSearchMode(); err != nil {
+		return Response{}, ctx, err
 	}
 
 	searchReader := NewSearchReader(ctx, runner.searchStore, collection, searchQ)
@@ -162,12 +180,18 @@ func (runner *SearchQueryRunner) ReadOnly(ctx context.Context, tenant *metadata.
 				row.Data.RawData = newValue
 			}
 
+			metadata := &api.SearchHitMeta{
+				CreatedAt: row.Data.CreateToProtoTS(),
+				UpdatedAt: row.Data.UpdatedToProtoTS(),
+			}
+			if searchQ.IsHybridSearch() {
+				// the scores of both rankings are useful to tune the fusion
+				metadata.Match = iterator.getMatch()
+			}
+
 			resp.Hits = append(resp.Hits, &api.SearchHit{
-				Data: row.Data.RawData,
-				Metadata: &api.SearchHitMeta{
-					CreatedAt: row.Data.CreateToProtoTS(),
-					UpdatedAt: row.Data.UpdatedToProtoTS(),
-				},
+				Data:     row.Data.RawData,
+				Metadata: metadata,
 			})
 
 			if len(resp.Hits) == pageSize {
@@ -209,9 +233,29 @@ func (runner *SearchQueryRunner) ReadOnly(ctx context.Context, tenant *metadata.
 		pageNo++
 	}
 
+	if runner.req.Page <= defaultPageNo {
+		// paginating through the results is not recorded as another search
+		tenant.MetaStore.SearchAnalytics().RecordSearch(ctx, runner.txMgr, &metadata.SearchAnalyticsTarget{
+			NamespaceId: tenant.GetNamespace().Id(),
+			Project:     runner.req.GetProject(),
+			Branch:      db.BranchName(),
+			Collection:  collection.Name,
+		}, runner.req.Q, iterator.getTotalFound())
+	}
+
 	return Response{}, ctx, nil
 }
 
+// checkReadVersion rejects the snapshot reads. The search index is not versioned, it is updated once a transaction is
+// committed and only serves its latest state, so a read at a requested version or timestamp can't be served from it.
+func (runner *SearchQueryRunner) checkReadVersion() error {
+	if runner.req.GetReadVersion() != 0 || runner.req.GetReadTimestamp() != nil {
+		return errors.InvalidArgument("read version is not supported by search, the search index only serves the latest version")
+	}
+
+	return nil
+}
+
 func (runner *SearchQueryRunner) getSearchFields(coll *schema.DefaultCollection) ([]string, error) {
 	searchFields := runner.req.SearchFields
 	if len(searchFields) == 0 {
@@ -294,7 +338,22 @@ func (runner *SearchQueryRunner) getFieldSelection(coll *schema.DefaultCollectio
 	return factory, nil
 }
 
-func (runner *SearchQueryRunner) getVectorSearch(coll *schema.DefaultCollection) (qsearch.VectorSearch, error) {
+// getRelevance returns the relevance of the request completed by the defaults of the collection.
+func (runner *SearchQueryRunner) getRelevance(coll *schema.DefaultCollection) (*schema.SearchRelevance, error) {
+	relevance, err := schema.UnmarshalSearchRelevance(runner.req.Relevance)
+	if err != nil {
+		return nil, err
+	}
+
+	relevance = relevance.Merge(coll.Relevance)
+	if err = relevance.Validate(coll.QueryableFields); err != nil {
+		return nil, err
+	}
+
+	return relevance, nil
+}
+
+func (runner *SearchQueryRunner) getVectorSearch(ctx context.Context, coll *schema.DefaultCollection) (qsearch.VectorSearch, error) {
 	vectorSearch, err := qsearch.UnmarshalVectorSearch(runner.req.Vector)
 	if err != nil {
 		return vectorSearch, err
@@ -310,6 +369,9 @@ func (runner *SearchQueryRunner) getVectorSearch(coll *schema.DefaultCollection)
 	if f.DataType != schema.VectorType {
 		return qsearch.VectorSearch{}, errors.InvalidArgument("Cannot perform vector search on non-vector type, field `%s` is not a vector", f.FieldName)
 	}
+	if err = embedding.EmbedVectorSearch(ctx, f, &vectorSearch); err != nil {
+		return qsearch.VectorSearch{}, err
+	}
 	if f.Dimensions != nil && *f.Dimensions != len(vectorSearch.VectorV) {
 		return qsearch.VectorSearch{}, errors.InvalidArgument("query vector is not same size as dimensions, expected size: %d", *f.Dimensions)
 	}
@@ -0,0 +1,467 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/keys"
+	"github.com/tigrisdata/tigris/query/filter"
+	qsearch "github.com/tigrisdata/tigris/query/search"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/store/kv"
+	"github.com/tigrisdata/tigris/store/search"
+)
+
+const (
+	verifyBatchSize        = 100
+	verifyProgressInterval = 30 * time.Second
+)
+
+// SearchVerifier compares the documents of a collection with the documents of its implicit search index. The
+// collection is read in key ranges of verifyBatchSize documents, the matching search documents are fetched by id and
+// compared using the version stored in the search document, a hash of the content of the document it was indexed
+// from. The search documents indexed before the version was stored are reported as stale. Then the search index is scanned to find the documents which don't exist
+// in the collection anymore.
+//
+// If repair is set, only the inconsistent documents are fixed: the missing and stale documents are indexed again from
+// the collection and the extra documents are removed from the search index.
+type SearchVerifier struct {
+	txMgr        *transaction.Manager
+	encoder      metadata.Encoder
+	searchStore  search.Store
+	collection   *schema.DefaultCollection
+	repair       bool
+	lastProgress time.Time
+
+	// ProgressUpdate is called periodically while verifying, the worker uses it to extend the lease of the task.
+	ProgressUpdate func(context.Context) error
+}
+
+func NewSearchVerifier(txMgr *transaction.Manager, searchStore search.Store, coll *schema.DefaultCollection, repair bool) *SearchVerifier {
+	return &SearchVerifier{
+		txMgr:        txMgr,
+		encoder:      metadata.NewEncoder(),
+		searchStore:  searchStore,
+		collection:   coll,
+		repair:       repair,
+		lastProgress: time.Now(),
+	}
+}
+
+// Verify fills the report with the inconsistencies found between the collection and its search index.
+func (v *SearchVerifier) Verify(ctx context.Context, report *metadata.SearchVerifyReport) error {
+	start := time.Now()
+
+	if err := v.verifyCollection(ctx, report); err != nil {
+		return err
+	}
+
+	if err := v.verifyIndex(ctx, report); err != nil {
+		return err
+	}
+
+	log.Info().Msgf("Verified search index '%s' of collection '%s' in '%v': checked '%d', indexed '%d', missing '%d', extra '%d', stale '%d', repaired '%d'",
+		v.indexName(), v.collection.Name, time.Since(start), report.Checked, report.Indexed, report.NumMissing,
+		report.NumExtra, report.NumStale, report.Repaired)
+
+	return nil
+}
+
+func (v *SearchVerifier) indexName() string {
+	return v.collection.ImplicitSearchIndex.StoreIndexName()
+}
+
+// verifyCollection finds the documents of the collection which are missing or outdated in the search index.
+func (v *SearchVerifier) verifyCollection(ctx context.Context, report *metadata.SearchVerifyReport) error {
+	var last []byte
+	for {
+		rows, err := v.readRows(ctx, last)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		last = rows[len(rows)-1].Key
+
+		if err = v.compareRows(ctx, rows, report); err != nil {
+			return err
+		}
+
+		if err = v.progress(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// readRows reads the next batch of documents after the last key, each batch is read in its own transaction.
+func (v *SearchVerifier) readRows(ctx context.Context, last []byte) ([]Row, error) {
+	tx, err := v.txMgr.StartTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var iter Iterator
+	reader := NewDatabaseReader(ctx, tx)
+	if last != nil {
+		var from keys.Key
+		if from, err = keys.FromBinary(v.collection.EncodedName, last); err == nil {
+			iter, err = reader.ScanIterator(from, nil, false)
+		}
+	} else {
+		iter, err = reader.ScanTable(v.collection.EncodedName, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		row  Row
+		rows []Row
+	)
+	for len(rows) < verifyBatchSize && iter.Next(&row) {
+		if last != nil && bytes.Equal(row.Key, last) {
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, iter.Interrupted()
+}
+
+func (v *SearchVerifier) compareRows(ctx context.Context, rows []Row, report *metadata.SearchVerifyReport) error {
+	ids := make([]string, len(rows))
+	for i := range rows {
+		// the search documents are packed from the latest version of the schema
+		if err := v.upgradeRow(rows[i].Data); err != nil {
+			return err
+		}
+
+		id, err := searchKeyFromDocument(v.collection, rows[i].Data.RawData)
+		if err != nil {
+			return err
+		}
+		ids[i] = id
+	}
+
+	result, err := v.searchStore.GetDocuments(ctx, v.indexName(), ids)
+	if err != nil {
+		return err
+	}
+
+	// the version is kept as a string, so it doesn't depend on how the numbers of the search document are decoded
+	indexed := make(map[string]string)
+	if result != nil {
+		for _, hit := range result.Hits {
+			id, ok := hit.Document[schema.SearchId].(string)
+			if !ok {
+				continue
+			}
+			version, _ := hit.Document[schema.ReservedFields[schema.SearchVersion]].(string)
+			indexed[id] = version
+		}
+	}
+
+	var outdated []keys.Key
+	for i, id := range ids {
+		report.Checked++
+
+		version, found := indexed[id]
+		switch {
+		case !found:
+			report.AddMissing(id)
+		case version != documentVersion(rows[i].Data):
+			report.AddStale(id)
+		default:
+			continue
+		}
+
+		key, err := keys.FromBinary(v.collection.EncodedName, rows[i].Key)
+		if err != nil {
+			return err
+		}
+		outdated = append(outdated, key)
+	}
+
+	if !v.repair || len(outdated) == 0 {
+		return nil
+	}
+
+	return v.reindex(ctx, outdated, report)
+}
+
+// reindex indexes the documents again, the documents are read again so that a document written concurrently to the
+// verification is not replaced by the version read earlier. The documents are packed once the transaction is closed,
+// packing may call the embedding provider.
+func (v *SearchVerifier) reindex(ctx context.Context, docKeys []keys.Key, report *metadata.SearchVerifyReport) error {
+	data, ids, err := v.readDocuments(ctx, docKeys)
+	if err != nil {
+		return err
+	}
+
+	// the documents deleted in the meantime are removed from the search index by the delete itself
+	if len(data) == 0 {
+		return nil
+	}
+
+	packed, err := PackSearchDocuments(ctx, data, v.collection, ids)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, p := range packed {
+		buf.Write(p)
+		buf.WriteByte('\n')
+	}
+
+	resp, err := v.searchStore.IndexDocuments(ctx, v.indexName(), &buf, search.IndexDocumentsOptions{
+		Action:    search.Replace,
+		BatchSize: len(packed),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range resp {
+		if !r.Success {
+			return search.NewSearchError(r.Code, search.ErrCodeUnhandled, r.Error)
+		}
+		report.Repaired++
+	}
+
+	return nil
+}
+
+// readDocuments reads the documents of the keys which still exist along with their search ids.
+func (v *SearchVerifier) readDocuments(ctx context.Context, docKeys []keys.Key) ([]*internal.TableData, []string, error) {
+	tx, err := v.txMgr.StartTx(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	iter, err := NewDatabaseReader(ctx, tx).KeyIterator(docKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		row  Row
+		data []*internal.TableData
+		ids  []string
+	)
+	for iter.Next(&row) {
+		if err = v.upgradeRow(row.Data); err != nil {
+			return nil, nil, err
+		}
+
+		id, err := searchKeyFromDocument(v.collection, row.Data.RawData)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		data = append(data, row.Data)
+		ids = append(ids, id)
+	}
+
+	return data, ids, iter.Interrupted()
+}
+
+func (v *SearchVerifier) upgradeRow(data *internal.TableData) error {
+	if v.collection.CompatibleSchemaSince(uint32(data.Ver)) {
+		return nil
+	}
+
+	var err error
+	data.RawData, err = v.collection.UpdateRowSchemaRaw(data.RawData, uint32(data.Ver))
+
+	return err
+}
+
+type extraDocument struct {
+	id  string
+	key keys.Key
+}
+
+// verifyIndex finds the search documents which don't exist in the collection. The extra documents are removed once
+// the scan is done, removing them while paging would shift the pages of the scan.
+func (v *SearchVerifier) verifyIndex(ctx context.Context, report *metadata.SearchVerifyReport) error {
+	query := qsearch.NewBuilder().
+		Filter(filter.WrappedEmptyFilter).
+		PageSize(verifyBatchSize).
+		Build()
+	iter := NewSearchReader(ctx, v.searchStore, v.collection, query).Iterator(ctx, v.collection, filter.WrappedEmptyFilter)
+
+	var (
+		row    Row
+		batch  []extraDocument
+		extras []extraDocument
+	)
+	for {
+		more := iter.Next(&row)
+		if more {
+			report.Indexed++
+
+			doc := extraDocument{id: string(row.Key)}
+			if parts, err := primaryKeyParts(v.collection, row.Data.RawData); err == nil {
+				if doc.key, err = v.encoder.EncodeKey(v.collection.EncodedName, v.collection.GetPrimaryKey(), parts); err != nil {
+					return err
+				}
+			}
+			batch = append(batch, doc)
+		}
+
+		if len(batch) == verifyBatchSize || (!more && len(batch) > 0) {
+			absent, err := v.absentDocuments(ctx, batch)
+			if err != nil {
+				return err
+			}
+
+			for _, doc := range absent {
+				report.AddExtra(doc.id)
+			}
+			if v.repair {
+				extras = append(extras, absent...)
+			}
+
+			batch = batch[:0]
+			if err = v.progress(ctx); err != nil {
+				return err
+			}
+		}
+
+		if !more {
+			break
+		}
+	}
+	if err := iter.Interrupted(); err != nil {
+		return err
+	}
+
+	return v.removeExtras(ctx, extras, report)
+}
+
+// absentDocuments returns the search documents which don't have a document in the collection. A search document
+// without a valid primary key can't belong to the collection.
+func (v *SearchVerifier) absentDocuments(ctx context.Context, docs []extraDocument) ([]extraDocument, error) {
+	tx, err := v.txMgr.StartTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var absent []extraDocument
+	for _, doc := range docs {
+		if doc.key != nil {
+			exists, err := documentExists(ctx, tx, doc.key)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				continue
+			}
+		}
+
+		absent = append(absent, doc)
+	}
+
+	return absent, nil
+}
+
+func (v *SearchVerifier) removeExtras(ctx context.Context, extras []extraDocument, report *metadata.SearchVerifyReport) error {
+	for len(extras) > 0 {
+		n := len(extras)
+		if n > verifyBatchSize {
+			n = verifyBatchSize
+		}
+
+		// the documents are checked again, these may have been inserted since the scan
+		absent, err := v.absentDocuments(ctx, extras[:n])
+		if err != nil {
+			return err
+		}
+		extras = extras[n:]
+
+		for _, doc := range absent {
+			if err = v.searchStore.DeleteDocument(ctx, v.indexName(), doc.id); err != nil {
+				if !search.IsErrNotFound(err) {
+					return err
+				}
+				continue
+			}
+			report.Repaired++
+		}
+
+		if err = v.progress(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (v *SearchVerifier) progress(ctx context.Context) error {
+	if v.ProgressUpdate == nil || time.Since(v.lastProgress) < verifyProgressInterval {
+		return nil
+	}
+
+	v.lastProgress = time.Now()
+	return v.ProgressUpdate(ctx)
+}
+
+func documentExists(ctx context.Context, tx transaction.Tx, key keys.Key) (bool, error) {
+	it, err := tx.Read(ctx, key, false)
+	if err != nil {
+		return false, err
+	}
+
+	var kvs kv.KeyValue
+	if it.Next(&kvs) {
+		return true, nil
+	}
+
+	return false, it.Err()
+}
+
+// documentVersion hashes the timestamps and the payload of the document. It is stored in the search document as a
+// string when it is indexed, so the versions differ if the search document is outdated.
+func documentVersion(data *internal.TableData) string {
+	var buf [16]byte
+	if data.CreatedAt != nil {
+		binary.BigEndian.PutUint64(buf[:8], uint64(data.CreatedAt.UnixNano()))
+	}
+	if data.UpdatedAt != nil {
+		binary.BigEndian.PutUint64(buf[8:], uint64(data.UpdatedAt.UnixNano()))
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write(buf[:])
+	_, _ = h.Write(data.RawData)
+
+	return strconv.FormatUint(h.Sum64(), 16)
+}
@@ -0,0 +1,125 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/keys"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/store/search"
+)
+
+func TestDocumentVersion(t *testing.T) {
+	created := internal.CreateNewTimestamp(1000)
+	updated := internal.CreateNewTimestamp(2000)
+
+	v1 := documentVersion(&internal.TableData{CreatedAt: created})
+	require.Equal(t, v1, documentVersion(&internal.TableData{CreatedAt: internal.CreateNewTimestamp(1000)}))
+	require.NotEqual(t, v1, documentVersion(&internal.TableData{CreatedAt: created, UpdatedAt: updated}))
+	require.NotEqual(t, v1, documentVersion(&internal.TableData{CreatedAt: internal.CreateNewTimestamp(1001)}))
+
+	// the version depends on the payload as well
+	require.NotEqual(t,
+		documentVersion(&internal.TableData{CreatedAt: created, UpdatedAt: updated, RawData: []byte(`{"a":1}`)}),
+		documentVersion(&internal.TableData{CreatedAt: created, UpdatedAt: updated, RawData: []byte(`{"a":2}`)}))
+}
+
+// fakeSearchStore returns the documents decoded the way the Typesense client decodes them, with float64 numbers.
+type fakeSearchStore struct {
+	search.NoopStore
+
+	docs map[string]map[string]any
+}
+
+func (s *fakeSearchStore) index(t *testing.T, packed []byte) {
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(packed, &doc))
+	s.docs[doc[schema.SearchId].(string)] = doc
+}
+
+func (s *fakeSearchStore) GetDocuments(_ context.Context, _ string, ids []string) (*search.Result, error) {
+	var hits []search.Hit
+	for _, id := range ids {
+		if doc, ok := s.docs[id]; ok {
+			hits = append(hits, search.Hit{Document: doc})
+		}
+	}
+
+	return &search.Result{Found: len(hits), Hits: hits}, nil
+}
+
+func TestSearchVerifierCompareRows(t *testing.T) {
+	factory, err := schema.NewFactoryBuilder(true).Build("t1", []byte(`{
+		"title": "t1",
+		"properties": {
+			"id": { "type": "integer" },
+			"name": { "type": "string" }
+		},
+		"primary_key": ["id"]
+	}`))
+	require.NoError(t, err)
+	coll, err := schema.NewDefaultCollection(1, 1, factory, nil, schema.NewImplicitSearchIndex("t1", "t1", factory.Fields, nil))
+	require.NoError(t, err)
+	coll.EncodedName = []byte("t1")
+
+	store := &fakeSearchStore{docs: make(map[string]map[string]any)}
+	v := NewSearchVerifier(nil, store, coll, false)
+
+	// the batch is larger than the default page of the search store and the timestamps don't fit in a float64
+	var rows []Row
+	for i := 0; i < 25; i++ {
+		data := internal.NewTableDataWithTS(internal.CreateNewTimestamp(1684000000123456789+int64(i)), nil,
+			[]byte(fmt.Sprintf(`{"id":%d,"name":"doc %d"}`, i, i)))
+		rows = append(rows, Row{Key: keys.NewKey(coll.EncodedName, "pkey", int64(i)).SerializeToBytes(), Data: data})
+
+		id, err := searchKeyFromDocument(coll, data.RawData)
+		require.NoError(t, err)
+
+		switch i {
+		case 3:
+			// never indexed
+			continue
+		case 7:
+			// indexed from an older version of the document
+			data = internal.NewTableDataWithTS(data.CreatedAt, nil, []byte(`{"id":7,"name":"old"}`))
+		}
+
+		packed, err := PackSearchFields(context.TODO(), data, coll, id)
+		require.NoError(t, err)
+		store.index(t, packed)
+	}
+
+	report := &metadata.SearchVerifyReport{}
+	require.NoError(t, v.compareRows(context.TODO(), rows, report))
+	require.Equal(t, int64(25), report.Checked)
+	require.Equal(t, int64(1), report.NumMissing)
+	require.Equal(t, int64(1), report.NumStale)
+
+	// the search documents indexed before the version was stored are stale
+	for _, doc := range store.docs {
+		delete(doc, schema.ReservedFields[schema.SearchVersion])
+	}
+	report = &metadata.SearchVerifyReport{}
+	require.NoError(t, v.compareRows(context.TODO(), rows, report))
+	require.Equal(t, int64(1), report.NumMissing)
+	require.Equal(t, int64(24), report.NumStale)
+}
@@ -0,0 +1,162 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/server/config"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// SearchVerifyRunner queues the verification of the implicit search index of a collection and returns the report of
+// the latest verification.
+type SearchVerifyRunner struct {
+	*BaseQueryRunner
+
+	verifyReq *api.VerifyCollectionSearchIndexRequest
+	reportReq *api.GetCollectionSearchIndexReportRequest
+}
+
+func (runner *SearchVerifyRunner) SetVerifyReq(verify *api.VerifyCollectionSearchIndexRequest) {
+	runner.verifyReq = verify
+}
+
+func (runner *SearchVerifyRunner) SetReportReq(report *api.GetCollectionSearchIndexReportRequest) {
+	runner.reportReq = report
+}
+
+func (runner *SearchVerifyRunner) Run(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant) (Response, context.Context, error) {
+	switch {
+	case runner.verifyReq != nil:
+		return runner.verify(ctx, tx, tenant)
+	case runner.reportReq != nil:
+		return runner.report(ctx, tx, tenant)
+	}
+
+	return Response{}, ctx, errors.Unknown("unknown request path")
+}
+
+func (runner *SearchVerifyRunner) verify(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant) (Response, context.Context, error) {
+	if !config.DefaultConfig.Workers.SearchEnabled {
+		return Response{}, ctx, errors.Unimplemented("search index verification requires the search workers to be enabled")
+	}
+
+	task, err := runner.getTask(ctx, tx, tenant, runner.verifyReq.GetProject(), runner.verifyReq.GetBranch(),
+		runner.verifyReq.GetCollection())
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	queueData, err := jsoniter.Marshal(metadata.SearchVerifyTask{
+		IndexBuildTask: *task,
+		Repair:         runner.verifyReq.GetRepair(),
+	})
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	item := metadata.NewQueueItem(0, queueData, metadata.VERIFY_SEARCH_INDEX_TASK)
+	if err = tenant.MetaStore.Queue().Enqueue(ctx, tx, item, 0); err != nil {
+		return Response{}, ctx, err
+	}
+
+	if err = tenant.MetaStore.SearchVerify().Put(ctx, tx, task, &metadata.SearchVerifyReport{
+		Id:        item.Id,
+		Status:    metadata.VerifyStatusQueued,
+		Repair:    runner.verifyReq.GetRepair(),
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return Response{}, ctx, err
+	}
+
+	return Response{
+		Response: &api.VerifyCollectionSearchIndexResponse{
+			Id:     item.Id,
+			Status: metadata.VerifyStatusQueued,
+		},
+	}, ctx, nil
+}
+
+func (runner *SearchVerifyRunner) report(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant) (Response, context.Context, error) {
+	task, err := runner.getTask(ctx, tx, tenant, runner.reportReq.GetProject(), runner.reportReq.GetBranch(),
+		runner.reportReq.GetCollection())
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	report, err := tenant.MetaStore.SearchVerify().Get(ctx, tx, task)
+	if err == errors.ErrNotFound {
+		return Response{}, ctx, errors.NotFound("search index of collection '%s' was never verified", task.CollName)
+	}
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	return Response{
+		Response: &api.GetCollectionSearchIndexReportResponse{
+			Report: verifyReportToAPI(report),
+		},
+	}, ctx, nil
+}
+
+func (runner *SearchVerifyRunner) getTask(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant,
+	project string, branch string, collection string,
+) (*metadata.IndexBuildTask, error) {
+	db, coll, err := runner.getDBAndCollection(ctx, tx, tenant, project, collection, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	return &metadata.IndexBuildTask{
+		NamespaceId: tenant.GetNamespace().StrId(),
+		ProjName:    db.DbName(),
+		Branch:      db.BranchName(),
+		CollName:    coll.Name,
+	}, nil
+}
+
+func verifyReportToAPI(report *metadata.SearchVerifyReport) *api.SearchIndexReport {
+	resp := &api.SearchIndexReport{
+		Id:         report.Id,
+		Status:     report.Status,
+		Repair:     report.Repair,
+		CreatedAt:  timestamppb.New(report.CreatedAt),
+		Error:      report.Error,
+		Checked:    report.Checked,
+		Indexed:    report.Indexed,
+		NumMissing: report.NumMissing,
+		NumExtra:   report.NumExtra,
+		NumStale:   report.NumStale,
+		Repaired:   report.Repaired,
+		Missing:    report.Missing,
+		Extra:      report.Extra,
+		Stale:      report.Stale,
+	}
+	if !report.StartedAt.IsZero() {
+		resp.StartedAt = timestamppb.New(report.StartedAt)
+	}
+	if !report.CompletedAt.IsZero() {
+		resp.CompletedAt = timestamppb.New(report.CompletedAt)
+	}
+
+	return resp
+}
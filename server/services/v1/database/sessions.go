@@ -50,6 +50,7 @@ const (
 
 type Session interface {
 	Create(ctx context.Context, trackVerInOwnTxn bool, instantVerTracking bool, track bool) (*QuerySession, error)
+	CreateLongRunning(ctx context.Context) (*QuerySession, error)
 	Get(ctx context.Context) (*QuerySession, error)
 	Remove(ctx context.Context) error
 	ReadOnlyExecute(ctx context.Context, runner ReadOnlyQueryRunner, req ReqOptions) (Response, error)
@@ -94,6 +95,14 @@ func (m *SessionManagerWithMetrics) Create(ctx context.Context, trackVerInOwnTxn
 	return
 }
 
+func (m *SessionManagerWithMetrics) CreateLongRunning(ctx context.Context) (qs *QuerySession, err error) {
+	m.measure(ctx, "CreateLongRunning", func(ctx context.Context) error {
+		qs, err = m.s.CreateLongRunning(ctx)
+		return CreateApiError(err)
+	})
+	return
+}
+
 func (m *SessionManagerWithMetrics) Get(ctx context.Context) (qs *QuerySession, err error) {
 	// Very cheap in-memory operation, not measuring it to avoid overhead
 	return m.s.Get(ctx)
@@ -128,6 +137,11 @@ func (m *SessionManagerWithMetrics) executeWithRetry(ctx context.Context, runner
 	return
 }
 
+// Stop stops the background reaping of the expired long-running transactions.
+func (m *SessionManagerWithMetrics) Stop() {
+	m.s.Stop()
+}
+
 func NewSessionManager(txMgr *transaction.Manager, tenantMgr *metadata.TenantManager, listeners []TxListener, tenantTracker *metadata.CacheTracker) *SessionManager {
 	return &SessionManager{
 		txMgr:         txMgr,
@@ -152,6 +166,11 @@ func NewSessionManagerWithMetrics(txMgr *transaction.Manager, tenantMgr *metadat
 	}
 }
 
+// Stop stops the background reaping of the expired long-running transactions.
+func (sessMgr *SessionManager) Stop() {
+	sessMgr.tracker.stop()
+}
+
 func (sessMgr *SessionManager) CreateReadOnlySession(ctx context.Context) (*ReadOnlySession, error) {
 	namespaceForThisSession, err := request.GetNamespace(ctx)
 	if err != nil {
@@ -184,6 +203,18 @@ func (sessMgr *SessionManager) CreateReadOnlySession(ctx context.Context) (*Read
 // It first creates or get a tenant, read the metadata version and based on that reload the tenant cache and then finally
 // create a transaction which will be used to execute all the query in this session.
 func (sessMgr *SessionManager) Create(ctx context.Context, trackVerInOwnTxn bool, instantVerTracking bool, track bool) (*QuerySession, error) {
+	return sessMgr.create(ctx, trackVerInOwnTxn, instantVerTracking, track, false)
+}
+
+// CreateLongRunning returns a tracked QuerySession of an explicit transaction that is not bounded by the five seconds
+// limit of FDB, see transaction.LongTxSession.
+func (sessMgr *SessionManager) CreateLongRunning(ctx context.Context) (*QuerySession, error) {
+	return sessMgr.create(ctx, true, true, true, true)
+}
+
+func (sessMgr *SessionManager) create(ctx context.Context, trackVerInOwnTxn bool, instantVerTracking bool, track bool,
+	longRunning bool,
+) (*QuerySession, error) {
 	namespaceForThisSession, err := request.GetNamespace(ctx)
 	if err != nil {
 		return nil, err
@@ -213,7 +244,12 @@ func (sessMgr *SessionManager) Create(ctx context.Context, trackVerInOwnTxn bool
 		}
 	}
 
-	tx, err := sessMgr.txMgr.StartTx(ctx)
+	var tx transaction.Tx
+	if longRunning {
+		tx, err = sessMgr.txMgr.StartLongRunningTx(ctx)
+	} else {
+		tx, err = sessMgr.txMgr.StartTx(ctx)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -314,6 +350,9 @@ func (sessMgr *SessionManager) executeWithRetry(ctx context.Context, runner Quer
 
 		err = session.Commit(sessMgr.versionH, session.tx.Context().IsMetadataStateChanged(), err)
 		log.Debug().Err(err).Msg("session.commit after")
+		if err == nil {
+			resp.CommitVersion = session.tx.CommittedVersion()
+		}
 		if !IsErrConflictingTransaction(err) && !search.IsErrDuplicateFieldNames(err) {
 			return
 		}
@@ -475,16 +514,65 @@ func (*SessionManager) askMetadataCluster(ctx context.Context, namespaceId strin
 	return listNamespaceResp.Namespaces[0], nil
 }
 
+// sessionReapInterval is how often the expired long-running transactions are rolled back.
+const sessionReapInterval = 10 * time.Second
+
 // sessionTracker is used to track sessions.
 type sessionTracker struct {
 	sync.RWMutex
 
 	sessions map[string]*QuerySession
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
 func newSessionTracker() *sessionTracker {
-	return &sessionTracker{
+	ctx, cancel := context.WithCancel(context.Background())
+
+	tracker := &sessionTracker{
 		sessions: make(map[string]*QuerySession),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	tracker.wg.Add(1)
+	go tracker.reap()
+
+	return tracker
+}
+
+// reap periodically rolls back the expired long-running transactions, so the abandoned ones don't hold their buffer
+// until a new session is added.
+func (tracker *sessionTracker) reap() {
+	defer tracker.wg.Done()
+
+	ticker := time.NewTicker(sessionReapInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			tracker.rollbackExpired()
+		case <-tracker.ctx.Done():
+			return
+		}
+	}
+}
+
+// stop stops the reaper and waits for it to exit.
+func (tracker *sessionTracker) stop() {
+	tracker.cancel()
+	tracker.wg.Wait()
+}
+
+func (tracker *sessionTracker) rollbackExpired() {
+	tracker.Lock()
+	expired := tracker.removeExpired()
+	tracker.Unlock()
+
+	for _, s := range expired {
+		_ = s.Rollback()
 	}
 }
 
@@ -508,3 +596,17 @@ func (tracker *sessionTracker) add(id string, session *QuerySession) {
 
 	tracker.sessions[id] = session
 }
+
+// removeExpired removes the long-running transactions that are past their timeout, these are abandoned by the
+// clients as they can only be rolled back.
+func (tracker *sessionTracker) removeExpired() []*QuerySession {
+	var expired []*QuerySession
+	for id, s := range tracker.sessions {
+		if tx, ok := s.tx.(*transaction.LongTxSession); ok && tx.Expired() {
+			expired = append(expired, s)
+			delete(tracker.sessions, id)
+		}
+	}
+
+	return expired
+}
@@ -22,6 +22,8 @@ import (
 
 func TestSessionTracker(t *testing.T) {
 	s := newSessionTracker()
+	defer s.stop()
+
 	require.Equal(t, 0, len(s.sessions))
 	require.NotNil(t, s.sessions)
 
@@ -0,0 +1,102 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// versionsPerSecond is the rate at which the FoundationDB cluster advances its version.
+	versionsPerSecond = 1000000
+	// snapshotWindow is the MVCC window of FoundationDB, a read version older than this can't be read.
+	snapshotWindow = 5 * time.Second
+)
+
+// readVersionAt returns the read version corresponding to the timestamp "at", given the "current" read version observed
+// at "now". The cluster advances one version per microsecond.
+func readVersionAt(current int64, now time.Time, at time.Time) int64 {
+	return current - now.Sub(at).Microseconds()
+}
+
+// validateReadVersion checks that the requested version can be served by a snapshot read given the current read version.
+func validateReadVersion(current int64, version int64) error {
+	if version > current {
+		return errors.InvalidArgument("read version '%d' is ahead of the current version '%d'", version, current)
+	}
+	if current-version > int64(snapshotWindow.Seconds())*versionsPerSecond {
+		return errors.InvalidArgument("read version '%d' is older than the snapshot window of '%s'", version, snapshotWindow)
+	}
+
+	return nil
+}
+
+// resolveReadVersion returns the read version requested either explicitly as a version or as a timestamp in the recent
+// past. It returns zero if neither is set, which means the read should be performed at the latest version.
+func resolveReadVersion(ctx context.Context, txMgr *transaction.Manager, version int64, ts *timestamppb.Timestamp) (int64, error) {
+	if version == 0 && ts == nil {
+		return 0, nil
+	}
+
+	current, err := currentReadVersion(ctx, txMgr)
+	if err != nil {
+		return 0, err
+	}
+
+	return snapshotVersion(current, time.Now(), version, ts)
+}
+
+// snapshotVersion validates the requested version or timestamp against the "current" read version observed at "now"
+// and returns the version to read at.
+func snapshotVersion(current int64, now time.Time, version int64, ts *timestamppb.Timestamp) (int64, error) {
+	if version != 0 && ts != nil {
+		return 0, errors.InvalidArgument("only one of read version or read timestamp can be set")
+	}
+	if version < 0 {
+		return 0, errors.InvalidArgument("read version '%d' is not valid", version)
+	}
+
+	if ts != nil {
+		if err := ts.CheckValid(); err != nil {
+			return 0, errors.InvalidArgument("read timestamp is not valid: %s", err.Error())
+		}
+		if ts.AsTime().After(now) {
+			return 0, errors.InvalidArgument("read timestamp can't be in the future")
+		}
+		version = readVersionAt(current, now, ts.AsTime())
+	}
+
+	if err := validateReadVersion(current, version); err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// currentReadVersion returns the latest read version of the cluster.
+func currentReadVersion(ctx context.Context, txMgr *transaction.Manager) (int64, error) {
+	tx, err := txMgr.StartTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	return tx.GetReadVersion(ctx)
+}
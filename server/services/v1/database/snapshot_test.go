@@ -0,0 +1,66 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestReadVersionAt(t *testing.T) {
+	now := time.Now()
+
+	require.Equal(t, int64(10000000), readVersionAt(10000000, now, now))
+	require.Equal(t, int64(9000000), readVersionAt(10000000, now, now.Add(-time.Second)))
+	require.Equal(t, int64(9999500), readVersionAt(10000000, now, now.Add(-500*time.Microsecond)))
+}
+
+func TestValidateReadVersion(t *testing.T) {
+	current := int64(100000000)
+
+	require.NoError(t, validateReadVersion(current, current))
+	require.NoError(t, validateReadVersion(current, current-int64(versionsPerSecond)))
+	require.NoError(t, validateReadVersion(current, current-5*int64(versionsPerSecond)))
+	require.Error(t, validateReadVersion(current, current+1))
+	require.Error(t, validateReadVersion(current, current-5*int64(versionsPerSecond)-1))
+}
+
+func TestSnapshotVersion(t *testing.T) {
+	now := time.Now()
+	current := int64(100000000)
+
+	version, err := snapshotVersion(current, now, current-100, nil)
+	require.NoError(t, err)
+	require.Equal(t, current-100, version)
+
+	version, err = snapshotVersion(current, now, 0, timestamppb.New(now.Add(-2*time.Second)))
+	require.NoError(t, err)
+	require.Equal(t, current-2*int64(versionsPerSecond), version)
+
+	_, err = snapshotVersion(current, now, current, timestamppb.New(now))
+	require.Error(t, err)
+
+	_, err = snapshotVersion(current, now, -1, nil)
+	require.Error(t, err)
+
+	_, err = snapshotVersion(current, now, 0, timestamppb.New(now.Add(time.Second)))
+	require.Error(t, err)
+
+	_, err = snapshotVersion(current, now, 0, timestamppb.New(now.Add(-time.Minute)))
+	require.Error(t, err)
+}
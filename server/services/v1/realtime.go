@@ -17,6 +17,7 @@ package v1
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/fullstorydev/grpchan/inprocgrpc"
@@ -26,8 +27,12 @@ import (
 	"github.com/rs/zerolog/log"
 	api "github.com/tigrisdata/tigris/api/server/v1"
 	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/internal"
 	"github.com/tigrisdata/tigris/server/config"
 	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/middleware"
+	"github.com/tigrisdata/tigris/server/outbox"
+	"github.com/tigrisdata/tigris/server/request"
 	"github.com/tigrisdata/tigris/server/services/v1/realtime"
 	"github.com/tigrisdata/tigris/server/transaction"
 	"github.com/tigrisdata/tigris/store/cache"
@@ -38,6 +43,8 @@ import (
 
 const (
 	realtimePathPattern = fullProjectPath + "/realtime/*"
+	// maxHTTPEventSize is the maximum size of an event posted by a device using the SSE or long-poll transport.
+	maxHTTPEventSize = 1 << 20
 )
 
 type realtimeService struct {
@@ -46,21 +53,46 @@ type realtimeService struct {
 	cache     cache.Cache
 	devices   *realtime.Sessions
 	rtmRunner *realtime.RTMRunnerFactory
+	presence  *realtime.Presence
+	webhooks  *realtime.Webhooks
+	relay     *outbox.Relay
 }
 
 func newRealtimeService(_ kv.TxStore, _ search.Store, tenantMgr *metadata.TenantManager, txMgr *transaction.Manager) *realtimeService {
 	cacheS := cache.NewCache(&config.DefaultConfig.Cache)
 	encoder := metadata.NewCacheEncoder()
 	heartbeatF := realtime.NewHeartbeatFactory(cacheS, encoder)
-	channelFactory := realtime.NewChannelFactory(cacheS, encoder, heartbeatF)
+	retention := realtime.NewChannelRetention(txMgr, tenantMgr.GetRealtimeStore())
+	channelFactory := realtime.NewChannelFactory(cacheS, encoder, heartbeatF, retention)
+	rules := realtime.NewChannelRules(txMgr, tenantMgr.GetRealtimeStore())
+	presence := realtime.NewPresence(cacheS, encoder, heartbeatF, channelFactory)
+	webhooks := realtime.NewWebhooks(txMgr, tenantMgr.GetRealtimeStore(), cacheS, channelFactory)
+
+	var relay *outbox.Relay
+	if config.DefaultConfig.Outbox.Enabled {
+		relay = outbox.NewRelay(tenantMgr, txMgr, cacheS, channelFactory)
+		relay.Start()
+	}
 
 	return &realtimeService{
 		cache:     cacheS,
-		rtmRunner: realtime.NewRTMRunnerFactory(cacheS, channelFactory),
-		devices:   realtime.NewSessionMgr(cacheS, tenantMgr, txMgr, heartbeatF, channelFactory),
+		rtmRunner: realtime.NewRTMRunnerFactory(cacheS, channelFactory, rules, retention, presence, webhooks),
+		devices:   realtime.NewSessionMgr(cacheS, tenantMgr, txMgr, heartbeatF, channelFactory, rules, presence),
+		presence:  presence,
+		webhooks:  webhooks,
+		relay:     relay,
 	}
 }
 
+// Stop stops publishing the outbox, delivering the webhooks and sweeping the presence members.
+func (s *realtimeService) Stop() {
+	if s.relay != nil {
+		s.relay.Stop()
+	}
+	s.webhooks.Stop()
+	s.presence.Stop()
+}
+
 func (s *realtimeService) RegisterHTTP(router chi.Router, inproc *inprocgrpc.Channel) error {
 	mux := runtime.NewServeMux(
 		runtime.WithMarshalerOption(runtime.MIMEWildcard, &api.CustomMarshaler{JSONBuiltin: &runtime.JSONBuiltin{}}),
@@ -74,7 +106,18 @@ func (s *realtimeService) RegisterHTTP(router chi.Router, inproc *inprocgrpc.Cha
 
 	api.RegisterRealtimeServer(inproc, s)
 
-	router.HandleFunc(apiPathPrefix+"/projects/{project}/realtime", s.DeviceConnectionHandler)
+	var handler http.Handler = http.HandlerFunc(s.DeviceConnectionHandler)
+	if config.DefaultConfig.Server.Type != config.RealtimeServerType {
+		// the realtime server extracts the metadata and authenticates all its HTTP requests, when the service runs
+		// in the database server only the websocket connections need it as the rest goes through the interceptors
+		handler = middleware.HTTPMetadataExtractorMiddleware(&config.DefaultConfig)(
+			middleware.HTTPAuthMiddleware(&config.DefaultConfig)(handler))
+	}
+	router.Handle(apiPathPrefix+"/projects/{project}/realtime", handler)
+	router.Method(http.MethodGet, apiPathPrefix+"/projects/{project}/realtime/sse", s.authenticated(s.SSEConnectionHandler))
+	router.Method(http.MethodGet, apiPathPrefix+"/projects/{project}/realtime/poll", s.authenticated(s.LongPollHandler))
+	router.Method(http.MethodPost, apiPathPrefix+"/projects/{project}/realtime/sessions/{session}/events",
+		s.authenticated(s.SessionEventsHandler))
 	router.HandleFunc(apiPathPrefix+realtimePathPattern, func(w http.ResponseWriter, r *http.Request) {
 		mux.ServeHTTP(w, r)
 	})
@@ -82,6 +125,20 @@ func (s *realtimeService) RegisterHTTP(router chi.Router, inproc *inprocgrpc.Cha
 	return nil
 }
 
+// authenticated wraps the handlers of the SSE and long-poll connections like the websocket handler, as they don't go
+// through the grpc gateway either.
+func (*realtimeService) authenticated(fn http.HandlerFunc) http.Handler {
+	var handler http.Handler = fn
+	if config.DefaultConfig.Server.Type != config.RealtimeServerType {
+		// the realtime server extracts the metadata and authenticates all its HTTP requests, when the service runs
+		// in the database server only the device connections need it as the rest goes through the interceptors
+		handler = middleware.HTTPMetadataExtractorMiddleware(&config.DefaultConfig)(
+			middleware.HTTPAuthMiddleware(&config.DefaultConfig)(handler))
+	}
+
+	return handler
+}
+
 func (s *realtimeService) RegisterGRPC(grpc *grpc.Server) error {
 	api.RegisterRealtimeServer(grpc, s)
 	return nil
@@ -138,6 +195,154 @@ func (s *realtimeService) DeviceConnectionHandler(w http.ResponseWriter, r *http
 	_ = session.Start(ctx)
 }
 
+// SSEConnectionHandler connects a device streaming its events as server-sent events, the device sends its events by
+// posting them to the session.
+func (s *realtimeService) SSEConnectionHandler(w http.ResponseWriter, r *http.Request) {
+	params := s.extractConnParams(r)
+	conn, err := realtime.NewSSEConn(w)
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	session, err := s.devices.AddDevice(ctx, conn, params)
+	if err != nil {
+		err = realtime.SendReply(conn, params.ToEncodingType(), api.EventType_error, errors.InternalWS(err.Error()))
+		log.Err(err).Msgf("failed to send error msg")
+		_ = conn.Close()
+		return
+	}
+	defer func() {
+		_ = session.Close()
+		s.devices.RemoveDevice(ctx, session)
+	}()
+
+	go func() {
+		// the client going away is the socket dying
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-conn.Done():
+		}
+	}()
+
+	_ = session.SendConnSuccess()
+	_ = session.Start(ctx)
+}
+
+// LongPollHandler returns the events queued for a long-poll session, waiting for them up to the poll timeout. A
+// request without the session id of an existing session connects a new session and returns its connected event.
+func (s *realtimeService) LongPollHandler(w http.ResponseWriter, r *http.Request) {
+	params := s.extractConnParams(r)
+	if len(params.SessionId) > 0 {
+		if session, ok := s.lookupSession(w, r, params.SessionId); ok {
+			s.poll(w, r, session)
+		}
+		return
+	}
+
+	// the session outlives the request, its events are handled with the metadata of the connecting request
+	ctx := context.Background()
+	if md, err := request.GetRequestMetadataFromContext(r.Context()); err == nil {
+		ctx = md.SaveToContext(ctx)
+	}
+
+	conn := realtime.NewPollConn()
+	session, err := s.devices.AddDevice(r.Context(), conn, params)
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	go func() {
+		// returns once the device stops polling
+		_ = session.Start(ctx)
+		_ = session.Close()
+		s.devices.RemoveDevice(ctx, session)
+	}()
+
+	_ = session.SendConnSuccess()
+	s.poll(w, r, session)
+}
+
+func (*realtimeService) poll(w http.ResponseWriter, r *http.Request, session *realtime.Session) {
+	conn, ok := session.PollConn()
+	if !ok {
+		writeHTTPError(w, errors.InvalidArgument("session '%s' is not a long-poll session", session.Id()))
+		return
+	}
+
+	session.Touch()
+	messages, err := conn.Poll(r.Context(), realtime.PollTimeout)
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	jsonEncoding := session.EncodingType() == internal.JsonEncoding
+	if jsonEncoding {
+		w.Header().Set("Content-Type", "application/json")
+	} else {
+		w.Header().Set("Content-Type", "application/x-msgpack")
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(realtime.EncodePollBatch(jsonEncoding, messages))
+}
+
+// SessionEventsHandler hands an event posted by a device using the SSE or long-poll transport to its session, the
+// replies are sent through the transport.
+func (s *realtimeService) SessionEventsHandler(w http.ResponseWriter, r *http.Request) {
+	session, ok := s.lookupSession(w, r, chi.URLParam(r, "session"))
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxHTTPEventSize))
+	if err != nil {
+		writeHTTPError(w, errors.InvalidArgument(err.Error()))
+		return
+	}
+
+	if err = session.Deliver(r.Context(), body); err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// lookupSession returns the session the request of an SSE or long-poll device acts on. The sessions live in the
+// process of the server the device connected to, so the requests of a session must be routed to that server, by a
+// load balancer with sticky sessions on the session id for instance.
+func (s *realtimeService) lookupSession(w http.ResponseWriter, r *http.Request, sessionId string) (*realtime.Session, bool) {
+	namespace, err := request.GetNamespace(r.Context())
+	if err != nil {
+		writeHTTPError(w, err)
+		return nil, false
+	}
+
+	// a session of another project or created by another subject is reported as missing, so its existence isn't
+	// revealed
+	session, ok := s.devices.GetDevice(sessionId)
+	if !ok || !session.BelongsTo(namespace, chi.URLParam(r, "project")) ||
+		!session.OwnedBy(realtime.PrincipalFromContext(r.Context())) {
+		writeHTTPError(w, errors.NotFound("session '%s' not found", sessionId))
+		return nil, false
+	}
+
+	return session, true
+}
+
+func writeHTTPError(w http.ResponseWriter, err error) {
+	switch e := err.(type) {
+	case *api.TigrisError:
+		http.Error(w, e.Message, api.ToHTTPCode(e.Code))
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func (*realtimeService) Ping(_ context.Context, _ *api.HeartbeatEvent) (*api.HeartbeatEvent, error) {
 	return &api.HeartbeatEvent{}, nil
 }
@@ -193,3 +398,111 @@ func (s *realtimeService) ListSubscriptions(ctx context.Context, req *api.ListSu
 	}
 	return resp.Response.(*api.ListSubscriptionResponse), nil
 }
+
+func (s *realtimeService) SetChannelRule(ctx context.Context, req *api.SetChannelRuleRequest) (*api.SetChannelRuleResponse, error) {
+	runner := s.rtmRunner.GetChannelRulesRunner()
+	runner.SetSetReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.SetChannelRuleResponse), nil
+}
+
+func (s *realtimeService) DeleteChannelRule(ctx context.Context, req *api.DeleteChannelRuleRequest) (*api.DeleteChannelRuleResponse, error) {
+	runner := s.rtmRunner.GetChannelRulesRunner()
+	runner.SetDeleteReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.DeleteChannelRuleResponse), nil
+}
+
+func (s *realtimeService) ListChannelRules(ctx context.Context, req *api.ListChannelRulesRequest) (*api.ListChannelRulesResponse, error) {
+	runner := s.rtmRunner.GetChannelRulesRunner()
+	runner.SetListReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.ListChannelRulesResponse), nil
+}
+
+func (s *realtimeService) ChannelHistory(ctx context.Context, req *api.ChannelHistoryRequest) (*api.ChannelHistoryResponse, error) {
+	runner := s.rtmRunner.GetChannelHistoryRunner(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.ChannelHistoryResponse), nil
+}
+
+func (s *realtimeService) SetChannelRetention(ctx context.Context, req *api.SetChannelRetentionRequest) (*api.SetChannelRetentionResponse, error) {
+	runner := s.rtmRunner.GetChannelRetentionRunner()
+	runner.SetSetReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.SetChannelRetentionResponse), nil
+}
+
+func (s *realtimeService) GetChannelRetention(ctx context.Context, req *api.GetChannelRetentionRequest) (*api.GetChannelRetentionResponse, error) {
+	runner := s.rtmRunner.GetChannelRetentionRunner()
+	runner.SetGetReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.GetChannelRetentionResponse), nil
+}
+
+func (s *realtimeService) GetPresence(ctx context.Context, req *api.GetPresenceRequest) (*api.GetPresenceResponse, error) {
+	runner := s.rtmRunner.GetPresenceRunner(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.GetPresenceResponse), nil
+}
+
+func (s *realtimeService) CreateWebhook(ctx context.Context, req *api.CreateWebhookRequest) (*api.CreateWebhookResponse, error) {
+	runner := s.rtmRunner.GetWebhooksRunner()
+	runner.SetCreateReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.CreateWebhookResponse), nil
+}
+
+func (s *realtimeService) DeleteWebhook(ctx context.Context, req *api.DeleteWebhookRequest) (*api.DeleteWebhookResponse, error) {
+	runner := s.rtmRunner.GetWebhooksRunner()
+	runner.SetDeleteReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.DeleteWebhookResponse), nil
+}
+
+func (s *realtimeService) ListWebhooks(ctx context.Context, req *api.ListWebhooksRequest) (*api.ListWebhooksResponse, error) {
+	runner := s.rtmRunner.GetWebhooksRunner()
+	runner.SetListReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.ListWebhooksResponse), nil
+}
@@ -16,9 +16,12 @@ package realtime
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/rs/zerolog/log"
+	api "github.com/tigrisdata/tigris/api/server/v1"
 	"github.com/tigrisdata/tigris/internal"
 	"github.com/tigrisdata/tigris/store/cache"
 )
@@ -26,11 +29,13 @@ import (
 type Channel struct {
 	sync.RWMutex
 
-	encName  string
-	tenant   uint32
-	project  uint32
-	stream   cache.Stream
-	watchers map[string]*ChannelWatcher
+	encName   string
+	name      string
+	tenant    uint32
+	project   uint32
+	stream    cache.Stream
+	retention *ChannelRetention
+	watchers  map[string]*ChannelWatcher
 }
 
 func NewChannel(encName string, stream cache.Stream) *Channel {
@@ -50,11 +55,86 @@ func (ch *Channel) Read(ctx context.Context, pos string) (*cache.StreamMessages,
 }
 
 func (ch *Channel) PublishPresence(ctx context.Context, data *internal.StreamData) (string, error) {
-	return ch.stream.Add(ctx, data)
+	return ch.publish(ctx, data)
 }
 
 func (ch *Channel) PublishMessage(ctx context.Context, data *internal.StreamData) (string, error) {
-	return ch.stream.Add(ctx, data)
+	return ch.publish(ctx, data)
+}
+
+// PublishSequenced publishes the message only if it is the next one of the sequence counted under the guard key, see
+// cache.Stream.AddSequenced. It returns false if the message was published before.
+func (ch *Channel) PublishSequenced(ctx context.Context, data *internal.StreamData, guardTable string, guardKey string, seq int64) (bool, error) {
+	_, added, err := ch.stream.AddSequenced(ctx, data, guardTable, guardKey, seq)
+	if err != nil || !added {
+		return false, err
+	}
+
+	if err = ch.trimOnPublish(ctx); err != nil {
+		log.Err(err).Str("channel", ch.encName).Msg("applying retention failed")
+	}
+
+	return true, nil
+}
+
+func (ch *Channel) publish(ctx context.Context, data *internal.StreamData) (string, error) {
+	id, err := ch.stream.Add(ctx, data)
+	if err != nil {
+		return "", err
+	}
+
+	if err = ch.trimOnPublish(ctx); err != nil {
+		// the message is published, trimming is retried on the next publish
+		log.Err(err).Str("channel", ch.encName).Msg("applying retention failed")
+	}
+
+	return id, nil
+}
+
+// trimOnPublish bounds the channel with a single approximate trim, by count of messages if the policy has one and by
+// age otherwise. The channel may exceed the policy by a few messages until ApplyRetention trims it exactly.
+func (ch *Channel) trimOnPublish(ctx context.Context) error {
+	policy, err := ch.RetentionPolicy(ctx)
+	if err != nil || policy == nil {
+		return err
+	}
+
+	if policy.MaxMessages > 0 {
+		return ch.stream.TrimApprox(ctx, policy.MaxMessages, "")
+	}
+	return ch.stream.TrimApprox(ctx, 0, retentionMinID(policy))
+}
+
+func retentionMinID(policy *api.ChannelRetention) string {
+	if policy.MaxAgeMs == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d-0", time.Now().UnixMilli()-policy.MaxAgeMs)
+}
+
+// RetentionPolicy returns the retention policy of the channel, nil if the channel has no policy.
+func (ch *Channel) RetentionPolicy(ctx context.Context) (*api.ChannelRetention, error) {
+	if ch.retention == nil {
+		return nil, nil
+	}
+
+	return ch.retention.Get(ctx, ch.tenant, ch.project, ch.name)
+}
+
+// ApplyRetention trims exactly the messages of the channel exceeding its retention policy. It returns true if the
+// channel has a policy of its own, the history of such a channel is kept after the devices left.
+func (ch *Channel) ApplyRetention(ctx context.Context) (bool, error) {
+	if ch.retention == nil {
+		return false, nil
+	}
+
+	policy, own, err := ch.retention.lookup(ctx, ch.tenant, ch.project, ch.name)
+	if err != nil || policy == nil {
+		return false, err
+	}
+
+	_, err = ch.stream.Trim(ctx, policy.MaxMessages, retentionMinID(policy))
+	return own, err
 }
 
 func (ch *Channel) getWatcher(watcher string) *ChannelWatcher {
@@ -38,16 +38,21 @@ type Session struct {
 	socketId     string
 	closed       bool
 	encType      internal.UserDataEncType
-	conn         *websocket.Conn
+	conn         Conn
 	lastReceived time.Time
 	chFactory    *ChannelFactory
+	rules        *ChannelRules
+	presence     *Presence
+	principal    *Principal
 	heartbeat    *HeartbeatTable
 	tenant       *metadata.Tenant
 	project      *metadata.Project
 	watchers     map[string]*ChannelWatcher
+	// entered are the channels the session is a presence member of
+	entered map[string]struct{}
 }
 
-func (s *Sessions) CreateDeviceSession(ctx context.Context, conn *websocket.Conn, params ConnectionParams) (*Session, error) {
+func (s *Sessions) CreateDeviceSession(ctx context.Context, conn Conn, params ConnectionParams) (*Session, error) {
 	sessionId := params.SessionId
 	if len(sessionId) == 0 {
 		sessionId = uuid.NewUUIDAsString()
@@ -89,11 +94,58 @@ func (s *Sessions) CreateDeviceSession(ctx context.Context, conn *websocket.Conn
 		project:   proj,
 		encType:   params.ToEncodingType(),
 		chFactory: s.channelFactory,
+		rules:     s.rules,
+		presence:  s.presence,
+		principal: PrincipalFromContext(ctx),
 		watchers:  make(map[string]*ChannelWatcher),
+		entered:   make(map[string]struct{}),
 		heartbeat: s.heartbeatFactory.GetHeartbeatTable(tenant.GetNamespace().Id(), proj.Id()),
 	}, nil
 }
 
+func (session *Session) Id() string {
+	return session.id
+}
+
+func (session *Session) EncodingType() internal.UserDataEncType {
+	return session.encType
+}
+
+// Touch records activity of a device using a transport without inbound messages, like a long-poll request.
+func (session *Session) Touch() {
+	session.lastReceived = time.Now()
+	_ = session.heartbeat.Ping(session.id)
+}
+
+// Deliver hands a message posted over HTTP to the session, only the HTTP transports accept them.
+func (session *Session) Deliver(ctx context.Context, message []byte) error {
+	conn, ok := session.conn.(interface {
+		Deliver(ctx context.Context, msg []byte) error
+	})
+	if !ok {
+		return errors.InvalidArgument("session '%s' doesn't accept events over HTTP", session.id)
+	}
+
+	return conn.Deliver(ctx, message)
+}
+
+// PollConn returns the connection of a long-poll session.
+func (session *Session) PollConn() (*PollConn, bool) {
+	conn, ok := session.conn.(*PollConn)
+	return conn, ok
+}
+
+// BelongsTo returns true if the session is connected to the project of the namespace.
+func (session *Session) BelongsTo(namespace string, project string) bool {
+	return session.tenant.GetNamespace().StrId() == namespace && session.project.Name() == project
+}
+
+// OwnedBy returns true if the principal is the one which created the session, the requests of the HTTP transports
+// acting on an existing session must come from the same subject.
+func (session *Session) OwnedBy(principal *Principal) bool {
+	return session.principal.Claims["sub"] == principal.Claims["sub"]
+}
+
 func (session *Session) IsActive() bool {
 	return time.Since(session.lastReceived) <= 30*time.Second
 }
@@ -126,6 +178,10 @@ func (session *Session) Close() error {
 	for _, w := range session.watchers {
 		w.Disconnect()
 	}
+	// the socket is gone, the other members are notified the session left
+	for channel := range session.entered {
+		session.leave(context.TODO(), channel)
+	}
 
 	return session.conn.Close()
 }
@@ -179,6 +235,7 @@ func (session *Session) handleMessage(ctx context.Context, req *api.RealTimeMess
 			watcher := session.watchers[event.Channel]
 			watcher.Disconnect()
 			delete(session.watchers, event.Channel)
+			session.leave(ctx, event.Channel)
 		} else {
 			if err := session.Close(); err != nil {
 				return errors.InternalWS(err.Error())
@@ -197,11 +254,23 @@ func (session *Session) handleMessage(ctx context.Context, req *api.RealTimeMess
 			return errors.InternalWS("expecting 'attach' event")
 		}
 
+		if errEvent := session.authorize(ctx, event.Channel, ActionSubscribe, ActionPublish, ActionPresence); errEvent != nil {
+			return errEvent
+		}
+
 		// create a channel if it doesn't exist
 		_, err := session.chFactory.GetOrCreateChannel(ctx, session.tenant.GetNamespace().Id(), session.project.Id(), event.Channel)
 		if err != nil {
 			return errors.InternalWS(err.Error())
 		}
+
+		// attaching enters the presence of the channel if the rules allow the session to be a member
+		if session.authorize(ctx, event.Channel, ActionPresence) == nil {
+			if err = session.presence.Enter(ctx, session.tenant.GetNamespace().Id(), session.project.Id(), event.Channel, session.id, session.socketId); err != nil {
+				return errors.InternalWS(err.Error())
+			}
+			session.entered[event.Channel] = struct{}{}
+		}
 		return nil
 	case api.EventType_detach:
 		event, ok := decoded.(*api.DetachEvent)
@@ -213,6 +282,7 @@ func (session *Session) handleMessage(ctx context.Context, req *api.RealTimeMess
 			watcher.Disconnect()
 			delete(session.watchers, event.Channel)
 		}
+		session.leave(ctx, event.Channel)
 		return nil
 	case api.EventType_unsubscribe:
 		event, ok := decoded.(*api.UnsubscribeEvent)
@@ -231,6 +301,10 @@ func (session *Session) handleMessage(ctx context.Context, req *api.RealTimeMess
 			return errors.InternalWS("expecting 'subscribe' event")
 		}
 
+		if errEvent := session.authorize(ctx, event.Channel, ActionSubscribe); errEvent != nil {
+			return errEvent
+		}
+
 		channel, err := session.chFactory.GetChannel(ctx, session.tenant.GetNamespace().Id(), session.project.Id(), event.Channel)
 		if err != nil {
 			return errors.InternalWS(err.Error())
@@ -258,6 +332,10 @@ func (session *Session) handleMessage(ctx context.Context, req *api.RealTimeMess
 			return errors.InternalWS("expecting message event")
 		}
 
+		if errEvent := session.authorize(ctx, event.Channel, ActionPublish); errEvent != nil {
+			return errEvent
+		}
+
 		ch, err := session.chFactory.GetChannel(ctx, session.tenant.GetNamespace().Id(), session.project.Id(), event.Channel)
 		if err != nil {
 			return errors.InternalWS(err.Error())
@@ -272,30 +350,123 @@ func (session *Session) handleMessage(ctx context.Context, req *api.RealTimeMess
 		}
 		return nil
 	case api.EventType_presence_member:
-		event, ok := decoded.(*api.MessageEvent)
+		event, ok := decoded.(*api.PresenceMemberEvent)
 		if !ok {
 			return errors.InternalWS("expecting presence event")
 		}
 
-		ch, err := session.chFactory.GetChannel(ctx, session.tenant.GetNamespace().Id(), session.project.Id(), event.Channel)
+		if errEvent := session.authorize(ctx, event.Channel, ActionPresence); errEvent != nil {
+			return errEvent
+		}
+
+		if event.Name == PresenceLeave {
+			session.leave(ctx, event.Channel)
+			return nil
+		}
+
+		if err := session.presence.Update(ctx, session.tenant.GetNamespace().Id(), session.project.Id(), event.Channel, session.id, session.socketId, session.encType, event.Data); err != nil {
+			return errors.InternalWS(err.Error())
+		}
+		session.entered[event.Channel] = struct{}{}
+	case api.EventType_history:
+		event, ok := decoded.(*api.HistoryEvent)
+		if !ok {
+			return errors.InternalWS("expecting history event")
+		}
+
+		if errEvent := session.authorize(ctx, event.Channel, ActionSubscribe); errEvent != nil {
+			return errEvent
+		}
+
+		return session.sendHistory(ctx, event)
+	default:
+		// ToDo: presence channel subscribe by adding a different watcher name
+	}
+	return nil
+}
+
+// sendHistory replies to the history event with a page of the retained messages of the channel.
+func (session *Session) sendHistory(ctx context.Context, event *api.HistoryEvent) *api.ErrorEvent {
+	ch, err := session.chFactory.GetChannel(ctx, session.tenant.GetNamespace().Id(), session.project.Id(), event.Channel)
+	if err != nil {
+		return errors.InternalWS(err.Error())
+	}
+
+	resp, next, err := ch.History(ctx, &HistoryQuery{
+		Start:       event.Start,
+		End:         event.End,
+		StartTimeMs: event.StartTimeMs,
+		EndTimeMs:   event.EndTimeMs,
+		Limit:       event.Limit,
+		Reverse:     event.Reverse,
+	})
+	if err != nil {
+		return errors.InternalWS(err.Error())
+	}
+
+	messages := make([]*api.MessageEvent, 0, len(resp.Messages))
+	for _, m := range resp.Messages {
+		data, err := resp.Decode(m)
 		if err != nil {
 			return errors.InternalWS(err.Error())
 		}
 
-		streamData, err := NewPresenceData(session.encType, session.clientId, session.socketId, event.Name, event)
+		md, err := DecodeStreamMD(data.Md)
 		if err != nil {
 			return errors.InternalWS(err.Error())
 		}
-		if _, err := ch.PublishPresence(ctx, streamData); err != nil {
+		if md.DataType != MessageChannelData {
+			continue
+		}
+
+		rawData, err := SanitizeUserData(session.encType, data)
+		if err != nil {
 			return errors.InternalWS(err.Error())
 		}
-	default:
-		// ToDo: presence channel subscribe by adding a different watcher name
+
+		messages = append(messages, &api.MessageEvent{
+			Id:      m.ID,
+			Name:    md.EventName,
+			Channel: event.Channel,
+			Data:    rawData,
+		})
 	}
+
+	err = SendReply(session.conn, session.encType, api.EventType_history, &api.HistoryResultEvent{
+		Channel:  event.Channel,
+		Messages: messages,
+		Next:     next,
+	})
+	log.Err(err).Msgf("failed to send history message")
 	return nil
 }
 
-func SendReply(conn *websocket.Conn, encType internal.UserDataEncType, eventType api.EventType, event proto.Message) error {
+// leave removes the session from the presence members of the channel.
+func (session *Session) leave(ctx context.Context, channel string) {
+	if _, ok := session.entered[channel]; !ok {
+		return
+	}
+
+	delete(session.entered, channel)
+	if err := session.presence.Leave(ctx, session.tenant.GetNamespace().Id(), session.project.Id(), channel, session.id, session.socketId); err != nil {
+		log.Err(err).Str("channel", channel).Msg("leaving presence failed")
+	}
+}
+
+// authorize checks the channel rules of the project allow the session any of the actions on the channel.
+func (session *Session) authorize(ctx context.Context, channel string, actions ...string) *api.ErrorEvent {
+	err := session.rules.Authorize(ctx, session.tenant.GetNamespace().Id(), session.project.Id(), session.principal, channel, actions...)
+	if err == nil {
+		return nil
+	}
+
+	if e, ok := err.(*api.TigrisError); ok && e.Code == api.Code_PERMISSION_DENIED {
+		return errors.PermissionDeniedWS(e.Message)
+	}
+	return errors.InternalWS(err.Error())
+}
+
+func SendReply(conn Conn, encType internal.UserDataEncType, eventType api.EventType, event proto.Message) error {
 	encEvent, err := EncodeEvent(encType, event)
 	if err != nil {
 		panic(err)
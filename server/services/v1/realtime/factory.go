@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/tigrisdata/tigris/internal"
 	"github.com/tigrisdata/tigris/server/metadata"
 	"github.com/tigrisdata/tigris/store/cache"
 )
@@ -32,14 +33,16 @@ type ChannelFactory struct {
 	cache      cache.Cache
 	encoder    metadata.CacheEncoder
 	heartbeatF *HeartbeatFactory
+	retention  *ChannelRetention
 	channels   map[string]*Channel
 }
 
-func NewChannelFactory(cache cache.Cache, encoder metadata.CacheEncoder, heartbeatF *HeartbeatFactory) *ChannelFactory {
+func NewChannelFactory(cache cache.Cache, encoder metadata.CacheEncoder, heartbeatF *HeartbeatFactory, retention *ChannelRetention) *ChannelFactory {
 	factory := &ChannelFactory{
 		cache:      cache,
 		encoder:    encoder,
 		heartbeatF: heartbeatF,
+		retention:  retention,
 		channels:   make(map[string]*Channel),
 	}
 
@@ -58,6 +61,17 @@ func (factory *ChannelFactory) monitorStreams() {
 }
 
 func (factory *ChannelFactory) deleteChannelIfInactive(c *Channel) error {
+	// the history of a channel having its own retention policy is kept after the devices left, the policy bounds its
+	// size. The default policy of the project only bounds the channels, the inactive ones are still deleted.
+	retained, err := c.ApplyRetention(context.TODO())
+	if err != nil {
+		log.Err(err).Str("channel", c.encName).Msg("applying retention failed")
+		return err
+	}
+	if retained {
+		return nil
+	}
+
 	groups, err := c.stream.GetConsumerGroups(context.TODO())
 	if err != nil {
 		log.Err(err).Str("channel", c.encName).Msg("reading consumers failed")
@@ -66,6 +80,10 @@ func (factory *ChannelFactory) deleteChannelIfInactive(c *Channel) error {
 
 	groupsName := make([]string, len(groups))
 	for i, g := range groups {
+		if isWebhookGroup(g.Name) {
+			// a channel having webhooks is kept, the webhooks are subscribed without a device being connected
+			return nil
+		}
 		groupsName[i] = g.Name
 	}
 
@@ -85,13 +103,23 @@ func (factory *ChannelFactory) getChannel(encStream string) (*Channel, bool) {
 	return ch, ok
 }
 
-func (factory *ChannelFactory) getOrCreateChannelFromCache(ctx context.Context, encStream string) (*Channel, error) {
+func (factory *ChannelFactory) newChannel(encStream string, tenantId uint32, projId uint32, channelName string, stream cache.Stream) *Channel {
+	ch := NewChannel(encStream, stream)
+	ch.name = channelName
+	ch.tenant = tenantId
+	ch.project = projId
+	ch.retention = factory.retention
+
+	return ch
+}
+
+func (factory *ChannelFactory) getOrCreateChannelFromCache(ctx context.Context, encStream string, tenantId uint32, projId uint32, channelName string) (*Channel, error) {
 	stream, err := factory.cache.CreateOrGetStream(ctx, encStream)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewChannel(encStream, stream), nil
+	return factory.newChannel(encStream, tenantId, projId, channelName, stream), nil
 }
 
 func (factory *ChannelFactory) ListChannels(ctx context.Context, tenantId uint32, projId uint32, prefix string) ([]string, error) {
@@ -131,7 +159,7 @@ func (factory *ChannelFactory) GetChannel(ctx context.Context, tenantId uint32,
 		return nil, err
 	}
 
-	ch := NewChannel(encStream, stream)
+	ch := factory.newChannel(encStream, tenantId, projId, channelName, stream)
 
 	factory.Lock()
 	factory.channels[encStream] = ch
@@ -152,7 +180,7 @@ func (factory *ChannelFactory) GetOrCreateChannel(ctx context.Context, tenantId
 	factory.Lock()
 	defer factory.Unlock()
 
-	ch, err := factory.getOrCreateChannelFromCache(ctx, encStream)
+	ch, err := factory.getOrCreateChannelFromCache(ctx, encStream, tenantId, projId, channelName)
 	if err != nil {
 		return nil, err
 	}
@@ -161,6 +189,25 @@ func (factory *ChannelFactory) GetOrCreateChannel(ctx context.Context, tenantId
 	return ch, nil
 }
 
+// PublishSequenced publishes a JSON message to the channel on behalf of the server, creating the channel if needed. It
+// is used by the outbox relay to deliver the messages enqueued by database transactions exactly once, the message is
+// only published if seq is the number of messages published so far under the guard key of the guard table.
+func (factory *ChannelFactory) PublishSequenced(ctx context.Context, tenantId uint32, projId uint32, channelName string,
+	name string, data []byte, guardTable string, guardKey string, seq int64,
+) (bool, error) {
+	ch, err := factory.GetOrCreateChannel(ctx, tenantId, projId, channelName)
+	if err != nil {
+		return false, err
+	}
+
+	streamData, err := newStreamData(MessageChannelData, internal.JsonEncoding, "", "", name, data)
+	if err != nil {
+		return false, err
+	}
+
+	return ch.PublishSequenced(ctx, streamData, guardTable, guardKey, seq)
+}
+
 // CreateChannel will throw an error if stream already exists. Use CreateOrGet to create if not exists primitive.
 func (factory *ChannelFactory) CreateChannel(ctx context.Context, tenantId uint32, projId uint32, channelName string) (*Channel, error) {
 	encStream, err := factory.encoder.EncodeCacheTableName(tenantId, projId, channelName)
@@ -179,7 +226,7 @@ func (factory *ChannelFactory) CreateChannel(ctx context.Context, tenantId uint3
 		return nil, err
 	}
 
-	ch := NewChannel(encStream, stream)
+	ch := factory.newChannel(encStream, tenantId, projId, channelName, stream)
 	factory.channels[ch.encName] = ch
 	return ch, nil
 }
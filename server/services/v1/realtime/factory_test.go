@@ -56,8 +56,8 @@ func TestFactory(t *testing.T) {
 	})
 }
 
-func newFactory(_ *testing.T) *ChannelFactory {
+func newFactory(t *testing.T) *ChannelFactory {
 	cacheS := cache.NewCache(config.GetTestCacheConfig())
 	encoder := metadata.NewCacheEncoder()
-	return NewChannelFactory(cacheS, encoder, NewHeartbeatFactory(cacheS, encoder))
+	return NewChannelFactory(cacheS, encoder, NewHeartbeatFactory(cacheS, encoder), NewChannelRetention(newTestRealtimeStore(t, 2, 3)))
 }
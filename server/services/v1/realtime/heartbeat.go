@@ -101,3 +101,13 @@ func (h *HeartbeatTable) GroupsExpired(groupsName []string) bool {
 
 	return false
 }
+
+// Expired returns true if the session didn't send a heartbeat within the expiry time.
+func (h *HeartbeatTable) Expired(ctx context.Context, sessionId string) (bool, error) {
+	count, err := h.cache.Exists(ctx, h.tableName, sessionId)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 0, nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/store/cache"
+)
+
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+// HistoryQuery selects a page of the messages of a channel. The range is bounded by message ids or by timestamps in
+// milliseconds, both bounds are inclusive and an unset bound is the beginning or the end of the channel.
+type HistoryQuery struct {
+	Start       string
+	End         string
+	StartTimeMs int64
+	EndTimeMs   int64
+	Limit       int64
+	Reverse     bool
+}
+
+func (q *HistoryQuery) validate() error {
+	if len(q.Start) > 0 && q.StartTimeMs > 0 {
+		return errors.InvalidArgument("only one of start and start_time can be set")
+	}
+	if len(q.End) > 0 && q.EndTimeMs > 0 {
+		return errors.InvalidArgument("only one of end and end_time can be set")
+	}
+	if q.Limit < 0 || q.Limit > maxHistoryLimit {
+		return errors.InvalidArgument("limit should be between 0 and %d", maxHistoryLimit)
+	}
+
+	return nil
+}
+
+func (q *HistoryQuery) bounds() (string, string) {
+	start, end := q.Start, q.End
+	switch {
+	case q.StartTimeMs > 0:
+		start = fmt.Sprintf("%d-0", q.StartTimeMs)
+	case len(start) == 0:
+		start = "-"
+	}
+	switch {
+	case q.EndTimeMs > 0:
+		// an id without sequence includes all the messages of that millisecond
+		end = strconv.FormatInt(q.EndTimeMs, 10)
+	case len(end) == 0:
+		end = "+"
+	}
+
+	return start, end
+}
+
+func (q *HistoryQuery) limit() int64 {
+	if q.Limit == 0 {
+		return defaultHistoryLimit
+	}
+	return q.Limit
+}
+
+// History returns a page of the messages of the channel and the position of the next page, the position is the start
+// of the next page or the end of it if the query is in reverse order. The position is empty on the last page.
+func (ch *Channel) History(ctx context.Context, q *HistoryQuery) (*cache.StreamMessages, string, error) {
+	if err := q.validate(); err != nil {
+		return nil, "", err
+	}
+
+	start, end := q.bounds()
+	limit := q.limit()
+	messages, err := ch.stream.Range(ctx, start, end, limit, q.Reverse)
+	if err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if int64(len(messages.Messages)) == limit {
+		next = nextHistoryPosition(messages.Messages[len(messages.Messages)-1].ID, q.Reverse)
+	}
+
+	return messages, next, nil
+}
+
+// nextHistoryPosition returns the id following the message id, or preceding it in reverse order, so that the next
+// page doesn't repeat the last message of the previous one.
+func nextHistoryPosition(id string, reverse bool) string {
+	msStr, seqStr, _ := strings.Cut(id, "-")
+	ms, err := strconv.ParseUint(msStr, 10, 64)
+	if err != nil {
+		return ""
+	}
+	seq, err := strconv.ParseUint(seqStr, 10, 64)
+	if err != nil {
+		return ""
+	}
+
+	if !reverse {
+		if seq == math.MaxUint64 {
+			return fmt.Sprintf("%d-0", ms+1)
+		}
+		return fmt.Sprintf("%d-%d", ms, seq+1)
+	}
+
+	switch {
+	case seq > 0:
+		return fmt.Sprintf("%d-%d", ms, seq-1)
+	case ms > 0:
+		return fmt.Sprintf("%d-%d", ms-1, uint64(math.MaxUint64))
+	default:
+		return ""
+	}
+}
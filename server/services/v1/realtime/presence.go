@@ -0,0 +1,395 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog/log"
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/store/cache"
+)
+
+const (
+	// presenceChannelsKey is the sorted set of the tracked channels, keyed by the time they were last swept.
+	presenceChannelsKey = "channels"
+	// presenceSweepDuration is how often the members of the channels are checked for expired heartbeats, the members
+	// of a session that died without closing its socket leave after the heartbeat expiry and at most this duration.
+	presenceSweepDuration = 30 * time.Second
+	// presenceSweepBatch is the maximum number of channels swept at once by a server.
+	presenceSweepBatch = 100
+)
+
+const (
+	PresenceEnter  = "enter"
+	PresenceUpdate = "update"
+	PresenceLeave  = "leave"
+)
+
+// Presence maintains the members of the channels. A session becomes a member when it attaches to a channel and stops
+// being one when it detaches, disconnects or its heartbeat expires. Every change is published to the channel as a
+// presence event so that the subscribers can follow the members.
+//
+// The channels having members are tracked in a sorted set of the cache store with the time they were last swept, so
+// any server sweeps the channels whose sessions died, whichever server they were connected to. The members are
+// identified by an opaque id derived from their session, the session id itself allows resuming the session so it is
+// never exposed to the other devices.
+type Presence struct {
+	cache      cache.Cache
+	encoder    metadata.CacheEncoder
+	heartbeatF *HeartbeatFactory
+	chFactory  *ChannelFactory
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// storedMember is the value of a member in the cache store, the session is kept to check its heartbeat.
+type storedMember struct {
+	Member    *api.PresenceMember `json:"member"`
+	SessionId string              `json:"session_id"`
+}
+
+func NewPresence(cache cache.Cache, encoder metadata.CacheEncoder, heartbeatF *HeartbeatFactory, chFactory *ChannelFactory) *Presence {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	presence := &Presence{
+		cache:      cache,
+		encoder:    encoder,
+		heartbeatF: heartbeatF,
+		chFactory:  chFactory,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	presence.wg.Add(1)
+	go presence.monitorMembers()
+
+	return presence
+}
+
+// Stop stops the background sweeping of the members and waits for it to exit.
+func (p *Presence) Stop() {
+	p.cancel()
+	p.wg.Wait()
+}
+
+// memberId returns the opaque id of the member of the session.
+func memberId(sessionId string) string {
+	h := sha256.Sum256([]byte(sessionId))
+	return hex.EncodeToString(h[:16])
+}
+
+func trackedChannel(tenantId uint32, projId uint32, channel string) string {
+	return fmt.Sprintf("%d:%d:%s", tenantId, projId, channel)
+}
+
+func parseTrackedChannel(tracked string) (uint32, uint32, string, bool) {
+	parts := strings.SplitN(tracked, ":", 3)
+	if len(parts) != 3 {
+		return 0, 0, "", false
+	}
+
+	tenantId, err1 := strconv.ParseUint(parts[0], 10, 32)
+	projId, err2 := strconv.ParseUint(parts[1], 10, 32)
+	if err1 != nil || err2 != nil {
+		return 0, 0, "", false
+	}
+
+	return uint32(tenantId), uint32(projId), parts[2], true
+}
+
+func (p *Presence) monitorMembers() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(presenceSweepDuration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.sweepDue(p.ctx, time.Now()); err != nil && p.ctx.Err() == nil {
+				log.Err(err).Msg("sweeping presence members failed")
+			}
+		case <-p.ctx.Done():
+			return
+		}
+	}
+}
+
+func (p *Presence) channelsTable() (string, error) {
+	return p.encoder.EncodeInternalCacheTableName(0, 0, metadata.PresenceChannelsCacheTable)
+}
+
+// track adds the channel to the tracked channels, a channel already tracked keeps the time it was last swept so
+// that the updates of its members don't delay its sweep.
+func (p *Presence) track(ctx context.Context, tenantId uint32, projId uint32, channel string) error {
+	tableName, err := p.channelsTable()
+	if err != nil {
+		return err
+	}
+
+	tracked := trackedChannel(tenantId, projId, channel)
+	if _, err = p.cache.ZRank(ctx, tableName, presenceChannelsKey, tracked, false); err != cache.ErrKeyNotFound {
+		return err
+	}
+
+	_, err = p.cache.ZAdd(ctx, tableName, presenceChannelsKey, cache.ZMember{Member: tracked, Score: float64(time.Now().UnixMilli())})
+	return err
+}
+
+// sweepDue sweeps the tracked channels which were not swept during the last presenceSweepDuration. The channels are
+// marked as swept before being swept, so that the other servers skip them.
+func (p *Presence) sweepDue(ctx context.Context, now time.Time) error {
+	tableName, err := p.channelsTable()
+	if err != nil {
+		return err
+	}
+
+	due, err := p.cache.ZRangeByScore(ctx, tableName, presenceChannelsKey, math.Inf(-1),
+		float64(now.Add(-presenceSweepDuration).UnixMilli()), presenceSweepBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range due {
+		tenantId, projId, channel, ok := parseTrackedChannel(d.Member)
+		if !ok {
+			_, _ = p.cache.ZRem(ctx, tableName, presenceChannelsKey, d.Member)
+			continue
+		}
+
+		if _, err = p.cache.ZAdd(ctx, tableName, presenceChannelsKey, cache.ZMember{Member: d.Member, Score: float64(now.UnixMilli())}); err != nil {
+			return err
+		}
+
+		remaining, err := p.sweep(ctx, tenantId, projId, channel)
+		if err != nil {
+			continue
+		}
+		if remaining == 0 {
+			_, _ = p.cache.ZRem(ctx, tableName, presenceChannelsKey, d.Member)
+		}
+	}
+
+	return nil
+}
+
+func (p *Presence) tableName(tenantId uint32, projId uint32) (string, error) {
+	return p.encoder.EncodeInternalCacheTableName(tenantId, projId, metadata.PresenceCacheTable)
+}
+
+// Enter adds the session to the members of the channel, it does nothing if the session is already a member.
+func (p *Presence) Enter(ctx context.Context, tenantId uint32, projId uint32, channel string, sessionId string, socketId string) error {
+	tableName, err := p.tableName(tenantId, projId)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.cache.HGet(ctx, tableName, channel, memberId(sessionId))
+	if err == nil {
+		return nil
+	}
+	if err != cache.ErrKeyNotFound {
+		return err
+	}
+
+	return p.set(ctx, tenantId, projId, tableName, channel, sessionId, socketId, PresenceEnter, internal.JsonEncoding, nil)
+}
+
+// Update sets the data of the member, the session enters the channel if it isn't a member yet.
+func (p *Presence) Update(ctx context.Context, tenantId uint32, projId uint32, channel string, sessionId string, socketId string, encType internal.UserDataEncType, data []byte) error {
+	tableName, err := p.tableName(tenantId, projId)
+	if err != nil {
+		return err
+	}
+
+	return p.set(ctx, tenantId, projId, tableName, channel, sessionId, socketId, PresenceUpdate, encType, data)
+}
+
+func (p *Presence) set(ctx context.Context, tenantId uint32, projId uint32, tableName string, channel string, sessionId string, socketId string, action string, encType internal.UserDataEncType, data []byte) error {
+	member := &api.PresenceMember{
+		Id:          memberId(sessionId),
+		UpdatedAtMs: time.Now().UnixMilli(),
+	}
+	if len(data) > 0 {
+		var err error
+		// members are stored as JSON to be returned as-is by the API
+		if member.Data, err = SanitizeUserData(internal.JsonEncoding, internal.NewStreamData(encType, nil, data)); err != nil {
+			return err
+		}
+	}
+
+	enc, err := jsoniter.Marshal(&storedMember{Member: member, SessionId: sessionId})
+	if err != nil {
+		return err
+	}
+
+	added, err := p.cache.HSet(ctx, tableName, channel, map[string]*internal.CacheData{
+		member.Id: internal.NewCacheData(enc),
+	})
+	if err != nil {
+		return err
+	}
+	if added > 0 {
+		action = PresenceEnter
+	}
+
+	if err = p.track(ctx, tenantId, projId, channel); err != nil {
+		return err
+	}
+
+	ch, err := p.chFactory.GetOrCreateChannel(ctx, tenantId, projId, channel)
+	if err != nil {
+		return err
+	}
+
+	return p.publish(ctx, ch, member.Id, socketId, action, member.Data)
+}
+
+// Leave removes the session from the members of the channel, it does nothing if the session isn't a member.
+func (p *Presence) Leave(ctx context.Context, tenantId uint32, projId uint32, channel string, sessionId string, socketId string) error {
+	tableName, err := p.tableName(tenantId, projId)
+	if err != nil {
+		return err
+	}
+
+	id := memberId(sessionId)
+	existing, err := p.cache.HGet(ctx, tableName, channel, id)
+	if err == cache.ErrKeyNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	var stored storedMember
+	if err = jsoniter.Unmarshal(existing.RawData, &stored); err == nil && stored.Member != nil {
+		data = stored.Member.Data
+	}
+
+	removed, err := p.cache.HDel(ctx, tableName, channel, id)
+	if err != nil || removed == 0 {
+		// a concurrent leave already published the event
+		return err
+	}
+
+	ch, err := p.chFactory.GetChannel(ctx, tenantId, projId, channel)
+	if err != nil {
+		// the channel is deleted, there is no one to notify
+		return nil
+	}
+
+	return p.publish(ctx, ch, id, socketId, PresenceLeave, data)
+}
+
+func (*Presence) publish(ctx context.Context, ch *Channel, memberId string, socketId string, action string, data []byte) error {
+	streamData, err := NewPresenceData(internal.JsonEncoding, memberId, socketId, action, data)
+	if err != nil {
+		return err
+	}
+
+	_, err = ch.PublishPresence(ctx, streamData)
+	return err
+}
+
+// Members returns the members of the channel ordered by id.
+func (p *Presence) Members(ctx context.Context, tenantId uint32, projId uint32, channel string) ([]*api.PresenceMember, error) {
+	stored, err := p.members(ctx, tenantId, projId, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]*api.PresenceMember, len(stored))
+	for i, m := range stored {
+		members[i] = m.Member
+	}
+
+	return members, nil
+}
+
+func (p *Presence) members(ctx context.Context, tenantId uint32, projId uint32, channel string) ([]*storedMember, error) {
+	tableName, err := p.tableName(tenantId, projId)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := p.cache.HGetAll(ctx, tableName, channel)
+	if err == cache.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]*storedMember, 0, len(fields))
+	for _, f := range fields {
+		var member storedMember
+		if err = jsoniter.Unmarshal(f.RawData, &member); err != nil {
+			return nil, err
+		}
+		if member.Member != nil {
+			members = append(members, &member)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Member.Id < members[j].Member.Id
+	})
+
+	return members, nil
+}
+
+// sweep removes the members of the channel whose session heartbeat expired, a leave event is published for each of
+// them. It returns the number of members remaining.
+func (p *Presence) sweep(ctx context.Context, tenantId uint32, projId uint32, channel string) (int, error) {
+	members, err := p.members(ctx, tenantId, projId, channel)
+	if err != nil {
+		log.Err(err).Str("channel", channel).Msg("reading presence members failed")
+		return 0, err
+	}
+
+	heartbeat := p.heartbeatF.GetHeartbeatTable(tenantId, projId)
+	remaining := 0
+	for _, m := range members {
+		expired, err := heartbeat.Expired(ctx, m.SessionId)
+		if err != nil {
+			return 0, err
+		}
+		if !expired {
+			remaining++
+			continue
+		}
+
+		if err = p.Leave(ctx, tenantId, projId, channel, m.SessionId, ""); err != nil {
+			log.Err(err).Str("channel", channel).Str("member", m.Member.Id).Msg("removing expired member failed")
+			return 0, err
+		}
+	}
+
+	return remaining, nil
+}
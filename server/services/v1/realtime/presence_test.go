@@ -0,0 +1,96 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/store/cache"
+)
+
+func TestPresence(t *testing.T) {
+	ctx := context.TODO()
+	factory := newFactory(t)
+	presence := NewPresence(factory.cache, factory.encoder, factory.heartbeatF, factory)
+	defer presence.Stop()
+
+	channel, err := factory.GetOrCreateChannel(ctx, 1, 2, "room")
+	require.NoError(t, err)
+	defer factory.DeleteChannel(ctx, channel)
+	defer func() {
+		for _, id := range []string{"s1", "s2"} {
+			_ = presence.Leave(ctx, 1, 2, "room", id, "")
+		}
+	}()
+
+	require.NoError(t, factory.heartbeatF.GetHeartbeatTable(1, 2).Ping("s1"))
+
+	require.NoError(t, presence.Enter(ctx, 1, 2, "room", "s1", "socket1"))
+	require.NoError(t, presence.Update(ctx, 1, 2, "room", "s2", "socket2", internal.JsonEncoding, []byte(`{"name":"b"}`)))
+	require.NoError(t, presence.Update(ctx, 1, 2, "room", "s2", "socket2", internal.JsonEncoding, []byte(`{"name":"c"}`)))
+	// entering again is a no-op
+	require.NoError(t, presence.Enter(ctx, 1, 2, "room", "s1", "socket1"))
+
+	s1, s2 := memberId("s1"), memberId("s2")
+	members, err := presence.Members(ctx, 1, 2, "room")
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	byId := map[string]*api.PresenceMember{members[0].Id: members[0], members[1].Id: members[1]}
+	// the session ids are not exposed
+	require.Empty(t, byId[s1].Data)
+	require.JSONEq(t, `{"name":"c"}`, string(byId[s2].Data))
+
+	// the channel isn't due yet, s2 never sent a heartbeat
+	require.NoError(t, presence.sweepDue(ctx, time.Now()))
+	members, err = presence.Members(ctx, 1, 2, "room")
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	require.NoError(t, presence.sweepDue(ctx, time.Now().Add(presenceSweepDuration)))
+	members, err = presence.Members(ctx, 1, 2, "room")
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	require.Equal(t, s1, members[0].Id)
+
+	require.NoError(t, presence.Leave(ctx, 1, 2, "room", "s1", "socket1"))
+	members, err = presence.Members(ctx, 1, 2, "room")
+	require.NoError(t, err)
+	require.Empty(t, members)
+
+	// the channel without members is no longer tracked once swept
+	require.NoError(t, presence.sweepDue(ctx, time.Now().Add(2*presenceSweepDuration)))
+	tableName, err := presence.channelsTable()
+	require.NoError(t, err)
+	_, err = factory.cache.ZRank(ctx, tableName, presenceChannelsKey, trackedChannel(1, 2, "room"), false)
+	require.Equal(t, cache.ErrKeyNotFound, err)
+
+	messages, _, err := channel.History(ctx, &HistoryQuery{})
+	require.NoError(t, err)
+
+	var events []string
+	for _, m := range messages.Messages {
+		data, err := messages.Decode(m)
+		require.NoError(t, err)
+		md, err := DecodeStreamMD(data.Md)
+		require.NoError(t, err)
+		require.Equal(t, PresenceChannelData, md.DataType)
+		events = append(events, md.ClientId+":"+md.EventName)
+	}
+	require.Equal(t, []string{s1 + ":enter", s2 + ":enter", s2 + ":update", s2 + ":leave", s1 + ":leave"}, events)
+}
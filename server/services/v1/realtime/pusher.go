@@ -15,7 +15,6 @@
 package realtime
 
 import (
-	"github.com/gorilla/websocket"
 	"github.com/rs/zerolog/log"
 	api "github.com/tigrisdata/tigris/api/server/v1"
 	"github.com/tigrisdata/tigris/internal"
@@ -27,7 +26,7 @@ type DevicePusher struct {
 	sessionId  string
 	socketId   string
 	encType    internal.UserDataEncType
-	connection *websocket.Conn
+	connection Conn
 }
 
 func NewDevicePusher(session *Session, channel string) *DevicePusher {
@@ -73,6 +72,20 @@ func (pusher *DevicePusher) sendMessage(msgId string, md *StreamMessageMD, data
 		return
 	}
 
+	if md.DataType == PresenceChannelData {
+		member := &api.PresenceMemberEvent{
+			Id:       msgId,
+			Name:     md.EventName,
+			Channel:  pusher.channel,
+			MemberId: md.ClientId,
+			Data:     rawData,
+		}
+
+		err = SendReply(pusher.connection, pusher.encType, api.EventType_presence_member, member)
+		log.Err(err).Msgf("failed to push presence")
+		return
+	}
+
 	message := &api.MessageEvent{
 		Id:      msgId,
 		Name:    md.EventName,
@@ -0,0 +1,94 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"context"
+
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/transaction"
+)
+
+const (
+	// projectRetentionName is the name of the project default policy, it can't collide with a channel name as channel
+	// names are not glob patterns.
+	projectRetentionName = "*"
+)
+
+const (
+	RetentionSetStatus = "set"
+)
+
+// ChannelRetention stores the retention policies of the channels of a project in the metadata. A policy bounds the
+// channel stream by count of messages and by age, a channel without its own policy uses the default policy of the
+// project and the messages of a channel without any policy are kept as long as the channel exists.
+type ChannelRetention struct {
+	settings *projectSettings[api.ChannelRetention]
+}
+
+func NewChannelRetention(txMgr *transaction.Manager, store *metadata.RealtimeSubspace) *ChannelRetention {
+	return &ChannelRetention{
+		settings: newProjectSettings[api.ChannelRetention](metadata.RealtimeChannelRetention, txMgr, store),
+	}
+}
+
+func isRetentionEmpty(policy *api.ChannelRetention) bool {
+	return policy == nil || (policy.MaxMessages == 0 && policy.MaxAgeMs == 0)
+}
+
+func retentionName(channel string) string {
+	if len(channel) == 0 {
+		return projectRetentionName
+	}
+	return channel
+}
+
+// Set sets the retention policy of the channel, or the default policy of the project if the channel is empty. An empty
+// policy removes the existing one.
+func (r *ChannelRetention) Set(ctx context.Context, tenantId uint32, projId uint32, channel string, policy *api.ChannelRetention) error {
+	if policy != nil && (policy.MaxMessages < 0 || policy.MaxAgeMs < 0) {
+		return errors.InvalidArgument("max_messages and max_age_ms of the retention can't be negative")
+	}
+
+	if isRetentionEmpty(policy) {
+		_, err := r.settings.delete(ctx, tenantId, projId, retentionName(channel))
+		return err
+	}
+
+	return r.settings.put(ctx, tenantId, projId, map[string]*api.ChannelRetention{retentionName(channel): policy})
+}
+
+// Get returns the retention policy applied to the channel, the default policy of the project if the channel has none.
+// It returns nil if neither the channel nor the project have a policy.
+func (r *ChannelRetention) Get(ctx context.Context, tenantId uint32, projId uint32, channel string) (*api.ChannelRetention, error) {
+	policy, _, err := r.lookup(ctx, tenantId, projId, channel)
+	return policy, err
+}
+
+// lookup returns the retention policy applied to the channel and whether the policy is the channel's own policy rather
+// than the default policy of the project.
+func (r *ChannelRetention) lookup(ctx context.Context, tenantId uint32, projId uint32, channel string) (*api.ChannelRetention, bool, error) {
+	policies, err := r.settings.get(ctx, tenantId, projId)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if policy, ok := policies[retentionName(channel)]; ok {
+		return policy, len(channel) > 0, nil
+	}
+	return policies[projectRetentionName], false, nil
+}
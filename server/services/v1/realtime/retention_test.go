@@ -0,0 +1,145 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/internal"
+)
+
+func TestChannelRetention(t *testing.T) {
+	ctx := context.TODO()
+	factory := newFactory(t)
+	retention := factory.retention
+
+	policy, err := retention.Get(ctx, 1, 2, "chat")
+	require.NoError(t, err)
+	require.Nil(t, policy)
+
+	require.NoError(t, retention.Set(ctx, 1, 2, "", &api.ChannelRetention{MaxMessages: 10}))
+	require.NoError(t, retention.Set(ctx, 1, 2, "chat", &api.ChannelRetention{MaxMessages: 3, MaxAgeMs: 60000}))
+	defer func() {
+		_ = retention.Set(ctx, 1, 2, "", nil)
+		_ = retention.Set(ctx, 1, 2, "chat", nil)
+	}()
+
+	policy, err = retention.Get(ctx, 1, 2, "chat")
+	require.NoError(t, err)
+	require.Equal(t, int64(3), policy.MaxMessages)
+	require.Equal(t, int64(60000), policy.MaxAgeMs)
+
+	// the project default applies to the channels without a policy
+	policy, err = retention.Get(ctx, 1, 2, "other")
+	require.NoError(t, err)
+	require.Equal(t, int64(10), policy.MaxMessages)
+
+	// policies are per project
+	policy, err = retention.Get(ctx, 1, 3, "chat")
+	require.NoError(t, err)
+	require.Nil(t, policy)
+
+	require.Error(t, retention.Set(ctx, 1, 2, "chat", &api.ChannelRetention{MaxMessages: -1}))
+
+	t.Run("trim_on_publish", func(t *testing.T) {
+		channel, err := factory.GetOrCreateChannel(ctx, 1, 2, "chat")
+		require.NoError(t, err)
+		defer factory.DeleteChannel(ctx, channel)
+
+		var ids []string
+		for i := 0; i < 5; i++ {
+			id, err := channel.PublishMessage(ctx, newTestMessage(t, i))
+			require.NoError(t, err)
+			ids = append(ids, id)
+		}
+
+		// the trim on publish is approximate, the channel is trimmed exactly by the monitor
+		retained, err := channel.ApplyRetention(ctx)
+		require.NoError(t, err)
+		require.True(t, retained)
+
+		messages, next, err := channel.History(ctx, &HistoryQuery{})
+		require.NoError(t, err)
+		require.Empty(t, next)
+		require.Len(t, messages.Messages, 3)
+		require.Equal(t, ids[2], messages.Messages[0].ID)
+	})
+	t.Run("project_default", func(t *testing.T) {
+		channel, err := factory.GetOrCreateChannel(ctx, 1, 2, "other")
+		require.NoError(t, err)
+		defer factory.DeleteChannel(ctx, channel)
+
+		// the project default bounds the channel but doesn't keep its history once the devices left
+		retained, err := channel.ApplyRetention(ctx)
+		require.NoError(t, err)
+		require.False(t, retained)
+	})
+	t.Run("history_pages", func(t *testing.T) {
+		channel, err := factory.GetOrCreateChannel(ctx, 1, 2, "history")
+		require.NoError(t, err)
+		defer factory.DeleteChannel(ctx, channel)
+
+		var ids []string
+		for i := 0; i < 5; i++ {
+			id, err := channel.PublishMessage(ctx, newTestMessage(t, i))
+			require.NoError(t, err)
+			ids = append(ids, id)
+		}
+
+		messages, next, err := channel.History(ctx, &HistoryQuery{Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, messages.Messages, 2)
+		require.Equal(t, ids[1], messages.Messages[1].ID)
+		require.NotEmpty(t, next)
+
+		messages, next, err = channel.History(ctx, &HistoryQuery{Start: next, End: ids[3], Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, messages.Messages, 2)
+		require.Equal(t, ids[2], messages.Messages[0].ID)
+		require.Equal(t, ids[3], messages.Messages[1].ID)
+		require.NotEmpty(t, next)
+
+		messages, _, err = channel.History(ctx, &HistoryQuery{Limit: 2, Reverse: true})
+		require.NoError(t, err)
+		require.Len(t, messages.Messages, 2)
+		require.Equal(t, ids[4], messages.Messages[0].ID)
+		require.Equal(t, ids[3], messages.Messages[1].ID)
+
+		_, _, err = channel.History(ctx, &HistoryQuery{Start: ids[0], StartTimeMs: 1})
+		require.Error(t, err)
+		_, _, err = channel.History(ctx, &HistoryQuery{Limit: maxHistoryLimit + 1})
+		require.Error(t, err)
+	})
+}
+
+func TestNextHistoryPosition(t *testing.T) {
+	require.Equal(t, "10-3", nextHistoryPosition("10-2", false))
+	require.Equal(t, "10-1", nextHistoryPosition("10-2", true))
+	require.Equal(t, "9-18446744073709551615", nextHistoryPosition("10-0", true))
+	require.Equal(t, "11-0", nextHistoryPosition("10-18446744073709551615", false))
+	require.Equal(t, "", nextHistoryPosition("0-0", true))
+}
+
+func newTestMessage(t *testing.T, i int) *internal.StreamData {
+	data, err := NewEventDataFromMessage(internal.JsonEncoding, "", "", "msg", &api.Message{
+		Data: []byte(fmt.Sprintf(`{"i": %d}`, i)),
+	})
+	require.NoError(t, err)
+	return data
+}
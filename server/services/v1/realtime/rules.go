@@ -0,0 +1,242 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"context"
+	"path"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/request"
+	"github.com/tigrisdata/tigris/server/transaction"
+)
+
+const (
+	// rulesEnforcedName is the name of the setting marking a project whose rules are enforced, it can't collide with
+	// a rule as the channel pattern of a rule can't be empty.
+	rulesEnforcedName = ""
+)
+
+const (
+	RuleSetStatus     = "set"
+	RuleDeletedStatus = "deleted"
+)
+
+const (
+	// CustomClaimPrefix namespaces the custom claims of the access token in the claims of the principal, so they can't
+	// shadow the claims set by Tigris.
+	CustomClaimPrefix = "claims."
+)
+
+const (
+	ActionPublish   = "publish"
+	ActionSubscribe = "subscribe"
+	ActionPresence  = "presence"
+)
+
+// Principal is the identity the channel rules are evaluated against, it is extracted from the access token of the
+// device connection.
+type Principal struct {
+	Role   string
+	Claims map[string]string
+}
+
+// PrincipalFromContext returns the principal of the access token of the request, a principal without role or claims if
+// the request is not authenticated. The custom claims of the token are prefixed with CustomClaimPrefix.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	token, err := request.GetAccessToken(ctx)
+	if err != nil {
+		return &Principal{}
+	}
+
+	claims := make(map[string]string, len(token.Claims)+2)
+	for k, v := range token.Claims {
+		claims[CustomClaimPrefix+k] = v
+	}
+	claims["sub"] = token.Sub
+	claims["project"] = token.Project
+
+	return &Principal{
+		Role:   token.Role,
+		Claims: claims,
+	}
+}
+
+// ChannelRules controls the access of the device sessions to the channels of a project. A rule is keyed by a glob
+// pattern of the channel names and grants actions to roles or token claims. A project which never had rules allows all
+// the actions. Once a rule is set the rules of the project are enforced: an action on a channel is only allowed if a
+// grant of a matching rule allows it, and removing all the rules denies all the actions. The rules are stored in the
+// metadata, an action is denied if the rules can't be read.
+type ChannelRules struct {
+	settings *projectSettings[api.ChannelRule]
+}
+
+func NewChannelRules(txMgr *transaction.Manager, store *metadata.RealtimeSubspace) *ChannelRules {
+	return &ChannelRules{
+		settings: newProjectSettings[api.ChannelRule](metadata.RealtimeChannelRules, txMgr, store),
+	}
+}
+
+func validateChannelRule(rule *api.ChannelRule) error {
+	if rule == nil || len(rule.Channel) == 0 {
+		return errors.InvalidArgument("channel pattern of the rule can't be empty")
+	}
+	if _, err := path.Match(rule.Channel, ""); err != nil {
+		return errors.InvalidArgument("invalid channel pattern '%s'", rule.Channel)
+	}
+	if len(rule.Grants) == 0 {
+		return errors.InvalidArgument("rule of channel '%s' must have at least one grant", rule.Channel)
+	}
+
+	for _, g := range rule.Grants {
+		if len(g.Actions) == 0 {
+			return errors.InvalidArgument("grant of channel '%s' must have at least one action", rule.Channel)
+		}
+		for _, a := range g.Actions {
+			if a != ActionPublish && a != ActionSubscribe && a != ActionPresence {
+				return errors.InvalidArgument("unsupported action '%s', expecting '%s', '%s' or '%s'", a,
+					ActionPublish, ActionSubscribe, ActionPresence)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Set adds the rule or replaces the rule having the same channel pattern.
+func (r *ChannelRules) Set(ctx context.Context, tenantId uint32, projId uint32, rule *api.ChannelRule) error {
+	if err := validateChannelRule(rule); err != nil {
+		return err
+	}
+
+	return r.settings.put(ctx, tenantId, projId, map[string]*api.ChannelRule{
+		rule.Channel:      rule,
+		rulesEnforcedName: {},
+	})
+}
+
+// Delete removes the rule of the channel pattern, it returns false if there is no such rule.
+func (r *ChannelRules) Delete(ctx context.Context, tenantId uint32, projId uint32, channel string) (bool, error) {
+	if len(channel) == 0 {
+		return false, errors.InvalidArgument("channel pattern of the rule can't be empty")
+	}
+
+	return r.settings.delete(ctx, tenantId, projId, channel)
+}
+
+// List returns the rules of the project ordered by channel pattern.
+func (r *ChannelRules) List(ctx context.Context, tenantId uint32, projId uint32) ([]*api.ChannelRule, error) {
+	values, err := r.settings.read(ctx, tenantId, projId)
+	if err != nil {
+		return nil, err
+	}
+
+	return sortedRules(values), nil
+}
+
+func sortedRules(values map[string]*api.ChannelRule) []*api.ChannelRule {
+	rules := make([]*api.ChannelRule, 0, len(values))
+	for name, rule := range values {
+		if name != rulesEnforcedName {
+			rules = append(rules, rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Channel < rules[j].Channel
+	})
+
+	return rules
+}
+
+// Authorize returns a permission denied error if the principal isn't allowed to perform any of the actions on the
+// channel.
+func (r *ChannelRules) Authorize(ctx context.Context, tenantId uint32, projId uint32, principal *Principal, channel string, actions ...string) error {
+	rules, err := r.settings.get(ctx, tenantId, projId)
+	if err != nil {
+		log.Err(err).Uint32("project", projId).Msg("failed to read the channel rules")
+		return errors.Unavailable("channel rules can't be read, access to channel '%s' is denied", channel)
+	}
+	if _, enforced := rules[rulesEnforcedName]; !enforced {
+		return nil
+	}
+
+	for _, action := range actions {
+		if isAllowed(rules, principal, channel, action) {
+			return nil
+		}
+	}
+
+	if len(actions) == 1 {
+		return errors.PermissionDenied("'%s' is not allowed on channel '%s'", actions[0], channel)
+	}
+	return errors.PermissionDenied("access to channel '%s' is not allowed", channel)
+}
+
+// isAllowed returns true if a grant of a rule matching the channel allows the action. The marker of the enforced rules
+// has no channel pattern, so it doesn't match any channel.
+func isAllowed(rules map[string]*api.ChannelRule, principal *Principal, channel string, action string) bool {
+	for _, rule := range rules {
+		if matched, _ := path.Match(rule.Channel, channel); !matched {
+			continue
+		}
+
+		for _, g := range rule.Grants {
+			if grantMatches(g, principal) && containsAction(g.Actions, action) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// grantMatches returns true if the principal has one of the roles and all the claims of the grant. A grant without
+// roles and claims applies to everyone, including the devices connected without an access token.
+func grantMatches(g *api.ChannelGrant, principal *Principal) bool {
+	if len(g.Roles) > 0 {
+		found := false
+		for _, role := range g.Roles {
+			if role == principal.Role {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for claim, expected := range g.Claims {
+		if actual, ok := principal.Claims[claim]; !ok || len(actual) == 0 || actual != expected {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsAction(actions []string, action string) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,174 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/server/config"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/request"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/server/types"
+	"github.com/tigrisdata/tigris/store/kv"
+	ulog "github.com/tigrisdata/tigris/util/log"
+)
+
+var kvStore kv.TxStore
+
+// newTestRealtimeStore returns the store of the settings of the test, the settings of its projects are removed once
+// the test completes.
+func newTestRealtimeStore(t *testing.T, projIds ...uint32) (*transaction.Manager, *metadata.RealtimeSubspace) {
+	txMgr := transaction.NewManager(kvStore)
+	store := metadata.NewRealtimeStore(&metadata.NameRegistry{RealtimeSB: "test_realtime_" + t.Name()})
+
+	t.Cleanup(func() {
+		ctx := context.TODO()
+		tx, err := txMgr.StartTx(ctx)
+		require.NoError(t, err)
+		for _, projId := range projIds {
+			require.NoError(t, store.DeleteProject(ctx, tx, 1, projId))
+		}
+		require.NoError(t, tx.Commit(ctx))
+	})
+
+	return txMgr, store
+}
+
+func TestChannelRules(t *testing.T) {
+	ctx := context.TODO()
+	rules := NewChannelRules(newTestRealtimeStore(t, 2, 3))
+
+	owner := &Principal{Role: "o", Claims: map[string]string{"sub": "admin"}}
+	user := &Principal{Role: "e", Claims: map[string]string{"sub": "user1"}}
+	anonymous := &Principal{}
+
+	// no rules allow everything
+	require.NoError(t, rules.Authorize(ctx, 1, 2, anonymous, "chat.room1", ActionPublish))
+
+	require.NoError(t, rules.Set(ctx, 1, 2, &api.ChannelRule{
+		Channel: "chat.*",
+		Grants: []*api.ChannelGrant{
+			{Actions: []string{ActionSubscribe}},
+			{Roles: []string{"o"}, Actions: []string{ActionPublish, ActionPresence}},
+		},
+	}))
+	require.NoError(t, rules.Set(ctx, 1, 2, &api.ChannelRule{
+		Channel: "user.*",
+		Grants: []*api.ChannelGrant{
+			{Claims: map[string]string{"sub": "user1"}, Actions: []string{ActionPublish, ActionSubscribe}},
+		},
+	}))
+
+	require.NoError(t, rules.Authorize(ctx, 1, 2, anonymous, "chat.room1", ActionSubscribe))
+	require.Error(t, rules.Authorize(ctx, 1, 2, anonymous, "chat.room1", ActionPublish))
+	require.Error(t, rules.Authorize(ctx, 1, 2, user, "chat.room1", ActionPresence))
+	require.NoError(t, rules.Authorize(ctx, 1, 2, owner, "chat.room1", ActionPresence))
+	require.NoError(t, rules.Authorize(ctx, 1, 2, user, "user.inbox", ActionPublish))
+	require.Error(t, rules.Authorize(ctx, 1, 2, owner, "user.inbox", ActionPublish))
+	// any of the actions
+	require.NoError(t, rules.Authorize(ctx, 1, 2, anonymous, "chat.room1", ActionPublish, ActionSubscribe))
+	// channels not matching any rule are denied
+	require.Error(t, rules.Authorize(ctx, 1, 2, owner, "other", ActionSubscribe))
+	// rules are per project
+	require.NoError(t, rules.Authorize(ctx, 1, 3, anonymous, "other", ActionPublish))
+
+	list, err := rules.List(ctx, 1, 2)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, "chat.*", list[0].Channel)
+	require.Equal(t, "user.*", list[1].Channel)
+
+	deleted, err := rules.Delete(ctx, 1, 2, "user.*")
+	require.NoError(t, err)
+	require.True(t, deleted)
+	deleted, err = rules.Delete(ctx, 1, 2, "user.*")
+	require.NoError(t, err)
+	require.False(t, deleted)
+	require.Error(t, rules.Authorize(ctx, 1, 2, user, "user.inbox", ActionPublish))
+
+	// removing all the rules denies everything
+	deleted, err = rules.Delete(ctx, 1, 2, "chat.*")
+	require.NoError(t, err)
+	require.True(t, deleted)
+	list, err = rules.List(ctx, 1, 2)
+	require.NoError(t, err)
+	require.Empty(t, list)
+	require.Error(t, rules.Authorize(ctx, 1, 2, owner, "chat.room1", ActionSubscribe))
+	require.NoError(t, rules.Authorize(ctx, 1, 3, anonymous, "chat.room1", ActionSubscribe))
+
+	for _, invalid := range []*api.ChannelRule{
+		{Channel: ""},
+		{Channel: "chat.[", Grants: []*api.ChannelGrant{{Actions: []string{ActionPublish}}}},
+		{Channel: "chat.*"},
+		{Channel: "chat.*", Grants: []*api.ChannelGrant{{}}},
+		{Channel: "chat.*", Grants: []*api.ChannelGrant{{Actions: []string{"write"}}}},
+	} {
+		require.Error(t, rules.Set(ctx, 1, 2, invalid))
+	}
+}
+
+func TestChannelRules_CustomClaims(t *testing.T) {
+	ctx := context.TODO()
+	rules := NewChannelRules(newTestRealtimeStore(t, 4))
+
+	require.NoError(t, rules.Set(ctx, 1, 4, &api.ChannelRule{
+		Channel: "team.*",
+		Grants: []*api.ChannelGrant{
+			{Claims: map[string]string{CustomClaimPrefix + "team": "red"}, Actions: []string{ActionSubscribe}},
+		},
+	}))
+
+	principalOf := func(token *types.AccessToken) *Principal {
+		md := &request.Metadata{}
+		md.SetAccessToken(token)
+		return PrincipalFromContext(context.WithValue(ctx, request.MetadataCtxKey{}, md))
+	}
+
+	red := principalOf(&types.AccessToken{Sub: "user1", Project: "p1", Claims: map[string]string{"team": "red"}})
+	blue := principalOf(&types.AccessToken{Sub: "user2", Project: "p1", Claims: map[string]string{"team": "blue"}})
+	// custom claims can't shadow the claims set by Tigris
+	shadow := principalOf(&types.AccessToken{Sub: "user3", Claims: map[string]string{"sub": "user1"}})
+
+	require.Equal(t, map[string]string{"sub": "user1", "project": "p1", "claims.team": "red"}, red.Claims)
+	require.Equal(t, "user3", shadow.Claims["sub"])
+	require.Equal(t, "user1", shadow.Claims["claims.sub"])
+
+	require.NoError(t, rules.Authorize(ctx, 1, 4, red, "team.red", ActionSubscribe))
+	require.Error(t, rules.Authorize(ctx, 1, 4, blue, "team.red", ActionSubscribe))
+	require.Error(t, rules.Authorize(ctx, 1, 4, principalOf(nil), "team.red", ActionSubscribe))
+	require.Error(t, rules.Authorize(ctx, 1, 4, &Principal{Claims: map[string]string{"team": "red"}}, "team.red", ActionSubscribe))
+}
+
+func TestMain(m *testing.M) {
+	ulog.Configure(ulog.LogConfig{Level: "disabled"})
+
+	fdbCfg, err := config.GetTestFDBConfig("../../../..")
+	if err != nil {
+		panic(fmt.Sprintf("failed to init FDB config: %v", err))
+	}
+
+	kvStore, err = kv.NewBuilder().Build(fdbCfg)
+	if err != nil {
+		panic(fmt.Sprintf("failed to init FDB KV %v", err))
+	}
+
+	os.Exit(m.Run())
+}
@@ -33,15 +33,23 @@ type RTMRunner interface {
 }
 
 type RTMRunnerFactory struct {
-	cache   cache.Cache
-	factory *ChannelFactory
+	cache     cache.Cache
+	factory   *ChannelFactory
+	rules     *ChannelRules
+	retention *ChannelRetention
+	presence  *Presence
+	webhooks  *Webhooks
 }
 
 // NewRTMRunnerFactory returns RTMRunnerFactory object.
-func NewRTMRunnerFactory(cache cache.Cache, factory *ChannelFactory) *RTMRunnerFactory {
+func NewRTMRunnerFactory(cache cache.Cache, factory *ChannelFactory, rules *ChannelRules, retention *ChannelRetention, presence *Presence, webhooks *Webhooks) *RTMRunnerFactory {
 	return &RTMRunnerFactory{
-		cache:   cache,
-		factory: factory,
+		cache:     cache,
+		factory:   factory,
+		rules:     rules,
+		retention: retention,
+		presence:  presence,
+		webhooks:  webhooks,
 	}
 }
 
@@ -66,6 +74,42 @@ func (f *RTMRunnerFactory) GetChannelRunner() *ChannelRunner {
 	}
 }
 
+func (f *RTMRunnerFactory) GetChannelRulesRunner() *ChannelRulesRunner {
+	return &ChannelRulesRunner{
+		baseRunner: newBaseRunner(f.cache, f.factory),
+		rules:      f.rules,
+	}
+}
+
+func (f *RTMRunnerFactory) GetChannelHistoryRunner(r *api.ChannelHistoryRequest) *ChannelHistoryRunner {
+	return &ChannelHistoryRunner{
+		baseRunner: newBaseRunner(f.cache, f.factory),
+		req:        r,
+	}
+}
+
+func (f *RTMRunnerFactory) GetChannelRetentionRunner() *ChannelRetentionRunner {
+	return &ChannelRetentionRunner{
+		baseRunner: newBaseRunner(f.cache, f.factory),
+		retention:  f.retention,
+	}
+}
+
+func (f *RTMRunnerFactory) GetPresenceRunner(r *api.GetPresenceRequest) *PresenceRunner {
+	return &PresenceRunner{
+		baseRunner: newBaseRunner(f.cache, f.factory),
+		presence:   f.presence,
+		req:        r,
+	}
+}
+
+func (f *RTMRunnerFactory) GetWebhooksRunner() *WebhooksRunner {
+	return &WebhooksRunner{
+		baseRunner: newBaseRunner(f.cache, f.factory),
+		webhooks:   f.webhooks,
+	}
+}
+
 type baseRunner struct {
 	cache   cache.Cache
 	factory *ChannelFactory
@@ -293,3 +337,304 @@ func (runner *ChannelRunner) Run(ctx context.Context, tenant *metadata.Tenant) (
 		}, nil
 	}
 }
+
+// ChannelRulesRunner is to manage the rules controlling the access of the devices to the channels of a project.
+type ChannelRulesRunner struct {
+	*baseRunner
+
+	rules     *ChannelRules
+	setReq    *api.SetChannelRuleRequest
+	deleteReq *api.DeleteChannelRuleRequest
+	listReq   *api.ListChannelRulesRequest
+}
+
+func (runner *ChannelRulesRunner) SetSetReq(req *api.SetChannelRuleRequest) {
+	runner.setReq = req
+}
+
+func (runner *ChannelRulesRunner) SetDeleteReq(req *api.DeleteChannelRuleRequest) {
+	runner.deleteReq = req
+}
+
+func (runner *ChannelRulesRunner) SetListReq(req *api.ListChannelRulesRequest) {
+	runner.listReq = req
+}
+
+func (runner *ChannelRulesRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	switch {
+	case runner.setReq != nil:
+		project, err := runner.getProject(tenant, runner.setReq.Project)
+		if err != nil {
+			return Response{}, err
+		}
+
+		if err = runner.rules.Set(ctx, tenant.GetNamespace().Id(), project.Id(), runner.setReq.Rule); err != nil {
+			return Response{}, err
+		}
+
+		return Response{
+			Response: &api.SetChannelRuleResponse{
+				Status: RuleSetStatus,
+			},
+		}, nil
+	case runner.deleteReq != nil:
+		project, err := runner.getProject(tenant, runner.deleteReq.Project)
+		if err != nil {
+			return Response{}, err
+		}
+
+		deleted, err := runner.rules.Delete(ctx, tenant.GetNamespace().Id(), project.Id(), runner.deleteReq.Channel)
+		if err != nil {
+			return Response{}, err
+		}
+		if !deleted {
+			return Response{}, errors.NotFound("rule of channel '%s' not present", runner.deleteReq.Channel)
+		}
+
+		return Response{
+			Response: &api.DeleteChannelRuleResponse{
+				Status: RuleDeletedStatus,
+			},
+		}, nil
+	default:
+		project, err := runner.getProject(tenant, runner.listReq.Project)
+		if err != nil {
+			return Response{}, err
+		}
+
+		rules, err := runner.rules.List(ctx, tenant.GetNamespace().Id(), project.Id())
+		if err != nil {
+			return Response{}, err
+		}
+
+		return Response{
+			Response: &api.ListChannelRulesResponse{
+				Rules: rules,
+			},
+		}, nil
+	}
+}
+
+// ChannelHistoryRunner is to read a page of the retained messages of a channel.
+type ChannelHistoryRunner struct {
+	*baseRunner
+
+	req *api.ChannelHistoryRequest
+}
+
+func (runner *ChannelHistoryRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	project, err := runner.getProject(tenant, runner.req.Project)
+	if err != nil {
+		return Response{}, err
+	}
+
+	channel, err := runner.factory.GetChannel(ctx, tenant.GetNamespace().Id(), project.Id(), runner.req.Channel)
+	if err != nil {
+		return Response{}, err
+	}
+
+	resp, next, err := channel.History(ctx, &HistoryQuery{
+		Start:       runner.req.Start,
+		End:         runner.req.End,
+		StartTimeMs: runner.req.StartTimeMs,
+		EndTimeMs:   runner.req.EndTimeMs,
+		Limit:       runner.req.Limit,
+		Reverse:     runner.req.Reverse,
+	})
+	if err != nil {
+		return Response{}, err
+	}
+
+	messages := make([]*api.Message, 0, len(resp.Messages))
+	for _, m := range resp.Messages {
+		data, err := resp.Decode(m)
+		if err != nil {
+			return Response{}, err
+		}
+
+		md, err := DecodeStreamMD(data.Md)
+		if err != nil {
+			return Response{}, err
+		}
+		if md.DataType != MessageChannelData {
+			continue
+		}
+
+		rawData, err := SanitizeUserData(internal.JsonEncoding, data)
+		if err != nil {
+			return Response{}, err
+		}
+
+		id := m.ID
+		messages = append(messages, &api.Message{
+			Id:   &id,
+			Name: md.EventName,
+			Data: rawData,
+		})
+	}
+
+	return Response{
+		Response: &api.ChannelHistoryResponse{
+			Messages: messages,
+			Next:     next,
+		},
+	}, nil
+}
+
+// ChannelRetentionRunner is to manage the retention policies of the channels of a project.
+type ChannelRetentionRunner struct {
+	*baseRunner
+
+	retention *ChannelRetention
+	setReq    *api.SetChannelRetentionRequest
+	getReq    *api.GetChannelRetentionRequest
+}
+
+func (runner *ChannelRetentionRunner) SetSetReq(req *api.SetChannelRetentionRequest) {
+	runner.setReq = req
+}
+
+func (runner *ChannelRetentionRunner) SetGetReq(req *api.GetChannelRetentionRequest) {
+	runner.getReq = req
+}
+
+func (runner *ChannelRetentionRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	switch {
+	case runner.setReq != nil:
+		project, err := runner.getProject(tenant, runner.setReq.Project)
+		if err != nil {
+			return Response{}, err
+		}
+
+		if err = runner.retention.Set(ctx, tenant.GetNamespace().Id(), project.Id(), runner.setReq.Channel, runner.setReq.Retention); err != nil {
+			return Response{}, err
+		}
+
+		return Response{
+			Response: &api.SetChannelRetentionResponse{
+				Status: RetentionSetStatus,
+			},
+		}, nil
+	default:
+		project, err := runner.getProject(tenant, runner.getReq.Project)
+		if err != nil {
+			return Response{}, err
+		}
+
+		policy, err := runner.retention.Get(ctx, tenant.GetNamespace().Id(), project.Id(), runner.getReq.Channel)
+		if err != nil {
+			return Response{}, err
+		}
+
+		return Response{
+			Response: &api.GetChannelRetentionResponse{
+				Retention: policy,
+			},
+		}, nil
+	}
+}
+
+// PresenceRunner is to read the current presence members of a channel.
+type PresenceRunner struct {
+	*baseRunner
+
+	presence *Presence
+	req      *api.GetPresenceRequest
+}
+
+func (runner *PresenceRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	project, err := runner.getProject(tenant, runner.req.Project)
+	if err != nil {
+		return Response{}, err
+	}
+
+	members, err := runner.presence.Members(ctx, tenant.GetNamespace().Id(), project.Id(), runner.req.Channel)
+	if err != nil {
+		return Response{}, err
+	}
+
+	return Response{
+		Response: &api.GetPresenceResponse{
+			Members: members,
+		},
+	}, nil
+}
+
+// WebhooksRunner is to manage the webhooks delivering the messages of the channels of a project to HTTP endpoints.
+type WebhooksRunner struct {
+	*baseRunner
+
+	webhooks  *Webhooks
+	createReq *api.CreateWebhookRequest
+	deleteReq *api.DeleteWebhookRequest
+	listReq   *api.ListWebhooksRequest
+}
+
+func (runner *WebhooksRunner) SetCreateReq(req *api.CreateWebhookRequest) {
+	runner.createReq = req
+}
+
+func (runner *WebhooksRunner) SetDeleteReq(req *api.DeleteWebhookRequest) {
+	runner.deleteReq = req
+}
+
+func (runner *WebhooksRunner) SetListReq(req *api.ListWebhooksRequest) {
+	runner.listReq = req
+}
+
+func (runner *WebhooksRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	switch {
+	case runner.createReq != nil:
+		project, err := runner.getProject(tenant, runner.createReq.Project)
+		if err != nil {
+			return Response{}, err
+		}
+
+		secret, err := runner.webhooks.Create(ctx, tenant.GetNamespace().Id(), project.Id(), runner.createReq.Webhook)
+		if err != nil {
+			return Response{}, err
+		}
+
+		return Response{
+			Response: &api.CreateWebhookResponse{
+				Status: WebhookCreatedStatus,
+				Secret: secret,
+			},
+		}, nil
+	case runner.deleteReq != nil:
+		project, err := runner.getProject(tenant, runner.deleteReq.Project)
+		if err != nil {
+			return Response{}, err
+		}
+
+		deleted, err := runner.webhooks.Delete(ctx, tenant.GetNamespace().Id(), project.Id(), runner.deleteReq.Channel, runner.deleteReq.Name)
+		if err != nil {
+			return Response{}, err
+		}
+		if !deleted {
+			return Response{}, errors.NotFound("webhook '%s' of channel '%s' not present", runner.deleteReq.Name, runner.deleteReq.Channel)
+		}
+
+		return Response{
+			Response: &api.DeleteWebhookResponse{
+				Status: WebhookDeletedStatus,
+			},
+		}, nil
+	default:
+		project, err := runner.getProject(tenant, runner.listReq.Project)
+		if err != nil {
+			return Response{}, err
+		}
+
+		webhooks, err := runner.webhooks.List(ctx, tenant.GetNamespace().Id(), project.Id(), runner.listReq.Channel)
+		if err != nil {
+			return Response{}, err
+		}
+
+		return Response{
+			Response: &api.ListWebhooksResponse{
+				Webhooks: webhooks,
+			},
+		}, nil
+	}
+}
@@ -142,6 +142,8 @@ func DecodeEvent(encodingType internal.UserDataEncType, eventType api.EventType,
 		event = &api.PresenceMemberEvent{}
 	case api.EventType_disconnect:
 		event = &api.DisconnectEvent{}
+	case api.EventType_history:
+		event = &api.HistoryEvent{}
 	default:
 		return nil, fmt.Errorf("unsupported eventtype '%d'", eventType)
 	}
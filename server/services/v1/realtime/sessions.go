@@ -19,7 +19,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/gorilla/websocket"
 	"github.com/tigrisdata/tigris/errors"
 	"github.com/tigrisdata/tigris/server/metadata"
 	"github.com/tigrisdata/tigris/server/request"
@@ -40,12 +39,14 @@ type Sessions struct {
 	txMgr            *transaction.Manager
 	tenantMgr        *metadata.TenantManager
 	channelFactory   *ChannelFactory
+	rules            *ChannelRules
+	presence         *Presence
 	heartbeatFactory *HeartbeatFactory
 	versionH         *metadata.VersionHandler
 	tenantTracker    *metadata.CacheTracker
 }
 
-func NewSessionMgr(cache cache.Cache, tenantMgr *metadata.TenantManager, txMgr *transaction.Manager, heartbeatF *HeartbeatFactory, factory *ChannelFactory) *Sessions {
+func NewSessionMgr(cache cache.Cache, tenantMgr *metadata.TenantManager, txMgr *transaction.Manager, heartbeatF *HeartbeatFactory, factory *ChannelFactory, rules *ChannelRules, presence *Presence) *Sessions {
 	return &Sessions{
 		cache:            cache,
 		txMgr:            txMgr,
@@ -53,6 +54,8 @@ func NewSessionMgr(cache cache.Cache, tenantMgr *metadata.TenantManager, txMgr *
 		heartbeatFactory: heartbeatF,
 		devices:          make(map[string]*Session),
 		channelFactory:   factory,
+		rules:            rules,
+		presence:         presence,
 		tenantTracker:    metadata.NewCacheTracker(tenantMgr, txMgr),
 	}
 }
@@ -103,7 +106,16 @@ func (s *Sessions) RemoveDevice(_ context.Context, session *Session) {
 	}
 }
 
-func (s *Sessions) AddDevice(ctx context.Context, conn *websocket.Conn, params ConnectionParams) (*Session, error) {
+// GetDevice returns the session of the device.
+func (s *Sessions) GetDevice(sessionId string) (*Session, bool) {
+	s.RLock()
+	defer s.RUnlock()
+
+	session, ok := s.devices[sessionId]
+	return session, ok
+}
+
+func (s *Sessions) AddDevice(ctx context.Context, conn Conn, params ConnectionParams) (*Session, error) {
 	if device, ok := s.devices[params.SessionId]; ok {
 		return device, nil
 	}
@@ -0,0 +1,224 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/transaction"
+)
+
+// settingsRefreshInterval is how long the settings of a project are used before being read again from the metadata,
+// this is the delay for a change made through another server to apply to the connected devices.
+const settingsRefreshInterval = 5 * time.Second
+
+// projectSettings reads a kind of settings of the projects, like the channel rules, from the metadata and keeps them
+// for settingsRefreshInterval. The settings are keyed by name and stored as JSON.
+type projectSettings[T any] struct {
+	sync.RWMutex
+
+	kind   string
+	txMgr  *transaction.Manager
+	store  *metadata.RealtimeSubspace
+	loaded map[projectKey]*loadedSettings[T]
+}
+
+type projectKey struct {
+	tenantId uint32
+	projId   uint32
+}
+
+type loadedSettings[T any] struct {
+	values   map[string]*T
+	loadedAt time.Time
+}
+
+func newProjectSettings[T any](kind string, txMgr *transaction.Manager, store *metadata.RealtimeSubspace) *projectSettings[T] {
+	return &projectSettings[T]{
+		kind:   kind,
+		txMgr:  txMgr,
+		store:  store,
+		loaded: make(map[projectKey]*loadedSettings[T]),
+	}
+}
+
+func (s *projectSettings[T]) inTx(ctx context.Context, fn func(tx transaction.Tx) error) error {
+	tx, err := s.txMgr.StartTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(tx); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// put adds the settings or replaces the settings having the same names, in a single transaction.
+func (s *projectSettings[T]) put(ctx context.Context, tenantId uint32, projId uint32, values map[string]*T) error {
+	err := s.inTx(ctx, func(tx transaction.Tx) error {
+		for name, v := range values {
+			enc, err := jsoniter.Marshal(v)
+			if err != nil {
+				return err
+			}
+			if err = s.store.Put(ctx, tx, tenantId, projId, s.kind, name, enc); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	s.invalidate(tenantId, projId)
+	return err
+}
+
+// insert adds the setting, it returns false if there is already a setting with the same name unless replace returns
+// true for the existing setting, in which case it is replaced. A nil replace never replaces the existing setting.
+func (s *projectSettings[T]) insert(ctx context.Context, tenantId uint32, projId uint32, name string, v *T, replace func(existing *T) bool) (bool, error) {
+	inserted := false
+	err := s.inTx(ctx, func(tx transaction.Tx) error {
+		enc, err := s.store.Get(ctx, tx, tenantId, projId, s.kind, name)
+		if err == nil {
+			if replace == nil {
+				return nil
+			}
+
+			var existing T
+			if err = jsoniter.Unmarshal(enc, &existing); err != nil {
+				return err
+			}
+			if !replace(&existing) {
+				return nil
+			}
+		} else if err != errors.ErrNotFound {
+			return err
+		}
+
+		enc, err = jsoniter.Marshal(v)
+		if err != nil {
+			return err
+		}
+		inserted = true
+		return s.store.Put(ctx, tx, tenantId, projId, s.kind, name, enc)
+	})
+
+	s.invalidate(tenantId, projId)
+	return inserted && err == nil, err
+}
+
+// update changes the setting with fn in a single transaction, it returns false if there is no such setting.
+func (s *projectSettings[T]) update(ctx context.Context, tenantId uint32, projId uint32, name string, fn func(v *T)) (bool, error) {
+	updated := false
+	err := s.inTx(ctx, func(tx transaction.Tx) error {
+		enc, err := s.store.Get(ctx, tx, tenantId, projId, s.kind, name)
+		if err == errors.ErrNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var v T
+		if err = jsoniter.Unmarshal(enc, &v); err != nil {
+			return err
+		}
+		fn(&v)
+
+		if enc, err = jsoniter.Marshal(&v); err != nil {
+			return err
+		}
+		updated = true
+		return s.store.Put(ctx, tx, tenantId, projId, s.kind, name, enc)
+	})
+
+	s.invalidate(tenantId, projId)
+	return updated && err == nil, err
+}
+
+// delete removes the setting, it returns false if there is no such setting.
+func (s *projectSettings[T]) delete(ctx context.Context, tenantId uint32, projId uint32, name string) (bool, error) {
+	var deleted bool
+	err := s.inTx(ctx, func(tx transaction.Tx) (err error) {
+		deleted, err = s.store.Delete(ctx, tx, tenantId, projId, s.kind, name)
+		return
+	})
+
+	s.invalidate(tenantId, projId)
+	return deleted, err
+}
+
+// read returns the settings of the project read from the metadata.
+func (s *projectSettings[T]) read(ctx context.Context, tenantId uint32, projId uint32) (map[string]*T, error) {
+	tx, err := s.txMgr.StartTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	encoded, err := s.store.List(ctx, tx, tenantId, projId, s.kind)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]*T, len(encoded))
+	for name, enc := range encoded {
+		var v T
+		if err = jsoniter.Unmarshal(enc, &v); err != nil {
+			return nil, err
+		}
+		values[name] = &v
+	}
+
+	return values, nil
+}
+
+// get returns the settings of the project, they are read again from the metadata once older than
+// settingsRefreshInterval. An error reading them is returned as is, the callers must not fall back to any default.
+func (s *projectSettings[T]) get(ctx context.Context, tenantId uint32, projId uint32) (map[string]*T, error) {
+	key := projectKey{tenantId: tenantId, projId: projId}
+
+	s.RLock()
+	l, ok := s.loaded[key]
+	s.RUnlock()
+	if ok && time.Since(l.loadedAt) < settingsRefreshInterval {
+		return l.values, nil
+	}
+
+	values, err := s.read(ctx, tenantId, projId)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Lock()
+	s.loaded[key] = &loadedSettings[T]{values: values, loadedAt: time.Now()}
+	s.Unlock()
+
+	return values, nil
+}
+
+func (s *projectSettings[T]) invalidate(tenantId uint32, projId uint32) {
+	s.Lock()
+	defer s.Unlock()
+
+	delete(s.loaded, projectKey{tenantId: tenantId, projId: projId})
+}
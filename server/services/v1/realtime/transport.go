@@ -0,0 +1,280 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tigrisdata/tigris/errors"
+)
+
+const (
+	// PollTimeout is how long a long-poll request waits for events before returning an empty batch.
+	PollTimeout = 25 * time.Second
+	// pollIdleTimeout is how long a long-poll session is kept without the device polling it.
+	pollIdleTimeout  = 60 * time.Second
+	inboundQueueSize = 64
+	// maxPollQueueSize is the maximum number of messages queued for a long-poll device between two polls, the session
+	// is closed once a slow device lets its queue grow past it.
+	maxPollQueueSize = 1024
+)
+
+var errPollQueueFull = errors.ResourceExhausted("too many messages queued since the last poll, the session is closed")
+
+var errConnClosed = errors.Unavailable("connection is closed")
+
+// Conn is the transport between a device and its session. The websocket connection is one, the SSE and long-poll
+// transports emulate it over plain HTTP requests so that all the transports share the session machinery.
+//
+// The sessions of the SSE and long-poll transports live in the process of the server the device connected to, the
+// following requests of the device, posting its events or polling, must reach the same server. The deployments
+// running more than one realtime server need sticky routing on the session id.
+type Conn interface {
+	// ReadMessage blocks until the device sends a message.
+	ReadMessage() (int, []byte, error)
+	// WriteMessage sends a message to the device.
+	WriteMessage(messageType int, data []byte) error
+	Close() error
+}
+
+// httpConn receives the messages of the device from the HTTP requests posting them.
+type httpConn struct {
+	inbound   chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *httpConn) init() {
+	c.inbound = make(chan []byte, inboundQueueSize)
+	c.closed = make(chan struct{})
+}
+
+func (c *httpConn) ReadMessage() (int, []byte, error) {
+	select {
+	case msg := <-c.inbound:
+		return websocket.BinaryMessage, msg, nil
+	case <-c.closed:
+		return 0, nil, errConnClosed
+	}
+}
+
+// Deliver hands a message posted by the device to its session.
+func (c *httpConn) Deliver(ctx context.Context, msg []byte) error {
+	select {
+	case c.inbound <- msg:
+		return nil
+	case <-c.closed:
+		return errConnClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *httpConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+	return nil
+}
+
+// Done is closed once the connection is closed.
+func (c *httpConn) Done() <-chan struct{} {
+	return c.closed
+}
+
+// SSEConn streams the messages to the device as server-sent events, binary messages are base64 encoded.
+type SSEConn struct {
+	httpConn
+
+	sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func NewSSEConn(w http.ResponseWriter) (*SSEConn, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errors.Internal("streaming is not supported")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// disables the response buffering of nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	conn := &SSEConn{
+		w:       w,
+		flusher: flusher,
+	}
+	conn.init()
+
+	return conn, nil
+}
+
+func (c *SSEConn) WriteMessage(messageType int, data []byte) error {
+	c.Lock()
+	defer c.Unlock()
+
+	select {
+	case <-c.closed:
+		return errConnClosed
+	default:
+	}
+
+	payload := string(data)
+	if messageType == websocket.BinaryMessage {
+		payload = base64.StdEncoding.EncodeToString(data)
+	}
+	if _, err := io.WriteString(c.w, sseEvent(payload)); err != nil {
+		return err
+	}
+	c.flusher.Flush()
+
+	return nil
+}
+
+// sseEvent frames the payload as an event of the stream. A line break ends a field of the event, so every line of the
+// payload is sent as its own data field, the device joins them back with a line feed.
+func sseEvent(payload string) string {
+	payload = strings.ReplaceAll(payload, "\r\n", "\n")
+	payload = strings.ReplaceAll(payload, "\r", "\n")
+
+	var sb strings.Builder
+	for _, line := range strings.Split(payload, "\n") {
+		sb.WriteString("data: ")
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+// PollConn queues the messages until the device polls them.
+type PollConn struct {
+	httpConn
+
+	sync.Mutex
+	queue    [][]byte
+	notify   chan struct{}
+	lastPoll time.Time
+}
+
+func NewPollConn() *PollConn {
+	conn := &PollConn{
+		notify:   make(chan struct{}, 1),
+		lastPoll: time.Now(),
+	}
+	conn.init()
+
+	return conn
+}
+
+func (c *PollConn) ReadMessage() (int, []byte, error) {
+	ticker := time.NewTicker(pollIdleTimeout / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-c.inbound:
+			return websocket.BinaryMessage, msg, nil
+		case <-c.closed:
+			return 0, nil, errConnClosed
+		case <-ticker.C:
+			// the device stopped polling, the session is closed as if its socket died
+			if c.idle() {
+				_ = c.Close()
+				return 0, nil, errConnClosed
+			}
+		}
+	}
+}
+
+func (c *PollConn) idle() bool {
+	c.Lock()
+	defer c.Unlock()
+
+	return time.Since(c.lastPoll) > pollIdleTimeout
+}
+
+func (c *PollConn) WriteMessage(_ int, data []byte) error {
+	c.Lock()
+	if len(c.queue) >= maxPollQueueSize {
+		c.Unlock()
+		// the device doesn't keep up, it has to connect again
+		_ = c.Close()
+		return errPollQueueFull
+	}
+	c.queue = append(c.queue, data)
+	c.Unlock()
+
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Poll waits until there are queued messages or the timeout expires and returns the queued messages.
+func (c *PollConn) Poll(ctx context.Context, timeout time.Duration) ([][]byte, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		c.Lock()
+		c.lastPoll = time.Now()
+		if len(c.queue) > 0 {
+			messages := c.queue
+			c.queue = nil
+			c.Unlock()
+			return messages, nil
+		}
+		c.Unlock()
+
+		select {
+		case <-c.notify:
+		case <-timer.C:
+			return nil, nil
+		case <-c.closed:
+			return nil, errConnClosed
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// EncodePollBatch encodes the polled messages as a JSON array, msgpack messages are concatenated as a msgpack stream.
+func EncodePollBatch(jsonEncoding bool, messages [][]byte) []byte {
+	if !jsonEncoding {
+		return bytes.Join(messages, nil)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	buf.Write(bytes.Join(messages, []byte(",")))
+	buf.WriteByte(']')
+	return buf.Bytes()
+}
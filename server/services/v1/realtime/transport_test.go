@@ -0,0 +1,102 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransport(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("sse", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		conn, err := NewSSEConn(w)
+		require.NoError(t, err)
+		require.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"a":1}`)))
+		require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, []byte{1, 2}))
+		require.Equal(t, "data: {\"a\":1}\n\ndata: "+base64.StdEncoding.EncodeToString([]byte{1, 2})+"\n\n", w.Body.String())
+
+		// every line of a multi-line payload is a data field of the same event
+		w.Body.Reset()
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("{\n\"a\":1\r\n}\r")))
+		require.Equal(t, "data: {\ndata: \"a\":1\ndata: }\ndata: \n\n", w.Body.String())
+
+		require.NoError(t, conn.Deliver(ctx, []byte("event")))
+		_, msg, err := conn.ReadMessage()
+		require.NoError(t, err)
+		require.Equal(t, []byte("event"), msg)
+
+		require.NoError(t, conn.Close())
+		_, _, err = conn.ReadMessage()
+		require.Equal(t, errConnClosed, err)
+		require.Equal(t, errConnClosed, conn.WriteMessage(websocket.TextMessage, nil))
+	})
+	t.Run("poll", func(t *testing.T) {
+		conn := NewPollConn()
+		defer func() { _ = conn.Close() }()
+
+		messages, err := conn.Poll(ctx, 10*time.Millisecond)
+		require.NoError(t, err)
+		require.Empty(t, messages)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"a":1}`))
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"b":2}`))
+		}()
+
+		var polled [][]byte
+		for len(polled) < 2 {
+			messages, err = conn.Poll(ctx, time.Second)
+			require.NoError(t, err)
+			require.NotEmpty(t, messages)
+			polled = append(polled, messages...)
+		}
+		require.Equal(t, `[{"a":1},{"b":2}]`, string(EncodePollBatch(true, polled)))
+		require.Equal(t, []byte{1, 2, 3}, EncodePollBatch(false, [][]byte{{1}, {2, 3}}))
+	})
+	t.Run("poll_queue_full", func(t *testing.T) {
+		conn := NewPollConn()
+
+		for i := 0; i < maxPollQueueSize; i++ {
+			require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{}`)))
+		}
+		require.Equal(t, errPollQueueFull, conn.WriteMessage(websocket.TextMessage, []byte(`{}`)))
+
+		// the device still gets the queued messages, then the closed session
+		messages, err := conn.Poll(ctx, time.Second)
+		require.NoError(t, err)
+		require.Len(t, messages, maxPollQueueSize)
+		_, err = conn.Poll(ctx, time.Second)
+		require.Equal(t, errConnClosed, err)
+	})
+	t.Run("owner", func(t *testing.T) {
+		session := &Session{principal: &Principal{Claims: map[string]string{"sub": "user1"}}}
+
+		require.True(t, session.OwnedBy(&Principal{Role: "o", Claims: map[string]string{"sub": "user1"}}))
+		require.False(t, session.OwnedBy(&Principal{Claims: map[string]string{"sub": "user2"}}))
+		require.False(t, session.OwnedBy(&Principal{}))
+	})
+}
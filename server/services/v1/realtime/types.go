@@ -42,8 +42,8 @@ func NewStreamMessageMD(dataType string, clientId string, socketId string, event
 	}
 }
 
-func NewPresenceData(encType internal.UserDataEncType, clientId string, socketId string, eventName string, msg *api.MessageEvent) (*internal.StreamData, error) {
-	return newStreamData(PresenceChannelData, encType, clientId, socketId, eventName, msg.Data)
+func NewPresenceData(encType internal.UserDataEncType, clientId string, socketId string, eventName string, data []byte) (*internal.StreamData, error) {
+	return newStreamData(PresenceChannelData, encType, clientId, socketId, eventName, data)
 }
 
 func NewMessageData(encType internal.UserDataEncType, clientId string, socketId string, eventName string, msg *api.MessageEvent) (*internal.StreamData, error) {
@@ -0,0 +1,777 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	xredis "github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog/log"
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/store/cache"
+)
+
+const (
+	// webhookGroupPrefix is the prefix of the consumer groups of the webhooks, the group keeps the position of the
+	// webhook in the channel and the messages read but not yet delivered.
+	webhookGroupPrefix = "_tigris_webhook_"
+	// webhookRefreshInterval is how often the webhooks are read from the metadata and their leases renewed, this is the
+	// delay for a new webhook to start receiving the messages.
+	webhookRefreshInterval = 5 * time.Second
+	// webhookLeaseTTL is how long a server which stopped renewing its lease keeps the other servers from taking over
+	// the delivery of a webhook.
+	webhookLeaseTTL   = 30 * time.Second
+	webhookTimeout    = 10 * time.Second
+	webhookMinBackoff = time.Second
+	webhookMaxBackoff = time.Minute
+	webhookSecretSize = 32
+	// webhookMaxAttempts is the number of times a batch is posted before the webhook is disabled, about five minutes
+	// with the backoff in between.
+	webhookMaxAttempts = 10
+)
+
+const (
+	defaultWebhookBatchSize   = 100
+	maxWebhookBatchSize       = 1000
+	maxWebhookBatchIntervalMs = 60000
+	maxWebhookNameLength      = 64
+)
+
+const (
+	WebhookSignatureHeader = "Tigris-Signature"
+	WebhookDeliveryHeader  = "Tigris-Delivery"
+	webhookSignatureFormat = "t=%d,v1=%s"
+)
+
+const (
+	WebhookCreatedStatus = "created"
+	WebhookDeletedStatus = "deleted"
+)
+
+var webhookNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// sharedAddressSpace is the range of the carrier-grade NATs, these addresses are private to the network of the server.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// webhookAllowPrivateNetworks lets the webhooks deliver to any address, only the tests set it to use a local endpoint.
+var webhookAllowPrivateNetworks = false
+
+var errWebhookFailing = fmt.Errorf("webhook failed %d consecutive attempts", webhookMaxAttempts)
+
+// WebhookPayload is the body POSTed to the endpoint of a webhook, it carries a batch of the messages of the channel
+// in the order they were published.
+type WebhookPayload struct {
+	Webhook  string            `json:"webhook"`
+	Channel  string            `json:"channel"`
+	Messages []*WebhookMessage `json:"messages"`
+}
+
+type WebhookMessage struct {
+	Id   string              `json:"id"`
+	Name string              `json:"name,omitempty"`
+	Data jsoniter.RawMessage `json:"data,omitempty"`
+}
+
+// SignWebhook returns the hex encoded HMAC-SHA256 of the timestamp and the body of a delivery. The signature is sent
+// in the Tigris-Signature header as "t=<timestamp>,v1=<signature>" so that the endpoint can verify that the delivery
+// comes from the server and reject the replayed ones.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = fmt.Fprintf(mac, "%d.", timestamp)
+	_, _ = mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookEntry is a webhook as stored in the metadata.
+type webhookEntry struct {
+	TenantId  uint32
+	ProjectId uint32
+	Webhook   *api.Webhook
+	// Disabled is set once a batch of messages failed webhookMaxAttempts times, the webhook is no longer delivered
+	// until it is created again. Creating it again replaces it but keeps its consumer group, so the failed batch and
+	// the messages published since are delivered first.
+	Disabled bool `json:",omitempty"`
+}
+
+func (e *webhookEntry) field() string {
+	return webhookField(e.TenantId, e.ProjectId, e.Webhook.Channel, e.Webhook.Name)
+}
+
+func webhookField(tenantId uint32, projId uint32, channel string, name string) string {
+	return fmt.Sprintf("%d/%d/%s", tenantId, projId, webhookKey(channel, name))
+}
+
+// webhookKey is the name of the webhook in the metadata of the project, the name of a webhook has no '/' so the key is
+// unique.
+func webhookKey(channel string, name string) string {
+	return channel + "/" + name
+}
+
+func webhookGroup(name string) string {
+	return webhookGroupPrefix + name
+}
+
+func isWebhookGroup(group string) bool {
+	return strings.HasPrefix(group, webhookGroupPrefix)
+}
+
+// Webhooks are the server-side subscribers of the channels, a webhook POSTs the messages published to its channel to
+// an HTTP endpoint. Each webhook reads the channel through its own consumer group, so its position survives restarts
+// and a batch is only acknowledged once the endpoint accepted it, the delivery is at-least-once. The webhooks and their
+// secrets are kept in the metadata of the projects, every server reads them periodically and a lease in the cache
+// makes sure a single server delivers a webhook at a time.
+type Webhooks struct {
+	sync.Mutex
+
+	cache    cache.Cache
+	factory  *ChannelFactory
+	settings *projectSettings[webhookEntry]
+	client   *http.Client
+	// lease is the value of the leases this server holds, the same value is set on all of them so that a lease is
+	// renewed by comparing its value.
+	lease   *internal.CacheData
+	workers map[string]*webhookWorker
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	// wg tracks the monitor and the workers.
+	wg sync.WaitGroup
+}
+
+func NewWebhooks(txMgr *transaction.Manager, store *metadata.RealtimeSubspace, cache cache.Cache, factory *ChannelFactory) *Webhooks {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	w := &Webhooks{
+		cache:    cache,
+		factory:  factory,
+		settings: newProjectSettings[webhookEntry](metadata.RealtimeWebhooks, txMgr, store),
+		client:   newWebhookClient(),
+		lease:    internal.NewCacheData([]byte(uuid.New().String())),
+		workers:  make(map[string]*webhookWorker),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	w.wg.Add(1)
+	go w.monitor()
+
+	return w
+}
+
+// Stop stops refreshing the webhooks and delivering them, and waits for the deliveries in progress to exit. The leases
+// are not released, the other servers take the webhooks over once they expire.
+func (w *Webhooks) Stop() {
+	w.cancel()
+	w.wg.Wait()
+}
+
+// newWebhookClient returns the client of the deliveries. The endpoints are given by the users so the client connects
+// only to public addresses, the address is checked once resolved so that a name resolving to an internal address is
+// rejected as well, and the redirects are not followed.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: checkWebhookAddress,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   webhookTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// checkWebhookAddress rejects the connections to the loopback, private, link-local, shared and unspecified
+// addresses, like the ones of the metadata endpoints of the clouds and of the other services of the cluster.
+func checkWebhookAddress(_ string, address string, _ syscall.RawConn) error {
+	if webhookAllowPrivateNetworks {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !isPublicAddr(addr) {
+		return fmt.Errorf("webhook endpoint address %s is not public", addr)
+	}
+
+	return nil
+}
+
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+func validateWebhook(webhook *api.Webhook) error {
+	if webhook == nil || len(webhook.Channel) == 0 {
+		return errors.InvalidArgument("channel of the webhook can't be empty")
+	}
+	if len(webhook.Name) == 0 || len(webhook.Name) > maxWebhookNameLength || !webhookNameRegex.MatchString(webhook.Name) {
+		return errors.InvalidArgument("invalid webhook name '%s', expecting at most %d letters, digits, '_' or '-'",
+			webhook.Name, maxWebhookNameLength)
+	}
+
+	u, err := url.Parse(webhook.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Hostname()) == 0 {
+		return errors.InvalidArgument("invalid url '%s' of webhook '%s', expecting an http or https url", webhook.Url, webhook.Name)
+	}
+	// the names are checked once resolved when delivering, the addresses and localhost are rejected early
+	if addr, err := netip.ParseAddr(u.Hostname()); (err == nil && !isPublicAddr(addr)) ||
+		strings.EqualFold(u.Hostname(), "localhost") {
+		if !webhookAllowPrivateNetworks {
+			return errors.InvalidArgument("url '%s' of webhook '%s' is not a public address", webhook.Url, webhook.Name)
+		}
+	}
+
+	if webhook.MaxBatchSize < 0 || webhook.MaxBatchSize > maxWebhookBatchSize {
+		return errors.InvalidArgument("max batch size of the webhook must be between 0 and %d", maxWebhookBatchSize)
+	}
+	if webhook.BatchIntervalMs < 0 || webhook.BatchIntervalMs > maxWebhookBatchIntervalMs {
+		return errors.InvalidArgument("batch interval of the webhook must be between 0 and %d ms", maxWebhookBatchIntervalMs)
+	}
+
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	var secret [webhookSecretSize]byte
+	if _, err := rand.Read(secret[:]); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(secret[:]), nil
+}
+
+// Create adds the webhook to the channel, the webhook receives the messages published after it is created. A secret
+// is generated if the webhook doesn't have one, the secret is returned as it is not exposed afterwards. A disabled
+// webhook is replaced and enabled again, it resumes at the messages it didn't deliver.
+func (w *Webhooks) Create(ctx context.Context, tenantId uint32, projId uint32, webhook *api.Webhook) (string, error) {
+	if err := validateWebhook(webhook); err != nil {
+		return "", err
+	}
+
+	if len(webhook.Secret) == 0 {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return "", err
+		}
+		webhook.Secret = secret
+	}
+
+	ch, err := w.factory.GetOrCreateChannel(ctx, tenantId, projId, webhook.Channel)
+	if err != nil {
+		return "", err
+	}
+
+	replaced := false
+	inserted, err := w.settings.insert(ctx, tenantId, projId, webhookKey(webhook.Channel, webhook.Name), &webhookEntry{
+		TenantId:  tenantId,
+		ProjectId: projId,
+		Webhook:   webhook,
+	}, func(existing *webhookEntry) bool {
+		replaced = existing.Disabled
+		return replaced
+	})
+	if err != nil {
+		return "", err
+	}
+	if !inserted {
+		return "", errors.AlreadyExists("webhook '%s' of channel '%s' already exists", webhook.Name, webhook.Channel)
+	}
+	if replaced {
+		// the consumer group of the disabled webhook still holds its position in the channel
+		return webhook.Secret, nil
+	}
+
+	if err = ch.stream.CreateConsumerGroup(ctx, webhookGroup(webhook.Name), cache.ConsumerGroupDefaultCurrentPos); err != nil {
+		if _, delErr := w.settings.delete(ctx, tenantId, projId, webhookKey(webhook.Channel, webhook.Name)); delErr != nil {
+			log.Err(delErr).Str("channel", webhook.Channel).Str("webhook", webhook.Name).Msg("removing webhook failed")
+		}
+		return "", err
+	}
+
+	return webhook.Secret, nil
+}
+
+// Delete removes the webhook and its position in the channel, it returns false if there is no such webhook.
+func (w *Webhooks) Delete(ctx context.Context, tenantId uint32, projId uint32, channel string, name string) (bool, error) {
+	deleted, err := w.settings.delete(ctx, tenantId, projId, webhookKey(channel, name))
+	if err != nil || !deleted {
+		return false, err
+	}
+
+	field := webhookField(tenantId, projId, channel, name)
+	w.stop(field)
+	if table, err := w.leasesTable(); err == nil {
+		if _, err = w.cache.CompareAndDelete(ctx, table, field, w.lease); err != nil {
+			log.Err(err).Str("webhook", field).Msg("releasing webhook lease failed")
+		}
+	}
+
+	if ch, err := w.factory.GetChannel(ctx, tenantId, projId, channel); err == nil {
+		if err = ch.stream.RemoveConsumerGroup(ctx, webhookGroup(name)); err != nil {
+			log.Err(err).Str("channel", channel).Str("webhook", name).Msg("removing webhook consumer group failed")
+		}
+	}
+
+	return true, nil
+}
+
+// List returns the webhooks of the project, or only the ones of the channel if it is set, ordered by channel and
+// name. The secrets are not returned, disabled webhooks are flagged.
+func (w *Webhooks) List(ctx context.Context, tenantId uint32, projId uint32, channel string) ([]*api.Webhook, error) {
+	entries, err := w.settings.read(ctx, tenantId, projId)
+	if err != nil {
+		return nil, err
+	}
+
+	var webhooks []*api.Webhook
+	for _, e := range entries {
+		if len(channel) > 0 && e.Webhook.Channel != channel {
+			continue
+		}
+
+		e.Webhook.Secret = ""
+		e.Webhook.Disabled = e.Disabled
+		webhooks = append(webhooks, e.Webhook)
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		if webhooks[i].Channel != webhooks[j].Channel {
+			return webhooks[i].Channel < webhooks[j].Channel
+		}
+		return webhooks[i].Name < webhooks[j].Name
+	})
+
+	return webhooks, nil
+}
+
+// all returns the webhooks of all the projects.
+func (w *Webhooks) all(ctx context.Context) ([]*webhookEntry, error) {
+	tx, err := w.settings.txMgr.StartTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	encoded, err := w.settings.store.ListKind(ctx, tx, metadata.RealtimeWebhooks)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*webhookEntry, 0, len(encoded))
+	for _, enc := range encoded {
+		var entry webhookEntry
+		if err = jsoniter.Unmarshal(enc, &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+	}
+
+	return entries, nil
+}
+
+// disable marks the webhook as disabled in the metadata and stops delivering it.
+func (w *Webhooks) disable(ctx context.Context, e *webhookEntry) {
+	log.Warn().Str("webhook", e.field()).Int("attempts", webhookMaxAttempts).Msg("disabling failing webhook")
+
+	if _, err := w.settings.update(ctx, e.TenantId, e.ProjectId, webhookKey(e.Webhook.Channel, e.Webhook.Name),
+		func(v *webhookEntry) { v.Disabled = true },
+	); err != nil {
+		log.Err(err).Str("webhook", e.field()).Msg("disabling webhook failed")
+	}
+
+	w.stop(e.field())
+}
+
+func (w *Webhooks) leasesTable() (string, error) {
+	return w.factory.encoder.EncodeInternalCacheTableName(0, 0, metadata.WebhookLeasesCacheTable)
+}
+
+func (w *Webhooks) monitor() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(webhookRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.refresh(w.ctx); err != nil && w.ctx.Err() == nil {
+				log.Err(err).Msg("refreshing webhooks failed")
+			}
+		case <-w.ctx.Done():
+			return
+		}
+	}
+}
+
+// refresh starts delivering the webhooks whose lease this server holds and stops the ones which were deleted, were
+// disabled or whose lease was lost.
+func (w *Webhooks) refresh(ctx context.Context) error {
+	entries, err := w.all(ctx)
+	if err != nil {
+		return err
+	}
+
+	held := make(map[string]*webhookEntry)
+	for _, e := range entries {
+		if e.Disabled {
+			continue
+		}
+		field := e.field()
+
+		ok, err := w.acquire(ctx, field)
+		if err != nil {
+			log.Err(err).Str("webhook", field).Msg("acquiring webhook lease failed")
+			continue
+		}
+		if ok {
+			held[field] = e
+		}
+	}
+
+	w.Lock()
+	defer w.Unlock()
+
+	if w.ctx.Err() != nil {
+		return w.ctx.Err()
+	}
+
+	for field, worker := range w.workers {
+		if _, ok := held[field]; !ok {
+			worker.stop()
+			delete(w.workers, field)
+		}
+	}
+
+	for field, e := range held {
+		if _, ok := w.workers[field]; ok {
+			continue
+		}
+
+		ch, err := w.factory.GetOrCreateChannel(ctx, e.TenantId, e.ProjectId, e.Webhook.Channel)
+		if err != nil {
+			log.Err(err).Str("webhook", field).Msg("reading webhook channel failed")
+			continue
+		}
+
+		e := e
+		worker := newWebhookWorker(e.Webhook, ch.stream, w.client, func() { w.disable(context.TODO(), e) })
+		w.workers[field] = worker
+		worker.start(w.ctx, &w.wg)
+	}
+
+	return nil
+}
+
+// acquire renews the lease of this server on the webhook if it still holds it, or acquires it if no server does.
+func (w *Webhooks) acquire(ctx context.Context, field string) (bool, error) {
+	table, err := w.leasesTable()
+	if err != nil {
+		return false, err
+	}
+
+	renewed, err := w.cache.CompareAndExpire(ctx, table, field, w.lease, webhookLeaseTTL)
+	if err != nil || renewed {
+		return renewed, err
+	}
+
+	err = w.cache.Set(ctx, table, field, w.lease, &cache.SetOptions{
+		NX: true,
+		EX: uint64(webhookLeaseTTL.Seconds()),
+	})
+	if err == cache.ErrKeyAlreadyExists {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (w *Webhooks) stop(field string) {
+	w.Lock()
+	defer w.Unlock()
+
+	if worker, ok := w.workers[field]; ok {
+		worker.stop()
+		delete(w.workers, field)
+	}
+}
+
+// webhookWorker delivers the messages of a channel to the endpoint of a webhook. The pending messages of the consumer
+// group, read but not acknowledged before a restart or a takeover, are delivered first.
+type webhookWorker struct {
+	webhook *api.Webhook
+	group   string
+	stream  cache.Stream
+	client  *http.Client
+	// disable is called once a batch failed webhookMaxAttempts times, the failed batch stays pending in the group.
+	disable func()
+	cancel  context.CancelFunc
+}
+
+func newWebhookWorker(webhook *api.Webhook, stream cache.Stream, client *http.Client, disable func()) *webhookWorker {
+	return &webhookWorker{
+		webhook: webhook,
+		group:   webhookGroup(webhook.Name),
+		stream:  stream,
+		client:  client,
+		disable: disable,
+	}
+}
+
+// start runs the worker until it is stopped or the parent context is cancelled, wg is done once it exits.
+func (worker *webhookWorker) start(parent context.Context, wg *sync.WaitGroup) {
+	var ctx context.Context
+	ctx, worker.cancel = context.WithCancel(parent)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		worker.run(ctx)
+	}()
+}
+
+func (worker *webhookWorker) stop() {
+	worker.cancel()
+}
+
+func (worker *webhookWorker) batchSize() int {
+	if worker.webhook.MaxBatchSize > 0 {
+		return int(worker.webhook.MaxBatchSize)
+	}
+
+	return defaultWebhookBatchSize
+}
+
+func (worker *webhookWorker) run(ctx context.Context) {
+	pos := cache.ReadGroupPosStart
+	backoff := webhookMinBackoff
+
+	for ctx.Err() == nil {
+		resp, _, err := worker.stream.ReadGroup(ctx, worker.group, pos)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Err(err).Str("webhook", worker.webhook.Name).Msg("reading webhook messages failed")
+			}
+			if !sleepCtx(ctx, backoff) {
+				return
+			}
+			backoff = nextBackoff(backoff)
+			continue
+		}
+		backoff = webhookMinBackoff
+
+		if resp == nil || len(resp.Messages) == 0 {
+			// the pending messages are delivered, continue with the new ones
+			pos = cache.ReadGroupPosCurrent
+			continue
+		}
+
+		if err = worker.deliver(ctx, resp); err != nil {
+			if err == errWebhookFailing {
+				worker.disable()
+			}
+			return
+		}
+
+		if worker.webhook.BatchIntervalMs > 0 && !sleepCtx(ctx, time.Duration(worker.webhook.BatchIntervalMs)*time.Millisecond) {
+			return
+		}
+	}
+}
+
+// deliver POSTs the messages in batches, retrying a batch until the endpoint accepts it, and acknowledges them. It
+// returns errWebhookFailing if the endpoint didn't accept a batch in webhookMaxAttempts and an error when the worker
+// is stopped.
+func (worker *webhookWorker) deliver(ctx context.Context, resp *cache.StreamMessages) error {
+	size := worker.batchSize()
+
+	for start := 0; start < len(resp.Messages); start += size {
+		end := start + size
+		if end > len(resp.Messages) {
+			end = len(resp.Messages)
+		}
+
+		ids := make([]string, 0, end-start)
+		payload := &WebhookPayload{
+			Webhook: worker.webhook.Name,
+			Channel: worker.webhook.Channel,
+		}
+		for _, m := range resp.Messages[start:end] {
+			ids = append(ids, m.ID)
+
+			msg, err := webhookMessage(resp, m)
+			if err != nil {
+				log.Err(err).Str("webhook", worker.webhook.Name).Str("id", m.ID).Msg("skipping undecodable message")
+				continue
+			}
+			if msg != nil {
+				payload.Messages = append(payload.Messages, msg)
+			}
+		}
+
+		if len(payload.Messages) > 0 {
+			if err := worker.postWithRetry(ctx, payload, ids[len(ids)-1]); err != nil {
+				return err
+			}
+		}
+
+		if err := worker.stream.Ack(ctx, worker.group, ids...); err != nil {
+			// the batch stays pending and is delivered again by the next read of the pending messages
+			log.Err(err).Str("webhook", worker.webhook.Name).Msg("acknowledging webhook messages failed")
+		}
+	}
+
+	return nil
+}
+
+// webhookMessage converts a stream message to the message of the payload, only the messages published to the channel
+// are delivered, nil is returned for the other ones like the presence events.
+func webhookMessage(resp *cache.StreamMessages, m xredis.XMessage) (*WebhookMessage, error) {
+	data, err := resp.Decode(m)
+	if err != nil {
+		return nil, err
+	}
+
+	md, err := DecodeStreamMD(data.Md)
+	if err != nil {
+		return nil, err
+	}
+	if md.DataType != MessageChannelData {
+		return nil, nil
+	}
+
+	msg := &WebhookMessage{
+		Id:   m.ID,
+		Name: md.EventName,
+	}
+	if len(data.RawData) > 0 {
+		if msg.Data, err = SanitizeUserData(internal.JsonEncoding, data); err != nil {
+			return nil, err
+		}
+	}
+
+	return msg, nil
+}
+
+func (worker *webhookWorker) postWithRetry(ctx context.Context, payload *WebhookPayload, deliveryId string) error {
+	body, err := jsoniter.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	backoff := webhookMinBackoff
+	for attempt := 1; ; attempt++ {
+		err = worker.post(ctx, body, deliveryId)
+		if err == nil {
+			return nil
+		}
+		if attempt == webhookMaxAttempts {
+			log.Warn().Err(err).Str("webhook", worker.webhook.Name).Str("delivery", deliveryId).Msg("webhook delivery failed")
+			return errWebhookFailing
+		}
+
+		log.Warn().Err(err).Str("webhook", worker.webhook.Name).Str("delivery", deliveryId).Msg("webhook delivery failed, retrying")
+		if !sleepCtx(ctx, backoff) {
+			return ctx.Err()
+		}
+		backoff = nextBackoff(backoff)
+	}
+}
+
+func (worker *webhookWorker) post(ctx context.Context, body []byte, deliveryId string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, worker.webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookDeliveryHeader, deliveryId)
+	req.Header.Set(WebhookSignatureHeader, fmt.Sprintf(webhookSignatureFormat, timestamp,
+		SignWebhook(worker.webhook.Secret, timestamp, body)))
+
+	resp, err := worker.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func nextBackoff(backoff time.Duration) time.Duration {
+	if backoff *= 2; backoff > webhookMaxBackoff {
+		return webhookMaxBackoff
+	}
+
+	return backoff
+}
+
+// sleepCtx waits for the duration, it returns false if the context is done before.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
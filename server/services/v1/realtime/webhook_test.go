@@ -0,0 +1,243 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/internal"
+)
+
+func TestSignWebhook(t *testing.T) {
+	require.Equal(t, "49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686",
+		SignWebhook("secret", 1700000000, []byte(`{"a":1}`)))
+}
+
+func TestValidateWebhook(t *testing.T) {
+	valid := func() *api.Webhook {
+		return &api.Webhook{Name: "hook", Channel: "orders", Url: "https://example.com/hook"}
+	}
+	require.NoError(t, validateWebhook(valid()))
+
+	cases := []func(w *api.Webhook){
+		func(w *api.Webhook) { w.Channel = "" },
+		func(w *api.Webhook) { w.Name = "" },
+		func(w *api.Webhook) { w.Name = "a/b" },
+		func(w *api.Webhook) { w.Url = "ftp://example.com" },
+		func(w *api.Webhook) { w.Url = "https://" },
+		func(w *api.Webhook) { w.Url = "http://localhost:8080/hook" },
+		func(w *api.Webhook) { w.Url = "http://127.0.0.1/hook" },
+		func(w *api.Webhook) { w.Url = "http://169.254.169.254/latest/meta-data" },
+		func(w *api.Webhook) { w.Url = "http://10.0.0.1/hook" },
+		func(w *api.Webhook) { w.Url = "http://[::1]/hook" },
+		func(w *api.Webhook) { w.MaxBatchSize = maxWebhookBatchSize + 1 },
+		func(w *api.Webhook) { w.BatchIntervalMs = -1 },
+	}
+	for i, c := range cases {
+		w := valid()
+		c(w)
+		require.Error(t, validateWebhook(w), "case %d", i)
+	}
+}
+
+func TestCheckWebhookAddress(t *testing.T) {
+	require.NoError(t, checkWebhookAddress("tcp", "93.184.216.34:443", nil))
+	require.NoError(t, checkWebhookAddress("tcp6", "[2606:2800:220:1:248:1893:25c8:1946]:443", nil))
+
+	for _, address := range []string{
+		"127.0.0.1:80", "[::1]:80", "0.0.0.0:80", "10.1.2.3:6379", "172.16.0.1:80", "192.168.1.1:80",
+		"169.254.169.254:80", "100.64.0.1:80", "[::ffff:10.0.0.1]:80", "[fd00::1]:80", "[fe80::1]:80",
+	} {
+		require.Error(t, checkWebhookAddress("tcp", address, nil), address)
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	ctx := context.TODO()
+	factory := newFactory(t)
+	txMgr, store := newTestRealtimeStore(t, 2)
+	webhooks := NewWebhooks(txMgr, store, factory.cache, factory)
+	defer webhooks.Stop()
+
+	// the endpoint of the test is on the loopback
+	webhookAllowPrivateNetworks = true
+	defer func() { webhookAllowPrivateNetworks = false }()
+
+	var (
+		mu       sync.Mutex
+		attempts int
+		received []string
+		secret   string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		attempts++
+		if attempts == 1 {
+			// the first delivery fails and is retried
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		var ts int64
+		var sig string
+		_, err = fmt.Sscanf(strings.Replace(r.Header.Get(WebhookSignatureHeader), ",v1=", " ", 1), "t=%d %s", &ts, &sig)
+		assert.NoError(t, err)
+		assert.Equal(t, SignWebhook(secret, ts, body), sig)
+
+		var payload WebhookPayload
+		if !assert.NoError(t, jsoniter.Unmarshal(body, &payload)) || !assert.NotEmpty(t, payload.Messages) {
+			return
+		}
+		assert.Equal(t, "hook", payload.Webhook)
+		assert.Equal(t, "orders", payload.Channel)
+		assert.LessOrEqual(t, len(payload.Messages), 2)
+		assert.Equal(t, payload.Messages[len(payload.Messages)-1].Id, r.Header.Get(WebhookDeliveryHeader))
+
+		for _, m := range payload.Messages {
+			assert.Equal(t, "created", m.Name)
+			received = append(received, string(m.Data))
+		}
+	}))
+	defer srv.Close()
+
+	var err error
+	mu.Lock()
+	secret, err = webhooks.Create(ctx, 1, 2, &api.Webhook{Name: "hook", Channel: "orders", Url: srv.URL, MaxBatchSize: 2})
+	mu.Unlock()
+	require.NoError(t, err)
+	require.NotEmpty(t, secret)
+	defer func() { _, _ = webhooks.Delete(ctx, 1, 2, "orders", "hook") }()
+
+	_, err = webhooks.Create(ctx, 1, 2, &api.Webhook{Name: "hook", Channel: "orders", Url: srv.URL})
+	require.Error(t, err)
+
+	ch, err := factory.GetOrCreateChannel(ctx, 1, 2, "orders")
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		streamData, err := newStreamData(MessageChannelData, internal.JsonEncoding, "", "", "created",
+			[]byte(`{"i":`+strconv.Itoa(i)+`}`))
+		require.NoError(t, err)
+		_, err = ch.PublishMessage(ctx, streamData)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, webhooks.refresh(ctx))
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 3
+	}, 10*time.Second, 50*time.Millisecond)
+	require.Equal(t, []string{`{"i":0}`, `{"i":1}`, `{"i":2}`}, received)
+
+	list, err := webhooks.List(ctx, 1, 2, "")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, "hook", list[0].Name)
+	require.Empty(t, list[0].Secret)
+
+	t.Run("lease", func(t *testing.T) {
+		field := webhookField(1, 2, "orders", "hook")
+
+		// the lease is renewed by the server holding it only
+		acquired, err := webhooks.acquire(ctx, field)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		other := &Webhooks{cache: factory.cache, factory: factory, lease: internal.NewCacheData([]byte("other"))}
+		acquired, err = other.acquire(ctx, field)
+		require.NoError(t, err)
+		require.False(t, acquired)
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		_, err := webhooks.Create(ctx, 1, 2, &api.Webhook{Name: "failing", Channel: "orders", Url: srv.URL})
+		require.NoError(t, err)
+		defer func() { _, _ = webhooks.Delete(ctx, 1, 2, "orders", "failing") }()
+
+		field := webhookField(1, 2, "orders", "failing")
+		require.NoError(t, webhooks.refresh(ctx))
+		webhooks.Lock()
+		require.Contains(t, webhooks.workers, field)
+		webhooks.Unlock()
+
+		webhooks.disable(ctx, &webhookEntry{TenantId: 1, ProjectId: 2, Webhook: &api.Webhook{Name: "failing", Channel: "orders"}})
+
+		// a disabled webhook is kept but not delivered anymore
+		require.NoError(t, webhooks.refresh(ctx))
+		webhooks.Lock()
+		require.NotContains(t, webhooks.workers, field)
+		webhooks.Unlock()
+
+		list, err := webhooks.List(ctx, 1, 2, "orders")
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		require.Equal(t, "failing", list[0].Name)
+		require.True(t, list[0].Disabled)
+		require.False(t, list[1].Disabled)
+
+		// creating the disabled webhook again enables it, an enabled webhook can't be created again
+		_, err = webhooks.Create(ctx, 1, 2, &api.Webhook{Name: "failing", Channel: "orders", Url: srv.URL})
+		require.NoError(t, err)
+		_, err = webhooks.Create(ctx, 1, 2, &api.Webhook{Name: "failing", Channel: "orders", Url: srv.URL})
+		require.Error(t, err)
+
+		require.NoError(t, webhooks.refresh(ctx))
+		webhooks.Lock()
+		require.Contains(t, webhooks.workers, field)
+		webhooks.Unlock()
+
+		list, err = webhooks.List(ctx, 1, 2, "orders")
+		require.NoError(t, err)
+		require.False(t, list[0].Disabled)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		webhooks.Lock()
+		require.Contains(t, webhooks.workers, webhookField(1, 2, "orders", "hook"))
+		webhooks.Unlock()
+
+		// the workers have exited once stopped and no new one is started
+		webhooks.Stop()
+		require.Error(t, webhooks.refresh(ctx))
+	})
+
+	deleted, err := webhooks.Delete(ctx, 1, 2, "orders", "hook")
+	require.NoError(t, err)
+	require.True(t, deleted)
+
+	deleted, err = webhooks.Delete(ctx, 1, 2, "orders", "hook")
+	require.NoError(t, err)
+	require.False(t, deleted)
+}
@@ -149,6 +149,210 @@ func (s *searchService) ListIndexes(ctx context.Context, req *api.ListIndexesReq
 	return resp.Response.(*api.ListIndexesResponse), nil
 }
 
+func (s *searchService) UpsertSynonym(ctx context.Context, req *api.UpsertSynonymRequest) (*api.UpsertSynonymResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetSettingsRunner(accessToken)
+	runner.SetUpsertSynonymReq(req)
+
+	resp, err := s.sessions.TxExecute(ctx, runner, search.SessionOptions{
+		IncVersion: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.UpsertSynonymResponse{
+		Status: resp.Status,
+	}, nil
+}
+
+func (s *searchService) DeleteSynonym(ctx context.Context, req *api.DeleteSynonymRequest) (*api.DeleteSynonymResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetSettingsRunner(accessToken)
+	runner.SetDeleteSynonymReq(req)
+
+	resp, err := s.sessions.TxExecute(ctx, runner, search.SessionOptions{
+		IncVersion: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.DeleteSynonymResponse{
+		Status: resp.Status,
+	}, nil
+}
+
+func (s *searchService) ListSynonyms(ctx context.Context, req *api.ListSynonymsRequest) (*api.ListSynonymsResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetSettingsRunner(accessToken)
+	runner.SetListSynonymsReq(req)
+
+	resp, err := s.sessions.TxExecute(ctx, runner, search.SessionOptions{
+		IncVersion: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Response.(*api.ListSynonymsResponse), nil
+}
+
+func (s *searchService) UpsertStopwords(ctx context.Context, req *api.UpsertStopwordsRequest) (*api.UpsertStopwordsResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetSettingsRunner(accessToken)
+	runner.SetUpsertStopwordsReq(req)
+
+	resp, err := s.sessions.TxExecute(ctx, runner, search.SessionOptions{
+		IncVersion: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.UpsertStopwordsResponse{
+		Status: resp.Status,
+	}, nil
+}
+
+func (s *searchService) DeleteStopwords(ctx context.Context, req *api.DeleteStopwordsRequest) (*api.DeleteStopwordsResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetSettingsRunner(accessToken)
+	runner.SetDeleteStopwordsReq(req)
+
+	resp, err := s.sessions.TxExecute(ctx, runner, search.SessionOptions{
+		IncVersion: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.DeleteStopwordsResponse{
+		Status: resp.Status,
+	}, nil
+}
+
+func (s *searchService) ListStopwords(ctx context.Context, req *api.ListStopwordsRequest) (*api.ListStopwordsResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetSettingsRunner(accessToken)
+	runner.SetListStopwordsReq(req)
+
+	resp, err := s.sessions.TxExecute(ctx, runner, search.SessionOptions{
+		IncVersion: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Response.(*api.ListStopwordsResponse), nil
+}
+
+func (s *searchService) UpsertCurationRule(ctx context.Context, req *api.UpsertCurationRuleRequest) (*api.UpsertCurationRuleResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetSettingsRunner(accessToken)
+	runner.SetUpsertCurationRuleReq(req)
+
+	resp, err := s.sessions.TxExecute(ctx, runner, search.SessionOptions{
+		IncVersion: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.UpsertCurationRuleResponse{
+		Status: resp.Status,
+	}, nil
+}
+
+func (s *searchService) DeleteCurationRule(ctx context.Context, req *api.DeleteCurationRuleRequest) (*api.DeleteCurationRuleResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetSettingsRunner(accessToken)
+	runner.SetDeleteCurationRuleReq(req)
+
+	resp, err := s.sessions.TxExecute(ctx, runner, search.SessionOptions{
+		IncVersion: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.DeleteCurationRuleResponse{
+		Status: resp.Status,
+	}, nil
+}
+
+func (s *searchService) ListCurationRules(ctx context.Context, req *api.ListCurationRulesRequest) (*api.ListCurationRulesResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetSettingsRunner(accessToken)
+	runner.SetListCurationRulesReq(req)
+
+	resp, err := s.sessions.TxExecute(ctx, runner, search.SessionOptions{
+		IncVersion: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Response.(*api.ListCurationRulesResponse), nil
+}
+
+func (s *searchService) GetTopQueries(ctx context.Context, req *api.GetTopQueriesRequest) (*api.GetTopQueriesResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetAnalyticsRunner(accessToken)
+	runner.SetGetTopQueriesReq(req)
+
+	resp, err := s.sessions.TxExecute(ctx, runner, search.SessionOptions{
+		IncVersion: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Response.(*api.GetTopQueriesResponse), nil
+}
+
+func (s *searchService) GetNoResultQueries(ctx context.Context, req *api.GetNoResultQueriesRequest) (*api.GetNoResultQueriesResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetAnalyticsRunner(accessToken)
+	runner.SetGetNoResultQueriesReq(req)
+
+	resp, err := s.sessions.TxExecute(ctx, runner, search.SessionOptions{
+		IncVersion: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Response.(*api.GetNoResultQueriesResponse), nil
+}
+
+func (s *searchService) SuggestQueries(ctx context.Context, req *api.SuggestQueriesRequest) (*api.SuggestQueriesResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetAnalyticsRunner(accessToken)
+	runner.SetSuggestQueriesReq(req)
+
+	resp, err := s.sessions.TxExecute(ctx, runner, search.SessionOptions{
+		IncVersion: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Response.(*api.SuggestQueriesResponse), nil
+}
+
 func (s *searchService) Get(ctx context.Context, req *api.GetDocumentRequest) (*api.GetDocumentResponse, error) {
 	accessToken, _ := request.GetAccessToken(ctx)
 
@@ -252,3 +456,15 @@ func (s *searchService) Search(req *api.SearchIndexRequest, stream api.Search_Se
 
 	return nil
 }
+
+func (s *searchService) MultiSearch(ctx context.Context, req *api.MultiSearchRequest) (*api.MultiSearchResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetMultiSearchRunner(req, accessToken)
+	resp, err := s.sessions.Execute(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Response.(*api.MultiSearchResponse), nil
+}
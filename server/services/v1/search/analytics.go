@@ -0,0 +1,189 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"context"
+	"time"
+
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/server/config"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/transaction"
+)
+
+const (
+	defaultAnalyticsDays  = 7
+	defaultAnalyticsLimit = 10
+)
+
+// AnalyticsRunner serves the search analytics recorded by the searches of an index or of a collection.
+type AnalyticsRunner struct {
+	*baseRunner
+
+	topQueries      *api.GetTopQueriesRequest
+	noResultQueries *api.GetNoResultQueriesRequest
+	suggestQueries  *api.SuggestQueriesRequest
+}
+
+func (runner *AnalyticsRunner) SetGetTopQueriesReq(req *api.GetTopQueriesRequest) {
+	runner.topQueries = req
+}
+
+func (runner *AnalyticsRunner) SetGetNoResultQueriesReq(req *api.GetNoResultQueriesRequest) {
+	runner.noResultQueries = req
+}
+
+func (runner *AnalyticsRunner) SetSuggestQueriesReq(req *api.SuggestQueriesRequest) {
+	runner.suggestQueries = req
+}
+
+func (runner *AnalyticsRunner) Run(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant) (Response, error) {
+	switch {
+	case runner.topQueries != nil:
+		req := runner.topQueries
+		stats, err := runner.rollup(ctx, tx, tenant, req.GetProject(), req.GetIndex(), req.GetCollection(), req.GetBranch(), req.GetDays())
+		if err != nil {
+			return Response{}, err
+		}
+
+		return Response{
+			Response: &api.GetTopQueriesResponse{
+				Queries: queryStatsToAPI(metadata.TopQueries(stats, analyticsLimit(req.GetLimit()))),
+			},
+		}, nil
+	case runner.noResultQueries != nil:
+		req := runner.noResultQueries
+		stats, err := runner.rollup(ctx, tx, tenant, req.GetProject(), req.GetIndex(), req.GetCollection(), req.GetBranch(), req.GetDays())
+		if err != nil {
+			return Response{}, err
+		}
+
+		return Response{
+			Response: &api.GetNoResultQueriesResponse{
+				Queries: queryStatsToAPI(metadata.NoResultQueries(stats, analyticsLimit(req.GetLimit()))),
+			},
+		}, nil
+	case runner.suggestQueries != nil:
+		req := runner.suggestQueries
+		target, err := runner.target(ctx, tx, tenant, req.GetProject(), req.GetIndex(), req.GetCollection(), req.GetBranch())
+		if err != nil {
+			return Response{}, err
+		}
+
+		// only the queries starting with the prefix are read
+		stats, err := tenant.MetaStore.SearchAnalytics().RollupPrefix(ctx, tx, target, req.GetPrefix(), analyticsDays(req.GetDays()), time.Now())
+		if err != nil {
+			return Response{}, err
+		}
+
+		resp := &api.SuggestQueriesResponse{}
+		for _, st := range metadata.SuggestQueries(stats, req.GetPrefix(), analyticsLimit(req.GetLimit())) {
+			resp.Suggestions = append(resp.Suggestions, st.Query)
+		}
+
+		return Response{
+			Response: resp,
+		}, nil
+	}
+
+	return Response{}, errors.Unknown("unknown request path")
+}
+
+// rollup validates that the index or the collection exists and returns the stats of its queries over the last days.
+func (runner *AnalyticsRunner) rollup(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant, projName string,
+	indexName string, collName string, branch string, days int32,
+) ([]*metadata.QueryStats, error) {
+	target, err := runner.target(ctx, tx, tenant, projName, indexName, collName, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	return tenant.MetaStore.SearchAnalytics().Rollup(ctx, tx, target, analyticsDays(days), time.Now())
+}
+
+// target validates that the index or the collection exists and returns the target its queries are recorded for.
+func (*AnalyticsRunner) target(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant, projName string,
+	indexName string, collName string, branch string,
+) (*metadata.SearchAnalyticsTarget, error) {
+	project, err := tenant.GetProject(projName)
+	if err != nil {
+		return nil, createApiError(err)
+	}
+
+	target := &metadata.SearchAnalyticsTarget{
+		NamespaceId: tenant.GetNamespace().Id(),
+		Project:     projName,
+	}
+	if len(collName) > 0 {
+		dbName := metadata.NewDatabaseNameWithBranch(projName, branch)
+		db, err := project.GetDatabase(dbName)
+		if err != nil {
+			return nil, createApiError(err)
+		}
+		if db.GetCollection(collName) == nil {
+			return nil, errors.NotFound("collection doesn't exist '%s'", collName)
+		}
+
+		target.Branch = dbName.Branch()
+		target.Collection = collName
+	} else {
+		index, err := tenant.GetSearchIndex(ctx, tx, project, indexName)
+		if err != nil {
+			return nil, createApiError(err)
+		}
+
+		target.Index = index.Name
+	}
+
+	return target, nil
+}
+
+// analyticsDays returns the requested number of days, bounded by the retention of the rollups.
+func analyticsDays(days int32) int {
+	d := int(days)
+	if d == 0 {
+		d = defaultAnalyticsDays
+	}
+
+	if retention := config.DefaultConfig.Search.Analytics.RetentionDays; retention > 0 && d > retention {
+		d = retention
+	}
+
+	return d
+}
+
+func analyticsLimit(limit int32) int {
+	if limit == 0 {
+		return defaultAnalyticsLimit
+	}
+
+	return int(limit)
+}
+
+func queryStatsToAPI(stats []*metadata.QueryStats) []*api.QueryAnalytics {
+	queries := make([]*api.QueryAnalytics, 0, len(stats))
+	for _, st := range stats {
+		queries = append(queries, &api.QueryAnalytics{
+			Query:     st.Query,
+			Searches:  st.Searches,
+			NoResults: st.NoResults,
+			Found:     st.Found,
+		})
+	}
+
+	return queries
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/tigrisdata/tigris/keys"
 	"github.com/tigrisdata/tigris/schema"
 	"github.com/tigrisdata/tigris/server/config"
+	"github.com/tigrisdata/tigris/server/embedding"
 	"github.com/tigrisdata/tigris/server/metadata"
 	"github.com/tigrisdata/tigris/server/metrics"
 	"github.com/tigrisdata/tigris/server/transaction"
@@ -74,11 +75,24 @@ func (*baseRunner) encodeDocuments(ctx context.Context, index *schema.SearchInde
 		serializedDocs = make([][]byte, len(documents))
 	)
 
+	docs := make([]map[string]any, len(documents))
 	for i, raw := range documents {
 		metrics.AddSearchBytesInContext(ctx, int64(len(raw)))
-		doc, err := util.JSONToMap(raw)
-		if err != nil {
-			docErrors[i] = err
+		docs[i], docErrors[i] = util.JSONToMap(raw)
+	}
+
+	// compute the vectors derived from the text fields of all the documents together
+	if err := embedding.EmbedDocuments(ctx, index.Fields, docs); err != nil {
+		for i := range docErrors {
+			if docErrors[i] == nil {
+				docErrors[i] = err
+			}
+		}
+	}
+
+	for i, raw := range documents {
+		doc := docs[i]
+		if docErrors[i] != nil {
 			continue
 		}
 
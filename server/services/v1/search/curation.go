@@ -0,0 +1,174 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/query/filter"
+	qsearch "github.com/tigrisdata/tigris/query/search"
+	"github.com/tigrisdata/tigris/query/sort"
+	"github.com/tigrisdata/tigris/schema"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// curation is the outcome of the curation rules of an index that match a search query.
+type curation struct {
+	pinned  []qsearch.PinnedHit
+	hidden  []string
+	filters []jsoniter.RawMessage
+	sort    jsoniter.RawMessage
+}
+
+// newCuration merges the rules that are active at "now" and match the query, in the order these are defined. The first
+// rule pinning a document decides its position, and a pinned document is never hidden.
+func newCuration(rules []schema.CurationRule, q string, now time.Time) *curation {
+	c := &curation{}
+	pinned := make(map[string]struct{})
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Matches(q, now) {
+			continue
+		}
+
+		for _, p := range rule.Pins {
+			if _, ok := pinned[p.Id]; !ok {
+				pinned[p.Id] = struct{}{}
+				c.pinned = append(c.pinned, qsearch.PinnedHit{Id: p.Id, Position: p.Position})
+			}
+		}
+		c.hidden = append(c.hidden, rule.Hides...)
+		if len(rule.Filter) > 0 {
+			c.filters = append(c.filters, rule.Filter)
+		}
+		if len(c.sort) == 0 && len(rule.Sort) > 0 {
+			c.sort = rule.Sort
+		}
+	}
+
+	hidden := c.hidden[:0]
+	for _, id := range c.hidden {
+		if _, ok := pinned[id]; !ok {
+			hidden = append(hidden, id)
+		}
+	}
+	c.hidden = hidden
+
+	return c
+}
+
+// applyFilter returns the filter of the request ANDed with the filters of the matching rules.
+func (c *curation) applyFilter(reqFilter []byte) ([]byte, error) {
+	if len(c.filters) == 0 {
+		return reqFilter, nil
+	}
+
+	filters := c.filters
+	if !filter.None(reqFilter) {
+		filters = append([]jsoniter.RawMessage{reqFilter}, filters...)
+	}
+	if len(filters) == 1 {
+		return filters[0], nil
+	}
+
+	return jsoniter.Marshal(map[string][]jsoniter.RawMessage{
+		string(filter.AndOP): filters,
+	})
+}
+
+// applySort returns the sort order of the request, or the sort order of the first matching rule defining one.
+func (c *curation) applySort(reqSort []byte) []byte {
+	if len(reqSort) > 0 || len(c.sort) == 0 {
+		return reqSort
+	}
+
+	return c.sort
+}
+
+// validateCurationRule checks the rule and that its filter and sort order can be applied to the index.
+func validateCurationRule(index *schema.SearchIndex, rule *schema.CurationRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := filter.NewFactory(index.QueryableFields, nil).WrappedFilter(rule.Filter); err != nil {
+		return err
+	}
+
+	ordering, err := sort.UnmarshalSort(rule.Sort)
+	if err != nil || ordering == nil {
+		return err
+	}
+	for _, sf := range *ordering {
+		cf, err := index.GetQueryableField(sf.Name)
+		if err != nil {
+			return err
+		}
+		if !cf.Sortable {
+			return errors.InvalidArgument("Cannot sort on `%s` field", sf.Name)
+		}
+	}
+
+	return nil
+}
+
+func curationRuleFromAPI(r *api.CurationRule) schema.CurationRule {
+	rule := schema.CurationRule{
+		Id:      r.GetId(),
+		Pattern: r.GetPattern(),
+		Match:   schema.CurationMatch(r.GetMatch()),
+		Hides:   r.GetHides(),
+		Filter:  r.GetFilter(),
+		Sort:    r.GetSort(),
+	}
+	for _, p := range r.GetPins() {
+		rule.Pins = append(rule.Pins, schema.CurationPin{Id: p.GetId(), Position: int(p.GetPosition())})
+	}
+	if r.GetValidFrom() != nil {
+		t := r.GetValidFrom().AsTime()
+		rule.ValidFrom = &t
+	}
+	if r.GetValidUntil() != nil {
+		t := r.GetValidUntil().AsTime()
+		rule.ValidUntil = &t
+	}
+
+	return rule
+}
+
+func curationRuleToAPI(rule *schema.CurationRule) *api.CurationRule {
+	r := &api.CurationRule{
+		Id:      rule.Id,
+		Pattern: rule.Pattern,
+		Match:   string(rule.Match),
+		Hides:   rule.Hides,
+		Filter:  rule.Filter,
+		Sort:    rule.Sort,
+	}
+	for _, p := range rule.Pins {
+		r.Pins = append(r.Pins, &api.CurationPin{Id: p.Id, Position: int32(p.Position)})
+	}
+	if rule.ValidFrom != nil {
+		r.ValidFrom = timestamppb.New(*rule.ValidFrom)
+	}
+	if rule.ValidUntil != nil {
+		r.ValidUntil = timestamppb.New(*rule.ValidUntil)
+	}
+
+	return r
+}
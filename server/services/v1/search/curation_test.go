@@ -0,0 +1,91 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	qsearch "github.com/tigrisdata/tigris/query/search"
+	"github.com/tigrisdata/tigris/schema"
+)
+
+func TestCuration(t *testing.T) {
+	now := time.Now()
+	expired := now.Add(-time.Hour)
+	rules := []schema.CurationRule{
+		{
+			Id:      "tv",
+			Pattern: "tv",
+			Pins:    []schema.CurationPin{{Id: "1", Position: 1}, {Id: "2", Position: 3}},
+			Hides:   []string{"3", "4"},
+			Filter:  []byte(`{"in_stock":true}`),
+		},
+		{
+			Id:      "all_tv",
+			Pattern: "tv",
+			Match:   schema.CurationMatchContains,
+			Pins:    []schema.CurationPin{{Id: "2", Position: 2}},
+			Hides:   []string{"1"},
+			Filter:  []byte(`{"brand":"acme"}`),
+			Sort:    []byte(`[{"price":"$asc"}]`),
+		},
+		{
+			Id:         "expired",
+			Pattern:    "tv",
+			Hides:      []string{"5"},
+			ValidUntil: &expired,
+		},
+	}
+
+	t.Run("no_match", func(t *testing.T) {
+		c := newCuration(rules, "radio", now)
+		require.Empty(t, c.pinned)
+		require.Empty(t, c.hidden)
+
+		f, err := c.applyFilter([]byte(`{"a":1}`))
+		require.NoError(t, err)
+		require.Equal(t, `{"a":1}`, string(f))
+		require.Nil(t, c.applySort(nil))
+	})
+
+	t.Run("match", func(t *testing.T) {
+		c := newCuration(rules, "TV", now)
+		require.Equal(t, []qsearch.PinnedHit{{Id: "1", Position: 1}, {Id: "2", Position: 3}}, c.pinned)
+		require.Equal(t, []string{"3", "4"}, c.hidden)
+
+		f, err := c.applyFilter(nil)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"$and":[{"in_stock":true},{"brand":"acme"}]}`, string(f))
+
+		f, err = c.applyFilter([]byte(`{"a":1}`))
+		require.NoError(t, err)
+		require.JSONEq(t, `{"$and":[{"a":1},{"in_stock":true},{"brand":"acme"}]}`, string(f))
+
+		require.Equal(t, `[{"price":"$asc"}]`, string(c.applySort(nil)))
+		require.Equal(t, `[{"name":"$asc"}]`, string(c.applySort([]byte(`[{"name":"$asc"}]`))))
+	})
+
+	t.Run("single_filter", func(t *testing.T) {
+		c := newCuration(rules, "smart tv", now)
+		require.Equal(t, []qsearch.PinnedHit{{Id: "2", Position: 2}}, c.pinned)
+		require.Equal(t, []string{"1"}, c.hidden)
+
+		f, err := c.applyFilter([]byte(`{}`))
+		require.NoError(t, err)
+		require.Equal(t, `{"brand":"acme"}`, string(f))
+	})
+}
@@ -0,0 +1,257 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/lib/container"
+	"github.com/tigrisdata/tigris/query/filter"
+	qsearch "github.com/tigrisdata/tigris/query/search"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/metrics"
+	"github.com/tigrisdata/tigris/store/search"
+)
+
+// maxMergedHits bounds the hits read from every index to merge them, the merged ranking is built from the first
+// pages of every index up to the requested page.
+const maxMergedHits = 250
+
+// mergeRankConstant is the constant of the reciprocal rank fusion of the merged ranking, it is the usual default that
+// the hybrid search uses as well.
+const mergeRankConstant = 60
+
+// MultiSearchRunner runs the searches of a request against multiple indexes concurrently. The results are either
+// returned per index or merged in a single ranking where every hit is labeled with its index.
+type MultiSearchRunner struct {
+	*baseRunner
+
+	req *api.MultiSearchRequest
+}
+
+// indexSearch is a single search of the multi search request.
+type indexSearch struct {
+	index   *schema.SearchIndex
+	filter  *filter.WrappedFilter
+	query   *qsearch.Query
+	hits    []*api.SearchHit
+	group   []*api.GroupedSearchHits
+	facets  map[string]*api.SearchFacet
+	found   int64
+	matched []string
+	err     error
+}
+
+func (s *indexSearch) run(ctx context.Context, store search.Store, pageNo int32) {
+	iterator := NewReader(ctx, store, s.index, s.query).SinglePageIterator(s.index, s.filter, pageNo)
+
+	matchedFields := container.NewHashSet()
+	var rows ResultRow
+	for iterator.Next(&rows) {
+		var hits []*api.SearchHit
+		for _, row := range rows.Rows {
+			hit, err := newSearchHit(s.query, row)
+			if err != nil {
+				s.err = err
+				return
+			}
+
+			if hit.Metadata.Match != nil {
+				for _, f := range hit.Metadata.Match.Fields {
+					if f != nil {
+						matchedFields.Insert(f.Name)
+					}
+				}
+			}
+			hits = append(hits, hit)
+		}
+
+		if len(rows.Group) > 0 {
+			s.group = append(s.group, &api.GroupedSearchHits{
+				GroupKeys: rows.Group,
+				Hits:      hits,
+			})
+		} else {
+			s.hits = append(s.hits, hits...)
+		}
+
+		if len(s.hits) == s.query.PageSize || len(s.group) == s.query.PageSize {
+			break
+		}
+	}
+
+	s.err = iterator.Interrupted()
+	s.facets = iterator.getFacets()
+	s.found = iterator.getTotalFound()
+	s.matched = matchedFields.ToList()
+}
+
+func (runner *MultiSearchRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	reqStatus, reqStatusExists := metrics.RequestStatusFromContext(ctx)
+	if reqStatus != nil && reqStatusExists {
+		reqStatus.SetApiSearchType()
+	}
+
+	pageSize := int(runner.req.PageSize)
+	if pageSize == 0 {
+		pageSize = defaultPerPage
+	}
+	pageNo := runner.req.Page
+	if pageNo == 0 {
+		pageNo = defaultPageNo
+	}
+
+	// the merged ranking needs all the hits of every index up to the requested page
+	readPage, readSize := pageNo, pageSize
+	if runner.req.Merge {
+		readPage, readSize = defaultPageNo, int(pageNo)*pageSize
+		if readSize > maxMergedHits {
+			return Response{}, errors.InvalidArgument("merged search can only return the first %d hits, "+
+				"reduce 'page' or 'page_size'", maxMergedHits)
+		}
+	}
+
+	searches := make([]*indexSearch, len(runner.req.Searches))
+	for i, req := range runner.req.Searches {
+		// the pagination is shared by all the searches
+		req.Project = runner.req.Project
+		req.Page = readPage
+		req.PageSize = int32(readSize)
+
+		sr := &SearchRunner{baseRunner: runner.baseRunner, req: req}
+		index, wrappedF, searchQ, err := sr.buildQuery(ctx, tenant)
+		if err != nil {
+			return Response{}, err
+		}
+		if runner.req.Merge && searchQ.IsGroupByQuery() {
+			return Response{}, errors.InvalidArgument("group_by is not supported when the results are merged")
+		}
+
+		searches[i] = &indexSearch{index: index, filter: wrappedF, query: searchQ}
+	}
+
+	var wg sync.WaitGroup
+	for _, s := range searches {
+		wg.Add(1)
+		go func(s *indexSearch) {
+			defer wg.Done()
+			s.run(ctx, runner.store, readPage)
+		}(s)
+	}
+	wg.Wait()
+
+	resp := &api.MultiSearchResponse{}
+	var found int64
+	for i, s := range searches {
+		if s.err != nil {
+			return Response{}, s.err
+		}
+
+		result := &api.MultiSearchResult{
+			Index:  s.index.Name,
+			Facets: s.facets,
+			Meta: &api.SearchMetadata{
+				Found:      s.found,
+				TotalPages: int32(math.Ceil(float64(s.found) / float64(pageSize))),
+				Page: &api.Page{
+					Current: pageNo,
+					Size:    int32(pageSize),
+				},
+				MatchedFields: s.matched,
+			},
+		}
+		if !runner.req.Merge {
+			result.Hits = s.hits
+			result.Group = s.group
+		}
+		resp.Results = append(resp.Results, result)
+		found += s.found
+
+		if pageNo <= defaultPageNo {
+			tenant.MetaStore.SearchAnalytics().RecordSearch(ctx, runner.txMgr, &metadata.SearchAnalyticsTarget{
+				NamespaceId: tenant.GetNamespace().Id(),
+				Project:     runner.req.Project,
+				Index:       s.index.Name,
+			}, runner.req.Searches[i].Q, s.found)
+		}
+	}
+
+	if runner.req.Merge {
+		resp.Hits = mergeHits(searches, int(pageNo-1)*pageSize, pageSize)
+		resp.Meta = &api.SearchMetadata{
+			Found:      found,
+			TotalPages: int32(math.Ceil(float64(found) / float64(pageSize))),
+			Page: &api.Page{
+				Current: pageNo,
+				Size:    int32(pageSize),
+			},
+		}
+	}
+
+	return Response{
+		Response: resp,
+	}, nil
+}
+
+// mergeHits fuses the rankings of all the searches with reciprocal rank fusion and returns the page starting at
+// offset. The scores of the indexes are not comparable, the text match depends on the documents and the fields of
+// every index and the vector search hits don't have one, so a hit is only ranked by its position in its index. The
+// hits with the same rank are in the order of the searches.
+func mergeHits(searches []*indexSearch, offset int, size int) []*api.MultiSearchHit {
+	type ranked struct {
+		search int
+		score  float64
+		hit    *api.SearchHit
+	}
+
+	var all []ranked
+	for i, s := range searches {
+		for rank, hit := range s.hits {
+			all = append(all, ranked{search: i, score: 1 / float64(mergeRankConstant+rank+1), hit: hit})
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].score != all[j].score {
+			return all[i].score > all[j].score
+		}
+		return all[i].search < all[j].search
+	})
+
+	if offset >= len(all) {
+		return nil
+	}
+	all = all[offset:]
+	if len(all) > size {
+		all = all[:size]
+	}
+
+	hits := make([]*api.MultiSearchHit, 0, len(all))
+	for _, r := range all {
+		hits = append(hits, &api.MultiSearchHit{
+			Index:    searches[r.search].index.Name,
+			Data:     r.hit.Data,
+			Metadata: r.hit.Metadata,
+		})
+	}
+
+	return hits
+}
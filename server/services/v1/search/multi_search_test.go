@@ -0,0 +1,103 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/schema"
+)
+
+func TestMergeHits(t *testing.T) {
+	hit := func(id string, score string) *api.SearchHit {
+		return &api.SearchHit{
+			Data:     []byte(`{"id":"` + id + `"}`),
+			Metadata: &api.SearchHitMeta{Match: &api.Match{Score: score}},
+		}
+	}
+
+	searches := []*indexSearch{
+		{
+			index: &schema.SearchIndex{Name: "products"},
+			hits:  []*api.SearchHit{hit("p1", "300"), hit("p2", "100")},
+		},
+		{
+			index: &schema.SearchIndex{Name: "articles"},
+			hits:  []*api.SearchHit{hit("a1", "200"), hit("a2", "100"), hit("a3", "")},
+		},
+		{
+			index: &schema.SearchIndex{Name: "users"},
+			hits:  []*api.SearchHit{hit("u1", "")},
+		},
+	}
+
+	merged := func(offset int, size int) []string {
+		var labels []string
+		for _, h := range mergeHits(searches, offset, size) {
+			labels = append(labels, h.Index+":"+string(h.Data))
+		}
+		return labels
+	}
+
+	require.Equal(t, []string{
+		`products:{"id":"p1"}`,
+		`articles:{"id":"a1"}`,
+		`users:{"id":"u1"}`,
+		`products:{"id":"p2"}`,
+		`articles:{"id":"a2"}`,
+		`articles:{"id":"a3"}`,
+	}, merged(0, 10))
+
+	// same rank, the hits are in the order of the searches
+	require.Equal(t, []string{`users:{"id":"u1"}`, `products:{"id":"p2"}`}, merged(2, 2))
+	require.Empty(t, merged(6, 2))
+}
+
+func TestMergeHitsScoreScales(t *testing.T) {
+	hit := func(id string, score string) *api.SearchHit {
+		return &api.SearchHit{
+			Data:     []byte(`{"id":"` + id + `"}`),
+			Metadata: &api.SearchHitMeta{Match: &api.Match{Score: score}},
+		}
+	}
+
+	// the text match of the first index is orders of magnitude higher, it must not push the other index out of the
+	// first page
+	searches := []*indexSearch{
+		{
+			index: &schema.SearchIndex{Name: "products"},
+			hits: []*api.SearchHit{
+				hit("p1", "1736172387017490433"), hit("p2", "1736172387017490000"), hit("p3", "1736172387017400000"),
+			},
+		},
+		{
+			index: &schema.SearchIndex{Name: "articles"},
+			hits:  []*api.SearchHit{hit("a1", "578730123365187705"), hit("a2", "100")},
+		},
+	}
+
+	var labels []string
+	for _, h := range mergeHits(searches, 0, 4) {
+		labels = append(labels, h.Index+":"+string(h.Data))
+	}
+	require.Equal(t, []string{
+		`products:{"id":"p1"}`,
+		`articles:{"id":"a1"}`,
+		`products:{"id":"p2"}`,
+		`articles:{"id":"a2"}`,
+	}, labels)
+}
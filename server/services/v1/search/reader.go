@@ -143,7 +143,7 @@ func (p *pageReader) read() error {
 	if len(p.cachedFacets) == 0 {
 		if len(result) > 0 {
 			builder := tsearch.NewFacetResponse(p.query.Facets)
-			for field, built := range builder.Build(result[0].FacetCounts) {
+			for field, built := range builder.Build(result[0].Facets) {
 				p.cachedFacets[field] = built
 			}
 		}
@@ -152,9 +152,7 @@ func (p *pageReader) read() error {
 	if p.found == -1 {
 		p.found = 0
 		for _, r := range result {
-			if r.Found != nil {
-				p.found += int64(*r.Found)
-			}
+			p.found += int64(r.Found)
 		}
 	}
 	return nil
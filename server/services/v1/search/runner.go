@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"time"
 
 	"github.com/buger/jsonparser"
 	jsoniter "github.com/json-iterator/go"
@@ -34,6 +35,7 @@ import (
 	"github.com/tigrisdata/tigris/query/sort"
 	"github.com/tigrisdata/tigris/schema"
 	"github.com/tigrisdata/tigris/server/config"
+	"github.com/tigrisdata/tigris/server/embedding"
 	"github.com/tigrisdata/tigris/server/metadata"
 	"github.com/tigrisdata/tigris/server/metrics"
 	"github.com/tigrisdata/tigris/server/request"
@@ -91,6 +93,13 @@ func (f *RunnerFactory) GetSearchRunner(r *api.SearchIndexRequest, streaming Str
 	}
 }
 
+func (f *RunnerFactory) GetMultiSearchRunner(r *api.MultiSearchRequest, accessToken *types.AccessToken) *MultiSearchRunner {
+	return &MultiSearchRunner{
+		baseRunner: newBaseRunner(f.store, f.encoder, f.txMgr, accessToken),
+		req:        r,
+	}
+}
+
 func (f *RunnerFactory) GetCreateRunner(accessToken *types.AccessToken) *CreateRunner {
 	return &CreateRunner{
 		baseRunner: newBaseRunner(f.store, f.encoder, f.txMgr, accessToken),
@@ -111,6 +120,18 @@ func (f *RunnerFactory) GetUpdateQueryRunner(r *api.UpdateDocumentRequest, acces
 	}
 }
 
+func (f *RunnerFactory) GetSettingsRunner(accessToken *types.AccessToken) *SettingsRunner {
+	return &SettingsRunner{
+		baseRunner: newBaseRunner(f.store, f.encoder, f.txMgr, accessToken),
+	}
+}
+
+func (f *RunnerFactory) GetAnalyticsRunner(accessToken *types.AccessToken) *AnalyticsRunner {
+	return &AnalyticsRunner{
+		baseRunner: newBaseRunner(f.store, f.encoder, f.txMgr, accessToken),
+	}
+}
+
 func (f *RunnerFactory) GetDeleteQueryRunner(accessToken *types.AccessToken) *DeleteRunner {
 	return &DeleteRunner{
 		baseRunner: newBaseRunner(f.store, f.encoder, f.txMgr, accessToken),
@@ -137,10 +158,10 @@ func (runner *ReadRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Res
 		return Response{}, err
 	}
 
-	idToHits := make(map[string]*map[string]any)
-	for _, hit := range *result.Hits {
+	idToHits := make(map[string]map[string]any)
+	for _, hit := range result.Hits {
 		// at this point we can safely rely on accessing "schema.SearchId" because we always inject it as top level key.
-		idToHits[(*hit.Document)[schema.SearchId].(string)] = hit.Document
+		idToHits[hit.Document[schema.SearchId].(string)] = hit.Document
 	}
 
 	transformer := newReadTransformer(index)
@@ -153,7 +174,7 @@ func (runner *ReadRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Res
 			continue
 		}
 
-		doc, created, updated, err := transformer.fromSearch(*outDoc)
+		doc, created, updated, err := transformer.fromSearch(outDoc)
 		if err != nil {
 			return Response{}, err
 		}
@@ -286,6 +307,9 @@ func (runner *CreateRunner) createDocumentById(ctx context.Context, tenant *meta
 	if err != nil {
 		return Response{}, err
 	}
+	if err = embedding.EmbedDocuments(ctx, index.Fields, []map[string]any{decDoc}); err != nil {
+		return Response{}, err
+	}
 
 	transformer := newWriteTransformer(index, internal.NewTimestamp(), false)
 	id, err := transformer.getOrGenerateId(req.Document, decDoc)
@@ -579,60 +603,72 @@ type SearchRunner struct {
 	streaming Streaming
 }
 
-func (runner *SearchRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
-	reqStatus, reqStatusExists := metrics.RequestStatusFromContext(ctx)
-	if reqStatus != nil && reqStatusExists {
-		reqStatus.SetApiSearchType()
+// buildQuery validates the search request against the index and builds the query sent to the search store.
+func (runner *SearchRunner) buildQuery(ctx context.Context, tenant *metadata.Tenant) (*schema.SearchIndex, *filter.WrappedFilter, *qsearch.Query, error) {
+	index, err := runner.getIndex(tenant, runner.req.GetProject(), runner.req.GetIndex())
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
-	index, err := runner.getIndex(tenant, runner.req.GetProject(), runner.req.GetIndex())
+	curated := newCuration(index.CurationRules, runner.req.Q, time.Now())
+	reqFilter, err := curated.applyFilter(runner.req.Filter)
 	if err != nil {
-		return Response{}, err
+		return nil, nil, nil, err
 	}
 
-	wrappedF, err := filter.NewFactory(index.QueryableFields, value.NewCollationFrom(runner.req.Collation)).WrappedFilter(runner.req.Filter)
+	wrappedF, err := filter.NewFactory(index.QueryableFields, value.NewCollationFrom(runner.req.Collation)).WrappedFilter(reqFilter)
 	if err != nil {
-		return Response{}, err
+		return nil, nil, nil, err
 	}
 
 	facets, err := runner.getFacetFields(index)
 	if err != nil {
-		return Response{}, err
+		return nil, nil, nil, err
 	}
 
 	fieldSelection, err := runner.getFieldSelection(index)
 	if err != nil {
-		return Response{}, err
+		return nil, nil, nil, err
 	}
 
 	searchFields, err := runner.getSearchFields(index)
 	if err != nil {
-		return Response{}, err
+		return nil, nil, nil, err
 	}
 
-	sortOrder, err := runner.getSortOrdering(index)
+	sortOrder, err := runner.getSortOrdering(index, curated.applySort(runner.req.Sort))
 	if err != nil {
-		return Response{}, err
+		return nil, nil, nil, err
 	}
 
 	groupBy, err := runner.getGroupBy(index)
 	if err != nil {
-		return Response{}, err
+		return nil, nil, nil, err
 	}
 
-	vecSearch, err := runner.getVectorSearch(index)
+	vecSearch, err := runner.getVectorSearch(ctx, index)
 	if err != nil {
-		return Response{}, err
+		return nil, nil, nil, err
+	}
+
+	hybrid, err := qsearch.UnmarshalHybridSearch(runner.req.Hybrid)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	relevance, err := runner.getRelevance(index)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
 	pageSize := int(runner.req.PageSize)
 	if pageSize == 0 {
 		pageSize = defaultPerPage
 	}
-	var totalPages *int32
 
 	searchQ := qsearch.NewBuilder().
 		Query(runner.req.Q).
+		Stopwords(index.Stopwords).
 		SearchFields(searchFields).
 		Facets(facets).
 		PageSize(pageSize).
@@ -641,11 +677,33 @@ func (runner *SearchRunner) Run(ctx context.Context, tenant *metadata.Tenant) (R
 		SortOrder(sortOrder).
 		GroupBy(groupBy).
 		VectorSearch(vecSearch).
+		Hybrid(hybrid).
+		Relevance(relevance).
+		Analyzers(schema.SearchAnalyzers(index.QueryableFields)).
+		PinnedHits(curated.pinned).
+		HiddenHits(curated.hidden).
 		Build()
-	if searchQ.IsQAndVectorBoth() {
-		return Response{}, errors.InvalidArgument("Currently either full text or vector search is supported")
+	if err = searchQ.ValidateSearchMode(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return index, wrappedF, searchQ, nil
+}
+
+func (runner *SearchRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	reqStatus, reqStatusExists := metrics.RequestStatusFromContext(ctx)
+	if reqStatus != nil && reqStatusExists {
+		reqStatus.SetApiSearchType()
 	}
 
+	index, wrappedF, searchQ, err := runner.buildQuery(ctx, tenant)
+	if err != nil {
+		return Response{}, err
+	}
+
+	pageSize := searchQ.PageSize
+	var totalPages *int32
+
 	searchReader := NewReader(ctx, runner.store, index, searchQ)
 	var iterator *FilterableSearchIterator
 	if runner.req.Page != 0 {
@@ -669,35 +727,20 @@ func (runner *SearchRunner) Run(ctx context.Context, tenant *metadata.Tenant) (R
 		for iterator.Next(&rows) {
 			var indexedDocs []*api.SearchHit
 			for _, row := range rows.Rows {
-				if searchQ.ReadFields != nil {
-					// apply field selection
-					newValue, err := searchQ.ReadFields.Apply(row.Document)
-					if ulog.E(err) {
-						return Response{}, err
-					}
-					row.Document = newValue
+				hit, err := newSearchHit(searchQ, row)
+				if err != nil {
+					return Response{}, err
 				}
 
-				metadata := &api.SearchHitMeta{}
-				if row.CreatedAt != nil {
-					metadata.CreatedAt = row.CreatedAt.GetProtoTS()
-				}
-				if row.UpdatedAt != nil {
-					metadata.UpdatedAt = row.UpdatedAt.GetProtoTS()
-				}
-				metadata.Match = row.Match
-				if metadata.Match != nil {
-					for _, f := range metadata.Match.Fields {
+				if hit.Metadata.Match != nil {
+					for _, f := range hit.Metadata.Match.Fields {
 						if f != nil {
 							matchedFields.Insert(f.Name)
 						}
 					}
 				}
 
-				indexedDocs = append(indexedDocs, &api.SearchHit{
-					Data:     row.Document,
-					Metadata: metadata,
-				})
+				indexedDocs = append(indexedDocs, hit)
 			}
 
 			if len(rows.Group) > 0 {
@@ -749,9 +792,44 @@ func (runner *SearchRunner) Run(ctx context.Context, tenant *metadata.Tenant) (R
 		pageNo++
 	}
 
+	if runner.req.Page <= defaultPageNo {
+		// paginating through the results is not recorded as another search
+		tenant.MetaStore.SearchAnalytics().RecordSearch(ctx, runner.txMgr, &metadata.SearchAnalyticsTarget{
+			NamespaceId: tenant.GetNamespace().Id(),
+			Project:     runner.req.GetProject(),
+			Index:       index.Name,
+		}, runner.req.Q, iterator.getTotalFound())
+	}
+
 	return Response{}, nil
 }
 
+// newSearchHit applies the field selection of the query to the row and builds the hit returned to the caller.
+func newSearchHit(searchQ *qsearch.Query, row *Row) (*api.SearchHit, error) {
+	if searchQ.ReadFields != nil {
+		// apply field selection
+		newValue, err := searchQ.ReadFields.Apply(row.Document)
+		if ulog.E(err) {
+			return nil, err
+		}
+		row.Document = newValue
+	}
+
+	metadata := &api.SearchHitMeta{}
+	if row.CreatedAt != nil {
+		metadata.CreatedAt = row.CreatedAt.GetProtoTS()
+	}
+	if row.UpdatedAt != nil {
+		metadata.UpdatedAt = row.UpdatedAt.GetProtoTS()
+	}
+	metadata.Match = row.Match
+
+	return &api.SearchHit{
+		Data:     row.Document,
+		Metadata: metadata,
+	}, nil
+}
+
 func (runner *SearchRunner) getSearchFields(index *schema.SearchIndex) ([]string, error) {
 	searchFields := runner.req.SearchFields
 	if len(searchFields) == 0 {
@@ -837,8 +915,8 @@ func (runner *SearchRunner) getFieldSelection(index *schema.SearchIndex) (*read.
 	return factory, nil
 }
 
-func (runner *SearchRunner) getSortOrdering(index *schema.SearchIndex) (*sort.Ordering, error) {
-	ordering, err := sort.UnmarshalSort(runner.req.Sort)
+func (*SearchRunner) getSortOrdering(index *schema.SearchIndex, reqSort []byte) (*sort.Ordering, error) {
+	ordering, err := sort.UnmarshalSort(reqSort)
 	if err != nil || ordering == nil {
 		return nil, err
 	}
@@ -884,7 +962,22 @@ func (runner *SearchRunner) getGroupBy(index *schema.SearchIndex) (qsearch.Group
 	return groupBy, nil
 }
 
-func (runner *SearchRunner) getVectorSearch(index *schema.SearchIndex) (qsearch.VectorSearch, error) {
+// getRelevance returns the relevance of the request completed by the defaults of the index.
+func (runner *SearchRunner) getRelevance(index *schema.SearchIndex) (*schema.SearchRelevance, error) {
+	relevance, err := schema.UnmarshalSearchRelevance(runner.req.Relevance)
+	if err != nil {
+		return nil, err
+	}
+
+	relevance = relevance.Merge(index.Relevance)
+	if err = relevance.Validate(index.QueryableFields); err != nil {
+		return nil, err
+	}
+
+	return relevance, nil
+}
+
+func (runner *SearchRunner) getVectorSearch(ctx context.Context, index *schema.SearchIndex) (qsearch.VectorSearch, error) {
 	vectorSearch, err := qsearch.UnmarshalVectorSearch(runner.req.Vector)
 	if err != nil {
 		return vectorSearch, err
@@ -900,6 +993,9 @@ func (runner *SearchRunner) getVectorSearch(index *schema.SearchIndex) (qsearch.
 	if f.DataType != schema.VectorType {
 		return qsearch.VectorSearch{}, errors.InvalidArgument("Cannot perform vector search on non-vector type, field `%s` is not a vector", f.FieldName)
 	}
+	if err = embedding.EmbedVectorSearch(ctx, f, &vectorSearch); err != nil {
+		return qsearch.VectorSearch{}, err
+	}
 	if f.Dimensions != nil && *f.Dimensions != len(vectorSearch.VectorV) {
 		return qsearch.VectorSearch{}, errors.InvalidArgument("query vector is not same size as dimensions, expected size: %d", *f.Dimensions)
 	}
@@ -1047,3 +1143,222 @@ func (runner *IndexRunner) Run(ctx context.Context, tx transaction.Tx, tenant *m
 
 	return Response{}, nil
 }
+
+// SettingsRunner manages the relevance settings of an index i.e. the synonyms, the stopword sets and the curation
+// rules.
+type SettingsRunner struct {
+	*baseRunner
+
+	upsertSynonym   *api.UpsertSynonymRequest
+	deleteSynonym   *api.DeleteSynonymRequest
+	listSynonyms    *api.ListSynonymsRequest
+	upsertStopwords *api.UpsertStopwordsRequest
+	deleteStopwords *api.DeleteStopwordsRequest
+	listStopwords   *api.ListStopwordsRequest
+	upsertRule      *api.UpsertCurationRuleRequest
+	deleteRule      *api.DeleteCurationRuleRequest
+	listRules       *api.ListCurationRulesRequest
+}
+
+func (runner *SettingsRunner) SetUpsertSynonymReq(req *api.UpsertSynonymRequest) {
+	runner.upsertSynonym = req
+}
+
+func (runner *SettingsRunner) SetDeleteSynonymReq(req *api.DeleteSynonymRequest) {
+	runner.deleteSynonym = req
+}
+
+func (runner *SettingsRunner) SetListSynonymsReq(req *api.ListSynonymsRequest) {
+	runner.listSynonyms = req
+}
+
+func (runner *SettingsRunner) SetUpsertStopwordsReq(req *api.UpsertStopwordsRequest) {
+	runner.upsertStopwords = req
+}
+
+func (runner *SettingsRunner) SetDeleteStopwordsReq(req *api.DeleteStopwordsRequest) {
+	runner.deleteStopwords = req
+}
+
+func (runner *SettingsRunner) SetListStopwordsReq(req *api.ListStopwordsRequest) {
+	runner.listStopwords = req
+}
+
+func (runner *SettingsRunner) SetUpsertCurationRuleReq(req *api.UpsertCurationRuleRequest) {
+	runner.upsertRule = req
+}
+
+func (runner *SettingsRunner) SetDeleteCurationRuleReq(req *api.DeleteCurationRuleRequest) {
+	runner.deleteRule = req
+}
+
+func (runner *SettingsRunner) SetListCurationRulesReq(req *api.ListCurationRulesRequest) {
+	runner.listRules = req
+}
+
+func (runner *SettingsRunner) Run(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant) (Response, error) {
+	switch {
+	case runner.upsertSynonym != nil:
+		project, err := tenant.GetProject(runner.upsertSynonym.GetProject())
+		if err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		synonym := runner.upsertSynonym.GetSynonym()
+		if err = tenant.UpsertSearchSynonym(ctx, tx, project, runner.upsertSynonym.GetIndex(), search.Synonym{
+			Id:       synonym.GetId(),
+			Root:     synonym.GetRoot(),
+			Synonyms: synonym.GetSynonyms(),
+		}); err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		return Response{
+			Status: database.UpdatedStatus,
+		}, nil
+	case runner.deleteSynonym != nil:
+		project, err := tenant.GetProject(runner.deleteSynonym.GetProject())
+		if err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		if err = tenant.DeleteSearchSynonym(ctx, tx, project, runner.deleteSynonym.GetIndex(), runner.deleteSynonym.GetId()); err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		return Response{
+			Status: database.DeletedStatus,
+		}, nil
+	case runner.listSynonyms != nil:
+		project, err := tenant.GetProject(runner.listSynonyms.GetProject())
+		if err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		synonyms, err := tenant.ListSearchSynonyms(ctx, tx, project, runner.listSynonyms.GetIndex())
+		if err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		resp := &api.ListSynonymsResponse{}
+		for _, s := range synonyms {
+			resp.Synonyms = append(resp.Synonyms, &api.Synonym{
+				Id:       s.Id,
+				Root:     s.Root,
+				Synonyms: s.Synonyms,
+			})
+		}
+
+		return Response{
+			Response: resp,
+		}, nil
+	case runner.upsertStopwords != nil:
+		project, err := tenant.GetProject(runner.upsertStopwords.GetProject())
+		if err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		set := runner.upsertStopwords.GetStopwordSet()
+		if err = tenant.UpsertSearchStopwords(ctx, tx, project, runner.upsertStopwords.GetIndex(), search.StopwordSet{
+			Id:        set.GetId(),
+			Stopwords: set.GetStopwords(),
+		}); err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		return Response{
+			Status: database.UpdatedStatus,
+		}, nil
+	case runner.deleteStopwords != nil:
+		project, err := tenant.GetProject(runner.deleteStopwords.GetProject())
+		if err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		if err = tenant.DeleteSearchStopwords(ctx, tx, project, runner.deleteStopwords.GetIndex(), runner.deleteStopwords.GetId()); err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		return Response{
+			Status: database.DeletedStatus,
+		}, nil
+	case runner.listStopwords != nil:
+		project, err := tenant.GetProject(runner.listStopwords.GetProject())
+		if err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		sets, err := tenant.ListSearchStopwords(ctx, tx, project, runner.listStopwords.GetIndex())
+		if err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		resp := &api.ListStopwordsResponse{}
+		for _, set := range sets {
+			resp.StopwordSets = append(resp.StopwordSets, &api.StopwordSet{
+				Id:        set.Id,
+				Stopwords: set.Stopwords,
+			})
+		}
+
+		return Response{
+			Response: resp,
+		}, nil
+	case runner.upsertRule != nil:
+		project, err := tenant.GetProject(runner.upsertRule.GetProject())
+		if err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		index, err := tenant.GetSearchIndex(ctx, tx, project, runner.upsertRule.GetIndex())
+		if err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		rule := curationRuleFromAPI(runner.upsertRule.GetRule())
+		if err = validateCurationRule(index, &rule); err != nil {
+			return Response{}, err
+		}
+		if err = tenant.UpsertSearchCurationRule(ctx, tx, project, index.Name, rule); err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		return Response{
+			Status: database.UpdatedStatus,
+		}, nil
+	case runner.deleteRule != nil:
+		project, err := tenant.GetProject(runner.deleteRule.GetProject())
+		if err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		if err = tenant.DeleteSearchCurationRule(ctx, tx, project, runner.deleteRule.GetIndex(), runner.deleteRule.GetId()); err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		return Response{
+			Status: database.DeletedStatus,
+		}, nil
+	case runner.listRules != nil:
+		project, err := tenant.GetProject(runner.listRules.GetProject())
+		if err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		rules, err := tenant.ListSearchCurationRules(ctx, tx, project, runner.listRules.GetIndex())
+		if err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		resp := &api.ListCurationRulesResponse{}
+		for i := range rules {
+			resp.Rules = append(resp.Rules, curationRuleToAPI(&rules[i]))
+		}
+
+		return Response{
+			Response: resp,
+		}, nil
+	}
+
+	return Response{}, nil
+}
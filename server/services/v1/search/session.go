@@ -117,7 +117,8 @@ func (sessions *SessionManager) TxExecute(ctx context.Context, runner TxRunner,
 		return Response{}, createApiError(err)
 	}
 
-	if tx.Context().IsMetadataStateChanged() {
+	metadataChanged := tx.Context().IsMetadataStateChanged()
+	if metadataChanged {
 		if err = sessions.versionH.Increment(ctx, tx); ulog.E(err) {
 			_ = tx.Rollback(ctx)
 			return Response{}, err
@@ -127,5 +128,12 @@ func (sessions *SessionManager) TxExecute(ctx context.Context, runner TxRunner,
 	if err = tx.Commit(ctx); err != nil {
 		return Response{}, createApiError(err)
 	}
+
+	if metadataChanged {
+		// the committed metadata is reloaded so that the changes not applied to the cached state by the runner, like
+		// the index settings, are visible to the next requests.
+		_ = sessions.TrackVersion(ctx, tenant)
+	}
+
 	return resp, nil
 }
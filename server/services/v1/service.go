@@ -34,6 +34,11 @@ type Service interface {
 	RegisterGRPC(grpc *grpc.Server) error
 }
 
+// Stopper is implemented by the services running background work, the work is stopped on shutdown.
+type Stopper interface {
+	Stop()
+}
+
 func GetRegisteredServicesRealtime(kvStore kv.TxStore, searchStore search.Store, tenantMgr *metadata.TenantManager, txMgr *transaction.Manager) []Service {
 	var v1Services []Service
 	v1Services = append(v1Services, newRealtimeService(kvStore, searchStore, tenantMgr, txMgr))
@@ -60,6 +65,10 @@ func GetRegisteredServices(kvStore kv.TxStore, searchStore search.Store, tenantM
 
 	v1Services = append(v1Services, newObservabilityService(tenantMgr))
 	v1Services = append(v1Services, newCacheService(tenantMgr, txMgr))
+	if config.DefaultConfig.Cache.Backend == config.CacheBackendMemory {
+		// the in-memory cache can't be shared with a separate realtime server
+		v1Services = append(v1Services, newRealtimeService(kvStore, searchStore, tenantMgr, txMgr))
+	}
 	v1Services = append(v1Services, newSearchService(searchStore, tenantMgr, forSearchTxMgr))
 	v1Services = append(v1Services, newBillingService(bProvider, tenantMgr))
 
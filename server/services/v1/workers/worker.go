@@ -214,6 +214,8 @@ func (w *Worker) processItem(queueItem *metadata.QueueItem) error {
 		return w.testQueueTask(queueItem)
 	case metadata.BUILD_SEARCH_INDEX_TASK:
 		return w.buildSearchTask(queueItem)
+	case metadata.VERIFY_SEARCH_INDEX_TASK:
+		return w.verifySearchTask(queueItem)
 	}
 
 	return fmt.Errorf("unknown job type")
@@ -343,7 +345,7 @@ func (w *Worker) buildSearchTask(queueItem *metadata.QueueItem) error {
 		Project:    task.ProjName,
 	}
 
-	qr := database.NewQueryRunnerFactory(w.txMgr, nil, w.searchStore)
+	qr := database.NewQueryRunnerFactory(w.txMgr, nil, w.searchStore, nil)
 	searchIndexer := qr.GetSearchIndexRunner(req, &metrics.WriteQueryMetrics{}, nil)
 	searchIndexer.ProgressUpdate = func(ctx context.Context) error {
 		tx, err := w.txMgr.StartTx(ctx)
@@ -377,6 +379,94 @@ func (w *Worker) buildSearchTask(queueItem *metadata.QueueItem) error {
 	return tx.Commit(ctx)
 }
 
+func (w *Worker) verifySearchTask(queueItem *metadata.QueueItem) error {
+	var task metadata.SearchVerifyTask
+	if err := jsoniter.Unmarshal(queueItem.Data, &task); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	dbBranch := metadata.NewDatabaseNameWithBranch(task.ProjName, task.Branch)
+	tenant, err := w.tenantMgr.GetTenant(ctx, task.NamespaceId)
+	if err != nil {
+		return err
+	}
+
+	project, err := tenant.GetProject(task.ProjName)
+	if err != nil {
+		return err
+	}
+
+	db, err := project.GetDatabase(dbBranch)
+	if err != nil {
+		return err
+	}
+	coll := db.GetCollection(task.CollName)
+	if coll == nil {
+		return fmt.Errorf("could not find collection \"%s\"", task.CollName)
+	}
+
+	verifyStore := tenant.MetaStore.SearchVerify()
+	report := &metadata.SearchVerifyReport{
+		Id:        queueItem.Id,
+		Repair:    task.Repair,
+		CreatedAt: time.Now(),
+	}
+	if err = w.updateVerifyReport(ctx, &task, func(ctx context.Context, tx transaction.Tx) error {
+		// keep the creation time of the queued report
+		if queued, err := verifyStore.Get(ctx, tx, &task.IndexBuildTask); err == nil && queued.Id == report.Id {
+			report.CreatedAt = queued.CreatedAt
+		}
+
+		report.Status = metadata.VerifyStatusRunning
+		report.StartedAt = time.Now()
+		return verifyStore.Put(ctx, tx, &task.IndexBuildTask, report)
+	}); err != nil {
+		return err
+	}
+
+	verifier := database.NewSearchVerifier(w.txMgr, w.searchStore, coll, task.Repair)
+	verifier.ProgressUpdate = func(ctx context.Context) error {
+		return w.updateVerifyReport(ctx, &task, func(ctx context.Context, tx transaction.Tx) error {
+			if err := w.queue.RenewLease(ctx, tx, queueItem, LEASE_TIME); err != nil {
+				return err
+			}
+			return verifyStore.Put(ctx, tx, &task.IndexBuildTask, report)
+		})
+	}
+
+	report.Status = metadata.VerifyStatusCompleted
+	if err = verifier.Verify(ctx, report); err != nil {
+		// the failure is kept in the report instead of retrying the task
+		log.Err(err).Str("collection", task.CollName).Msg("failed to verify search index")
+		report.Status = metadata.VerifyStatusFailed
+		report.Error = err.Error()
+	}
+	report.CompletedAt = time.Now()
+
+	return w.updateVerifyReport(ctx, &task, func(ctx context.Context, tx transaction.Tx) error {
+		if err := verifyStore.Put(ctx, tx, &task.IndexBuildTask, report); err != nil {
+			return err
+		}
+		return w.queue.Complete(ctx, tx, queueItem)
+	})
+}
+
+func (w *Worker) updateVerifyReport(ctx context.Context, task *metadata.SearchVerifyTask, update func(context.Context, transaction.Tx) error) error {
+	tx, err := w.txMgr.StartTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = update(ctx, tx); ulog.E(err) {
+		_ = tx.Rollback(ctx)
+		log.Error().Str("collection", task.CollName).Msg("failed to update search verification report")
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
 type WorkerInfo struct {
 	worker       *Worker
 	lastHearbeat time.Time
@@ -0,0 +1,1021 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transaction
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/keys"
+	"github.com/tigrisdata/tigris/server/metrics"
+	"github.com/tigrisdata/tigris/store/kv"
+)
+
+// readTxMaxAge is how long a short-lived FDB transaction serves the reads of a long-running transaction before it is
+// replaced by a new one. It is kept below the five seconds limit so that reads rarely hit it.
+const readTxMaxAge = 3 * time.Second
+
+// LongTxSession is a transaction that is not bounded by the five seconds limit of FDB. The reads are served by
+// short-lived FDB transactions and merged with the writes of the transaction, which are buffered in the server until
+// commit. Every read that is not a snapshot read is remembered along with a hash of what it observed. At commit, a
+// single FDB transaction reads them again and fails the commit with a conflict if any of them changed, then applies
+// the buffered writes. This is optimistic concurrency control, the transaction is serializable but concurrent
+// writers are not blocked, instead the commit is rejected.
+//
+// The buffered writes are bounded by a size limit as they are written in a single FDB transaction. The reads to validate
+// are bounded the same way as they are all read again by that transaction, which has to finish within the five
+// seconds limit of FDB: a read past the limit fails with ContentTooLarge instead of the commit failing later. The
+// lifetime of the transaction is bounded by a timeout after which it is rolled back.
+type LongTxSession struct {
+	sync.Mutex
+
+	context *SessionCtx
+	kvStore kv.TxStore
+	state   sessionState
+	txCtx   *api.TransactionCtx
+	timeout time.Duration
+	maxSize int64
+	// maxReadSize bounds the size of the rows read by the transaction that are validated at commit.
+	maxReadSize int64
+	started     time.Time
+
+	readVersion int64
+	readTx      kv.Tx
+	readTxStart time.Time
+	// readTxs are all the short-lived transactions, the replaced ones may still be used by the open iterators, so they
+	// are only closed at the end of the transaction.
+	readTxs []kv.Tx
+
+	writes   []*longTxWrite
+	rows     map[string]*longTxRow
+	atomics  map[string]int64
+	size     int64
+	reads    []longTxRead
+	readSize int64
+
+	committedVersion int64
+}
+
+func newLongTxSession(kvStore kv.TxStore, timeout time.Duration, maxSize int64, maxReadSize int64) (*LongTxSession, error) {
+	if kvStore == nil {
+		return nil, errors.Internal("session needs non-nil kv object")
+	}
+
+	return &LongTxSession{
+		context:     &SessionCtx{},
+		kvStore:     kvStore,
+		state:       sessionCreated,
+		txCtx:       generateTransactionCtx(),
+		timeout:     timeout,
+		maxSize:     maxSize,
+		maxReadSize: maxReadSize,
+		rows:        make(map[string]*longTxRow),
+		atomics:     make(map[string]int64),
+	}, nil
+}
+
+func (s *LongTxSession) start(ctx context.Context) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.state != sessionCreated {
+		return errors.Internal("session state is misused")
+	}
+
+	tx, err := s.newReadTx()
+	if err != nil {
+		return err
+	}
+
+	if s.readVersion, err = tx.GetReadVersion(ctx); err != nil {
+		s.closeReadTxs(ctx)
+		return err
+	}
+
+	s.state = sessionActive
+	s.started = time.Now()
+	metrics.LongTxStarted()
+
+	return nil
+}
+
+func (s *LongTxSession) GetTxCtx() *api.TransactionCtx {
+	return s.txCtx
+}
+
+func (s *LongTxSession) Context() *SessionCtx {
+	return s.context
+}
+
+// Expired returns true once the transaction is past its timeout, the transaction can only be rolled back then.
+func (s *LongTxSession) Expired() bool {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.expired()
+}
+
+func (s *LongTxSession) expired() bool {
+	return s.state == sessionActive && s.timeout > 0 && time.Since(s.started) > s.timeout
+}
+
+func (s *LongTxSession) validateSession(ctx context.Context) error {
+	if s.state == sessionEnded {
+		return ErrSessionIsGone
+	}
+	if s.state == sessionCreated {
+		return ErrSessionIsNotStarted
+	}
+	if s.expired() {
+		s.end(ctx, metrics.LongTxExpired)
+		return errors.DeadlineExceeded("long-running transaction exceeded its timeout of %s", s.timeout)
+	}
+
+	return nil
+}
+
+// end releases the transaction and reports its outcome.
+func (s *LongTxSession) end(ctx context.Context, outcome string) {
+	if s.state == sessionActive {
+		metrics.LongTxFinished(outcome, time.Since(s.started), s.size, len(s.reads))
+	}
+
+	s.state = sessionEnded
+	s.closeReadTxs(ctx)
+	s.writes, s.rows, s.atomics, s.reads = nil, nil, nil, nil
+}
+
+func (s *LongTxSession) newReadTx() (kv.Tx, error) {
+	// the read transactions outlive the request that creates them, so they don't inherit its deadline
+	tx, err := s.kvStore.BeginTx(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	s.readTx = tx
+	s.readTxStart = time.Now()
+	s.readTxs = append(s.readTxs, tx)
+
+	return tx, nil
+}
+
+func (s *LongTxSession) getReadTx() (kv.Tx, error) {
+	if s.readTx != nil && time.Since(s.readTxStart) < readTxMaxAge {
+		return s.readTx, nil
+	}
+
+	return s.newReadTx()
+}
+
+func (s *LongTxSession) closeReadTxs(ctx context.Context) {
+	for _, tx := range s.readTxs {
+		_ = tx.Rollback(ctx)
+	}
+	s.readTx, s.readTxs = nil, nil
+}
+
+// withReadTx runs the read in a short-lived transaction, the read is retried once in a new transaction if the current
+// one got too old.
+func (s *LongTxSession) withReadTx(fn func(tx kv.Tx) error) error {
+	tx, err := s.getReadTx()
+	if err != nil {
+		return err
+	}
+
+	if err = fn(tx); !isReadTxTooOld(err) {
+		return err
+	}
+
+	if tx, err = s.newReadTx(); err != nil {
+		return err
+	}
+
+	return fn(tx)
+}
+
+// freshReadTx is used by the iterators to continue a read after their transaction got too old.
+func (s *LongTxSession) freshReadTx(ctx context.Context) (kv.Tx, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.validateSession(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.newReadTx()
+}
+
+func isReadTxTooOld(err error) bool {
+	return err == kv.ErrTransactionMaxDurationReached || err == kv.ErrTransactionTimedOut
+}
+
+func (s *LongTxSession) buffer(w *longTxWrite, size int64) error {
+	if s.maxSize > 0 && s.size+size > s.maxSize {
+		return errors.ContentTooLarge("long-running transaction exceeds the limit of %d bytes of writes", s.maxSize)
+	}
+
+	s.size += size
+	s.writes = append(s.writes, w)
+
+	return nil
+}
+
+// trackRead accounts the size of a read that is validated at commit.
+func (s *LongTxSession) trackRead(size int64) error {
+	if s.maxReadSize > 0 && s.readSize+size > s.maxReadSize {
+		return errors.ContentTooLarge("long-running transaction exceeds the limit of %d bytes of reads", s.maxReadSize)
+	}
+
+	s.readSize += size
+
+	return nil
+}
+
+// trackRangeRead is used by the iterators, which run outside the lock of the session, to account the rows they read.
+func (s *LongTxSession) trackRangeRead(row *kv.KeyValue) error {
+	s.Lock()
+	defer s.Unlock()
+
+	size := int64(len(row.FDBKey))
+	if row.Data != nil {
+		size += int64(len(row.Data.RawData))
+	}
+
+	return s.trackRead(size)
+}
+
+func (s *LongTxSession) bufferRow(ctx context.Context, op string, w *longTxWrite, key keys.Key) error {
+	fdbKey := key.SerializeToBytes()
+
+	size := int64(len(fdbKey))
+	if w.data != nil {
+		size += int64(len(w.data.RawData))
+	}
+	if err := s.buffer(w, size); err != nil {
+		return err
+	}
+
+	s.rows[string(fdbKey)] = &longTxRow{
+		key:     w.key,
+		fdbKey:  fdbKey,
+		data:    w.data,
+		deleted: w.op == longTxDelete,
+	}
+
+	// the writes are applied at commit, but the listeners consume the events before that, so the events are sent
+	// now the same way the listener kv layer does it.
+	listener := kv.GetEventListener(ctx)
+	if w.op == longTxDelete {
+		listener.OnClear(op, w.table, w.key)
+	} else {
+		listener.OnSet(op, w.table, w.key, w.data)
+	}
+
+	return nil
+}
+
+func (s *LongTxSession) Insert(ctx context.Context, key keys.Key, data *internal.TableData) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.validateSession(ctx); err != nil {
+		return err
+	}
+
+	fdbKey := key.SerializeToBytes()
+	if row, ok := s.rows[string(fdbKey)]; ok {
+		if !row.deleted {
+			return kv.ErrDuplicateKey
+		}
+	} else {
+		// reject early the keys that already exist, the insert is checked again when it is applied at commit
+		var existing []byte
+		if err := s.withReadTx(func(tx kv.Tx) (err error) {
+			existing, err = tx.Get(ctx, fdbKey, true).Get()
+			return
+		}); err != nil {
+			return err
+		}
+		if existing != nil {
+			return kv.ErrDuplicateKey
+		}
+	}
+
+	return s.bufferRow(ctx, kv.InsertEvent, &longTxWrite{
+		op:    longTxInsert,
+		table: key.Table(),
+		key:   kv.BuildKey(key.IndexParts()...),
+		data:  data,
+	}, key)
+}
+
+func (s *LongTxSession) Replace(ctx context.Context, key keys.Key, data *internal.TableData, isUpdate bool) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.validateSession(ctx); err != nil {
+		return err
+	}
+
+	op := kv.ReplaceEvent
+	if isUpdate {
+		op = kv.UpdateEvent
+	}
+
+	return s.bufferRow(ctx, op, &longTxWrite{
+		op:       longTxReplace,
+		table:    key.Table(),
+		key:      kv.BuildKey(key.IndexParts()...),
+		data:     data,
+		isUpdate: isUpdate,
+	}, key)
+}
+
+func (s *LongTxSession) Delete(ctx context.Context, key keys.Key) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.validateSession(ctx); err != nil {
+		return err
+	}
+
+	return s.bufferRow(ctx, kv.DeleteEvent, &longTxWrite{
+		op:    longTxDelete,
+		table: key.Table(),
+		key:   kv.BuildKey(key.IndexParts()...),
+	}, key)
+}
+
+func (s *LongTxSession) SetVersionstampedValue(ctx context.Context, key []byte, value []byte) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.validateSession(ctx); err != nil {
+		return err
+	}
+
+	return s.buffer(&longTxWrite{op: longTxVersionstampedValue, rawKey: key, rawValue: value}, int64(len(key)+len(value)))
+}
+
+func (s *LongTxSession) SetVersionstampedKey(ctx context.Context, key []byte, value []byte) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.validateSession(ctx); err != nil {
+		return err
+	}
+
+	return s.buffer(&longTxWrite{op: longTxVersionstampedKey, rawKey: key, rawValue: value}, int64(len(key)+len(value)))
+}
+
+func (s *LongTxSession) AtomicAdd(ctx context.Context, key keys.Key, value int64) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.validateSession(ctx); err != nil {
+		return err
+	}
+
+	fdbKey := key.SerializeToBytes()
+	if err := s.buffer(&longTxWrite{
+		op:    longTxAtomicAdd,
+		table: key.Table(),
+		key:   kv.BuildKey(key.IndexParts()...),
+		delta: value,
+	}, int64(len(fdbKey)+8)); err != nil {
+		return err
+	}
+	s.atomics[string(fdbKey)] += value
+
+	return nil
+}
+
+func (s *LongTxSession) AtomicRead(ctx context.Context, key keys.Key) (int64, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.validateSession(ctx); err != nil {
+		return 0, err
+	}
+
+	read := &longTxAtomicRead{table: key.Table(), key: kv.BuildKey(key.IndexParts()...)}
+	if err := s.withReadTx(func(tx kv.Tx) (err error) {
+		read.value, err = tx.AtomicRead(ctx, read.table, read.key)
+		return
+	}); err != nil {
+		return 0, err
+	}
+	if err := s.trackRead(int64(len(key.SerializeToBytes()) + 8)); err != nil {
+		return 0, err
+	}
+	s.reads = append(s.reads, read)
+
+	return read.value + s.atomics[string(key.SerializeToBytes())], nil
+}
+
+// AtomicReadRange is not supported, the counters read by the range can't be validated at commit.
+func (*LongTxSession) AtomicReadRange(context.Context, keys.Key, keys.Key, bool) (kv.AtomicIterator, error) {
+	return nil, errors.InvalidArgument("atomic range reads are not supported in a long-running transaction")
+}
+
+func (s *LongTxSession) Get(ctx context.Context, key []byte, isSnapshot bool) (kv.Future, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.validateSession(ctx); err != nil {
+		return nil, err
+	}
+
+	// the rows written by the transaction are only applied at commit, they are read from the buffer
+	if row, ok := s.rows[string(key)]; ok {
+		if row.deleted {
+			return &readyFuture{}, nil
+		}
+
+		value, err := internal.Encode(row.data)
+		if err != nil {
+			return nil, err
+		}
+		return &readyFuture{value: value}, nil
+	}
+
+	read := &longTxGet{key: key}
+	if err := s.withReadTx(func(tx kv.Tx) (err error) {
+		read.value, err = tx.Get(ctx, key, true).Get()
+		return
+	}); err != nil {
+		return nil, err
+	}
+	if !isSnapshot {
+		if err := s.trackRead(int64(len(key) + len(read.value))); err != nil {
+			return nil, err
+		}
+		s.reads = append(s.reads, read)
+	}
+
+	return &readyFuture{value: read.value}, nil
+}
+
+func (s *LongTxSession) Read(ctx context.Context, key keys.Key, reverse bool) (kv.Iterator, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.validateSession(ctx); err != nil {
+		return nil, err
+	}
+
+	prefix := key.SerializeToBytes()
+	read := &longTxRangeRead{
+		table:    key.Table(),
+		prefix:   kv.BuildKey(key.IndexParts()...),
+		isPrefix: true,
+		begin:    prefix,
+		reverse:  reverse,
+		hash:     sha256.New(),
+	}
+
+	return s.newIterator(ctx, read, true)
+}
+
+func (s *LongTxSession) ReadRange(ctx context.Context, lKey keys.Key, rKey keys.Key, isSnapshot bool, reverse bool) (kv.Iterator, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.validateSession(ctx); err != nil {
+		return nil, err
+	}
+
+	read := &longTxRangeRead{
+		reverse: reverse,
+		hash:    sha256.New(),
+	}
+	if lKey != nil {
+		read.table = lKey.Table()
+		read.lKey = kv.BuildKey(lKey.IndexParts()...)
+		read.begin = lKey.SerializeToBytes()
+	}
+	if rKey != nil {
+		read.table = rKey.Table()
+		read.rKey = kv.BuildKey(rKey.IndexParts()...)
+		read.end = rKey.SerializeToBytes()
+	} else {
+		// same as the table boundary used by the kv layer
+		read.end = append(append([]byte{}, read.table...), 0xFF)
+	}
+	if read.begin == nil {
+		read.begin = read.table
+	}
+
+	return s.newIterator(ctx, read, !isSnapshot)
+}
+
+func (s *LongTxSession) newIterator(ctx context.Context, read *longTxRangeRead, track bool) (kv.Iterator, error) {
+	var it kv.Iterator
+	if err := s.withReadTx(func(tx kv.Tx) (err error) {
+		it, err = read.open(ctx, tx)
+		return
+	}); err != nil {
+		return nil, err
+	}
+
+	// the own writes are merged with the rows of the storage, the rows written after the iterator is created are not
+	// visible to it.
+	var rows []*longTxRow
+	for _, row := range s.rows {
+		if read.contains(row.fdbKey) {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return read.before(rows[i].fdbKey, rows[j].fdbKey)
+	})
+
+	if track {
+		s.reads = append(s.reads, read)
+	}
+
+	return &longTxIterator{
+		ctx:     ctx,
+		session: s,
+		read:    read,
+		track:   track,
+		it:      it,
+		rows:    rows,
+	}, nil
+}
+
+func (s *LongTxSession) RangeSize(ctx context.Context, _ []byte, lKey keys.Key, rKey keys.Key) (size int64, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if err = s.validateSession(ctx); err != nil {
+		return 0, err
+	}
+
+	err = s.withReadTx(func(tx kv.Tx) (err error) {
+		if rKey != nil && lKey != nil {
+			size, err = tx.RangeSize(ctx, lKey.Table(), kv.BuildKey(lKey.IndexParts()...), kv.BuildKey(rKey.IndexParts()...))
+		} else if lKey != nil {
+			size, err = tx.RangeSize(ctx, lKey.Table(), kv.BuildKey(lKey.IndexParts()...), nil)
+		} else {
+			size, err = tx.RangeSize(ctx, rKey.Table(), nil, kv.BuildKey(rKey.IndexParts()...))
+		}
+		return
+	})
+
+	return
+}
+
+// GetReadVersion returns the version at which the transaction started. Only the first reads are performed at this
+// version, the later ones are performed at newer versions and validated at commit.
+func (s *LongTxSession) GetReadVersion(ctx context.Context) (int64, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.validateSession(ctx); err != nil {
+		return 0, err
+	}
+
+	return s.readVersion, nil
+}
+
+func (*LongTxSession) SetReadVersion(context.Context, int64) error {
+	return errors.InvalidArgument("read version can't be set on a long-running transaction")
+}
+
+// Commit validates the reads and applies the buffered writes in a single FDB transaction. The commit fails with
+// kv.ErrConflictingTransaction if any of the reads changed since it was performed.
+func (s *LongTxSession) Commit(ctx context.Context) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.validateSession(ctx); err != nil {
+		return err
+	}
+
+	outcome := metrics.LongTxFailed
+	defer func() {
+		s.end(ctx, outcome)
+	}()
+
+	tx, err := s.kvStore.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = s.validateReads(ctx, tx); err != nil {
+		_ = tx.Rollback(ctx)
+		if err == kv.ErrConflictingTransaction {
+			outcome = metrics.LongTxConflict
+		}
+		return err
+	}
+
+	// the events are already sent to the listener when the writes were buffered
+	applyCtx := context.WithValue(ctx, kv.EventListenerCtxKey{}, &kv.NoopEventListener{})
+	for _, w := range s.writes {
+		if err = w.apply(applyCtx, tx); err != nil {
+			_ = tx.Rollback(ctx)
+			return err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		if err == kv.ErrConflictingTransaction {
+			outcome = metrics.LongTxConflict
+		}
+		return err
+	}
+
+	outcome = metrics.LongTxCommitted
+	s.committedVersion = committedVersion(ctx, tx)
+	log.Debug().Str("id", s.txCtx.GetId()).Int("writes", len(s.writes)).Int("reads", len(s.reads)).
+		Dur("duration", time.Since(s.started)).Msg("long-running transaction committed")
+
+	return nil
+}
+
+func (s *LongTxSession) CommittedVersion() int64 {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.committedVersion
+}
+
+func (s *LongTxSession) validateReads(ctx context.Context, tx kv.Tx) error {
+	for _, read := range s.reads {
+		valid, err := read.validate(ctx, tx)
+		if err != nil {
+			return err
+		}
+		if !valid {
+			return kv.ErrConflictingTransaction
+		}
+	}
+
+	return nil
+}
+
+func (s *LongTxSession) Rollback(ctx context.Context) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.state == sessionEnded {
+		// already committed, no-op
+		return nil
+	}
+
+	s.end(ctx, metrics.LongTxRolledBack)
+
+	return nil
+}
+
+type longTxOp uint8
+
+const (
+	longTxInsert longTxOp = iota + 1
+	longTxReplace
+	longTxDelete
+	longTxAtomicAdd
+	longTxVersionstampedValue
+	longTxVersionstampedKey
+)
+
+// longTxWrite is a buffered write, the writes are applied in order at commit.
+type longTxWrite struct {
+	op       longTxOp
+	table    []byte
+	key      kv.Key
+	data     *internal.TableData
+	isUpdate bool
+	delta    int64
+	rawKey   []byte
+	rawValue []byte
+}
+
+func (w *longTxWrite) apply(ctx context.Context, tx kv.Tx) error {
+	switch w.op {
+	case longTxInsert:
+		return tx.Insert(ctx, w.table, w.key, w.data)
+	case longTxReplace:
+		return tx.Replace(ctx, w.table, w.key, w.data, w.isUpdate)
+	case longTxDelete:
+		return tx.Delete(ctx, w.table, w.key)
+	case longTxAtomicAdd:
+		return tx.AtomicAdd(ctx, w.table, w.key, w.delta)
+	case longTxVersionstampedValue:
+		return tx.SetVersionstampedValue(ctx, w.rawKey, w.rawValue)
+	case longTxVersionstampedKey:
+		return tx.SetVersionstampedKey(ctx, w.rawKey, w.rawValue)
+	default:
+		return errors.Internal("unknown buffered write %d", w.op)
+	}
+}
+
+// longTxRow is the latest value written by the transaction for a key, used to serve the reads of the transaction.
+type longTxRow struct {
+	key     kv.Key
+	fdbKey  []byte
+	data    *internal.TableData
+	deleted bool
+}
+
+// longTxRead is a read that is validated at commit.
+type longTxRead interface {
+	validate(ctx context.Context, tx kv.Tx) (bool, error)
+}
+
+type longTxGet struct {
+	key   []byte
+	value []byte
+}
+
+func (r *longTxGet) validate(ctx context.Context, tx kv.Tx) (bool, error) {
+	value, err := tx.Get(ctx, r.key, false).Get()
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(value, r.value), nil
+}
+
+type longTxAtomicRead struct {
+	table []byte
+	key   kv.Key
+	value int64
+}
+
+func (r *longTxAtomicRead) validate(ctx context.Context, tx kv.Tx) (bool, error) {
+	value, err := tx.AtomicRead(ctx, r.table, r.key)
+	if err != nil {
+		return false, err
+	}
+
+	return value == r.value, nil
+}
+
+// longTxRangeRead is a prefix or range read. Only the part of the range consumed by the caller is validated, the
+// hash covers the rows of the storage consumed so far and last is the key of the last of them.
+type longTxRangeRead struct {
+	table    []byte
+	prefix   kv.Key
+	isPrefix bool
+	lKey     kv.Key
+	rKey     kv.Key
+	reverse  bool
+
+	// begin and end are the bounds of the range in the storage, end is only set for a range read.
+	begin []byte
+	end   []byte
+
+	hash hash.Hash
+	last []byte
+	rows int
+	done bool
+}
+
+func (r *longTxRangeRead) open(ctx context.Context, tx kv.Tx) (kv.Iterator, error) {
+	if r.isPrefix {
+		return tx.Read(ctx, r.table, r.prefix, r.reverse)
+	}
+
+	return tx.ReadRange(ctx, r.table, r.lKey, r.rKey, false, r.reverse)
+}
+
+// resume opens the range again from the last row returned, the row itself is returned again by the iterator.
+func (r *longTxRangeRead) resume(ctx context.Context, tx kv.Tx, last kv.Key) (kv.Iterator, error) {
+	begin, end := r.lKey, r.rKey
+	if r.isPrefix {
+		// the end of the prefix can't be expressed as a key, contains stops the iteration once past it
+		begin, end = r.prefix, nil
+	}
+	if r.reverse {
+		end = last
+	} else {
+		begin = last
+	}
+
+	return tx.ReadRange(ctx, r.table, begin, end, false, r.reverse)
+}
+
+func (r *longTxRangeRead) contains(fdbKey []byte) bool {
+	if r.isPrefix {
+		return bytes.HasPrefix(fdbKey, r.begin)
+	}
+
+	return bytes.Compare(fdbKey, r.begin) >= 0 && bytes.Compare(fdbKey, r.end) < 0
+}
+
+// before returns true if a comes before b in the order of iteration.
+func (r *longTxRangeRead) before(a []byte, b []byte) bool {
+	if r.reverse {
+		return bytes.Compare(a, b) > 0
+	}
+
+	return bytes.Compare(a, b) < 0
+}
+
+func (r *longTxRangeRead) observe(row *kv.KeyValue) {
+	hashRow(r.hash, row)
+	r.last = row.FDBKey
+	r.rows++
+}
+
+func (r *longTxRangeRead) validate(ctx context.Context, tx kv.Tx) (bool, error) {
+	if r.rows == 0 && !r.done {
+		// nothing is consumed from this range
+		return true, nil
+	}
+
+	it, err := r.open(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+
+	h := sha256.New()
+	var row kv.KeyValue
+	for it.Next(&row) {
+		if !r.done && r.before(r.last, row.FDBKey) {
+			break
+		}
+		hashRow(h, &row)
+	}
+	if err = it.Err(); err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(h.Sum(nil), r.hash.Sum(nil)), nil
+}
+
+func hashRow(h hash.Hash, row *kv.KeyValue) {
+	var size [8]byte
+
+	binary.BigEndian.PutUint64(size[:], uint64(len(row.FDBKey)))
+	_, _ = h.Write(size[:])
+	_, _ = h.Write(row.FDBKey)
+
+	if row.Data != nil {
+		binary.BigEndian.PutUint64(size[:], uint64(len(row.Data.RawData)))
+		_, _ = h.Write(size[:])
+		_, _ = h.Write(row.Data.RawData)
+	}
+}
+
+// longTxIterator merges the rows of the storage with the rows written by the transaction. The read of the storage
+// continues in a new short-lived transaction when the current one gets too old.
+type longTxIterator struct {
+	ctx     context.Context
+	session *LongTxSession
+	read    *longTxRangeRead
+	track   bool
+
+	it         kv.Iterator
+	lastKey    kv.Key
+	lastFDBKey []byte
+	skip       []byte
+	retried    bool
+	next       *kv.KeyValue
+	done       bool
+
+	rows []*longTxRow
+	pos  int
+	err  error
+}
+
+// fill reads the next row of the storage, unless it is already read or the storage has no more rows.
+func (it *longTxIterator) fill() {
+	for !it.done && it.next == nil {
+		var row kv.KeyValue
+		if it.it.Next(&row) {
+			if it.skip != nil && bytes.Equal(row.FDBKey, it.skip) {
+				it.skip = nil
+				continue
+			}
+			it.skip = nil
+
+			if !it.read.contains(row.FDBKey) {
+				it.done = true
+				return
+			}
+
+			if it.track {
+				if err := it.session.trackRangeRead(&row); err != nil {
+					it.err = err
+					return
+				}
+				it.read.observe(&row)
+			}
+			it.lastKey, it.lastFDBKey, it.retried, it.next = row.Key, row.FDBKey, false, &row
+			return
+		}
+
+		err := it.it.Err()
+		if err == nil {
+			it.done = true
+			if it.track {
+				it.read.done = true
+			}
+			return
+		}
+
+		if !isReadTxTooOld(err) || it.retried {
+			it.err = err
+			return
+		}
+
+		tx, err := it.session.freshReadTx(it.ctx)
+		if err != nil {
+			it.err = err
+			return
+		}
+
+		it.retried = true
+		if it.lastKey == nil {
+			it.it, err = it.read.open(it.ctx, tx)
+		} else {
+			it.it, err = it.read.resume(it.ctx, tx, it.lastKey)
+			it.skip = it.lastFDBKey
+		}
+		if err != nil {
+			it.err = err
+			return
+		}
+	}
+}
+
+func (it *longTxIterator) Next(value *kv.KeyValue) bool {
+	for it.err == nil {
+		it.fill()
+		if it.err != nil {
+			return false
+		}
+
+		var row *longTxRow
+		if it.pos < len(it.rows) {
+			row = it.rows[it.pos]
+		}
+		if it.next == nil && row == nil {
+			return false
+		}
+
+		if row == nil || (it.next != nil && it.read.before(it.next.FDBKey, row.fdbKey)) {
+			*value = *it.next
+			it.next = nil
+			return true
+		}
+
+		if it.next != nil && bytes.Equal(it.next.FDBKey, row.fdbKey) {
+			// the row is overwritten by the transaction
+			it.next = nil
+		}
+		it.pos++
+
+		if row.deleted {
+			continue
+		}
+
+		value.Key = row.key
+		value.FDBKey = row.fdbKey
+		value.Data = row.data
+		return true
+	}
+
+	return false
+}
+
+func (it *longTxIterator) Err() error {
+	return it.err
+}
+
+// readyFuture is the result of a read that is already performed.
+type readyFuture struct {
+	value []byte
+}
+
+func (f *readyFuture) Get() ([]byte, error) { return f.value, nil }
+func (f *readyFuture) MustGet() []byte      { return f.value }
+func (*readyFuture) BlockUntilReady()       {}
+func (*readyFuture) IsReady() bool          { return true }
+func (*readyFuture) Cancel()                {}
@@ -0,0 +1,467 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transaction
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/keys"
+	"github.com/tigrisdata/tigris/store/kv"
+)
+
+var longTxTable = []byte("long_tx_test")
+
+// memStore is an in-memory kv.TxStore, the writes of a transaction are applied on commit and the reads see the
+// committed state.
+type memStore struct {
+	sync.Mutex
+
+	rows    map[string]*memRow
+	atomics map[string]int64
+	// tooOldAfter fails the iterators of the next transaction after returning this number of rows.
+	tooOldAfter int
+}
+
+type memRow struct {
+	key  kv.Key
+	data *internal.TableData
+}
+
+func newMemStore() *memStore {
+	return &memStore{rows: make(map[string]*memRow), atomics: make(map[string]int64)}
+}
+
+func memFDBKey(table []byte, key kv.Key) []byte {
+	parts := make([]any, 0, len(key))
+	for _, p := range key {
+		parts = append(parts, p)
+	}
+
+	return keys.NewKey(table, parts...).SerializeToBytes()
+}
+
+func (m *memStore) BeginTx(context.Context) (kv.Tx, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	tx := &memTx{store: m, written: make(map[string]bool), tooOldAfter: m.tooOldAfter}
+	m.tooOldAfter = 0
+
+	return tx, nil
+}
+
+func (*memStore) CreateTable(context.Context, []byte) error { return nil }
+func (*memStore) DropTable(context.Context, []byte) error   { return nil }
+func (*memStore) GetInternalDatabase() (any, error)         { return nil, nil }
+func (*memStore) GetTableStats(context.Context, []byte) (*kv.TableStats, error) {
+	return &kv.TableStats{}, nil
+}
+
+func (m *memStore) put(key kv.Key, value string) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.rows[string(memFDBKey(longTxTable, key))] = &memRow{key: key, data: internal.NewTableData([]byte(value))}
+}
+
+func (m *memStore) values() []string {
+	m.Lock()
+	defer m.Unlock()
+
+	var fdbKeys []string
+	for k := range m.rows {
+		fdbKeys = append(fdbKeys, k)
+	}
+	sort.Strings(fdbKeys)
+
+	values := make([]string, 0, len(fdbKeys))
+	for _, k := range fdbKeys {
+		values = append(values, string(m.rows[k].data.RawData))
+	}
+
+	return values
+}
+
+type memTx struct {
+	store  *memStore
+	writes []func()
+	// written tracks the keys written by the transaction, false if deleted, for the existence check of insert
+	written     map[string]bool
+	tooOldAfter int
+}
+
+func (tx *memTx) Insert(ctx context.Context, table []byte, key kv.Key, data *internal.TableData) error {
+	fdbKey := string(memFDBKey(table, key))
+
+	tx.store.Lock()
+	_, exists := tx.store.rows[fdbKey]
+	tx.store.Unlock()
+	if written, ok := tx.written[fdbKey]; ok {
+		exists = written
+	}
+	if exists {
+		return kv.ErrDuplicateKey
+	}
+
+	return tx.Replace(ctx, table, key, data, false)
+}
+
+func (tx *memTx) Replace(_ context.Context, table []byte, key kv.Key, data *internal.TableData, _ bool) error {
+	tx.written[string(memFDBKey(table, key))] = true
+	tx.writes = append(tx.writes, func() {
+		tx.store.rows[string(memFDBKey(table, key))] = &memRow{key: key, data: data}
+	})
+	return nil
+}
+
+func (tx *memTx) Delete(_ context.Context, table []byte, key kv.Key) error {
+	tx.written[string(memFDBKey(table, key))] = false
+	tx.writes = append(tx.writes, func() {
+		prefix := memFDBKey(table, key)
+		for k := range tx.store.rows {
+			if bytes.HasPrefix([]byte(k), prefix) {
+				delete(tx.store.rows, k)
+			}
+		}
+	})
+	return nil
+}
+
+func (tx *memTx) scan(begin []byte, end []byte, prefix []byte, reverse bool) kv.Iterator {
+	tx.store.Lock()
+	defer tx.store.Unlock()
+
+	var rows []*kv.KeyValue
+	for k, row := range tx.store.rows {
+		fdbKey := []byte(k)
+		if prefix != nil && !bytes.HasPrefix(fdbKey, prefix) {
+			continue
+		}
+		if prefix == nil && (bytes.Compare(fdbKey, begin) < 0 || bytes.Compare(fdbKey, end) >= 0) {
+			continue
+		}
+		rows = append(rows, &kv.KeyValue{Key: row.key, FDBKey: fdbKey, Data: row.data})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		c := bytes.Compare(rows[i].FDBKey, rows[j].FDBKey)
+		if reverse {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	return &memIterator{rows: rows, tooOldAfter: tx.tooOldAfter}
+}
+
+func (tx *memTx) Read(_ context.Context, table []byte, key kv.Key, reverse bool) (kv.Iterator, error) {
+	return tx.scan(nil, nil, memFDBKey(table, key), reverse), nil
+}
+
+func (tx *memTx) ReadRange(_ context.Context, table []byte, lKey kv.Key, rKey kv.Key, _ bool, reverse bool) (kv.Iterator, error) {
+	end := append(append([]byte{}, table...), 0xFF)
+	if rKey != nil {
+		end = memFDBKey(table, rKey)
+	}
+
+	return tx.scan(memFDBKey(table, lKey), end, nil, reverse), nil
+}
+
+func (*memTx) GetMetadata(context.Context, []byte, kv.Key) (*internal.TableData, error) {
+	return nil, kv.ErrNotFound
+}
+
+func (*memTx) SetVersionstampedKey(context.Context, []byte, []byte) error   { return nil }
+func (*memTx) SetVersionstampedValue(context.Context, []byte, []byte) error { return nil }
+
+func (tx *memTx) Get(_ context.Context, key []byte, _ bool) kv.Future {
+	tx.store.Lock()
+	defer tx.store.Unlock()
+
+	if row, ok := tx.store.rows[string(key)]; ok {
+		return &readyFuture{value: row.data.RawData}
+	}
+
+	return &readyFuture{}
+}
+
+func (tx *memTx) AtomicAdd(_ context.Context, table []byte, key kv.Key, value int64) error {
+	tx.writes = append(tx.writes, func() {
+		tx.store.atomics[string(memFDBKey(table, key))] += value
+	})
+	return nil
+}
+
+func (tx *memTx) AtomicRead(_ context.Context, table []byte, key kv.Key) (int64, error) {
+	tx.store.Lock()
+	defer tx.store.Unlock()
+
+	return tx.store.atomics[string(memFDBKey(table, key))], nil
+}
+
+func (*memTx) AtomicReadRange(context.Context, []byte, kv.Key, kv.Key, bool) (kv.AtomicIterator, error) {
+	return nil, nil
+}
+
+func (*memTx) AtomicReadPrefix(context.Context, []byte, kv.Key, bool) (kv.AtomicIterator, error) {
+	return nil, nil
+}
+
+func (tx *memTx) Commit(context.Context) error {
+	tx.store.Lock()
+	defer tx.store.Unlock()
+
+	for _, w := range tx.writes {
+		w()
+	}
+	return nil
+}
+
+func (*memTx) Rollback(context.Context) error                                   { return nil }
+func (*memTx) IsRetriable() bool                                                { return false }
+func (*memTx) RangeSize(context.Context, []byte, kv.Key, kv.Key) (int64, error) { return 0, nil }
+func (*memTx) GetReadVersion(context.Context) (int64, error)                    { return 1, nil }
+func (*memTx) SetReadVersion(context.Context, int64) error                      { return nil }
+func (*memTx) GetCommittedVersion(context.Context) (int64, error)               { return 2, nil }
+
+type memIterator struct {
+	rows        []*kv.KeyValue
+	pos         int
+	tooOldAfter int
+	err         error
+}
+
+func (it *memIterator) Next(value *kv.KeyValue) bool {
+	if it.tooOldAfter > 0 && it.pos == it.tooOldAfter {
+		it.err = kv.ErrTransactionMaxDurationReached
+		return false
+	}
+	if it.pos == len(it.rows) {
+		return false
+	}
+
+	*value = *it.rows[it.pos]
+	it.pos++
+	return true
+}
+
+func (it *memIterator) Err() error { return it.err }
+
+func startLongTx(t *testing.T, store *memStore, timeout time.Duration, maxSize int64) *LongTxSession {
+	s, err := newLongTxSession(store, timeout, maxSize, 0)
+	require.NoError(t, err)
+	require.NoError(t, s.start(context.Background()))
+	return s
+}
+
+func readAll(t *testing.T, it kv.Iterator, limit int) []string {
+	var values []string
+	var row kv.KeyValue
+	for (limit == 0 || len(values) < limit) && it.Next(&row) {
+		values = append(values, string(row.Data.RawData))
+	}
+	require.NoError(t, it.Err())
+	return values
+}
+
+func longTxKey(id string) keys.Key {
+	return keys.NewKey(longTxTable, id)
+}
+
+func TestLongTxSession(t *testing.T) {
+	ctx := context.Background()
+	data := func(v string) *internal.TableData { return internal.NewTableData([]byte(v)) }
+
+	t.Run("read_own_writes", func(t *testing.T) {
+		store := newMemStore()
+		store.put(kv.BuildKey("a"), "a")
+		store.put(kv.BuildKey("b"), "b")
+		store.put(kv.BuildKey("c"), "c")
+
+		s := startLongTx(t, store, time.Minute, 0)
+		require.NoError(t, s.Replace(ctx, longTxKey("b"), data("b1"), false))
+		require.NoError(t, s.Delete(ctx, longTxKey("c")))
+		require.NoError(t, s.Insert(ctx, longTxKey("d"), data("d")))
+
+		it, err := s.Read(ctx, keys.NewKey(longTxTable), false)
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b1", "d"}, readAll(t, it, 0))
+
+		it, err = s.ReadRange(ctx, keys.NewKey(longTxTable), nil, false, true)
+		require.NoError(t, err)
+		require.Equal(t, []string{"d", "b1", "a"}, readAll(t, it, 0))
+
+		f, err := s.Get(ctx, longTxKey("b").SerializeToBytes(), false)
+		require.NoError(t, err)
+		decoded, err := internal.Decode(f.MustGet())
+		require.NoError(t, err)
+		require.Equal(t, "b1", string(decoded.RawData))
+
+		f, err = s.Get(ctx, longTxKey("c").SerializeToBytes(), false)
+		require.NoError(t, err)
+		require.Nil(t, f.MustGet())
+
+		// nothing is written before commit
+		require.Equal(t, []string{"a", "b", "c"}, store.values())
+		require.NoError(t, s.Commit(ctx))
+		require.Equal(t, []string{"a", "b1", "d"}, store.values())
+
+		require.Equal(t, ErrSessionIsGone, s.Insert(ctx, longTxKey("e"), data("e")))
+	})
+
+	t.Run("insert", func(t *testing.T) {
+		store := newMemStore()
+		store.put(kv.BuildKey("a"), "a")
+
+		s := startLongTx(t, store, time.Minute, 0)
+		require.Equal(t, kv.ErrDuplicateKey, s.Insert(ctx, longTxKey("a"), data("a1")))
+		require.NoError(t, s.Insert(ctx, longTxKey("b"), data("b")))
+		require.Equal(t, kv.ErrDuplicateKey, s.Insert(ctx, longTxKey("b"), data("b1")))
+
+		require.NoError(t, s.Delete(ctx, longTxKey("a")))
+		require.NoError(t, s.Insert(ctx, longTxKey("a"), data("a2")))
+		require.NoError(t, s.Commit(ctx))
+		require.Equal(t, []string{"a2", "b"}, store.values())
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		store := newMemStore()
+		store.put(kv.BuildKey("a"), "a")
+		store.put(kv.BuildKey("b"), "b")
+
+		s := startLongTx(t, store, time.Minute, 0)
+		it, err := s.Read(ctx, longTxKey("a"), false)
+		require.NoError(t, err)
+		require.Equal(t, []string{"a"}, readAll(t, it, 0))
+		require.NoError(t, s.Replace(ctx, longTxKey("x"), data("x"), false))
+
+		store.put(kv.BuildKey("a"), "a1")
+
+		require.Equal(t, kv.ErrConflictingTransaction, s.Commit(ctx))
+		require.Equal(t, []string{"a1", "b"}, store.values())
+	})
+
+	t.Run("no_conflict_outside_reads", func(t *testing.T) {
+		store := newMemStore()
+		store.put(kv.BuildKey("a"), "a")
+		store.put(kv.BuildKey("b"), "b")
+		store.put(kv.BuildKey("c"), "c")
+
+		s := startLongTx(t, store, time.Minute, 0)
+
+		// only the consumed part of the range is validated
+		it, err := s.Read(ctx, keys.NewKey(longTxTable), false)
+		require.NoError(t, err)
+		require.Equal(t, []string{"a"}, readAll(t, it, 1))
+
+		// snapshot reads are not validated
+		it, err = s.ReadRange(ctx, longTxKey("b"), nil, true, false)
+		require.NoError(t, err)
+		require.Equal(t, []string{"b", "c"}, readAll(t, it, 0))
+
+		store.put(kv.BuildKey("c"), "c1")
+		store.put(kv.BuildKey("b"), "b1")
+
+		require.NoError(t, s.Replace(ctx, longTxKey("d"), data("d"), false))
+		require.NoError(t, s.Commit(ctx))
+		require.Equal(t, []string{"a", "b1", "c1", "d"}, store.values())
+	})
+
+	t.Run("resume_read", func(t *testing.T) {
+		store := newMemStore()
+		for _, id := range []string{"a", "b", "c", "d", "e"} {
+			store.put(kv.BuildKey(id), id)
+		}
+
+		s := startLongTx(t, store, time.Minute, 0)
+		require.NoError(t, s.Delete(ctx, longTxKey("d")))
+
+		s.Lock()
+		s.readTx = nil
+		s.Unlock()
+		store.tooOldAfter = 2
+
+		it, err := s.Read(ctx, keys.NewKey(longTxTable), false)
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b", "c", "e"}, readAll(t, it, 0))
+		require.NoError(t, s.Commit(ctx))
+	})
+
+	t.Run("atomic", func(t *testing.T) {
+		store := newMemStore()
+
+		s := startLongTx(t, store, time.Minute, 0)
+		require.NoError(t, s.AtomicAdd(ctx, longTxKey("counter"), 5))
+		require.NoError(t, s.AtomicAdd(ctx, longTxKey("counter"), 2))
+		v, err := s.AtomicRead(ctx, longTxKey("counter"))
+		require.NoError(t, err)
+		require.Equal(t, int64(7), v)
+		require.NoError(t, s.Commit(ctx))
+
+		require.Equal(t, int64(7), store.atomics[string(longTxKey("counter").SerializeToBytes())])
+	})
+
+	t.Run("size_limit", func(t *testing.T) {
+		s := startLongTx(t, newMemStore(), time.Minute, 64)
+		require.NoError(t, s.Replace(ctx, longTxKey("a"), data("small"), false))
+		require.Error(t, s.Replace(ctx, longTxKey("b"), data(string(make([]byte, 64))), false))
+		require.NoError(t, s.Rollback(ctx))
+	})
+
+	t.Run("read_size_limit", func(t *testing.T) {
+		store := newMemStore()
+		for _, id := range []string{"a", "b", "c", "d"} {
+			store.put(kv.BuildKey(id), string(make([]byte, 32)))
+		}
+
+		s, err := newLongTxSession(store, time.Minute, 0, 100)
+		require.NoError(t, err)
+		require.NoError(t, s.start(ctx))
+
+		// snapshot reads are not validated at commit, so they don't count against the limit
+		it, err := s.ReadRange(ctx, nil, keys.NewKey(longTxTable, "e"), true, false)
+		require.NoError(t, err)
+		require.Len(t, readAll(t, it, 0), 4)
+
+		it, err = s.Read(ctx, keys.NewKey(longTxTable), false)
+		require.NoError(t, err)
+		var (
+			row  kv.KeyValue
+			rows int
+		)
+		for it.Next(&row) {
+			rows++
+		}
+		require.Equal(t, 2, rows)
+		require.Equal(t, errors.ContentTooLarge("long-running transaction exceeds the limit of 100 bytes of reads"), it.Err())
+		require.NoError(t, s.Rollback(ctx))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		s := startLongTx(t, newMemStore(), time.Millisecond, 0)
+		time.Sleep(5 * time.Millisecond)
+		require.True(t, s.Expired())
+		require.Error(t, s.Replace(ctx, longTxKey("a"), data("a"), false))
+		require.Equal(t, ErrSessionIsGone, s.Commit(ctx))
+		require.NoError(t, s.Rollback(ctx))
+	})
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/tigrisdata/tigris/internal"
 	"github.com/tigrisdata/tigris/keys"
 	"github.com/tigrisdata/tigris/lib/uuid"
+	"github.com/tigrisdata/tigris/server/config"
 	"github.com/tigrisdata/tigris/server/types"
 	"github.com/tigrisdata/tigris/store/kv"
 )
@@ -49,7 +50,10 @@ type BaseTx interface {
 	SetVersionstampedKey(ctx context.Context, key []byte, value []byte) error
 	AtomicAdd(ctx context.Context, key keys.Key, value int64) error
 	AtomicRead(ctx context.Context, key keys.Key) (int64, error)
+	AtomicReadRange(ctx context.Context, lKey keys.Key, rKey keys.Key, isSnapshot bool) (kv.AtomicIterator, error)
 	RangeSize(ctx context.Context, table []byte, lKey keys.Key, rKey keys.Key) (size int64, err error)
+	GetReadVersion(ctx context.Context) (int64, error)
+	SetReadVersion(ctx context.Context, version int64) error
 }
 
 type Tx interface {
@@ -57,6 +61,9 @@ type Tx interface {
 
 	Commit(ctx context.Context) error
 	Rollback(ctx context.Context) error
+	// CommittedVersion returns the version at which the transaction is committed. It is zero until the transaction is
+	// committed and for a transaction without writes.
+	CommittedVersion() int64
 }
 
 type stagedDatabase interface {
@@ -123,6 +130,26 @@ func (m *Manager) StartTx(ctx context.Context) (Tx, error) {
 	return session, nil
 }
 
+// StartLongRunningTx starts a read-write tx session that is not bounded by the five seconds limit of FDB. The
+// writes are buffered until commit and the reads are validated at commit, see LongTxSession.
+func (m *Manager) StartLongRunningTx(ctx context.Context) (Tx, error) {
+	cfg := config.DefaultConfig.KV.LongRunningTx
+	if !cfg.Enabled {
+		return nil, errors.InvalidArgument("long-running transactions are not enabled")
+	}
+
+	session, err := newLongTxSession(m.kvStore, cfg.Timeout, cfg.MaxSize, cfg.MaxReadSize)
+	if err != nil {
+		return nil, errors.Internal("issue creating a session %v", err)
+	}
+
+	if err = session.start(ctx); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
 type sessionState uint8
 
 const (
@@ -142,6 +169,8 @@ type TxSession struct {
 	kTx     kv.Tx
 	state   sessionState
 	txCtx   *api.TransactionCtx
+
+	committedVersion int64
 }
 
 func newTxSession(kv kv.TxStore) (*TxSession, error) {
@@ -294,6 +323,17 @@ func (s *TxSession) AtomicRead(ctx context.Context, key keys.Key) (int64, error)
 	return s.kTx.AtomicRead(ctx, key.Table(), kv.BuildKey(key.IndexParts()...))
 }
 
+func (s *TxSession) AtomicReadRange(ctx context.Context, lKey keys.Key, rKey keys.Key, isSnapshot bool) (kv.AtomicIterator, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.validateSession(); err != nil {
+		return nil, err
+	}
+
+	return s.kTx.AtomicReadRange(ctx, lKey.Table(), kv.BuildKey(lKey.IndexParts()...), kv.BuildKey(rKey.IndexParts()...), isSnapshot)
+}
+
 func (s *TxSession) Get(ctx context.Context, key []byte, isSnapshot bool) (kv.Future, error) {
 	s.Lock()
 	defer s.Unlock()
@@ -322,6 +362,28 @@ func (s *TxSession) RangeSize(ctx context.Context, _ []byte, lKey keys.Key, rKey
 	return s.kTx.RangeSize(ctx, rKey.Table(), nil, kv.BuildKey(rKey.IndexParts()...))
 }
 
+func (s *TxSession) GetReadVersion(ctx context.Context) (int64, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.validateSession(); err != nil {
+		return 0, err
+	}
+
+	return s.kTx.GetReadVersion(ctx)
+}
+
+func (s *TxSession) SetReadVersion(ctx context.Context, version int64) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.validateSession(); err != nil {
+		return err
+	}
+
+	return s.kTx.SetReadVersion(ctx, version)
+}
+
 func (s *TxSession) Commit(ctx context.Context) error {
 	s.Lock()
 	defer s.Unlock()
@@ -329,11 +391,32 @@ func (s *TxSession) Commit(ctx context.Context) error {
 	s.state = sessionEnded
 
 	err := s.kTx.Commit(ctx)
+	if err == nil {
+		s.committedVersion = committedVersion(ctx, s.kTx)
+	}
 
 	s.kTx = nil
 	return err
 }
 
+func (s *TxSession) CommittedVersion() int64 {
+	s.RLock()
+	defer s.RUnlock()
+
+	return s.committedVersion
+}
+
+// committedVersion returns the committed version of a committed kv transaction, a read-only transaction returns -1
+// which is reported as zero.
+func committedVersion(ctx context.Context, tx kv.Tx) int64 {
+	version, err := tx.GetCommittedVersion(ctx)
+	if err != nil || version < 0 {
+		return 0
+	}
+
+	return version
+}
+
 func (s *TxSession) Rollback(ctx context.Context) error {
 	s.Lock()
 	defer s.Unlock()
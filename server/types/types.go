@@ -32,4 +32,6 @@ type AccessToken struct {
 	Sub       string
 	Project   string
 	Role      string
+	// Claims are the custom claims of the token, the values which aren't a string, a number or a boolean are dropped.
+	Claims map[string]string
 }
@@ -75,14 +75,15 @@ func (c *cache) Set(ctx context.Context, tableName string, key string, value *in
 	return err
 }
 
-func (c *cache) GetSet(ctx context.Context, tableName string, key string, value *internal.CacheData) (*internal.CacheData, error) {
+func (c *cache) GetSet(ctx context.Context, tableName string, key string, value *internal.CacheData, ttl time.Duration) (*internal.CacheData, error) {
 	cacheKey := encodeToCacheKey(tableName, key)
 	enc, err := internal.EncodeCacheData(value)
 	if err != nil {
 		return nil, err
 	}
 
-	val, err := c.Client.GetSet(ctx, cacheKey, enc).Bytes()
+	// SET with GET replaces GETSET, it also sets the expiry along with the value
+	val, err := c.Client.SetArgs(ctx, cacheKey, enc, xredis.SetArgs{TTL: ttl, Get: true}).Result()
 	if err != nil && err != xredis.Nil {
 		return nil, err
 	}
@@ -90,7 +91,7 @@ func (c *cache) GetSet(ctx context.Context, tableName string, key string, value
 		return nil, nil
 	}
 
-	return internal.DecodeCacheData(val)
+	return decodeCacheValue([]byte(val))
 }
 
 func (c *cache) Get(ctx context.Context, tableName string, key string, options *GetOptions) (*internal.CacheData, error) {
@@ -115,7 +116,7 @@ func (c *cache) Get(ctx context.Context, tableName string, key string, options *
 		return nil, err
 	}
 
-	return internal.DecodeCacheData(value)
+	return decodeCacheValue(value)
 }
 
 func (c *cache) Delete(ctx context.Context, tableName string, keys ...string) (int64, error) {
@@ -156,6 +157,184 @@ func (c *cache) Scan(ctx context.Context, tableName string, cursor uint64, count
 	return scanCmd.Val()
 }
 
+func (c *cache) MGet(ctx context.Context, tableName string, keys ...string) ([]*internal.CacheData, error) {
+	if len(keys) == 0 {
+		return nil, ErrEmptyKey
+	}
+
+	cacheKeys := make([]string, len(keys))
+	for i, k := range keys {
+		cacheKeys[i] = encodeToCacheKey(tableName, k)
+	}
+
+	values, err := c.Client.MGet(ctx, cacheKeys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*internal.CacheData, len(values))
+	for i, v := range values {
+		str, ok := v.(string)
+		if !ok {
+			// the key doesn't exist
+			continue
+		}
+		if result[i], err = decodeCacheValue([]byte(str)); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// msetScript sets all the keys with an optional expiry in milliseconds. In NX mode none of the keys is set if one of
+// them already exists.
+var msetScript = xredis.NewScript(`
+local ttl = tonumber(ARGV[1])
+if ARGV[2] == "1" then
+	for _, key in ipairs(KEYS) do
+		if redis.call("EXISTS", key) == 1 then
+			return 0
+		end
+	end
+end
+for i, key in ipairs(KEYS) do
+	if ttl > 0 then
+		redis.call("SET", key, ARGV[i + 2], "PX", ttl)
+	else
+		redis.call("SET", key, ARGV[i + 2])
+	end
+end
+return 1
+`)
+
+func (c *cache) MSet(ctx context.Context, tableName string, values map[string]*internal.CacheData, options *SetOptions) error {
+	if len(values) == 0 {
+		return ErrEmptyKey
+	}
+
+	var ttl time.Duration
+	if options != nil && options.EX > 0 {
+		ttl = time.Duration(options.EX) * time.Second
+	} else if options != nil && options.PX > 0 {
+		ttl = time.Duration(options.PX) * time.Millisecond
+	}
+
+	nx := "0"
+	if options != nil && options.NX {
+		nx = "1"
+	}
+
+	cacheKeys := make([]string, 0, len(values))
+	args := make([]any, 0, len(values)+2)
+	args = append(args, ttl.Milliseconds(), nx)
+	for k, v := range values {
+		enc, err := internal.EncodeCacheData(v)
+		if err != nil {
+			return err
+		}
+		cacheKeys = append(cacheKeys, encodeToCacheKey(tableName, k))
+		args = append(args, enc)
+	}
+
+	set, err := msetScript.Run(ctx, c.Client, cacheKeys, args...).Int()
+	if err != nil {
+		return err
+	}
+	if set == 0 {
+		return ErrKeyAlreadyExists
+	}
+
+	return nil
+}
+
+func (c *cache) Expire(ctx context.Context, tableName string, key string, ttl time.Duration) error {
+	ok, err := c.Client.PExpire(ctx, encodeToCacheKey(tableName, key), ttl).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrKeyNotFound
+	}
+
+	return nil
+}
+
+func (c *cache) Persist(ctx context.Context, tableName string, key string) error {
+	cacheKey := encodeToCacheKey(tableName, key)
+	ok, err := c.Client.Persist(ctx, cacheKey).Result()
+	if err != nil || ok {
+		return err
+	}
+
+	// persist also returns false for a key without an expiry
+	exists, err := c.Client.Exists(ctx, cacheKey).Result()
+	if err != nil {
+		return err
+	}
+	if exists == 0 {
+		return ErrKeyNotFound
+	}
+
+	return nil
+}
+
+func (c *cache) TTL(ctx context.Context, tableName string, key string) (time.Duration, error) {
+	ttl, err := c.Client.PTTL(ctx, encodeToCacheKey(tableName, key)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// redis returns -2 if the key doesn't exist and -1 if it has no expiry
+	switch ttl {
+	case -2:
+		return 0, ErrKeyNotFound
+	case -1:
+		return NoExpiry, nil
+	default:
+		return ttl, nil
+	}
+}
+
+func (c *cache) Stats(ctx context.Context, tableName string) (*Stats, error) {
+	stats := &Stats{}
+
+	var (
+		cursor uint64
+		keys   []string
+		err    error
+	)
+	for {
+		keys, cursor, err = c.Client.Scan(ctx, cursor, encodeToCacheKey(tableName, "*"), config.DefaultConfig.Cache.MaxScan).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(keys) > 0 {
+			pipe := c.Client.Pipeline()
+			usages := make([]*xredis.IntCmd, len(keys))
+			for i, k := range keys {
+				usages[i] = pipe.MemoryUsage(ctx, k)
+			}
+			if _, err = pipe.Exec(ctx); err != nil && err != xredis.Nil {
+				return nil, err
+			}
+
+			for _, usage := range usages {
+				// the key may have expired since the scan
+				if bytes, err := usage.Result(); err == nil {
+					stats.Keys++
+					stats.MemoryBytes += bytes
+				}
+			}
+		}
+
+		if cursor == 0 {
+			return stats, nil
+		}
+	}
+}
+
 func (c *cache) ListStreams(ctx context.Context, streamNamePrefix string) ([]string, error) {
 	return c.Client.Keys(ctx, streamNamePrefix).Result()
 }
@@ -19,13 +19,14 @@ import (
 	"fmt"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/tigrisdata/tigris/internal"
 	"github.com/tigrisdata/tigris/server/config"
 )
 
-func dropCacheTable(t *testing.T, c *cache, tableName string) {
+func dropCacheTable(t *testing.T, c Cache, tableName string) {
 	keys, err := c.Keys(context.TODO(), tableName, "*")
 	require.NoError(t, err)
 
@@ -37,7 +38,11 @@ func dropCacheTable(t *testing.T, c *cache, tableName string) {
 }
 
 func TestRedis(t *testing.T) {
-	c := newCache(config.GetTestCacheConfig())
+	testCache(t, newCache(config.GetTestCacheConfig()))
+}
+
+// testCache runs the tests shared by all the implementations of Cache.
+func testCache(t *testing.T, c Cache) {
 	ctx := context.TODO()
 	tableName := "cache_test"
 
@@ -58,13 +63,22 @@ func TestRedis(t *testing.T) {
 		require.NoError(t, c.Set(ctx, tableName, "key1", internal.NewCacheData(s1), nil))
 
 		s2 := []byte(`{"a1": "b1"}`)
-		g, err := c.GetSet(ctx, tableName, "key1", internal.NewCacheData(s2))
+		g, err := c.GetSet(ctx, tableName, "key1", internal.NewCacheData(s2), 0)
 		require.NoError(t, err)
 		require.Equal(t, s1, g.RawData)
 
 		g, err = c.Get(ctx, tableName, "key1", nil)
 		require.NoError(t, err)
 		require.Equal(t, s2, g.RawData)
+
+		// the expiry is set along with the new value
+		g, err = c.GetSet(ctx, tableName, "key1", internal.NewCacheData(s1), time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, s2, g.RawData)
+
+		ttl, err := c.TTL(ctx, tableName, "key1")
+		require.NoError(t, err)
+		require.Greater(t, ttl, time.Duration(0))
 	})
 
 	t.Run("set_nx", func(t *testing.T) {
@@ -155,4 +169,254 @@ func TestRedis(t *testing.T) {
 			require.Truef(t, contains, "key %s not found", keyToSearch)
 		}
 	})
+
+	t.Run("mget_mset", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		s1, s2 := []byte(`{"a": "b"}`), []byte(`{"c": "d"}`)
+		require.NoError(t, c.MSet(ctx, tableName, map[string]*internal.CacheData{
+			"key1": internal.NewCacheData(s1),
+			"key2": internal.NewCacheData(s2),
+		}, &SetOptions{PX: 60000}))
+
+		values, err := c.MGet(ctx, tableName, "key1", "missing", "key2")
+		require.NoError(t, err)
+		require.Len(t, values, 3)
+		require.Equal(t, s1, values[0].RawData)
+		require.Nil(t, values[1])
+		require.Equal(t, s2, values[2].RawData)
+
+		ttl, err := c.TTL(ctx, tableName, "key2")
+		require.NoError(t, err)
+		require.Greater(t, ttl, time.Duration(0))
+
+		// none of the keys is set if one of them exists
+		require.Equal(t, ErrKeyAlreadyExists, c.MSet(ctx, tableName, map[string]*internal.CacheData{
+			"key1": internal.NewCacheData(s2),
+			"key3": internal.NewCacheData(s2),
+		}, &SetOptions{NX: true}))
+		exists, err := c.Exists(ctx, tableName, "key1", "key3")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), exists)
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		s1 := []byte(`{"a": "b"}`)
+		require.NoError(t, c.Set(ctx, tableName, "key1", internal.NewCacheData(s1), nil))
+
+		ttl, err := c.TTL(ctx, tableName, "key1")
+		require.NoError(t, err)
+		require.Equal(t, NoExpiry, ttl)
+
+		require.NoError(t, c.Expire(ctx, tableName, "key1", time.Minute))
+		ttl, err = c.TTL(ctx, tableName, "key1")
+		require.NoError(t, err)
+		require.Greater(t, ttl, 59*time.Second)
+
+		require.NoError(t, c.Persist(ctx, tableName, "key1"))
+		require.NoError(t, c.Persist(ctx, tableName, "key1"))
+		ttl, err = c.TTL(ctx, tableName, "key1")
+		require.NoError(t, err)
+		require.Equal(t, NoExpiry, ttl)
+
+		require.Equal(t, ErrKeyNotFound, c.Expire(ctx, tableName, "missing", time.Minute))
+		require.Equal(t, ErrKeyNotFound, c.Persist(ctx, tableName, "missing"))
+		_, err = c.TTL(ctx, tableName, "missing")
+		require.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		s1 := []byte(`{"a": "b"}`)
+		require.NoError(t, c.Set(ctx, tableName, "key1", internal.NewCacheData(s1), nil))
+		require.NoError(t, c.Set(ctx, tableName, "key2", internal.NewCacheData(s1), nil))
+
+		stats, err := c.Stats(ctx, tableName)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), stats.Keys)
+		require.Greater(t, stats.MemoryBytes, int64(0))
+	})
+
+	t.Run("counters", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		v, err := c.IncrBy(ctx, tableName, "counter", 1)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), v)
+		v, err = c.IncrBy(ctx, tableName, "counter", 10)
+		require.NoError(t, err)
+		require.Equal(t, int64(11), v)
+		v, err = c.IncrBy(ctx, tableName, "counter", -12)
+		require.NoError(t, err)
+		require.Equal(t, int64(-1), v)
+
+		// counters are readable as plain values
+		g1, err := c.Get(ctx, tableName, "counter", nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("-1"), g1.RawData)
+
+		require.NoError(t, c.Set(ctx, tableName, "key1", internal.NewCacheData([]byte(`{"a": "b"}`)), nil))
+		_, err = c.IncrBy(ctx, tableName, "key1", 1)
+		require.Equal(t, ErrWrongType, err)
+	})
+
+	t.Run("hashes", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		added, err := c.HSet(ctx, tableName, "hash", map[string]*internal.CacheData{
+			"f1": internal.NewCacheData([]byte(`"v1"`)),
+			"f2": internal.NewCacheData([]byte(`{"a": 1}`)),
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), added)
+
+		added, err = c.HSet(ctx, tableName, "hash", map[string]*internal.CacheData{
+			"f1": internal.NewCacheData([]byte(`"v2"`)),
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(0), added)
+
+		v, err := c.HGet(ctx, tableName, "hash", "f1")
+		require.NoError(t, err)
+		require.Equal(t, []byte(`"v2"`), v.RawData)
+		_, err = c.HGet(ctx, tableName, "hash", "f3")
+		require.Equal(t, ErrKeyNotFound, err)
+
+		fields, err := c.HGetAll(ctx, tableName, "hash")
+		require.NoError(t, err)
+		require.Len(t, fields, 2)
+		require.Equal(t, []byte(`{"a": 1}`), fields["f2"].RawData)
+		_, err = c.HGetAll(ctx, tableName, "missing")
+		require.Equal(t, ErrKeyNotFound, err)
+
+		removed, err := c.HDel(ctx, tableName, "hash", "f1", "f3")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), removed)
+		removed, err = c.HDel(ctx, tableName, "missing", "f1")
+		require.NoError(t, err)
+		require.Equal(t, int64(0), removed)
+
+		_, err = c.IncrBy(ctx, tableName, "hash", 1)
+		require.Equal(t, ErrWrongType, err)
+	})
+
+	t.Run("sorted_sets", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		added, err := c.ZAdd(ctx, tableName, "leaderboard",
+			ZMember{Member: "a", Score: 10},
+			ZMember{Member: "b", Score: 30},
+			ZMember{Member: "c", Score: 20},
+		)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), added)
+
+		members, err := c.ZRange(ctx, tableName, "leaderboard", 0, -1, false)
+		require.NoError(t, err)
+		require.Equal(t, []ZMember{{"a", 10}, {"c", 20}, {"b", 30}}, members)
+
+		members, err = c.ZRange(ctx, tableName, "leaderboard", 0, 1, true)
+		require.NoError(t, err)
+		require.Equal(t, []ZMember{{"b", 30}, {"c", 20}}, members)
+
+		rank, err := c.ZRank(ctx, tableName, "leaderboard", "c", false)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), rank)
+		rank, err = c.ZRank(ctx, tableName, "leaderboard", "a", true)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), rank)
+		_, err = c.ZRank(ctx, tableName, "leaderboard", "d", false)
+		require.Equal(t, ErrKeyNotFound, err)
+
+		members, err = c.ZRangeByScore(ctx, tableName, "leaderboard", 15, 30, 0)
+		require.NoError(t, err)
+		require.Equal(t, []ZMember{{"c", 20}, {"b", 30}}, members)
+		members, err = c.ZRangeByScore(ctx, tableName, "leaderboard", 0, 30, 1)
+		require.NoError(t, err)
+		require.Equal(t, []ZMember{{"a", 10}}, members)
+
+		removed, err := c.ZRem(ctx, tableName, "leaderboard", "a", "d")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), removed)
+		members, err = c.ZRange(ctx, tableName, "leaderboard", 0, -1, false)
+		require.NoError(t, err)
+		require.Equal(t, []ZMember{{"c", 20}, {"b", 30}}, members)
+	})
+
+	t.Run("lists", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		length, err := c.LPush(ctx, tableName, "queue", internal.NewCacheData([]byte(`1`)), internal.NewCacheData([]byte(`2`)))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), length)
+		length, err = c.LPush(ctx, tableName, "queue", internal.NewCacheData([]byte(`3`)))
+		require.NoError(t, err)
+		require.Equal(t, int64(3), length)
+
+		for _, expected := range []string{"1", "2", "3"} {
+			v, err := c.RPop(ctx, tableName, "queue")
+			require.NoError(t, err)
+			require.Equal(t, []byte(expected), v.RawData)
+		}
+		_, err = c.RPop(ctx, tableName, "queue")
+		require.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("expire_if_persistent", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		_, err := c.IncrBy(ctx, tableName, "counter", 1)
+		require.NoError(t, err)
+		require.NoError(t, c.ExpireIfPersistent(ctx, tableName, "counter", time.Hour))
+		require.NoError(t, c.ExpireIfPersistent(ctx, tableName, "counter", time.Minute))
+
+		// the expiry set first is kept
+		ttl, err := c.TTL(ctx, tableName, "counter")
+		require.NoError(t, err)
+		require.Greater(t, ttl, time.Minute)
+	})
+
+	t.Run("compare_and_delete", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		owner := internal.NewCacheData([]byte(`owner`))
+		require.NoError(t, c.Set(ctx, tableName, "lease", owner, nil))
+
+		deleted, err := c.CompareAndDelete(ctx, tableName, "lease", internal.NewCacheData([]byte(`other`)))
+		require.NoError(t, err)
+		require.False(t, deleted)
+
+		deleted, err = c.CompareAndDelete(ctx, tableName, "lease", owner)
+		require.NoError(t, err)
+		require.True(t, deleted)
+
+		deleted, err = c.CompareAndDelete(ctx, tableName, "lease", owner)
+		require.NoError(t, err)
+		require.False(t, deleted)
+	})
+
+	t.Run("compare_and_expire", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		owner := internal.NewCacheData([]byte(`owner`))
+		require.NoError(t, c.Set(ctx, tableName, "lease", owner, &SetOptions{EX: 60}))
+
+		set, err := c.CompareAndExpire(ctx, tableName, "lease", internal.NewCacheData([]byte(`other`)), time.Hour)
+		require.NoError(t, err)
+		require.False(t, set)
+
+		set, err = c.CompareAndExpire(ctx, tableName, "lease", owner, time.Hour)
+		require.NoError(t, err)
+		require.True(t, set)
+		ttl, err := c.TTL(ctx, tableName, "lease")
+		require.NoError(t, err)
+		require.Greater(t, ttl, time.Minute)
+
+		set, err = c.CompareAndExpire(ctx, tableName, "missing", owner, time.Hour)
+		require.NoError(t, err)
+		require.False(t, set)
+	})
 }
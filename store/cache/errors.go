@@ -31,6 +31,7 @@ const (
 	ErrCodeKeyNotFound      ErrCode = 0x03
 	ErrCodeKeyAlreadyExists ErrCode = 0x04
 	ErrCodeEmptyKey         ErrCode = 0x05
+	ErrCodeWrongType        ErrCode = 0x06
 )
 
 var (
@@ -41,6 +42,7 @@ var (
 	ErrKeyNotFound      = NewCacheError(ErrCodeKeyNotFound, "key not found")
 	ErrKeyAlreadyExists = NewCacheError(ErrCodeKeyAlreadyExists, "key already exists")
 	ErrEmptyKey         = NewCacheError(ErrCodeEmptyKey, "key is empty")
+	ErrWrongType        = NewCacheError(ErrCodeWrongType, "key holds a value of a different type")
 )
 
 type Error struct {
@@ -0,0 +1,996 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/server/config"
+)
+
+// memoryEntry is a key of the in-memory cache. The value is a []byte for the plain values and the counters, a
+// map[string][]byte for the hashes, a map[string]float64 for the sorted sets, a [][]byte for the lists and a
+// *memoryStreamData for the streams.
+type memoryEntry struct {
+	value    any
+	expireAt time.Time
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expireAt.IsZero() && !now.Before(e.expireAt)
+}
+
+// size is the approximate memory used by the entry.
+func (e *memoryEntry) size(key string) int64 {
+	sz := int64(len(key))
+	switch v := e.value.(type) {
+	case []byte:
+		sz += int64(len(v))
+	case map[string][]byte:
+		for f, fv := range v {
+			sz += int64(len(f) + len(fv))
+		}
+	case map[string]float64:
+		for m := range v {
+			sz += int64(len(m) + 8)
+		}
+	case [][]byte:
+		for _, lv := range v {
+			sz += int64(len(lv))
+		}
+	case *memoryStreamData:
+		for _, m := range v.messages {
+			sz += int64(len(m.ID))
+			for mk, mv := range m.Values {
+				if s, ok := mv.(string); ok {
+					sz += int64(len(mk) + len(s))
+				}
+			}
+		}
+	}
+
+	return sz
+}
+
+// memoryCache is an in-process implementation of the Cache and the Stream interfaces with the semantics of the Redis
+// commands used by the Redis implementation. It is meant for local development and tests, the keys are lost on
+// restart. All the operations are serialized by a single lock.
+type memoryCache struct {
+	sync.Mutex
+
+	entries map[string]*memoryEntry
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{
+		entries: make(map[string]*memoryEntry),
+	}
+}
+
+// get returns the entry of the key if it exists and is not expired, it must be called with the lock held.
+func (c *memoryCache) get(key string) *memoryEntry {
+	e, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if e.expired(time.Now()) {
+		delete(c.entries, key)
+		return nil
+	}
+
+	return e
+}
+
+// getBytes returns the plain value of the key, ErrKeyNotFound if it doesn't exist.
+func (c *memoryCache) getBytes(key string) ([]byte, error) {
+	e := c.get(key)
+	if e == nil {
+		return nil, ErrKeyNotFound
+	}
+
+	value, ok := e.value.([]byte)
+	if !ok {
+		return nil, ErrWrongType
+	}
+
+	return value, nil
+}
+
+func (c *memoryCache) put(key string, value any, ttl time.Duration) {
+	e := &memoryEntry{value: value}
+	if ttl > 0 {
+		e.expireAt = time.Now().Add(ttl)
+	}
+	c.entries[key] = e
+}
+
+// matchingKeys returns the sorted non-expired keys matching the glob style pattern.
+func (c *memoryCache) matchingKeys(pattern string) []string {
+	now := time.Now()
+
+	var keys []string
+	for k, e := range c.entries {
+		if e.expired(now) {
+			delete(c.entries, k)
+			continue
+		}
+		if matchPattern(pattern, k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func setOptionsTTL(options *SetOptions) time.Duration {
+	switch {
+	case options != nil && options.EX > 0:
+		return time.Duration(options.EX) * time.Second
+	case options != nil && options.PX > 0:
+		return time.Duration(options.PX) * time.Millisecond
+	default:
+		return 0
+	}
+}
+
+func (c *memoryCache) Set(_ context.Context, tableName string, key string, value *internal.CacheData, options *SetOptions) error {
+	enc, err := internal.EncodeCacheData(value)
+	if err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	exists := c.get(cacheKey) != nil
+	if options != nil && options.XX && !exists {
+		return ErrKeyNotFound
+	}
+	if options != nil && options.NX && exists {
+		return ErrKeyAlreadyExists
+	}
+
+	c.put(cacheKey, enc, setOptionsTTL(options))
+	return nil
+}
+
+func (c *memoryCache) GetSet(_ context.Context, tableName string, key string, value *internal.CacheData, ttl time.Duration) (*internal.CacheData, error) {
+	enc, err := internal.EncodeCacheData(value)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	old, err := c.getBytes(cacheKey)
+	if err != nil && err != ErrKeyNotFound {
+		return nil, err
+	}
+
+	c.put(cacheKey, enc, ttl)
+	if len(old) == 0 {
+		return nil, nil
+	}
+
+	return decodeCacheValue(old)
+}
+
+func (c *memoryCache) Get(_ context.Context, tableName string, key string, options *GetOptions) (*internal.CacheData, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	value, err := c.getBytes(cacheKey)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case options != nil && options.Expiry > 0:
+		c.entries[cacheKey].expireAt = time.Now().Add(options.Expiry)
+	case options != nil && options.GetDelete:
+		delete(c.entries, cacheKey)
+	}
+
+	return decodeCacheValue(value)
+}
+
+func (c *memoryCache) Delete(_ context.Context, tableName string, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, ErrEmptyKey
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	var deleted int64
+	for _, k := range keys {
+		if c.delete(encodeToCacheKey(tableName, k)) {
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// delete removes the key and wakes up the readers blocked on it if it is a stream.
+func (c *memoryCache) delete(key string) bool {
+	e := c.get(key)
+	if e == nil {
+		return false
+	}
+
+	if s, ok := e.value.(*memoryStreamData); ok {
+		s.notify()
+	}
+	delete(c.entries, key)
+
+	return true
+}
+
+func (c *memoryCache) Exists(_ context.Context, tableName string, keys ...string) (int64, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if len(keys) == 0 {
+		if c.get(tableName) != nil {
+			return 1, nil
+		}
+		return 0, nil
+	}
+
+	var count int64
+	for _, k := range keys {
+		if c.get(encodeToCacheKey(tableName, k)) != nil {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (c *memoryCache) Keys(_ context.Context, tableName string, pattern string) ([]string, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.matchingKeys(encodeToCacheKey(tableName, pattern)), nil
+}
+
+// Scan uses the offset in the sorted matching keys as the cursor, the keys added or removed between two calls may
+// therefore shift the keys returned by the next call.
+func (c *memoryCache) Scan(_ context.Context, tableName string, cursor uint64, count int64, pattern string) ([]string, uint64) {
+	if count > config.DefaultConfig.Cache.MaxScan {
+		count = config.DefaultConfig.Cache.MaxScan
+	}
+	if count <= 0 {
+		count = 10
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	keys := c.matchingKeys(encodeToCacheKey(tableName, pattern))
+	if cursor >= uint64(len(keys)) {
+		return nil, 0
+	}
+
+	end := cursor + uint64(count)
+	if end >= uint64(len(keys)) {
+		return keys[cursor:], 0
+	}
+
+	return keys[cursor:end], end
+}
+
+func (c *memoryCache) MGet(_ context.Context, tableName string, keys ...string) ([]*internal.CacheData, error) {
+	if len(keys) == 0 {
+		return nil, ErrEmptyKey
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	result := make([]*internal.CacheData, len(keys))
+	for i, k := range keys {
+		// like in redis, the keys holding a value of a different type are returned as missing
+		value, err := c.getBytes(encodeToCacheKey(tableName, k))
+		if err != nil {
+			continue
+		}
+		if result[i], err = decodeCacheValue(value); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (c *memoryCache) MSet(_ context.Context, tableName string, values map[string]*internal.CacheData, options *SetOptions) error {
+	if len(values) == 0 {
+		return ErrEmptyKey
+	}
+
+	encoded := make(map[string][]byte, len(values))
+	for k, v := range values {
+		enc, err := internal.EncodeCacheData(v)
+		if err != nil {
+			return err
+		}
+		encoded[encodeToCacheKey(tableName, k)] = enc
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if options != nil && options.NX {
+		for k := range encoded {
+			if c.get(k) != nil {
+				return ErrKeyAlreadyExists
+			}
+		}
+	}
+
+	ttl := setOptionsTTL(options)
+	for k, enc := range encoded {
+		c.put(k, enc, ttl)
+	}
+
+	return nil
+}
+
+func (c *memoryCache) Expire(_ context.Context, tableName string, key string, ttl time.Duration) error {
+	c.Lock()
+	defer c.Unlock()
+
+	e := c.get(encodeToCacheKey(tableName, key))
+	if e == nil {
+		return ErrKeyNotFound
+	}
+	e.expireAt = time.Now().Add(ttl)
+
+	return nil
+}
+
+func (c *memoryCache) Persist(_ context.Context, tableName string, key string) error {
+	c.Lock()
+	defer c.Unlock()
+
+	e := c.get(encodeToCacheKey(tableName, key))
+	if e == nil {
+		return ErrKeyNotFound
+	}
+	e.expireAt = time.Time{}
+
+	return nil
+}
+
+func (c *memoryCache) TTL(_ context.Context, tableName string, key string) (time.Duration, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	e := c.get(encodeToCacheKey(tableName, key))
+	if e == nil {
+		return 0, ErrKeyNotFound
+	}
+	if e.expireAt.IsZero() {
+		return NoExpiry, nil
+	}
+
+	return time.Until(e.expireAt).Truncate(time.Millisecond), nil
+}
+
+func (c *memoryCache) ExpireIfPersistent(_ context.Context, tableName string, key string, ttl time.Duration) error {
+	c.Lock()
+	defer c.Unlock()
+
+	if e := c.get(encodeToCacheKey(tableName, key)); e != nil && e.expireAt.IsZero() {
+		e.expireAt = time.Now().Add(ttl)
+	}
+
+	return nil
+}
+
+func (c *memoryCache) CompareAndDelete(_ context.Context, tableName string, key string, value *internal.CacheData) (bool, error) {
+	enc, err := internal.EncodeCacheData(value)
+	if err != nil {
+		return false, err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	current, err := c.getBytes(cacheKey)
+	if err == ErrKeyNotFound || err == ErrWrongType {
+		return false, nil
+	}
+	if err != nil || !bytes.Equal(current, enc) {
+		return false, err
+	}
+
+	return c.delete(cacheKey), nil
+}
+
+func (c *memoryCache) CompareAndExpire(_ context.Context, tableName string, key string, value *internal.CacheData, ttl time.Duration) (bool, error) {
+	enc, err := internal.EncodeCacheData(value)
+	if err != nil {
+		return false, err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	e := c.get(encodeToCacheKey(tableName, key))
+	if e == nil {
+		return false, nil
+	}
+	if current, ok := e.value.([]byte); !ok || !bytes.Equal(current, enc) {
+		return false, nil
+	}
+
+	e.expireAt = time.Now().Add(ttl)
+	return true, nil
+}
+
+func (c *memoryCache) Stats(_ context.Context, tableName string) (*Stats, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	stats := &Stats{}
+	for _, k := range c.matchingKeys(encodeToCacheKey(tableName, "*")) {
+		stats.Keys++
+		stats.MemoryBytes += c.entries[k].size(k)
+	}
+
+	return stats, nil
+}
+
+func (c *memoryCache) IncrBy(_ context.Context, tableName string, key string, delta int64) (int64, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	value, err := c.getBytes(cacheKey)
+	if err != nil && err != ErrKeyNotFound {
+		return 0, err
+	}
+
+	var counter int64
+	if err == nil {
+		if counter, err = strconv.ParseInt(string(value), 10, 64); err != nil {
+			return 0, ErrWrongType
+		}
+	}
+	counter += delta
+
+	// like in redis, the expiry of an existing counter is kept
+	if e := c.get(cacheKey); e != nil {
+		e.value = []byte(strconv.FormatInt(counter, 10))
+	} else {
+		c.put(cacheKey, []byte(strconv.FormatInt(counter, 10)), 0)
+	}
+
+	return counter, nil
+}
+
+// getTyped returns the entry of the key creating it with the value returned by create if it doesn't exist,
+// ErrWrongType if the key holds a value of a different type than the one checked by ok.
+func (c *memoryCache) getTyped(key string, ok func(any) bool, create func() any) (*memoryEntry, error) {
+	e := c.get(key)
+	if e == nil {
+		if create == nil {
+			return nil, ErrKeyNotFound
+		}
+		e = &memoryEntry{value: create()}
+		c.entries[key] = e
+	}
+	if !ok(e.value) {
+		return nil, ErrWrongType
+	}
+
+	return e, nil
+}
+
+func isHash(v any) bool {
+	_, ok := v.(map[string][]byte)
+	return ok
+}
+
+func isSortedSet(v any) bool {
+	_, ok := v.(map[string]float64)
+	return ok
+}
+
+func isList(v any) bool {
+	_, ok := v.([][]byte)
+	return ok
+}
+
+func (c *memoryCache) HSet(_ context.Context, tableName string, key string, fields map[string]*internal.CacheData) (int64, error) {
+	if len(fields) == 0 {
+		return 0, ErrEmptyKey
+	}
+
+	encoded := make(map[string][]byte, len(fields))
+	for f, v := range fields {
+		enc, err := internal.EncodeCacheData(v)
+		if err != nil {
+			return 0, err
+		}
+		encoded[f] = enc
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	e, err := c.getTyped(encodeToCacheKey(tableName, key), isHash, func() any { return make(map[string][]byte) })
+	if err != nil {
+		return 0, err
+	}
+
+	hash := e.value.(map[string][]byte)
+	var added int64
+	for f, enc := range encoded {
+		if _, ok := hash[f]; !ok {
+			added++
+		}
+		hash[f] = enc
+	}
+
+	return added, nil
+}
+
+func (c *memoryCache) HGet(_ context.Context, tableName string, key string, field string) (*internal.CacheData, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	e, err := c.getTyped(encodeToCacheKey(tableName, key), isHash, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := e.value.(map[string][]byte)[field]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	return internal.DecodeCacheData(value)
+}
+
+func (c *memoryCache) HGetAll(_ context.Context, tableName string, key string) (map[string]*internal.CacheData, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	e, err := c.getTyped(encodeToCacheKey(tableName, key), isHash, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := e.value.(map[string][]byte)
+	fields := make(map[string]*internal.CacheData, len(hash))
+	for f, v := range hash {
+		if fields[f], err = internal.DecodeCacheData(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return fields, nil
+}
+
+func (c *memoryCache) HDel(_ context.Context, tableName string, key string, fields ...string) (int64, error) {
+	if len(fields) == 0 {
+		return 0, ErrEmptyKey
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	e, err := c.getTyped(cacheKey, isHash, nil)
+	if err == ErrKeyNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	hash := e.value.(map[string][]byte)
+	var removed int64
+	for _, f := range fields {
+		if _, ok := hash[f]; ok {
+			delete(hash, f)
+			removed++
+		}
+	}
+	if len(hash) == 0 {
+		// like redis, a hash without fields doesn't exist
+		delete(c.entries, cacheKey)
+	}
+
+	return removed, nil
+}
+
+func (c *memoryCache) ZAdd(_ context.Context, tableName string, key string, members ...ZMember) (int64, error) {
+	if len(members) == 0 {
+		return 0, ErrEmptyKey
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	e, err := c.getTyped(encodeToCacheKey(tableName, key), isSortedSet, func() any { return make(map[string]float64) })
+	if err != nil {
+		return 0, err
+	}
+
+	set := e.value.(map[string]float64)
+	var added int64
+	for _, m := range members {
+		if _, ok := set[m.Member]; !ok {
+			added++
+		}
+		set[m.Member] = m.Score
+	}
+
+	return added, nil
+}
+
+// sortedMembers returns the members of the sorted set ordered by score and then lexicographically like in redis.
+func sortedMembers(set map[string]float64, rev bool) []ZMember {
+	members := make([]ZMember, 0, len(set))
+	for m, s := range set {
+		members = append(members, ZMember{Member: m, Score: s})
+	}
+	sort.Slice(members, func(i, j int) bool {
+		less := members[i].Score < members[j].Score ||
+			(members[i].Score == members[j].Score && members[i].Member < members[j].Member)
+		if rev {
+			return !less
+		}
+		return less
+	})
+
+	return members
+}
+
+// rangeIndexes converts the start and stop ranks, which can be negative to count from the end, to slice indexes.
+func rangeIndexes(start int64, stop int64, length int64) (int64, int64, bool) {
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= length {
+		stop = length - 1
+	}
+	if start > stop || start >= length {
+		return 0, 0, false
+	}
+
+	return start, stop + 1, true
+}
+
+func (c *memoryCache) ZRange(_ context.Context, tableName string, key string, start int64, stop int64, rev bool) ([]ZMember, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	e, err := c.getTyped(encodeToCacheKey(tableName, key), isSortedSet, nil)
+	if err == ErrKeyNotFound {
+		return []ZMember{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	members := sortedMembers(e.value.(map[string]float64), rev)
+	from, to, ok := rangeIndexes(start, stop, int64(len(members)))
+	if !ok {
+		return []ZMember{}, nil
+	}
+
+	return members[from:to], nil
+}
+
+func (c *memoryCache) ZRank(_ context.Context, tableName string, key string, member string, rev bool) (int64, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	e, err := c.getTyped(encodeToCacheKey(tableName, key), isSortedSet, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, m := range sortedMembers(e.value.(map[string]float64), rev) {
+		if m.Member == member {
+			return int64(i), nil
+		}
+	}
+
+	return 0, ErrKeyNotFound
+}
+
+func (c *memoryCache) ZRangeByScore(_ context.Context, tableName string, key string, min float64, max float64, count int64) ([]ZMember, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	e, err := c.getTyped(encodeToCacheKey(tableName, key), isSortedSet, nil)
+	if err == ErrKeyNotFound {
+		return []ZMember{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	members := []ZMember{}
+	for _, m := range sortedMembers(e.value.(map[string]float64), false) {
+		if m.Score < min || m.Score > max {
+			continue
+		}
+		if count > 0 && int64(len(members)) == count {
+			break
+		}
+		members = append(members, m)
+	}
+
+	return members, nil
+}
+
+func (c *memoryCache) ZRem(_ context.Context, tableName string, key string, members ...string) (int64, error) {
+	if len(members) == 0 {
+		return 0, ErrEmptyKey
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	e, err := c.getTyped(cacheKey, isSortedSet, nil)
+	if err == ErrKeyNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	set := e.value.(map[string]float64)
+	var removed int64
+	for _, m := range members {
+		if _, ok := set[m]; ok {
+			delete(set, m)
+			removed++
+		}
+	}
+	if len(set) == 0 {
+		// like redis, an empty sorted set doesn't exist
+		delete(c.entries, cacheKey)
+	}
+
+	return removed, nil
+}
+
+func (c *memoryCache) LPush(_ context.Context, tableName string, key string, values ...*internal.CacheData) (int64, error) {
+	if len(values) == 0 {
+		return 0, ErrEmptyKey
+	}
+
+	encoded := make([][]byte, len(values))
+	for i, v := range values {
+		enc, err := internal.EncodeCacheData(v)
+		if err != nil {
+			return 0, err
+		}
+		// each value is pushed in turn to the head of the list
+		encoded[len(values)-1-i] = enc
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	e, err := c.getTyped(encodeToCacheKey(tableName, key), isList, func() any { return [][]byte{} })
+	if err != nil {
+		return 0, err
+	}
+
+	list := append(encoded, e.value.([][]byte)...)
+	e.value = list
+
+	return int64(len(list)), nil
+}
+
+func (c *memoryCache) RPop(_ context.Context, tableName string, key string) (*internal.CacheData, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	e, err := c.getTyped(cacheKey, isList, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	list := e.value.([][]byte)
+	value := list[len(list)-1]
+	if len(list) == 1 {
+		// like in redis, the list is removed with its last value
+		delete(c.entries, cacheKey)
+	} else {
+		e.value = list[:len(list)-1]
+	}
+
+	return internal.DecodeCacheData(value)
+}
+
+func (c *memoryCache) ListStreams(_ context.Context, streamNamePrefix string) ([]string, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.matchingKeys(streamNamePrefix), nil
+}
+
+func (c *memoryCache) GetStream(_ context.Context, streamName string) (Stream, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.get(streamName) == nil {
+		return nil, ErrStreamNotFound
+	}
+
+	return newMemoryStream(c, streamName), nil
+}
+
+func (c *memoryCache) DeleteStream(_ context.Context, streamName string) error {
+	c.Lock()
+	defer c.Unlock()
+
+	c.delete(streamName)
+	return nil
+}
+
+func (c *memoryCache) CreateOrGetStream(_ context.Context, streamName string) (Stream, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if err := c.createStream(streamName); err != nil && !strings.Contains(err.Error(), errStrConsGroupAlreadyExists) {
+		return nil, err
+	}
+
+	return newMemoryStream(c, streamName), nil
+}
+
+func (c *memoryCache) CreateStream(_ context.Context, streamName string) (Stream, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if err := c.createStream(streamName); err != nil {
+		if strings.Contains(err.Error(), errStrConsGroupAlreadyExists) {
+			return nil, ErrStreamAlreadyExists
+		}
+
+		return nil, err
+	}
+
+	return newMemoryStream(c, streamName), nil
+}
+
+// createStream creates the stream if it doesn't exist along with the default group, like XGROUP CREATE MKSTREAM.
+func (c *memoryCache) createStream(streamName string) error {
+	e, err := c.getTyped(streamName, isStream, func() any { return newMemoryStreamData() })
+	if err != nil {
+		return err
+	}
+
+	return e.value.(*memoryStreamData).createGroup(DefaultGroup, string(ReadGroupPosStart))
+}
+
+// matchPattern reports whether the string matches the redis glob style pattern, supporting '*', '?', character
+// classes with ranges and negation, and backslash escapes.
+func matchPattern(pattern string, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 0 && pattern[0] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if matchPattern(pattern, s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+			s = s[1:]
+			pattern = pattern[1:]
+		case '[':
+			if len(s) == 0 {
+				return false
+			}
+			end := strings.IndexByte(pattern[1:], ']')
+			if end < 0 {
+				// an unterminated class is matched literally
+				if s[0] != '[' {
+					return false
+				}
+				s = s[1:]
+				pattern = pattern[1:]
+				continue
+			}
+			if !matchClass(pattern[1:end+1], s[0]) {
+				return false
+			}
+			s = s[1:]
+			pattern = pattern[end+2:]
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(s) == 0 || s[0] != pattern[0] {
+				return false
+			}
+			s = s[1:]
+			pattern = pattern[1:]
+		}
+	}
+
+	return len(s) == 0
+}
+
+func matchClass(class string, ch byte) bool {
+	negate := len(class) > 0 && class[0] == '^'
+	if negate {
+		class = class[1:]
+	}
+
+	matched := false
+	for i := 0; i < len(class); i++ {
+		if i+2 < len(class) && class[i+1] == '-' {
+			lo, hi := class[i], class[i+2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			if ch >= lo && ch <= hi {
+				matched = true
+			}
+			i += 2
+			continue
+		}
+		if class[i] == ch {
+			matched = true
+		}
+	}
+
+	return matched != negate
+}
@@ -0,0 +1,650 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	xredis "github.com/go-redis/redis/v8"
+	"github.com/tigrisdata/tigris/internal"
+)
+
+const (
+	errStrNoGroup     = "NOGROUP No such key '%s' or consumer group '%s'"
+	errStrKeyRequired = "ERR The XGROUP subcommand requires the key to exist"
+)
+
+// memoryStreamNodeSize is the number of messages TrimApprox removes at once, the default stream-node-max-entries.
+const memoryStreamNodeSize = 100
+
+// readBlockDuration is how long Read waits for new messages, like the Redis implementation.
+var readBlockDuration = 1 * time.Second
+
+// streamID is the parsed form of the "<milliseconds>-<sequence>" stream IDs.
+type streamID struct {
+	ms  uint64
+	seq uint64
+}
+
+func parseStreamID(id string) (streamID, error) {
+	msStr, seqStr, hasSeq := strings.Cut(id, "-")
+
+	ms, err := strconv.ParseUint(msStr, 10, 64)
+	if err != nil {
+		return streamID{}, fmt.Errorf("ERR Invalid stream ID specified as stream command argument '%s'", id)
+	}
+
+	var seq uint64
+	if hasSeq {
+		if seq, err = strconv.ParseUint(seqStr, 10, 64); err != nil {
+			return streamID{}, fmt.Errorf("ERR Invalid stream ID specified as stream command argument '%s'", id)
+		}
+	}
+
+	return streamID{ms: ms, seq: seq}, nil
+}
+
+func (id streamID) less(other streamID) bool {
+	return id.ms < other.ms || (id.ms == other.ms && id.seq < other.seq)
+}
+
+func (id streamID) String() string {
+	return fmt.Sprintf("%d-%d", id.ms, id.seq)
+}
+
+type memoryGroup struct {
+	name          string
+	lastDelivered streamID
+	pending       map[streamID]struct{}
+	consumers     int64
+}
+
+// memoryStreamData is the value of a stream key of the in-memory cache.
+type memoryStreamData struct {
+	ids      []streamID
+	messages []xredis.XMessage
+	lastID   streamID
+	groups   []*memoryGroup
+	// changed is closed and replaced when a message is added or the stream is deleted to wake up the blocked readers.
+	changed chan struct{}
+}
+
+func newMemoryStreamData() *memoryStreamData {
+	return &memoryStreamData{
+		changed: make(chan struct{}),
+	}
+}
+
+func isStream(v any) bool {
+	_, ok := v.(*memoryStreamData)
+	return ok
+}
+
+func (s *memoryStreamData) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// resolve converts a position to an ID, "$" being the last ID of the stream.
+func (s *memoryStreamData) resolve(pos string) (streamID, error) {
+	if pos == ConsumerGroupDefaultCurrentPos {
+		return s.lastID, nil
+	}
+
+	return parseStreamID(pos)
+}
+
+// after returns the messages with an ID greater than the one passed.
+func (s *memoryStreamData) after(id streamID) ([]streamID, []xredis.XMessage) {
+	i := sort.Search(len(s.ids), func(i int) bool {
+		return id.less(s.ids[i])
+	})
+
+	return s.ids[i:], s.messages[i:]
+}
+
+func (s *memoryStreamData) group(name string) *memoryGroup {
+	for _, g := range s.groups {
+		if g.name == name {
+			return g
+		}
+	}
+
+	return nil
+}
+
+func (s *memoryStreamData) createGroup(name string, pos string) error {
+	if s.group(name) != nil {
+		return fmt.Errorf("BUSYGROUP %s", errStrConsGroupAlreadyExists)
+	}
+
+	id, err := s.resolve(pos)
+	if err != nil {
+		return err
+	}
+
+	s.groups = append(s.groups, &memoryGroup{
+		name:          name,
+		lastDelivered: id,
+		pending:       make(map[streamID]struct{}),
+	})
+
+	return nil
+}
+
+func (s *memoryStreamData) add(values map[string]any) string {
+	id := streamID{ms: uint64(time.Now().UnixMilli())}
+	if !s.lastID.less(id) {
+		id = streamID{ms: s.lastID.ms, seq: s.lastID.seq + 1}
+	}
+
+	s.ids = append(s.ids, id)
+	s.messages = append(s.messages, xredis.XMessage{ID: id.String(), Values: values})
+	s.lastID = id
+	s.notify()
+
+	return id.String()
+}
+
+// memoryStream implements Stream on top of a stream key of the in-memory cache, the key is looked up by each
+// operation as the stream may be deleted in the meantime.
+type memoryStream struct {
+	cache *memoryCache
+	name  string
+}
+
+func newMemoryStream(cache *memoryCache, streamName string) Stream {
+	return &memoryStream{
+		cache: cache,
+		name:  streamName,
+	}
+}
+
+func (s *memoryStream) Name() string {
+	return s.name
+}
+
+// data returns the stream data, nil if the stream doesn't exist. It must be called with the cache lock held.
+func (s *memoryStream) data() (*memoryStreamData, error) {
+	e, err := s.cache.getTyped(s.name, isStream, nil)
+	if err == ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return e.value.(*memoryStreamData), nil
+}
+
+// groupData returns the stream data and the group, an error like redis if one of them doesn't exist.
+func (s *memoryStream) groupData(group string) (*memoryStreamData, *memoryGroup, error) {
+	data, err := s.data()
+	if err != nil {
+		return nil, nil, err
+	}
+	if data == nil || data.group(group) == nil {
+		return nil, nil, fmt.Errorf(errStrNoGroup, s.name, group)
+	}
+
+	return data, data.group(group), nil
+}
+
+// wait blocks until the channel is closed, the timer fires or the context is done. It returns false on timeout.
+func wait(ctx context.Context, changed <-chan struct{}, timer *time.Timer) (bool, error) {
+	select {
+	case <-changed:
+		return true, nil
+	case <-timer.C:
+		return false, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+func (s *memoryStream) Add(_ context.Context, value *internal.StreamData) (string, error) {
+	data, err := encodeToStreamValue(value)
+	if err != nil {
+		return "", err
+	}
+
+	// the payload is stored as a string like redis returns it
+	data[payloadKey] = string(data[payloadKey].([]byte))
+
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	e, err := s.cache.getTyped(s.name, isStream, func() any { return newMemoryStreamData() })
+	if err != nil {
+		return "", err
+	}
+
+	return e.value.(*memoryStreamData).add(data), nil
+}
+
+func (s *memoryStream) AddSequenced(_ context.Context, value *internal.StreamData, guardTable string, guardKey string, seq int64) (string, bool, error) {
+	data, err := encodeToStreamValue(value)
+	if err != nil {
+		return "", false, err
+	}
+	data[payloadKey] = string(data[payloadKey].([]byte))
+
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	guard := encodeToCacheKey(guardTable, guardKey)
+	guarded, err := s.cache.getBytes(guard)
+	if err != nil && err != ErrKeyNotFound {
+		return "", false, err
+	}
+
+	var added int64
+	if err == nil {
+		if added, err = strconv.ParseInt(string(guarded), 10, 64); err != nil {
+			return "", false, ErrWrongType
+		}
+	}
+	if added != seq {
+		return "", false, nil
+	}
+
+	e, err := s.cache.getTyped(s.name, isStream, func() any { return newMemoryStreamData() })
+	if err != nil {
+		return "", false, err
+	}
+	id := e.value.(*memoryStreamData).add(data)
+	s.cache.put(guard, []byte(strconv.FormatInt(added+1, 10)), 0)
+
+	return id, true, nil
+}
+
+func (s *memoryStream) Read(ctx context.Context, pos string) (*StreamMessages, bool, error) {
+	timer := time.NewTimer(readBlockDuration)
+	defer timer.Stop()
+
+	s.cache.Lock()
+	data, err := s.data()
+	if err != nil {
+		s.cache.Unlock()
+		return nil, true, err
+	}
+
+	var from streamID
+	if data != nil {
+		from, err = data.resolve(pos)
+	} else if pos != ConsumerGroupDefaultCurrentPos {
+		from, err = parseStreamID(pos)
+	}
+	if err != nil {
+		s.cache.Unlock()
+		return nil, true, err
+	}
+
+	for {
+		// a missing stream is waited for until the timeout like a stream without new messages
+		var changed <-chan struct{}
+		if data != nil {
+			if _, messages := data.after(from); len(messages) > 0 {
+				s.cache.Unlock()
+				return s.messages(messages), true, nil
+			}
+			changed = data.changed
+		}
+		s.cache.Unlock()
+
+		woken, err := wait(ctx, changed, timer)
+		if err != nil {
+			return nil, true, err
+		}
+		if !woken {
+			return nil, false, nil
+		}
+
+		s.cache.Lock()
+		if data, err = s.data(); err != nil {
+			s.cache.Unlock()
+			return nil, true, err
+		}
+	}
+}
+
+func (s *memoryStream) ReadGroup(ctx context.Context, group string, pos ReadGroupPos) (*StreamMessages, bool, error) {
+	s.cache.Lock()
+	data, g, err := s.groupData(group)
+	if err != nil {
+		s.cache.Unlock()
+		return nil, true, err
+	}
+
+	if pos != ReadGroupPosCurrent {
+		// an explicit position returns the history of the pending messages
+		defer s.cache.Unlock()
+
+		from, err := parseStreamID(string(pos))
+		if err != nil {
+			return nil, true, err
+		}
+
+		ids, messages := data.after(from)
+		var pending []xredis.XMessage
+		for i, id := range ids {
+			if _, ok := g.pending[id]; ok {
+				pending = append(pending, messages[i])
+			}
+		}
+		if len(pending) == 0 {
+			return nil, true, nil
+		}
+
+		return s.messages(pending), true, nil
+	}
+
+	timer := time.NewTimer(BlockReadGroupDuration)
+	defer timer.Stop()
+
+	for {
+		g.consumers = 1
+		if ids, messages := data.after(g.lastDelivered); len(messages) > 0 {
+			for _, id := range ids {
+				g.pending[id] = struct{}{}
+			}
+			g.lastDelivered = ids[len(ids)-1]
+			s.cache.Unlock()
+
+			return s.messages(messages), true, nil
+		}
+		changed := data.changed
+		s.cache.Unlock()
+
+		woken, err := wait(ctx, changed, timer)
+		if err != nil {
+			return nil, true, err
+		}
+		if !woken {
+			return nil, false, nil
+		}
+
+		s.cache.Lock()
+		if data, g, err = s.groupData(group); err != nil {
+			s.cache.Unlock()
+			return nil, true, err
+		}
+	}
+}
+
+// messages copies the messages as the slice of the stream may be appended to once the lock is released.
+func (s *memoryStream) messages(messages []xredis.XMessage) *StreamMessages {
+	return &StreamMessages{
+		XStream: xredis.XStream{
+			Stream:   s.name,
+			Messages: append([]xredis.XMessage(nil), messages...),
+		},
+	}
+}
+
+func (s *memoryStream) CreateConsumerGroup(_ context.Context, group string, pos string) error {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	data, err := s.data()
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return fmt.Errorf(errStrKeyRequired)
+	}
+
+	return data.createGroup(group, pos)
+}
+
+func (s *memoryStream) RemoveConsumerGroup(_ context.Context, group string) error {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	data, err := s.data()
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return fmt.Errorf(errStrKeyRequired)
+	}
+
+	for i, g := range data.groups {
+		if g.name == group {
+			data.groups = append(data.groups[:i], data.groups[i+1:]...)
+			// wake up the readers of the group so that they notice it is gone
+			data.notify()
+			break
+		}
+	}
+
+	return nil
+}
+
+func groupInfo(g *memoryGroup) xredis.XInfoGroup {
+	return xredis.XInfoGroup{
+		Name:            g.name,
+		Consumers:       g.consumers,
+		Pending:         int64(len(g.pending)),
+		LastDeliveredID: g.lastDelivered.String(),
+	}
+}
+
+func (s *memoryStream) GetConsumerGroups(_ context.Context) ([]xredis.XInfoGroup, error) {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	data, err := s.data()
+	if data == nil || err != nil {
+		return nil, err
+	}
+
+	groups := make([]xredis.XInfoGroup, len(data.groups))
+	for i, g := range data.groups {
+		groups[i] = groupInfo(g)
+	}
+
+	return groups, nil
+}
+
+func (s *memoryStream) GetConsumerGroup(_ context.Context, group string) (*xredis.XInfoGroup, bool, error) {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	data, err := s.data()
+	if data == nil || err != nil {
+		return nil, false, err
+	}
+
+	g := data.group(group)
+	if g == nil {
+		return nil, false, nil
+	}
+
+	info := groupInfo(g)
+	return &info, true, nil
+}
+
+func (s *memoryStream) SetID(_ context.Context, group string, pos string) error {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	data, g, err := s.groupData(group)
+	if err != nil {
+		return err
+	}
+
+	id, err := data.resolve(pos)
+	if err != nil {
+		return err
+	}
+	g.lastDelivered = id
+
+	return nil
+}
+
+func (s *memoryStream) Ack(_ context.Context, group string, ids ...string) error {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	data, err := s.data()
+	if data == nil || err != nil {
+		return err
+	}
+
+	g := data.group(group)
+	if g == nil {
+		return nil
+	}
+	for _, id := range ids {
+		parsed, err := parseStreamID(id)
+		if err != nil {
+			return err
+		}
+		delete(g.pending, parsed)
+	}
+
+	return nil
+}
+
+func (s *memoryStream) Delete(_ context.Context) error {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	s.cache.delete(s.name)
+	return nil
+}
+
+// parseRangeID parses a bound of a range, an end bound without a sequence includes all the sequences of the
+// millisecond like redis does.
+func parseRangeID(pos string, end bool) (streamID, error) {
+	switch pos {
+	case "-":
+		return streamID{}, nil
+	case "+":
+		return streamID{ms: math.MaxUint64, seq: math.MaxUint64}, nil
+	}
+
+	id, err := parseStreamID(pos)
+	if err != nil {
+		return streamID{}, err
+	}
+	if end && !strings.Contains(pos, "-") {
+		id.seq = math.MaxUint64
+	}
+
+	return id, nil
+}
+
+func (s *memoryStream) Trim(_ context.Context, maxLen int64, minID string) (int64, error) {
+	return s.trim(maxLen, minID, 1)
+}
+
+// TrimApprox removes the messages by nodes of memoryStreamNodeSize messages like redis does with the default
+// stream-node-max-entries, a stream keeps its messages until a whole node is to be removed.
+func (s *memoryStream) TrimApprox(_ context.Context, maxLen int64, minID string) error {
+	if maxLen > 0 {
+		minID = ""
+	}
+
+	_, err := s.trim(maxLen, minID, memoryStreamNodeSize)
+	return err
+}
+
+// trim removes the oldest messages exceeding the limits, the number of messages removed is rounded down to a
+// multiple of unit.
+func (s *memoryStream) trim(maxLen int64, minID string, unit int) (int64, error) {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	data, err := s.data()
+	if data == nil || err != nil {
+		return 0, err
+	}
+
+	drop := 0
+	if maxLen > 0 && len(data.ids) > int(maxLen) {
+		drop = len(data.ids) - int(maxLen)
+	}
+	if len(minID) > 0 {
+		min, err := parseStreamID(minID)
+		if err != nil {
+			return 0, err
+		}
+
+		older := sort.Search(len(data.ids), func(i int) bool {
+			return !data.ids[i].less(min)
+		})
+		if older > drop {
+			drop = older
+		}
+	}
+	drop -= drop % unit
+
+	data.ids = data.ids[drop:]
+	data.messages = data.messages[drop:]
+
+	return int64(drop), nil
+}
+
+func (s *memoryStream) Range(_ context.Context, start string, end string, count int64, rev bool) (*StreamMessages, error) {
+	from, err := parseRangeID(start, false)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseRangeID(end, true)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	data, err := s.data()
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []xredis.XMessage
+	if data != nil {
+		for i := range data.ids {
+			idx := i
+			if rev {
+				idx = len(data.ids) - 1 - i
+			}
+			if data.ids[idx].less(from) || to.less(data.ids[idx]) {
+				continue
+			}
+
+			messages = append(messages, data.messages[idx])
+			if count > 0 && int64(len(messages)) == count {
+				break
+			}
+		}
+	}
+
+	return &StreamMessages{
+		XStream: xredis.XStream{
+			Stream:   s.name,
+			Messages: messages,
+		},
+	}, nil
+}
@@ -0,0 +1,259 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/server/config"
+)
+
+func TestMemoryCache(t *testing.T) {
+	testCache(t, newMemoryCache())
+
+	c := newMemoryCache()
+	ctx := context.TODO()
+	tableName := "cache_test"
+
+	t.Run("expiry", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		s1 := []byte(`{"a": "b"}`)
+		require.NoError(t, c.Set(ctx, tableName, "key1", internal.NewCacheData(s1), &SetOptions{PX: 10}))
+		require.NoError(t, c.Set(ctx, tableName, "key2", internal.NewCacheData(s1), nil))
+		time.Sleep(20 * time.Millisecond)
+
+		_, err := c.Get(ctx, tableName, "key1", nil)
+		require.Equal(t, ErrKeyNotFound, err)
+		keys, err := c.Keys(ctx, tableName, "*")
+		require.NoError(t, err)
+		require.Equal(t, []string{encodeToCacheKey(tableName, "key2")}, keys)
+
+		_, err = c.Get(ctx, tableName, "key2", &GetOptions{Expiry: 10 * time.Millisecond})
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+		exists, err := c.Exists(ctx, tableName, "key2")
+		require.NoError(t, err)
+		require.Equal(t, int64(0), exists)
+	})
+
+	t.Run("scan", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		for i := 0; i < 25; i++ {
+			require.NoError(t, c.Set(ctx, tableName, fmt.Sprintf("key%02d", i), internal.NewCacheData([]byte(`1`)), nil))
+		}
+
+		var (
+			all    []string
+			keys   []string
+			cursor uint64
+		)
+		for {
+			keys, cursor = c.Scan(ctx, tableName, cursor, 10, "key1*")
+			all = append(all, keys...)
+			if cursor == 0 {
+				break
+			}
+		}
+		require.Len(t, all, 10)
+		require.Equal(t, encodeToCacheKey(tableName, "key10"), all[0])
+	})
+
+	t.Run("wrong_type", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		_, err := c.LPush(ctx, tableName, "list", internal.NewCacheData([]byte(`1`)))
+		require.NoError(t, err)
+
+		_, err = c.Get(ctx, tableName, "list", nil)
+		require.Equal(t, ErrWrongType, err)
+		_, err = c.HSet(ctx, tableName, "list", map[string]*internal.CacheData{"f": internal.NewCacheData([]byte(`1`))})
+		require.Equal(t, ErrWrongType, err)
+		_, err = c.ZAdd(ctx, tableName, "list", ZMember{Member: "a", Score: 1})
+		require.Equal(t, ErrWrongType, err)
+
+		// set replaces a value of any type
+		require.NoError(t, c.Set(ctx, tableName, "list", internal.NewCacheData([]byte(`1`)), nil))
+		_, err = c.Get(ctx, tableName, "list", nil)
+		require.NoError(t, err)
+	})
+}
+
+func TestMemoryStream(t *testing.T) {
+	testStream(t, newMemoryCache())
+
+	c := newMemoryCache()
+	ctx := context.TODO()
+
+	add := func(t *testing.T, s Stream, data string) string {
+		id, err := s.Add(ctx, internal.NewStreamData(internal.JsonEncoding, nil, []byte(data)))
+		require.NoError(t, err)
+		return id
+	}
+
+	t.Run("pending_and_ack", func(t *testing.T) {
+		s, err := c.CreateOrGetStream(ctx, "test")
+		require.NoError(t, err)
+		defer func() { _ = s.Delete(ctx) }()
+
+		add(t, s, `1`)
+		require.NoError(t, s.CreateConsumerGroup(ctx, "watcher", ConsumerGroupDefaultCurrentPos))
+		id2 := add(t, s, `2`)
+		id3 := add(t, s, `3`)
+
+		messages, exists, err := s.ReadGroup(ctx, "watcher", ReadGroupPosCurrent)
+		require.NoError(t, err)
+		require.True(t, exists)
+		require.Len(t, messages.Messages, 2)
+		require.Equal(t, id2, messages.Messages[0].ID)
+		data, err := messages.Decode(messages.Messages[1])
+		require.NoError(t, err)
+		require.Equal(t, []byte(`3`), data.RawData)
+		require.Equal(t, id3, data.Id)
+
+		group, exists, err := s.GetConsumerGroup(ctx, "watcher")
+		require.NoError(t, err)
+		require.True(t, exists)
+		require.Equal(t, int64(2), group.Pending)
+		require.Equal(t, id3, group.LastDeliveredID)
+
+		// an explicit position returns the pending messages
+		require.NoError(t, s.Ack(ctx, "watcher", id2))
+		messages, _, err = s.ReadGroup(ctx, "watcher", ReadGroupPosStart)
+		require.NoError(t, err)
+		require.Len(t, messages.Messages, 1)
+		require.Equal(t, id3, messages.Messages[0].ID)
+
+		require.NoError(t, s.Ack(ctx, "watcher", id3))
+		group, _, err = s.GetConsumerGroup(ctx, "watcher")
+		require.NoError(t, err)
+		require.Equal(t, int64(0), group.Pending)
+
+		// moving the group back delivers the messages again
+		require.NoError(t, s.SetID(ctx, "watcher", "0"))
+		messages, _, err = s.ReadGroup(ctx, "watcher", ReadGroupPosCurrent)
+		require.NoError(t, err)
+		require.Len(t, messages.Messages, 3)
+
+		require.NoError(t, s.RemoveConsumerGroup(ctx, "watcher"))
+		_, _, err = s.ReadGroup(ctx, "watcher", ReadGroupPosCurrent)
+		require.Error(t, err)
+		require.Error(t, s.SetID(ctx, "watcher", "0"))
+	})
+
+	t.Run("blocking_reads", func(t *testing.T) {
+		s, err := c.CreateOrGetStream(ctx, "test")
+		require.NoError(t, err)
+		defer func() { _ = s.Delete(ctx) }()
+
+		require.NoError(t, s.CreateConsumerGroup(ctx, "watcher", ConsumerGroupDefaultCurrentPos))
+
+		read := make(chan *StreamMessages, 2)
+		go func() {
+			messages, _, _ := s.Read(ctx, ConsumerGroupDefaultCurrentPos)
+			read <- messages
+		}()
+		go func() {
+			messages, _, _ := s.ReadGroup(ctx, "watcher", ReadGroupPosCurrent)
+			read <- messages
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		id := add(t, s, `1`)
+
+		for i := 0; i < 2; i++ {
+			select {
+			case messages := <-read:
+				require.NotNil(t, messages)
+				require.Equal(t, id, messages.Messages[0].ID)
+			case <-time.After(time.Second):
+				require.Fail(t, "blocked read is not woken up")
+			}
+		}
+	})
+
+	t.Run("read_timeout", func(t *testing.T) {
+		s, err := c.CreateOrGetStream(ctx, "test")
+		require.NoError(t, err)
+		defer func() { _ = s.Delete(ctx) }()
+
+		defer func(d time.Duration) { BlockReadGroupDuration = d }(BlockReadGroupDuration)
+		BlockReadGroupDuration = 10 * time.Millisecond
+
+		messages, exists, err := s.ReadGroup(ctx, DefaultGroup, ReadGroupPosCurrent)
+		require.NoError(t, err)
+		require.False(t, exists)
+		require.Nil(t, messages)
+
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, _, err = s.Read(cctx, ConsumerGroupDefaultCurrentPos)
+		require.Equal(t, context.Canceled, err)
+	})
+
+	t.Run("create_get_delete", func(t *testing.T) {
+		_, err := c.GetStream(ctx, "missing")
+		require.Equal(t, ErrStreamNotFound, err)
+
+		s, err := c.CreateStream(ctx, "test")
+		require.NoError(t, err)
+		_, err = c.CreateStream(ctx, "test")
+		require.Equal(t, ErrStreamAlreadyExists, err)
+
+		streams, err := c.ListStreams(ctx, "te*")
+		require.NoError(t, err)
+		require.Equal(t, []string{"test"}, streams)
+
+		require.NoError(t, s.Delete(ctx))
+		_, err = c.GetStream(ctx, "test")
+		require.Equal(t, ErrStreamNotFound, err)
+	})
+}
+
+func TestNewCacheMemoryBackend(t *testing.T) {
+	cfg := &config.CacheConfig{Backend: config.CacheBackendMemory}
+	require.Same(t, NewCache(cfg), NewCache(cfg))
+}
+
+func TestMatchPattern(t *testing.T) {
+	for _, c := range []struct {
+		pattern string
+		str     string
+		match   bool
+	}{
+		{"*", "", true},
+		{"*", "a:b/c", true},
+		{"t:*", "t:key", true},
+		{"t:*", "u:key", false},
+		{"t:k?y", "t:key", true},
+		{"t:k?y", "t:ky", false},
+		{"t:k[ae]y", "t:key", true},
+		{"t:k[^ae]y", "t:key", false},
+		{"t:k[a-f]y", "t:key", true},
+		{"t:k[x-z]y", "t:key", false},
+		{`t:\*`, "t:*", true},
+		{`t:\*`, "t:a", false},
+		{"t:*e*", "t:key", true},
+		{"t:*x*", "t:key", false},
+	} {
+		require.Equal(t, c.match, matchPattern(c.pattern, c.str), "%s %s", c.pattern, c.str)
+	}
+}
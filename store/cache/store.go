@@ -16,9 +16,11 @@ package cache
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	xredis "github.com/go-redis/redis/v8"
+	"github.com/rs/zerolog/log"
 	"github.com/tigrisdata/tigris/internal"
 	"github.com/tigrisdata/tigris/server/config"
 )
@@ -27,6 +29,10 @@ type Stream interface {
 	Name() string
 	// Add is to add streamData to a stream
 	Add(ctx context.Context, value *internal.StreamData) (string, error)
+	// AddSequenced adds the value only if seq is the number of values already added under the guard key of the
+	// guard table and advances it, atomically. It returns false if the value was added before, which makes adding a
+	// sequence of values idempotent for a caller retrying it from the start.
+	AddSequenced(ctx context.Context, value *internal.StreamData, guardTable string, guardKey string, seq int64) (string, bool, error)
 	// Read data from the stream, returns data ID greater than position. To read from current use "$"
 	Read(ctx context.Context, pos string) (*StreamMessages, bool, error)
 	// ReadGroup is similar to Read but with support for reading from a group. We don't have multiple consumers in a
@@ -48,6 +54,16 @@ type Stream interface {
 	Ack(ctx context.Context, group string, ids ...string) error
 	// Delete is to delete this stream. it removes all the associated consumer group as well.
 	Delete(ctx context.Context) error
+	// Trim removes the oldest messages so that the stream keeps at most maxLen messages and no message with an ID
+	// lower than minID, a zero maxLen or an empty minID disables the respective limit. It returns the number of
+	// messages removed.
+	Trim(ctx context.Context, maxLen int64, minID string) (int64, error)
+	// TrimApprox is a cheaper Trim removing the oldest messages by whole nodes of the stream, the stream may keep a few
+	// more messages than maxLen or older than minID. Only one limit is applied, maxLen if it is set and minID otherwise.
+	TrimApprox(ctx context.Context, maxLen int64, minID string) error
+	// Range returns at most count messages with an ID between start and end, both inclusive, "-" and "+" being the
+	// lowest and highest IDs. The messages are returned from the end if rev is set. A zero count returns all of them.
+	Range(ctx context.Context, start string, end string, count int64, rev bool) (*StreamMessages, error)
 }
 
 type SetOptions struct {
@@ -67,10 +83,19 @@ type GetOptions struct {
 	GetDelete bool
 }
 
+// Stats are the number of keys of a cache and the memory they use.
+type Stats struct {
+	Keys        int64
+	MemoryBytes int64
+}
+
+// NoExpiry is the TTL of a key without an expiry.
+const NoExpiry time.Duration = -1
+
 type Cache interface {
 	Set(ctx context.Context, tableName string, key string, value *internal.CacheData, options *SetOptions) error
 	// GetSet is to get the previous value and set the new value
-	GetSet(ctx context.Context, tableName string, key string, value *internal.CacheData) (*internal.CacheData, error)
+	GetSet(ctx context.Context, tableName string, key string, value *internal.CacheData, ttl time.Duration) (*internal.CacheData, error)
 	// Get the value of key
 	Get(ctx context.Context, tableName string, key string, options *GetOptions) (*internal.CacheData, error)
 	// Delete deletes one or more keys
@@ -79,6 +104,53 @@ type Cache interface {
 	Exists(ctx context.Context, tableName string, key ...string) (int64, error)
 	Keys(ctx context.Context, tableName string, pattern string) ([]string, error)
 	Scan(ctx context.Context, tableName string, cursor uint64, count int64, pattern string) ([]string, uint64)
+	// MGet returns the values of the keys in the same order, the value is nil if the key doesn't exist
+	MGet(ctx context.Context, tableName string, keys ...string) ([]*internal.CacheData, error)
+	// MSet sets all the values atomically, only the NX and the expiry options are supported
+	MSet(ctx context.Context, tableName string, values map[string]*internal.CacheData, options *SetOptions) error
+	// Expire sets the expiry of an existing key
+	Expire(ctx context.Context, tableName string, key string, ttl time.Duration) error
+	// Persist removes the expiry of an existing key
+	Persist(ctx context.Context, tableName string, key string) error
+	// TTL returns the remaining time to live of the key, NoExpiry if the key doesn't expire
+	TTL(ctx context.Context, tableName string, key string) (time.Duration, error)
+	// Stats returns the number of keys of the cache and the memory they use, it scans all the keys of the cache
+	Stats(ctx context.Context, tableName string) (*Stats, error)
+	// CompareAndDelete deletes the key only if it still holds the value, which must be the value that was set. It
+	// returns true if the key was deleted.
+	CompareAndDelete(ctx context.Context, tableName string, key string, value *internal.CacheData) (bool, error)
+	// CompareAndExpire sets the expiry of the key only if it still holds the value, which must be the value that was
+	// set. It returns true if the expiry was set.
+	CompareAndExpire(ctx context.Context, tableName string, key string, value *internal.CacheData, ttl time.Duration) (bool, error)
+	// ExpireIfPersistent sets the expiry of an existing key only if it doesn't have one yet
+	ExpireIfPersistent(ctx context.Context, tableName string, key string, ttl time.Duration) error
+
+	// IncrBy atomically adds the delta to the counter and returns the new value, a missing counter starts at zero
+	IncrBy(ctx context.Context, tableName string, key string, delta int64) (int64, error)
+	// HSet sets the fields of the hash and returns the number of fields added
+	HSet(ctx context.Context, tableName string, key string, fields map[string]*internal.CacheData) (int64, error)
+	// HGet returns the value of the field of the hash
+	HGet(ctx context.Context, tableName string, key string, field string) (*internal.CacheData, error)
+	// HGetAll returns all the fields of the hash
+	HGetAll(ctx context.Context, tableName string, key string) (map[string]*internal.CacheData, error)
+	// HDel removes the fields from the hash and returns the number of fields removed
+	HDel(ctx context.Context, tableName string, key string, fields ...string) (int64, error)
+	// ZAdd adds or updates the members of the sorted set and returns the number of members added
+	ZAdd(ctx context.Context, tableName string, key string, members ...ZMember) (int64, error)
+	// ZRange returns the members of the sorted set between the start and stop ranks, both inclusive, ordered by
+	// score or by descending score if rev is set
+	ZRange(ctx context.Context, tableName string, key string, start int64, stop int64, rev bool) ([]ZMember, error)
+	// ZRank returns the rank of the member in the sorted set ordered by score or by descending score if rev is set
+	ZRank(ctx context.Context, tableName string, key string, member string, rev bool) (int64, error)
+	// ZRangeByScore returns at most count members of the sorted set with a score between min and max, both inclusive,
+	// ordered by score. A zero count returns all of them.
+	ZRangeByScore(ctx context.Context, tableName string, key string, min float64, max float64, count int64) ([]ZMember, error)
+	// ZRem removes the members from the sorted set and returns the number of members removed
+	ZRem(ctx context.Context, tableName string, key string, members ...string) (int64, error)
+	// LPush prepends the values to the list and returns the length of the list
+	LPush(ctx context.Context, tableName string, key string, values ...*internal.CacheData) (int64, error)
+	// RPop removes and returns the last value of the list
+	RPop(ctx context.Context, tableName string, key string) (*internal.CacheData, error)
 
 	// CreateStream creates and returns a stream object, throws an error if stream already exists
 	CreateStream(ctx context.Context, streamName string) (Stream, error)
@@ -92,6 +164,25 @@ type Cache interface {
 	DeleteStream(ctx context.Context, streamName string) error
 }
 
+var (
+	memoryCacheOnce   sync.Once
+	sharedMemoryCache *memoryCache
+)
+
+// NewCache returns the cache of the backend selected in the config. The in-memory cache is shared by all the callers
+// in the process, so that the services see the same keys and streams like they would with a Redis server.
 func NewCache(cfg *config.CacheConfig) Cache {
-	return newCache(cfg)
+	switch cfg.Backend {
+	case config.CacheBackendMemory:
+		memoryCacheOnce.Do(func() {
+			sharedMemoryCache = newMemoryCache()
+			log.Info().Msg("initialized in-memory cache")
+		})
+		return sharedMemoryCache
+	case config.CacheBackendRedis, "":
+		return newCache(cfg)
+	default:
+		log.Fatal().Msgf("unknown cache backend '%s'", cfg.Backend)
+		return nil
+	}
 }
@@ -87,6 +87,35 @@ func (s *stream) Add(ctx context.Context, value *internal.StreamData) (string, e
 	return cmd.Result()
 }
 
+// addSequencedScript adds the message to the stream if the guard counter is at the expected sequence and advances it.
+var addSequencedScript = xredis.NewScript(`
+local added = tonumber(redis.call("GET", KEYS[2]) or "0")
+if added ~= tonumber(ARGV[1]) then
+	return false
+end
+local id = redis.call("XADD", KEYS[1], "*", ARGV[2], ARGV[3])
+redis.call("SET", KEYS[2], added + 1)
+return id
+`)
+
+func (s *stream) AddSequenced(ctx context.Context, value *internal.StreamData, guardTable string, guardKey string, seq int64) (string, bool, error) {
+	enc, err := internal.EncodeStreamData(value)
+	if err != nil {
+		return "", false, err
+	}
+
+	id, err := addSequencedScript.Run(ctx, s.cache.Client, []string{s.name, encodeToCacheKey(guardTable, guardKey)},
+		seq, payloadKey, enc).Text()
+	if err == xredis.Nil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	return id, true, nil
+}
+
 func (s *stream) Read(ctx context.Context, pos string) (*StreamMessages, bool, error) {
 	resp := s.cache.Client.XRead(ctx, &xredis.XReadArgs{
 		Streams: []string{s.name, pos},
@@ -188,6 +217,63 @@ func (s *stream) Delete(ctx context.Context) error {
 	return err
 }
 
+func (s *stream) Trim(ctx context.Context, maxLen int64, minID string) (int64, error) {
+	var trimmed int64
+	if maxLen > 0 {
+		n, err := s.cache.Client.XTrimMaxLen(ctx, s.name, maxLen).Result()
+		if err != nil {
+			return trimmed, err
+		}
+		trimmed += n
+	}
+	if len(minID) > 0 {
+		n, err := s.cache.Client.XTrimMinID(ctx, s.name, minID).Result()
+		if err != nil {
+			return trimmed, err
+		}
+		trimmed += n
+	}
+
+	return trimmed, nil
+}
+
+func (s *stream) TrimApprox(ctx context.Context, maxLen int64, minID string) error {
+	switch {
+	case maxLen > 0:
+		return s.cache.Client.XTrimMaxLenApprox(ctx, s.name, maxLen, 0).Err()
+	case len(minID) > 0:
+		return s.cache.Client.XTrimMinIDApprox(ctx, s.name, minID, 0).Err()
+	default:
+		return nil
+	}
+}
+
+func (s *stream) Range(ctx context.Context, start string, end string, count int64, rev bool) (*StreamMessages, error) {
+	var cmd *xredis.XMessageSliceCmd
+	switch {
+	case rev && count > 0:
+		cmd = s.cache.Client.XRevRangeN(ctx, s.name, end, start, count)
+	case rev:
+		cmd = s.cache.Client.XRevRange(ctx, s.name, end, start)
+	case count > 0:
+		cmd = s.cache.Client.XRangeN(ctx, s.name, start, end, count)
+	default:
+		cmd = s.cache.Client.XRange(ctx, s.name, start, end)
+	}
+
+	messages, err := cmd.Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return &StreamMessages{
+		XStream: xredis.XStream{
+			Stream:   s.name,
+			Messages: messages,
+		},
+	}, nil
+}
+
 func encodeToStreamValue(event *internal.StreamData) (map[string]any, error) {
 	enc, err := internal.EncodeStreamData(event)
 	if err != nil {
@@ -29,8 +29,12 @@ import (
 )
 
 func TestStream(t *testing.T) {
+	testStream(t, NewCache(config.GetTestCacheConfig()))
+}
+
+// testStream runs the tests shared by all the implementations of Stream.
+func testStream(t *testing.T, r Cache) {
 	ctx := context.TODO()
-	r := NewCache(config.GetTestCacheConfig())
 
 	t.Run("add_read", func(t *testing.T) {
 		stream, err := r.CreateOrGetStream(context.TODO(), "test")
@@ -70,6 +74,83 @@ func TestStream(t *testing.T) {
 		require.Equal(t, "first", groups[1].Name)
 		require.Equal(t, "second", groups[2].Name)
 	})
+	t.Run("range_trim", func(t *testing.T) {
+		stream, err := r.CreateOrGetStream(context.TODO(), "test")
+		require.NoError(t, err)
+		defer func() {
+			_ = stream.Delete(ctx)
+		}()
+
+		var ids []string
+		for i := 0; i < 5; i++ {
+			id, err := stream.Add(ctx, internal.NewStreamData(internal.JsonEncoding, nil, []byte(fmt.Sprintf(`{"i": %d}`, i))))
+			require.NoError(t, err)
+			ids = append(ids, id)
+		}
+
+		messages, err := stream.Range(ctx, "-", "+", 0, false)
+		require.NoError(t, err)
+		require.Len(t, messages.XStream.Messages, 5)
+		require.Equal(t, ids[0], messages.XStream.Messages[0].ID)
+
+		messages, err = stream.Range(ctx, ids[1], ids[3], 0, false)
+		require.NoError(t, err)
+		require.Len(t, messages.XStream.Messages, 3)
+		require.Equal(t, ids[1], messages.XStream.Messages[0].ID)
+		require.Equal(t, ids[3], messages.XStream.Messages[2].ID)
+
+		messages, err = stream.Range(ctx, "-", "+", 2, true)
+		require.NoError(t, err)
+		require.Len(t, messages.XStream.Messages, 2)
+		require.Equal(t, ids[4], messages.XStream.Messages[0].ID)
+		require.Equal(t, ids[3], messages.XStream.Messages[1].ID)
+
+		trimmed, err := stream.Trim(ctx, 3, "")
+		require.NoError(t, err)
+		require.Equal(t, int64(2), trimmed)
+
+		trimmed, err = stream.Trim(ctx, 0, ids[3])
+		require.NoError(t, err)
+		require.Equal(t, int64(1), trimmed)
+
+		messages, err = stream.Range(ctx, "-", "+", 0, false)
+		require.NoError(t, err)
+		require.Len(t, messages.XStream.Messages, 2)
+		require.Equal(t, ids[3], messages.XStream.Messages[0].ID)
+
+		// a small stream is a single node, the approximate trim keeps it
+		require.NoError(t, stream.TrimApprox(ctx, 1, ""))
+		messages, err = stream.Range(ctx, "-", "+", 0, false)
+		require.NoError(t, err)
+		require.Len(t, messages.XStream.Messages, 2)
+	})
+	t.Run("add_sequenced", func(t *testing.T) {
+		stream, err := r.CreateOrGetStream(context.TODO(), "test")
+		require.NoError(t, err)
+		defer func() {
+			_ = stream.Delete(ctx)
+			_, _ = r.Delete(ctx, "test_guard", "entry")
+		}()
+
+		add := func(seq int64) bool {
+			_, added, err := stream.AddSequenced(ctx, internal.NewStreamData(internal.JsonEncoding, nil,
+				[]byte(fmt.Sprintf(`{"i": %d}`, seq))), "test_guard", "entry", seq)
+			require.NoError(t, err)
+			return added
+		}
+
+		require.True(t, add(0))
+		require.True(t, add(1))
+		// retrying the sequence from the start adds only the values not added yet
+		require.False(t, add(0))
+		require.False(t, add(1))
+		require.True(t, add(2))
+		require.False(t, add(4))
+
+		messages, err := stream.Range(ctx, "-", "+", 0, false)
+		require.NoError(t, err)
+		require.Len(t, messages.XStream.Messages, 3)
+	})
 }
 
 func TestBenchmarkingStreams(t *testing.T) {
@@ -0,0 +1,297 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	xredis "github.com/go-redis/redis/v8"
+	"github.com/tigrisdata/tigris/internal"
+)
+
+const (
+	errStrWrongType  = "WRONGTYPE"
+	errStrNotInteger = "ERR value is not an integer"
+)
+
+// ZMember is a member of a sorted set along with its score.
+type ZMember struct {
+	Member string
+	Score  float64
+}
+
+// convertRedisErr converts the errors returned when a key holds a value of a different type than the one expected
+// by the operation.
+func convertRedisErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	if strings.HasPrefix(err.Error(), errStrWrongType) || strings.HasPrefix(err.Error(), errStrNotInteger) {
+		return ErrWrongType
+	}
+
+	return err
+}
+
+// decodeCacheValue decodes the value of a key. Counters are stored as plain integers so that they can be incremented
+// atomically, they are returned as is as they are valid JSON numbers.
+func decodeCacheValue(value []byte) (*internal.CacheData, error) {
+	if _, err := strconv.ParseInt(string(value), 10, 64); err == nil {
+		return &internal.CacheData{RawData: value}, nil
+	}
+
+	return internal.DecodeCacheData(value)
+}
+
+func (c *cache) IncrBy(ctx context.Context, tableName string, key string, delta int64) (int64, error) {
+	value, err := c.Client.IncrBy(ctx, encodeToCacheKey(tableName, key), delta).Result()
+	return value, convertRedisErr(err)
+}
+
+func (c *cache) HSet(ctx context.Context, tableName string, key string, fields map[string]*internal.CacheData) (int64, error) {
+	if len(fields) == 0 {
+		return 0, ErrEmptyKey
+	}
+
+	values := make([]any, 0, 2*len(fields))
+	for f, v := range fields {
+		enc, err := internal.EncodeCacheData(v)
+		if err != nil {
+			return 0, err
+		}
+		values = append(values, f, enc)
+	}
+
+	added, err := c.Client.HSet(ctx, encodeToCacheKey(tableName, key), values...).Result()
+	return added, convertRedisErr(err)
+}
+
+func (c *cache) HGet(ctx context.Context, tableName string, key string, field string) (*internal.CacheData, error) {
+	value, err := c.Client.HGet(ctx, encodeToCacheKey(tableName, key), field).Bytes()
+	if err == xredis.Nil {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, convertRedisErr(err)
+	}
+
+	return internal.DecodeCacheData(value)
+}
+
+func (c *cache) HGetAll(ctx context.Context, tableName string, key string) (map[string]*internal.CacheData, error) {
+	values, err := c.Client.HGetAll(ctx, encodeToCacheKey(tableName, key)).Result()
+	if err != nil {
+		return nil, convertRedisErr(err)
+	}
+	if len(values) == 0 {
+		return nil, ErrKeyNotFound
+	}
+
+	fields := make(map[string]*internal.CacheData, len(values))
+	for f, v := range values {
+		if fields[f], err = internal.DecodeCacheData([]byte(v)); err != nil {
+			return nil, err
+		}
+	}
+
+	return fields, nil
+}
+
+func (c *cache) HDel(ctx context.Context, tableName string, key string, fields ...string) (int64, error) {
+	if len(fields) == 0 {
+		return 0, ErrEmptyKey
+	}
+
+	removed, err := c.Client.HDel(ctx, encodeToCacheKey(tableName, key), fields...).Result()
+	return removed, convertRedisErr(err)
+}
+
+func (c *cache) ZAdd(ctx context.Context, tableName string, key string, members ...ZMember) (int64, error) {
+	if len(members) == 0 {
+		return 0, ErrEmptyKey
+	}
+
+	zs := make([]*xredis.Z, len(members))
+	for i, m := range members {
+		zs[i] = &xredis.Z{Score: m.Score, Member: m.Member}
+	}
+
+	added, err := c.Client.ZAdd(ctx, encodeToCacheKey(tableName, key), zs...).Result()
+	return added, convertRedisErr(err)
+}
+
+func (c *cache) ZRange(ctx context.Context, tableName string, key string, start int64, stop int64, rev bool) ([]ZMember, error) {
+	cacheKey := encodeToCacheKey(tableName, key)
+
+	var (
+		zs  []xredis.Z
+		err error
+	)
+	if rev {
+		zs, err = c.Client.ZRevRangeWithScores(ctx, cacheKey, start, stop).Result()
+	} else {
+		zs, err = c.Client.ZRangeWithScores(ctx, cacheKey, start, stop).Result()
+	}
+	if err != nil {
+		return nil, convertRedisErr(err)
+	}
+
+	members := make([]ZMember, len(zs))
+	for i, z := range zs {
+		members[i] = ZMember{Member: z.Member.(string), Score: z.Score}
+	}
+
+	return members, nil
+}
+
+func (c *cache) ZRank(ctx context.Context, tableName string, key string, member string, rev bool) (int64, error) {
+	cacheKey := encodeToCacheKey(tableName, key)
+
+	var (
+		rank int64
+		err  error
+	)
+	if rev {
+		rank, err = c.Client.ZRevRank(ctx, cacheKey, member).Result()
+	} else {
+		rank, err = c.Client.ZRank(ctx, cacheKey, member).Result()
+	}
+	if err == xredis.Nil {
+		return 0, ErrKeyNotFound
+	}
+
+	return rank, convertRedisErr(err)
+}
+
+func (c *cache) ZRangeByScore(ctx context.Context, tableName string, key string, min float64, max float64, count int64) ([]ZMember, error) {
+	zs, err := c.Client.ZRangeByScoreWithScores(ctx, encodeToCacheKey(tableName, key), &xredis.ZRangeBy{
+		Min:   strconv.FormatFloat(min, 'f', -1, 64),
+		Max:   strconv.FormatFloat(max, 'f', -1, 64),
+		Count: count,
+	}).Result()
+	if err != nil {
+		return nil, convertRedisErr(err)
+	}
+
+	members := make([]ZMember, len(zs))
+	for i, z := range zs {
+		members[i] = ZMember{Member: z.Member.(string), Score: z.Score}
+	}
+
+	return members, nil
+}
+
+func (c *cache) ZRem(ctx context.Context, tableName string, key string, members ...string) (int64, error) {
+	if len(members) == 0 {
+		return 0, ErrEmptyKey
+	}
+
+	args := make([]any, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+
+	removed, err := c.Client.ZRem(ctx, encodeToCacheKey(tableName, key), args...).Result()
+	return removed, convertRedisErr(err)
+}
+
+func (c *cache) LPush(ctx context.Context, tableName string, key string, values ...*internal.CacheData) (int64, error) {
+	if len(values) == 0 {
+		return 0, ErrEmptyKey
+	}
+
+	encoded := make([]any, len(values))
+	for i, v := range values {
+		enc, err := internal.EncodeCacheData(v)
+		if err != nil {
+			return 0, err
+		}
+		encoded[i] = enc
+	}
+
+	length, err := c.Client.LPush(ctx, encodeToCacheKey(tableName, key), encoded...).Result()
+	return length, convertRedisErr(err)
+}
+
+func (c *cache) RPop(ctx context.Context, tableName string, key string) (*internal.CacheData, error) {
+	value, err := c.Client.RPop(ctx, encodeToCacheKey(tableName, key)).Bytes()
+	if err == xredis.Nil {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, convertRedisErr(err)
+	}
+
+	return internal.DecodeCacheData(value)
+}
+
+// expireIfPersistentScript sets the expiry of a key only if it exists and doesn't have one.
+var expireIfPersistentScript = xredis.NewScript(`
+if redis.call("PTTL", KEYS[1]) == -1 then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return 0
+`)
+
+func (c *cache) ExpireIfPersistent(ctx context.Context, tableName string, key string, ttl time.Duration) error {
+	return expireIfPersistentScript.Run(ctx, c.Client, []string{encodeToCacheKey(tableName, key)}, ttl.Milliseconds()).Err()
+}
+
+// compareAndDeleteScript deletes a key only if it holds the value.
+var compareAndDeleteScript = xredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+func (c *cache) CompareAndDelete(ctx context.Context, tableName string, key string, value *internal.CacheData) (bool, error) {
+	enc, err := internal.EncodeCacheData(value)
+	if err != nil {
+		return false, err
+	}
+
+	deleted, err := compareAndDeleteScript.Run(ctx, c.Client, []string{encodeToCacheKey(tableName, key)}, enc).Int()
+	if err != nil {
+		return false, convertRedisErr(err)
+	}
+
+	return deleted == 1, nil
+}
+
+// compareAndExpireScript sets the expiry of a key in milliseconds only if it holds the value.
+var compareAndExpireScript = xredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+func (c *cache) CompareAndExpire(ctx context.Context, tableName string, key string, value *internal.CacheData, ttl time.Duration) (bool, error) {
+	enc, err := internal.EncodeCacheData(value)
+	if err != nil {
+		return false, err
+	}
+
+	set, err := compareAndExpireScript.Run(ctx, c.Client, []string{encodeToCacheKey(tableName, key)}, enc, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, convertRedisErr(err)
+	}
+
+	return set == 1, nil
+}
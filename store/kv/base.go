@@ -52,6 +52,9 @@ type baseTx interface {
 	Commit(context.Context) error
 	Rollback(context.Context) error
 	IsRetriable() bool
+	GetReadVersion(ctx context.Context) (int64, error)
+	SetReadVersion(ctx context.Context, version int64) error
+	GetCommittedVersion(ctx context.Context) (int64, error)
 }
 
 type baseKVStore interface {
@@ -33,6 +33,7 @@ const (
 	ErrCodeValueSizeExceeded       StoreErrCode = 0x06
 	ErrCodeTransactionSizeExceeded StoreErrCode = 0x07
 	ErrCodeNotFound                StoreErrCode = 0x08
+	ErrCodeInvalidReadVersion      StoreErrCode = 0x09
 )
 
 var (
@@ -48,6 +49,7 @@ var (
 	ErrValueSizeExceeded       = NewStoreError(2103, ErrCodeValueSizeExceeded, "document exceeds limit")
 	ErrTransactionSizeExceeded = NewStoreError(2101, ErrCodeTransactionSizeExceeded, "transaction exceeds limit")
 	ErrNotFound                = NewStoreError(0, ErrCodeNotFound, "not found")
+	ErrInvalidReadVersion      = NewStoreError(0, ErrCodeInvalidReadVersion, "invalid read version")
 )
 
 type StoreError struct {
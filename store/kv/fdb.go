@@ -501,6 +501,45 @@ func (t *ftx) Rollback(_ context.Context) error {
 	return nil
 }
 
+// GetReadVersion returns the read version of the transaction. The read version is acquired lazily by FDB, so calling
+// this method before any read fixes the snapshot the transaction is going to observe.
+func (t *ftx) GetReadVersion(_ context.Context) (int64, error) {
+	version, err := t.tx.GetReadVersion().Get()
+	if err != nil {
+		return 0, convertFDBToStoreErr(err)
+	}
+
+	log.Trace().Int64("version", version).Msg("tx GetReadVersion")
+
+	return version, nil
+}
+
+// SetReadVersion sets the read version of the transaction. Reads performed by the transaction observe the database
+// as of this version. FDB only keeps five seconds of MVCC history, an older version fails the reads with
+// ErrTransactionMaxDurationReached.
+func (t *ftx) SetReadVersion(_ context.Context, version int64) error {
+	if version <= 0 {
+		return ErrInvalidReadVersion
+	}
+
+	t.tx.SetReadVersion(version)
+
+	log.Trace().Int64("version", version).Msg("tx SetReadVersion")
+
+	return nil
+}
+
+// GetCommittedVersion returns the version of the database that the transaction committed. A read-only transaction
+// doesn't have a committed version and returns -1.
+func (t *ftx) GetCommittedVersion(_ context.Context) (int64, error) {
+	version, err := t.tx.GetCommittedVersion()
+	if err != nil {
+		return 0, convertFDBToStoreErr(err)
+	}
+
+	return version, nil
+}
+
 // IsRetriable returns true if transaction can be retried after error.
 func (t *ftx) IsRetriable() bool {
 	if t.err == nil {
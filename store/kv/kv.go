@@ -70,6 +70,14 @@ type Tx interface {
 	Rollback(context.Context) error
 	IsRetriable() bool
 	RangeSize(ctx context.Context, table []byte, lkey Key, rkey Key) (int64, error)
+	// GetReadVersion returns the version at which the reads of this transaction are performed.
+	GetReadVersion(ctx context.Context) (int64, error)
+	// SetReadVersion pins the reads of this transaction to an explicit version. It must be called before the first
+	// read and the version must still be inside the MVCC window of the storage.
+	SetReadVersion(ctx context.Context, version int64) error
+	// GetCommittedVersion returns the version at which the transaction is committed, it is only available after a
+	// successful commit.
+	GetCommittedVersion(ctx context.Context) (int64, error)
 }
 
 type TxStore interface {
@@ -236,6 +236,22 @@ func (m *TxImplWithMetrics) IsRetriable() bool {
 	return m.tx.IsRetriable()
 }
 
+func (m *TxImplWithMetrics) GetReadVersion(ctx context.Context) (version int64, err error) {
+	m.measure(ctx, "GetReadVersion", func() error {
+		version, err = m.tx.GetReadVersion(ctx)
+		return err
+	})
+	return
+}
+
+func (m *TxImplWithMetrics) SetReadVersion(ctx context.Context, version int64) error {
+	return m.tx.SetReadVersion(ctx, version)
+}
+
+func (m *TxImplWithMetrics) GetCommittedVersion(ctx context.Context) (int64, error) {
+	return m.tx.GetCommittedVersion(ctx)
+}
+
 func (m *TxImplWithMetrics) Insert(ctx context.Context, table []byte, key Key, data *internal.TableData) (err error) {
 	m.measure(ctx, "Insert", func() error {
 		err = m.tx.Insert(ctx, table, key, data)
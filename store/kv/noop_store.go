@@ -38,6 +38,10 @@ func (*NoopTx) Commit(context.Context) error   { return nil }
 func (*NoopTx) Rollback(context.Context) error { return nil }
 func (*NoopTx) IsRetriable() bool              { return false }
 
+func (*NoopTx) GetReadVersion(context.Context) (int64, error)      { return 0, nil }
+func (*NoopTx) SetReadVersion(context.Context, int64) error        { return nil }
+func (*NoopTx) GetCommittedVersion(context.Context) (int64, error) { return 0, nil }
+
 // NoopKVStore is a noop store, useful if we need to profile/debug only compute and not with the storage. This can be
 // initialized in main.go instead of using default kvStore.
 type NoopKVStore struct {
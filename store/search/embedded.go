@@ -0,0 +1,654 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog/log"
+	"github.com/tigrisdata/tigris/query/filter"
+	qsearch "github.com/tigrisdata/tigris/query/search"
+	"github.com/tigrisdata/tigris/schema"
+)
+
+const (
+	embeddedLogExt = ".log"
+	// embeddedCompactThreshold is the minimum number of operations in the log of a collection before it is compacted.
+	embeddedCompactThreshold = 1024
+)
+
+type embeddedOp string
+
+const (
+	embeddedOpSchema embeddedOp = "schema"
+	embeddedOpFields embeddedOp = "fields"
+	embeddedOpUpsert embeddedOp = "upsert"
+	embeddedOpDelete embeddedOp = "delete"
+	// embeddedOpSynonym upserts a synonym, a synonym without the terms is deleted.
+	embeddedOpSynonym embeddedOp = "synonym"
+)
+
+// embeddedRecord is an entry of the log of a collection persisted by the embedded store.
+type embeddedRecord struct {
+	Op      embeddedOp                `json:"op"`
+	Schema  *schema.SearchStoreSchema `json:"schema,omitempty"`
+	Fields  []schema.SearchStoreField `json:"fields,omitempty"`
+	Doc     map[string]any            `json:"doc,omitempty"`
+	ID      string                    `json:"id,omitempty"`
+	Synonym *Synonym                  `json:"synonym,omitempty"`
+}
+
+// embeddedStore is a search store that indexes the documents in the server process. The documents and the schemas use
+// the same representation as the Typesense backend, so the rest of the server doesn't need to know which backend is
+// used. If a directory is configured then every change is appended to a log file per collection, the log is replayed
+// when the store is opened and compacted once it grows past twice the number of documents.
+type embeddedStore struct {
+	sync.RWMutex
+
+	dir         string
+	collections map[string]*embeddedCollection
+}
+
+// NewEmbeddedStore returns an in-process search store. The indexes are loaded from and persisted to "dir" unless it is
+// empty, in which case they are only kept in memory.
+func NewEmbeddedStore(dir string) (Store, error) {
+	s := &embeddedStore{
+		dir:         dir,
+		collections: make(map[string]*embeddedCollection),
+	}
+
+	if len(dir) == 0 {
+		return s, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *embeddedStore) load() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != embeddedLogExt {
+			continue
+		}
+
+		name, err := url.PathUnescape(strings.TrimSuffix(entry.Name(), embeddedLogExt))
+		if err != nil {
+			log.Warn().Str("file", entry.Name()).Msg("skipping unknown file in the search directory")
+			continue
+		}
+
+		coll, err := s.replay(name)
+		if err != nil {
+			return err
+		}
+		if coll != nil {
+			s.collections[name] = coll
+		}
+	}
+
+	log.Info().Str("dir", s.dir).Int("collections", len(s.collections)).Msg("loaded embedded search indexes")
+	return nil
+}
+
+func (s *embeddedStore) logPath(name string) string {
+	return filepath.Join(s.dir, url.PathEscape(name)+embeddedLogExt)
+}
+
+func (s *embeddedStore) replay(name string) (*embeddedCollection, error) {
+	f, err := os.Open(s.logPath(name))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var coll *embeddedCollection
+	ops := 0
+	decoder := jsoniter.NewDecoder(bufio.NewReader(f))
+	decoder.UseNumber()
+	for decoder.More() {
+		var record embeddedRecord
+		if err = decoder.Decode(&record); err != nil {
+			// a partially written record at the end of the log is dropped.
+			log.Warn().Err(err).Str("collection", name).Msg("truncated search log")
+			break
+		}
+
+		ops++
+		switch record.Op {
+		case embeddedOpSchema:
+			coll = newEmbeddedCollection(record.Schema)
+		case embeddedOpFields:
+			if coll != nil {
+				coll.updateFields(record.Fields)
+			}
+		case embeddedOpUpsert:
+			if coll != nil {
+				coll.put(record.Doc)
+			}
+		case embeddedOpDelete:
+			if coll != nil {
+				coll.remove(record.ID)
+			}
+		case embeddedOpSynonym:
+			if coll != nil && record.Synonym != nil {
+				coll.putSynonym(*record.Synonym)
+			}
+		}
+	}
+
+	if coll == nil {
+		return nil, nil
+	}
+
+	coll.ops = ops
+	coll.file, err = os.OpenFile(s.logPath(name), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return coll, nil
+}
+
+// persist appends the records to the log of the collection, the caller must hold the lock of the collection.
+func (s *embeddedStore) persist(name string, coll *embeddedCollection, records ...embeddedRecord) error {
+	if len(s.dir) == 0 || len(records) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	encoder := jsoniter.NewEncoder(&buf)
+	for _, r := range records {
+		if err := encoder.Encode(r); err != nil {
+			return err
+		}
+	}
+
+	if coll.file == nil {
+		var err error
+		if coll.file, err = os.OpenFile(s.logPath(name), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644); err != nil {
+			return err
+		}
+	}
+
+	if _, err := coll.file.Write(buf.Bytes()); err != nil {
+		return err
+	}
+
+	coll.ops += len(records)
+	if coll.ops > embeddedCompactThreshold && coll.ops > 2*len(coll.docs) {
+		return s.compact(name, coll)
+	}
+
+	return nil
+}
+
+// compact rewrites the log of the collection with only its schema and current documents.
+func (s *embeddedStore) compact(name string, coll *embeddedCollection) error {
+	tmp := s.logPath(name) + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	encoder := jsoniter.NewEncoder(w)
+	storeSchema := coll.schema()
+	ops := 1
+	err = encoder.Encode(embeddedRecord{Op: embeddedOpSchema, Schema: &storeSchema})
+	for _, syn := range coll.synonyms {
+		if err != nil {
+			break
+		}
+		syn := syn
+		err = encoder.Encode(embeddedRecord{Op: embeddedOpSynonym, Synonym: &syn})
+		ops++
+	}
+	for _, doc := range coll.ordered() {
+		if err != nil {
+			break
+		}
+		err = encoder.Encode(embeddedRecord{Op: embeddedOpUpsert, Doc: doc.fields})
+		ops++
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	_ = f.Close()
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	if err = os.Rename(tmp, s.logPath(name)); err != nil {
+		return err
+	}
+
+	_ = coll.file.Close()
+	coll.ops = ops
+	coll.file, err = os.OpenFile(s.logPath(name), os.O_WRONLY|os.O_APPEND, 0o644)
+	return err
+}
+
+func (s *embeddedStore) collection(name string) (*embeddedCollection, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	coll, ok := s.collections[name]
+	if !ok {
+		return nil, NewSearchError(http.StatusNotFound, ErrCodeNotFound, "Not Found")
+	}
+
+	return coll, nil
+}
+
+func (s *embeddedStore) AllCollections(_ context.Context) (map[string]*CollectionInfo, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	resp := make(map[string]*CollectionInfo, len(s.collections))
+	for name, coll := range s.collections {
+		resp[name] = coll.describe()
+	}
+
+	return resp, nil
+}
+
+func (s *embeddedStore) DescribeCollection(_ context.Context, name string) (*CollectionInfo, error) {
+	coll, err := s.collection(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return coll.describe(), nil
+}
+
+func (s *embeddedStore) CreateCollection(_ context.Context, storeSchema *schema.SearchStoreSchema) error {
+	if err := validateEmbeddedFields(storeSchema.Fields); err != nil {
+		return err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if _, ok := s.collections[storeSchema.Name]; ok {
+		return NewSearchError(http.StatusConflict, ErrCodeDuplicate, "A collection with name `%s` already exists.", storeSchema.Name)
+	}
+
+	coll := newEmbeddedCollection(storeSchema)
+	coll.Lock()
+	defer coll.Unlock()
+
+	if err := s.persist(storeSchema.Name, coll, embeddedRecord{Op: embeddedOpSchema, Schema: storeSchema}); err != nil {
+		return err
+	}
+
+	s.collections[storeSchema.Name] = coll
+	return nil
+}
+
+func (s *embeddedStore) UpdateCollection(_ context.Context, name string, fields []schema.SearchStoreField) error {
+	coll, err := s.collection(name)
+	if err != nil {
+		return err
+	}
+
+	coll.Lock()
+	defer coll.Unlock()
+
+	if err = validateEmbeddedFields(applyEmbeddedFields(coll.fields, fields)); err != nil {
+		return err
+	}
+
+	if err = s.persist(name, coll, embeddedRecord{Op: embeddedOpFields, Fields: fields}); err != nil {
+		return err
+	}
+
+	coll.updateFields(fields)
+	return nil
+}
+
+func (s *embeddedStore) DropCollection(_ context.Context, table string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	coll, ok := s.collections[table]
+	if !ok {
+		return NewSearchError(http.StatusNotFound, ErrCodeNotFound, "No collection with name `%s` found.", table)
+	}
+
+	delete(s.collections, table)
+
+	coll.Lock()
+	defer coll.Unlock()
+
+	if coll.file != nil {
+		_ = coll.file.Close()
+		coll.file = nil
+		if err := os.Remove(s.logPath(table)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *embeddedStore) UpsertSynonym(_ context.Context, table string, synonym Synonym) error {
+	if len(synonym.Id) == 0 || len(synonym.Synonyms) == 0 {
+		return NewSearchError(http.StatusBadRequest, ErrCodeInvalid, "synonym must have an id and at least one synonym")
+	}
+
+	coll, err := s.collection(table)
+	if err != nil {
+		return err
+	}
+
+	coll.Lock()
+	defer coll.Unlock()
+
+	if err = s.persist(table, coll, embeddedRecord{Op: embeddedOpSynonym, Synonym: &synonym}); err != nil {
+		return err
+	}
+
+	coll.putSynonym(synonym)
+	return nil
+}
+
+func (s *embeddedStore) DeleteSynonym(_ context.Context, table string, id string) error {
+	coll, err := s.collection(table)
+	if err != nil {
+		return err
+	}
+
+	coll.Lock()
+	defer coll.Unlock()
+
+	if _, ok := coll.synonyms[id]; !ok {
+		return NewSearchError(http.StatusNotFound, ErrCodeNotFound, "Could not find that `id`.")
+	}
+
+	if err = s.persist(table, coll, embeddedRecord{Op: embeddedOpSynonym, Synonym: &Synonym{Id: id}}); err != nil {
+		return err
+	}
+
+	delete(coll.synonyms, id)
+	return nil
+}
+
+func (s *embeddedStore) CreateDocument(_ context.Context, table string, doc map[string]any) error {
+	coll, err := s.collection(table)
+	if err != nil {
+		return err
+	}
+
+	normalized, err := normalizeEmbeddedDoc(doc)
+	if err != nil {
+		return NewSearchError(http.StatusBadRequest, ErrCodeInvalid, err.Error())
+	}
+
+	coll.Lock()
+	defer coll.Unlock()
+
+	resp := s.index(table, coll, normalized, Create)
+	if !resp.Success {
+		return NewSearchError(resp.Code, ErrCodeIndexingDocuments, resp.Error)
+	}
+
+	return nil
+}
+
+func (s *embeddedStore) IndexDocuments(_ context.Context, table string, documents io.Reader, options IndexDocumentsOptions) ([]IndexResp, error) {
+	coll, err := s.collection(table)
+	if err != nil {
+		return nil, err
+	}
+
+	coll.Lock()
+	defer coll.Unlock()
+
+	var responses []IndexResp
+	scanner := bufio.NewScanner(documents)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var doc map[string]any
+		decoder := jsoniter.NewDecoder(bytes.NewReader(line))
+		decoder.UseNumber()
+		if err = decoder.Decode(&doc); err != nil {
+			responses = append(responses, IndexResp{
+				Code:     http.StatusBadRequest,
+				Document: string(line),
+				Error:    fmt.Sprintf("Bad JSON: %s", err.Error()),
+			})
+			continue
+		}
+
+		resp := s.index(table, coll, doc, options.Action)
+		if !resp.Success {
+			resp.Document = string(line)
+		}
+		responses = append(responses, resp)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return responses, nil
+}
+
+// index creates, replaces or updates a single document according to the action. The caller must hold the lock of the
+// collection.
+func (s *embeddedStore) index(table string, coll *embeddedCollection, doc map[string]any, action IndexAction) IndexResp {
+	id, ok := doc[embeddedIDField].(string)
+	if !ok || len(id) == 0 {
+		return IndexResp{Code: http.StatusBadRequest, Error: "Document's `id` field should be a string."}
+	}
+
+	existing, exists := coll.docs[id]
+	switch action {
+	case Create:
+		if exists {
+			return IndexResp{Code: http.StatusConflict, Error: fmt.Sprintf("A document with id %s already exists.", id)}
+		}
+	case Update:
+		if !exists {
+			return IndexResp{Code: http.StatusNotFound, Error: fmt.Sprintf("Could not find a document with id: %s", id)}
+		}
+
+		merged := make(map[string]any, len(existing.fields)+len(doc))
+		for k, v := range existing.fields {
+			merged[k] = v
+		}
+		for k, v := range doc {
+			merged[k] = v
+		}
+		doc = merged
+	}
+
+	if err := coll.validate(doc); err != nil {
+		return IndexResp{Code: http.StatusBadRequest, Error: err.Error()}
+	}
+
+	if err := s.persist(table, coll, embeddedRecord{Op: embeddedOpUpsert, Doc: doc}); err != nil {
+		return IndexResp{Code: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	coll.put(doc)
+	return IndexResp{Code: http.StatusOK, Success: true}
+}
+
+func (s *embeddedStore) DeleteDocument(_ context.Context, table string, key string) error {
+	coll, err := s.collection(table)
+	if err != nil {
+		return err
+	}
+
+	coll.Lock()
+	defer coll.Unlock()
+
+	if _, ok := coll.docs[key]; !ok {
+		return NewSearchError(http.StatusNotFound, ErrCodeNotFound, "Could not find a document with id: %s", key)
+	}
+
+	if err = s.persist(table, coll, embeddedRecord{Op: embeddedOpDelete, ID: key}); err != nil {
+		return err
+	}
+
+	coll.remove(key)
+	return nil
+}
+
+func (s *embeddedStore) DeleteDocuments(_ context.Context, table string, wrapped *filter.WrappedFilter) (int, error) {
+	coll, err := s.collection(table)
+	if err != nil {
+		return 0, err
+	}
+
+	expr, err := parseEmbeddedFilter(wrapped.SearchFilter())
+	if err != nil {
+		return 0, err
+	}
+
+	coll.Lock()
+	defer coll.Unlock()
+
+	var records []embeddedRecord
+	for id, doc := range coll.docs {
+		if expr == nil || expr.matches(doc.fields) {
+			records = append(records, embeddedRecord{Op: embeddedOpDelete, ID: id})
+		}
+	}
+
+	if err = s.persist(table, coll, records...); err != nil {
+		return 0, err
+	}
+
+	for _, r := range records {
+		coll.remove(r.ID)
+	}
+
+	return len(records), nil
+}
+
+func (s *embeddedStore) Search(ctx context.Context, table string, query *qsearch.Query, pageNo int) ([]Result, error) {
+	if query.IsHybridSearch() {
+		return hybridSearch(ctx, table, query, pageNo, s.Search)
+	}
+
+	coll, err := s.collection(table)
+	if err != nil {
+		return nil, err
+	}
+
+	var expr embeddedExpr
+	if searchFilter := query.WrappedF.SearchFilter(); !query.HasNoSearchFilter() && len(searchFilter) > 1 {
+		if expr, err = parseEmbeddedFilter(searchFilter); err != nil {
+			return nil, err
+		}
+		bindEmbeddedAnalyzers(expr, query.Analyzers)
+	}
+
+	coll.RLock()
+	defer coll.RUnlock()
+
+	result, err := coll.search(query, expr, pageNo)
+	if err != nil {
+		return nil, err
+	}
+
+	return []Result{result}, nil
+}
+
+func (s *embeddedStore) GetDocuments(_ context.Context, table string, ids []string) (*Result, error) {
+	coll, err := s.collection(table)
+	if err != nil {
+		return nil, err
+	}
+
+	coll.RLock()
+	defer coll.RUnlock()
+
+	hits := make([]Hit, 0, len(ids))
+	for _, id := range ids {
+		if doc, ok := coll.docs[id]; ok {
+			hits = append(hits, doc.hit())
+		}
+	}
+
+	return &Result{
+		Found: len(hits),
+		OutOf: len(coll.docs),
+		Hits:  hits,
+	}, nil
+}
+
+// normalizeEmbeddedDoc returns a copy of the document in which numbers are represented the same way as in the documents
+// decoded from the Typesense responses.
+func normalizeEmbeddedDoc(doc map[string]any) (map[string]any, error) {
+	raw, err := jsoniter.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized map[string]any
+	decoder := jsoniter.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err = decoder.Decode(&normalized); err != nil {
+		return nil, err
+	}
+
+	return normalized, nil
+}
+
+func validateEmbeddedFields(fields []schema.SearchStoreField) error {
+	names := make(map[string]struct{}, len(fields))
+	for _, f := range fields {
+		if _, ok := names[f.Name]; ok {
+			return NewSearchError(http.StatusBadRequest, ErrCodeInvalid, errDuplicateFields)
+		}
+		names[f.Name] = struct{}{}
+	}
+
+	return nil
+}
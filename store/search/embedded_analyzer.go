@@ -0,0 +1,182 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/tigrisdata/tigris/schema"
+)
+
+// The embedded store indexes the terms of all the string fields with the standard tokenization, the analyzers of the
+// fields are applied when the terms are matched against the query and the filters.
+
+// embeddedStemRule replaces an inflection suffix of a word by the ending of its stem.
+type embeddedStemRule struct {
+	suffix  string
+	replace string
+}
+
+// embeddedStemRules are the inflection suffixes removed from the words of the languages with stemming. The rules are
+// tried in order, so the longer suffixes come first.
+var embeddedStemRules = map[string][]embeddedStemRule{
+	"en": {{"sses", "ss"}, {"ches", "ch"}, {"shes", "sh"}, {"xes", "x"}, {"ies", "y"}, {"ing", ""}, {"ed", ""}, {"ly", ""}, {"s", ""}},
+	"fr": {{"ements", ""}, {"ement", ""}, {"euses", ""}, {"euse", ""}, {"eux", ""}, {"es", ""}, {"s", ""}, {"e", ""}},
+	"de": {{"ungen", ""}, {"ung", ""}, {"en", ""}, {"er", ""}, {"es", ""}, {"e", ""}, {"n", ""}, {"s", ""}},
+	"es": {{"aciones", ""}, {"acion", ""}, {"es", ""}, {"as", ""}, {"os", ""}, {"s", ""}, {"a", ""}, {"o", ""}},
+	"it": {{"azioni", ""}, {"azione", ""}, {"i", ""}, {"e", ""}, {"a", ""}, {"o", ""}},
+	"pt": {{"acoes", ""}, {"acao", ""}, {"es", ""}, {"as", ""}, {"os", ""}, {"s", ""}, {"a", ""}, {"o", ""}},
+	"nl": {{"heden", ""}, {"heid", ""}, {"en", ""}, {"e", ""}, {"s", ""}},
+}
+
+const embeddedMinStemLen = 3
+
+// embeddedSegmented returns true for the languages that are not separated by spaces, their terms are matched on
+// any part of the text.
+func embeddedSegmented(a *schema.FieldAnalyzer) bool {
+	switch a.Locale() {
+	case "zh", "ja", "ko", "th":
+		return true
+	default:
+		return false
+	}
+}
+
+// stemEmbedded removes the inflection suffix of the word in the language.
+func stemEmbedded(language string, word string) string {
+	for _, rule := range embeddedStemRules[language] {
+		if len(word)-len(rule.suffix) < embeddedMinStemLen || !strings.HasSuffix(word, rule.suffix) {
+			continue
+		}
+
+		stem := strings.TrimSuffix(word, rule.suffix) + rule.replace
+		if language == "en" && (rule.suffix == "ing" || rule.suffix == "ed") && doubleConsonant(stem) {
+			// "running" and "stopped" are stemmed to "run" and "stop"
+			stem = stem[:len(stem)-1]
+		}
+		return stem
+	}
+
+	return word
+}
+
+func doubleConsonant(word string) bool {
+	n := len(word)
+	return n >= 2 && word[n-1] == word[n-2] && !strings.ContainsRune("aeioulsz", rune(word[n-1]))
+}
+
+// analyzedTermMatches returns true if the indexed term is matched by the token of the query according to the analyzer
+// of the field. An exact match is always a match.
+func analyzedTermMatches(a *schema.FieldAnalyzer, term string, token string) bool {
+	switch {
+	case term == token:
+		return true
+	case a.Is(schema.AnalyzerNGram), embeddedSegmented(a):
+		return strings.Contains(term, token)
+	case a.Is(schema.AnalyzerEdgeNGram):
+		return strings.HasPrefix(term, token)
+	case a.Is(schema.AnalyzerLanguage):
+		return stemEmbedded(a.Language, term) == stemEmbedded(a.Language, token)
+	default:
+		return false
+	}
+}
+
+// normalizeKeyword returns the value of a keyword field as it is compared, the whole value is a single token.
+func normalizeKeyword(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// keywordMatches returns true if the value, or one of the elements if it is an array, is the keyword.
+func keywordMatches(v any, keyword string) bool {
+	switch t := v.(type) {
+	case string:
+		return normalizeKeyword(t) == keyword
+	case []any:
+		for _, e := range t {
+			if s, ok := e.(string); ok && normalizeKeyword(s) == keyword {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// analyzedContains returns true if the string of the document contains all the tokens of the value according to the
+// analyzer of the field. A keyword field needs to be equal to the value.
+func analyzedContains(a *schema.FieldAnalyzer, docValue string, value string) bool {
+	if a.Is(schema.AnalyzerKeyword) {
+		return normalizeKeyword(docValue) == normalizeKeyword(value)
+	}
+
+	tokens := tokenizeEmbedded(docValue)
+	for _, want := range tokenizeEmbedded(value) {
+		found := false
+		for _, t := range tokens {
+			if analyzedTermMatches(a, t, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// bindEmbeddedAnalyzers sets the analyzers of the fields on the conditions of the filter.
+func bindEmbeddedAnalyzers(expr embeddedExpr, analyzers map[string]*schema.FieldAnalyzer) {
+	if len(analyzers) == 0 {
+		return
+	}
+
+	switch e := expr.(type) {
+	case *embeddedAnd:
+		bindEmbeddedAnalyzers(e.left, analyzers)
+		bindEmbeddedAnalyzers(e.right, analyzers)
+	case *embeddedOr:
+		bindEmbeddedAnalyzers(e.left, analyzers)
+		bindEmbeddedAnalyzers(e.right, analyzers)
+	case *embeddedCond:
+		e.analyzer = analyzers[e.field]
+	}
+}
+
+// embeddedHighlights are the terms matched in each field of a document.
+type embeddedHighlights map[string]map[string]struct{}
+
+func (h embeddedHighlights) add(field string, term string) {
+	terms, ok := h[field]
+	if !ok {
+		terms = make(map[string]struct{})
+		h[field] = terms
+	}
+	terms[term] = struct{}{}
+}
+
+// fields returns the names of the fields with a matched term, sorted by name.
+func (h embeddedHighlights) fields() []string {
+	fields := make([]string, 0, len(h))
+	for field := range h {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	return fields
+}
@@ -0,0 +1,307 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/tigrisdata/tigris/schema"
+)
+
+// embeddedExpr is a parsed search filter evaluated by the embedded store against the indexed documents.
+type embeddedExpr interface {
+	matches(doc map[string]any) bool
+}
+
+type embeddedAnd struct {
+	left, right embeddedExpr
+}
+
+func (e *embeddedAnd) matches(doc map[string]any) bool {
+	return e.left.matches(doc) && e.right.matches(doc)
+}
+
+type embeddedOr struct {
+	left, right embeddedExpr
+}
+
+func (e *embeddedOr) matches(doc map[string]any) bool {
+	return e.left.matches(doc) || e.right.matches(doc)
+}
+
+// embeddedCond is a single condition of the filter like "field:>=10", "field:=`value`" or "field:[a,b]". The analyzer
+// of the field applies to the conditions without an operator.
+type embeddedCond struct {
+	field    string
+	op       string
+	values   []string
+	analyzer *schema.FieldAnalyzer
+}
+
+func (e *embeddedCond) matches(doc map[string]any) bool {
+	v, ok := lookupEmbeddedField(doc, e.field)
+	if !ok || v == nil {
+		return e.op == "!="
+	}
+
+	values, isArray := v.([]any)
+	if !isArray {
+		values = []any{v}
+	}
+
+	if e.op == "!=" {
+		// none of the elements is allowed to be equal
+		for _, value := range values {
+			if e.matchesValue(value, "=") {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, value := range values {
+		if e.matchesValue(value, e.op) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *embeddedCond) matchesValue(docValue any, op string) bool {
+	for _, value := range e.values {
+		if matchesEmbeddedValue(e.analyzer, docValue, op, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesEmbeddedValue(analyzer *schema.FieldAnalyzer, docValue any, op string, value string) bool {
+	var cmp int
+	switch dv := docValue.(type) {
+	case string:
+		if op == "" {
+			// without an operator a string matches if it contains all the tokens of the value
+			return analyzedContains(analyzer, dv, value)
+		}
+		cmp = strings.Compare(dv, value)
+	case bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return false
+		}
+		return (op == "" || op == "=") && dv == b
+	default:
+		f, ok := embeddedNumber(docValue)
+		if !ok {
+			return false
+		}
+		want, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		switch {
+		case f < want:
+			cmp = -1
+		case f > want:
+			cmp = 1
+		}
+	}
+
+	switch op {
+	case "", "=":
+		return cmp == 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	default:
+		return false
+	}
+}
+
+// parseEmbeddedFilter parses the search filter built by the query filters, see filter.Filter ToSearchFilter. The "&&"
+// binds tighter than "||" and the parentheses group the nested filters. It returns nil for an empty filter.
+func parseEmbeddedFilter(input string) (embeddedExpr, error) {
+	if len(strings.TrimSpace(input)) == 0 {
+		return nil, nil
+	}
+
+	p := &embeddedFilterParser{input: input}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	if p.pos != len(p.input) {
+		return nil, p.error()
+	}
+
+	return expr, nil
+}
+
+type embeddedFilterParser struct {
+	input string
+	pos   int
+}
+
+func (p *embeddedFilterParser) error() error {
+	return NewSearchError(http.StatusBadRequest, ErrCodeInvalid, "Could not parse the filter query at position %d: `%s`", p.pos, p.input)
+}
+
+func (p *embeddedFilterParser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *embeddedFilterParser) consume(token string) bool {
+	p.skipSpaces()
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *embeddedFilterParser) parseOr() (embeddedExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.consume("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &embeddedOr{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *embeddedFilterParser) parseAnd() (embeddedExpr, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.consume("&&") {
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &embeddedAnd{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *embeddedFilterParser) parseTerm() (embeddedExpr, error) {
+	if p.consume("(") {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, p.error()
+		}
+		return expr, nil
+	}
+
+	p.skipSpaces()
+	colon := strings.IndexByte(p.input[p.pos:], ':')
+	if colon <= 0 {
+		return nil, p.error()
+	}
+
+	cond := &embeddedCond{field: strings.TrimSpace(p.input[p.pos : p.pos+colon])}
+	p.pos += colon + 1
+
+	p.skipSpaces()
+	for _, op := range []string{"!=", ">=", "<=", "=", ">", "<"} {
+		if strings.HasPrefix(p.input[p.pos:], op) {
+			cond.op = op
+			p.pos += len(op)
+			break
+		}
+	}
+
+	p.skipSpaces()
+	if p.consume("[") {
+		for {
+			value, err := p.parseValue(true)
+			if err != nil {
+				return nil, err
+			}
+			cond.values = append(cond.values, value)
+			if p.consume("]") {
+				break
+			}
+			if !p.consume(",") {
+				return nil, p.error()
+			}
+		}
+		return cond, nil
+	}
+
+	value, err := p.parseValue(false)
+	if err != nil {
+		return nil, err
+	}
+	cond.values = []string{value}
+
+	return cond, nil
+}
+
+// parseValue parses either a value quoted with backticks or a bare value that ends at a space, a closing parenthesis,
+// or, inside a list, at a comma or the closing bracket.
+func (p *embeddedFilterParser) parseValue(inList bool) (string, error) {
+	p.skipSpaces()
+	if p.pos < len(p.input) && p.input[p.pos] == '`' {
+		end := strings.IndexByte(p.input[p.pos+1:], '`')
+		if end < 0 {
+			return "", p.error()
+		}
+		value := p.input[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return value, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if unicode.IsSpace(rune(c)) || c == ')' || (inList && (c == ',' || c == ']')) ||
+			strings.HasPrefix(p.input[p.pos:], "&&") || strings.HasPrefix(p.input[p.pos:], "||") {
+			break
+		}
+		p.pos++
+	}
+
+	if p.pos == start {
+		return "", p.error()
+	}
+
+	return p.input[start:p.pos], nil
+}
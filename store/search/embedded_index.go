@@ -0,0 +1,1002 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	qsearch "github.com/tigrisdata/tigris/query/search"
+	"github.com/tigrisdata/tigris/schema"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	embeddedIDField        = "id"
+	embeddedTextMatchField = "_text_match"
+	embeddedVectorField    = "_vector_distance"
+
+	embeddedDefaultPerPage    = 10
+	embeddedDefaultGroupLimit = 3
+)
+
+type embeddedDoc struct {
+	seq    uint64
+	fields map[string]any
+}
+
+// hit returns the document as a search hit, the document is copied as the caller is free to modify it.
+func (d *embeddedDoc) hit() Hit {
+	return Hit{Document: copyEmbeddedValue(d.fields).(map[string]any)}
+}
+
+// embeddedCollection is a search index of the embedded store. It keeps the documents by id along with an inverted
+// index of the tokens of their string fields.
+type embeddedCollection struct {
+	sync.RWMutex
+
+	name      string
+	fields    []schema.SearchStoreField
+	createdAt int64
+	docs      map[string]*embeddedDoc
+	// terms is the inverted index, it maps a field to its tokens and a token to the frequency in every document.
+	terms    map[string]map[string]map[string]int
+	synonyms map[string]Synonym
+	seq      uint64
+
+	// ops is the number of records in the log file of the collection.
+	ops  int
+	file *os.File
+}
+
+func newEmbeddedCollection(storeSchema *schema.SearchStoreSchema) *embeddedCollection {
+	return &embeddedCollection{
+		name:      storeSchema.Name,
+		fields:    append([]schema.SearchStoreField(nil), storeSchema.Fields...),
+		createdAt: time.Now().Unix(),
+		docs:      make(map[string]*embeddedDoc),
+		terms:     make(map[string]map[string]map[string]int),
+		synonyms:  make(map[string]Synonym),
+	}
+}
+
+// putSynonym replaces the synonym with the same id, a synonym without any term is removed.
+func (c *embeddedCollection) putSynonym(synonym Synonym) {
+	if len(synonym.Synonyms) == 0 {
+		delete(c.synonyms, synonym.Id)
+		return
+	}
+
+	c.synonyms[synonym.Id] = synonym
+}
+
+// expandSynonyms returns the tokens that a query token also matches. A multi-way synonym expands every term of the
+// group to the others and a one-way synonym only expands the root to its synonyms.
+func (c *embeddedCollection) expandSynonyms(token string) []string {
+	var expanded []string
+	add := func(terms ...string) {
+		for _, term := range terms {
+			for _, t := range tokenizeEmbedded(term) {
+				if t != token {
+					expanded = append(expanded, t)
+				}
+			}
+		}
+	}
+
+	for _, syn := range c.synonyms {
+		if len(syn.Root) > 0 {
+			if strings.ToLower(syn.Root) == token {
+				add(syn.Synonyms...)
+			}
+			continue
+		}
+
+		for _, term := range syn.Synonyms {
+			if strings.ToLower(term) == token {
+				add(syn.Synonyms...)
+				break
+			}
+		}
+	}
+
+	return expanded
+}
+
+func (c *embeddedCollection) schema() schema.SearchStoreSchema {
+	return schema.SearchStoreSchema{
+		Name:   c.name,
+		Fields: append([]schema.SearchStoreField(nil), c.fields...),
+	}
+}
+
+func (c *embeddedCollection) describe() *CollectionInfo {
+	c.RLock()
+	defer c.RUnlock()
+
+	return &CollectionInfo{
+		Name:         c.name,
+		Fields:       append([]schema.SearchStoreField(nil), c.fields...),
+		NumDocuments: int64(len(c.docs)),
+		CreatedAt:    c.createdAt,
+	}
+}
+
+func (c *embeddedCollection) updateFields(fields []schema.SearchStoreField) {
+	c.fields = applyEmbeddedFields(c.fields, fields)
+}
+
+// applyEmbeddedFields applies a schema update, a field with "drop" set removes the existing field and any other field
+// is added.
+func applyEmbeddedFields(existing []schema.SearchStoreField, update []schema.SearchStoreField) []schema.SearchStoreField {
+	fields := append([]schema.SearchStoreField(nil), existing...)
+	for _, f := range update {
+		if f.Drop != nil && *f.Drop {
+			for i := range fields {
+				if fields[i].Name == f.Name {
+					fields = append(fields[:i], fields[i+1:]...)
+					break
+				}
+			}
+			continue
+		}
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+func (c *embeddedCollection) field(name string) (schema.SearchStoreField, bool) {
+	for _, f := range c.fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+
+	return schema.SearchStoreField{}, false
+}
+
+// validate checks that the values of the fields declared in the schema have the declared type.
+func (c *embeddedCollection) validate(doc map[string]any) error {
+	for _, f := range c.fields {
+		v, ok := doc[f.Name]
+		if !ok || v == nil {
+			if (f.Optional == nil || !*f.Optional) && f.Name != ".*" {
+				return fmt.Errorf("field `%s` has been declared in the schema, but is not found in the document", f.Name)
+			}
+			continue
+		}
+
+		if !embeddedTypeMatches(f.Type, v) {
+			return fmt.Errorf("field `%s` must be of type `%s`", f.Name, f.Type)
+		}
+		if f.NumDim != nil {
+			if arr, ok := v.([]any); ok && len(arr) != *f.NumDim {
+				return fmt.Errorf("field `%s` must have %d dimensions", f.Name, *f.NumDim)
+			}
+		}
+	}
+
+	return nil
+}
+
+func embeddedTypeMatches(fieldType string, v any) bool {
+	if elem, isArray := strings.CutSuffix(fieldType, "[]"); isArray {
+		arr, ok := v.([]any)
+		if !ok {
+			return false
+		}
+		for _, e := range arr {
+			if e != nil && !embeddedTypeMatches(elem, e) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch fieldType {
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "int32", "int64":
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	case "float":
+		_, ok := v.(json.Number)
+		return ok
+	case "bool":
+		_, ok := v.(bool)
+		return ok
+	default:
+		// "auto", "object" and the wildcard types are not checked.
+		return true
+	}
+}
+
+func (c *embeddedCollection) put(doc map[string]any) {
+	id, _ := doc[embeddedIDField].(string)
+
+	seq := c.seq
+	if existing, ok := c.docs[id]; ok {
+		// replacing a document keeps its position in the default ordering
+		seq = existing.seq
+		c.unindex(id, existing)
+	} else {
+		c.seq++
+	}
+
+	d := &embeddedDoc{seq: seq, fields: doc}
+	c.docs[id] = d
+	c.indexTerms(id, d)
+}
+
+func (c *embeddedCollection) remove(id string) {
+	if existing, ok := c.docs[id]; ok {
+		c.unindex(id, existing)
+		delete(c.docs, id)
+	}
+}
+
+func (c *embeddedCollection) indexTerms(id string, d *embeddedDoc) {
+	for field, v := range d.fields {
+		for _, token := range embeddedValueTokens(v) {
+			tokens, ok := c.terms[field]
+			if !ok {
+				tokens = make(map[string]map[string]int)
+				c.terms[field] = tokens
+			}
+			postings, ok := tokens[token]
+			if !ok {
+				postings = make(map[string]int)
+				tokens[token] = postings
+			}
+			postings[id]++
+		}
+	}
+}
+
+func (c *embeddedCollection) unindex(id string, d *embeddedDoc) {
+	for field, v := range d.fields {
+		tokens := c.terms[field]
+		for _, token := range embeddedValueTokens(v) {
+			delete(tokens[token], id)
+			if len(tokens[token]) == 0 {
+				delete(tokens, token)
+			}
+		}
+		if len(tokens) == 0 {
+			delete(c.terms, field)
+		}
+	}
+}
+
+// ordered returns the documents in the order in which they are first indexed.
+func (c *embeddedCollection) ordered() []*embeddedDoc {
+	docs := make([]*embeddedDoc, 0, len(c.docs))
+	for _, d := range c.docs {
+		docs = append(docs, d)
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].seq < docs[j].seq })
+
+	return docs
+}
+
+// queryFields returns the fields searched by the query, all the string fields of the schema if the query doesn't
+// restrict them.
+func (c *embeddedCollection) queryFields(query *qsearch.Query) []string {
+	if len(query.SearchFields) > 0 {
+		return query.SearchFields
+	}
+
+	var fields []string
+	for _, f := range c.fields {
+		if (f.Type == "string" || f.Type == "string[]") && (f.Index == nil || *f.Index) {
+			fields = append(fields, f.Name)
+		}
+	}
+
+	return fields
+}
+
+type embeddedMatch struct {
+	doc        *embeddedDoc
+	score      int64
+	distance   float64
+	highlights embeddedHighlights
+}
+
+func (c *embeddedCollection) search(query *qsearch.Query, expr embeddedExpr, pageNo int) (Result, error) {
+	var matches []*embeddedMatch
+	switch {
+	case query.IsVectorSearch():
+		var err error
+		if matches, err = c.vectorMatches(query); err != nil {
+			return Result{}, err
+		}
+	case len(query.Q) == 0 || query.Q == "*":
+		for _, d := range c.ordered() {
+			matches = append(matches, &embeddedMatch{doc: d})
+		}
+	default:
+		matches = c.textMatches(query)
+	}
+
+	if expr != nil {
+		filtered := matches[:0]
+		for _, m := range matches {
+			if expr.matches(m.doc.fields) {
+				filtered = append(filtered, m)
+			}
+		}
+		matches = filtered
+	}
+
+	if query.IsVectorSearch() && query.VectorS.TopK > 0 && len(matches) > query.VectorS.TopK {
+		matches = matches[:query.VectorS.TopK]
+	}
+
+	if query.SortOrder != nil && len(*query.SortOrder) > 0 {
+		sortEmbeddedMatches(matches, query)
+	}
+	matches = c.curate(query, expr, matches)
+
+	perPage := query.PageSize
+	if perPage <= 0 {
+		perPage = embeddedDefaultPerPage
+	}
+	if pageNo <= 0 {
+		pageNo = 1
+	}
+
+	result := Result{
+		OutOf:  len(c.docs),
+		Page:   pageNo,
+		Facets: c.facets(query, matches),
+	}
+
+	if query.IsGroupByQuery() {
+		groups := groupEmbeddedMatches(query, matches)
+		result.Found = len(groups)
+		result.GroupedHits = paginate(groups, pageNo, perPage)
+		return result, nil
+	}
+
+	result.Found = len(matches)
+	matches = paginate(matches, pageNo, perPage)
+	result.Hits = make([]Hit, 0, len(matches))
+	for _, m := range matches {
+		result.Hits = append(result.Hits, m.hit(query))
+	}
+
+	return result, nil
+}
+
+// curate removes the hidden documents and places the pinned documents at their positions. Like the other matches, a
+// pinned document is only returned if it matches the filter.
+func (c *embeddedCollection) curate(query *qsearch.Query, expr embeddedExpr, matches []*embeddedMatch) []*embeddedMatch {
+	if len(query.PinnedHits) == 0 && len(query.HiddenHits) == 0 {
+		return matches
+	}
+
+	excluded := make(map[string]struct{})
+	for _, id := range query.HiddenHits {
+		excluded[id] = struct{}{}
+	}
+	for _, p := range query.PinnedHits {
+		excluded[p.Id] = struct{}{}
+	}
+
+	curated := make([]*embeddedMatch, 0, len(matches)+len(query.PinnedHits))
+	for _, m := range matches {
+		if _, ok := excluded[embeddedValueString(m.doc.fields[embeddedIDField])]; !ok {
+			curated = append(curated, m)
+		}
+	}
+
+	pinned := append([]qsearch.PinnedHit(nil), query.PinnedHits...)
+	sort.SliceStable(pinned, func(i, j int) bool {
+		return pinned[i].Position < pinned[j].Position
+	})
+	for _, p := range pinned {
+		d, ok := c.docs[p.Id]
+		if !ok || (expr != nil && !expr.matches(d.fields)) {
+			continue
+		}
+
+		pos := p.Position - 1
+		if pos < 0 {
+			pos = 0
+		}
+		if pos > len(curated) {
+			pos = len(curated)
+		}
+		curated = slices.Insert(curated, pos, &embeddedMatch{doc: d})
+	}
+
+	return curated
+}
+
+func (m *embeddedMatch) hit(query *qsearch.Query) Hit {
+	hit := m.doc.hit()
+	switch {
+	case query.IsVectorSearch():
+		distance := m.distance
+		hit.VectorDistance = &distance
+	case len(query.Q) > 0 && query.Q != "*":
+		score := m.score
+		hit.TextMatch = &score
+		hit.MatchedFields = m.highlights.fields()
+	}
+
+	return hit
+}
+
+func paginate[T any](items []T, pageNo int, perPage int) []T {
+	start := (pageNo - 1) * perPage
+	if start >= len(items) {
+		return []T{}
+	}
+
+	end := start + perPage
+	if end > len(items) {
+		end = len(items)
+	}
+
+	return items[start:end]
+}
+
+// textMatches returns the documents matching the tokens of the query in the searched fields, ranked by the number of
+// tokens matched, then by the documents having a field equal to the query and then by the frequency of the tokens
+// weighted by the field weight or the position of the field in the searched fields. The last token is also matched as
+// a prefix, and the tokens tolerate typos if the query sets the number of typos. Documents need to match all the tokens,
+// unless fewer documents than the drop tokens threshold do in which case the documents matching any token are returned.
+func (c *embeddedCollection) textMatches(query *qsearch.Query) []*embeddedMatch {
+	tokens := tokenizeEmbedded(query.ToSearchQ())
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	relevance := query.Relevance
+	if relevance == nil {
+		relevance = &schema.SearchRelevance{}
+	}
+
+	fields := c.queryFields(query)
+	scores := make(map[string]int64)
+	matched := make(map[string]int)
+	highlights := make(map[string]embeddedHighlights)
+	highlight := func(id string, field string, term string) {
+		h, ok := highlights[id]
+		if !ok {
+			h = make(embeddedHighlights)
+			highlights[id] = h
+		}
+		h.add(field, term)
+	}
+	fieldWeight := func(j int, field string) int64 {
+		if len(relevance.FieldWeights) > 0 {
+			return int64(query.FieldWeight(field))
+		}
+		return int64(len(fields) - j)
+	}
+
+	// a keyword field is a single token, it matches all the tokens of the query if it is equal to the query
+	keyword := normalizeKeyword(query.Q)
+	keywordMatched := make(map[string]struct{})
+	for j, field := range fields {
+		if !query.Analyzer(field).Is(schema.AnalyzerKeyword) {
+			continue
+		}
+		for id, d := range c.docs {
+			if keywordMatches(d.fields[field], keyword) {
+				scores[id] += 2 * fieldWeight(j, field) * int64(len(tokens))
+				keywordMatched[id] = struct{}{}
+				highlight(id, field, keyword)
+			}
+		}
+	}
+
+	for i, token := range tokens {
+		prefix := i == len(tokens)-1 && query.IsPrefixSearch()
+		typos := embeddedTypos(relevance, token)
+		synonyms := c.expandSynonyms(token)
+		tokenMatched := make(map[string]struct{})
+		for j, field := range fields {
+			analyzer := query.Analyzer(field)
+			if analyzer.Is(schema.AnalyzerKeyword) {
+				continue
+			}
+			weight := fieldWeight(j, field)
+
+			for term, postings := range c.terms[field] {
+				var boost int64
+				switch {
+				case term == token:
+					boost = 2
+				case analyzedTermMatches(analyzer, term, token):
+					boost = 1
+				case prefix && strings.HasPrefix(term, token), slices.Contains(synonyms, term):
+					boost = 1
+				case typos > 0 && editDistance(term, token, typos) <= typos:
+					boost = 1
+				default:
+					continue
+				}
+
+				for id, freq := range postings {
+					scores[id] += boost * weight * int64(freq)
+					tokenMatched[id] = struct{}{}
+					highlight(id, field, term)
+				}
+			}
+		}
+		for id := range tokenMatched {
+			if _, ok := keywordMatched[id]; !ok {
+				matched[id]++
+			}
+		}
+	}
+	for id := range keywordMatched {
+		matched[id] = len(tokens)
+	}
+
+	threshold := 1
+	if relevance.DropTokensThreshold != nil {
+		threshold = *relevance.DropTokensThreshold
+	}
+	matchedAll := 0
+	for _, n := range matched {
+		if n == len(tokens) {
+			matchedAll++
+		}
+	}
+	required := len(tokens)
+	if threshold > 0 && matchedAll < threshold {
+		required = 1
+	}
+
+	exact := relevance.PrioritizeExactMatch == nil || *relevance.PrioritizeExactMatch
+	matches := make([]*embeddedMatch, 0, len(matched))
+	for id, n := range matched {
+		if n < required {
+			continue
+		}
+
+		score := int64(n)<<40 | scores[id]
+		if exact && c.docs[id].equalsTokens(fields, tokens) {
+			score |= 1 << 39
+		}
+		matches = append(matches, &embeddedMatch{
+			doc:        c.docs[id],
+			score:      score,
+			highlights: highlights[id],
+		})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].doc.seq < matches[j].doc.seq
+	})
+
+	return matches
+}
+
+// equalsTokens returns true if one of the fields of the document has exactly the tokens.
+func (d *embeddedDoc) equalsTokens(fields []string, tokens []string) bool {
+	for _, field := range fields {
+		if str, ok := d.fields[field].(string); ok && slices.Equal(tokenizeEmbedded(str), tokens) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// embeddedTypos returns the number of typos tolerated for the token, the minimum lengths default to the ones of
+// Typesense. No typo is tolerated if the number of typos is not set.
+func embeddedTypos(relevance *schema.SearchRelevance, token string) int {
+	if relevance.NumTypos == nil {
+		return 0
+	}
+
+	minLen1, minLen2 := 4, 7
+	if relevance.MinLen1Typo != nil {
+		minLen1 = *relevance.MinLen1Typo
+	}
+	if relevance.MinLen2Typo != nil {
+		minLen2 = *relevance.MinLen2Typo
+	}
+
+	length := len([]rune(token))
+	switch {
+	case length < minLen1:
+		return 0
+	case length < minLen2 && *relevance.NumTypos > 1:
+		return 1
+	default:
+		return *relevance.NumTypos
+	}
+}
+
+// editDistance returns the Levenshtein distance between both words, stopping early once it exceeds the limit.
+func editDistance(a string, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if diff := len(ra) - len(rb); diff > limit || -diff > limit {
+		return limit + 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if prev[j]+1 < curr[j] {
+				curr[j] = prev[j] + 1
+			}
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+			if curr[j] < rowMin {
+				rowMin = curr[j]
+			}
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// vectorMatches returns the documents having the vector field ordered by the cosine distance to the query vector.
+func (c *embeddedCollection) vectorMatches(query *qsearch.Query) ([]*embeddedMatch, error) {
+	field := query.VectorS.VectorF
+	if f, ok := c.field(field); ok && f.NumDim != nil && *f.NumDim != len(query.VectorS.VectorV) {
+		return nil, NewSearchError(http.StatusBadRequest, ErrCodeInvalid,
+			"Query field `%s` must have %d dimensions.", field, *f.NumDim)
+	}
+
+	var matches []*embeddedMatch
+	for _, d := range c.ordered() {
+		vector, ok := embeddedVector(d.fields[field])
+		if !ok || len(vector) != len(query.VectorS.VectorV) {
+			continue
+		}
+
+		matches = append(matches, &embeddedMatch{
+			doc:      d,
+			distance: cosineDistance(query.VectorS.VectorV, vector),
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].distance < matches[j].distance })
+	return matches, nil
+}
+
+func embeddedVector(v any) ([]float64, bool) {
+	arr, ok := v.([]any)
+	if !ok {
+		return nil, false
+	}
+
+	vector := make([]float64, len(arr))
+	for i, e := range arr {
+		f, ok := embeddedNumber(e)
+		if !ok {
+			return nil, false
+		}
+		vector[i] = f
+	}
+
+	return vector, true
+}
+
+func cosineDistance(a []float64, b []float64) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 1
+	}
+
+	return 1 - dot/(math.Sqrt(normA)*math.Sqrt(normB))
+}
+
+func sortEmbeddedMatches(matches []*embeddedMatch, query *qsearch.Query) {
+	sort.SliceStable(matches, func(i, j int) bool {
+		for _, f := range *query.SortOrder {
+			a, aOk := matches[i].sortValue(f.Name)
+			b, bOk := matches[j].sortValue(f.Name)
+			switch {
+			case !aOk && !bOk:
+				continue
+			case !aOk:
+				return f.MissingValuesFirst
+			case !bOk:
+				return !f.MissingValuesFirst
+			}
+
+			cmp := compareEmbeddedValues(a, b)
+			if cmp == 0 {
+				continue
+			}
+			if f.Ascending {
+				return cmp < 0
+			}
+			return cmp > 0
+		}
+		return false
+	})
+}
+
+func (m *embeddedMatch) sortValue(field string) (any, bool) {
+	switch field {
+	case embeddedTextMatchField:
+		return json.Number(strconv.FormatInt(m.score, 10)), true
+	case embeddedVectorField:
+		return json.Number(strconv.FormatFloat(m.distance, 'g', -1, 64)), true
+	}
+
+	v, ok := lookupEmbeddedField(m.doc.fields, field)
+	return v, ok && v != nil
+}
+
+func compareEmbeddedValues(a any, b any) int {
+	if fa, ok := embeddedNumber(a); ok {
+		if fb, ok := embeddedNumber(b); ok {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	return strings.Compare(embeddedValueString(a), embeddedValueString(b))
+}
+
+func (*embeddedCollection) facets(query *qsearch.Query, matches []*embeddedMatch) []FacetCounts {
+	if len(query.Facets.Fields) == 0 {
+		return nil
+	}
+
+	size := query.ToSearchFacetSize()
+	facets := make([]FacetCounts, 0, len(query.Facets.Fields))
+	for _, ff := range query.Facets.Fields {
+		counts := make(map[string]int)
+		numeric := true
+		var sum, minV, maxV float64
+		var numbers int
+		for _, m := range matches {
+			v, ok := lookupEmbeddedField(m.doc.fields, ff.Name)
+			if !ok || v == nil {
+				continue
+			}
+
+			values, isArray := v.([]any)
+			if !isArray {
+				values = []any{v}
+			}
+			for _, value := range values {
+				if value == nil {
+					continue
+				}
+				counts[embeddedValueString(value)]++
+
+				f, ok := embeddedNumber(value)
+				if !ok {
+					numeric = false
+					continue
+				}
+				if numbers == 0 || f < minV {
+					minV = f
+				}
+				if numbers == 0 || f > maxV {
+					maxV = f
+				}
+				sum += f
+				numbers++
+			}
+		}
+
+		facets = append(facets, buildEmbeddedFacet(ff.Name, counts, size, numeric && numbers > 0, sum, minV, maxV, numbers))
+	}
+
+	return facets
+}
+
+func buildEmbeddedFacet(field string, counts map[string]int, size int, numeric bool, sum, minV, maxV float64, numbers int) FacetCounts {
+	values := make([]string, 0, len(counts))
+	for v := range counts {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if counts[values[i]] != counts[values[j]] {
+			return counts[values[i]] > counts[values[j]]
+		}
+		return values[i] < values[j]
+	})
+	if size > 0 && len(values) > size {
+		values = values[:size]
+	}
+
+	facet := FacetCounts{
+		FieldName: field,
+		Counts:    make([]FacetCount, 0, len(values)),
+		Stats:     &FacetStats{TotalValues: len(counts)},
+	}
+	for _, v := range values {
+		facet.Counts = append(facet.Counts, FacetCount{Value: v, Count: counts[v]})
+	}
+	if numeric {
+		avg := sum / float64(numbers)
+		facet.Stats.Avg, facet.Stats.Max, facet.Stats.Min, facet.Stats.Sum = &avg, &maxV, &minV, &sum
+	}
+
+	return facet
+}
+
+func groupEmbeddedMatches(query *qsearch.Query, matches []*embeddedMatch) []GroupedHit {
+	limit := embeddedDefaultGroupLimit
+	if query.GroupBy.Limit != nil && *query.GroupBy.Limit > 0 {
+		limit = int(*query.GroupBy.Limit)
+	}
+
+	var groups []GroupedHit
+	index := make(map[string]int)
+	for _, m := range matches {
+		key := make([]string, 0, len(query.GroupBy.Fields))
+		for _, f := range query.GroupBy.Fields {
+			v, _ := lookupEmbeddedField(m.doc.fields, f)
+			key = append(key, embeddedValueString(v))
+		}
+
+		joined := strings.Join(key, "\x00")
+		i, ok := index[joined]
+		if !ok {
+			i = len(groups)
+			index[joined] = i
+			groups = append(groups, GroupedHit{GroupKey: key})
+		}
+		if len(groups[i].Hits) < limit {
+			groups[i].Hits = append(groups[i].Hits, m.hit(query))
+		}
+	}
+
+	return groups
+}
+
+// lookupEmbeddedField returns the value of the field, the name of a nested field is either a key of the flattened
+// document or a path of keys separated by a dot.
+func lookupEmbeddedField(doc map[string]any, name string) (any, bool) {
+	if v, ok := doc[name]; ok {
+		return v, true
+	}
+
+	parent, child, found := strings.Cut(name, ".")
+	if !found {
+		return nil, false
+	}
+	if nested, ok := doc[parent].(map[string]any); ok {
+		return lookupEmbeddedField(nested, child)
+	}
+
+	return nil, false
+}
+
+func embeddedNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+func embeddedValueString(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		return fmt.Sprintf("%v", t)
+	}
+}
+
+func embeddedValueTokens(v any) []string {
+	switch t := v.(type) {
+	case string:
+		return tokenizeEmbedded(t)
+	case []any:
+		var tokens []string
+		for _, e := range t {
+			if s, ok := e.(string); ok {
+				tokens = append(tokens, tokenizeEmbedded(s)...)
+			}
+		}
+		return tokens
+	default:
+		return nil
+	}
+}
+
+// tokenizeEmbedded splits the text into lower-cased tokens of letters and digits.
+func tokenizeEmbedded(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func copyEmbeddedValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(t))
+		for k, e := range t {
+			copied[k] = copyEmbeddedValue(e)
+		}
+		return copied
+	case []any:
+		copied := make([]any, len(t))
+		for i, e := range t {
+			copied[i] = copyEmbeddedValue(e)
+		}
+		return copied
+	default:
+		return v
+	}
+}
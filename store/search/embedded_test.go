@@ -0,0 +1,487 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/query/filter"
+	qsearch "github.com/tigrisdata/tigris/query/search"
+	"github.com/tigrisdata/tigris/query/sort"
+	"github.com/tigrisdata/tigris/schema"
+)
+
+var embeddedTestDocs = `{"id":"1","name":"Red running shoes","brand":"acme","price":50,"tags":["sport","red"],"vec":[1,0]}
+{"id":"2","name":"Blue running jacket","brand":"acme","price":120,"tags":["sport"],"vec":[0,1]}
+{"id":"3","name":"Red dress","brand":"bolt","price":80,"tags":["red"],"vec":[0.9,0.1]}
+{"id":"4","name":"Green hat","brand":"bolt","vec":[0.5,0.5]}
+`
+
+func newEmbeddedTestStore(t *testing.T, dir string) Store {
+	s, err := NewEmbeddedStore(dir)
+	require.NoError(t, err)
+
+	optional := true
+	dim := 2
+	require.NoError(t, s.CreateCollection(context.TODO(), &schema.SearchStoreSchema{
+		Name: "products",
+		Fields: []schema.SearchStoreField{
+			{Name: "id", Type: "string"},
+			{Name: "name", Type: "string"},
+			{Name: "brand", Type: "string", Facet: &optional},
+			{Name: "price", Type: "int64", Optional: &optional},
+			{Name: "tags", Type: "string[]", Optional: &optional},
+			{Name: "vec", Type: "float[]", NumDim: &dim, Optional: &optional},
+		},
+	}))
+
+	resp, err := s.IndexDocuments(context.TODO(), "products", strings.NewReader(embeddedTestDocs), IndexDocumentsOptions{
+		Action: Create,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp, 4)
+	for _, r := range resp {
+		require.True(t, r.Success, r.Error)
+	}
+
+	return s
+}
+
+func embeddedHitIds(t *testing.T, result []Result) []string {
+	require.Len(t, result, 1)
+
+	var ids []string
+	for _, hit := range result[0].Hits {
+		ids = append(ids, hit.Document["id"].(string))
+	}
+	return ids
+}
+
+func TestEmbeddedFilter(t *testing.T) {
+	doc := map[string]any{
+		"name":   "Red running shoes",
+		"price":  json.Number("50"),
+		"active": true,
+		"tags":   []any{"sport", "red"},
+		"nested": map[string]any{"size": json.Number("42")},
+	}
+
+	cases := []struct {
+		filter  string
+		matches bool
+	}{
+		{"price:=50", true},
+		{"price:>50", false},
+		{"price:>=50", true},
+		{"price:<60 && price:>40", true},
+		{"price:<40 || price:>45", true},
+		{"name:=`Red running shoes`", true},
+		{"name:=`Red`", false},
+		{"name:running", true},
+		{"active:=true", true},
+		{"active:=false", false},
+		{"tags:=red", true},
+		{"tags:!=blue", true},
+		{"tags:!=red", false},
+		{"tags:[blue,sport]", true},
+		{"nested.size:=42", true},
+		{"missing:=1", false},
+		{"price:=50 && (tags:=blue || name:=`Red running shoes`)", true},
+		{"price:=10 || price:=20 && name:=`Red running shoes`", false},
+	}
+	for _, c := range cases {
+		expr, err := parseEmbeddedFilter(c.filter)
+		require.NoError(t, err, c.filter)
+		require.Equal(t, c.matches, expr.matches(doc), c.filter)
+	}
+
+	for _, invalid := range []string{"price", "price:=", "(price:=1", "name:=`abc", "price:=1 &&"} {
+		_, err := parseEmbeddedFilter(invalid)
+		require.Error(t, err, invalid)
+	}
+
+	analyzed := []struct {
+		filter   string
+		analyzer *schema.FieldAnalyzer
+		matches  bool
+	}{
+		{"name:shoe", nil, false},
+		{"name:shoe", &schema.FieldAnalyzer{Type: schema.AnalyzerLanguage, Language: "en"}, true},
+		{"name:running", &schema.FieldAnalyzer{Type: schema.AnalyzerKeyword}, false},
+		{"name:`red running shoes`", &schema.FieldAnalyzer{Type: schema.AnalyzerKeyword}, true},
+		{"name:unn", &schema.FieldAnalyzer{Type: schema.AnalyzerNGram}, true},
+		{"name:sho", &schema.FieldAnalyzer{Type: schema.AnalyzerEdgeNGram}, true},
+	}
+	for _, c := range analyzed {
+		expr, err := parseEmbeddedFilter(c.filter)
+		require.NoError(t, err, c.filter)
+		bindEmbeddedAnalyzers(expr, map[string]*schema.FieldAnalyzer{"name": c.analyzer})
+		require.Equal(t, c.matches, expr.matches(doc), c.filter)
+	}
+
+	expr, err := parseEmbeddedFilter("  ")
+	require.NoError(t, err)
+	require.Nil(t, expr)
+}
+
+func TestEmbeddedStore(t *testing.T) {
+	ctx := context.TODO()
+	s := newEmbeddedTestStore(t, "")
+
+	t.Run("collections", func(t *testing.T) {
+		err := s.CreateCollection(ctx, &schema.SearchStoreSchema{Name: "products"})
+		require.True(t, IsErrDuplicateEntity(err))
+
+		_, err = s.DescribeCollection(ctx, "unknown")
+		require.True(t, IsErrNotFound(err))
+
+		resp, err := s.DescribeCollection(ctx, "products")
+		require.NoError(t, err)
+		require.Equal(t, int64(4), resp.NumDocuments)
+
+		err = s.UpdateCollection(ctx, "products", []schema.SearchStoreField{{Name: "name", Type: "string"}})
+		require.True(t, IsErrDuplicateFieldNames(err))
+
+		drop := true
+		require.NoError(t, s.UpdateCollection(ctx, "products", []schema.SearchStoreField{
+			{Name: "name", Drop: &drop},
+			{Name: "name", Type: "string"},
+		}))
+
+		all, err := s.AllCollections(ctx)
+		require.NoError(t, err)
+		require.Contains(t, all, "products")
+	})
+
+	t.Run("create_duplicate", func(t *testing.T) {
+		resp, err := s.IndexDocuments(ctx, "products", strings.NewReader(`{"id":"1","name":"dup"}`), IndexDocumentsOptions{Action: Create})
+		require.NoError(t, err)
+		require.False(t, resp[0].Success)
+		require.Equal(t, http.StatusConflict, resp[0].Code)
+
+		err = s.CreateDocument(ctx, "products", map[string]any{"id": "1", "name": "dup"})
+		require.Error(t, err)
+
+		resp, err = s.IndexDocuments(ctx, "products", strings.NewReader(`{"id":"1","name":5}`), IndexDocumentsOptions{Action: Replace})
+		require.NoError(t, err)
+		require.False(t, resp[0].Success)
+		require.Equal(t, http.StatusBadRequest, resp[0].Code)
+	})
+
+	t.Run("full_text", func(t *testing.T) {
+		result, err := s.Search(ctx, "products", qsearch.NewBuilder().Query("red").Filter(filter.WrappedEmptyFilter).PageSize(10).Build(), 1)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"1", "3"}, embeddedHitIds(t, result))
+		require.Equal(t, 2, result[0].Found)
+		require.NotNil(t, result[0].Hits[0].TextMatch)
+
+		// the last token matches as a prefix and the documents matching all tokens are ranked first
+		result, err = s.Search(ctx, "products", qsearch.NewBuilder().Query("running sho").Filter(filter.WrappedEmptyFilter).PageSize(10).Build(), 1)
+		require.NoError(t, err)
+		require.Equal(t, []string{"1"}, embeddedHitIds(t, result))
+
+		// the stopwords of the query are dropped
+		result, err = s.Search(ctx, "products", qsearch.NewBuilder().Query("the running sho").Stopwords([]string{"the"}).Filter(filter.WrappedEmptyFilter).PageSize(10).Build(), 1)
+		require.NoError(t, err)
+		require.Equal(t, []string{"1"}, embeddedHitIds(t, result))
+
+		// no document has both tokens so any of them is enough
+		result, err = s.Search(ctx, "products", qsearch.NewBuilder().Query("hat dress").Filter(filter.WrappedEmptyFilter).PageSize(10).Build(), 1)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"3", "4"}, embeddedHitIds(t, result))
+
+		// restricted to a field
+		result, err = s.Search(ctx, "products", qsearch.NewBuilder().Query("acme").SearchFields([]string{"name"}).Filter(filter.WrappedEmptyFilter).PageSize(10).Build(), 1)
+		require.NoError(t, err)
+		require.Empty(t, result[0].Hits)
+	})
+
+	t.Run("synonyms", func(t *testing.T) {
+		search := func(q string) []string {
+			result, err := s.Search(ctx, "products", qsearch.NewBuilder().Query(q).Filter(filter.WrappedEmptyFilter).PageSize(10).Build(), 1)
+			require.NoError(t, err)
+			return embeddedHitIds(t, result)
+		}
+
+		require.NoError(t, s.UpsertSynonym(ctx, "products", Synonym{Id: "clothes", Synonyms: []string{"jacket", "dress"}}))
+		require.ElementsMatch(t, []string{"2", "3"}, search("jacket"))
+		require.ElementsMatch(t, []string{"2", "3"}, search("dress"))
+
+		// a one-way synonym only expands the root
+		require.NoError(t, s.UpsertSynonym(ctx, "products", Synonym{Id: "footwear", Root: "footwear", Synonyms: []string{"shoes"}}))
+		require.Equal(t, []string{"1"}, search("footwear"))
+		require.Equal(t, []string{"1"}, search("shoes"))
+
+		require.NoError(t, s.DeleteSynonym(ctx, "products", "clothes"))
+		require.Equal(t, []string{"2"}, search("jacket"))
+		require.True(t, IsErrNotFound(s.DeleteSynonym(ctx, "products", "clothes")))
+		require.True(t, IsErrNotFound(s.UpsertSynonym(ctx, "unknown", Synonym{Id: "a", Synonyms: []string{"b"}})))
+		require.NoError(t, s.DeleteSynonym(ctx, "products", "footwear"))
+	})
+
+	t.Run("relevance", func(t *testing.T) {
+		search := func(q string, relevance *schema.SearchRelevance, fields ...string) []string {
+			result, err := s.Search(ctx, "products", qsearch.NewBuilder().Query(q).SearchFields(fields).Relevance(relevance).Filter(filter.WrappedEmptyFilter).PageSize(10).Build(), 1)
+			require.NoError(t, err)
+			return embeddedHitIds(t, result)
+		}
+		intP := func(i int) *int { return &i }
+
+		require.Empty(t, search("runing", nil))
+		require.ElementsMatch(t, []string{"1", "2"}, search("runing", &schema.SearchRelevance{NumTypos: intP(1)}))
+		require.Empty(t, search("runing", &schema.SearchRelevance{NumTypos: intP(1), MinLen1Typo: intP(7)}))
+
+		prefix := false
+		require.Equal(t, []string{"1"}, search("sho", nil))
+		require.Empty(t, search("sho", &schema.SearchRelevance{Prefix: &prefix}))
+
+		require.Equal(t, []string{"1", "3", "2"}, search("red acme", &schema.SearchRelevance{DropTokensThreshold: intP(2)}, "name", "brand"))
+		require.Equal(t, []string{"1", "2", "3"}, search("red acme", &schema.SearchRelevance{
+			DropTokensThreshold: intP(2),
+			FieldWeights:        map[string]int{"brand": 10},
+		}, "name", "brand"))
+
+		require.ElementsMatch(t, []string{"1", "3", "4"}, search("red hat", nil))
+		require.Empty(t, search("red hat", &schema.SearchRelevance{DropTokensThreshold: intP(0)}))
+	})
+
+	t.Run("analyzers", func(t *testing.T) {
+		noPrefix := false
+		search := func(q string, analyzers map[string]*schema.FieldAnalyzer, fields ...string) []Hit {
+			result, err := s.Search(ctx, "products", qsearch.NewBuilder().Query(q).SearchFields(fields).
+				Relevance(&schema.SearchRelevance{Prefix: &noPrefix}).Analyzers(analyzers).Filter(filter.WrappedEmptyFilter).PageSize(10).Build(), 1)
+			require.NoError(t, err)
+			return result[0].Hits
+		}
+		ids := func(hits []Hit) []string {
+			var ids []string
+			for _, hit := range hits {
+				ids = append(ids, hit.Document["id"].(string))
+			}
+			return ids
+		}
+
+		english := map[string]*schema.FieldAnalyzer{"name": {Type: schema.AnalyzerLanguage, Language: "en"}}
+		require.Empty(t, search("shoe", nil, "name"))
+		require.Equal(t, []string{"1"}, ids(search("shoe", english, "name")))
+		require.ElementsMatch(t, []string{"1", "2"}, ids(search("runs", english, "name")))
+
+		keyword := map[string]*schema.FieldAnalyzer{"brand": {Type: schema.AnalyzerKeyword}}
+		require.ElementsMatch(t, []string{"1", "2"}, ids(search("ACME", keyword, "brand")))
+		require.Empty(t, search("acme corp", keyword, "brand"))
+
+		ngram := map[string]*schema.FieldAnalyzer{"name": {Type: schema.AnalyzerNGram}}
+		require.ElementsMatch(t, []string{"1", "2"}, ids(search("unnin", ngram, "name")))
+
+		edge := map[string]*schema.FieldAnalyzer{"name": {Type: schema.AnalyzerEdgeNGram}}
+		require.ElementsMatch(t, []string{"1", "2"}, ids(search("run", edge, "name")))
+		require.Empty(t, search("unnin", edge, "name"))
+
+		// the fields with a matched term are returned
+		hits := search("shoe", english, "name")
+		require.Equal(t, []string{"name"}, hits[0].MatchedFields)
+	})
+
+	t.Run("curation", func(t *testing.T) {
+		query := qsearch.NewBuilder().
+			Query("red").
+			Filter(filter.WrappedEmptyFilter).
+			PinnedHits([]qsearch.PinnedHit{{Id: "2", Position: 10}, {Id: "4", Position: 1}, {Id: "unknown", Position: 2}}).
+			HiddenHits([]string{"3"}).
+			PageSize(10).
+			Build()
+
+		result, err := s.Search(ctx, "products", query, 1)
+		require.NoError(t, err)
+		require.Equal(t, []string{"4", "1", "2"}, embeddedHitIds(t, result))
+		require.Equal(t, 3, result[0].Found)
+	})
+
+	t.Run("sort_and_page", func(t *testing.T) {
+		query := qsearch.NewBuilder().
+			Filter(filter.WrappedEmptyFilter).
+			SortOrder(&sort.Ordering{{Name: "price", Ascending: false}}).
+			PageSize(2).
+			Build()
+
+		result, err := s.Search(ctx, "products", query, 1)
+		require.NoError(t, err)
+		require.Equal(t, []string{"2", "3"}, embeddedHitIds(t, result))
+		require.Equal(t, 4, result[0].Found)
+
+		result, err = s.Search(ctx, "products", query, 2)
+		require.NoError(t, err)
+		require.Equal(t, []string{"1", "4"}, embeddedHitIds(t, result))
+
+		result, err = s.Search(ctx, "products", query, 3)
+		require.NoError(t, err)
+		require.Empty(t, embeddedHitIds(t, result))
+	})
+
+	t.Run("facets", func(t *testing.T) {
+		query := qsearch.NewBuilder().
+			Filter(filter.WrappedEmptyFilter).
+			Facets(qsearch.Facets{Fields: []qsearch.FacetField{{Name: "brand", Size: 10}, {Name: "price", Size: 1}}}).
+			PageSize(10).
+			Build()
+
+		result, err := s.Search(ctx, "products", query, 1)
+		require.NoError(t, err)
+
+		facets := result[0].Facets
+		require.Len(t, facets, 2)
+		require.Equal(t, "brand", facets[0].FieldName)
+		require.Len(t, facets[0].Counts, 2)
+		require.Equal(t, 2, facets[0].Counts[0].Count)
+		require.Nil(t, facets[0].Stats.Sum)
+
+		require.Equal(t, 250.0, *facets[1].Stats.Sum)
+		require.Equal(t, 120.0, *facets[1].Stats.Max)
+		require.Equal(t, 3, facets[1].Stats.TotalValues)
+	})
+
+	t.Run("group_by", func(t *testing.T) {
+		limit := int64(1)
+		query := qsearch.NewBuilder().
+			Filter(filter.WrappedEmptyFilter).
+			GroupBy(qsearch.GroupBy{Fields: []string{"brand"}, Limit: &limit}).
+			PageSize(10).
+			Build()
+
+		result, err := s.Search(ctx, "products", query, 1)
+		require.NoError(t, err)
+		require.Equal(t, 2, result[0].Found)
+
+		groups := result[0].GroupedHits
+		require.Equal(t, []string{"acme"}, groups[0].GroupKey)
+		require.Len(t, groups[0].Hits, 1)
+		require.Equal(t, []string{"bolt"}, groups[1].GroupKey)
+	})
+
+	t.Run("vector", func(t *testing.T) {
+		query := qsearch.NewBuilder().
+			Filter(filter.WrappedEmptyFilter).
+			VectorSearch(qsearch.VectorSearch{VectorF: "vec", VectorV: []float64{1, 0}, TopK: 2}).
+			PageSize(10).
+			Build()
+
+		result, err := s.Search(ctx, "products", query, 1)
+		require.NoError(t, err)
+		require.Equal(t, []string{"1", "3"}, embeddedHitIds(t, result))
+		require.InDelta(t, 0, *result[0].Hits[0].VectorDistance, 0.0001)
+
+		query.VectorS.VectorV = []float64{1, 0, 0}
+		_, err = s.Search(ctx, "products", query, 1)
+		require.Error(t, err)
+	})
+
+	t.Run("hybrid", func(t *testing.T) {
+		query := qsearch.NewBuilder().
+			Query("running").
+			Filter(filter.WrappedEmptyFilter).
+			VectorSearch(qsearch.VectorSearch{VectorF: "vec", VectorV: []float64{0, 1}}).
+			Hybrid(&qsearch.HybridSearch{Fusion: qsearch.FusionRRF, RankConstant: 60}).
+			PageSize(3).
+			Build()
+
+		result, err := s.Search(ctx, "products", query, 1)
+		require.NoError(t, err)
+		require.Equal(t, []string{"2", "1", "4"}, embeddedHitIds(t, result))
+		require.Equal(t, 4, result[0].Found)
+
+		hits := result[0].Hits
+		require.NotNil(t, hits[0].TextMatch)
+		require.NotNil(t, hits[0].VectorDistance)
+		require.Nil(t, hits[2].TextMatch)
+
+		result, err = s.Search(ctx, "products", query, 2)
+		require.NoError(t, err)
+		require.Equal(t, []string{"3"}, embeddedHitIds(t, result))
+	})
+
+	t.Run("documents", func(t *testing.T) {
+		resp, err := s.IndexDocuments(ctx, "products", strings.NewReader(`{"id":"4","price":10}`), IndexDocumentsOptions{Action: Update})
+		require.NoError(t, err)
+		require.True(t, resp[0].Success, resp[0].Error)
+
+		result, err := s.GetDocuments(ctx, "products", []string{"4", "10"})
+		require.NoError(t, err)
+		require.Len(t, result.Hits, 1)
+		doc := result.Hits[0].Document
+		require.Equal(t, "Green hat", doc["name"])
+		require.Equal(t, json.Number("10"), doc["price"])
+
+		// the returned document is a copy
+		doc["name"] = "changed"
+		result, err = s.GetDocuments(ctx, "products", []string{"4"})
+		require.NoError(t, err)
+		require.Equal(t, "Green hat", result.Hits[0].Document["name"])
+
+		require.NoError(t, s.DeleteDocument(ctx, "products", "4"))
+		require.True(t, IsErrNotFound(s.DeleteDocument(ctx, "products", "4")))
+
+		count, err := s.DeleteDocuments(ctx, "products", filter.WrappedEmptyFilter)
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+
+		require.NoError(t, s.DropCollection(ctx, "products"))
+		_, err = s.Search(ctx, "products", qsearch.NewBuilder().Filter(filter.WrappedEmptyFilter).Build(), 1)
+		require.True(t, IsErrNotFound(err))
+	})
+}
+
+func TestEmbeddedStorePersistence(t *testing.T) {
+	ctx := context.TODO()
+	dir := t.TempDir()
+
+	s := newEmbeddedTestStore(t, dir)
+	require.NoError(t, s.DeleteDocument(ctx, "products", "2"))
+	require.NoError(t, s.UpsertSynonym(ctx, "products", Synonym{Id: "red", Synonyms: []string{"red", "scarlet"}}))
+	require.NoError(t, s.CreateCollection(ctx, &schema.SearchStoreSchema{Name: "dropped"}))
+	require.NoError(t, s.DropCollection(ctx, "dropped"))
+
+	reopened, err := NewEmbeddedStore(dir)
+	require.NoError(t, err)
+
+	all, err := reopened.AllCollections(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	require.Equal(t, int64(3), all["products"].NumDocuments)
+
+	result, err := reopened.Search(ctx, "products", qsearch.NewBuilder().Query("scarlet").Filter(filter.WrappedEmptyFilter).PageSize(10).Build(), 1)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"1", "3"}, embeddedHitIds(t, result))
+
+	// compaction keeps the documents
+	for i := 0; i < embeddedCompactThreshold; i++ {
+		require.NoError(t, reopened.CreateDocument(ctx, "products", map[string]any{"id": "tmp", "name": "temporary", "brand": "acme"}))
+		require.NoError(t, reopened.DeleteDocument(ctx, "products", "tmp"))
+	}
+
+	reopened, err = NewEmbeddedStore(dir)
+	require.NoError(t, err)
+	resp, err := reopened.DescribeCollection(ctx, "products")
+	require.NoError(t, err)
+	require.Equal(t, int64(3), resp.NumDocuments)
+
+	result, err = reopened.Search(ctx, "products", qsearch.NewBuilder().Query("scarlet").Filter(filter.WrappedEmptyFilter).PageSize(10).Build(), 1)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"1", "3"}, embeddedHitIds(t, result))
+}
@@ -0,0 +1,215 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	qsearch "github.com/tigrisdata/tigris/query/search"
+	"golang.org/x/exp/slices"
+)
+
+// hybridCandidates is the number of documents read from each of the keyword and the vector rankings before these are
+// fused, the pages of a hybrid search can't go beyond it.
+const hybridCandidates = 250
+
+type searchFunc func(ctx context.Context, table string, query *qsearch.Query, pageNo int) ([]Result, error)
+
+// hybridSearch runs the keyword and the vector search of the query separately and fuses both rankings. The facets are
+// the ones of the keyword search. A fused hit has the text match of the keyword hit and the vector distance of the
+// vector hit. The pinned and hidden documents are applied to the fused ranking, the positions of the pinned documents
+// would otherwise be lost by the fusion.
+func hybridSearch(ctx context.Context, table string, query *qsearch.Query, pageNo int, search searchFunc) ([]Result, error) {
+	if (pageNo-1)*query.PageSize >= hybridCandidates {
+		return nil, NewSearchError(http.StatusBadRequest, ErrCodeInvalid,
+			"hybrid search only returns the first '%d' results, page '%d' is out of range", hybridCandidates, pageNo)
+	}
+
+	// the keyword search keeps the pinned documents so that these are read along with the candidates
+	keywordQ := *query
+	keywordQ.Hybrid = nil
+	keywordQ.VectorS = qsearch.VectorSearch{}
+	keywordQ.HiddenHits = nil
+	keywordQ.PageSize = hybridCandidates
+
+	vectorQ := *query
+	vectorQ.Hybrid = nil
+	vectorQ.Q = "*"
+	vectorQ.Facets = qsearch.Facets{}
+	vectorQ.PinnedHits = nil
+	vectorQ.HiddenHits = nil
+	vectorQ.PageSize = hybridCandidates
+	if vectorQ.VectorS.TopK <= 0 || vectorQ.VectorS.TopK > hybridCandidates {
+		vectorQ.VectorS.TopK = hybridCandidates
+	}
+
+	keyword, err := search(ctx, table, &keywordQ, 1)
+	if err != nil {
+		return nil, err
+	}
+	vector, err := search(ctx, table, &vectorQ, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := curateHits(query, fuseRankings(query.Hybrid, resultHits(keyword), resultHits(vector)))
+	result := Result{
+		Found: len(hits),
+		Hits:  paginate(hits, pageNo, query.PageSize),
+		Page:  pageNo,
+	}
+	if len(keyword) > 0 {
+		result.Facets = keyword[0].Facets
+		result.OutOf = keyword[0].OutOf
+	}
+
+	return []Result{result}, nil
+}
+
+// curateHits removes the hidden documents from the fused ranking and moves the pinned documents to their positions. A
+// pinned document is only returned if one of the searches returned it, i.e. if it matches the filter.
+func curateHits(query *qsearch.Query, hits []Hit) []Hit {
+	if len(query.PinnedHits) == 0 && len(query.HiddenHits) == 0 {
+		return hits
+	}
+
+	excluded := make(map[string]struct{})
+	for _, id := range query.HiddenHits {
+		excluded[id] = struct{}{}
+	}
+	for _, p := range query.PinnedHits {
+		excluded[p.Id] = struct{}{}
+	}
+
+	pinnedHits := make(map[string]Hit)
+	curated := make([]Hit, 0, len(hits))
+	for _, hit := range hits {
+		id := hitID(hit)
+		if _, ok := excluded[id]; !ok {
+			curated = append(curated, hit)
+		} else {
+			pinnedHits[id] = hit
+		}
+	}
+
+	pinned := append([]qsearch.PinnedHit(nil), query.PinnedHits...)
+	sort.SliceStable(pinned, func(i, j int) bool {
+		return pinned[i].Position < pinned[j].Position
+	})
+	for _, p := range pinned {
+		hit, ok := pinnedHits[p.Id]
+		if !ok {
+			continue
+		}
+
+		pos := p.Position - 1
+		if pos < 0 {
+			pos = 0
+		}
+		if pos > len(curated) {
+			pos = len(curated)
+		}
+		curated = slices.Insert(curated, pos, hit)
+	}
+
+	return curated
+}
+
+func resultHits(results []Result) []Hit {
+	var hits []Hit
+	for _, r := range results {
+		hits = append(hits, r.Hits...)
+	}
+
+	return hits
+}
+
+type fusedHit struct {
+	hit   Hit
+	score float64
+}
+
+// fuseRankings merges both rankings into one ordered by the fused score, documents with the same score keep the order
+// in which they are first seen in the keyword and then the vector ranking.
+func fuseRankings(hybrid *qsearch.HybridSearch, keyword []Hit, vector []Hit) []Hit {
+	var fused []*fusedHit
+	byID := make(map[string]*fusedHit)
+	get := func(hit Hit) *fusedHit {
+		id := hitID(hit)
+		if f, ok := byID[id]; ok {
+			return f
+		}
+
+		f := &fusedHit{hit: Hit{Document: hit.Document}}
+		fused = append(fused, f)
+		if len(id) > 0 {
+			byID[id] = f
+		}
+		return f
+	}
+
+	var maxTextMatch int64
+	for _, hit := range keyword {
+		if hit.TextMatch != nil && *hit.TextMatch > maxTextMatch {
+			maxTextMatch = *hit.TextMatch
+		}
+	}
+
+	for rank, hit := range keyword {
+		f := get(hit)
+		f.hit.TextMatch = hit.TextMatch
+		f.hit.MatchedFields = hit.MatchedFields
+
+		if hybrid.Fusion == qsearch.FusionAlpha {
+			if hit.TextMatch != nil && maxTextMatch > 0 {
+				f.score += (1 - hybrid.Alpha) * float64(*hit.TextMatch) / float64(maxTextMatch)
+			}
+		} else {
+			f.score += 1 / float64(hybrid.RankConstant+rank+1)
+		}
+	}
+
+	for rank, hit := range vector {
+		f := get(hit)
+		f.hit.VectorDistance = hit.VectorDistance
+
+		if hybrid.Fusion == qsearch.FusionAlpha {
+			if hit.VectorDistance != nil {
+				// the cosine distance is between 0 and 2, the similarity is normalized between 0 and 1.
+				f.score += hybrid.Alpha * (1 - *hit.VectorDistance/2)
+			}
+		} else {
+			f.score += 1 / float64(hybrid.RankConstant+rank+1)
+		}
+	}
+
+	sort.SliceStable(fused, func(i, j int) bool {
+		return fused[i].score > fused[j].score
+	})
+
+	hits := make([]Hit, len(fused))
+	for i, f := range fused {
+		hits[i] = f.hit
+	}
+
+	return hits
+}
+
+func hitID(hit Hit) string {
+	id, _ := hit.Document[embeddedIDField].(string)
+	return id
+}
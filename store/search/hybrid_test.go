@@ -0,0 +1,113 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	qsearch "github.com/tigrisdata/tigris/query/search"
+)
+
+func TestFuseRankings(t *testing.T) {
+	hit := func(id string, textMatch int64, distance float64) Hit {
+		h := Hit{Document: map[string]any{"id": id}}
+		if textMatch > 0 {
+			h.TextMatch = &textMatch
+		}
+		if distance >= 0 {
+			h.VectorDistance = &distance
+		}
+		return h
+	}
+	ids := func(hits []Hit) []string {
+		var ids []string
+		for _, h := range hits {
+			ids = append(ids, hitID(h))
+		}
+		return ids
+	}
+
+	keyword := []Hit{hit("a", 100, -1), hit("b", 50, -1), hit("c", 10, -1)}
+	vector := []Hit{hit("c", 0, 0.1), hit("d", 0, 0.2), hit("b", 0, 1)}
+
+	t.Run("rrf", func(t *testing.T) {
+		hits := fuseRankings(&qsearch.HybridSearch{Fusion: qsearch.FusionRRF, RankConstant: 60}, keyword, vector)
+		require.Equal(t, []string{"c", "b", "a", "d"}, ids(hits))
+		require.Equal(t, int64(10), *hits[0].TextMatch)
+		require.Equal(t, 0.1, *hits[0].VectorDistance)
+		require.Nil(t, hits[2].VectorDistance)
+		require.Nil(t, hits[3].TextMatch)
+	})
+
+	t.Run("alpha", func(t *testing.T) {
+		hits := fuseRankings(&qsearch.HybridSearch{Fusion: qsearch.FusionAlpha, Alpha: 0}, keyword, vector)
+		require.Equal(t, []string{"a", "b", "c", "d"}, ids(hits))
+
+		hits = fuseRankings(&qsearch.HybridSearch{Fusion: qsearch.FusionAlpha, Alpha: 1}, keyword, vector)
+		require.Equal(t, []string{"c", "d", "b", "a"}, ids(hits))
+
+		hits = fuseRankings(&qsearch.HybridSearch{Fusion: qsearch.FusionAlpha, Alpha: 0.5}, keyword, vector)
+		require.Equal(t, []string{"c", "a", "b", "d"}, ids(hits))
+	})
+}
+
+func TestHybridSearchCuration(t *testing.T) {
+	doc := func(id string) Hit {
+		return Hit{Document: map[string]any{"id": id}}
+	}
+	result := func(hits ...Hit) []Result {
+		return []Result{{Found: len(hits), Hits: hits}}
+	}
+
+	var searched []*qsearch.Query
+	search := func(_ context.Context, _ string, query *qsearch.Query, _ int) ([]Result, error) {
+		searched = append(searched, query)
+		if query.IsVectorSearch() {
+			return result(doc("c"), doc("d"), doc("a")), nil
+		}
+		// the pinned document is returned at its position by the keyword search
+		return result(doc("a"), doc("e"), doc("b"), doc("c")), nil
+	}
+
+	query := qsearch.NewBuilder().
+		Query("shoe").
+		VectorSearch(qsearch.VectorSearch{VectorF: "v", VectorV: []float64{1}}).
+		Hybrid(&qsearch.HybridSearch{Fusion: qsearch.FusionRRF, RankConstant: 60}).
+		PinnedHits([]qsearch.PinnedHit{{Id: "e", Position: 1}}).
+		HiddenHits([]string{"c"}).
+		PageSize(10).
+		Build()
+
+	results, err := hybridSearch(context.TODO(), "t1", query, 1, search)
+	require.NoError(t, err)
+	require.Len(t, searched, 2)
+	for _, q := range searched {
+		require.Empty(t, q.HiddenHits)
+	}
+	require.Empty(t, searched[1].PinnedHits)
+
+	var ids []string
+	for _, h := range results[0].Hits {
+		ids = append(ids, hitID(h))
+	}
+	require.Equal(t, []string{"e", "a", "d", "b"}, ids)
+	require.Equal(t, 4, results[0].Found)
+
+	// the pages beyond the fused candidates are rejected instead of being empty
+	_, err = hybridSearch(context.TODO(), "t1", query, hybridCandidates/10+1, search)
+	require.Error(t, err)
+}
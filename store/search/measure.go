@@ -23,33 +23,47 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/tigrisdata/tigris/query/filter"
 	qsearch "github.com/tigrisdata/tigris/query/search"
+	"github.com/tigrisdata/tigris/schema"
 	"github.com/tigrisdata/tigris/server/config"
 	"github.com/tigrisdata/tigris/server/metrics"
 	"github.com/tigrisdata/typesense-go/typesense"
-	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
 )
 
 type storeImplWithMetrics struct {
 	s Store
 }
 
-func NewStore(config *config.SearchConfig, withMetrics bool) Store {
-	client := typesense.NewClient(
-		typesense.WithServer(fmt.Sprintf("http://%s", net.JoinHostPort(config.Host, fmt.Sprintf("%d", config.Port)))),
-		typesense.WithAPIKey(config.AuthKey))
-	log.Info().Str("host", config.Host).Int16("port", config.Port).Msg("initialized search store")
-
-	store := &storeImpl{
-		client: client,
+// NewStore returns the search store of the backend selected in the config, either an external Typesense server or the
+// embedded store.
+func NewStore(cfg *config.SearchConfig, withMetrics bool) (Store, error) {
+	var store Store
+	switch cfg.Backend {
+	case config.SearchBackendEmbedded:
+		var err error
+		if store, err = NewEmbeddedStore(cfg.Embedded.Dir); err != nil {
+			return nil, err
+		}
+		log.Info().Str("dir", cfg.Embedded.Dir).Msg("initialized embedded search store")
+	case config.SearchBackendTypesense, "":
+		client := typesense.NewClient(
+			typesense.WithServer(fmt.Sprintf("http://%s", net.JoinHostPort(cfg.Host, fmt.Sprintf("%d", cfg.Port)))),
+			typesense.WithAPIKey(cfg.AuthKey))
+		log.Info().Str("host", cfg.Host).Int16("port", cfg.Port).Msg("initialized search store")
+
+		store = &storeImpl{
+			client: client,
+		}
+	default:
+		return nil, fmt.Errorf("unknown search backend '%s'", cfg.Backend)
 	}
 
 	if withMetrics {
 		return &storeImplWithMetrics{
 			store,
-		}
+		}, nil
 	}
 
-	return store
+	return store, nil
 }
 
 func (*storeImplWithMetrics) measure(ctx context.Context, name string, f func(ctx context.Context) error) {
@@ -70,7 +84,7 @@ func (*storeImplWithMetrics) measure(ctx context.Context, name string, f func(ct
 	}
 }
 
-func (m *storeImplWithMetrics) AllCollections(ctx context.Context) (resp map[string]*tsApi.CollectionResponse, err error) {
+func (m *storeImplWithMetrics) AllCollections(ctx context.Context) (resp map[string]*CollectionInfo, err error) {
 	m.measure(ctx, "AllCollections", func(ctx context.Context) error {
 		resp, err = m.s.AllCollections(ctx)
 		return err
@@ -78,7 +92,7 @@ func (m *storeImplWithMetrics) AllCollections(ctx context.Context) (resp map[str
 	return
 }
 
-func (m *storeImplWithMetrics) DescribeCollection(ctx context.Context, name string) (resp *tsApi.CollectionResponse, err error) {
+func (m *storeImplWithMetrics) DescribeCollection(ctx context.Context, name string) (resp *CollectionInfo, err error) {
 	m.measure(ctx, "DescribeCollection", func(ctx context.Context) error {
 		resp, err = m.s.DescribeCollection(ctx, name)
 		return err
@@ -86,10 +100,10 @@ func (m *storeImplWithMetrics) DescribeCollection(ctx context.Context, name stri
 	return
 }
 
-func (m *storeImplWithMetrics) CreateCollection(ctx context.Context, schema *tsApi.CollectionSchema) (err error) {
+func (m *storeImplWithMetrics) CreateCollection(ctx context.Context, storeSchema *schema.SearchStoreSchema) (err error) {
 	reqStatus, reqStatusExists := metrics.RequestStatusFromContext(ctx)
 	m.measure(ctx, "CreateCollection", func(ctx context.Context) error {
-		err = m.s.CreateCollection(ctx, schema)
+		err = m.s.CreateCollection(ctx, storeSchema)
 		return err
 	})
 	if reqStatus != nil && reqStatusExists {
@@ -98,10 +112,10 @@ func (m *storeImplWithMetrics) CreateCollection(ctx context.Context, schema *tsA
 	return
 }
 
-func (m *storeImplWithMetrics) UpdateCollection(ctx context.Context, name string, schema *tsApi.CollectionUpdateSchema) (err error) {
+func (m *storeImplWithMetrics) UpdateCollection(ctx context.Context, name string, fields []schema.SearchStoreField) (err error) {
 	// TODO: measure the bytes written in global status
 	m.measure(ctx, "UpdateCollection", func(ctx context.Context) error {
-		err = m.s.UpdateCollection(ctx, name, schema)
+		err = m.s.UpdateCollection(ctx, name, fields)
 		return err
 	})
 	return
@@ -152,7 +166,7 @@ func (m *storeImplWithMetrics) DeleteDocuments(ctx context.Context, table string
 	return
 }
 
-func (m *storeImplWithMetrics) Search(ctx context.Context, table string, query *qsearch.Query, pageNo int) (result []tsApi.SearchResult, err error) {
+func (m *storeImplWithMetrics) Search(ctx context.Context, table string, query *qsearch.Query, pageNo int) (result []Result, err error) {
 	reqStatus, reqStatusExists := metrics.RequestStatusFromContext(ctx)
 	m.measure(ctx, "Search", func(ctx context.Context) error {
 		result, err = m.s.Search(ctx, table, query, pageNo)
@@ -169,15 +183,13 @@ func (m *storeImplWithMetrics) Search(ctx context.Context, table string, query *
 			}
 		}
 		for _, res := range result {
-			if res.Hits != nil {
-				reqStatus.AddResultDocs(int64(len(*res.Hits)))
-			}
+			reqStatus.AddResultDocs(int64(len(res.Hits)))
 		}
 	}
 	return
 }
 
-func (m *storeImplWithMetrics) GetDocuments(ctx context.Context, table string, ids []string) (result *tsApi.SearchResult, err error) {
+func (m *storeImplWithMetrics) GetDocuments(ctx context.Context, table string, ids []string) (result *Result, err error) {
 	reqStatus, reqStatusExists := metrics.RequestStatusFromContext(ctx)
 	m.measure(ctx, "Get", func(ctx context.Context) error {
 		result, err = m.s.GetDocuments(ctx, table, ids)
@@ -202,3 +214,19 @@ func (m *storeImplWithMetrics) CreateDocument(ctx context.Context, table string,
 	})
 	return
 }
+
+func (m *storeImplWithMetrics) UpsertSynonym(ctx context.Context, table string, synonym Synonym) (err error) {
+	m.measure(ctx, "UpsertSynonym", func(ctx context.Context) error {
+		err = m.s.UpsertSynonym(ctx, table, synonym)
+		return err
+	})
+	return
+}
+
+func (m *storeImplWithMetrics) DeleteSynonym(ctx context.Context, table string, id string) (err error) {
+	m.measure(ctx, "DeleteSynonym", func(ctx context.Context) error {
+		err = m.s.DeleteSynonym(ctx, table, id)
+		return err
+	})
+	return
+}
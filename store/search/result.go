@@ -0,0 +1,78 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"github.com/tigrisdata/tigris/schema"
+)
+
+// CollectionInfo describes an index of the search store.
+type CollectionInfo struct {
+	Name         string
+	Fields       []schema.SearchStoreField
+	NumDocuments int64
+	CreatedAt    int64
+}
+
+// Result is a page of the documents matching a search query.
+type Result struct {
+	// Found is the number of documents matching the query.
+	Found int
+	// OutOf is the number of documents in the index.
+	OutOf int
+	Page  int
+	// Hits are the matching documents of the page, it is empty for a group by query.
+	Hits []Hit
+	// GroupedHits are the matching documents of the page grouped by the group by fields.
+	GroupedHits []GroupedHit
+	Facets      []FacetCounts
+}
+
+// Hit is a document matching a search query along with how it matched.
+type Hit struct {
+	Document map[string]any
+	// MatchedFields are the fields of the document that matched the text query.
+	MatchedFields []string
+	// TextMatch is the text relevance of the document, it is only comparable with the other hits of the same search.
+	TextMatch *int64
+	// VectorDistance is the distance to the query vector, it is only set for a vector query.
+	VectorDistance *float64
+}
+
+type GroupedHit struct {
+	GroupKey []string
+	Hits     []Hit
+}
+
+// FacetCounts are the counts of the values of a faceted field, sorted by count.
+type FacetCounts struct {
+	FieldName string
+	Counts    []FacetCount
+	// Stats is only set for a numeric field.
+	Stats *FacetStats
+}
+
+type FacetCount struct {
+	Value string
+	Count int
+}
+
+type FacetStats struct {
+	Avg         *float64
+	Max         *float64
+	Min         *float64
+	Sum         *float64
+	TotalValues int
+}
@@ -20,7 +20,7 @@ import (
 
 	"github.com/tigrisdata/tigris/query/filter"
 	qsearch "github.com/tigrisdata/tigris/query/search"
-	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
+	"github.com/tigrisdata/tigris/schema"
 )
 
 type IndexAction string
@@ -31,15 +31,30 @@ var (
 	Update  IndexAction = "update"
 )
 
+// Synonym is a group of terms that the search considers equivalent. A one-way synonym has a root, a search for the root
+// also matches the synonyms but a search for a synonym doesn't match the root.
+type Synonym struct {
+	Id       string   `json:"id"`
+	Root     string   `json:"root,omitempty"`
+	Synonyms []string `json:"synonyms"`
+}
+
+// StopwordSet is a named list of words that are removed from the search queries.
+type StopwordSet struct {
+	Id        string   `json:"id"`
+	Stopwords []string `json:"stopwords"`
+}
+
 type Store interface {
 	// AllCollections is to describe all search indexes.
-	AllCollections(ctx context.Context) (map[string]*tsApi.CollectionResponse, error)
+	AllCollections(ctx context.Context) (map[string]*CollectionInfo, error)
 	// DescribeCollection is to describe a search index.
-	DescribeCollection(ctx context.Context, name string) (*tsApi.CollectionResponse, error)
+	DescribeCollection(ctx context.Context, name string) (*CollectionInfo, error)
 	// CreateCollection is to create a search index.
-	CreateCollection(ctx context.Context, schema *tsApi.CollectionSchema) error
-	// UpdateCollection is to update the search index.
-	UpdateCollection(ctx context.Context, name string, schema *tsApi.CollectionUpdateSchema) error
+	CreateCollection(ctx context.Context, storeSchema *schema.SearchStoreSchema) error
+	// UpdateCollection is to update the search index. The fields are added to the index, a field with drop set is
+	// removed from it.
+	UpdateCollection(ctx context.Context, name string, fields []schema.SearchStoreField) error
 	// DropCollection is to drop the search index.
 	DropCollection(ctx context.Context, table string) error
 	// CreateDocument is to create and index a single document
@@ -51,22 +66,26 @@ type Store interface {
 	// DeleteDocuments is to delete multiple documents using filter.
 	DeleteDocuments(ctx context.Context, table string, filter *filter.WrappedFilter) (int, error)
 	// Search is to search using Query.
-	Search(ctx context.Context, table string, query *qsearch.Query, pageNo int) ([]tsApi.SearchResult, error)
+	Search(ctx context.Context, table string, query *qsearch.Query, pageNo int) ([]Result, error)
 	// GetDocuments is to get a single or multiple documents by id.
-	GetDocuments(ctx context.Context, table string, ids []string) (*tsApi.SearchResult, error)
+	GetDocuments(ctx context.Context, table string, ids []string) (*Result, error)
+	// UpsertSynonym is to create or replace a synonym of the search index.
+	UpsertSynonym(ctx context.Context, table string, synonym Synonym) error
+	// DeleteSynonym is to delete a synonym of the search index.
+	DeleteSynonym(ctx context.Context, table string, id string) error
 }
 
 type NoopStore struct{}
 
-func (*NoopStore) AllCollections(context.Context) (map[string]*tsApi.CollectionResponse, error) {
+func (*NoopStore) AllCollections(context.Context) (map[string]*CollectionInfo, error) {
 	return nil, nil
 }
 
-func (*NoopStore) DescribeCollection(context.Context, string) (*tsApi.CollectionResponse, error) {
-	return &tsApi.CollectionResponse{}, nil
+func (*NoopStore) DescribeCollection(context.Context, string) (*CollectionInfo, error) {
+	return &CollectionInfo{}, nil
 }
-func (*NoopStore) CreateCollection(context.Context, *tsApi.CollectionSchema) error { return nil }
-func (*NoopStore) UpdateCollection(context.Context, string, *tsApi.CollectionUpdateSchema) error {
+func (*NoopStore) CreateCollection(context.Context, *schema.SearchStoreSchema) error { return nil }
+func (*NoopStore) UpdateCollection(context.Context, string, []schema.SearchStoreField) error {
 	return nil
 }
 func (*NoopStore) DropCollection(context.Context, string) error { return nil }
@@ -78,14 +97,17 @@ func (*NoopStore) DeleteDocuments(context.Context, string, *filter.WrappedFilter
 	return 0, nil
 }
 
-func (*NoopStore) Search(context.Context, string, *qsearch.Query, int) ([]tsApi.SearchResult, error) {
+func (*NoopStore) Search(context.Context, string, *qsearch.Query, int) ([]Result, error) {
 	return nil, nil
 }
 
-func (*NoopStore) GetDocuments(_ context.Context, _ string, _ []string) (*tsApi.SearchResult, error) {
+func (*NoopStore) GetDocuments(_ context.Context, _ string, _ []string) (*Result, error) {
 	return nil, nil
 }
 
 func (*NoopStore) CreateDocument(_ context.Context, _ string, _ map[string]any) error {
 	return nil
 }
+
+func (*NoopStore) UpsertSynonym(context.Context, string, Synonym) error { return nil }
+func (*NoopStore) DeleteSynonym(context.Context, string, string) error  { return nil }
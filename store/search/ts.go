@@ -20,11 +20,13 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strconv"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/rs/zerolog/log"
 	"github.com/tigrisdata/tigris/query/filter"
 	qsearch "github.com/tigrisdata/tigris/query/search"
+	"github.com/tigrisdata/tigris/schema"
 	"github.com/tigrisdata/tigris/util"
 	ulog "github.com/tigrisdata/tigris/util/log"
 	"github.com/tigrisdata/typesense-go/typesense"
@@ -33,6 +35,9 @@ import (
 
 var maxCandidates = 100
 
+// maxGetDocumentsPerPage is the maximum page size allowed by Typesense.
+const maxGetDocumentsPerPage = 250
+
 type IndexResp struct {
 	Code     int
 	Document string
@@ -115,8 +120,9 @@ func (s *storeImpl) IndexDocuments(_ context.Context, table string, reader io.Re
 }
 
 func (*storeImpl) getBaseSearchParam(table string, query *qsearch.Query, pageNo int) tsApi.MultiSearchCollectionParameters {
+	q := query.ToSearchQ()
 	baseParam := tsApi.MultiSearchCollectionParameters{
-		Q:          &query.Q,
+		Q:          &q,
 		Collection: table,
 		Page:       &pageNo,
 		PerPage:    &query.PageSize,
@@ -143,11 +149,41 @@ func (*storeImpl) getBaseSearchParam(table string, query *qsearch.Query, pageNo
 	if vector := query.ToSearchVector(); len(vector) > 0 {
 		baseParam.VectorQuery = &vector
 	}
+	if pinned := query.ToSearchPinnedHits(); len(pinned) > 0 {
+		baseParam.PinnedHits = &pinned
+	}
+	if hidden := query.ToSearchHiddenHits(); len(hidden) > 0 {
+		baseParam.HiddenHits = &hidden
+	}
+	if weights := query.ToSearchQueryByWeights(); len(weights) > 0 {
+		baseParam.QueryByWeights = &weights
+	}
+	if r := query.Relevance; r != nil {
+		baseParam.NumTypos = r.NumTypos
+		baseParam.MinLen1typo = r.MinLen1Typo
+		baseParam.MinLen2typo = r.MinLen2Typo
+		baseParam.DropTokensThreshold = r.DropTokensThreshold
+		baseParam.PrioritizeExactMatch = r.PrioritizeExactMatch
+		if r.Prefix != nil {
+			prefix := strconv.FormatBool(*r.Prefix)
+			baseParam.Prefix = &prefix
+		}
+	}
+	if prefix := query.ToSearchPrefix(); len(prefix) > 0 {
+		baseParam.Prefix = &prefix
+	}
+	if infix := query.ToSearchInfix(); len(infix) > 0 {
+		baseParam.Infix = &infix
+	}
 
 	return baseParam
 }
 
-func (s *storeImpl) Search(_ context.Context, table string, query *qsearch.Query, pageNo int) ([]tsApi.SearchResult, error) {
+func (s *storeImpl) Search(ctx context.Context, table string, query *qsearch.Query, pageNo int) ([]Result, error) {
+	if query.IsHybridSearch() {
+		return hybridSearch(ctx, table, query, pageNo, s.Search)
+	}
+
 	var params []tsApi.MultiSearchCollectionParameters
 	params = append(params, s.getBaseSearchParam(table, query, pageNo))
 
@@ -170,7 +206,8 @@ func (s *storeImpl) Search(_ context.Context, table string, query *qsearch.Query
 		log.Error().Err(err).Interface("query", query).Msg("search error")
 		return nil, s.convertToInternalError(err)
 	}
-	for _, each := range dest.Results {
+	results := make([]Result, 0, len(dest.Results))
+	for i, each := range dest.Results {
 		if each.Hits == nil && each.GroupedHits == nil {
 			type errResult struct {
 				Code    int    `json:"code"`
@@ -187,42 +224,47 @@ func (s *storeImpl) Search(_ context.Context, table string, query *qsearch.Query
 				return nil, NewSearchError(errorsRes.Res[0].Code, ErrCodeUnhandled, errorsRes.Res[0].Message)
 			}
 		}
+		results = append(results, fromTsResult(&dest.Results[i]))
 	}
 
-	return dest.Results, nil
+	return results, nil
 }
 
-func (s *storeImpl) AllCollections(_ context.Context) (map[string]*tsApi.CollectionResponse, error) {
+func (s *storeImpl) AllCollections(_ context.Context) (map[string]*CollectionInfo, error) {
 	resp, err := s.client.Collections().Retrieve()
 	if err != nil {
 		return nil, s.convertToInternalError(err)
 	}
 
-	respMap := make(map[string]*tsApi.CollectionResponse)
+	respMap := make(map[string]*CollectionInfo)
 	for _, r := range resp {
-		respMap[r.Name] = r
+		respMap[r.Name] = fromTsCollection(r)
 	}
 	return respMap, nil
 }
 
-func (s *storeImpl) DescribeCollection(_ context.Context, name string) (*tsApi.CollectionResponse, error) {
+func (s *storeImpl) DescribeCollection(_ context.Context, name string) (*CollectionInfo, error) {
 	resp, err := s.client.Collection(name).Retrieve()
 	if err != nil {
 		return nil, s.convertToInternalError(err)
 	}
-	return resp, nil
+	return fromTsCollection(resp), nil
 }
 
-func (s *storeImpl) CreateCollection(_ context.Context, schema *tsApi.CollectionSchema) error {
+func (s *storeImpl) CreateCollection(_ context.Context, storeSchema *schema.SearchStoreSchema) error {
 	ptrTrue := true
-	schema.EnableNestedFields = &ptrTrue
-
-	_, err := s.client.Collections().Create(schema)
+	_, err := s.client.Collections().Create(&tsApi.CollectionSchema{
+		Name:               storeSchema.Name,
+		Fields:             toTsFields(storeSchema.Fields),
+		EnableNestedFields: &ptrTrue,
+	})
 	return s.convertToInternalError(err)
 }
 
-func (s *storeImpl) UpdateCollection(_ context.Context, name string, schema *tsApi.CollectionUpdateSchema) error {
-	_, err := s.client.Collection(name).Update(schema)
+func (s *storeImpl) UpdateCollection(_ context.Context, name string, fields []schema.SearchStoreField) error {
+	_, err := s.client.Collection(name).Update(&tsApi.CollectionUpdateSchema{
+		Fields: toTsFields(fields),
+	})
 	return s.convertToInternalError(err)
 }
 
@@ -231,20 +273,56 @@ func (s *storeImpl) DropCollection(_ context.Context, table string) error {
 	return s.convertToInternalError(err)
 }
 
-func (s *storeImpl) GetDocuments(_ context.Context, table string, ids []string) (*tsApi.SearchResult, error) {
-	filterBy := "id: ["
-	for i, id := range ids {
-		if i != 0 {
-			filterBy += ","
-		}
-		filterBy += id
+func (s *storeImpl) UpsertSynonym(_ context.Context, table string, synonym Synonym) error {
+	schema := &tsApi.SearchSynonymSchema{
+		Synonyms: synonym.Synonyms,
+	}
+	if len(synonym.Root) > 0 {
+		schema.Root = &synonym.Root
 	}
-	filterBy += "]"
 
-	res, err := s.client.Collection(table).Documents().Search(&tsApi.SearchCollectionParams{
-		Q:        "*",
-		FilterBy: &filterBy,
-	})
+	_, err := s.client.Collection(table).Synonyms().Upsert(synonym.Id, schema)
+	return s.convertToInternalError(err)
+}
+
+func (s *storeImpl) DeleteSynonym(_ context.Context, table string, id string) error {
+	_, err := s.client.Collection(table).Synonym(id).Delete()
+	return s.convertToInternalError(err)
+}
+
+func (s *storeImpl) GetDocuments(_ context.Context, table string, ids []string) (*Result, error) {
+	res := &Result{}
+	// the ids are fetched in pages of at most maxGetDocumentsPerPage, a search only returns a page of the matches
+	for start := 0; start < len(ids); start += maxGetDocumentsPerPage {
+		end := start + maxGetDocumentsPerPage
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		filterBy := "id: ["
+		for i, id := range ids[start:end] {
+			if i != 0 {
+				filterBy += ","
+			}
+			filterBy += id
+		}
+		filterBy += "]"
+
+		perPage := end - start
+		page, err := s.client.Collection(table).Documents().Search(&tsApi.SearchCollectionParams{
+			Q:        "*",
+			FilterBy: &filterBy,
+			PerPage:  &perPage,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		converted := fromTsResult(page)
+		res.Found += converted.Found
+		res.OutOf = converted.OutOf
+		res.Hits = append(res.Hits, converted.Hits...)
+	}
 
-	return res, err
+	return res, nil
 }
@@ -0,0 +1,253 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"github.com/tigrisdata/tigris/schema"
+	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
+)
+
+const (
+	tsMatchedTokensKey = "matched_tokens"
+)
+
+func toTsFields(fields []schema.SearchStoreField) []tsApi.Field {
+	tsFields := make([]tsApi.Field, 0, len(fields))
+	for _, f := range fields {
+		tsFields = append(tsFields, tsApi.Field{
+			Name:     f.Name,
+			Type:     f.Type,
+			Facet:    f.Facet,
+			Index:    f.Index,
+			Sort:     f.Sort,
+			Optional: f.Optional,
+			Infix:    f.Infix,
+			Locale:   f.Locale,
+			NumDim:   f.NumDim,
+			Drop:     f.Drop,
+		})
+	}
+
+	return tsFields
+}
+
+func fromTsFields(tsFields []tsApi.Field) []schema.SearchStoreField {
+	fields := make([]schema.SearchStoreField, 0, len(tsFields))
+	for _, f := range tsFields {
+		fields = append(fields, schema.SearchStoreField{
+			Name:     f.Name,
+			Type:     f.Type,
+			Facet:    f.Facet,
+			Index:    f.Index,
+			Sort:     f.Sort,
+			Optional: f.Optional,
+			Infix:    f.Infix,
+			Locale:   f.Locale,
+			NumDim:   f.NumDim,
+			Drop:     f.Drop,
+		})
+	}
+
+	return fields
+}
+
+func fromTsCollection(resp *tsApi.CollectionResponse) *CollectionInfo {
+	info := &CollectionInfo{
+		Name:   resp.Name,
+		Fields: fromTsFields(resp.Fields),
+	}
+	if resp.NumDocuments != nil {
+		info.NumDocuments = *resp.NumDocuments
+	}
+	if resp.CreatedAt != nil {
+		info.CreatedAt = *resp.CreatedAt
+	}
+
+	return info
+}
+
+func fromTsResult(tsResult *tsApi.SearchResult) Result {
+	var result Result
+	if tsResult.Found != nil {
+		result.Found = *tsResult.Found
+	}
+	if tsResult.OutOf != nil {
+		result.OutOf = *tsResult.OutOf
+	}
+	if tsResult.Page != nil {
+		result.Page = *tsResult.Page
+	}
+	if tsResult.Hits != nil {
+		result.Hits = fromTsHits(*tsResult.Hits)
+	}
+	if tsResult.GroupedHits != nil {
+		for _, g := range *tsResult.GroupedHits {
+			result.GroupedHits = append(result.GroupedHits, GroupedHit{
+				GroupKey: g.GroupKey,
+				Hits:     fromTsHits(g.Hits),
+			})
+		}
+	}
+	if tsResult.FacetCounts != nil {
+		result.Facets = fromTsFacets(*tsResult.FacetCounts)
+	}
+
+	return result
+}
+
+func fromTsHits(tsHits []tsApi.SearchResultHit) []Hit {
+	hits := make([]Hit, 0, len(tsHits))
+	for i := range tsHits {
+		hits = append(hits, fromTsHit(&tsHits[i]))
+	}
+
+	return hits
+}
+
+func fromTsHit(tsHit *tsApi.SearchResultHit) Hit {
+	hit := Hit{
+		TextMatch:      tsHit.TextMatch,
+		VectorDistance: tsHit.VectorDistance,
+	}
+	if tsHit.Document != nil {
+		hit.Document = *tsHit.Document
+	}
+
+	if tsHit.Highlight != nil {
+		// check first in highlight
+		hit.MatchedFields = fromTsHighlight(*tsHit.Highlight)
+	} else if tsHit.Highlights != nil {
+		hit.MatchedFields = fromTsHighlights(*tsHit.Highlights)
+	}
+
+	return hit
+}
+
+func fromTsHighlights(highlights []tsApi.SearchHighlight) []string {
+	var fields []string
+	for _, f := range highlights {
+		name := ""
+		if f.Field != nil {
+			name = *f.Field
+		}
+		fields = append(fields, name)
+	}
+
+	return fields
+}
+
+func fromTsHighlight(highlight map[string]any) []string {
+	var fields []string
+	for name, value := range highlight {
+		if mp, ok := value.(map[string]any); ok {
+			// any non array match should just fall in this "if"
+			if isTsMatchedToken(mp) {
+				fields = append(fields, name)
+			}
+			continue
+		}
+
+		if mArr, ok := value.([]any); ok {
+			for _, each := range mArr {
+				if mp, ok := each.(map[string]any); ok {
+					if isTsMatchedToken(mp) {
+						// simple array
+						fields = append(fields, name)
+						break
+					}
+
+					// now this means we can have an array of objects, we iterate one level
+					// to build matched fields
+					fields = append(fields, matchedForTsArrObjects(name, mp)...)
+				}
+			}
+		}
+	}
+
+	return fields
+}
+
+func matchedForTsArrObjects(parent string, obj map[string]any) []string {
+	var fields []string
+	for k, v := range obj {
+		if mpNested, ok := v.(map[string]any); ok {
+			// nested element in an array of object is not an array
+			if isTsMatchedToken(mpNested) {
+				fields = append(fields, parent+"."+k)
+			}
+		} else if mpNestedArr, ok := v.([]any); ok {
+			// nested element in an array of object is an array
+			for _, eachNestedArr := range mpNestedArr {
+				if eachNested, ok := eachNestedArr.(map[string]any); ok {
+					if isTsMatchedToken(eachNested) {
+						fields = append(fields, parent+"."+k)
+						break
+					}
+				}
+			}
+		}
+	}
+
+	return fields
+}
+
+func isTsMatchedToken(mp map[string]any) bool {
+	if matched, found := mp[tsMatchedTokensKey]; found {
+		if matchedSlice, ok := matched.([]any); ok {
+			return len(matchedSlice) > 0
+		}
+	}
+
+	return false
+}
+
+// fromTsFacets converts the facets, a facet without field name and the counts of the null values are skipped.
+func fromTsFacets(tsFacets []tsApi.FacetCounts) []FacetCounts {
+	facets := make([]FacetCounts, 0, len(tsFacets))
+	for _, fc := range tsFacets {
+		if fc.FieldName == nil {
+			continue
+		}
+
+		facet := FacetCounts{FieldName: *fc.FieldName}
+		if fc.Counts != nil {
+			for _, c := range *fc.Counts {
+				if c.Value == nil {
+					continue
+				}
+
+				count := FacetCount{Value: *c.Value}
+				if c.Count != nil {
+					count.Count = *c.Count
+				}
+				facet.Counts = append(facet.Counts, count)
+			}
+		}
+		if fc.Stats != nil {
+			facet.Stats = &FacetStats{
+				Avg: fc.Stats.Avg,
+				Max: fc.Stats.Max,
+				Min: fc.Stats.Min,
+				Sum: fc.Stats.Sum,
+			}
+			if fc.Stats.TotalValues != nil {
+				facet.Stats.TotalValues = *fc.Stats.TotalValues
+			}
+		}
+		facets = append(facets, facet)
+	}
+
+	return facets
+}
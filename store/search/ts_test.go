@@ -0,0 +1,215 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/typesense-go/typesense"
+	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
+)
+
+func TestGetDocuments(t *testing.T) {
+	var perPages []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/collections/t1/documents/search", r.URL.Path)
+
+		// like Typesense, only a page of the matches is returned, 10 by default
+		perPage := 10
+		if p := r.URL.Query().Get("per_page"); p != "" {
+			var err error
+			perPage, err = strconv.Atoi(p)
+			require.NoError(t, err)
+		}
+		perPages = append(perPages, perPage)
+
+		filterBy := r.URL.Query().Get("filter_by")
+		ids := strings.Split(strings.TrimSuffix(strings.TrimPrefix(filterBy, "id: ["), "]"), ",")
+
+		hits := []tsApi.SearchResultHit{}
+		for i := 0; i < len(ids) && i < perPage; i++ {
+			hits = append(hits, tsApi.SearchResultHit{Document: &map[string]interface{}{"id": ids[i]}})
+		}
+		found := len(ids)
+
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(tsApi.SearchResult{Found: &found, Hits: &hits}))
+	}))
+	defer srv.Close()
+
+	s := &storeImpl{client: typesense.NewClient(typesense.WithServer(srv.URL))}
+
+	var ids []string
+	for i := 0; i < 300; i++ {
+		ids = append(ids, fmt.Sprintf("id%d", i))
+	}
+
+	res, err := s.GetDocuments(context.TODO(), "t1", ids)
+	require.NoError(t, err)
+	require.Equal(t, []int{250, 50}, perPages)
+	require.Equal(t, 300, res.Found)
+	require.Len(t, res.Hits, 300)
+	for i, hit := range res.Hits {
+		require.Equal(t, ids[i], hit.Document["id"])
+	}
+
+	perPages = nil
+	res, err = s.GetDocuments(context.TODO(), "t1", ids[:15])
+	require.NoError(t, err)
+	require.Equal(t, []int{15}, perPages)
+	require.Len(t, res.Hits, 15)
+}
+
+func TestMatchedFields(t *testing.T) {
+	cases := []struct {
+		resp       []byte
+		expMatched []string
+	}{
+		{
+			[]byte(`{
+"results": [{
+		"hits": [{
+            "document": {},
+			"highlight": {
+				"arr_obj": [{
+					"domain": { "matched_tokens": [], "snippet": "regional24-7.com"},
+					"arr": [{"matched_tokens": [], "snippet": "Daihatsu"}, {"matched_tokens": [],"snippet": "Chrysler"}]
+				}, {
+					"domain": { "matched_tokens": [], "snippet": "internalorchestrate.name"},
+					"arr": [{"matched_tokens": ["Dino"],"snippet": "<mark>Dino</mark>"}, {"matched_tokens": [],"snippet": "Skoda"}, {"matched_tokens": ["Dino"],"snippet": "<mark>Dino</mark>"}]
+				}, {
+					"domain": {"matched_tokens": [],"snippet": "nationalincubate.net"},
+					"arr": [{"matched_tokens": [],"snippet": "Daewoo"}, {"matched_tokens": [],"snippet": "Cadillac"}]
+				}]
+			}
+		}, {
+            "document": {},
+			"highlight": {
+				"arr_obj": [{
+					"domain": {"matched_tokens": [],"snippet": "internalorchestrate.name"},
+					"arr": [{"matched_tokens": ["Dino"],"snippet": "<mark>Dino</mark>"}, {"matched_tokens": [],"snippet": "Skoda"}, {"matched_tokens": ["Dino"],"snippet": "<mark>Dino</mark>"}]
+				}, {
+					"domain": {	"matched_tokens": [],"snippet": "globalstrategic.net"},
+					"arr": [{"matched_tokens": [],"snippet": "Skoda"}, {"matched_tokens": [],"snippet": "Volkswagen"}, {"matched_tokens": ["Dino"],"snippet": "<mark>Dino</mark>"}]
+				}]
+			}
+		}]
+	}]
+}`),
+			[]string{"arr_obj.arr", "arr_obj.arr", "arr_obj.arr"},
+		},
+		{
+			[]byte(`{
+	"results": [{
+		"hits": [{
+            "document": {},
+ 			"highlight": {
+ 				"nested.address.city": {
+ 					"matched_tokens": ["Omaha"],
+ 					"snippet": "<mark>Omaha</mark>"
+ 				}
+ 			}
+		}]
+	}]
+}`),
+			[]string{"nested.address.city"},
+		},
+		{
+			[]byte(`{
+	"results": [{
+		"hits": [{
+            "document": {},
+            "highlight": {
+				"arr_obj": [{
+					"domain": {"matched_tokens": ["regional24-7.com"], "snippet": "<mark>regional24-7.com</mark>"},
+					"arr": [{"matched_tokens": [], "snippet": "Chrysler"}]
+				}, {
+					"domain": {"matched_tokens": [],"snippet": "internalorchestrate.name"},
+					"arr": [{"matched_tokens": [],"snippet": "Dino"}]
+				}] 
+			}
+		}]
+	}]
+}`),
+			[]string{"arr_obj.domain"},
+		},
+		{
+			[]byte(`{
+	"results": [{
+		"hits": [{
+            "document": {},
+            "highlight": {
+				"arr_obj": [{
+					"domain": { "matched_tokens": [], "snippet": "regional24-7.com"},
+					"arr": [{"matched_tokens": [], "snippet": "Daihatsu"}]
+				}, {
+					"domain": { "matched_tokens": [], "snippet": "internalorchestrate.name"},
+					"arr": [{"matched_tokens": ["Dino"],"snippet": "<mark>Dino</mark>"}]
+				}],
+				"commands_obj.name": {
+					"matched_tokens": ["dino"],
+					"snippet": "<mark>dino</mark>"
+				},
+				"name": {
+					"matched_tokens": ["dino"],
+					"snippet": "<mark>dino</mark>"
+				}
+			}
+		}]
+	}]
+}`),
+			[]string{"arr_obj.arr", "commands_obj.name", "name"},
+		},
+	}
+	for _, c := range cases {
+		var actualMatched []string
+		var dest tsApi.MultiSearchResult
+		require.NoError(t, jsoniter.Unmarshal(c.resp, &dest))
+		for i := range dest.Results {
+			for _, h := range fromTsResult(&dest.Results[i]).Hits {
+				actualMatched = append(actualMatched, h.MatchedFields...)
+			}
+		}
+
+		sort.Strings(c.expMatched)
+		sort.Strings(actualMatched)
+		require.Equal(t, c.expMatched, actualMatched)
+	}
+}
+
+func TestFromTsFacets(t *testing.T) {
+	var tsFacets []tsApi.FacetCounts
+	require.NoError(t, jsoniter.Unmarshal([]byte(`[
+		{"field_name":"a","counts":[{"count":20,"value":"value_1"},{"count":10,"value":null}]},
+		{"field_name":null,"counts":[{"count":20,"value":"value_1"}]},
+		{"field_name":"c","counts":[{"count":30,"value":"value_2"}],"stats":{"total_values":1,"sum":12345}}
+	]`), &tsFacets))
+
+	sum := float64(12345)
+	require.Equal(t, []FacetCounts{
+		{FieldName: "a", Counts: []FacetCount{{Value: "value_1", Count: 20}}},
+		{FieldName: "c", Counts: []FacetCount{{Value: "value_2", Count: 30}}, Stats: &FacetStats{Sum: &sum, TotalValues: 1}},
+	}, fromTsFacets(tsFacets))
+}
@@ -89,6 +89,29 @@ func TestDeleteCache(t *testing.T) {
 	assert.Equal(t, "NOT_FOUND", code)
 }
 
+func TestCacheNotCreated(t *testing.T) {
+	project := setupTestsOnlyProject(t)
+	cacheName := getCacheName(t)
+
+	// the keys of a cache are only reachable once the cache is created
+	code := setCacheKey(t, project, cacheName, "k1", "v1").Status(http.StatusNotFound).
+		JSON().
+		Object().
+		Value("error").
+		Object().
+		Value("code").
+		Raw()
+	assert.Equal(t, "NOT_FOUND", code)
+	getCacheKey(t, project, cacheName, "k1").Status(http.StatusNotFound)
+
+	createCache(t, project, cacheName)
+	setCacheKey(t, project, cacheName, "k1", "v1").Status(http.StatusOK)
+	getCacheKey(t, project, cacheName, "k1").Status(http.StatusOK)
+
+	deleteCache(t, project, cacheName).Status(http.StatusOK)
+	getCacheKey(t, project, cacheName, "k1").Status(http.StatusNotFound)
+}
+
 func TestListCaches(t *testing.T) {
 	project := setupTestsOnlyProject(t)
 	for i := 1; i <= 5; i++ {
@@ -283,6 +306,244 @@ func TestCacheSetWithGet(t *testing.T) {
 	assert.Equal(t, "v2", oldValue1)
 }
 
+func TestCacheExpiry(t *testing.T) {
+	project := setupTestsOnlyProject(t)
+	cacheName := getCacheName(t)
+	createCache(t, project, cacheName)
+
+	setCacheKey(t, project, cacheName, "k1", "v1").Status(http.StatusOK)
+	ttlCacheKey(t, project, cacheName, "k1").Status(http.StatusOK).
+		JSON().
+		Object().
+		ValueEqual("ttl_ms", -1)
+
+	e := cacheExpect(t)
+	e.POST(cacheKVOperationURL(project, cacheName, "k1", "expire")).
+		WithJSON(CacheTestMap{
+			"ex": 100,
+		}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		ValueEqual("status", cache.ExpiredStatus)
+
+	ttl := ttlCacheKey(t, project, cacheName, "k1").Status(http.StatusOK).
+		JSON().
+		Object().
+		Value("ttl_ms").
+		Number().
+		Raw()
+	assert.True(t, ttl > 0 && ttl <= 100000)
+
+	e.POST(cacheKVOperationURL(project, cacheName, "k1", "persist")).
+		WithJSON(CacheTestMap{}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		ValueEqual("status", cache.PersistedStatus)
+
+	ttlCacheKey(t, project, cacheName, "k1").Status(http.StatusOK).
+		JSON().
+		Object().
+		ValueEqual("ttl_ms", -1)
+
+	ttlCacheKey(t, project, cacheName, "k2").Status(http.StatusNotFound)
+}
+
+func TestCacheMSetWithMGet(t *testing.T) {
+	project := setupTestsOnlyProject(t)
+	cacheName := getCacheName(t)
+	createCache(t, project, cacheName)
+
+	e := cacheExpect(t)
+	e.POST(cacheOperationURL(project, cacheName, "mset")).
+		WithJSON(CacheTestMap{
+			"entries": []CacheTestMap{
+				{"key": "k1", "value": "v1"},
+				{"key": "k2", "value": CacheTestMap{"a": 1}},
+			},
+		}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		ValueEqual("status", cache.SetStatus)
+
+	entries := e.POST(cacheOperationURL(project, cacheName, "mget")).
+		WithJSON(CacheTestMap{
+			"keys": []string{"k1", "k2", "k3"},
+		}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		Value("entries").
+		Array()
+	entries.Length().Equal(3)
+	entries.Element(0).Object().ValueEqual("value", "v1").ValueEqual("found", true)
+	entries.Element(1).Object().ValueEqual("value", CacheTestMap{"a": 1}).ValueEqual("found", true)
+	entries.Element(2).Object().NotContainsKey("value").ValueEqual("found", false)
+
+	e.POST(cacheOperationURL(project, cacheName, "exists")).
+		WithJSON(CacheTestMap{
+			"keys": []string{"k1", "k2", "k3"},
+		}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		ValueEqual("count", 2)
+
+	// nx fails the whole batch if any of the keys exists
+	e.POST(cacheOperationURL(project, cacheName, "mset")).
+		WithJSON(CacheTestMap{
+			"entries": []CacheTestMap{
+				{"key": "k1", "value": "v2"},
+				{"key": "k3", "value": "v3"},
+			},
+			"nx": true,
+		}).
+		Expect().
+		Status(http.StatusConflict)
+	getCacheKey(t, project, cacheName, "k3").Status(http.StatusNotFound)
+}
+
+func TestCacheCounters(t *testing.T) {
+	project := setupTestsOnlyProject(t)
+	cacheName := getCacheName(t)
+	createCache(t, project, cacheName)
+
+	e := cacheExpect(t)
+	e.POST(cacheKVOperationURL(project, cacheName, "hits", "incr")).
+		WithJSON(CacheTestMap{}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		ValueEqual("value", 1)
+	e.POST(cacheKVOperationURL(project, cacheName, "hits", "incrby")).
+		WithJSON(CacheTestMap{
+			"increment": 10,
+		}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		ValueEqual("value", 11)
+	e.POST(cacheKVOperationURL(project, cacheName, "hits", "decr")).
+		WithJSON(CacheTestMap{}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		ValueEqual("value", 10)
+
+	getCacheKey(t, project, cacheName, "hits").Status(http.StatusOK).
+		JSON().
+		Object().
+		ValueEqual("value", 10)
+
+	setCacheKey(t, project, cacheName, "k1", "v1").Status(http.StatusOK)
+	e.POST(cacheKVOperationURL(project, cacheName, "k1", "incr")).
+		WithJSON(CacheTestMap{}).
+		Expect().
+		Status(http.StatusBadRequest)
+}
+
+func TestCacheDataStructures(t *testing.T) {
+	project := setupTestsOnlyProject(t)
+	cacheName := getCacheName(t)
+	createCache(t, project, cacheName)
+
+	e := cacheExpect(t)
+	e.POST(cacheKVOperationURL(project, cacheName, "user", "hset")).
+		WithJSON(CacheTestMap{
+			"fields": CacheTestMap{"name": "alice", "address": CacheTestMap{"city": "paris"}},
+		}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		ValueEqual("status", cache.SetStatus)
+	e.POST(cacheKVOperationURL(project, cacheName, "user", "hget")).
+		WithJSON(CacheTestMap{
+			"field": "name",
+		}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		ValueEqual("value", "alice")
+	e.GET(cacheKVOperationURL(project, cacheName, "user", "hgetall")).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		ValueEqual("fields", CacheTestMap{"name": "alice", "address": CacheTestMap{"city": "paris"}})
+
+	e.POST(cacheKVOperationURL(project, cacheName, "leaderboard", "zadd")).
+		WithJSON(CacheTestMap{
+			"members": []CacheTestMap{
+				{"member": "a", "score": 10},
+				{"member": "b", "score": 30},
+				{"member": "c", "score": 20},
+			},
+		}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		ValueEqual("added", 3)
+	e.GET(cacheKVOperationURL(project, cacheName, "leaderboard", "zrange")).
+		WithQuery("start", 0).
+		WithQuery("stop", 1).
+		WithQuery("rev", true).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		ValueEqual("members", []CacheTestMap{{"member": "b", "score": 30}, {"member": "c", "score": 20}})
+	e.POST(cacheKVOperationURL(project, cacheName, "leaderboard", "zrank")).
+		WithJSON(CacheTestMap{
+			"member": "c",
+		}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		ValueEqual("rank", 1)
+
+	e.POST(cacheKVOperationURL(project, cacheName, "queue", "lpush")).
+		WithJSON(CacheTestMap{
+			"values": []any{1, CacheTestMap{"a": 2}},
+		}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		ValueEqual("length", 2)
+	e.POST(cacheKVOperationURL(project, cacheName, "queue", "rpop")).
+		WithJSON(CacheTestMap{}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		ValueEqual("value", 1)
+	e.POST(cacheKVOperationURL(project, cacheName, "queue", "rpop")).
+		WithJSON(CacheTestMap{}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		ValueEqual("value", CacheTestMap{"a": 2})
+	e.POST(cacheKVOperationURL(project, cacheName, "queue", "rpop")).
+		WithJSON(CacheTestMap{}).
+		Expect().
+		Status(http.StatusNotFound)
+}
+
 func setCacheKey(t *testing.T, project string, cache string, key string, value string) *httpexpect.Response {
 	e := cacheExpect(t)
 	return e.POST(cacheKVOperationURL(project, cache, key, "set")).
@@ -307,6 +568,12 @@ func getCacheKey(t *testing.T, project string, cache string, key string) *httpex
 		Expect()
 }
 
+func ttlCacheKey(t *testing.T, project string, cache string, key string) *httpexpect.Response {
+	e := cacheExpect(t)
+	return e.GET(cacheKVOperationURL(project, cache, key, "ttl")).
+		Expect()
+}
+
 func delCacheKey(t *testing.T, project string, cache string, key string) *httpexpect.Response {
 	e := cacheExpect(t)
 	return e.DELETE(cacheKVOperationURL(project, cache, key, "delete")).
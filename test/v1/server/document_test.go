@@ -4513,6 +4513,63 @@ func TestRead_AcceptApplicationJSON(t *testing.T) {
 	}
 }
 
+func TestRead_DocumentCache(t *testing.T) {
+	dbName := fmt.Sprintf("db_test_%s", t.Name())
+
+	deleteProject(t, dbName)
+	createProject(t, dbName)
+	defer deleteProject(t, dbName)
+
+	collectionName := "test_collection_cached"
+	createCollection(t, dbName, collectionName,
+		Map{
+			"schema": Map{
+				"title": collectionName,
+				"properties": Map{
+					"pkey_int":     Map{"type": "integer"},
+					"string_value": Map{"type": "string"},
+				},
+				"primary_key": []any{"pkey_int"},
+				"cache":       Map{"enabled": true, "ttl_ms": 60000},
+			},
+		}).Status(http.StatusOK)
+
+	filter := Map{"pkey_int": 1}
+	inputDoc := []Doc{{"pkey_int": 1, "string_value": "foo"}}
+	insertDocuments(t, dbName, collectionName, inputDoc, true).
+		Status(http.StatusOK)
+
+	// the first read caches the document and the second one is served by the cache
+	readAndValidate(t, dbName, collectionName, filter, nil, inputDoc)
+	readAndValidate(t, dbName, collectionName, filter, nil, inputDoc)
+	readAndValidate(t, dbName, collectionName, Map{"$or": []Map{filter, {"pkey_int": 2}}}, nil, inputDoc)
+
+	updateByFilter(t, dbName, collectionName,
+		Map{"filter": filter},
+		Map{"fields": Map{"$set": Map{"string_value": "bar"}}},
+		nil).Status(http.StatusOK)
+
+	updatedDoc := []Doc{{"pkey_int": 1, "string_value": "bar"}}
+	readAndValidate(t, dbName, collectionName, filter, nil, updatedDoc)
+	readAndValidateWithOptions(t, dbName, collectionName, filter, nil, Map{"bypass_cache": true}, updatedDoc)
+
+	deleteByFilter(t, dbName, collectionName, Map{"filter": filter}).Status(http.StatusOK)
+	readAndValidate(t, dbName, collectionName, filter, nil, nil)
+
+	collectionName = "test_collection_cache_invalid"
+	createCollection(t, dbName, collectionName,
+		Map{
+			"schema": Map{
+				"title": collectionName,
+				"properties": Map{
+					"pkey_int": Map{"type": "integer"},
+				},
+				"primary_key": []any{"pkey_int"},
+				"cache":       Map{"enabled": true, "ttl_ms": -1},
+			},
+		}).Status(http.StatusBadRequest)
+}
+
 func TestTransaction_BadID(t *testing.T) {
 	db, coll := setupTests(t)
 	defer cleanupTests(t, db)